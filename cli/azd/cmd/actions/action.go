@@ -3,6 +3,8 @@ package actions
 
 import (
 	"context"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/warnings"
 )
 
 // ActionFunc is an Action implementation for regular functions.
@@ -25,6 +27,13 @@ type ActionResult struct {
 
 	// TraceID is a unique identifier of the end-to-end CLI command execution, that can be used to correlate events in logs.
 	TraceID string
+
+	// Data is the structured result of the action, like the deployed services, for programmatic callers. It is the value
+	// written when the output format is JSON. Nil when the action has no structured result.
+	Data any
+
+	// Warnings are the warnings raised while the action ran, collected by the warnings sink of its context.
+	Warnings []warnings.Warning
 }
 
 // Action is the representation of the application logic of a CLI command.
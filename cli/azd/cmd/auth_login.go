@@ -23,6 +23,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/contracts"
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/logging"
 	"github.com/azure/azure-dev/cli/azd/pkg/oneauth"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/github"
@@ -53,6 +54,7 @@ type loginFlags struct {
 	clientSecret           stringPtr
 	clientCertificate      string
 	federatedTokenProvider string
+	federatedTokenFile     string
 	scopes                 []string
 	redirectPort           int
 	global                 *internal.GlobalCommandOptions
@@ -108,8 +110,13 @@ const (
 	cClientSecretFlagName                = "client-secret"
 	cClientCertificateFlagName           = "client-certificate"
 	cFederatedCredentialProviderFlagName = "federated-credential-provider"
+	cFederatedTokenFileFlagName          = "federated-token-file"
 )
 
+// cFederatedTokenFileEnvVarName is the environment variable that holds the path to a federated token file. It is set by
+// the Azure Workload Identity webhook for pods running in Kubernetes.
+const cFederatedTokenFileEnvVarName = "AZURE_FEDERATED_TOKEN_FILE"
+
 func (lf *loginFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
 	local.BoolVar(&lf.onlyCheckStatus, "check-status", false, "Checks the log-in status instead of logging in.")
 	f := local.VarPF(
@@ -136,6 +143,14 @@ func (lf *loginFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandO
 		cFederatedCredentialProviderFlagName,
 		"",
 		"The provider to use to acquire a federated token to authenticate with.")
+	local.StringVar(
+		&lf.federatedTokenFile,
+		cFederatedTokenFileFlagName,
+		"",
+		fmt.Sprintf(
+			"The path to a file containing a federated token to authenticate with. Defaults to the value of %s "+
+				"when no other credential is provided.",
+			cFederatedTokenFileEnvVarName))
 	local.StringVar(
 		&lf.tenantID,
 		"tenant-id",
@@ -177,7 +192,7 @@ func newLoginCmd(parent string) *cobra.Command {
 		--use-device-code.
 
 		To log in as a service principal, pass --client-id and --tenant-id as well as one of: --client-secret,
-		--client-certificate, or --federated-credential-provider.
+		--client-certificate, --federated-credential-provider or --federated-token-file.
 		`),
 		Annotations: map[string]string{
 			loginCmdParentAnnotation: parent,
@@ -267,7 +282,7 @@ func (la *loginAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 		// In check status mode, we always print the final status to stdout.
 		// We print any non-setup related errors to stderr.
 		// We always return a zero exit code.
-		token, err := la.verifyLoggedIn(ctx)
+		token, source, err := la.verifyLoggedIn(ctx)
 		var loginExpiryError *auth.ReLoginRequiredError
 		if err != nil &&
 			!errors.Is(err, auth.ErrNoCurrentUser) &&
@@ -281,6 +296,7 @@ func (la *loginAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 		} else {
 			res.Status = contracts.LoginStatusSuccess
 			res.ExpiresOn = &token.ExpiresOn
+			res.Source = string(source)
 		}
 
 		if la.formatter.Kind() != output.NoneFormat {
@@ -290,6 +306,9 @@ func (la *loginAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 			switch res.Status {
 			case contracts.LoginStatusSuccess:
 				msg = cLoginSuccessMessage
+				if source == auth.CredentialSourceAzCli {
+					msg = "Logged in to Azure using the az CLI login."
+				}
 			case contracts.LoginStatusUnauthenticated:
 				msg = "Not logged in, run `azd auth login` to login to Azure."
 			default:
@@ -302,14 +321,14 @@ func (la *loginAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 	}
 
 	if err := la.accountSubManager.ClearSubscriptions(ctx); err != nil {
-		log.Printf("failed clearing subscriptions: %v", err)
+		logging.Warn("clearing the subscriptions", "error", err)
 	}
 
 	if err := la.login(ctx); err != nil {
 		return nil, err
 	}
 
-	if _, err := la.verifyLoggedIn(ctx); err != nil {
+	if _, _, err := la.verifyLoggedIn(ctx); err != nil {
 		return nil, err
 	}
 
@@ -329,14 +348,14 @@ func (la *loginAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 		if err != nil {
 			// If this fails, the subscriptions will still be loaded on-demand.
 			// erroring out when the user interacts with subscriptions is much more user-friendly.
-			log.Printf("failed retrieving subscriptions: %v", err)
+			logging.Warn("retrieving the subscriptions", "error", err)
 		}
 	} else {
 		// Service principals do not typically require subscription caching (running in CI scenarios)
 		// We simply clear the cache, which is much faster than rehydrating.
 		err := la.accountSubManager.ClearSubscriptions(ctx)
 		if err != nil {
-			log.Printf("failed clearing subscriptions: %v", err)
+			logging.Warn("clearing the subscriptions", "error", err)
 		}
 	}
 
@@ -346,14 +365,15 @@ func (la *loginAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 
 // Verifies that the user has credentials stored,
 // and that the credentials stored is accepted by the identity server (can be exchanged for access token).
-func (la *loginAction) verifyLoggedIn(ctx context.Context) (*azcore.AccessToken, error) {
+// The source of the credential is returned along with the token.
+func (la *loginAction) verifyLoggedIn(ctx context.Context) (*azcore.AccessToken, auth.CredentialSource, error) {
 	credOptions := auth.CredentialForCurrentUserOptions{
 		TenantID: la.flags.tenantID,
 	}
 
 	cred, err := la.authManager.CredentialForCurrentUser(ctx, &credOptions)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	// Ensure credential is valid, and can be exchanged for an access token
@@ -362,10 +382,10 @@ func (la *loginAction) verifyLoggedIn(ctx context.Context) (*azcore.AccessToken,
 	})
 
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	return &token, nil
+	return &token, auth.CredentialSourceOf(cred), nil
 }
 
 func countTrue(elms ...bool) int {
@@ -404,16 +424,27 @@ func (la *loginAction) login(ctx context.Context) error {
 			return errors.New("must set both `client-id` and `tenant-id` for service principal login")
 		}
 
+		if la.flags.federatedTokenFile == "" && countTrue(
+			la.flags.clientSecret.ptr != nil,
+			la.flags.clientCertificate != "",
+			la.flags.federatedTokenProvider != "",
+		) == 0 {
+			// When running with workload identity, the token file is provided by the environment.
+			la.flags.federatedTokenFile = os.Getenv(cFederatedTokenFileEnvVarName)
+		}
+
 		if countTrue(
 			la.flags.clientSecret.ptr != nil,
 			la.flags.clientCertificate != "",
 			la.flags.federatedTokenProvider != "",
+			la.flags.federatedTokenFile != "",
 		) != 1 {
 			return fmt.Errorf(
 				"must set exactly one of %s for service principal", strings.Join([]string{
 					cClientSecretFlagName,
 					cClientCertificateFlagName,
 					cFederatedCredentialProviderFlagName,
+					cFederatedTokenFileFlagName,
 				}, ", "))
 		}
 
@@ -457,6 +488,12 @@ func (la *loginAction) login(ctx context.Context) error {
 			); err != nil {
 				return fmt.Errorf("logging in: %w", err)
 			}
+		case la.flags.federatedTokenFile != "":
+			if _, err := la.authManager.LoginWithServicePrincipalFederatedTokenFile(
+				ctx, la.flags.tenantID, la.flags.clientID, la.flags.federatedTokenFile,
+			); err != nil {
+				return fmt.Errorf("logging in: %w", err)
+			}
 		}
 
 		return nil
@@ -467,7 +504,7 @@ func (la *loginAction) login(ctx context.Context) error {
 		//
 		// TODO(ellismg): We may want instead to call some explicit `/login` endpoint on the external auth system instead
 		// of abusing the token request in this manner. This would allow the other end to provide a more tailored experience.
-		_, err := la.verifyLoggedIn(ctx)
+		_, _, err := la.verifyLoggedIn(ctx)
 		return err
 	}
 
@@ -5,12 +5,14 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/MakeNowJust/heredoc/v2"
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
 	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/pkg/account"
@@ -38,14 +40,23 @@ func newAuthTokenFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions
 
 func newAuthTokenCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:    "token --output json",
-		Hidden: true,
+		Use:   "token --output json",
+		Short: "Print an access token for the current identity.",
+		Long: heredoc.Doc(`
+		Print an access token for the current identity.
+
+		The token is only written to stdout and requires --output json to be passed explicitly. By default, a token
+		for Azure Resource Manager is requested, use --scope to request a token for a different resource. The command
+		never prompts for interactive log in, run 'azd auth login' first.
+		`),
+		Example: "$ azd auth token --scope https://management.azure.com/.default --output json",
 	}
 }
 
 func (f *authTokenFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
 	f.global = global
-	local.StringArrayVar(&f.scopes, "scope", nil, "The scope to use when requesting an access token")
+	local.StringArrayVar(
+		&f.scopes, "scope", nil, "The scope to use when requesting an access token. May be repeated.")
 	local.StringVar(&f.tenantID, "tenant-id", "", "The tenant id to use when requesting an access token.")
 }
 
@@ -128,6 +139,11 @@ func getTenantIdFromEnv(
 }
 
 func (a *authTokenAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	// The token is a secret, so we only ever write it when the caller explicitly asked for it.
+	if a.formatter.Kind() != output.JsonFormat {
+		return nil, errors.New("printing an access token requires '--output json'")
+	}
+
 	if len(a.flags.scopes) == 0 {
 		a.flags.scopes = auth.LoginScopes(a.cloud)
 	}
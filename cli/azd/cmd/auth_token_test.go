@@ -10,6 +10,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log"
+	"os"
 	"testing"
 	"time"
 
@@ -334,6 +336,82 @@ func TestAuthTokenFailure(t *testing.T) {
 	require.ErrorContains(t, err, "could not fetch token")
 }
 
+func TestAuthTokenRequiresJsonOutput(t *testing.T) {
+	wasCalled := false
+	buf := &bytes.Buffer{}
+
+	token := authTokenFn(func(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+		wasCalled = true
+		return azcore.AccessToken{Token: "ABC123"}, nil
+	})
+
+	a := newAuthTokenAction(
+		credentialProviderForTokenFn(token),
+		&output.NoneFormatter{},
+		buf,
+		&authTokenFlags{},
+		func(ctx context.Context) (*environment.Environment, error) {
+			return nil, fmt.Errorf("not an azd env directory")
+		},
+		&mockSubscriptionTenantResolver{},
+		cloud.AzurePublic(),
+	)
+
+	_, err := a.Run(context.Background())
+	require.ErrorContains(t, err, "--output json")
+	require.False(t, wasCalled, "GetToken should not be called without an explicit output format")
+	require.Empty(t, buf.String())
+}
+
+func TestAuthTokenIsNotLogged(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logs := &bytes.Buffer{}
+
+	log.SetOutput(logs)
+	defer log.SetOutput(os.Stderr)
+
+	token := authTokenFn(func(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+		require.ElementsMatch(t, []string{"https://management.azure.com/.default"}, options.Scopes)
+
+		return azcore.AccessToken{
+			Token:     "SECRET-TOKEN-VALUE",
+			ExpiresOn: time.Unix(1669153000, 0).UTC(),
+		}, nil
+	})
+
+	a := newAuthTokenAction(
+		func(ctx context.Context, options *auth.CredentialForCurrentUserOptions) (azcore.TokenCredential, error) {
+			require.True(t, options.NoPrompt)
+			return token, nil
+		},
+		&output.JsonFormatter{},
+		buf,
+		&authTokenFlags{
+			scopes: []string{"https://management.azure.com/.default"},
+			global: &internal.GlobalCommandOptions{
+				EnableDebugLogging: true,
+				NoPrompt:           true,
+			},
+		},
+		func(ctx context.Context) (*environment.Environment, error) {
+			return nil, fmt.Errorf("not an azd env directory")
+		},
+		&mockSubscriptionTenantResolver{},
+		cloud.AzurePublic(),
+	)
+
+	_, err := a.Run(context.Background())
+	require.NoError(t, err)
+
+	var res map[string]any
+	err = json.Unmarshal(buf.Bytes(), &res)
+	require.NoError(t, err)
+	require.Equal(t, "SECRET-TOKEN-VALUE", res["token"])
+	require.Equal(t, "2022-11-22T21:36:40Z", res["expiresOn"])
+
+	require.NotContains(t, logs.String(), "SECRET-TOKEN-VALUE")
+}
+
 // authTokenFn implements azcore.TokenCredential using the function itself as the implementation of GetToken.
 type authTokenFn func(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error)
 
@@ -0,0 +1,175 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/cmd/middleware"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/internal/cmd"
+	"github.com/azure/azure-dev/cli/azd/pkg/azdapi"
+	"github.com/azure/azure-dev/cli/azd/pkg/ioc"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func init() {
+	azdapi.RegisterRuntime(&azdapiRuntime{})
+}
+
+// azdapiRuntime runs the actions of the CLI for the in-process clients of the azdapi package.
+type azdapiRuntime struct{}
+
+func (r *azdapiRuntime) RegisterServices(rootContainer *ioc.NestedContainer) {
+	_ = NewRootCmd(false, nil, rootContainer)
+
+	// The builder knows the descriptors of the commands it built, so the scopes of the operations share it to run the
+	// middleware of the commands
+	var cobraBuilder *CobraBuilder
+	if err := rootContainer.Resolve(&cobraBuilder); err != nil {
+		panic(err)
+	}
+	ioc.RegisterInstance(rootContainer, cobraBuilder)
+}
+
+func (r *azdapiRuntime) Provision(
+	ctx context.Context,
+	container *ioc.NestedContainer,
+) (*azdapi.ProvisionResult, error) {
+	envFlag, globalOptions, err := r.options(container)
+	if err != nil {
+		return nil, err
+	}
+
+	ioc.RegisterInstance(container, io.Discard)
+	ioc.RegisterInstance(container, cmd.NewProvisionFlagsFromEnvAndOptions(envFlag, globalOptions))
+	container.MustRegisterNamedTransient("provisionAction", cmd.NewProvisionAction)
+
+	actionResult, err := r.run(ctx, container, "provision", "provisionAction", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &azdapi.ProvisionResult{}
+	if provisionResult, ok := actionResult.Data.(*cmd.ProvisionResult); ok && !provisionResult.Skipped {
+		result.EnvRefreshResult = provisionResult.EnvRefreshResult
+		result.Duration = provisionResult.Duration
+	}
+
+	return result, nil
+}
+
+func (r *azdapiRuntime) Deploy(
+	ctx context.Context,
+	container *ioc.NestedContainer,
+	options azdapi.DeployOptions,
+) (*azdapi.DeployResult, error) {
+	envFlag, globalOptions, err := r.options(container)
+	if err != nil {
+		return nil, err
+	}
+
+	deployFlags := cmd.NewDeployFlagsFromEnvAndOptions(envFlag, globalOptions)
+	args := []string{}
+	if options.ServiceName != "" {
+		args = append(args, options.ServiceName)
+	} else {
+		deployFlags.All = true
+	}
+
+	ioc.RegisterInstance(container, io.Discard)
+	ioc.RegisterInstance(container, deployFlags)
+	ioc.RegisterInstance(container, args)
+	container.MustRegisterNamedTransient("deployAction", cmd.NewDeployAction)
+
+	actionResult, err := r.run(ctx, container, "deploy", "deployAction", args)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &azdapi.DeployResult{}
+	if deployment, ok := actionResult.Data.(*cmd.DeploymentResult); ok {
+		result.Services = deployment.Services
+		result.Duration = deployment.Duration
+	}
+
+	return result, nil
+}
+
+// options returns the environment flag and the global options the client registered in the container.
+func (r *azdapiRuntime) options(
+	container *ioc.NestedContainer,
+) (*internal.EnvFlag, *internal.GlobalCommandOptions, error) {
+	var envFlag internal.EnvFlag
+	if err := container.Resolve(&envFlag); err != nil {
+		return nil, nil, err
+	}
+
+	var globalOptions *internal.GlobalCommandOptions
+	if err := container.Resolve(&globalOptions); err != nil {
+		return nil, nil, err
+	}
+
+	return &envFlag, globalOptions, nil
+}
+
+// run runs the action through the middleware of the command, like hooks and telemetry, as when running the command.
+func (r *azdapiRuntime) run(
+	ctx context.Context,
+	container *ioc.NestedContainer,
+	commandName string,
+	actionName string,
+	args []string,
+) (*actions.ActionResult, error) {
+	var rootCmd *cobra.Command
+	if err := container.ResolveNamed("root-cmd", &rootCmd); err != nil {
+		return nil, err
+	}
+
+	var cobraBuilder *CobraBuilder
+	if err := container.Resolve(&cobraBuilder); err != nil {
+		return nil, err
+	}
+
+	cmd, _, err := rootCmd.Find([]string{commandName})
+	if err != nil {
+		return nil, err
+	}
+
+	descriptor, has := cobraBuilder.descriptors[cmd]
+	if !has {
+		return nil, fmt.Errorf("command '%s' isn't built", commandName)
+	}
+
+	var action actions.Action
+	if err := container.ResolveNamed(actionName, &action); err != nil {
+		return nil, err
+	}
+
+	ioc.RegisterInstance(container, container)
+	ioc.RegisterInstance[ioc.ServiceLocator](container, container)
+
+	// The operations aren't configured with flags, so the middleware only sees the default values
+	runOptions := &middleware.Options{
+		Name:        cmd.Name(),
+		CommandPath: cmd.CommandPath(),
+		Flags:       pflag.NewFlagSet(cmd.Name(), pflag.ContinueOnError),
+		Args:        args,
+	}
+
+	actionResult, err := cobraBuilder.runAction(ctx, container, descriptor, runOptions, action)
+	if err != nil {
+		return nil, err
+	}
+
+	if actionResult == nil {
+		actionResult = &actions.ActionResult{}
+	}
+
+	return actionResult, nil
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
@@ -11,6 +12,8 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
 	"github.com/azure/azure-dev/cli/azd/cmd/middleware"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	internalcmd "github.com/azure/azure-dev/cli/azd/internal/cmd"
 	"github.com/azure/azure-dev/cli/azd/pkg/azapi"
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
@@ -19,7 +22,9 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/warnings"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const cDocsFlagName = "docs"
@@ -27,12 +32,15 @@ const cDocsFlagName = "docs"
 // CobraBuilder manages the construction of the cobra command tree from nested ActionDescriptors
 type CobraBuilder struct {
 	container *ioc.NestedContainer
+	// The descriptor each built command was created from
+	descriptors map[*cobra.Command]*actions.ActionDescriptor
 }
 
 // Creates a new instance of the Cobra builder
 func NewCobraBuilder(container *ioc.NestedContainer) *CobraBuilder {
 	return &CobraBuilder{
-		container: container,
+		container:   container,
+		descriptors: map[*cobra.Command]*actions.ActionDescriptor{},
 	}
 }
 
@@ -42,6 +50,7 @@ func (cb *CobraBuilder) BuildCommand(descriptor *actions.ActionDescriptor) (*cob
 	if cmd.Use == "" {
 		cmd.Use = descriptor.Name
 	}
+	cb.descriptors[cmd] = descriptor
 
 	// Build the full command tree
 	for _, childDescriptor := range descriptor.Children() {
@@ -101,6 +110,9 @@ func (cb *CobraBuilder) configureActionResolver(cmd *cobra.Command, descriptor *
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		// Register root go context that will be used for resolving singleton dependencies
 		ctx := tools.WithInstalledCheckCache(cmd.Context())
+		// Collects the warnings raised by the action, rendered once it completes
+		warningSink := warnings.NewSink()
+		ctx = warnings.WithSink(ctx, warningSink)
 		ioc.RegisterInstance(cb.container, ctx)
 
 		// Create new container scope for the current command
@@ -116,12 +128,6 @@ func (cb *CobraBuilder) configureActionResolver(cmd *cobra.Command, descriptor *
 		ioc.RegisterInstance(cmdContainer, cmdContainer)
 		ioc.RegisterInstance[ioc.ServiceLocator](cmdContainer, cmdContainer)
 
-		// Register any required middleware registered for the current action descriptor
-		middlewareRunner := middleware.NewMiddlewareRunner(cmdContainer)
-		if err := cb.registerMiddleware(middlewareRunner, descriptor); err != nil {
-			return err
-		}
-
 		actionName := createActionName(cmd)
 		var action actions.Action
 		if err := cmdContainer.ResolveNamed(actionName, &action); err != nil {
@@ -145,16 +151,38 @@ func (cb *CobraBuilder) configureActionResolver(cmd *cobra.Command, descriptor *
 			Args:        args,
 		}
 
-		// Set the container that should be used for resolving middleware components
-		runOptions.WithContainer(cmdContainer)
 		// Run the middleware chain with action
-		actionResult, err := middlewareRunner.RunAction(ctx, runOptions, action)
+		actionResult, err := cb.runAction(ctx, cmdContainer, descriptor, runOptions, action)
+		if actionResult != nil {
+			actionResult.Warnings = warningSink.Warnings()
+		}
 
 		// At this point, we know that there might be an error, so we can silence cobra from showing it after us.
 		cmd.SilenceErrors = true
 
 		// TODO: Consider refactoring to move the UX writing to a middleware
-		invokeErr := cmdContainer.Invoke(func(console input.Console) {
+		invokeErr := cmdContainer.Invoke(func(console input.Console, rootOptions *internal.GlobalCommandOptions) {
+			for _, warning := range warningSink.Warnings() {
+				console.MessageUxItem(ctx, &ux.WarningMessage{Description: warning.Message})
+			}
+
+			// Tools parse the error from stderr, so it's written instead of the error text, and stdout stays clean
+			if err != nil && rootOptions.JsonErrors {
+				correlationId := ""
+				if actionResult != nil {
+					if traceId, parseErr := trace.TraceIDFromHex(actionResult.TraceID); parseErr == nil {
+						correlationId = traceId.String()
+					}
+				}
+
+				if writeErr := internalcmd.WriteJsonError(cmd.ErrOrStderr(), err, correlationId); writeErr != nil {
+					log.Printf("failed to write json error: %v", writeErr)
+				}
+
+				console.StopSpinner(ctx, "", input.Step)
+				return
+			}
+
 			var displayResult *ux.ActionResult
 			if actionResult != nil && actionResult.Message != nil {
 				displayResult = &ux.ActionResult{
@@ -369,6 +397,26 @@ func (cb *CobraBuilder) bindCommand(cmd *cobra.Command, descriptor *actions.Acti
 	return nil
 }
 
+// Runs the action through the middleware registered for the descriptor and its parents, resolving the middleware
+// components from the container of the command
+func (cb *CobraBuilder) runAction(
+	ctx context.Context,
+	cmdContainer *ioc.NestedContainer,
+	descriptor *actions.ActionDescriptor,
+	runOptions *middleware.Options,
+	action actions.Action,
+) (*actions.ActionResult, error) {
+	middlewareRunner := middleware.NewMiddlewareRunner(cmdContainer)
+	if err := cb.registerMiddleware(middlewareRunner, descriptor); err != nil {
+		return nil, err
+	}
+
+	// Set the container that should be used for resolving middleware components
+	runOptions.WithContainer(cmdContainer)
+
+	return middlewareRunner.RunAction(ctx, runOptions, action)
+}
+
 // Registers all middleware components for the current command and any parent descriptors
 // Middleware components are insure to run in the order that they were registered from the
 // root registration, down through action groups and ultimately individual actions
@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
 
@@ -286,6 +288,40 @@ func Test_RunDocsAndHelpFlow(t *testing.T) {
 	require.Equal(t, "", calledUrl)
 }
 
+func Test_RunActionWithJsonErrors(t *testing.T) {
+	container := ioc.NewNestedContainer(nil)
+	registerCommonDependencies(container)
+	testCtx := mocks.NewMockContext(context.Background())
+	container.MustRegisterSingleton(func() input.Console {
+		return testCtx.Console
+	})
+	ioc.RegisterInstance(container, &internal.GlobalCommandOptions{JsonErrors: true})
+
+	root := actions.NewActionDescriptor("root", &actions.ActionDescriptorOptions{
+		Command:        &cobra.Command{SilenceUsage: true},
+		ActionResolver: newTestAction,
+		FlagsResolver:  newTestFlags,
+	})
+
+	cmd, err := NewCobraBuilder(container).BuildCommand(root)
+	require.NoError(t, err)
+
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	// The test action fails when the flag isn't set
+	cmd.SetArgs([]string{})
+	err = cmd.ExecuteContext(context.Background())
+	require.EqualError(t, err, "flag was not set")
+
+	var jsonErr map[string]any
+	require.NoError(t, json.Unmarshal(stderr.Bytes(), &jsonErr))
+	require.Equal(t, "user", jsonErr["category"])
+	require.Equal(t, "flag was not set", jsonErr["message"])
+	require.Empty(t, stdout.String())
+	require.Empty(t, testCtx.Console.Output())
+}
+
 func setup(container *ioc.NestedContainer) {
 	registerCommonDependencies(container)
 	globalOptions := &internal.GlobalCommandOptions{
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -13,7 +14,10 @@ import (
 	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/pkg/alpha"
 	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/lazy"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
 	"github.com/spf13/cobra"
@@ -154,6 +158,19 @@ $ azd config set defaults.location eastus`,
 		FlagsResolver:  newConfigResetFlags,
 	})
 
+	group.Add("list-paths", &actions.ActionDescriptorOptions{
+		Command: &cobra.Command{
+			Short: "Show the paths of the configuration, project, environments and caches used by azd.",
+			Long: heredoc.Doc(`
+				Show the paths of the files and directories azd reads its configuration and state from, like the user
+				configuration, the project configuration, the environments and the caches, and whether they exist.`),
+			Args: cobra.NoArgs,
+		},
+		ActionResolver: newConfigListPathsAction,
+		OutputFormats:  []output.Format{output.JsonFormat, output.TableFormat},
+		DefaultFormat:  output.TableFormat,
+	})
+
 	group.Add("list-alpha", &actions.ActionDescriptorOptions{
 		Command: &cobra.Command{
 			Short: "Display the list of available features in alpha stage.",
@@ -443,6 +460,118 @@ func getCmdConfigHelpFooter(c *cobra.Command) string {
 	})
 }
 
+// azd config list-paths
+
+// configPath is a file or directory azd reads its configuration or state from.
+type configPath struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Exists bool   `json:"exists"`
+}
+
+type configListPathsAction struct {
+	lazyAzdContext *lazy.Lazy[*azdcontext.AzdContext]
+	formatter      output.Formatter
+	writer         io.Writer
+}
+
+func newConfigListPathsAction(
+	lazyAzdContext *lazy.Lazy[*azdcontext.AzdContext],
+	formatter output.Formatter,
+	writer io.Writer,
+) actions.Action {
+	return &configListPathsAction{
+		lazyAzdContext: lazyAzdContext,
+		formatter:      formatter,
+		writer:         writer,
+	}
+}
+
+func (a *configListPathsAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	paths, err := a.paths()
+	if err != nil {
+		return nil, err
+	}
+
+	if a.formatter.Kind() == output.TableFormat {
+		err = a.formatter.Format(paths, a.writer, output.TableFormatterOptions{
+			Columns: []output.Column{
+				{
+					Heading:       "NAME",
+					ValueTemplate: "{{.Name}}",
+				},
+				{
+					Heading:       "PATH",
+					ValueTemplate: "{{.Path}}",
+				},
+				{
+					Heading:       "EXISTS",
+					ValueTemplate: "{{.Exists}}",
+				},
+			},
+		})
+	} else {
+		err = a.formatter.Format(paths, a.writer, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// paths returns the paths of the user configuration and caches, and when run in a project, the paths of the project
+// configuration and environments.
+func (a *configListPathsAction) paths() ([]configPath, error) {
+	configDir, err := config.GetUserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("getting user config dir: %w", err)
+	}
+
+	paths := []configPath{
+		{Name: "User config directory", Path: configDir},
+		{Name: "User config", Path: filepath.Join(configDir, "config.json")},
+		{Name: "Auth", Path: filepath.Join(configDir, "auth")},
+		{Name: "Subscriptions cache", Path: filepath.Join(configDir, "subscriptions.cache")},
+		{Name: "Locations cache", Path: filepath.Join(configDir, "locations.cache")},
+		{Name: "Tools", Path: filepath.Join(configDir, "bin")},
+	}
+
+	// The project paths are only available when run in a project
+	if azdCtx, err := a.lazyAzdContext.GetValue(); err == nil {
+		paths = append(paths,
+			configPath{Name: "Project config", Path: azdCtx.ProjectPath()},
+			configPath{Name: "Environments", Path: azdCtx.EnvironmentDirectory()},
+		)
+
+		defaultEnv, err := azdCtx.GetDefaultEnvironmentName()
+		if err != nil {
+			return nil, fmt.Errorf("getting default environment: %w", err)
+		}
+
+		if defaultEnv != "" {
+			envRoot := azdCtx.EnvironmentRoot(defaultEnv)
+			paths = append(paths,
+				configPath{
+					Name: fmt.Sprintf("Environment values (%s)", defaultEnv),
+					Path: filepath.Join(envRoot, environment.DotEnvFileName),
+				},
+				configPath{
+					Name: fmt.Sprintf("Environment config (%s)", defaultEnv),
+					Path: filepath.Join(envRoot, environment.ConfigFileName),
+				},
+			)
+		}
+	}
+
+	for i := range paths {
+		_, err := os.Stat(paths[i].Path)
+		paths[i].Exists = err == nil
+	}
+
+	return paths, nil
+}
+
 type configListAlphaAction struct {
 	alphaFeaturesManager *alpha.FeatureManager
 	console              input.Console
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/lazy"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ConfigListPaths(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("AZD_CONFIG_DIR", configDir)
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config.json"), []byte("{}"), osutil.PermissionFile))
+
+	runListPaths := func(t *testing.T, lazyAzdContext *lazy.Lazy[*azdcontext.AzdContext]) map[string]configPath {
+		buf := &bytes.Buffer{}
+		action := newConfigListPathsAction(lazyAzdContext, &output.JsonFormatter{}, buf)
+		_, err := action.Run(context.Background())
+		require.NoError(t, err)
+
+		var paths []configPath
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &paths))
+
+		byName := map[string]configPath{}
+		for _, path := range paths {
+			byName[path.Name] = path
+		}
+
+		return byName
+	}
+
+	t.Run("Project", func(t *testing.T) {
+		projectDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(projectDir, "azure.yaml"), nil, osutil.PermissionFile))
+
+		azdCtx := azdcontext.NewAzdContextWithDirectory(projectDir)
+		envRoot := azdCtx.EnvironmentRoot("dev")
+		require.NoError(t, os.MkdirAll(envRoot, osutil.PermissionDirectory))
+		require.NoError(t, os.WriteFile(filepath.Join(envRoot, ".env"), nil, osutil.PermissionFile))
+		require.NoError(t, azdCtx.SetDefaultEnvironmentName("dev"))
+
+		paths := runListPaths(t, lazy.From(azdCtx))
+
+		require.Equal(t, configPath{Name: "User config directory", Path: configDir, Exists: true},
+			paths["User config directory"])
+		require.Equal(t, configPath{Name: "User config", Path: filepath.Join(configDir, "config.json"), Exists: true},
+			paths["User config"])
+		require.Equal(t,
+			configPath{Name: "Subscriptions cache", Path: filepath.Join(configDir, "subscriptions.cache"), Exists: false},
+			paths["Subscriptions cache"])
+		require.Equal(t, configPath{Name: "Project config", Path: filepath.Join(projectDir, "azure.yaml"), Exists: true},
+			paths["Project config"])
+		require.Equal(t, configPath{Name: "Environments", Path: filepath.Join(projectDir, ".azure"), Exists: true},
+			paths["Environments"])
+		require.Equal(t,
+			configPath{Name: "Environment values (dev)", Path: filepath.Join(envRoot, ".env"), Exists: true},
+			paths["Environment values (dev)"])
+		require.Equal(t,
+			configPath{Name: "Environment config (dev)", Path: filepath.Join(envRoot, "config.json"), Exists: false},
+			paths["Environment config (dev)"])
+	})
+
+	t.Run("NoProject", func(t *testing.T) {
+		paths := runListPaths(t, lazy.NewLazy(func() (*azdcontext.AzdContext, error) {
+			return nil, azdcontext.ErrNoProject
+		}))
+
+		require.Contains(t, paths, "User config")
+		require.NotContains(t, paths, "Project config")
+		require.NotContains(t, paths, "Environments")
+	})
+}
@@ -2,12 +2,14 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
@@ -20,6 +22,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/internal/repository"
 	"github.com/azure/azure-dev/cli/azd/pkg/account"
 	"github.com/azure/azure-dev/cli/azd/pkg/alpha"
+	"github.com/azure/azure-dev/cli/azd/pkg/artifacts"
 	"github.com/azure/azure-dev/cli/azd/pkg/auth"
 	"github.com/azure/azure-dev/cli/azd/pkg/azapi"
 	"github.com/azure/azure-dev/cli/azd/pkg/azd"
@@ -41,6 +44,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/kubelogin"
 	"github.com/azure/azure-dev/cli/azd/pkg/kustomize"
 	"github.com/azure/azure-dev/cli/azd/pkg/lazy"
+	"github.com/azure/azure-dev/cli/azd/pkg/logging"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/pipeline"
 	"github.com/azure/azure-dev/cli/azd/pkg/platform"
@@ -49,10 +53,12 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/state"
 	"github.com/azure/azure-dev/cli/azd/pkg/templates"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/cosign"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/dotnet"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/github"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/gradle"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/javac"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/kubectl"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/maven"
@@ -105,7 +111,8 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 	container.MustRegisterScoped(func(
 		rootOptions *internal.GlobalCommandOptions,
 		formatter output.Formatter,
-		cmd *cobra.Command) input.Console {
+		cmd *cobra.Command,
+		serviceLocator ioc.ServiceLocator) input.Console {
 		writer := cmd.OutOrStdout()
 		// When using JSON formatting, we want to ensure we always write messages from the console to stderr.
 		if formatter != nil && formatter.Kind() == output.JsonFormat {
@@ -119,28 +126,51 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 		isTerminal := cmd.OutOrStdout() == os.Stdout &&
 			cmd.InOrStdin() == os.Stdin && input.IsTerminal(os.Stdout.Fd(), os.Stdin.Fd())
 
-		return input.NewConsole(rootOptions.NoPrompt, isTerminal, input.Writers{Output: writer}, input.ConsoleHandles{
+		// Embedders can render the prompts in their own UI by registering an input.Prompter in the root container. The
+		// IoC container doesn't support optional dependencies, so the prompter is resolved when registered.
+		var prompter input.Prompter
+		if err := serviceLocator.Resolve(&prompter); err != nil {
+			prompter = nil
+		}
+
+		return input.NewConsoleWithPrompter(rootOptions.NoPrompt, isTerminal, input.Writers{Output: writer}, input.ConsoleHandles{
 			Stdin:  cmd.InOrStdin(),
 			Stdout: cmd.OutOrStdout(),
 			Stderr: cmd.ErrOrStderr(),
-		}, formatter, nil)
+		}, formatter, prompter)
 	})
 
 	container.MustRegisterSingleton(
 		func(console input.Console, rootOptions *internal.GlobalCommandOptions) exec.CommandRunner {
+			// The environment and output of the commands are logged at the trace level
 			return exec.NewCommandRunner(
 				&exec.RunnerOptions{
 					Stdin:        console.Handles().Stdin,
 					Stdout:       console.Handles().Stdout,
 					Stderr:       console.Handles().Stderr,
-					DebugLogging: rootOptions.EnableDebugLogging,
+					DebugLogging: rootOptions.EnableDebugLogging || logging.Enabled(logging.LevelTrace),
 				})
 		},
 	)
 
-	client := createHttpClient()
-	ioc.RegisterInstance[httputil.HttpClient](container, client)
-	ioc.RegisterInstance[auth.HttpClient](container, client)
+	container.MustRegisterSingleton(func(userConfigManager config.UserConfigManager) (*http.Client, error) {
+		caBundlePath := os.Getenv(httputil.CaBundleEnvVarName)
+		if caBundlePath == "" {
+			if userConfig, err := userConfigManager.Load(); err != nil {
+				log.Printf("reading the CA bundle from the user config: %v", err)
+			} else if configPath, has := userConfig.GetString(httputil.CaBundleConfigPath); has {
+				caBundlePath = configPath
+			}
+		}
+
+		return createHttpClient(caBundlePath)
+	})
+	container.MustRegisterSingleton(func(client *http.Client) httputil.HttpClient {
+		return client
+	})
+	container.MustRegisterSingleton(func(client *http.Client) auth.HttpClient {
+		return client
+	})
 	container.MustRegisterSingleton(func() httputil.UserAgent {
 		return httputil.UserAgent(internal.UserAgent())
 	})
@@ -200,6 +230,7 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 			envManager environment.Manager,
 			lazyEnv *lazy.Lazy[*environment.Environment],
 			envFlags internal.EnvFlag,
+			rootOptions *internal.GlobalCommandOptions,
 		) (*environment.Environment, error) {
 			if azdContext == nil {
 				return nil, azdcontext.ErrNoProject
@@ -208,6 +239,18 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 			environmentName := envFlags.EnvironmentName
 			var err error
 
+			// The environment set with --env must exist, rather than being created like with -e
+			if rootOptions.EnvironmentName != "" && rootOptions.EnvironmentName == environmentName {
+				if _, err := envManager.Get(ctx, environmentName); errors.Is(err, environment.ErrNotFound) {
+					return nil, fmt.Errorf(
+						"environment '%s' set with --env does not exist, create it with `azd env new %s`",
+						environmentName,
+						environmentName)
+				} else if err != nil {
+					return nil, fmt.Errorf("loading environment: %w", err)
+				}
+			}
+
 			env, err := envManager.LoadOrInitInteractive(ctx, environmentName)
 			if err != nil {
 				return nil, fmt.Errorf("loading environment: %w", err)
@@ -220,7 +263,10 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 			return env, nil
 		},
 	)
-	container.MustRegisterScoped(func(lazyEnvManager *lazy.Lazy[environment.Manager]) environment.EnvironmentResolver {
+	container.MustRegisterScoped(func(
+		lazyEnvManager *lazy.Lazy[environment.Manager],
+		rootOptions *internal.GlobalCommandOptions,
+	) environment.EnvironmentResolver {
 		return func(ctx context.Context) (*environment.Environment, error) {
 			azdCtx, err := azdcontext.NewAzdContext()
 			if err != nil {
@@ -231,6 +277,10 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 				return nil, err
 			}
 
+			if rootOptions.EnvironmentName != "" {
+				defaultEnv = rootOptions.EnvironmentName
+			}
+
 			// We need to lazy load the environment manager since it depends on azd context
 			envManager, err := lazyEnvManager.GetValue()
 			if err != nil {
@@ -308,6 +358,42 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 		return remoteStateConfig, nil
 	})
 
+	container.MustRegisterSingleton(func(
+		lazyProjectConfig *lazy.Lazy[*project.ProjectConfig],
+		userConfigManager config.UserConfigManager,
+	) (*artifacts.Config, error) {
+		var artifactsConfig *artifacts.Config
+
+		userConfig, err := userConfigManager.Load()
+		if err != nil {
+			return nil, fmt.Errorf("loading user config: %w", err)
+		}
+
+		// Lookup the artifact store config in the following precedence:
+		// 1. Project azure.yaml
+		// 2. User configuration
+		projectConfig, _ := lazyProjectConfig.GetValue()
+		if projectConfig != nil && projectConfig.Artifacts != nil {
+			artifactsConfig = projectConfig.Artifacts
+		} else {
+			if _, err := userConfig.GetSection("artifacts", &artifactsConfig); err != nil {
+				return nil, fmt.Errorf("getting artifact store config: %w", err)
+			}
+		}
+
+		return artifactsConfig, nil
+	})
+
+	// The artifact store is optional, and only resolved when packages are uploaded or deployed from a store
+	container.MustRegisterScoped(func(
+		serviceLocator ioc.ServiceLocator,
+		artifactsConfig *artifacts.Config,
+	) *lazy.Lazy[artifacts.Store] {
+		return lazy.NewLazy(func() (artifacts.Store, error) {
+			return artifacts.ResolveStore(serviceLocator, artifactsConfig)
+		})
+	})
+
 	// Lazy loads an existing environment, erroring out if not available
 	// One can repeatedly call GetValue to wait until the environment is available.
 	container.MustRegisterScoped(
@@ -477,7 +563,6 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 		clientOptionsBuilderFactory *azsdk.ClientOptionsBuilderFactory,
 	) *azcore.ClientOptions {
 		return clientOptionsBuilderFactory.NewClientOptionsBuilder().
-			WithPerCallPolicy(azsdk.NewMsCorrelationPolicy()).
 			BuildCoreClientOptions()
 	})
 
@@ -485,7 +570,7 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 		clientOptionsBuilderFactory *azsdk.ClientOptionsBuilderFactory,
 	) *arm.ClientOptions {
 		return clientOptionsBuilderFactory.NewClientOptionsBuilder().
-			WithPerCallPolicy(azsdk.NewMsCorrelationPolicy()).
+			WithRetryPolicy(azsdk.NewArmRetryPolicy(nil)).
 			BuildArmClientOptions()
 	})
 
@@ -503,8 +588,44 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 	container.MustRegisterScoped(project.NewProjectManager)
 	// Currently caches manifest across command executions
 	container.MustRegisterSingleton(project.NewDotNetImporter)
-	container.MustRegisterScoped(project.NewImportManager)
+	container.MustRegisterScoped(func(
+		dotNetImporter *project.DotNetImporter,
+		console input.Console,
+		envFlags internal.EnvFlag,
+		lazyAzdContext *lazy.Lazy[*azdcontext.AzdContext],
+	) *project.ImportManager {
+		// The services disabled for the environment are excluded from the ones commands run on. Only the name of the
+		// environment is read, so commands which don't need an environment don't prompt for one.
+		return project.NewImportManagerForEnvironment(dotNetImporter, console, func() string {
+			if envFlags.EnvironmentName != "" {
+				return envFlags.EnvironmentName
+			}
+
+			azdCtx, err := lazyAzdContext.GetValue()
+			if err != nil {
+				return ""
+			}
+
+			envName, err := azdCtx.GetDefaultEnvironmentName()
+			if err != nil {
+				log.Printf("reading the default environment to filter services: %v", err)
+			}
+
+			return envName
+		})
+	})
 	container.MustRegisterScoped(project.NewServiceManager)
+	container.MustRegisterScoped(project.NewConsoleProgressReporter)
+	container.MustRegisterSingleton(func(commandRunner exec.CommandRunner) *project.ServiceTargetRegistry {
+		registry := project.NewServiceTargetRegistry()
+		if configDir, err := config.GetUserConfigDir(); err != nil {
+			log.Printf("could not load service target plugins: %v", err)
+		} else {
+			registry.LoadPlugins(filepath.Join(configDir, project.PluginsDirectoryName), commandRunner)
+		}
+
+		return registry
+	})
 
 	// Even though the service manager is scoped based on its use of environment we can still
 	// register its internal cache as a singleton to ensure operation caching is consistent across all instances
@@ -562,7 +683,8 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 	container.MustRegisterSingleton(account.NewSubscriptionsService)
 	container.MustRegisterSingleton(account.NewManager)
 	container.MustRegisterSingleton(account.NewSubscriptionsManager)
-	container.MustRegisterSingleton(account.NewSubscriptionCredentialProvider)
+	// Scoped, like the tenant resolver below, so the tenants it caches never outlive the environment they came from.
+	container.MustRegisterScoped(account.NewSubscriptionCredentialProvider)
 	container.MustRegisterSingleton(azcli.NewManagedClustersService)
 	container.MustRegisterSingleton(azcli.NewAdService)
 	container.MustRegisterSingleton(azcli.NewContainerRegistryService)
@@ -571,8 +693,26 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 	container.MustRegisterScoped(project.NewContainerHelper)
 	container.MustRegisterSingleton(azcli.NewSpringService)
 
-	container.MustRegisterSingleton(func(subManager *account.SubscriptionsManager) account.SubscriptionTenantResolver {
-		return subManager
+	container.MustRegisterScoped(func(
+		subManager *account.SubscriptionsManager,
+		lazyEnv *lazy.Lazy[*environment.Environment],
+	) account.SubscriptionTenantResolver {
+		// Allow the environment to pin the tenant used for a given subscription, which is needed when the
+		// subscription lives in a different tenant than the one the account is resolved through.
+		return account.NewTenantOverrideResolver(subManager, func(ctx context.Context, subscriptionId string) (string, bool) {
+			env, err := lazyEnv.GetValue()
+			if err != nil {
+				return "", false
+			}
+
+			tenantId, has, err := account.TenantOverrideFromConfig(env.Config, subscriptionId)
+			if err != nil {
+				log.Printf("failed reading tenant override: %v", err)
+				return "", false
+			}
+
+			return tenantId, has
+		})
 	})
 
 	// Tools
@@ -594,6 +734,9 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 	})
 	container.MustRegisterSingleton(azapi.NewDeployments)
 	container.MustRegisterSingleton(azapi.NewDeploymentOperations)
+	container.MustRegisterSingleton(azapi.NewNameAvailabilityService)
+	container.MustRegisterSingleton(azapi.NewResourceTypeLocationsService)
+	container.MustRegisterSingleton(cosign.NewCosignCli)
 	container.MustRegisterSingleton(docker.NewDocker)
 	container.MustRegisterSingleton(dotnet.NewDotNetCli)
 	container.MustRegisterSingleton(git.NewGitCli)
@@ -601,6 +744,7 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 	container.MustRegisterSingleton(javac.NewCli)
 	container.MustRegisterSingleton(kubectl.NewKubectl)
 	container.MustRegisterSingleton(maven.NewMavenCli)
+	container.MustRegisterSingleton(gradle.NewGradleCli)
 	container.MustRegisterSingleton(kubelogin.NewCli)
 	container.MustRegisterSingleton(helm.NewCli)
 	container.MustRegisterSingleton(kustomize.NewCli)
@@ -651,6 +795,7 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 	}
 
 	container.MustRegisterNamedScoped(string(project.ServiceLanguageDocker), project.NewDockerProjectAsFrameworkService)
+	container.MustRegisterNamedScoped(project.GradleFrameworkName, project.NewGradleProject)
 
 	// Pipelines
 	container.MustRegisterScoped(pipeline.NewPipelineManager)
@@ -2,12 +2,18 @@ package cmd
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/account"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
 	"github.com/azure/azure-dev/cli/azd/pkg/ioc"
 	"github.com/azure/azure-dev/cli/azd/pkg/lazy"
 	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
 )
 
@@ -154,3 +160,63 @@ type testLazyComponent[T comparable] struct {
 type testConcreteComponent[T comparable] struct {
 	concrete T
 }
+
+func Test_HttpClient_CaBundle(t *testing.T) {
+	t.Setenv("AZD_CONFIG_DIR", t.TempDir())
+	container := ioc.NewNestedContainer(nil)
+	ioc.RegisterInstance(container, context.Background())
+	registerCommonDependencies(container)
+
+	// The CA bundle set in the environment is loaded by the client of azd requests
+	t.Setenv(httputil.CaBundleEnvVarName, filepath.Join(t.TempDir(), "missing.pem"))
+
+	var client httputil.HttpClient
+	err := container.Resolve(&client)
+	require.ErrorContains(t, err, "reading CA bundle")
+}
+
+func Test_SubscriptionTenantResolver_PerScope(t *testing.T) {
+	t.Setenv("AZD_CONFIG_DIR", t.TempDir())
+	container := ioc.NewNestedContainer(nil)
+	ioc.RegisterInstance(container, context.Background())
+	registerCommonDependencies(container)
+
+	ioc.RegisterInstance(container, &cobra.Command{})
+	ioc.RegisterInstance(container, &internal.GlobalCommandOptions{})
+
+	subscriptionId := "d0a01878-d7f8-41ce-a4bc-2ead16199965"
+
+	// Each scope loads its own environment, which pins the subscription to a different tenant
+	lookupTenant := func(tenantId string) (string, account.SubscriptionCredentialProvider) {
+		scope, err := container.NewScope()
+		require.NoError(t, err)
+
+		env := environment.New("test")
+		require.NoError(t, env.Config.Set(account.SubscriptionTenantsConfigPath, map[string]any{
+			subscriptionId: tenantId,
+		}))
+
+		var lazyEnv *lazy.Lazy[*environment.Environment]
+		require.NoError(t, scope.Resolve(&lazyEnv))
+		lazyEnv.SetValue(env)
+
+		var resolver account.SubscriptionTenantResolver
+		require.NoError(t, scope.Resolve(&resolver))
+
+		var credentialProvider account.SubscriptionCredentialProvider
+		require.NoError(t, scope.Resolve(&credentialProvider))
+
+		resolved, err := resolver.LookupTenant(context.Background(), subscriptionId)
+		require.NoError(t, err)
+		return resolved, credentialProvider
+	}
+
+	tenant1, provider1 := lookupTenant("fafbff54-b655-4648-98a2-dc3ada4df86e")
+	tenant2, provider2 := lookupTenant("e615e058-6ff1-46e6-ab20-a1d5efd0f68c")
+
+	require.Equal(t, "fafbff54-b655-4648-98a2-dc3ada4df86e", tenant1)
+	require.Equal(t, "e615e058-6ff1-46e6-ab20-a1d5efd0f68c", tenant2)
+
+	// The tenants cached by the credential provider are not shared across scopes
+	require.NotSame(t, provider1, provider2)
+}
@@ -8,11 +8,23 @@ package cmd
 import (
 	"net/http"
 
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
 	"github.com/benbjohnson/clock"
 )
 
-func createHttpClient() *http.Client {
-	return http.DefaultClient
+// createHttpClient returns the client of the azd HTTP requests, trusting the certificates of the PEM bundle at
+// caBundlePath in addition to the system ones. The proxy environment variables are honored.
+func createHttpClient(caBundlePath string) (*http.Client, error) {
+	if caBundlePath == "" {
+		return http.DefaultClient, nil
+	}
+
+	transport, err := httputil.NewTransport(caBundlePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{Transport: transport}, nil
 }
 
 func createClock() clock.Clock {
@@ -16,7 +16,9 @@ import (
 	"github.com/benbjohnson/clock"
 )
 
-func createHttpClient() *http.Client {
+// createHttpClient returns the client of the azd HTTP requests in record mode, which trusts any certificate, so the CA
+// bundle is ignored.
+func createHttpClient(caBundlePath string) (*http.Client, error) {
 	transport := http.DefaultTransport.(*http.Transport).Clone()
 	// Allow for self-signed certificates, which is what the recording proxy uses.
 	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
@@ -34,7 +36,7 @@ func createHttpClient() *http.Client {
 
 	http.DefaultClient.Transport = transport
 
-	return http.DefaultClient
+	return http.DefaultClient, nil
 }
 
 func createClock() clock.Clock {
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
 	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -21,6 +23,7 @@ import (
 type downFlags struct {
 	forceDelete bool
 	purgeDelete bool
+	tagged      bool
 	global      *internal.GlobalCommandOptions
 	internal.EnvFlag
 }
@@ -31,8 +34,15 @@ func (i *downFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOpt
 		&i.purgeDelete,
 		"purge",
 		false,
-		//nolint:lll
-		"Does not require confirmation before it permanently deletes resources that are soft-deleted by default (for example, key vaults).",
+		"Permanently deletes resources that are soft-deleted by default (for example, key vaults). "+
+			"Asks for confirmation unless --force is set.",
+	)
+	local.BoolVar(
+		&i.tagged,
+		"include-tagged",
+		false,
+		"Also deletes the resources tagged with the environment name outside of its resource groups. "+
+			"Environment names are not unique across projects, review the listed resources before confirming.",
 	)
 	i.EnvFlag.Bind(local, global)
 	i.global = global
@@ -89,6 +99,18 @@ func (a *downAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 
 	startTime := time.Now()
 
+	// Purging can't be undone, so it is never done with the default answer of its confirmation
+	if a.flags.purgeDelete && !a.flags.forceDelete && a.flags.global.NoPrompt {
+		return nil, &azcli.ErrorWithSuggestion{
+			Err:        errors.New("--purge requires a confirmation, which can't be asked with --no-prompt"),
+			Suggestion: "Set --force to permanently delete the soft-deleted resources without confirmation.",
+		}
+	}
+
+	if err := a.confirmAdoptedDelete(ctx); err != nil {
+		return nil, err
+	}
+
 	infra, err := a.importManager.ProjectInfrastructure(ctx, a.projectConfig)
 	if err != nil {
 		return nil, err
@@ -99,7 +121,8 @@ func (a *downAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 		return nil, fmt.Errorf("initializing provisioning manager: %w", err)
 	}
 
-	destroyOptions := provisioning.NewDestroyOptions(a.flags.forceDelete, a.flags.purgeDelete)
+	destroyOptions := provisioning.NewDestroyOptions(a.flags.forceDelete, a.flags.purgeDelete).
+		WithTaggedResources(a.flags.tagged)
 	if _, err := a.provisionManager.Destroy(ctx, destroyOptions); err != nil {
 		return nil, fmt.Errorf("deleting infrastructure: %w", err)
 	}
@@ -111,6 +134,36 @@ func (a *downAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 	}, nil
 }
 
+// confirmAdoptedDelete asks for confirmation before deleting the resources of an environment created from an existing
+// resource group, with 'azd env new --from-azure', since they were not provisioned by azd. The confirmation is required
+// even when --force is set.
+func (a *downAction) confirmAdoptedDelete(ctx context.Context) error {
+	resourceGroup, adopted := a.env.GetAdoptedResourceGroup()
+	if !adopted {
+		return nil
+	}
+
+	confirm, err := a.console.Confirm(ctx, input.ConsoleOptions{
+		Message: fmt.Sprintf(
+			"Environment '%s' was created from the existing resource group '%s', which resources were not "+
+				"provisioned by azd. Delete them anyway?",
+			a.env.Name(),
+			resourceGroup,
+		),
+		DefaultValue: false,
+	})
+	if err != nil {
+		return fmt.Errorf("confirming deletion of adopted resources: %w", err)
+	}
+
+	if !confirm {
+		return fmt.Errorf(
+			"deletion of the resources of adopted resource group '%s' was not confirmed", resourceGroup)
+	}
+
+	return nil
+}
+
 func getCmdDownHelpDescription(*cobra.Command) string {
 	return generateCmdHelpDescription(fmt.Sprintf(
 		"Delete Azure resources for an application. Running %s will not delete application"+
@@ -122,7 +175,9 @@ func getCmdDownHelpFooter(*cobra.Command) string {
 		"Delete all resources for an application." +
 			" You will be prompted to confirm your decision.": output.WithHighLightFormat("azd down"),
 		"Forcibly delete all applications resources without confirmation.": output.WithHighLightFormat("azd down --force"),
-		"Permanently delete resources that are soft-deleted by default," +
-			" without confirmation.": output.WithHighLightFormat("azd down --purge"),
+		"Permanently delete resources that are soft-deleted by default." +
+			" You will be prompted to confirm the purge.": output.WithHighLightFormat("azd down --purge"),
+		"Delete and permanently purge all resources without confirmation.": output.WithHighLightFormat(
+			"azd down --force --purge"),
 	})
 }
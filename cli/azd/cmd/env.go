@@ -4,13 +4,23 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
 	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/account"
+	"github.com/azure/azure-dev/cli/azd/pkg/cloud"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
@@ -18,6 +28,8 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
 	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -72,8 +84,10 @@ func envActions(root *actions.ActionDescriptor) *actions.ActionDescriptor {
 		Command:        newEnvGetValuesCmd(),
 		FlagsResolver:  newEnvGetValuesFlags,
 		ActionResolver: newEnvGetValuesAction,
-		OutputFormats:  []output.Format{output.JsonFormat, output.EnvVarsFormat},
-		DefaultFormat:  output.EnvVarsFormat,
+		OutputFormats: []output.Format{
+			output.JsonFormat, output.EnvVarsFormat, output.TableFormat, output.TsvFormat,
+		},
+		DefaultFormat: output.EnvVarsFormat,
 	})
 
 	return group
@@ -90,18 +104,61 @@ func newEnvSetCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "set <key> <value>",
 		Short: "Manage your environment settings.",
-		Args:  cobra.ExactArgs(2),
+		Args: func(cmd *cobra.Command, args []string) error {
+			// Values imported from a file replace the key and value arguments
+			if cmd.Flags().Changed("from-file") || cmd.Flags().Changed("from-json") {
+				if len(args) > 0 {
+					return errors.New("<key> and <value> can't be set together with --from-file or --from-json")
+				}
+				return nil
+			}
+
+			return cobra.ExactArgs(2)(cmd, args)
+		},
 	}
 }
 
 type envSetFlags struct {
 	internal.EnvFlag
-	global *internal.GlobalCommandOptions
+	global    *internal.GlobalCommandOptions
+	fromFile  string
+	fromJson  string
+	overwrite bool
+	secret    bool
 }
 
 func (f *envSetFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
 	f.EnvFlag.Bind(local, global)
 	f.global = global
+
+	local.StringVar(
+		&f.fromFile,
+		"from-file",
+		"",
+		"Imports the values of a dotenv file, like 'values.env', instead of setting a single value.",
+	)
+	local.StringVar(
+		&f.fromJson,
+		"from-json",
+		"",
+		"Imports the values of a JSON file with an object of keys and values, instead of setting a single value.",
+	)
+	local.BoolVar(
+		&f.overwrite,
+		"overwrite",
+		false,
+		"When importing values, replaces the values of keys already set in the environment rather than skipping them.",
+	)
+	local.BoolVar(
+		&f.secret,
+		"secret",
+		false,
+		fmt.Sprintf(
+			"Stores the value encrypted in the %s file of the environment, with the passphrase set in %s.",
+			environment.SecretDotEnvFileName,
+			environment.SecretPassphraseEnvVarName,
+		),
+	)
 }
 
 type envSetAction struct {
@@ -132,7 +189,19 @@ func newEnvSetAction(
 }
 
 func (e *envSetAction) Run(ctx context.Context) (*actions.ActionResult, error) {
-	e.env.DotenvSet(e.args[0], e.args[1])
+	if e.flags.fromFile != "" || e.flags.fromJson != "" {
+		if e.flags.secret {
+			return nil, errors.New("--secret can't be used together with --from-file or --from-json")
+		}
+
+		return e.importValues(ctx)
+	}
+
+	if e.flags.secret {
+		e.env.DotenvSetSecret(e.args[0], e.args[1])
+	} else {
+		e.env.DotenvSet(e.args[0], e.args[1])
+	}
 
 	if err := e.envManager.Save(ctx, e.env); err != nil {
 		return nil, fmt.Errorf("saving environment: %w", err)
@@ -141,6 +210,132 @@ func (e *envSetAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 	return nil, nil
 }
 
+// importValues sets the values of the file of --from-file or --from-json in the environment. Keys already set in the
+// environment are skipped, unless --overwrite is set.
+func (e *envSetAction) importValues(ctx context.Context) (*actions.ActionResult, error) {
+	if e.flags.fromFile != "" && e.flags.fromJson != "" {
+		return nil, errors.New("--from-file and --from-json can't be used together")
+	}
+
+	var values map[string]string
+	var err error
+	if e.flags.fromFile != "" {
+		values, err = readDotenvValues(e.flags.fromFile)
+	} else {
+		values, err = readJsonValues(e.flags.fromJson)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	existing := e.env.Dotenv()
+	toSet := map[string]string{}
+	added, updated, skipped := []string{}, []string{}, []string{}
+	for key, value := range values {
+		current, has := existing[key]
+		switch {
+		case !has:
+			added = append(added, key)
+		case current == value:
+			// Unchanged values are neither updated nor reported as skipped
+			continue
+		case e.flags.overwrite:
+			updated = append(updated, key)
+		default:
+			skipped = append(skipped, key)
+			continue
+		}
+
+		toSet[key] = value
+	}
+
+	if len(toSet) > 0 {
+		e.env.DotenvSetMany(toSet)
+		if err := e.envManager.Save(ctx, e.env); err != nil {
+			return nil, fmt.Errorf("saving environment: %w", err)
+		}
+	}
+
+	for _, report := range []struct {
+		message string
+		keys    []string
+	}{
+		{"Added", added},
+		{"Updated", updated},
+		{"Skipped, already set (use --overwrite to replace)", skipped},
+	} {
+		if len(report.keys) == 0 {
+			continue
+		}
+
+		slices.Sort(report.keys)
+		e.console.Message(ctx, fmt.Sprintf("%s: %s", report.message, strings.Join(report.keys, ", ")))
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: fmt.Sprintf(
+				"Imported %d of %d values into environment '%s'.", len(added)+len(updated), len(values), e.env.Name()),
+		},
+	}, nil
+}
+
+// cEnvKeyRegexp matches the keys of environment values, which must be valid environment variable names.
+var cEnvKeyRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// readDotenvValues reads the values of the dotenv file at path.
+func readDotenvValues(path string) (map[string]string, error) {
+	values, err := godotenv.Read(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading dotenv file '%s': %w", path, err)
+	}
+
+	for key := range values {
+		if !cEnvKeyRegexp.MatchString(key) {
+			return nil, fmt.Errorf("invalid key '%s' in dotenv file '%s', expected a valid environment variable name", key, path)
+		}
+	}
+
+	return values, nil
+}
+
+// readJsonValues reads the values of the JSON file at path, an object with string, number or boolean values.
+func readJsonValues(path string) (map[string]string, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading JSON file '%s': %w", path, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	decoder.UseNumber()
+
+	var object map[string]any
+	if err := decoder.Decode(&object); err != nil {
+		return nil, fmt.Errorf("reading JSON file '%s', expected an object of keys and values: %w", path, err)
+	}
+
+	values := make(map[string]string, len(object))
+	for key, value := range object {
+		if !cEnvKeyRegexp.MatchString(key) {
+			return nil, fmt.Errorf("invalid key '%s' in JSON file '%s', expected a valid environment variable name", key, path)
+		}
+
+		switch value := value.(type) {
+		case string:
+			values[key] = value
+		case json.Number:
+			values[key] = value.String()
+		case bool:
+			values[key] = strconv.FormatBool(value)
+		default:
+			return nil, fmt.Errorf(
+				"invalid value of key '%s' in JSON file '%s', expected a string, number or boolean", key, path)
+		}
+	}
+
+	return values, nil
+}
+
 func newEnvSelectCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "select <environment>",
@@ -191,26 +386,37 @@ func newEnvListCmd() *cobra.Command {
 }
 
 type envListAction struct {
-	envManager environment.Manager
-	azdCtx     *azdcontext.AzdContext
-	formatter  output.Formatter
-	writer     io.Writer
+	envManager     environment.Manager
+	localDataStore environment.LocalDataStore
+	azdCtx         *azdcontext.AzdContext
+	formatter      output.Formatter
+	writer         io.Writer
 }
 
 func newEnvListAction(
 	envManager environment.Manager,
+	localDataStore environment.LocalDataStore,
 	azdCtx *azdcontext.AzdContext,
 	formatter output.Formatter,
 	writer io.Writer,
 ) actions.Action {
 	return &envListAction{
-		envManager: envManager,
-		azdCtx:     azdCtx,
-		formatter:  formatter,
-		writer:     writer,
+		envManager:     envManager,
+		localDataStore: localDataStore,
+		azdCtx:         azdCtx,
+		formatter:      formatter,
+		writer:         writer,
 	}
 }
 
+// envListItem is an environment listed with `azd env list --output json`, with the metadata read from its local state.
+type envListItem struct {
+	*environment.Description
+	SubscriptionId string     `json:",omitempty"`
+	Location       string     `json:",omitempty"`
+	LastDeployTime *time.Time `json:",omitempty"`
+}
+
 func (e *envListAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 	envs, err := e.envManager.List(ctx)
 
@@ -242,7 +448,13 @@ func (e *envListAction) Run(ctx context.Context) (*actions.ActionResult, error)
 			Columns: columns,
 		})
 	} else {
-		err = e.formatter.Format(envs, e.writer, nil)
+		var items []envListItem
+		items, err = e.listItems(ctx, envs)
+		if err != nil {
+			return nil, err
+		}
+
+		err = e.formatter.Format(items, e.writer, nil)
 	}
 	if err != nil {
 		return nil, err
@@ -251,10 +463,42 @@ func (e *envListAction) Run(ctx context.Context) (*actions.ActionResult, error)
 	return nil, nil
 }
 
+// listItems reads the metadata of the local environments. Environments which only exist remotely are listed without
+// metadata, rather than being downloaded.
+func (e *envListAction) listItems(ctx context.Context, envs []*environment.Description) ([]envListItem, error) {
+	items := make([]envListItem, 0, len(envs))
+	for _, description := range envs {
+		item := envListItem{Description: description}
+
+		if description.HasLocal {
+			env, err := e.localDataStore.Get(ctx, description.Name)
+			if err != nil {
+				return nil, fmt.Errorf("reading environment '%s': %w", description.Name, err)
+			}
+
+			// The values of the .env file only, not the ones of the azd process which would be the same for all
+			values := env.Dotenv()
+			item.SubscriptionId = values[environment.SubscriptionIdEnvVarName]
+			item.Location = values[environment.LocationEnvVarName]
+
+			if lastDeployTime, has := env.GetLastDeployTime(); has {
+				item.LastDeployTime = &lastDeployTime
+			}
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
 type envNewFlags struct {
-	subscription string
-	location     string
-	global       *internal.GlobalCommandOptions
+	subscription  string
+	location      string
+	refresh       bool
+	fromAzure     bool
+	resourceGroup string
+	global        *internal.GlobalCommandOptions
 }
 
 func (f *envNewFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
@@ -265,6 +509,24 @@ func (f *envNewFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandO
 		"Name or ID of an Azure subscription to use for the new environment",
 	)
 	local.StringVarP(&f.location, "location", "l", "", "Azure location for the new environment")
+	local.BoolVar(
+		&f.refresh,
+		"refresh",
+		false,
+		"Reload the available subscriptions and locations from Azure instead of using cached values",
+	)
+	local.BoolVar(
+		&f.fromAzure,
+		"from-azure",
+		false,
+		"Create the environment from an existing resource group, set with --resource-group",
+	)
+	local.StringVar(
+		&f.resourceGroup,
+		"resource-group",
+		"",
+		"Name of the existing resource group to create the environment from, when --from-azure is set",
+	)
 
 	f.global = global
 }
@@ -289,6 +551,9 @@ func newEnvNewCmd() *cobra.Command {
 type envNewAction struct {
 	azdCtx     *azdcontext.AzdContext
 	envManager environment.Manager
+	subManager *account.SubscriptionsManager
+	azCli      azcli.AzCli
+	cloud      *cloud.Cloud
 	flags      *envNewFlags
 	args       []string
 	console    input.Console
@@ -297,6 +562,9 @@ type envNewAction struct {
 func newEnvNewAction(
 	azdCtx *azdcontext.AzdContext,
 	envManager environment.Manager,
+	subManager *account.SubscriptionsManager,
+	azCli azcli.AzCli,
+	cloud *cloud.Cloud,
 	flags *envNewFlags,
 	args []string,
 	console input.Console,
@@ -304,6 +572,9 @@ func newEnvNewAction(
 	return &envNewAction{
 		azdCtx:     azdCtx,
 		envManager: envManager,
+		subManager: subManager,
+		azCli:      azCli,
+		cloud:      cloud,
 		flags:      flags,
 		args:       args,
 		console:    console,
@@ -311,6 +582,30 @@ func newEnvNewAction(
 }
 
 func (en *envNewAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	if en.flags.refresh {
+		if err := en.subManager.ClearSubscriptions(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if en.flags.resourceGroup != "" && !en.flags.fromAzure {
+		return nil, errors.New("--resource-group can only be used with --from-azure")
+	}
+
+	// The resource group is read before creating the environment, to not leave an incomplete environment behind
+	var adoptedValues map[string]string
+	if en.flags.fromAzure {
+		if en.flags.subscription == "" || en.flags.resourceGroup == "" {
+			return nil, errors.New("--from-azure requires --subscription and --resource-group to be set")
+		}
+
+		values, err := en.adoptedResourceGroupValues(ctx)
+		if err != nil {
+			return nil, err
+		}
+		adoptedValues = values
+	}
+
 	environmentName := ""
 	if len(en.args) >= 1 {
 		environmentName = en.args[0]
@@ -327,6 +622,34 @@ func (en *envNewAction) Run(ctx context.Context) (*actions.ActionResult, error)
 		return nil, fmt.Errorf("creating new environment: %w", err)
 	}
 
+	if en.flags.fromAzure {
+		for key, value := range adoptedValues {
+			// An explicit --location takes precedence over the location of the resource group
+			if key == environment.LocationEnvVarName && en.flags.location != "" {
+				continue
+			}
+
+			env.DotenvSet(key, value)
+		}
+
+		if err := env.SetAdoptedResourceGroup(en.flags.resourceGroup); err != nil {
+			return nil, fmt.Errorf("marking environment as adopted: %w", err)
+		}
+
+		if err := en.envManager.Save(ctx, env); err != nil {
+			return nil, fmt.Errorf("saving environment: %w", err)
+		}
+
+		en.console.MessageUxItem(ctx, &ux.DoneMessage{
+			Message: fmt.Sprintf(
+				"Created environment %s from resource group %s, with %d values derived from its resources",
+				output.WithHighLightFormat(env.Name()),
+				output.WithHighLightFormat(en.flags.resourceGroup),
+				len(adoptedValues),
+			),
+		})
+	}
+
 	if err := en.azdCtx.SetDefaultEnvironmentName(env.Name()); err != nil {
 		return nil, fmt.Errorf("saving default environment: %w", err)
 	}
@@ -334,6 +657,85 @@ func (en *envNewAction) Run(ctx context.Context) (*actions.ActionResult, error)
 	return nil, nil
 }
 
+// adoptedResourceGroupValues reads the existing resource group set with --resource-group and its resources, returning
+// the environment values derived from them, like the outputs a provisioning of the resources would have set.
+func (en *envNewAction) adoptedResourceGroupValues(ctx context.Context) (map[string]string, error) {
+	subscriptionId := en.flags.subscription
+	groups, err := en.azCli.ListResourceGroup(ctx, subscriptionId, nil)
+	if err != nil {
+		return nil, fmt.Errorf("listing resource groups: %w", err)
+	}
+
+	groupIndex := slices.IndexFunc(groups, func(group azcli.AzCliResource) bool {
+		return strings.EqualFold(group.Name, en.flags.resourceGroup)
+	})
+	if groupIndex < 0 {
+		return nil, fmt.Errorf(
+			"resource group '%s' was not found in subscription '%s'", en.flags.resourceGroup, subscriptionId)
+	}
+
+	resources, err := en.azCli.ListResourceGroupResources(ctx, subscriptionId, groups[groupIndex].Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("listing resources of resource group '%s': %w", en.flags.resourceGroup, err)
+	}
+
+	return adoptedEnvValues(groups[groupIndex], resources, en.cloud), nil
+}
+
+// adoptedEnvValues derives the environment values of an environment adopting an existing resource group from the
+// group and its resources. Values of a resource type are only derived when the group has a single resource of the type,
+// since the resource they refer to would be ambiguous otherwise.
+func adoptedEnvValues(
+	group azcli.AzCliResource,
+	resources []azcli.AzCliResource,
+	cloud *cloud.Cloud,
+) map[string]string {
+	values := map[string]string{
+		environment.ResourceGroupEnvVarName: group.Name,
+		environment.LocationEnvVarName:      group.Location,
+	}
+
+	byType := map[string][]azcli.AzCliResource{}
+	for _, resource := range resources {
+		resourceType := strings.ToLower(resource.Type)
+		byType[resourceType] = append(byType[resourceType], resource)
+	}
+
+	single := func(resourceType string) (azcli.AzCliResource, bool) {
+		ofType := byType[strings.ToLower(resourceType)]
+		if len(ofType) != 1 {
+			return azcli.AzCliResource{}, false
+		}
+
+		return ofType[0], true
+	}
+
+	if registry, has := single("Microsoft.ContainerRegistry/registries"); has {
+		values[environment.ContainerRegistryEndpointEnvVarName] = fmt.Sprintf(
+			"%s.%s", registry.Name, cloud.ContainerRegistryEndpointSuffix)
+	}
+
+	if cluster, has := single("Microsoft.ContainerService/managedClusters"); has {
+		values[environment.AksClusterEnvVarName] = cluster.Name
+	}
+
+	if containerEnv, has := single("Microsoft.App/managedEnvironments"); has {
+		values["AZURE_CONTAINER_ENVIRONMENT_NAME"] = containerEnv.Name
+	}
+
+	if vault, has := single("Microsoft.KeyVault/vaults"); has {
+		values["AZURE_KEY_VAULT_NAME"] = vault.Name
+	}
+
+	if account, has := single("Microsoft.Storage/storageAccounts"); has {
+		values["AZURE_STORAGE_ACCOUNT_NAME"] = account.Name
+		values["AZURE_STORAGE_BLOB_ENDPOINT"] = fmt.Sprintf(
+			"https://%s.blob.%s/", account.Name, cloud.StorageEndpointSuffix)
+	}
+
+	return values
+}
+
 type envRefreshFlags struct {
 	hint   string
 	global *internal.GlobalCommandOptions
@@ -463,6 +865,7 @@ func (ef *envRefreshAction) Run(ctx context.Context) (*actions.ActionResult, err
 		return nil, fmt.Errorf("getting deployment: %w", err)
 	}
 
+	previousValues := ef.env.Dotenv()
 	if err := ef.provisionManager.UpdateEnvironment(ctx, getStateResult.State.Outputs); err != nil {
 		return nil, err
 	}
@@ -472,6 +875,9 @@ func (ef *envRefreshAction) Run(ctx context.Context) (*actions.ActionResult, err
 		if err != nil {
 			return nil, fmt.Errorf("writing deployment result in JSON format: %w", err)
 		}
+	} else {
+		changes := envValueChanges(previousValues, ef.env.Dotenv(), getStateResult.State.Outputs)
+		ef.showEnvValueChanges(ctx, changes)
 	}
 
 	servicesStable, err := ef.importManager.ServiceStable(ctx, ef.projectConfig)
@@ -503,6 +909,77 @@ func (ef *envRefreshAction) Run(ctx context.Context) (*actions.ActionResult, err
 	}, nil
 }
 
+// envValueChange is a value of the environment changed by a refresh.
+type envValueChange struct {
+	Key      string
+	Previous string
+	Value    string
+	// Whether the value didn't exist before the refresh
+	Added bool
+	// Whether the value is a secret, which is redacted when displayed
+	Secure bool
+}
+
+// envValueChanges returns the values of the environment set from outputs that changed, sorted by key.
+func envValueChanges(
+	previous map[string]string,
+	current map[string]string,
+	outputs map[string]provisioning.OutputParameter,
+) []envValueChange {
+	var changes []envValueChange
+	for key, param := range outputs {
+		value, has := current[key]
+		if !has {
+			continue
+		}
+
+		previousValue, existed := previous[key]
+		if existed && previousValue == value {
+			continue
+		}
+
+		changes = append(changes, envValueChange{
+			Key:      key,
+			Previous: previousValue,
+			Value:    value,
+			Added:    !existed,
+			Secure:   param.Secure,
+		})
+	}
+
+	slices.SortFunc(changes, func(a, b envValueChange) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	return changes
+}
+
+const redactedEnvValue = "<redacted>"
+
+// showEnvValueChanges displays the changes of a refresh, redacting secrets.
+func (ef *envRefreshAction) showEnvValueChanges(ctx context.Context, changes []envValueChange) {
+	if len(changes) == 0 {
+		ef.console.Message(ctx, "No environment values changed.")
+		return
+	}
+
+	lines := []string{"Changed environment values:"}
+	for _, change := range changes {
+		previous, value := change.Previous, change.Value
+		if change.Secure {
+			previous, value = redactedEnvValue, redactedEnvValue
+		}
+
+		if change.Added {
+			lines = append(lines, output.WithSuccessFormat("  + %s=%s", change.Key, value))
+		} else {
+			lines = append(lines, output.WithWarningFormat("  ~ %s: %s -> %s", change.Key, previous, value))
+		}
+	}
+
+	ef.console.Message(ctx, strings.Join(lines, "\n"))
+}
+
 func newEnvGetValuesFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *envGetValuesFlags {
 	flags := &envGetValuesFlags{}
 	flags.Bind(cmd.Flags(), global)
@@ -575,7 +1052,50 @@ func (eg *envGetValuesAction) Run(ctx context.Context) (*actions.ActionResult, e
 		return nil, fmt.Errorf("ensuring environment exists: %w", err)
 	}
 
-	return nil, eg.formatter.Format(env.Dotenv(), eg.writer, nil)
+	values := env.Dotenv()
+	switch eg.formatter.Kind() {
+	case output.TableFormat, output.TsvFormat:
+		valueColumn := output.Column{
+			Heading:       "VALUE",
+			ValueTemplate: "{{.Value}}",
+		}
+		if eg.formatter.Kind() == output.TableFormat {
+			// Tabs and line breaks would break the alignment of the table, they're escaped like the tsv format does
+			valueColumn.Transformer = output.EscapeTsvField
+		}
+
+		return nil, eg.formatter.Format(envValueRows(values), eg.writer, output.TableFormatterOptions{
+			Columns: []output.Column{
+				{
+					Heading:       "NAME",
+					ValueTemplate: "{{.Name}}",
+				},
+				valueColumn,
+			},
+		})
+	default:
+		return nil, eg.formatter.Format(values, eg.writer, nil)
+	}
+}
+
+// envValueRow is a value of an environment, listed by the table and tsv formats of 'azd env get-values'.
+type envValueRow struct {
+	Name  string
+	Value string
+}
+
+// envValueRows returns the values of an environment sorted by name, so the rows of tabular formats are stable.
+func envValueRows(values map[string]string) []envValueRow {
+	rows := make([]envValueRow, 0, len(values))
+	for name, value := range values {
+		rows = append(rows, envValueRow{Name: name, Value: value})
+	}
+
+	slices.SortFunc(rows, func(a, b envValueRow) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return rows
 }
 
 func getCmdEnvHelpDescription(*cobra.Command) string {
@@ -0,0 +1,537 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/alpha"
+	"github.com/azure/azure-dev/cli/azd/pkg/cloud"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockenv"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// refreshTestProvider is a provisioning provider whose state has the given outputs, like resources changed after the
+// last provisioning.
+type refreshTestProvider struct {
+	provisioning.Provider
+	outputs map[string]provisioning.OutputParameter
+}
+
+func (p *refreshTestProvider) Name() string {
+	return "refresh-test"
+}
+
+func (p *refreshTestProvider) Initialize(ctx context.Context, projectPath string, options provisioning.Options) error {
+	return nil
+}
+
+func (p *refreshTestProvider) State(
+	ctx context.Context,
+	options *provisioning.StateOptions,
+) (*provisioning.StateResult, error) {
+	return &provisioning.StateResult{
+		State: &provisioning.State{
+			Outputs: p.outputs,
+		},
+	}, nil
+}
+
+func Test_EnvRefresh_ShowsChanges(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	env := environment.NewWithValues("dev", map[string]string{
+		"API_URL":           "https://api-old.contoso.com",
+		"STORAGE_KEY":       "old-key",
+		"RESOURCE_GROUP":    "rg-dev",
+		"NOT_AN_OUTPUT_KEY": "value",
+	})
+
+	provider := &refreshTestProvider{
+		outputs: map[string]provisioning.OutputParameter{
+			"API_URL":        {Type: provisioning.ParameterTypeString, Value: "https://api-new.contoso.com"},
+			"STORAGE_KEY":    {Type: provisioning.ParameterTypeString, Value: "new-key", Secure: true},
+			"RESOURCE_GROUP": {Type: provisioning.ParameterTypeString, Value: "rg-dev"},
+			"REPLICAS":       {Type: provisioning.ParameterTypeNumber, Value: 3},
+		},
+	}
+	mockContext.Container.MustRegisterNamedSingleton(string(provisioning.Test), func() provisioning.Provider {
+		return provider
+	})
+
+	envManager := &mockenv.MockEnvManager{}
+	envManager.On("Save", mock.Anything, env).Return(nil)
+	envManager.On("EnvPath", env).Return("/project/.azure/dev/.env")
+
+	projectConfig := &project.ProjectConfig{
+		Name: "refresh",
+		Path: t.TempDir(),
+		Infra: provisioning.Options{
+			Provider: provisioning.Test,
+		},
+	}
+
+	importManager := project.NewImportManager(nil)
+	action := newEnvRefreshAction(
+		provisioning.NewManager(
+			mockContext.Container,
+			func() (provisioning.ProviderKind, error) { return provisioning.Test, nil },
+			envManager,
+			env,
+			mockContext.Console,
+			alpha.NewFeaturesManagerWithConfig(mockContext.Config),
+		),
+		projectConfig,
+		project.NewProjectManager(azdcontext.NewAzdContextWithDirectory(projectConfig.Path), nil, importManager),
+		env,
+		envManager,
+		&envRefreshFlags{},
+		mockContext.Console,
+		&output.NoneFormatter{},
+		nil,
+		importManager,
+	)
+
+	_, err := action.Run(*mockContext.Context)
+	require.NoError(t, err)
+
+	// Secrets are refreshed but redacted from the displayed changes
+	require.Equal(t, "https://api-new.contoso.com", env.Getenv("API_URL"))
+	require.Equal(t, "new-key", env.Getenv("STORAGE_KEY"))
+	require.Equal(t, "3", env.Getenv("REPLICAS"))
+	require.Equal(t, "value", env.Getenv("NOT_AN_OUTPUT_KEY"))
+	envManager.AssertCalled(t, "Save", mock.Anything, env)
+
+	consoleOutput := strings.Join(mockContext.Console.Output(), "\n")
+	require.Contains(t, consoleOutput, "Changed environment values:")
+	require.Contains(t, consoleOutput, "  ~ API_URL: https://api-old.contoso.com -> https://api-new.contoso.com")
+	require.Contains(t, consoleOutput, "  + REPLICAS=3")
+	require.Contains(t, consoleOutput, "  ~ STORAGE_KEY: <redacted> -> <redacted>")
+	require.NotContains(t, consoleOutput, "RESOURCE_GROUP")
+	require.NotContains(t, consoleOutput, "new-key")
+	require.NotContains(t, consoleOutput, "old-key")
+}
+
+func Test_EnvValueChanges(t *testing.T) {
+	outputs := map[string]provisioning.OutputParameter{
+		"B_CHANGED":   {Type: provisioning.ParameterTypeString},
+		"A_ADDED":     {Type: provisioning.ParameterTypeString, Secure: true},
+		"C_UNCHANGED": {Type: provisioning.ParameterTypeString},
+	}
+
+	changes := envValueChanges(
+		map[string]string{"B_CHANGED": "1", "C_UNCHANGED": "1"},
+		map[string]string{"A_ADDED": "secret", "B_CHANGED": "2", "C_UNCHANGED": "1"},
+		outputs,
+	)
+
+	require.Equal(t, []envValueChange{
+		{Key: "A_ADDED", Value: "secret", Added: true, Secure: true},
+		{Key: "B_CHANGED", Previous: "1", Value: "2"},
+	}, changes)
+
+	require.Empty(t, envValueChanges(map[string]string{"C_UNCHANGED": "1"}, map[string]string{"C_UNCHANGED": "1"}, outputs))
+}
+
+func Test_EnvNew_FromAzure(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	const subscriptionId = "SUBSCRIPTION_ID"
+	resourceGroupId := "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-existing"
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/resourcegroups")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armresources.ResourceGroupListResult{
+			Value: []*armresources.ResourceGroup{
+				{
+					ID:       to.Ptr(resourceGroupId),
+					Name:     to.Ptr("rg-existing"),
+					Type:     to.Ptr("Microsoft.Resources/resourceGroups"),
+					Location: to.Ptr("westus2"),
+				},
+			},
+		})
+	})
+
+	resource := func(resourceType string, name string) *armresources.GenericResourceExpanded {
+		return &armresources.GenericResourceExpanded{
+			ID:       to.Ptr(fmt.Sprintf("%s/providers/%s/%s", resourceGroupId, resourceType, name)),
+			Name:     to.Ptr(name),
+			Type:     to.Ptr(resourceType),
+			Location: to.Ptr("westus2"),
+		}
+	}
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet &&
+			strings.HasSuffix(request.URL.Path, "/resourceGroups/rg-existing/resources")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armresources.ResourceListResult{
+			Value: []*armresources.GenericResourceExpanded{
+				resource("Microsoft.ContainerRegistry/registries", "crcontoso"),
+				resource("Microsoft.KeyVault/vaults", "kv-contoso"),
+				resource("Microsoft.App/managedEnvironments", "cae-contoso"),
+				// Ambiguous, no values are derived from the storage accounts
+				resource("Microsoft.Storage/storageAccounts", "stcontoso1"),
+				resource("Microsoft.Storage/storageAccounts", "stcontoso2"),
+			},
+		})
+	})
+
+	env := environment.NewWithValues("dev", map[string]string{
+		environment.SubscriptionIdEnvVarName: subscriptionId,
+	})
+	envManager := &mockenv.MockEnvManager{}
+	envManager.On("Create", mock.Anything, environment.Spec{Name: "dev", Subscription: subscriptionId}).Return(env, nil)
+	envManager.On("Save", mock.Anything, env).Return(nil)
+
+	action := newEnvNewAction(
+		azdcontext.NewAzdContextWithDirectory(t.TempDir()),
+		envManager,
+		nil,
+		mockazcli.NewAzCliFromMockContext(mockContext),
+		cloud.AzurePublic(),
+		&envNewFlags{subscription: subscriptionId, fromAzure: true, resourceGroup: "rg-existing"},
+		[]string{"dev"},
+		mockContext.Console,
+	)
+
+	_, err := action.Run(*mockContext.Context)
+	require.NoError(t, err)
+	envManager.AssertCalled(t, "Save", mock.Anything, env)
+
+	require.Equal(t, "rg-existing", env.Getenv(environment.ResourceGroupEnvVarName))
+	require.Equal(t, "westus2", env.GetLocation())
+	require.Equal(t, "crcontoso.azurecr.io", env.Getenv(environment.ContainerRegistryEndpointEnvVarName))
+	require.Equal(t, "kv-contoso", env.Getenv("AZURE_KEY_VAULT_NAME"))
+	require.Equal(t, "cae-contoso", env.Getenv("AZURE_CONTAINER_ENVIRONMENT_NAME"))
+	require.Empty(t, env.Getenv("AZURE_STORAGE_ACCOUNT_NAME"))
+
+	resourceGroup, adopted := env.GetAdoptedResourceGroup()
+	require.True(t, adopted)
+	require.Equal(t, "rg-existing", resourceGroup)
+}
+
+func Test_EnvNew_FromAzure_RequiresResourceGroup(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	action := newEnvNewAction(
+		azdcontext.NewAzdContextWithDirectory(t.TempDir()),
+		&mockenv.MockEnvManager{},
+		nil,
+		mockazcli.NewAzCliFromMockContext(mockContext),
+		cloud.AzurePublic(),
+		&envNewFlags{subscription: "SUBSCRIPTION_ID", fromAzure: true},
+		[]string{"dev"},
+		mockContext.Console,
+	)
+
+	_, err := action.Run(*mockContext.Context)
+	require.ErrorContains(t, err, "--from-azure requires --subscription and --resource-group")
+}
+
+func Test_Down_AdoptedEnvironmentRequiresConfirmation(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.Console.WhenConfirm(func(options input.ConsoleOptions) bool {
+		return strings.Contains(options.Message, "existing resource group 'rg-existing'")
+	}).Respond(false)
+
+	env := environment.New("dev")
+	require.NoError(t, env.SetAdoptedResourceGroup("rg-existing"))
+
+	action := &downAction{
+		// Confirmation is required even when --force is set
+		flags:   &downFlags{forceDelete: true},
+		env:     env,
+		console: mockContext.Console,
+	}
+
+	_, err := action.Run(*mockContext.Context)
+	require.ErrorContains(t, err, "was not confirmed")
+}
+
+func Test_Down_PurgeNoPromptRequiresForce(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+
+	action := &downAction{
+		flags: &downFlags{
+			purgeDelete: true,
+			global:      &internal.GlobalCommandOptions{NoPrompt: true},
+		},
+		env:     environment.New("dev"),
+		console: mockContext.Console,
+	}
+
+	_, err := action.Run(*mockContext.Context)
+	require.ErrorContains(t, err, "--purge requires a confirmation")
+}
+
+func Test_EnvList_Json(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	azdCtx := azdcontext.NewAzdContextWithDirectory(t.TempDir())
+	localDataStore := environment.NewLocalFileDataStore(azdCtx, config.NewFileConfigManager(config.NewManager()))
+
+	envManager, err := environment.NewManager(mockContext.Container, azdCtx, mockContext.Console, localDataStore, nil)
+	require.NoError(t, err)
+
+	deployTime := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	dev := environment.NewWithValues("dev", map[string]string{
+		environment.SubscriptionIdEnvVarName: "DEV_SUBSCRIPTION_ID",
+		environment.LocationEnvVarName:       "eastus2",
+	})
+	require.NoError(t, dev.SetLastDeployTime(deployTime))
+	require.NoError(t, localDataStore.Save(*mockContext.Context, dev))
+
+	prod := environment.NewWithValues("prod", map[string]string{
+		environment.SubscriptionIdEnvVarName: "PROD_SUBSCRIPTION_ID",
+	})
+	require.NoError(t, localDataStore.Save(*mockContext.Context, prod))
+	require.NoError(t, azdCtx.SetDefaultEnvironmentName("dev"))
+
+	var buf bytes.Buffer
+	action := newEnvListAction(envManager, localDataStore, azdCtx, &output.JsonFormatter{}, &buf)
+	_, err = action.Run(*mockContext.Context)
+	require.NoError(t, err)
+
+	var envs []map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &envs))
+	require.Len(t, envs, 2)
+
+	require.Equal(t, "dev", envs[0]["Name"])
+	require.Equal(t, true, envs[0]["IsDefault"])
+	require.Equal(t, "DEV_SUBSCRIPTION_ID", envs[0]["SubscriptionId"])
+	require.Equal(t, "eastus2", envs[0]["Location"])
+	require.Equal(t, "2024-05-01T10:30:00Z", envs[0]["LastDeployTime"])
+
+	require.Equal(t, "prod", envs[1]["Name"])
+	require.Equal(t, false, envs[1]["IsDefault"])
+	require.Equal(t, "PROD_SUBSCRIPTION_ID", envs[1]["SubscriptionId"])
+	require.NotContains(t, envs[1], "Location")
+	require.NotContains(t, envs[1], "LastDeployTime")
+}
+
+func Test_EnvGetValues_Formats(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	azdCtx := azdcontext.NewAzdContextWithDirectory(t.TempDir())
+	localDataStore := environment.NewLocalFileDataStore(azdCtx, config.NewFileConfigManager(config.NewManager()))
+
+	envManager, err := environment.NewManager(mockContext.Container, azdCtx, mockContext.Console, localDataStore, nil)
+	require.NoError(t, err)
+
+	dev := environment.NewWithValues("dev", map[string]string{
+		"SQL_CONN": "Server=sql;Password=\"p@ss\tword\"",
+		"MOTD":     "line one\nline two",
+		"APP_PATH": `C:\app`,
+	})
+	require.NoError(t, localDataStore.Save(*mockContext.Context, dev))
+	require.NoError(t, azdCtx.SetDefaultEnvironmentName("dev"))
+
+	getValues := func(t *testing.T, formatter output.Formatter) string {
+		var buf bytes.Buffer
+		action := newEnvGetValuesAction(
+			azdCtx, envManager, mockContext.Console, formatter, &buf, &envGetValuesFlags{})
+		_, err := action.Run(*mockContext.Context)
+		require.NoError(t, err)
+		return buf.String()
+	}
+
+	t.Run("Tsv", func(t *testing.T) {
+		require.Equal(t,
+			"NAME\tVALUE\n"+
+				"APP_PATH\tC:\\\\app\n"+
+				"AZURE_ENV_NAME\tdev\n"+
+				"MOTD\tline one\\nline two\n"+
+				"SQL_CONN\tServer=sql;Password=\"p@ss\\tword\"\n",
+			getValues(t, &output.TsvFormatter{}))
+	})
+
+	t.Run("Table", func(t *testing.T) {
+		lines := strings.Split(strings.TrimSuffix(getValues(t, &output.TableFormatter{}), "\n"), "\n")
+		require.Len(t, lines, 5)
+		require.Regexp(t, `^NAME\s+VALUE$`, lines[0])
+		require.Regexp(t, `^APP_PATH\s+C:\\\\app$`, lines[1])
+		require.Regexp(t, `^MOTD\s+line one\\nline two$`, lines[3])
+		require.Regexp(t, `^SQL_CONN\s+Server=sql;Password="p@ss\\tword"$`, lines[4])
+	})
+
+	t.Run("Json", func(t *testing.T) {
+		var values map[string]string
+		require.NoError(t, json.Unmarshal([]byte(getValues(t, &output.JsonFormatter{})), &values))
+		require.Equal(t, "Server=sql;Password=\"p@ss\tword\"", values["SQL_CONN"])
+		require.Equal(t, "line one\nline two", values["MOTD"])
+		require.Equal(t, `C:\app`, values["APP_PATH"])
+	})
+}
+
+func Test_EnvSet_Import(t *testing.T) {
+	tests := []struct {
+		name            string
+		flags           func(dir string) *envSetFlags
+		expectedValues  map[string]string
+		expectedOutput  []string
+		expectedImports string
+	}{
+		{
+			name: "Dotenv",
+			flags: func(dir string) *envSetFlags {
+				return &envSetFlags{fromFile: filepath.Join(dir, "values.env")}
+			},
+			expectedValues: map[string]string{
+				"API_URL":   "https://api.contoso.com",
+				"LOG_LEVEL": "debug",
+				"REPLICAS":  "3",
+				"FEATURES":  "search, chat",
+			},
+			expectedOutput: []string{
+				"Added: FEATURES, REPLICAS",
+				"Skipped, already set (use --overwrite to replace): LOG_LEVEL",
+			},
+			expectedImports: "Imported 2 of 4 values into environment 'dev'.",
+		},
+		{
+			name: "DotenvOverwrite",
+			flags: func(dir string) *envSetFlags {
+				return &envSetFlags{fromFile: filepath.Join(dir, "values.env"), overwrite: true}
+			},
+			expectedValues: map[string]string{
+				"API_URL":   "https://api.contoso.com",
+				"LOG_LEVEL": "info",
+				"REPLICAS":  "3",
+				"FEATURES":  "search, chat",
+			},
+			expectedOutput: []string{
+				"Added: FEATURES, REPLICAS",
+				"Updated: LOG_LEVEL",
+			},
+			expectedImports: "Imported 3 of 4 values into environment 'dev'.",
+		},
+		{
+			name: "Json",
+			flags: func(dir string) *envSetFlags {
+				return &envSetFlags{fromJson: filepath.Join(dir, "values.json")}
+			},
+			expectedValues: map[string]string{
+				"API_URL":   "https://api.contoso.com",
+				"LOG_LEVEL": "debug",
+				"REPLICAS":  "3",
+				"ENABLED":   "true",
+				"RATIO":     "0.5",
+			},
+			expectedOutput: []string{
+				"Added: ENABLED, RATIO, REPLICAS",
+				"Skipped, already set (use --overwrite to replace): LOG_LEVEL",
+			},
+			expectedImports: "Imported 3 of 5 values into environment 'dev'.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			dotenv := "API_URL=https://api.contoso.com\nLOG_LEVEL=info\n# scaled out\nREPLICAS=3\nFEATURES='search, chat'\n"
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "values.env"), []byte(dotenv), osutil.PermissionFile))
+			json := `{"API_URL": "https://api.contoso.com", "LOG_LEVEL": "info", "REPLICAS": 3, "ENABLED": true, "RATIO": 0.5}`
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "values.json"), []byte(json), osutil.PermissionFile))
+
+			mockContext := mocks.NewMockContext(context.Background())
+			env := environment.NewWithValues("dev", map[string]string{
+				"API_URL":   "https://api.contoso.com",
+				"LOG_LEVEL": "debug",
+			})
+			envManager := &mockenv.MockEnvManager{}
+			envManager.On("Save", mock.Anything, env).Return(nil)
+
+			action := newEnvSetAction(nil, env, envManager, mockContext.Console, tt.flags(dir), nil)
+			result, err := action.Run(*mockContext.Context)
+			require.NoError(t, err)
+
+			require.Equal(t, tt.expectedValues, env.Dotenv())
+			require.Equal(t, tt.expectedOutput, mockContext.Console.Output())
+			require.Equal(t, tt.expectedImports, result.Message.Header)
+			envManager.AssertCalled(t, "Save", mock.Anything, env)
+		})
+	}
+}
+
+func Test_EnvSet_ImportInvalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		file          string
+		contents      string
+		expectedError string
+	}{
+		{
+			name:          "DotenvSyntax",
+			file:          "values.env",
+			contents:      "API_URL=https://api.contoso.com\nnot a value\n",
+			expectedError: "reading dotenv file",
+		},
+		{
+			name:          "DotenvKey",
+			file:          "values.env",
+			contents:      "API URL=https://api.contoso.com\n",
+			expectedError: "invalid key 'API URL' in dotenv file",
+		},
+		{
+			name:          "JsonArray",
+			file:          "values.json",
+			contents:      `["API_URL"]`,
+			expectedError: "expected an object of keys and values",
+		},
+		{
+			name:          "JsonNested",
+			file:          "values.json",
+			contents:      `{"API": {"URL": "https://api.contoso.com"}}`,
+			expectedError: "invalid value of key 'API' in JSON file",
+		},
+		{
+			name:          "JsonKey",
+			file:          "values.json",
+			contents:      `{"1API_URL": "https://api.contoso.com"}`,
+			expectedError: "invalid key '1API_URL' in JSON file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			require.NoError(t, os.WriteFile(path, []byte(tt.contents), osutil.PermissionFile))
+
+			flags := &envSetFlags{fromFile: path}
+			if filepath.Ext(path) == ".json" {
+				flags = &envSetFlags{fromJson: path}
+			}
+
+			mockContext := mocks.NewMockContext(context.Background())
+			env := environment.NewWithValues("dev", map[string]string{})
+			envManager := &mockenv.MockEnvManager{}
+
+			action := newEnvSetAction(nil, env, envManager, mockContext.Console, flags, nil)
+			_, err := action.Run(*mockContext.Context)
+			require.ErrorContains(t, err, tt.expectedError)
+			require.Empty(t, env.Dotenv())
+			envManager.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -3,6 +3,8 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
 	"github.com/azure/azure-dev/cli/azd/internal"
@@ -14,6 +16,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
 	"github.com/azure/azure-dev/cli/azd/pkg/project"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -118,14 +121,21 @@ func (hra *hooksRunAction) Run(ctx context.Context) (*actions.ActionResult, erro
 
 	// Validate service name
 	if hra.flags.service != "" {
-		if has, err := hra.importManager.HasService(ctx, hra.projectConfig, hra.flags.service); err != nil {
+		if err := hra.importManager.ValidateServiceName(ctx, hra.projectConfig, hra.flags.service); err != nil {
 			return nil, err
-		} else if !has {
-			return nil, fmt.Errorf("service name '%s' doesn't exist", hra.flags.service)
 		}
 	}
 
-	// Project level hooks
+	stableServices, err := hra.importManager.ServiceStable(ctx, hra.projectConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := hra.validateHookName(hookName, stableServices); err != nil {
+		return nil, err
+	}
+
+	// Project level hooks, which may run for each service
 	if err := hra.processHooks(
 		ctx,
 		hra.projectConfig.Path,
@@ -133,27 +143,25 @@ func (hra *hooksRunAction) Run(ctx context.Context) (*actions.ActionResult, erro
 		fmt.Sprintf("Running %s command hook for project", hookName),
 		fmt.Sprintf("Project: %s Hook Output", hookName),
 		hra.projectConfig.Hooks,
+		project.HookServices(stableServices),
 		false,
 	); err != nil {
 		return nil, err
 	}
 
-	stableServices, err := hra.importManager.ServiceStable(ctx, hra.projectConfig)
-	if err != nil {
-		return nil, err
-	}
-
 	// Service level hooks
 	for _, service := range stableServices {
 		skip := hra.flags.service != "" && service.Name != hra.flags.service
 
+		// Service hooks run from the service directory, like in the service lifecycle
 		if err := hra.processHooks(
 			ctx,
-			service.RelativePath,
+			service.Path(),
 			hookName,
 			fmt.Sprintf("Running %s service hook for %s", hookName, service.Name),
 			fmt.Sprintf("%s: %s hook output", service.Name, hookName),
 			service.Hooks,
+			nil,
 			skip,
 		); err != nil {
 			return nil, err
@@ -167,6 +175,59 @@ func (hra *hooksRunAction) Run(ctx context.Context) (*actions.ActionResult, erro
 	}, nil
 }
 
+// validateHookName checks that the hook is defined by the project or by the services it runs for, listing the available
+// hooks otherwise.
+func (hra *hooksRunAction) validateHookName(hookName string, services []*project.ServiceConfig) error {
+	// The names of the hooks, with where they are defined: the project first, then the services
+	available := map[string][]string{}
+	for name := range hra.projectConfig.Hooks {
+		available[name] = append(available[name], "project")
+	}
+
+	for _, service := range services {
+		if hra.flags.service != "" && service.Name != hra.flags.service {
+			continue
+		}
+
+		for name := range service.Hooks {
+			available[name] = append(available[name], service.Name)
+		}
+	}
+
+	if _, has := available[hookName]; has {
+		return nil
+	}
+
+	scope := "the project or its services"
+	if hra.flags.service != "" {
+		scope = fmt.Sprintf("the project or service '%s'", hra.flags.service)
+	}
+
+	err := fmt.Errorf("hook '%s' is not defined for %s", hookName, scope)
+	if len(available) == 0 {
+		return &azcli.ErrorWithSuggestion{
+			Err:        err,
+			Suggestion: "No hooks are defined. Add hooks to the project or to its services in azure.yaml.",
+		}
+	}
+
+	names := make([]string, 0, len(available))
+	for name := range available {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("  - %s (%s)", name, strings.Join(available[name], ", ")))
+	}
+
+	return &azcli.ErrorWithSuggestion{
+		Err:        err,
+		Suggestion: fmt.Sprintf("Available hooks:\n%s", strings.Join(lines, "\n")),
+	}
+}
+
 func (hra *hooksRunAction) processHooks(
 	ctx context.Context,
 	cwd string,
@@ -174,6 +235,7 @@ func (hra *hooksRunAction) processHooks(
 	spinnerMessage string,
 	previewMessage string,
 	hooks map[string]*ext.HookConfig,
+	services []ext.HookService,
 	skip bool,
 ) error {
 	hra.console.ShowSpinner(ctx, spinnerMessage, input.Step)
@@ -195,7 +257,7 @@ func (hra *hooksRunAction) processHooks(
 		return err
 	}
 
-	err := hra.execHook(ctx, previewMessage, cwd, hookType, commandName, hook)
+	err := hra.execHook(ctx, previewMessage, cwd, hookType, commandName, hook, services)
 	if err != nil {
 		hra.console.StopSpinner(ctx, spinnerMessage, input.StepFailed)
 		return fmt.Errorf("failed running hook %s, %w", hookName, err)
@@ -214,6 +276,7 @@ func (hra *hooksRunAction) execHook(
 	hookType ext.HookType,
 	commandName string,
 	hook *ext.HookConfig,
+	services []ext.HookService,
 ) error {
 	hookName := string(hookType) + commandName
 
@@ -222,7 +285,8 @@ func (hra *hooksRunAction) execHook(
 	}
 
 	hooksManager := ext.NewHooksManager(cwd)
-	hooksRunner := ext.NewHooksRunner(hooksManager, hra.commandRunner, hra.envManager, hra.console, cwd, hooks, hra.env)
+	hooksRunner := ext.NewHooksRunner(hooksManager, hra.commandRunner, hra.envManager, hra.console, cwd, hooks, hra.env).
+		WithServices(services)
 
 	previewer := hra.console.ShowPreviewer(ctx, &input.ShowPreviewerOptions{
 		Prefix:       "  ",
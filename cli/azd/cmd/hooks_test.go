@@ -0,0 +1,178 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/ext"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockenv"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newHooksRunTestProject(t *testing.T) *project.ProjectConfig {
+	projectPath := t.TempDir()
+	projectConfig := &project.ProjectConfig{
+		Name: "hooks",
+		Path: projectPath,
+		Hooks: map[string]*ext.HookConfig{
+			"predeploy": {Run: "scripts/predeploy.sh"},
+		},
+	}
+
+	projectConfig.Services = map[string]*project.ServiceConfig{
+		"api": {
+			Name:         "api",
+			RelativePath: filepath.Join("src", "api"),
+			Project:      projectConfig,
+			Hooks: map[string]*ext.HookConfig{
+				"predeploy": {Run: "scripts/predeploy.sh"},
+				"postbuild": {Run: "scripts/postbuild.sh"},
+			},
+		},
+		"web": {
+			Name:         "web",
+			RelativePath: filepath.Join("src", "web"),
+			Project:      projectConfig,
+		},
+	}
+
+	for _, script := range []string{
+		filepath.Join(projectPath, "scripts", "predeploy.sh"),
+		filepath.Join(projectPath, "src", "api", "scripts", "predeploy.sh"),
+		filepath.Join(projectPath, "src", "api", "scripts", "postbuild.sh"),
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(script), osutil.PermissionDirectory))
+		require.NoError(t, os.WriteFile(script, nil, osutil.PermissionExecutableFile))
+	}
+
+	return projectConfig
+}
+
+func newHooksRunTestAction(
+	mockContext *mocks.MockContext,
+	projectConfig *project.ProjectConfig,
+	env *environment.Environment,
+	flags *hooksRunFlags,
+	hookName string,
+) *hooksRunAction {
+	envManager := &mockenv.MockEnvManager{}
+	envManager.On("Reload", mock.Anything, env).Return(nil)
+
+	return newHooksRunAction(
+		projectConfig,
+		project.NewImportManager(nil),
+		env,
+		envManager,
+		mockContext.CommandRunner,
+		mockContext.Console,
+		flags,
+		[]string{hookName},
+	).(*hooksRunAction)
+}
+
+func Test_HooksRun(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	projectConfig := newHooksRunTestProject(t)
+	env := environment.NewWithValues("dev", map[string]string{"AZURE_LOCATION": "eastus2"})
+
+	// The working directory of each hook, by script
+	ranHooks := map[string]string{}
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "predeploy.sh")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		require.Contains(t, args.Env, "AZURE_LOCATION=eastus2")
+		ranHooks[args.Cwd] = args.Args[0]
+
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	action := newHooksRunTestAction(mockContext, projectConfig, env, &hooksRunFlags{}, "predeploy")
+	_, err := action.Run(*mockContext.Context)
+	require.NoError(t, err)
+
+	// Service hooks run from the service directory, like in the service lifecycle
+	require.Equal(t, map[string]string{
+		projectConfig.Path: "scripts/predeploy.sh",
+		filepath.Join(projectConfig.Path, "src", "api"): "scripts/predeploy.sh",
+	}, ranHooks)
+}
+
+func Test_HooksRun_ForEachService(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	projectConfig := newHooksRunTestProject(t)
+	projectConfig.Hooks["predeploy"].ForEachService = true
+	env := environment.New("dev")
+
+	// The service each run of the project hook is for, by service path
+	ranServices := map[string]string{}
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "predeploy.sh") && args.Cwd == projectConfig.Path
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		var serviceName, servicePath string
+		for _, envVar := range args.Env {
+			if value, has := strings.CutPrefix(envVar, ext.ServiceNameEnvVarName+"="); has {
+				serviceName = value
+			} else if value, has := strings.CutPrefix(envVar, ext.ServicePathEnvVarName+"="); has {
+				servicePath = value
+			}
+		}
+		ranServices[servicePath] = serviceName
+
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "predeploy.sh") && args.Cwd != projectConfig.Path
+	}).Respond(exec.NewRunResult(0, "", ""))
+
+	action := newHooksRunTestAction(mockContext, projectConfig, env, &hooksRunFlags{}, "predeploy")
+	_, err := action.Run(*mockContext.Context)
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]string{
+		filepath.Join(projectConfig.Path, "src", "api"): "api",
+		filepath.Join(projectConfig.Path, "src", "web"): "web",
+	}, ranServices)
+}
+
+func Test_HooksRun_HookNotDefined(t *testing.T) {
+	t.Run("Project", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		action := newHooksRunTestAction(
+			mockContext, newHooksRunTestProject(t), environment.New("dev"), &hooksRunFlags{}, "prepackage")
+
+		_, err := action.Run(*mockContext.Context)
+
+		var suggestionErr *azcli.ErrorWithSuggestion
+		require.ErrorAs(t, err, &suggestionErr)
+		require.ErrorContains(t, err, "hook 'prepackage' is not defined for the project or its services")
+		require.Equal(t,
+			"Available hooks:\n  - postbuild (api)\n  - predeploy (project, api)", suggestionErr.Suggestion)
+	})
+
+	t.Run("Service", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		action := newHooksRunTestAction(
+			mockContext, newHooksRunTestProject(t), environment.New("dev"), &hooksRunFlags{service: "web"}, "postbuild")
+
+		_, err := action.Run(*mockContext.Context)
+
+		var suggestionErr *azcli.ErrorWithSuggestion
+		require.ErrorAs(t, err, &suggestionErr)
+		require.ErrorContains(t, err, "hook 'postbuild' is not defined for the project or service 'web'")
+		require.Equal(t, "Available hooks:\n  - predeploy (project)", suggestionErr.Suggestion)
+	})
+}
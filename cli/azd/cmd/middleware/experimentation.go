@@ -3,27 +3,37 @@ package middleware
 import (
 	"context"
 	"log"
-	"net/http"
 	"os"
 	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal/runcontext"
 	"github.com/azure/azure-dev/cli/azd/internal/tracing"
 	"github.com/azure/azure-dev/cli/azd/internal/tracing/fields"
 	"github.com/azure/azure-dev/cli/azd/pkg/alpha"
 	"github.com/azure/azure-dev/cli/azd/pkg/experimentation"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/azure/azure-dev/cli/azd/pkg/logging"
 )
 
 type ExperimentationMiddleware struct {
+	httpClient httputil.HttpClient
 }
 
-func NewExperimentationMiddleware() Middleware {
-	return &ExperimentationMiddleware{}
+func NewExperimentationMiddleware(httpClient httputil.HttpClient) Middleware {
+	return &ExperimentationMiddleware{
+		httpClient: httpClient,
+	}
 }
 
 const assignmentEndpoint = "https://default.exp-tas.com/exptas49/b80dfe81-554e-48ec-a7bc-1dd773cd6a54-azdexpws/api/v1/tas"
 
 func (m *ExperimentationMiddleware) Run(ctx context.Context, next NextFn) (*actions.ActionResult, error) {
+	if runcontext.IsOffline() {
+		log.Print("skipping variant assignments in offline mode")
+		return next(ctx)
+	}
+
 	endpoint := assignmentEndpoint
 	// Allow overriding the assignment endpoint, either for local development (where you want to hit a private instance)
 	// or testing (we use this in our end to end tests to control assignment behavior for the CLI under test)/
@@ -34,10 +44,10 @@ func (m *ExperimentationMiddleware) Run(ctx context.Context, next NextFn) (*acti
 
 	if assignmentManager, err := experimentation.NewAssignmentsManager(
 		endpoint,
-		http.DefaultClient,
+		m.httpClient,
 	); err == nil {
 		if assignment, err := assignmentManager.Assignment(ctx); err != nil {
-			log.Printf("failed to get variant assignments: %v", err)
+			logging.Warn("getting the variant assignments", "error", err)
 		} else {
 			log.Printf("assignment context: %v", assignment.AssignmentContext)
 			tracing.SetGlobalAttributes(fields.ExpAssignmentContextKey.String(assignment.AssignmentContext))
@@ -58,7 +68,7 @@ func (m *ExperimentationMiddleware) Run(ctx context.Context, next NextFn) (*acti
 			}
 		}
 	} else {
-		log.Printf("failed to create assignment manager: %v", err)
+		logging.Warn("creating the assignment manager", "error", err)
 	}
 
 	return next(ctx)
@@ -11,6 +11,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/ext"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/lazy"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/project"
 )
 
@@ -59,6 +60,15 @@ func (m *HooksMiddleware) Run(ctx context.Context, next NextFn) (*actions.Action
 		return next(ctx)
 	}
 
+	// Hooks run arbitrary scripts, so a dry run without side effects doesn't run them
+	if m.isDryRun() {
+		if hasHooks(projectConfig) {
+			m.console.Message(ctx, output.WithWarningFormat(
+				"WARNING: Command and service hooks are not run when '--dry-run' is set.\n"))
+		}
+		return next(ctx)
+	}
+
 	if err := m.registerServiceHooks(ctx, env, projectConfig); err != nil {
 		return nil, fmt.Errorf("failed registering service hooks, %w", err)
 	}
@@ -66,6 +76,31 @@ func (m *HooksMiddleware) Run(ctx context.Context, next NextFn) (*actions.Action
 	return m.registerCommandHooks(ctx, env, projectConfig, next)
 }
 
+// isDryRun returns true when the command runs with `--dry-run`.
+func (m *HooksMiddleware) isDryRun() bool {
+	if m.options.Flags == nil {
+		return false
+	}
+
+	dryRun, err := m.options.Flags.GetBool("dry-run")
+	return err == nil && dryRun
+}
+
+// hasHooks returns true when the project or any of its services configure hooks.
+func hasHooks(projectConfig *project.ProjectConfig) bool {
+	if len(projectConfig.Hooks) > 0 {
+		return true
+	}
+
+	for _, service := range projectConfig.Services {
+		if len(service.Hooks) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Register command level hooks for the executing cobra command & action
 // Invokes the middleware next function
 func (m *HooksMiddleware) registerCommandHooks(
@@ -86,6 +121,11 @@ func (m *HooksMiddleware) registerCommandHooks(
 		return nil, fmt.Errorf("failed getting environment manager, %w", err)
 	}
 
+	stableServices, err := m.importManager.ServiceStable(ctx, projectConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed getting services: %w", err)
+	}
+
 	hooksManager := ext.NewHooksManager(projectConfig.Path)
 	hooksRunner := ext.NewHooksRunner(
 		hooksManager,
@@ -95,7 +135,7 @@ func (m *HooksMiddleware) registerCommandHooks(
 		projectConfig.Path,
 		projectConfig.Hooks,
 		env,
-	)
+	).WithServices(project.HookServices(stableServices))
 
 	var actionResult *actions.ActionResult
 
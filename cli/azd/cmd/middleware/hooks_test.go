@@ -16,6 +16,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/azure/azure-dev/cli/azd/test/mocks/mockenv"
 	"github.com/azure/azure-dev/cli/azd/test/ostest"
+	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
@@ -181,6 +182,43 @@ func Test_CommandHooks_Middleware_PreHookWithErrorAndContinue(t *testing.T) {
 	require.True(t, *actionRan)
 }
 
+func Test_CommandHooks_Middleware_DryRun(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	azdContext := createAzdContext(t)
+
+	flags := pflag.NewFlagSet("deploy", pflag.ContinueOnError)
+	flags.Bool("dry-run", false, "")
+	require.NoError(t, flags.Set("dry-run", "true"))
+
+	envName := "test"
+	runOptions := Options{CommandPath: "deploy", Flags: flags}
+
+	projectConfig := project.ProjectConfig{
+		Name: envName,
+		Hooks: map[string]*ext.HookConfig{
+			"predeploy": {
+				Run:   "echo 'hello'",
+				Shell: ext.ShellTypeBash,
+			},
+		},
+	}
+
+	err := ensureAzdValid(mockContext, azdContext, envName, &projectConfig)
+	require.NoError(t, err)
+
+	nextFn, actionRan := createNextFn()
+	hookRan := setupHookMock(mockContext, 0)
+	result, err := runMiddleware(mockContext, azdContext, envName, &projectConfig, &runOptions, nextFn)
+
+	require.NotNil(t, result)
+	require.NoError(t, err)
+
+	// Hooks don't run during a dry run, the user is warned instead
+	require.False(t, *hookRan)
+	require.True(t, *actionRan)
+	require.Contains(t, strings.Join(mockContext.Console.Output(), "\n"), "hooks are not run")
+}
+
 func Test_CommandHooks_Middleware_WithCmdAlias(t *testing.T) {
 	mockContext := mocks.NewMockContext(context.Background())
 	azdContext := createAzdContext(t)
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+)
+
+// Cancels the action when it runs for longer than the timeout set with `--timeout`
+type TimeoutMiddleware struct {
+	options     *Options
+	rootOptions *internal.GlobalCommandOptions
+}
+
+// Creates a new instance of the Timeout middleware
+func NewTimeoutMiddleware(options *Options, rootOptions *internal.GlobalCommandOptions) Middleware {
+	return &TimeoutMiddleware{
+		options:     options,
+		rootOptions: rootOptions,
+	}
+}
+
+// Invokes the timeout middleware. When a timeout is set, the context of the action is cancelled at the deadline, which
+// stops the running tools and Azure requests, and the error of the action is wrapped with internal.ErrCommandTimeout.
+func (m *TimeoutMiddleware) Run(ctx context.Context, next NextFn) (*actions.ActionResult, error) {
+	// Child actions share the deadline of the root action
+	if m.options.IsChildAction(ctx) || m.rootOptions.Timeout <= 0 {
+		return next(ctx)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, m.rootOptions.Timeout)
+	defer cancel()
+
+	result, err := next(timeoutCtx)
+	if err != nil && errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) {
+		return result, fmt.Errorf("%w after %s: %w", internal.ErrCommandTimeout, m.rootOptions.Timeout, err)
+	}
+
+	return result, err
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	osexec "os/exec"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Timeout_Run(t *testing.T) {
+	// A slow action, which only returns when its context is cancelled
+	slowAction := func(ctx context.Context) (*actions.ActionResult, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Minute):
+			return &actions.ActionResult{}, nil
+		}
+	}
+
+	t.Run("CancelledAtDeadline", func(t *testing.T) {
+		middleware := NewTimeoutMiddleware(
+			&Options{CommandPath: "azd provision", Name: "provision"},
+			&internal.GlobalCommandOptions{Timeout: 100 * time.Millisecond},
+		)
+
+		start := time.Now()
+		result, err := middleware.Run(context.Background(), slowAction)
+
+		require.Nil(t, result)
+		require.ErrorIs(t, err, internal.ErrCommandTimeout)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.EqualError(t, err, "command timed out after 100ms: context deadline exceeded")
+		require.Less(t, time.Since(start), 10*time.Second)
+	})
+
+	t.Run("CancelsCommands", func(t *testing.T) {
+		sleep, err := osexec.LookPath("sleep")
+		if err != nil {
+			t.Skip("sleep is not installed")
+		}
+
+		middleware := NewTimeoutMiddleware(
+			&Options{Name: "provision"},
+			&internal.GlobalCommandOptions{Timeout: 100 * time.Millisecond},
+		)
+
+		start := time.Now()
+		_, err = middleware.Run(context.Background(), func(ctx context.Context) (*actions.ActionResult, error) {
+			runner := exec.NewCommandRunner(nil)
+			_, err := runner.Run(ctx, exec.NewRunArgs(sleep, "60"))
+			return nil, err
+		})
+
+		require.ErrorIs(t, err, internal.ErrCommandTimeout)
+		require.Less(t, time.Since(start), 10*time.Second)
+	})
+
+	t.Run("NoTimeout", func(t *testing.T) {
+		middleware := NewTimeoutMiddleware(&Options{Name: "provision"}, &internal.GlobalCommandOptions{})
+
+		var actualContext context.Context
+		_, err := middleware.Run(context.Background(), func(ctx context.Context) (*actions.ActionResult, error) {
+			actualContext = ctx
+			return nil, nil
+		})
+
+		require.NoError(t, err)
+		_, hasDeadline := actualContext.Deadline()
+		require.False(t, hasDeadline)
+	})
+
+	t.Run("ErrorBeforeDeadline", func(t *testing.T) {
+		middleware := NewTimeoutMiddleware(
+			&Options{Name: "provision"},
+			&internal.GlobalCommandOptions{Timeout: time.Minute},
+		)
+
+		actionErr := errors.New("provisioning failed")
+		_, err := middleware.Run(context.Background(), func(ctx context.Context) (*actions.ActionResult, error) {
+			return nil, actionErr
+		})
+
+		require.Equal(t, actionErr, err)
+	})
+}
@@ -7,11 +7,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"slices"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
 	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/pkg/account"
 	"github.com/azure/azure-dev/cli/azd/pkg/azapi"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
 	"github.com/azure/azure-dev/cli/azd/pkg/azure"
 	"github.com/azure/azure-dev/cli/azd/pkg/cloud"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
@@ -27,7 +31,9 @@ import (
 type monitorFlags struct {
 	monitorLive     bool
 	monitorLogs     bool
+	monitorMetrics  bool
 	monitorOverview bool
+	query           string
 	global          *internal.GlobalCommandOptions
 	internal.EnvFlag
 }
@@ -40,7 +46,14 @@ func (m *monitorFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommand
 		"Open a browser to Application Insights Live Metrics. Live Metrics is currently not supported for Python apps.",
 	)
 	local.BoolVar(&m.monitorLogs, "logs", false, "Open a browser to Application Insights Logs.")
+	local.BoolVar(&m.monitorMetrics, "metrics", false, "Open a browser to Application Insights Metrics.")
 	local.BoolVar(&m.monitorOverview, "overview", false, "Open a browser to Application Insights Overview Dashboard.")
+	local.StringVar(
+		&m.query,
+		"query",
+		"",
+		"Runs a KQL query against the application logs and prints the results instead of opening a browser.",
+	)
 	m.EnvFlag.Bind(local, global)
 	m.global = global
 }
@@ -66,8 +79,13 @@ type monitorAction struct {
 	azCli                azcli.AzCli
 	deploymentOperations azapi.DeploymentOperations
 	console              input.Console
+	formatter            output.Formatter
+	writer               io.Writer
 	flags                *monitorFlags
 	portalUrlBase        string
+	credentialProvider   account.SubscriptionCredentialProvider
+	armClientOptions     *arm.ClientOptions
+	logAnalyticsEndpoint string
 }
 
 func newMonitorAction(
@@ -77,8 +95,13 @@ func newMonitorAction(
 	azCli azcli.AzCli,
 	deploymentOperations azapi.DeploymentOperations,
 	console input.Console,
+	formatter output.Formatter,
+	writer io.Writer,
 	flags *monitorFlags,
 	portalUrlBase cloud.PortalUrlBase,
+	cloud *cloud.Cloud,
+	credentialProvider account.SubscriptionCredentialProvider,
+	armClientOptions *arm.ClientOptions,
 ) actions.Action {
 	return &monitorAction{
 		azdCtx:               azdCtx,
@@ -86,14 +109,20 @@ func newMonitorAction(
 		azCli:                azCli,
 		deploymentOperations: deploymentOperations,
 		console:              console,
+		formatter:            formatter,
+		writer:               writer,
 		flags:                flags,
 		subResolver:          subResolver,
 		portalUrlBase:        string(portalUrlBase),
+		credentialProvider:   credentialProvider,
+		armClientOptions:     armClientOptions,
+		logAnalyticsEndpoint: cloud.LogAnalyticsEndpoint,
 	}
 }
 
 func (m *monitorAction) Run(ctx context.Context) (*actions.ActionResult, error) {
-	if !m.flags.monitorLive && !m.flags.monitorLogs && !m.flags.monitorOverview {
+	if m.flags.query == "" && !m.flags.monitorLive && !m.flags.monitorLogs && !m.flags.monitorMetrics &&
+		!m.flags.monitorOverview {
 		m.flags.monitorOverview = true
 	}
 
@@ -112,6 +141,7 @@ func (m *monitorAction) Run(ctx context.Context) (*actions.ActionResult, error)
 
 	var insightsResources []azcli.AzCliResource
 	var portalResources []azcli.AzCliResource
+	var workspaceResources []azcli.AzCliResource
 
 	for _, resourceGroup := range resourceGroups {
 		resources, err := m.azCli.ListResourceGroupResources(
@@ -126,11 +156,23 @@ func (m *monitorAction) Run(ctx context.Context) (*actions.ActionResult, error)
 				portalResources = append(portalResources, resource)
 			case string(infra.AzureResourceTypeAppInsightComponent):
 				insightsResources = append(insightsResources, resource)
+			case string(infra.AzureResourceTypeLogAnalyticsWorkspace):
+				workspaceResources = append(workspaceResources, resource)
 			}
 		}
 	}
 
-	if len(insightsResources) == 0 && (m.flags.monitorLive || m.flags.monitorLogs) {
+	if m.flags.query != "" {
+		// Application Insights resources are preferred since the query runs against the application telemetry.
+		queryResources := append(slices.Clone(insightsResources), workspaceResources...)
+		if len(queryResources) == 0 {
+			return nil, fmt.Errorf("application does not contain an Application Insights or Log Analytics resource")
+		}
+
+		return nil, m.runQuery(ctx, queryResources[0])
+	}
+
+	if len(insightsResources) == 0 && (m.flags.monitorLive || m.flags.monitorLogs || m.flags.monitorMetrics) {
 		return nil, fmt.Errorf("application does not contain an Application Insights resource")
 	}
 
@@ -153,6 +195,11 @@ func (m *monitorAction) Run(ctx context.Context) (*actions.ActionResult, error)
 			openWithDefaultBrowser(ctx, m.console,
 				fmt.Sprintf("%s/#@%s/resource%s/logs", m.portalUrlBase, tenantId, insightsResource.Id))
 		}
+
+		if m.flags.monitorMetrics {
+			openWithDefaultBrowser(ctx, m.console,
+				fmt.Sprintf("%s/#@%s/resource%s/metrics", m.portalUrlBase, tenantId, insightsResource.Id))
+		}
 	}
 
 	for _, portalResource := range portalResources {
@@ -165,6 +212,62 @@ func (m *monitorAction) Run(ctx context.Context) (*actions.ActionResult, error)
 	return nil, nil
 }
 
+// runQuery runs the query from the --query flag against the logs of the given resource and prints the results.
+func (m *monitorAction) runQuery(ctx context.Context, resource azcli.AzCliResource) error {
+	credential, err := m.credentialProvider.CredentialForSubscription(ctx, m.env.GetSubscriptionId())
+	if err != nil {
+		return err
+	}
+
+	client, err := azsdk.NewLogsQueryClient(m.logAnalyticsEndpoint, credential, m.armClientOptions)
+	if err != nil {
+		return err
+	}
+
+	spinnerMessage := fmt.Sprintf("Querying logs of %s", resource.Name)
+	m.console.ShowSpinner(ctx, spinnerMessage, input.Step)
+	result, err := client.QueryResource(ctx, resource.Id, m.flags.query)
+	m.console.StopSpinner(ctx, spinnerMessage, input.GetStepResultFormat(err))
+	if err != nil {
+		return fmt.Errorf("running logs query: %w", err)
+	}
+
+	return formatLogsQueryResult(result, m.formatter, m.writer)
+}
+
+// formatLogsQueryResult prints the rows of the query result, as JSON with '--output json', and as a table with
+// '--output table' or when no output format is requested.
+func formatLogsQueryResult(result *azsdk.LogsQueryResult, formatter output.Formatter, writer io.Writer) error {
+	var table azsdk.LogsTable
+	if len(result.Tables) > 0 {
+		table = result.Tables[0]
+	}
+
+	if formatter.Kind() == output.JsonFormat {
+		return formatter.Format(table.Records(), writer, nil)
+	}
+
+	tableFormatter, ok := formatter.(*output.TableFormatter)
+	if !ok {
+		tableFormatter = &output.TableFormatter{}
+	}
+
+	if len(table.Rows) == 0 {
+		_, err := fmt.Fprintln(writer, "No results.")
+		return err
+	}
+
+	columns := make([]output.Column, len(table.Columns))
+	for index, column := range table.Columns {
+		columns[index] = output.Column{
+			Heading:       column.Name,
+			ValueTemplate: fmt.Sprintf("{{index . %q}}", column.Name),
+		}
+	}
+
+	return tableFormatter.Format(table.Records(), writer, output.TableFormatterOptions{Columns: columns})
+}
+
 func getCmdMonitorHelpDescription(*cobra.Command) string {
 	return generateCmdHelpDescription(
 		fmt.Sprintf("Monitor a deployed application %s. For more information, go to: %s.",
@@ -177,5 +280,8 @@ func getCmdMonitorHelpFooter(c *cobra.Command) string {
 		"Open Application Insights Overview Dashboard.": output.WithHighLightFormat("azd monitor --overview"),
 		"Open Application Insights Live Metrics.":       output.WithHighLightFormat("azd monitor --live"),
 		"Open Application Insights Logs.":               output.WithHighLightFormat("azd monitor --logs"),
+		"Open Application Insights Metrics.":            output.WithHighLightFormat("azd monitor --metrics"),
+		"Show the slowest requests of the last hour.": output.WithHighLightFormat(
+			"azd monitor --query \"requests | where timestamp > ago(1h) | top 10 by duration\""),
 	})
 }
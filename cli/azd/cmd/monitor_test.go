@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatLogsQueryResult(t *testing.T) {
+	result := &azsdk.LogsQueryResult{
+		Tables: []azsdk.LogsTable{
+			{
+				Name: "PrimaryResult",
+				Columns: []azsdk.LogsColumn{
+					{Name: "name", Type: "string"},
+					{Name: "count_", Type: "long"},
+				},
+				Rows: [][]any{
+					{"GET /", float64(42)},
+					{"GET /api/items", float64(7)},
+				},
+			},
+		},
+	}
+
+	// The rows are printed as a table by default, and with '--output table'
+	for name, formatter := range map[string]output.Formatter{
+		"Table":       &output.TableFormatter{},
+		"NoneAsTable": &output.NoneFormatter{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			err := formatLogsQueryResult(result, formatter, buf)
+			require.NoError(t, err)
+
+			lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+			require.Len(t, lines, 3)
+			require.Regexp(t, `^name\s+count_$`, string(lines[0]))
+			require.Regexp(t, `^GET /\s+42$`, string(lines[1]))
+			require.Regexp(t, `^GET /api/items\s+7$`, string(lines[2]))
+		})
+	}
+
+	t.Run("Json", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := formatLogsQueryResult(result, &output.JsonFormatter{}, buf)
+		require.NoError(t, err)
+		require.JSONEq(t, `[{"name": "GET /", "count_": 42}, {"name": "GET /api/items", "count_": 7}]`, buf.String())
+	})
+
+	t.Run("NoResults", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := formatLogsQueryResult(&azsdk.LogsQueryResult{}, &output.NoneFormatter{}, buf)
+		require.NoError(t, err)
+		require.Equal(t, "No results.\n", buf.String())
+	})
+}
@@ -4,12 +4,19 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
 	"time"
 
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
 	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/artifacts"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/lazy"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
 	"github.com/azure/azure-dev/cli/azd/pkg/project"
@@ -21,7 +28,9 @@ type packageFlags struct {
 	all    bool
 	global *internal.GlobalCommandOptions
 	*internal.EnvFlag
-	outputPath string
+	outputPath      string
+	upload          bool
+	zipDeployVerify bool
 }
 
 func newPackageFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *packageFlags {
@@ -50,6 +59,18 @@ func (pf *packageFlags) Bind(local *pflag.FlagSet, global *internal.GlobalComman
 		"",
 		"File or folder path where the generated packages will be saved.",
 	)
+	local.BoolVar(
+		&pf.upload,
+		"upload",
+		false,
+		"Uploads the generated packages to the configured artifact store.",
+	)
+	local.BoolVar(
+		&pf.zipDeployVerify,
+		"zip-deploy-verify",
+		false,
+		"Verifies the generated zip packages are valid archives with the expected entries.",
+	)
 }
 
 func newPackageCmd() *cobra.Command {
@@ -74,6 +95,9 @@ type packageAction struct {
 	console        input.Console
 	formatter      output.Formatter
 	writer         io.Writer
+	env            *environment.Environment
+	envManager     environment.Manager
+	artifactStore  *lazy.Lazy[artifacts.Store]
 }
 
 func newPackageAction(
@@ -86,6 +110,9 @@ func newPackageAction(
 	formatter output.Formatter,
 	writer io.Writer,
 	importManager *project.ImportManager,
+	env *environment.Environment,
+	envManager environment.Manager,
+	artifactStore *lazy.Lazy[artifacts.Store],
 ) actions.Action {
 	return &packageAction{
 		flags:          flags,
@@ -97,6 +124,9 @@ func newPackageAction(
 		formatter:      formatter,
 		writer:         writer,
 		importManager:  importManager,
+		env:            env,
+		envManager:     envManager,
+		artifactStore:  artifactStore,
 	}
 }
 
@@ -141,6 +171,14 @@ func (pa *packageAction) Run(ctx context.Context) (*actions.ActionResult, error)
 		return nil, err
 	}
 
+	var store artifacts.Store
+	if pa.flags.upload {
+		store, err = pa.artifactStore.GetValue()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	packageResults := map[string]*project.ServicePackageResult{}
 
 	serviceTable, err := pa.importManager.ServiceStable(ctx, pa.projectConfig)
@@ -194,7 +232,7 @@ func (pa *packageAction) Run(ctx context.Context) (*actions.ActionResult, error)
 			continue
 		}
 
-		options := &project.PackageOptions{OutputPath: pa.flags.outputPath}
+		options := &project.PackageOptions{OutputPath: pa.flags.outputPath, VerifyZip: pa.flags.zipDeployVerify}
 		packageTask := pa.serviceManager.Package(ctx, svc, nil, options)
 		done := make(chan struct{})
 		go func() {
@@ -208,6 +246,10 @@ func (pa *packageAction) Run(ctx context.Context) (*actions.ActionResult, error)
 		packageResult, err := packageTask.Await()
 		// adding a few seconds to wait for all async ops to be flush
 		<-done
+		if err == nil && store != nil {
+			pa.console.ShowSpinner(ctx, fmt.Sprintf("Packaging service %s (Uploading package)", svc.Name), input.Step)
+			err = pa.uploadPackage(ctx, store, svc, packageResult)
+		}
 		pa.console.StopSpinner(ctx, stepMessage, input.GetStepResultFormat(err))
 
 		if err != nil {
@@ -222,6 +264,12 @@ func (pa *packageAction) Run(ctx context.Context) (*actions.ActionResult, error)
 		}
 	}
 
+	if store != nil {
+		if err := pa.envManager.Save(ctx, pa.env); err != nil {
+			return nil, fmt.Errorf("saving package uris: %w", err)
+		}
+	}
+
 	if pa.formatter.Kind() == output.JsonFormat {
 		packageResult := PackageResult{
 			Timestamp: time.Now(),
@@ -240,6 +288,50 @@ func (pa *packageAction) Run(ctx context.Context) (*actions.ActionResult, error)
 	}, nil
 }
 
+// uploadPackage uploads the package of the service to the artifact store, and records the uri of the uploaded package
+// in the environment so it can be deployed later with 'azd deploy --from-package'. Packages that aren't files, like
+// container images pushed to a registry on deploy, aren't uploaded. Directory packages aren't either, since the hosts
+// deploying them don't accept archives, which is reported as a warning.
+func (pa *packageAction) uploadPackage(
+	ctx context.Context,
+	store artifacts.Store,
+	svc *project.ServiceConfig,
+	packageResult *project.ServicePackageResult,
+) error {
+	info, err := os.Stat(packageResult.PackagePath)
+	if err != nil {
+		log.Printf("skipping upload of package '%s' for service '%s'", packageResult.PackagePath, svc.Name)
+		return nil
+	}
+
+	if info.IsDir() {
+		pa.console.Message(ctx, output.WithWarningFormat(
+			"WARNING: The package of service '%s' is the directory '%s', which can't be uploaded to the artifact store. "+
+				"Deploy it from this directory with 'azd deploy %s --from-package'.",
+			svc.Name,
+			packageResult.PackagePath,
+			svc.Name,
+		))
+		return nil
+	}
+
+	name := path.Join(
+		pa.projectConfig.Name,
+		svc.Name,
+		time.Now().UTC().Format("20060102150405"),
+		filepath.Base(packageResult.PackagePath),
+	)
+	artifactUri, err := store.Upload(ctx, name, packageResult.PackagePath)
+	if err != nil {
+		return fmt.Errorf("uploading package for service '%s': %w", svc.Name, err)
+	}
+
+	packageResult.ArtifactUri = artifactUri
+	pa.env.SetServiceProperty(svc.Name, "PACKAGE_URI", artifactUri)
+
+	return nil
+}
+
 func getCmdPackageHelpDescription(*cobra.Command) string {
 	return generateCmdHelpDescription(fmt.Sprintf(
 		"Packages application's code to be deployed to Azure. %s",
@@ -251,6 +343,14 @@ func getCmdPackageHelpDescription(*cobra.Command) string {
 		formatHelpNote(
 			fmt.Sprintf("When %s is set, only the specific service is packaged.", output.WithHighLightFormat("<service>"))),
 		formatHelpNote("After the packaging is complete, the package locations are printed."),
+		formatHelpNote(fmt.Sprintf(
+			"When %s is set, packages are uploaded to the artifact store configured in 'azure.yaml' and their URIs are"+
+				" saved to the environment as SERVICE_<NAME>_PACKAGE_URI, to be deployed with 'azd deploy --from-package'.",
+			output.WithHighLightFormat("--upload"))),
+		formatHelpNote(fmt.Sprintf(
+			"When %s is set, zip packages are read back and checked for corrupt or missing entries, failing before"+
+				" they're deployed.",
+			output.WithHighLightFormat("--zip-deploy-verify"))),
 	})
 }
 
@@ -265,5 +365,8 @@ func getCmdPackageHelpFooter(*cobra.Command) string {
 		"Packages the service named 'api' to the specified output path.": output.WithHighLightFormat(
 			"azd package api --output-path ./dist/api.zip",
 		),
+		"Packages the service named 'api' and uploads it to the artifact store.": output.WithHighLightFormat(
+			"azd package api --upload",
+		),
 	})
 }
@@ -0,0 +1,177 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/artifacts"
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/lazy"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockenv"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePackageProjectManager is a project manager with no tools to install.
+type fakePackageProjectManager struct {
+	project.ProjectManager
+}
+
+func (m *fakePackageProjectManager) Initialize(ctx context.Context, projectConfig *project.ProjectConfig) error {
+	return nil
+}
+
+func (m *fakePackageProjectManager) EnsureAllTools(
+	ctx context.Context,
+	projectConfig *project.ProjectConfig,
+	serviceFilterFn project.ServiceFilterPredicate,
+) error {
+	return nil
+}
+
+// fakePackageServiceManager packages services to the package paths, keyed by service name.
+type fakePackageServiceManager struct {
+	project.ServiceManager
+	packagePaths map[string]string
+}
+
+func (m *fakePackageServiceManager) Package(
+	ctx context.Context,
+	serviceConfig *project.ServiceConfig,
+	buildOutput *project.ServiceBuildResult,
+	options *project.PackageOptions,
+) *async.TaskWithProgress[*project.ServicePackageResult, project.ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*project.ServicePackageResult, project.ServiceProgress]) {
+			task.SetResult(&project.ServicePackageResult{PackagePath: m.packagePaths[serviceConfig.Name]})
+		})
+}
+
+// fakeArtifactStore records the uploaded packages, keyed by artifact name.
+type fakeArtifactStore struct {
+	artifacts.Store
+	uploaded map[string][]byte
+}
+
+func (s *fakeArtifactStore) Upload(ctx context.Context, name string, path string) (string, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	s.uploaded[name] = contents
+	return "https://artifacts.example.com/" + name, nil
+}
+
+func Test_PackageAction_Upload(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+
+	apiPackage := filepath.Join(t.TempDir(), "api.zip")
+	require.NoError(t, os.WriteFile(apiPackage, []byte("api"), 0600))
+	sitePackage := t.TempDir()
+
+	projectConfig := &project.ProjectConfig{
+		Name: "todo",
+		Services: map[string]*project.ServiceConfig{
+			"api":  {Name: "api", Host: project.AppServiceTarget},
+			"web":  {Name: "web", Host: project.ContainerAppTarget},
+			"site": {Name: "site", Host: project.StaticWebAppTarget},
+		},
+	}
+
+	newAction := func(upload bool, store *lazy.Lazy[artifacts.Store]) (*packageAction, *bytes.Buffer) {
+		env := environment.New("dev")
+		envManager := &mockenv.MockEnvManager{}
+		envManager.On("Save", mock.Anything, env).Return(nil)
+
+		var buf bytes.Buffer
+		action := newPackageAction(
+			&packageFlags{all: true, upload: upload, global: &internal.GlobalCommandOptions{}},
+			nil,
+			projectConfig,
+			&fakePackageProjectManager{},
+			&fakePackageServiceManager{packagePaths: map[string]string{
+				"api": apiPackage,
+				// Container images are pushed to a registry rather than uploaded
+				"web": "contoso.azurecr.io/todo/web:azd-deploy-1",
+				// Directories can't be uploaded
+				"site": sitePackage,
+			}},
+			mockContext.Console,
+			&output.JsonFormatter{},
+			&buf,
+			project.NewImportManager(nil),
+			env,
+			envManager,
+			store,
+		).(*packageAction)
+
+		return action, &buf
+	}
+
+	t.Run("Upload", func(t *testing.T) {
+		store := &fakeArtifactStore{uploaded: map[string][]byte{}}
+		action, buf := newAction(true, lazy.From[artifacts.Store](store))
+
+		_, err := action.Run(*mockContext.Context)
+		require.NoError(t, err)
+
+		require.Len(t, store.uploaded, 1)
+		var name string
+		for uploadedName, contents := range store.uploaded {
+			name = uploadedName
+			require.Equal(t, []byte("api"), contents)
+		}
+		require.True(t, strings.HasPrefix(name, "todo/api/"))
+		require.Equal(t, "api.zip", filepath.Base(name))
+
+		// The uri of the uploaded package is recorded in the environment and the package result
+		artifactUri := "https://artifacts.example.com/" + name
+		require.Equal(t, artifactUri, action.env.GetServiceProperty("api", "PACKAGE_URI"))
+		require.Empty(t, action.env.GetServiceProperty("web", "PACKAGE_URI"))
+		require.Empty(t, action.env.GetServiceProperty("site", "PACKAGE_URI"))
+		require.Contains(
+			t,
+			strings.Join(mockContext.Console.Output(), "\n"),
+			"The package of service 'site' is the directory",
+		)
+		action.envManager.(*mockenv.MockEnvManager).AssertCalled(t, "Save", mock.Anything, action.env)
+
+		var result PackageResult
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+		require.Equal(t, artifactUri, result.Services["api"].ArtifactUri)
+		require.Empty(t, result.Services["web"].ArtifactUri)
+	})
+
+	t.Run("NoUpload", func(t *testing.T) {
+		store := &fakeArtifactStore{uploaded: map[string][]byte{}}
+		action, _ := newAction(false, lazy.From[artifacts.Store](store))
+
+		_, err := action.Run(*mockContext.Context)
+		require.NoError(t, err)
+		require.Empty(t, store.uploaded)
+		action.envManager.(*mockenv.MockEnvManager).AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+
+	t.Run("NotConfigured", func(t *testing.T) {
+		action, _ := newAction(true, lazy.NewLazy(func() (artifacts.Store, error) {
+			return nil, artifacts.ErrStoreNotConfigured
+		}))
+
+		_, err := action.Run(*mockContext.Context)
+		require.ErrorIs(t, err, artifacts.ErrStoreNotConfigured)
+	})
+}
@@ -5,8 +5,11 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
@@ -22,10 +25,15 @@ import (
 	"github.com/spf13/pflag"
 )
 
+// defaultRestoreParallelism is the default maximum number of services restored concurrently.
+const defaultRestoreParallelism = 4
+
 type restoreFlags struct {
 	all         bool
+	maxParallel int
 	global      *internal.GlobalCommandOptions
 	serviceName string
+	language    string
 	internal.EnvFlag
 }
 
@@ -45,6 +53,18 @@ func (r *restoreFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommand
 	)
 	//deprecate:flag hide --service
 	_ = local.MarkHidden("service")
+	local.IntVar(
+		&r.maxParallel,
+		"max-parallel",
+		defaultRestoreParallelism,
+		"Maximum number of services restored concurrently.",
+	)
+	local.StringVar(
+		&r.language,
+		"language",
+		"",
+		"Restores the services written in a language, like python or js.",
+	)
 }
 
 func newRestoreFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *restoreFlags {
@@ -123,13 +143,26 @@ func (ra *restoreAction) Run(ctx context.Context) (*actions.ActionResult, error)
 
 	startTime := time.Now()
 
-	serviceNameWarningCheck(ra.console, ra.flags.serviceName, "restore")
+	serviceNameWarningCheck(ctx, ra.console, ra.flags.serviceName, "restore")
 
 	targetServiceName := ra.flags.serviceName
 	if len(ra.args) == 1 {
 		targetServiceName = ra.args[0]
 	}
 
+	var language project.ServiceLanguageKind
+	if ra.flags.language != "" {
+		if targetServiceName != "" {
+			return nil, errors.New("cannot specify both --language and <service>")
+		}
+
+		var err error
+		if language, err = project.ParseLanguageFilter(ra.flags.language); err != nil {
+			return nil, err
+		}
+	}
+
+	// Filtering on a language selects all the services of the language
 	targetServiceName, err := getTargetServiceName(
 		ctx,
 		ra.projectManager,
@@ -137,56 +170,50 @@ func (ra *restoreAction) Run(ctx context.Context) (*actions.ActionResult, error)
 		ra.projectConfig,
 		string(project.ServiceEventRestore),
 		targetServiceName,
-		ra.flags.all,
+		ra.flags.all || language != project.ServiceLanguageNone,
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	selected := func(svc *project.ServiceConfig) bool {
+		return (targetServiceName == "" || svc.Name == targetServiceName) &&
+			(language == project.ServiceLanguageNone || svc.MatchesLanguage(language))
+	}
+
 	if err := ra.projectManager.Initialize(ctx, ra.projectConfig); err != nil {
 		return nil, err
 	}
 
-	if err := ra.projectManager.EnsureFrameworkTools(ctx, ra.projectConfig, func(svc *project.ServiceConfig) bool {
-		return targetServiceName == "" || svc.Name == targetServiceName
-	}); err != nil {
+	if err := ra.projectManager.EnsureFrameworkTools(ctx, ra.projectConfig, selected); err != nil {
 		return nil, err
 	}
 
-	restoreResults := map[string]*project.ServiceRestoreResult{}
 	stableServices, err := ra.importManager.ServiceStable(ctx, ra.projectConfig)
 	if err != nil {
 		return nil, err
 	}
 
+	var services []*project.ServiceConfig
 	for _, svc := range stableServices {
-		stepMessage := fmt.Sprintf("Restoring service %s", svc.Name)
-		ra.console.ShowSpinner(ctx, stepMessage, input.Step)
-
-		// Skip this service if both cases are true:
-		// 1. The user specified a service name
-		// 2. This service is not the one the user specified
-		if targetServiceName != "" && targetServiceName != svc.Name {
+		// Skip this service when the user specified a service name or a language it doesn't match
+		if !selected(svc) {
+			stepMessage := fmt.Sprintf("Restoring service %s", svc.Name)
+			ra.console.ShowSpinner(ctx, stepMessage, input.Step)
 			ra.console.StopSpinner(ctx, stepMessage, input.StepSkipped)
 			continue
 		}
 
-		restoreTask := ra.serviceManager.Restore(ctx, svc)
-		go func() {
-			for restoreProgress := range restoreTask.Progress() {
-				progressMessage := fmt.Sprintf("Restoring service %s (%s)", svc.Name, restoreProgress.Message)
-				ra.console.ShowSpinner(ctx, progressMessage, input.Step)
-			}
-		}()
+		services = append(services, svc)
+	}
 
-		restoreResult, err := restoreTask.Await()
-		if err != nil {
-			ra.console.StopSpinner(ctx, stepMessage, input.StepFailed)
-			return nil, err
-		}
+	if len(services) == 0 && language != project.ServiceLanguageNone {
+		return nil, fmt.Errorf("no services are written in language '%s'", ra.flags.language)
+	}
 
-		ra.console.StopSpinner(ctx, stepMessage, input.StepDone)
-		restoreResults[svc.Name] = restoreResult
+	restoreResults, err := ra.restoreServices(ctx, services)
+	if err != nil {
+		return nil, err
 	}
 
 	if ra.formatter.Kind() == output.JsonFormat {
@@ -208,6 +235,80 @@ func (ra *restoreAction) Run(ctx context.Context) (*actions.ActionResult, error)
 	}, nil
 }
 
+// restoreServices restores the services concurrently, up to the --max-parallel limit, since restores are independent
+// of each other. Progress of each service is prefixed with its name, and the failures of all the services are returned
+// together.
+func (ra *restoreAction) restoreServices(
+	ctx context.Context,
+	services []*project.ServiceConfig,
+) (map[string]*project.ServiceRestoreResult, error) {
+	// mu serializes the console updates and the collection of the results
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var running []string
+
+	restoreResults := map[string]*project.ServiceRestoreResult{}
+	errs := make([]error, len(services))
+	semaphore := make(chan struct{}, max(1, ra.flags.maxParallel))
+
+	for i, svc := range services {
+		wg.Add(1)
+		go func(i int, svc *project.ServiceConfig) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			stepMessage := fmt.Sprintf("Restoring service %s", svc.Name)
+			mu.Lock()
+			running = append(running, svc.Name)
+			ra.console.ShowSpinner(ctx, stepMessage, input.Step)
+			mu.Unlock()
+
+			restoreTask := ra.serviceManager.Restore(ctx, svc)
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for restoreProgress := range restoreTask.Progress() {
+					mu.Lock()
+					ra.console.ShowSpinner(
+						ctx, fmt.Sprintf("Restoring service %s (%s)", svc.Name, restoreProgress.Message), input.Step)
+					mu.Unlock()
+				}
+			}()
+
+			restoreResult, err := restoreTask.Await()
+			<-done
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			running = slices.DeleteFunc(running, func(name string) bool { return name == svc.Name })
+			if err != nil {
+				errs[i] = err
+				ra.console.StopSpinner(ctx, stepMessage, input.StepFailed)
+			} else {
+				restoreResults[svc.Name] = restoreResult
+				ra.console.StopSpinner(ctx, stepMessage, input.StepDone)
+			}
+
+			// Keep showing the progress of the services still being restored
+			if len(running) > 0 {
+				ra.console.ShowSpinner(
+					ctx, fmt.Sprintf("Restoring service %s", running[len(running)-1]), input.Step)
+			}
+		}(i, svc)
+	}
+
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return restoreResults, nil
+}
+
 func getCmdRestoreHelpDescription(*cobra.Command) string {
 	return generateCmdHelpDescription(
 		fmt.Sprintf("Restore application dependencies. %s", output.WithWarningFormat("(Beta)")),
@@ -228,5 +329,7 @@ func getCmdRestoreHelpFooter(*cobra.Command) string {
 			"dependency, Individual services are listed in your azure.yaml file.": fmt.Sprintf("%s %s",
 			output.WithHighLightFormat("azd restore <service>"),
 			output.WithWarningFormat("[Service name]")),
+		"Downloads and installs the dependencies of the services written in Python.": output.WithHighLightFormat(
+			"azd restore --language python"),
 	})
 }
@@ -0,0 +1,204 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockinput"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRestoreProjectManager is a project manager with no framework tools to install.
+type fakeRestoreProjectManager struct {
+	project.ProjectManager
+}
+
+func (m *fakeRestoreProjectManager) Initialize(ctx context.Context, projectConfig *project.ProjectConfig) error {
+	return nil
+}
+
+func (m *fakeRestoreProjectManager) EnsureFrameworkTools(
+	ctx context.Context,
+	projectConfig *project.ProjectConfig,
+	serviceFilterFn project.ServiceFilterPredicate,
+) error {
+	return nil
+}
+
+// fakeRestoreServiceManager restores services with the restore function.
+type fakeRestoreServiceManager struct {
+	project.ServiceManager
+	restore func(svc *project.ServiceConfig) error
+}
+
+func (m *fakeRestoreServiceManager) Restore(
+	ctx context.Context,
+	serviceConfig *project.ServiceConfig,
+) *async.TaskWithProgress[*project.ServiceRestoreResult, project.ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*project.ServiceRestoreResult, project.ServiceProgress]) {
+			task.SetProgress(project.NewServiceProgress("Installing dependencies"))
+			if err := m.restore(serviceConfig); err != nil {
+				task.SetError(fmt.Errorf("failed restoring service '%s': %w", serviceConfig.Name, err))
+				return
+			}
+
+			task.SetResult(&project.ServiceRestoreResult{})
+		})
+}
+
+func newTestRestoreAction(
+	mockContext *mocks.MockContext,
+	restore func(svc *project.ServiceConfig) error,
+) *restoreAction {
+	return newTestRestoreActionWithFlags(
+		mockContext,
+		&restoreFlags{all: true, maxParallel: defaultRestoreParallelism},
+		map[string]*project.ServiceConfig{
+			"api": {Name: "api", Language: project.ServiceLanguageJavaScript},
+			"web": {Name: "web", Language: project.ServiceLanguageJavaScript},
+		},
+		restore,
+	)
+}
+
+func newTestRestoreActionWithFlags(
+	mockContext *mocks.MockContext,
+	flags *restoreFlags,
+	services map[string]*project.ServiceConfig,
+	restore func(svc *project.ServiceConfig) error,
+) *restoreAction {
+	projectConfig := &project.ProjectConfig{
+		Name:     "restore",
+		Services: services,
+	}
+
+	return newRestoreAction(
+		flags,
+		nil,
+		mockContext.Console,
+		&output.NoneFormatter{},
+		io.Discard,
+		nil,
+		nil,
+		projectConfig,
+		&fakeRestoreProjectManager{},
+		&fakeRestoreServiceManager{restore: restore},
+		mockContext.CommandRunner,
+		project.NewImportManager(nil),
+	).(*restoreAction)
+}
+
+func Test_RestoreAction_Concurrent(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+
+	// Each restore waits for the other one to start, which only completes when both run concurrently
+	var started sync.WaitGroup
+	started.Add(2)
+	action := newTestRestoreAction(mockContext, func(svc *project.ServiceConfig) error {
+		started.Done()
+
+		allStarted := make(chan struct{})
+		go func() {
+			started.Wait()
+			close(allStarted)
+		}()
+
+		select {
+		case <-allStarted:
+			return nil
+		case <-time.After(5 * time.Second):
+			return errors.New("services weren't restored concurrently")
+		}
+	})
+
+	result, err := action.Run(*mockContext.Context)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Contains(t, mockContext.Console.SpinnerOps(), mockinput.SpinnerOp{
+		Op: mockinput.SpinnerOpStop, Message: "Restoring service api", Format: input.StepDone,
+	})
+	require.Contains(t, mockContext.Console.SpinnerOps(), mockinput.SpinnerOp{
+		Op: mockinput.SpinnerOpStop, Message: "Restoring service web", Format: input.StepDone,
+	})
+}
+
+func Test_RestoreAction_AggregatesFailures(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	action := newTestRestoreAction(mockContext, func(svc *project.ServiceConfig) error {
+		return fmt.Errorf("%s dependencies not found", svc.Name)
+	})
+
+	_, err := action.Run(*mockContext.Context)
+	require.ErrorContains(t, err, "failed restoring service 'api': api dependencies not found")
+	require.ErrorContains(t, err, "failed restoring service 'web': web dependencies not found")
+}
+
+func Test_RestoreAction_Language(t *testing.T) {
+	services := func() map[string]*project.ServiceConfig {
+		return map[string]*project.ServiceConfig{
+			"api":    {Name: "api", Language: project.ServiceLanguagePython},
+			"worker": {Name: "worker", Language: project.ServiceLanguagePython},
+			"web":    {Name: "web", Language: project.ServiceLanguageTypeScript},
+			"admin":  {Name: "admin", Language: project.ServiceLanguageCsharp},
+		}
+	}
+
+	restoreLanguage := func(t *testing.T, flags *restoreFlags) ([]string, error) {
+		mockContext := mocks.NewMockContext(context.Background())
+
+		var mu sync.Mutex
+		var restored []string
+		action := newTestRestoreActionWithFlags(mockContext, flags, services(), func(svc *project.ServiceConfig) error {
+			mu.Lock()
+			defer mu.Unlock()
+			restored = append(restored, svc.Name)
+			return nil
+		})
+
+		_, err := action.Run(*mockContext.Context)
+		slices.Sort(restored)
+		return restored, err
+	}
+
+	t.Run("MatchingServices", func(t *testing.T) {
+		restored, err := restoreLanguage(t, &restoreFlags{language: "py", maxParallel: 1})
+		require.NoError(t, err)
+		require.Equal(t, []string{"api", "worker"}, restored)
+	})
+
+	t.Run("DotNet", func(t *testing.T) {
+		restored, err := restoreLanguage(t, &restoreFlags{language: "dotnet", maxParallel: 1})
+		require.NoError(t, err)
+		require.Equal(t, []string{"admin"}, restored)
+	})
+
+	t.Run("UnknownLanguage", func(t *testing.T) {
+		_, err := restoreLanguage(t, &restoreFlags{language: "cobol", maxParallel: 1})
+		require.ErrorContains(t, err, "unsupported language 'cobol'")
+	})
+
+	t.Run("NoMatchingServices", func(t *testing.T) {
+		_, err := restoreLanguage(t, &restoreFlags{language: "java", maxParallel: 1})
+		require.EqualError(t, err, "no services are written in language 'java'")
+	})
+
+	t.Run("WithServiceName", func(t *testing.T) {
+		_, err := restoreLanguage(t, &restoreFlags{language: "python", serviceName: "api", maxParallel: 1})
+		require.EqualError(t, err, "cannot specify both --language and <service>")
+	})
+}
@@ -17,10 +17,12 @@ import (
 
 	"github.com/azure/azure-dev/cli/azd/pkg/azd"
 	"github.com/azure/azure-dev/cli/azd/pkg/ioc"
+	"github.com/azure/azure-dev/cli/azd/pkg/logging"
 	"github.com/azure/azure-dev/cli/azd/pkg/platform"
 
 	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/internal/cmd"
+	"github.com/azure/azure-dev/cli/azd/internal/runcontext"
 	"github.com/azure/azure-dev/cli/azd/internal/telemetry"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/spf13/cobra"
@@ -55,7 +57,34 @@ func NewRootCmd(
 				fmt.Print(output.WithWarningFormat("WARNING: %s\n\n", platform.Error.Error()))
 			}
 
+			if opts.LogLevel != "" {
+				level, err := logging.ParseLevel(opts.LogLevel)
+				if err != nil {
+					return err
+				}
+
+				// The debug and trace levels enable the debug logging of the tools azd runs, like --debug does
+				if level <= logging.LevelDebug {
+					opts.EnableDebugLogging = true
+				}
+			}
+
+			// The rest of azd, and the tools it runs, read the offline mode from the environment
+			if opts.Offline {
+				if err := os.Setenv(runcontext.OfflineEnvVarName, "true"); err != nil {
+					return err
+				}
+			}
+
+			if err := applyEnvFlag(cmd, opts); err != nil {
+				return err
+			}
+
 			if opts.Cwd != "" {
+				if err := validateCwd(opts.Cwd); err != nil {
+					return err
+				}
+
 				current, err := os.Getwd()
 
 				if err != nil {
@@ -90,9 +119,38 @@ func NewRootCmd(
 	root := actions.NewActionDescriptor("azd", &actions.ActionDescriptorOptions{
 		Command: rootCmd,
 		FlagsResolver: func(cmd *cobra.Command) *internal.GlobalCommandOptions {
-			rootCmd.PersistentFlags().StringVarP(&opts.Cwd, "cwd", "C", "", "Sets the current working directory.")
+			rootCmd.PersistentFlags().StringVarP(
+				&opts.Cwd, "cwd", "C", "", "Sets the current working directory, where azd looks for the project.")
 			rootCmd.PersistentFlags().
 				BoolVar(&opts.EnableDebugLogging, "debug", false, "Enables debugging and diagnostics logging.")
+			rootCmd.PersistentFlags().StringVar(
+				&opts.LogLevel,
+				"log-level",
+				"",
+				fmt.Sprintf(
+					"Sets the level of the diagnostics logging (%s). --debug is the same as trace.",
+					strings.Join(logging.LevelNames, ", ")))
+			rootCmd.PersistentFlags().StringVar(
+				&opts.EnvironmentName,
+				"env",
+				"",
+				"Runs the command against the environment, without changing the default environment.")
+			rootCmd.PersistentFlags().BoolVar(
+				&opts.Offline,
+				"offline",
+				false,
+				"Skips the network calls which aren't needed by the command, like the update check. "+
+					"Azure operations still run.")
+			rootCmd.PersistentFlags().BoolVar(
+				&opts.JsonErrors,
+				"json-errors",
+				false,
+				"Writes the error of a failing command as JSON to stderr, with its category and correlation id.")
+			rootCmd.PersistentFlags().DurationVar(
+				&opts.Timeout,
+				"timeout",
+				0,
+				"Cancels the command when it runs for longer than the duration, like 30m. Defaults to no timeout.")
 			rootCmd.PersistentFlags().
 				BoolVar(
 					&opts.NoPrompt,
@@ -295,6 +353,8 @@ func NewRootCmd(
 		Command:        newMonitorCmd(),
 		FlagsResolver:  newMonitorFlags,
 		ActionResolver: newMonitorAction,
+		OutputFormats:  []output.Format{output.JsonFormat, output.TableFormat, output.NoneFormat},
+		DefaultFormat:  output.NoneFormat,
 		HelpOptions: actions.ActionHelpOptions{
 			Description: getCmdMonitorHelpDescription,
 			Footer:      getCmdMonitorHelpFooter,
@@ -334,7 +394,8 @@ func NewRootCmd(
 		UseMiddleware("experimentation", middleware.NewExperimentationMiddleware).
 		UseMiddlewareWhen("telemetry", middleware.NewTelemetryMiddleware, func(descriptor *actions.ActionDescriptor) bool {
 			return !descriptor.Options.DisableTelemetry
-		})
+		}).
+		UseMiddleware("timeout", middleware.NewTimeoutMiddleware)
 
 	// Register common dependencies for the IoC rootContainer
 	if rootContainer == nil {
@@ -429,3 +490,43 @@ func getCmdRootHelpCommands(cmd *cobra.Command) (result string) {
 	}
 	return strings.Join(paragraph, "\n")
 }
+
+// validateCwd returns an error when dir, set with --cwd, isn't an existing directory. The directory may contain an azd
+// project, or a parent directory may, or have one created in it by `azd init`.
+func validateCwd(dir string) error {
+	info, err := os.Stat(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("--cwd: the directory '%s' does not exist", dir)
+	} else if err != nil {
+		return fmt.Errorf("--cwd: %w", err)
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("--cwd: '%s' is not a directory, specify the directory of the project", dir)
+	}
+
+	return nil
+}
+
+// applyEnvFlag sets the `-e, --environment` flag of the command to the environment set with the global `--env` flag, so
+// the command loads it like when passed with `-e`. Commands without an environment flag ignore `--env`.
+func applyEnvFlag(cmd *cobra.Command, opts *internal.GlobalCommandOptions) error {
+	if opts.EnvironmentName == "" {
+		return nil
+	}
+
+	envFlag := cmd.Flags().Lookup(internal.EnvironmentNameFlagName)
+	if envFlag == nil {
+		return nil
+	}
+
+	if envFlag.Changed && envFlag.Value.String() != opts.EnvironmentName {
+		return fmt.Errorf(
+			"--env '%s' and --%s '%s' specify different environments",
+			opts.EnvironmentName,
+			internal.EnvironmentNameFlagName,
+			envFlag.Value.String())
+	}
+
+	return envFlag.Value.Set(opts.EnvironmentName)
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/internal/runcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/contracts"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/ioc"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/stretchr/testify/require"
+)
+
+// runAzd runs the azd root command with args, returning what it wrote to stdout.
+func runAzd(t *testing.T, args ...string) (string, error) {
+	// Commands failing before their post run don't change back to the previous directory
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+
+	rootContainer := ioc.NewNestedContainer(nil)
+	ioc.RegisterInstance(rootContainer, context.Background())
+
+	var stdout bytes.Buffer
+	root := NewRootCmd(false, nil, rootContainer)
+	root.SetOut(&stdout)
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs(args)
+
+	err = root.ExecuteContext(context.Background())
+	return stdout.String(), err
+}
+
+func Test_Cwd(t *testing.T) {
+	t.Setenv("AZD_CONFIG_DIR", t.TempDir())
+	// Skips the experiment assignments, which are requested from the network
+	t.Setenv(runcontext.OfflineEnvVarName, "true")
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	projectDir := t.TempDir()
+	require.NoError(t, os.WriteFile(
+		filepath.Join(projectDir, azdcontext.ProjectFileName), []byte("name: cwd-test\n"), osutil.PermissionFile))
+
+	azdCtx := azdcontext.NewAzdContextWithDirectory(projectDir)
+	require.NoError(t, os.MkdirAll(azdCtx.EnvironmentRoot("dev"), osutil.PermissionDirectory))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(azdCtx.EnvironmentRoot("dev"), azdcontext.DotEnvFileName), nil, osutil.PermissionFile))
+	require.NoError(t, azdCtx.SetDefaultEnvironmentName("dev"))
+
+	t.Run("ResolvesProject", func(t *testing.T) {
+		output, err := runAzd(t, "--cwd", projectDir, "env", "list", "--output", "json")
+		require.NoError(t, err)
+
+		var envs []contracts.EnvListEnvironment
+		require.NoError(t, json.Unmarshal([]byte(output), &envs))
+		require.Len(t, envs, 1)
+		require.Equal(t, "dev", envs[0].Name)
+		require.True(t, envs[0].IsDefault)
+
+		// The previous directory is restored once the command completes
+		current, err := os.Getwd()
+		require.NoError(t, err)
+		require.Equal(t, wd, current)
+	})
+
+	t.Run("ResolvesProjectFromSubdirectory", func(t *testing.T) {
+		srcDir := filepath.Join(projectDir, "src")
+		require.NoError(t, os.MkdirAll(srcDir, osutil.PermissionDirectory))
+
+		output, err := runAzd(t, "--cwd", srcDir, "env", "list", "--output", "json")
+		require.NoError(t, err)
+		require.Contains(t, output, `"dev"`)
+	})
+
+	t.Run("DirectoryDoesNotExist", func(t *testing.T) {
+		missing := filepath.Join(t.TempDir(), "missing")
+
+		_, err := runAzd(t, "--cwd", missing, "env", "list")
+		require.EqualError(t, err, "--cwd: the directory '"+missing+"' does not exist")
+	})
+
+	t.Run("NotADirectory", func(t *testing.T) {
+		_, err := runAzd(t, "--cwd", azdCtx.ProjectPath(), "env", "list")
+		require.ErrorContains(t, err, "is not a directory")
+	})
+}
+
+func Test_EnvFlag(t *testing.T) {
+	t.Setenv("AZD_CONFIG_DIR", t.TempDir())
+	t.Setenv(runcontext.OfflineEnvVarName, "true")
+	t.Setenv(environment.EnvNameEnvVarName, "")
+
+	projectDir := t.TempDir()
+	require.NoError(t, os.WriteFile(
+		filepath.Join(projectDir, azdcontext.ProjectFileName), []byte("name: env-test\n"), osutil.PermissionFile))
+
+	azdCtx := azdcontext.NewAzdContextWithDirectory(projectDir)
+	for _, name := range []string{"dev", "prod"} {
+		require.NoError(t, os.MkdirAll(azdCtx.EnvironmentRoot(name), osutil.PermissionDirectory))
+		require.NoError(t, os.WriteFile(
+			filepath.Join(azdCtx.EnvironmentRoot(name), azdcontext.DotEnvFileName),
+			[]byte(environment.EnvNameEnvVarName+"="+name+"\n"),
+			osutil.PermissionFile))
+	}
+	require.NoError(t, azdCtx.SetDefaultEnvironmentName("dev"))
+
+	getValues := func(t *testing.T, args ...string) map[string]string {
+		output, err := runAzd(t, append([]string{"--cwd", projectDir}, args...)...)
+		require.NoError(t, err)
+
+		var values map[string]string
+		require.NoError(t, json.Unmarshal([]byte(output), &values))
+		return values
+	}
+
+	t.Run("RunsAgainstEnvironment", func(t *testing.T) {
+		_, err := runAzd(t, "--cwd", projectDir, "--env", "prod", "env", "set", "API_URL", "https://prod.contoso.com")
+		require.NoError(t, err)
+
+		prodValues := getValues(t, "env", "get-values", "-e", "prod", "--output", "json")
+		require.Equal(t, "https://prod.contoso.com", prodValues["API_URL"])
+		require.NotContains(t, getValues(t, "env", "get-values", "--output", "json"), "API_URL")
+		require.Equal(t, "prod", getValues(t, "--env", "prod", "env", "get-values", "--output", "json")["AZURE_ENV_NAME"])
+
+		// The default environment is unchanged
+		defaultEnv, err := azdCtx.GetDefaultEnvironmentName()
+		require.NoError(t, err)
+		require.Equal(t, "dev", defaultEnv)
+	})
+
+	t.Run("EnvironmentDoesNotExist", func(t *testing.T) {
+		_, err := runAzd(t, "--cwd", projectDir, "--env", "staging", "env", "set", "API_URL", "https://staging.contoso.com")
+		require.ErrorContains(t, err, "environment 'staging' set with --env does not exist")
+		require.NoDirExists(t, azdCtx.EnvironmentRoot("staging"))
+	})
+
+	t.Run("ConflictsWithEnvironmentFlag", func(t *testing.T) {
+		_, err := runAzd(t, "--cwd", projectDir, "--env", "prod", "env", "get-values", "-e", "dev")
+		require.EqualError(t, err, "--env 'prod' and --environment 'dev' specify different environments")
+	})
+}
+
+func Test_LogLevelEnablesDebugLogging(t *testing.T) {
+	t.Setenv("AZD_CONFIG_DIR", t.TempDir())
+	t.Setenv(runcontext.OfflineEnvVarName, "true")
+
+	tests := map[string]bool{
+		"trace": true,
+		"debug": true,
+		"info":  false,
+	}
+
+	for level, debug := range tests {
+		t.Run(level, func(t *testing.T) {
+			rootContainer := ioc.NewNestedContainer(nil)
+			ioc.RegisterInstance(rootContainer, context.Background())
+
+			root := NewRootCmd(false, nil, rootContainer)
+			root.SetOut(&bytes.Buffer{})
+			root.SetErr(&bytes.Buffer{})
+			root.SetArgs([]string{"version", "--log-level", level})
+			require.NoError(t, root.ExecuteContext(context.Background()))
+
+			var opts *internal.GlobalCommandOptions
+			require.NoError(t, rootContainer.Resolve(&opts))
+			require.Equal(t, debug, opts.EnableDebugLogging)
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/lazy"
+	"github.com/azure/azure-dev/cli/azd/pkg/logging"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
 	"github.com/azure/azure-dev/cli/azd/pkg/project"
@@ -237,28 +238,28 @@ func (s *showAction) serviceEndpoint(
 	ctx context.Context, subId string, serviceConfig *project.ServiceConfig, env *environment.Environment) string {
 	resourceManager, err := s.lazyResourceManager.GetValue()
 	if err != nil {
-		log.Printf("error: getting lazy target-resource. Endpoints will be empty: %v", err)
+		logging.Warn("getting the resource manager, endpoints will be empty", "error", err)
 		return ""
 	}
 	targetResource, err := resourceManager.GetTargetResource(ctx, subId, serviceConfig)
 	if err != nil {
-		log.Printf("error: getting target-resource. Endpoints will be empty: %v", err)
+		logging.Warn("getting the target resource, endpoints will be empty", "error", err)
 		return ""
 	}
 
 	serviceManager, err := s.lazyServiceManager.GetValue()
 	if err != nil {
-		log.Printf("error: getting lazy service manager. Endpoints will be empty: %v", err)
+		logging.Warn("getting the service manager, endpoints will be empty", "error", err)
 		return ""
 	}
 	st, err := serviceManager.GetServiceTarget(ctx, serviceConfig)
 	if err != nil {
-		log.Printf("error: getting service target. Endpoints will be empty: %v", err)
+		logging.Warn("getting the service target, endpoints will be empty", "error", err)
 		return ""
 	}
 	endpoints, err := st.Endpoints(ctx, serviceConfig, targetResource)
 	if err != nil {
-		log.Printf("error: getting service endpoints. Endpoints might be empty: %v", err)
+		logging.Warn("getting the service endpoints, endpoints might be empty", "error", err)
 	}
 
 	overriddenEndpoints := project.OverriddenEndpoints(ctx, serviceConfig, env)
@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/MakeNowJust/heredoc/v2"
@@ -24,7 +25,8 @@ import (
 type upFlags struct {
 	cmd.ProvisionFlags
 	cmd.DeployFlags
-	global *internal.GlobalCommandOptions
+	preview bool
+	global  *internal.GlobalCommandOptions
 	internal.EnvFlag
 }
 
@@ -36,6 +38,12 @@ func (u *upFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptio
 	u.ProvisionFlags.SetCommon(&u.EnvFlag)
 	u.DeployFlags.BindNonCommon(local, global)
 	u.DeployFlags.SetCommon(&u.EnvFlag)
+
+	local.BoolVar(
+		&u.preview,
+		"preview",
+		false,
+		"Package the services and preview the changes to Azure resources and services, without applying them.")
 }
 
 func newUpFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *upFlags {
@@ -113,6 +121,10 @@ func (u *upAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 		u.console.Message(ctx, output.WithGrayFormat("Note: Running custom 'up' workflow from azure.yaml"))
 	}
 
+	if u.flags.preview {
+		return u.runPreview(ctx, upWorkflow, startTime)
+	}
+
 	if err := u.workflowRunner.Run(ctx, upWorkflow); err != nil {
 		return nil, err
 	}
@@ -125,6 +137,65 @@ func (u *upAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 	}, nil
 }
 
+// runPreview runs the steps of the workflow that don't change Azure resources, previewing provisioning, and shows the
+// services that would be deployed.
+func (u *upAction) runPreview(
+	ctx context.Context,
+	upWorkflow *workflow.Workflow,
+	startTime time.Time,
+) (*actions.ActionResult, error) {
+	if err := u.workflowRunner.Run(ctx, previewWorkflow(upWorkflow)); err != nil {
+		return nil, err
+	}
+
+	services, err := u.importManager.ServiceStable(ctx, u.projectConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	u.console.Message(ctx, output.WithBold("Services that would be deployed (azd deploy --all):"))
+	for _, svc := range services {
+		u.console.Message(ctx, fmt.Sprintf("  %s %s", svc.Name, output.WithGrayFormat("(%s)", svc.Host)))
+	}
+	u.console.Message(ctx, "")
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: fmt.Sprintf("Generated the preview of your up workflow in %s.", ux.DurationAsText(since(startTime))),
+			FollowUp: fmt.Sprintf(
+				"No changes were applied. Run %s to provision and deploy.", output.WithHighLightFormat("azd up")),
+		},
+	}, nil
+}
+
+// previewWorkflow returns the steps of the workflow that are safe to run in preview mode: packaging is run as-is,
+// provisioning is previewed, and any other step, such as deploying, is skipped.
+func previewWorkflow(upWorkflow *workflow.Workflow) *workflow.Workflow {
+	preview := &workflow.Workflow{
+		Name: upWorkflow.Name,
+	}
+
+	for _, step := range upWorkflow.Steps {
+		if len(step.AzdCommand.Args) == 0 {
+			continue
+		}
+
+		switch step.AzdCommand.Args[0] {
+		case "package":
+			preview.Steps = append(preview.Steps, step)
+		case "provision":
+			args := slices.Clone(step.AzdCommand.Args)
+			if !slices.Contains(args, "--preview") {
+				args = append(args, "--preview")
+			}
+
+			preview.Steps = append(preview.Steps, &workflow.Step{AzdCommand: workflow.Command{Args: args}})
+		}
+	}
+
+	return preview
+}
+
 func getCmdUpHelpDescription(c *cobra.Command) string {
 	return generateCmdHelpDescription(
 		heredoc.Docf(
@@ -0,0 +1,155 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/alpha"
+	"github.com/azure/azure-dev/cli/azd/pkg/auth"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/workflow"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockenv"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingAzdRunner records the commands run by a workflow instead of running them.
+type recordingAzdRunner struct {
+	args     []string
+	commands [][]string
+}
+
+func (r *recordingAzdRunner) SetArgs(args []string) {
+	r.args = args
+}
+
+func (r *recordingAzdRunner) ExecuteContext(ctx context.Context) error {
+	r.commands = append(r.commands, r.args)
+	return nil
+}
+
+func newTestUpAction(
+	mockContext *mocks.MockContext,
+	flags *upFlags,
+	projectConfig *project.ProjectConfig,
+	azdRunner workflow.AzdCommandRunner,
+) *upAction {
+	mockContext.Container.MustRegisterNamedSingleton(string(provisioning.Test), func() provisioning.Provider {
+		return &refreshTestProvider{}
+	})
+
+	env := environment.New("dev")
+	envManager := &mockenv.MockEnvManager{}
+
+	return newUpAction(
+		flags,
+		mockContext.Console,
+		env,
+		auth.LoggedInGuard{},
+		projectConfig,
+		provisioning.NewManager(
+			mockContext.Container,
+			func() (provisioning.ProviderKind, error) { return provisioning.Test, nil },
+			envManager,
+			env,
+			mockContext.Console,
+			alpha.NewFeaturesManagerWithConfig(mockContext.Config),
+		),
+		project.NewImportManager(nil),
+		workflow.NewRunner(azdRunner, mockContext.Console),
+	).(*upAction)
+}
+
+func Test_UpAction_Preview(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	projectConfig := &project.ProjectConfig{
+		Name: "up",
+		Path: t.TempDir(),
+		Infra: provisioning.Options{
+			Provider: provisioning.Test,
+		},
+		Services: map[string]*project.ServiceConfig{
+			"web": {Name: "web", Host: project.StaticWebAppTarget},
+			"api": {Name: "api", Host: project.ContainerAppTarget},
+		},
+	}
+
+	azdRunner := &recordingAzdRunner{}
+	action := newTestUpAction(mockContext, &upFlags{preview: true}, projectConfig, azdRunner)
+
+	result, err := action.Run(*mockContext.Context)
+	require.NoError(t, err)
+	require.Contains(t, result.Message.FollowUp, "No changes were applied")
+
+	// Nothing is deployed and provisioning is only previewed
+	require.Equal(t, [][]string{
+		{"package", "--all"},
+		{"provision", "--preview"},
+	}, azdRunner.commands)
+
+	consoleOutput := strings.Join(mockContext.Console.Output(), "\n")
+	require.Contains(t, consoleOutput, "Services that would be deployed")
+	require.Contains(t, consoleOutput, "api")
+	require.Contains(t, consoleOutput, "web")
+}
+
+func Test_UpAction_PreviewCustomWorkflow(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	projectConfig := &project.ProjectConfig{
+		Name: "up",
+		Path: t.TempDir(),
+		Infra: provisioning.Options{
+			Provider: provisioning.Test,
+		},
+		Workflows: workflow.WorkflowMap{
+			"up": {
+				Steps: []*workflow.Step{
+					{AzdCommand: workflow.Command{Args: []string{"provision", "--no-state"}}},
+					{AzdCommand: workflow.Command{Args: []string{"package", "--all"}}},
+					{AzdCommand: workflow.Command{Args: []string{"deploy", "--all"}}},
+					{AzdCommand: workflow.Command{Args: []string{"env", "set", "DEPLOYED", "true"}}},
+				},
+			},
+		},
+	}
+
+	azdRunner := &recordingAzdRunner{}
+	action := newTestUpAction(mockContext, &upFlags{preview: true}, projectConfig, azdRunner)
+
+	_, err := action.Run(*mockContext.Context)
+	require.NoError(t, err)
+
+	require.Equal(t, [][]string{
+		{"provision", "--no-state", "--preview"},
+		{"package", "--all"},
+	}, azdRunner.commands)
+}
+
+func Test_UpAction_NoPreview(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	projectConfig := &project.ProjectConfig{
+		Name: "up",
+		Path: t.TempDir(),
+		Infra: provisioning.Options{
+			Provider: provisioning.Test,
+		},
+	}
+
+	azdRunner := &recordingAzdRunner{}
+	action := newTestUpAction(mockContext, &upFlags{}, projectConfig, azdRunner)
+
+	_, err := action.Run(*mockContext.Context)
+	require.NoError(t, err)
+
+	require.Equal(t, [][]string{
+		{"package", "--all"},
+		{"provision"},
+		{"deploy", "--all"},
+	}, azdRunner.commands)
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/azure/azure-dev/cli/azd/test/snapshot"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/require"
 )
 
@@ -53,3 +54,24 @@ func resolveTemplate(text string, data interface{}) (string, error) {
 	// update `>` and `<`
 	return strings.ReplaceAll(strings.ReplaceAll(finalBuffer.String(), "&lt;", "<"), "&gt;", ">"), nil
 }
+
+// A local flag with the name of a global flag shadows it, so the global flag doesn't apply to the command and is
+// hidden from its usage.
+func TestFlagsDontShadowGlobalFlags(t *testing.T) {
+	root := NewRootCmd(false, nil, nil)
+
+	var check func(cmd *cobra.Command)
+	check = func(cmd *cobra.Command) {
+		cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+			if global := root.PersistentFlags().Lookup(flag.Name); global != nil && global != flag {
+				t.Errorf("flag '--%s' of '%s' shadows the global flag", flag.Name, cmd.CommandPath())
+			}
+		})
+
+		for _, c := range cmd.Commands() {
+			check(c)
+		}
+	}
+
+	check(root)
+}
@@ -7,21 +7,31 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
 	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry"
 	"github.com/azure/azure-dev/cli/azd/pkg/contracts"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/update"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
 
 type versionFlags struct {
+	check  bool
 	global *internal.GlobalCommandOptions
 }
 
 func (v *versionFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.BoolVar(
+		&v.check,
+		"check",
+		false,
+		"Checks whether a newer version of azd is available. azd isn't updated.")
 	v.global = global
 }
 
@@ -33,10 +43,11 @@ func newVersionFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions)
 }
 
 type versionAction struct {
-	flags     *versionFlags
-	formatter output.Formatter
-	writer    io.Writer
-	console   input.Console
+	flags      *versionFlags
+	formatter  output.Formatter
+	writer     io.Writer
+	console    input.Console
+	httpClient httputil.HttpClient
 }
 
 func newVersionAction(
@@ -44,19 +55,50 @@ func newVersionAction(
 	formatter output.Formatter,
 	writer io.Writer,
 	console input.Console,
+	httpClient httputil.HttpClient,
 ) actions.Action {
 	return &versionAction{
-		flags:     flags,
-		formatter: formatter,
-		writer:    writer,
-		console:   console,
+		flags:      flags,
+		formatter:  formatter,
+		writer:     writer,
+		console:    console,
+		httpClient: httpClient,
 	}
 }
 
+// updateCheckMaxAge is how long the latest version cached by azd is used by `azd version --check`, which is shorter than
+// for the update check of the other commands, since the user is asking for updates.
+const updateCheckMaxAge = time.Hour
+
 func (v *versionAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	var checkResult *update.CheckResult
+	if v.flags.check {
+		checker, err := update.NewChecker(v.httpClient, telemetry.IsTelemetryEnabled())
+		if err != nil {
+			return nil, err
+		}
+
+		checkResult, err = checker.Check(ctx, updateCheckMaxAge)
+		if err != nil {
+			return nil, fmt.Errorf("checking for updates: %w", err)
+		}
+	}
+
 	switch v.formatter.Kind() {
 	case output.NoneFormat:
-		fmt.Fprintf(v.console.Handles().Stdout, "azd version %s\n", internal.Version)
+		stdout := v.console.Handles().Stdout
+		fmt.Fprintf(stdout, "azd version %s\n", internal.Version)
+
+		if checkResult != nil {
+			if checkResult.UpdateAvailable() {
+				fmt.Fprintf(stdout, "A newer version of azd is available: %s\n", checkResult.Latest)
+				fmt.Fprintf(stdout, "To update to the latest version, %s\n", update.UpgradeInstructions())
+			} else if internal.IsDevVersion() {
+				fmt.Fprintf(stdout, "This is a dev build, the latest version is %s\n", checkResult.Latest)
+			} else {
+				fmt.Fprintln(stdout, "azd is up to date")
+			}
+		}
 	case output.JsonFormat:
 		var result contracts.VersionResult
 		versionSpec := internal.VersionInfo()
@@ -64,6 +106,13 @@ func (v *versionAction) Run(ctx context.Context) (*actions.ActionResult, error)
 		result.Azd.Commit = versionSpec.Commit
 		result.Azd.Version = versionSpec.Version.String()
 
+		if checkResult != nil {
+			result.Update = &contracts.VersionUpdateResult{
+				Latest:          checkResult.Latest.String(),
+				UpdateAvailable: checkResult.UpdateAvailable(),
+			}
+		}
+
 		err := v.formatter.Format(result, v.writer, nil)
 		if err != nil {
 			return nil, err
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/contracts"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/update"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_VersionCheck(t *testing.T) {
+	t.Setenv("AZD_CONFIG_DIR", t.TempDir())
+	t.Setenv(update.SkipUpdateCheckEnvVarName, "false")
+
+	original := internal.Version
+	internal.Version = "1.5.0 (commit 0000000000000000000000000000000000000000)"
+	t.Cleanup(func() { internal.Version = original })
+
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.URL.Host == "aka.ms"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return &http.Response{
+			Request:    request,
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader("1.6.0\n")),
+		}, nil
+	})
+
+	buf := &bytes.Buffer{}
+	action := newVersionAction(
+		&versionFlags{check: true},
+		&output.JsonFormatter{},
+		buf,
+		mockContext.Console,
+		mockContext.HttpClient,
+	)
+	_, err := action.Run(*mockContext.Context)
+	require.NoError(t, err)
+
+	var result contracts.VersionResult
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	require.Equal(t, "1.5.0", result.Azd.Version)
+	require.Equal(t, &contracts.VersionUpdateResult{Latest: "1.6.0", UpdateAvailable: true}, result.Update)
+}
@@ -0,0 +1,53 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// noop is a reference service target plugin. It handles the 'noop' host without deploying anything, and shows the
+// protocol plugins implement: azd runs the plugin with the operation as its only argument, writes a
+// [project.PluginRequest] to its standard input and reads a [project.PluginResponse] from its standard output.
+//
+// To install the plugin, build it into a directory of the plugins directory of the user configuration directory,
+// next to its manifest:
+//
+//	go build -o ~/.azd/plugins/noop/azd-noop .
+//	cp azd-plugin.json ~/.azd/plugins/noop/
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	if len(os.Args) != 2 {
+		return fmt.Errorf("usage: %s <package|deploy|endpoints>", os.Args[0])
+	}
+
+	var request project.PluginRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&request); err != nil {
+		return fmt.Errorf("reading request: %w", err)
+	}
+
+	var response project.PluginResponse
+	switch os.Args[1] {
+	case "package":
+		// The package built by the language of the service is deployed as is
+		response.PackagePath = request.PackagePath
+	case "deploy":
+		response.Details = fmt.Sprintf("Service '%s' was not deployed by the noop plugin", request.Service.Name)
+	case "endpoints":
+	default:
+		return fmt.Errorf("unsupported operation '%s'", os.Args[1])
+	}
+
+	return json.NewEncoder(os.Stdout).Encode(response)
+}
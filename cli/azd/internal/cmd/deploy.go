@@ -5,32 +5,54 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
 	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/pkg/account"
 	"github.com/azure/azure-dev/cli/azd/pkg/alpha"
+	"github.com/azure/azure-dev/cli/azd/pkg/artifacts"
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
 	"github.com/azure/azure-dev/cli/azd/pkg/cloud"
+	"github.com/azure/azure-dev/cli/azd/pkg/containerapps"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/lazy"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
 	"github.com/azure/azure-dev/cli/azd/pkg/project"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/benbjohnson/clock"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
 
 type DeployFlags struct {
 	serviceName string
+	language    string
 	All         bool
 	fromPackage string
+	follow      bool
+	since       time.Duration
+	timeout     time.Duration
+	dryRun      bool
+	push        bool
 	global      *internal.GlobalCommandOptions
 	*internal.EnvFlag
 }
@@ -38,6 +60,24 @@ type DeployFlags struct {
 func (d *DeployFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
 	d.BindNonCommon(local, global)
 	d.bindCommon(local, global)
+	local.StringVar(
+		&d.language,
+		"language",
+		"",
+		"Deploys the services written in a language, like python or js.",
+	)
+	local.BoolVar(
+		&d.dryRun,
+		"dry-run",
+		false,
+		"Packages the services and shows what would be deployed, without deploying to Azure.",
+	)
+	local.BoolVar(
+		&d.push,
+		"push",
+		false,
+		"Pushes the container images to the container registry when '--dry-run' is set.",
+	)
 }
 
 func (d *DeployFlags) BindNonCommon(
@@ -69,7 +109,26 @@ func (d *DeployFlags) bindCommon(local *pflag.FlagSet, global *internal.GlobalCo
 		&d.fromPackage,
 		"from-package",
 		"",
-		"Deploys the application from an existing package.",
+		"Deploys the application from an existing package, or the URI of a package uploaded by 'azd package --upload'.",
+	)
+	local.BoolVar(
+		&d.follow,
+		"follow",
+		false,
+		"Streams the console logs of deployed container apps until interrupted.",
+	)
+	local.DurationVar(
+		&d.since,
+		"since",
+		0,
+		"Only shows logs newer than a relative duration like 5m or 1h when following logs.",
+	)
+	local.DurationVar(
+		&d.timeout,
+		"deploy-timeout",
+		0,
+		"Cancels the deployment when it takes longer than a duration like 30m. Unlike --timeout, following logs isn't "+
+			"limited.",
 	)
 }
 
@@ -107,6 +166,7 @@ type DeployAction struct {
 	projectConfig       *project.ProjectConfig
 	azdCtx              *azdcontext.AzdContext
 	env                 *environment.Environment
+	envManager          environment.Manager
 	projectManager      project.ProjectManager
 	serviceManager      project.ServiceManager
 	resourceManager     project.ResourceManager
@@ -119,6 +179,10 @@ type DeployAction struct {
 	commandRunner       exec.CommandRunner
 	alphaFeatureManager *alpha.FeatureManager
 	importManager       *project.ImportManager
+	containerAppService containerapps.ContainerAppService
+	containerHelper     *project.ContainerHelper
+	progressReporter    project.ProgressReporter
+	artifactStore       *lazy.Lazy[artifacts.Store]
 }
 
 func NewDeployAction(
@@ -130,6 +194,7 @@ func NewDeployAction(
 	resourceManager project.ResourceManager,
 	azdCtx *azdcontext.AzdContext,
 	environment *environment.Environment,
+	envManager environment.Manager,
 	accountManager account.Manager,
 	portalUrlBase cloud.PortalUrlBase,
 	azCli azcli.AzCli,
@@ -139,6 +204,10 @@ func NewDeployAction(
 	writer io.Writer,
 	alphaFeatureManager *alpha.FeatureManager,
 	importManager *project.ImportManager,
+	containerAppService containerapps.ContainerAppService,
+	containerHelper *project.ContainerHelper,
+	progressReporter project.ProgressReporter,
+	artifactStore *lazy.Lazy[artifacts.Store],
 ) actions.Action {
 	return &DeployAction{
 		flags:               flags,
@@ -146,6 +215,7 @@ func NewDeployAction(
 		projectConfig:       projectConfig,
 		azdCtx:              azdCtx,
 		env:                 environment,
+		envManager:          envManager,
 		projectManager:      projectManager,
 		serviceManager:      serviceManager,
 		resourceManager:     resourceManager,
@@ -158,12 +228,23 @@ func NewDeployAction(
 		commandRunner:       commandRunner,
 		alphaFeatureManager: alphaFeatureManager,
 		importManager:       importManager,
+		containerAppService: containerAppService,
+		containerHelper:     containerHelper,
+		progressReporter:    progressReporter,
+		artifactStore:       artifactStore,
 	}
 }
 
+// DeploymentResult is the result of `azd deploy`, written when the output format is JSON and returned as the data of the
+// action result.
 type DeploymentResult struct {
-	Timestamp time.Time                               `json:"timestamp"`
-	Services  map[string]*project.ServiceDeployResult `json:"services"`
+	Timestamp time.Time `json:"timestamp"`
+	// The result of each deployed service, keyed by service name, with its endpoints and deploy duration.
+	Services map[string]*project.ServiceDeployResult `json:"services"`
+	// The time taken to deploy all the services, excluding user interaction time.
+	Duration time.Duration `json:"duration"`
+	// DryRun is set when the services were packaged with `--dry-run`, without being deployed.
+	DryRun bool `json:"dryRun,omitempty"`
 }
 
 func (da *DeployAction) Run(ctx context.Context) (*actions.ActionResult, error) {
@@ -172,14 +253,36 @@ func (da *DeployAction) Run(ctx context.Context) (*actions.ActionResult, error)
 		targetServiceName = da.args[0]
 	}
 
-	serviceNameWarningCheck(da.console, da.flags.serviceName, "deploy")
+	serviceNameWarningCheck(ctx, da.console, da.flags.serviceName, "deploy")
+
+	if da.flags.push && !da.flags.dryRun {
+		return nil, errors.New("'--push' can only be specified when '--dry-run' is set")
+	}
+
+	if da.flags.dryRun && da.flags.follow {
+		return nil, errors.New("'--follow' cannot be specified when '--dry-run' is set")
+	}
 
-	if da.env.GetSubscriptionId() == "" {
+	// A dry run only needs the provisioned container registry to push images
+	if (!da.flags.dryRun || da.flags.push) && da.env.GetSubscriptionId() == "" {
 		return nil, errors.New(
 			"infrastructure has not been provisioned. Run `azd provision`",
 		)
 	}
 
+	var language project.ServiceLanguageKind
+	if da.flags.language != "" {
+		if targetServiceName != "" {
+			return nil, errors.New("cannot specify both --language and <service>")
+		}
+
+		var err error
+		if language, err = project.ParseLanguageFilter(da.flags.language); err != nil {
+			return nil, err
+		}
+	}
+
+	// Filtering on a language selects all the services of the language
 	targetServiceName, err := getTargetServiceName(
 		ctx,
 		da.projectManager,
@@ -187,17 +290,26 @@ func (da *DeployAction) Run(ctx context.Context) (*actions.ActionResult, error)
 		da.projectConfig,
 		string(project.ServiceEventDeploy),
 		targetServiceName,
-		da.flags.All,
+		da.flags.All || language != project.ServiceLanguageNone,
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	selected := func(svc *project.ServiceConfig) bool {
+		return (targetServiceName == "" || svc.Name == targetServiceName) &&
+			(language == project.ServiceLanguageNone || svc.MatchesLanguage(language))
+	}
+
 	if da.flags.All && da.flags.fromPackage != "" {
 		return nil, errors.New(
 			"'--from-package' cannot be specified when '--all' is set. Specify a specific service by passing a <service>")
 	}
 
+	if da.flags.since != 0 && !da.flags.follow {
+		return nil, errors.New("'--since' can only be specified when '--follow' is set")
+	}
+
 	if targetServiceName == "" && da.flags.fromPackage != "" {
 		return nil, errors.New(
 			//nolint:lll
@@ -205,21 +317,36 @@ func (da *DeployAction) Run(ctx context.Context) (*actions.ActionResult, error)
 		)
 	}
 
+	fromPackage := da.flags.fromPackage
+	if isArtifactUri(fromPackage) {
+		packagePath, cleanup, err := da.downloadPackage(ctx, fromPackage)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+
+		fromPackage = packagePath
+	}
+
 	if err := da.projectManager.Initialize(ctx, da.projectConfig); err != nil {
 		return nil, err
 	}
 
-	if err := da.projectManager.EnsureServiceTargetTools(ctx, da.projectConfig, func(svc *project.ServiceConfig) bool {
-		return targetServiceName == "" || svc.Name == targetServiceName
-	}); err != nil {
+	if err := da.projectManager.EnsureServiceTargetTools(ctx, da.projectConfig, selected); err != nil {
 		return nil, err
 	}
 
 	// Command title
+	title := "Deploying services (azd deploy)"
+	if da.flags.dryRun {
+		title = "Validating the deployment of services (azd deploy --dry-run)"
+	}
 	da.console.MessageUxItem(ctx, &ux.MessageTitle{
-		Title: "Deploying services (azd deploy)",
+		Title: title,
 	})
 
+	da.warnOnInfraDrift(ctx)
+
 	startTime := time.Now()
 
 	deployResults := map[string]*project.ServiceDeployResult{}
@@ -228,18 +355,43 @@ func (da *DeployAction) Run(ctx context.Context) (*actions.ActionResult, error)
 		return nil, err
 	}
 
+	// The deploy deadline covers packaging and deploying all services, while the timeout of a service is enforced by
+	// the service manager.
+	deployCtx := ctx
+	if da.flags.timeout > 0 {
+		var cancel context.CancelFunc
+		deployCtx, cancel = context.WithTimeout(ctx, da.flags.timeout)
+		defer cancel()
+	}
+
+	// The progress of each service reports the percentage of the deployed services
+	deployCount := 0
+	for _, svc := range stableServices {
+		if selected(svc) {
+			deployCount++
+		}
+	}
+
+	if deployCount == 0 && language != project.ServiceLanguageNone {
+		return nil, fmt.Errorf("no services are written in language '%s'", da.flags.language)
+	}
+
 	for _, svc := range stableServices {
 		stepMessage := fmt.Sprintf("Deploying service %s", svc.Name)
+		if da.flags.dryRun {
+			stepMessage = fmt.Sprintf("Validating service %s", svc.Name)
+		}
 		da.console.ShowSpinner(ctx, stepMessage, input.Step)
 
-		// Skip this service if both cases are true:
-		// 1. The user specified a service name
-		// 2. This service is not the one the user specified
-		if targetServiceName != "" && targetServiceName != svc.Name {
+		// Skip this service when the user specified a service name or a language it doesn't match
+		if !selected(svc) {
 			da.console.StopSpinner(ctx, stepMessage, input.StepSkipped)
 			continue
 		}
 
+		percent := len(deployResults) * 100 / deployCount
+		serviceStartTime := time.Now()
+
 		if alphaFeatureId, isAlphaFeature := alpha.IsFeatureKey(string(svc.Host)); isAlphaFeature {
 			// alpha feature on/off detection for host is done during initialization.
 			// This is just for displaying the warning during deployment.
@@ -247,20 +399,22 @@ func (da *DeployAction) Run(ctx context.Context) (*actions.ActionResult, error)
 		}
 
 		var packageResult *project.ServicePackageResult
-		if da.flags.fromPackage != "" {
+		if fromPackage != "" {
 			// --from-package set, skip packaging
+			if err := svc.Host.ValidatePackagePath(fromPackage); err != nil {
+				da.console.StopSpinner(ctx, stepMessage, input.StepFailed)
+				return nil, fmt.Errorf("deploying service '%s' from package: %w", svc.Name, err)
+			}
+
 			packageResult = &project.ServicePackageResult{
-				PackagePath: da.flags.fromPackage,
+				PackagePath: fromPackage,
 			}
 		} else {
 			//  --from-package not set, package the application
-			packageTask := da.serviceManager.Package(ctx, svc, nil, nil)
+			packageTask := da.serviceManager.Package(deployCtx, svc, nil, nil)
 			done := make(chan struct{})
 			go func() {
-				for packageProgress := range packageTask.Progress() {
-					progressMessage := fmt.Sprintf("Deploying service %s (%s)", svc.Name, packageProgress.Message)
-					da.console.ShowSpinner(ctx, progressMessage, input.Step)
-				}
+				project.ReportProgress(ctx, da.progressReporter, svc.Name, percent, da.flags.dryRun, packageTask.Progress())
 				close(done)
 			}()
 
@@ -270,17 +424,27 @@ func (da *DeployAction) Run(ctx context.Context) (*actions.ActionResult, error)
 			// do not stop progress here as next step is to deploy
 			if err != nil {
 				da.console.StopSpinner(ctx, stepMessage, input.StepFailed)
+				return nil, da.deployError(ctx, deployCtx, svc.Name, stableServices, deployResults, err)
+			}
+		}
+
+		if da.flags.dryRun {
+			deployResult, err := da.dryRun(deployCtx, svc, packageResult)
+			da.console.StopSpinner(ctx, stepMessage, input.GetStepResultFormat(err))
+			if err != nil {
 				return nil, err
 			}
+
+			deployResult.Duration = since(serviceStartTime)
+			deployResults[svc.Name] = deployResult
+			da.console.MessageUxItem(ctx, deployResult)
+			continue
 		}
 
-		deployTask := da.serviceManager.Deploy(ctx, svc, packageResult)
+		deployTask := da.serviceManager.Deploy(deployCtx, svc, packageResult)
 		done := make(chan struct{})
 		go func() {
-			for deployProgress := range deployTask.Progress() {
-				progressMessage := fmt.Sprintf("Deploying service %s (%s)", svc.Name, deployProgress.Message)
-				da.console.ShowSpinner(ctx, progressMessage, input.Step)
-			}
+			project.ReportProgress(ctx, da.progressReporter, svc.Name, percent, false, deployTask.Progress())
 			close(done)
 		}()
 
@@ -289,41 +453,401 @@ func (da *DeployAction) Run(ctx context.Context) (*actions.ActionResult, error)
 		<-done
 		da.console.StopSpinner(ctx, stepMessage, input.GetStepResultFormat(err))
 		if err != nil {
-			return nil, err
+			return nil, da.deployError(ctx, deployCtx, svc.Name, stableServices, deployResults, err)
 		}
 
+		deployResult.Duration = since(serviceStartTime)
 		deployResults[svc.Name] = deployResult
 
 		// report deploy outputs
 		da.console.MessageUxItem(ctx, deployResult)
 	}
 
+	if da.flags.dryRun {
+		return da.dryRunResult(deployResults, startTime)
+	}
+
+	// The services are already deployed, so failing to record the deploy time doesn't fail the command
+	if err := da.saveLastDeployTime(ctx); err != nil {
+		log.Printf("saving the last deploy time: %v", err)
+		da.console.Message(ctx, output.WithWarningFormat(
+			"WARNING: The deploy time couldn't be saved in environment '%s': %v", da.env.Name(), err))
+	}
+
+	deploymentResult := &DeploymentResult{
+		Timestamp: time.Now(),
+		Services:  deployResults,
+		Duration:  since(startTime),
+	}
+
 	if da.formatter.Kind() == output.JsonFormat {
-		deployResult := DeploymentResult{
-			Timestamp: time.Now(),
-			Services:  deployResults,
+		if fmtErr := da.formatter.Format(deploymentResult, da.writer, nil); fmtErr != nil {
+			return nil, fmt.Errorf("deploy result could not be displayed: %w", fmtErr)
 		}
+	}
 
-		if fmtErr := da.formatter.Format(deployResult, da.writer, nil); fmtErr != nil {
+	message := &actions.ResultMessage{
+		Header: fmt.Sprintf("Your application was deployed to Azure in %s.", ux.DurationAsText(since(startTime))),
+		FollowUp: getResourceGroupFollowUp(ctx,
+			da.formatter,
+			da.portalUrlBase,
+			da.projectConfig,
+			da.resourceManager,
+			da.env,
+			false,
+		),
+	}
+
+	if da.flags.follow {
+		// The result is shown before the logs, which are followed until azd is interrupted, so it isn't shown again
+		da.console.MessageUxItem(ctx, &ux.ActionResult{SuccessMessage: message.Header, FollowUp: message.FollowUp})
+
+		if err := da.followLogs(ctx, deployResults); err != nil {
+			return nil, err
+		}
+
+		return &actions.ActionResult{
+			Data: deploymentResult,
+		}, nil
+	}
+
+	return &actions.ActionResult{
+		Message: message,
+		Data:    deploymentResult,
+	}, nil
+}
+
+// warnOnInfraDrift warns when the infrastructure files changed since the last provision of the environment, since the
+// services may be deployed to resources that don't match the infrastructure anymore. The check is best effort and
+// never fails the deployment.
+func (da *DeployAction) warnOnInfraDrift(ctx context.Context) {
+	provisionedHash, has := da.env.Config.GetString(provisioning.ProvisionedInfraHashPath)
+	if !has || provisionedHash == "" {
+		return
+	}
+
+	infraRoot := da.projectConfig.Infra.Path
+	if infraRoot == "" {
+		infraRoot = project.DefaultPath
+	}
+	if !filepath.IsAbs(infraRoot) {
+		infraRoot = filepath.Join(da.projectConfig.Path, infraRoot)
+	}
+
+	// Infrastructure generated in memory, like for .NET Aspire projects, isn't checked
+	if _, err := os.Stat(infraRoot); err != nil {
+		return
+	}
+
+	infraHash, err := provisioning.InfraHash(infraRoot)
+	if err != nil {
+		log.Printf("skipping infrastructure drift check: %v", err)
+		return
+	}
+
+	if infraHash != provisionedHash {
+		da.console.Message(ctx, output.WithWarningFormat(
+			"WARNING: The infrastructure in '%s' changed since the last provision of environment '%s'.\n"+
+				"Services may be deployed to resources that are out of date. Run %s to apply the changes.\n",
+			infraRoot,
+			da.env.Name(),
+			output.WithHighLightFormat("azd provision"),
+		))
+	}
+}
+
+// dryRun returns the result of deploying a packaged service without deploying it. The container image of a service
+// hosted in a container is pushed to the container registry when '--push' is set.
+func (da *DeployAction) dryRun(
+	ctx context.Context,
+	svc *project.ServiceConfig,
+	packageResult *project.ServicePackageResult,
+) (*project.ServiceDeployResult, error) {
+	plan := &deployPlan{
+		Package: packageResult.PackagePath,
+		Host:    svc.Host,
+	}
+
+	// The target resource only exists once the environment is provisioned
+	if da.env.GetSubscriptionId() != "" {
+		targetResource, err := da.serviceManager.GetTargetResource(ctx, svc)
+		if err != nil {
+			return nil, fmt.Errorf("resolving the target resource of service '%s': %w", svc.Name, err)
+		}
+
+		plan.TargetResourceId = fmt.Sprintf(
+			"%s/providers/%s/%s",
+			azure.ResourceGroupRID(targetResource.SubscriptionId(), targetResource.ResourceGroupName()),
+			targetResource.ResourceType(),
+			targetResource.ResourceName(),
+		)
+		plan.TargetResource = fmt.Sprintf(
+			"%s (%s) in resource group %s",
+			targetResource.ResourceName(),
+			targetResource.ResourceType(),
+			targetResource.ResourceGroupName(),
+		)
+	}
+
+	if svc.Host.RequiresContainer() {
+		registryName, err := da.containerHelper.RegistryName(ctx, svc)
+		if err != nil {
+			log.Printf("resolving the container registry of service '%s': %v", svc.Name, err)
+		}
+		plan.Registry = registryName
+	}
+
+	deployResult := &project.ServiceDeployResult{
+		Package:          packageResult,
+		TargetResourceId: plan.TargetResourceId,
+		Kind:             svc.Host,
+		Details:          plan,
+	}
+
+	if da.flags.push && svc.Host.RequiresContainer() {
+		pushTask := da.containerHelper.Deploy(ctx, svc, packageResult, nil, false)
+		pushResult, err := pushTask.Await()
+		if err != nil {
+			return nil, fmt.Errorf("pushing the container image of service '%s': %w", svc.Name, err)
+		}
+
+		plan.Pushed = true
+		plan.Push = pushResult.Details
+	}
+
+	return deployResult, nil
+}
+
+// isArtifactUri returns true when the package passed to --from-package is the URI of a package uploaded to the
+// artifact store, rather than a local file or container image reference.
+func isArtifactUri(packagePath string) bool {
+	return strings.HasPrefix(packagePath, "https://") || strings.HasPrefix(packagePath, "http://")
+}
+
+// downloadPackage downloads the package uploaded to the artifact store at artifactUri to a temporary directory, returning
+// the path of the downloaded package and a function removing it.
+func (da *DeployAction) downloadPackage(ctx context.Context, artifactUri string) (string, func(), error) {
+	store, err := da.artifactStore.GetValue()
+	if err != nil {
+		return "", nil, err
+	}
+
+	parsed, err := url.Parse(artifactUri)
+	if err != nil {
+		return "", nil, fmt.Errorf("parsing package uri '%s': %w", artifactUri, err)
+	}
+
+	tempDir, err := os.MkdirTemp("", "azd-package")
+	if err != nil {
+		return "", nil, fmt.Errorf("creating temporary directory: %w", err)
+	}
+	cleanup := func() {
+		_ = os.RemoveAll(tempDir)
+	}
+
+	// Keep the name of the package, since hosts validate the kind of package from its extension
+	packagePath := filepath.Join(tempDir, path.Base(parsed.Path))
+	if err := store.Download(ctx, artifactUri, packagePath); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("downloading package '%s': %w", artifactUri, err)
+	}
+
+	return packagePath, cleanup, nil
+}
+
+// deployPlan describes how `azd deploy` would deploy a packaged service, as reported by `azd deploy --dry-run`.
+type deployPlan struct {
+	// The package that would be deployed
+	Package string `json:"package"`
+	// The kind of Azure service hosting the service
+	Host project.ServiceTargetKind `json:"host"`
+	// The resource the package would be deployed to, empty when the environment isn't provisioned
+	TargetResourceId string `json:"targetResourceId,omitempty"`
+	// The name, type and resource group of the target resource, for display
+	TargetResource string `json:"-"`
+	// The container registry the image would be pushed to, for services hosted in a container
+	Registry string `json:"registry,omitempty"`
+	// Pushed is set when the container image was pushed with `--push`
+	Pushed bool        `json:"pushed,omitempty"`
+	Push   interface{} `json:"push,omitempty"`
+}
+
+func (dp *deployPlan) ToString(currentIndentation string) string {
+	lines := []string{fmt.Sprintf("%s- Would deploy %s to %s", currentIndentation, dp.Package, dp.Host)}
+
+	if dp.TargetResource != "" {
+		lines = append(lines, fmt.Sprintf("%s  Target resource: %s", currentIndentation, dp.TargetResource))
+	} else {
+		lines = append(lines, fmt.Sprintf(
+			"%s  Target resource: not provisioned yet, run %s",
+			currentIndentation,
+			output.WithHighLightFormat("azd provision"),
+		))
+	}
+
+	if dp.Registry != "" {
+		pushed := "not pushed, use --push to push it"
+		if dp.Pushed {
+			pushed = "pushed"
+		}
+		lines = append(lines, fmt.Sprintf("%s  Container registry: %s (image %s)", currentIndentation, dp.Registry, pushed))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (dp *deployPlan) MarshalJSON() ([]byte, error) {
+	return json.Marshal(*dp)
+}
+
+// dryRunResult returns the result of `azd deploy --dry-run`, which doesn't record a deploy time in the environment.
+func (da *DeployAction) dryRunResult(
+	deployResults map[string]*project.ServiceDeployResult,
+	startTime time.Time,
+) (*actions.ActionResult, error) {
+	deploymentResult := &DeploymentResult{
+		Timestamp: time.Now(),
+		Services:  deployResults,
+		Duration:  since(startTime),
+		DryRun:    true,
+	}
+
+	if da.formatter.Kind() == output.JsonFormat {
+		if fmtErr := da.formatter.Format(deploymentResult, da.writer, nil); fmtErr != nil {
 			return nil, fmt.Errorf("deploy result could not be displayed: %w", fmtErr)
 		}
 	}
 
 	return &actions.ActionResult{
 		Message: &actions.ResultMessage{
-			Header: fmt.Sprintf("Your application was deployed to Azure in %s.", ux.DurationAsText(since(startTime))),
-			FollowUp: getResourceGroupFollowUp(ctx,
-				da.formatter,
-				da.portalUrlBase,
-				da.projectConfig,
-				da.resourceManager,
-				da.env,
-				false,
-			),
+			Header: fmt.Sprintf(
+				"Your application was packaged in %s. Nothing was deployed to Azure.", ux.DurationAsText(since(startTime))),
 		},
+		Data: deploymentResult,
 	}, nil
 }
 
+// deployError reports which services were deployed before the deployment of a service failed, and returns the error
+// to surface. Exceeding the deploy deadline is reported distinctly from the failure of the service.
+func (da *DeployAction) deployError(
+	ctx context.Context,
+	deployCtx context.Context,
+	serviceName string,
+	services []*project.ServiceConfig,
+	deployResults map[string]*project.ServiceDeployResult,
+	err error,
+) error {
+	deployed := []string{}
+	for _, svc := range services {
+		if _, has := deployResults[svc.Name]; has {
+			deployed = append(deployed, svc.Name)
+		}
+	}
+
+	if len(deployed) > 0 {
+		da.console.Message(ctx, output.WithWarningFormat(
+			"\nDeployed %s before service '%s' failed.", ux.ListAsText(deployed), serviceName))
+	}
+
+	if da.flags.timeout > 0 && ctx.Err() == nil && errors.Is(deployCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf(
+			"deployment exceeded the deadline of %s while deploying service '%s': %w", da.flags.timeout, serviceName, err)
+	}
+
+	return err
+}
+
+// saveLastDeployTime records the time of the deployment in the environment.
+func (da *DeployAction) saveLastDeployTime(ctx context.Context) error {
+	if err := da.env.SetLastDeployTime(time.Now()); err != nil {
+		return fmt.Errorf("recording the deploy time: %w", err)
+	}
+
+	if err := da.envManager.Save(ctx, da.env); err != nil {
+		return fmt.Errorf("saving environment: %w", err)
+	}
+
+	return nil
+}
+
+// followLogs streams the console logs of the deployed container apps until the context is cancelled or the user
+// interrupts azd. Lines are prefixed with the service name when more than one container app was deployed.
+func (da *DeployAction) followLogs(ctx context.Context, deployResults map[string]*project.ServiceDeployResult) error {
+	followers := map[string]*containerapps.LogFollower{}
+	serviceNames := []string{}
+	for serviceName, result := range deployResults {
+		if result.Kind == project.ContainerAppTarget && result.TargetResourceId != "" {
+			serviceNames = append(serviceNames, serviceName)
+		}
+	}
+	slices.Sort(serviceNames)
+
+	if len(serviceNames) == 0 {
+		da.console.Message(ctx, output.WithWarningFormat(
+			"WARNING: '--follow' is only supported for services hosted on Azure Container Apps."))
+		return nil
+	}
+
+	var mu sync.Mutex
+	for _, serviceName := range serviceNames {
+		resourceId, err := arm.ParseResourceID(deployResults[serviceName].TargetResourceId)
+		if err != nil {
+			return fmt.Errorf("parsing resource id of service '%s': %w", serviceName, err)
+		}
+
+		var writer io.Writer = &lockedWriter{mu: &mu, writer: da.writer}
+		if len(serviceNames) > 1 {
+			writer = &lockedWriter{mu: &mu, writer: da.writer, prefix: fmt.Sprintf("[%s] ", serviceName)}
+		}
+
+		source := containerapps.NewLogSource(
+			da.containerAppService,
+			resourceId.SubscriptionID,
+			resourceId.ResourceGroupName,
+			resourceId.Name,
+		)
+		followers[serviceName] = containerapps.NewLogFollower(source, writer, clock.New(), containerapps.LogFollowerOptions{
+			Since: da.flags.since,
+		})
+	}
+
+	da.console.Message(ctx, fmt.Sprintf(
+		"Following logs for %s. Press Ctrl+C to stop.\n", ux.ListAsText(serviceNames)))
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(serviceNames))
+	for i, serviceName := range serviceNames {
+		wg.Add(1)
+		go func(i int, serviceName string) {
+			defer wg.Done()
+			if err := followers[serviceName].Follow(ctx); err != nil {
+				errs[i] = fmt.Errorf("following logs of service '%s': %w", serviceName, err)
+			}
+		}(i, serviceName)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// lockedWriter serializes the lines written by concurrent log followers to a shared writer.
+type lockedWriter struct {
+	mu     *sync.Mutex
+	writer io.Writer
+	prefix string
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, err := io.WriteString(w.writer, w.prefix); err != nil {
+		return 0, err
+	}
+
+	return w.writer.Write(p)
+}
+
 func GetCmdDeployHelpDescription(*cobra.Command) string {
 	return generateCmdHelpDescription("Deploy application to Azure.", []string{
 		formatHelpNote(
@@ -350,5 +874,17 @@ func GetCmdDeployHelpFooter(*cobra.Command) string {
 		"Deploy the service named 'api' to Azure from a previously generated package.": output.WithHighLightFormat(
 			"azd deploy api --from-package <package-path>",
 		),
+		"Deploy the service named 'api' and stream its logs from the last 5 minutes.": output.WithHighLightFormat(
+			"azd deploy api --follow --since 5m",
+		),
+		"Deploy all services, cancelling the deployment when it takes longer than 30 minutes.": output.WithHighLightFormat(
+			"azd deploy --all --deploy-timeout 30m",
+		),
+		"Deploy all the services written in Python to Azure.": output.WithHighLightFormat(
+			"azd deploy --language python",
+		),
+		"Package all services and show what would be deployed, without deploying to Azure.": output.WithHighLightFormat(
+			"azd deploy --all --dry-run",
+		),
 	})
 }
@@ -0,0 +1,526 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/artifacts"
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/lazy"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockenv"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockinput"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProjectManager struct {
+	project.ProjectManager
+}
+
+func (m *fakeProjectManager) Initialize(ctx context.Context, projectConfig *project.ProjectConfig) error {
+	return nil
+}
+
+func (m *fakeProjectManager) EnsureServiceTargetTools(
+	ctx context.Context,
+	projectConfig *project.ProjectConfig,
+	serviceFilterFn project.ServiceFilterPredicate,
+) error {
+	return nil
+}
+
+// fakeServiceManager deploys each service to an endpoint named after the service.
+type fakeServiceManager struct {
+	project.ServiceManager
+	// deployed records the names of the deployed services.
+	deployed []string
+	// onDeploy, when set, is called with the package of each deployed service.
+	onDeploy func(packageOutput *project.ServicePackageResult)
+}
+
+func (m *fakeServiceManager) Package(
+	ctx context.Context,
+	serviceConfig *project.ServiceConfig,
+	buildOutput *project.ServiceBuildResult,
+	options *project.PackageOptions,
+) *async.TaskWithProgress[*project.ServicePackageResult, project.ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*project.ServicePackageResult, project.ServiceProgress]) {
+			task.SetResult(&project.ServicePackageResult{PackagePath: serviceConfig.Name + ".zip"})
+		})
+}
+
+func (m *fakeServiceManager) Deploy(
+	ctx context.Context,
+	serviceConfig *project.ServiceConfig,
+	packageOutput *project.ServicePackageResult,
+) *async.TaskWithProgress[*project.ServiceDeployResult, project.ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*project.ServiceDeployResult, project.ServiceProgress]) {
+			m.deployed = append(m.deployed, serviceConfig.Name)
+			if m.onDeploy != nil {
+				m.onDeploy(packageOutput)
+			}
+			task.SetResult(&project.ServiceDeployResult{
+				Package:          packageOutput,
+				TargetResourceId: "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg/sites/" + serviceConfig.Name,
+				Kind:             project.AppServiceTarget,
+				Endpoints:        []string{"https://" + serviceConfig.Name + ".azurewebsites.net/"},
+			})
+		})
+}
+
+func (m *fakeServiceManager) GetTargetResource(
+	ctx context.Context,
+	serviceConfig *project.ServiceConfig,
+) (*environment.TargetResource, error) {
+	return environment.NewTargetResource("SUBSCRIPTION_ID", "rg", "app-"+serviceConfig.Name, "Microsoft.Web/sites"), nil
+}
+
+// fakeArtifactStore serves the packages of the artifact store from memory.
+type fakeArtifactStore struct {
+	artifacts.Store
+	packages map[string][]byte
+	// downloaded records the uris of the downloaded packages.
+	downloaded []string
+}
+
+func (s *fakeArtifactStore) Download(ctx context.Context, uri string, path string) error {
+	s.downloaded = append(s.downloaded, uri)
+	return os.WriteFile(path, s.packages[uri], osutil.PermissionFile)
+}
+
+type fakeResourceManager struct {
+	project.ResourceManager
+}
+
+func (m *fakeResourceManager) GetResourceGroupName(
+	ctx context.Context, subscriptionId string, projectConfig *project.ProjectConfig) (string, error) {
+	return "", errors.New("no resource group")
+}
+
+func Test_DeployAction_Result(t *testing.T) {
+	projectConfig := &project.ProjectConfig{
+		Name: "test",
+		Services: map[string]*project.ServiceConfig{
+			"api": {Name: "api", Host: project.AppServiceTarget, Language: project.ServiceLanguagePython},
+			"web": {Name: "web", Host: project.AppServiceTarget, Language: project.ServiceLanguageJavaScript},
+			// Disabled for the environment the services are deployed to
+			"debug": {Name: "debug", Host: project.AppServiceTarget, DisabledFor: []string{"dev"}},
+		},
+	}
+
+	run := func(t *testing.T, formatter output.Formatter, language string) (*DeploymentResult, *bytes.Buffer) {
+		flags := NewDeployFlagsFromEnvAndOptions(&internal.EnvFlag{}, &internal.GlobalCommandOptions{})
+		flags.All = language == ""
+		flags.language = language
+
+		env := environment.NewWithValues("dev", map[string]string{
+			environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+		})
+		envManager := &mockenv.MockEnvManager{}
+		envManager.On("Save", mock.Anything, env).Return(nil)
+
+		var buf bytes.Buffer
+		action := &DeployAction{
+			flags:           flags,
+			projectConfig:   projectConfig,
+			env:             env,
+			envManager:      envManager,
+			projectManager:  &fakeProjectManager{},
+			serviceManager:  &fakeServiceManager{},
+			resourceManager: &fakeResourceManager{},
+			formatter:       formatter,
+			writer:          &buf,
+			console:         mockinput.NewMockConsole(),
+			importManager: project.NewImportManagerForEnvironment(nil, mockinput.NewMockConsole(), func() string {
+				return env.Name()
+			}),
+			progressReporter: project.ProgressReporterFunc(func(ctx context.Context, event project.ProgressEvent) {
+			}),
+		}
+
+		actionResult, err := action.Run(context.Background())
+		require.NoError(t, err)
+
+		deploymentResult, ok := actionResult.Data.(*DeploymentResult)
+		require.True(t, ok)
+
+		// The time of the deployment is saved in the environment
+		lastDeployTime, has := env.GetLastDeployTime()
+		require.True(t, has)
+		require.WithinDuration(t, deploymentResult.Timestamp, lastDeployTime, time.Minute)
+		envManager.AssertCalled(t, "Save", mock.Anything, env)
+
+		return deploymentResult, &buf
+	}
+
+	t.Run("Data", func(t *testing.T) {
+		result, _ := run(t, &output.JsonFormatter{}, "")
+
+		require.Len(t, result.Services, 2)
+		require.Equal(t, []string{"https://api.azurewebsites.net/"}, result.Services["api"].Endpoints)
+		require.Equal(t, "api.zip", result.Services["api"].Package.PackagePath)
+		require.Equal(t, project.AppServiceTarget, result.Services["web"].Kind)
+		require.Positive(t, result.Services["web"].Duration)
+		require.GreaterOrEqual(t, result.Duration, result.Services["web"].Duration)
+	})
+
+	t.Run("DisabledService", func(t *testing.T) {
+		result, _ := run(t, &output.JsonFormatter{}, "")
+		require.NotContains(t, result.Services, "debug")
+	})
+
+	t.Run("Language", func(t *testing.T) {
+		result, _ := run(t, &output.JsonFormatter{}, "python")
+		require.Len(t, result.Services, 1)
+		require.Contains(t, result.Services, "api")
+	})
+
+	t.Run("JsonOutput", func(t *testing.T) {
+		result, buf := run(t, &output.JsonFormatter{}, "")
+
+		var written DeploymentResult
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &written))
+		require.Equal(t, result.Duration, written.Duration)
+		require.Equal(t, result.Services["api"].Endpoints, written.Services["api"].Endpoints)
+	})
+
+	t.Run("TextOutput", func(t *testing.T) {
+		// The result is returned to programmatic callers regardless of the output format
+		result, buf := run(t, &output.NoneFormatter{}, "")
+
+		require.Len(t, result.Services, 2)
+		require.Empty(t, buf.String())
+	})
+}
+
+func Test_DeployAction_Follow(t *testing.T) {
+	flags := NewDeployFlagsFromEnvAndOptions(&internal.EnvFlag{}, &internal.GlobalCommandOptions{})
+	flags.All = true
+	flags.follow = true
+
+	env := environment.NewWithValues("dev", map[string]string{
+		environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+	})
+	envManager := &mockenv.MockEnvManager{}
+	envManager.On("Save", mock.Anything, env).Return(nil)
+
+	console := mockinput.NewMockConsole()
+	action := &DeployAction{
+		flags: flags,
+		projectConfig: &project.ProjectConfig{
+			Name: "test",
+			Services: map[string]*project.ServiceConfig{
+				"api": {Name: "api", Host: project.AppServiceTarget, Language: project.ServiceLanguagePython},
+			},
+		},
+		env:             env,
+		envManager:      envManager,
+		projectManager:  &fakeProjectManager{},
+		serviceManager:  &fakeServiceManager{},
+		resourceManager: &fakeResourceManager{},
+		formatter:       &output.NoneFormatter{},
+		writer:          &bytes.Buffer{},
+		console:         console,
+		importManager: project.NewImportManagerForEnvironment(nil, console, func() string {
+			return env.Name()
+		}),
+		progressReporter: project.ProgressReporterFunc(func(ctx context.Context, event project.ProgressEvent) {
+		}),
+	}
+
+	actionResult, err := action.Run(context.Background())
+	require.NoError(t, err)
+
+	// The result is shown once, before following the logs, instead of again when the command completes
+	require.Nil(t, actionResult.Message)
+	require.NotNil(t, actionResult.Data)
+
+	deployed := 0
+	for _, message := range console.Output() {
+		deployed += strings.Count(message, "Your application was deployed to Azure")
+	}
+	require.Equal(t, 1, deployed)
+}
+
+func Test_DeployAction_DryRun(t *testing.T) {
+	projectConfig := &project.ProjectConfig{
+		Name: "test",
+		Services: map[string]*project.ServiceConfig{
+			"api": {Name: "api", Host: project.AppServiceTarget, Language: project.ServiceLanguagePython},
+			"web": {Name: "web", Host: project.AppServiceTarget, Language: project.ServiceLanguageJavaScript},
+		},
+	}
+
+	newAction := func(flags *DeployFlags, env *environment.Environment) (*DeployAction, *fakeServiceManager) {
+		serviceManager := &fakeServiceManager{}
+		return &DeployAction{
+			flags:           flags,
+			projectConfig:   projectConfig,
+			env:             env,
+			envManager:      &mockenv.MockEnvManager{},
+			projectManager:  &fakeProjectManager{},
+			serviceManager:  serviceManager,
+			resourceManager: &fakeResourceManager{},
+			formatter:       &output.NoneFormatter{},
+			writer:          &bytes.Buffer{},
+			console:         mockinput.NewMockConsole(),
+			importManager: project.NewImportManagerForEnvironment(nil, mockinput.NewMockConsole(), func() string {
+				return env.Name()
+			}),
+			progressReporter: project.ProgressReporterFunc(func(ctx context.Context, event project.ProgressEvent) {
+			}),
+		}, serviceManager
+	}
+
+	t.Run("NotDeployed", func(t *testing.T) {
+		flags := NewDeployFlagsFromEnvAndOptions(&internal.EnvFlag{}, &internal.GlobalCommandOptions{})
+		flags.All = true
+		flags.dryRun = true
+
+		// The environment doesn't need to be provisioned
+		env := environment.New("dev")
+		action, serviceManager := newAction(flags, env)
+
+		actionResult, err := action.Run(context.Background())
+		require.NoError(t, err)
+		require.Empty(t, serviceManager.deployed)
+
+		deploymentResult, ok := actionResult.Data.(*DeploymentResult)
+		require.True(t, ok)
+		require.True(t, deploymentResult.DryRun)
+		require.Len(t, deploymentResult.Services, 2)
+		require.Equal(t, "api.zip", deploymentResult.Services["api"].Package.PackagePath)
+		require.Empty(t, deploymentResult.Services["api"].Endpoints)
+		require.Contains(t, actionResult.Message.Header, "Nothing was deployed to Azure")
+
+		// The environment isn't saved
+		_, has := env.GetLastDeployTime()
+		require.False(t, has)
+		action.envManager.(*mockenv.MockEnvManager).AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Plan", func(t *testing.T) {
+		flags := NewDeployFlagsFromEnvAndOptions(&internal.EnvFlag{}, &internal.GlobalCommandOptions{})
+		flags.All = true
+		flags.dryRun = true
+
+		action, serviceManager := newAction(flags, environment.NewWithValues("dev", map[string]string{
+			environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+		}))
+
+		actionResult, err := action.Run(context.Background())
+		require.NoError(t, err)
+		require.Empty(t, serviceManager.deployed)
+
+		// The target resource of each service is resolved and reported
+		deploymentResult := actionResult.Data.(*DeploymentResult)
+		require.Equal(t,
+			"/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg/providers/Microsoft.Web/sites/app-api",
+			deploymentResult.Services["api"].TargetResourceId)
+
+		consoleOutput := strings.Join(action.console.(*mockinput.MockConsole).Output(), "\n")
+		require.Contains(t, consoleOutput, "Would deploy api.zip to appservice")
+		require.Contains(t, consoleOutput, "Target resource: app-api (Microsoft.Web/sites) in resource group rg")
+	})
+
+	t.Run("PushWithoutDryRun", func(t *testing.T) {
+		flags := NewDeployFlagsFromEnvAndOptions(&internal.EnvFlag{}, &internal.GlobalCommandOptions{})
+		flags.All = true
+		flags.push = true
+
+		action, serviceManager := newAction(flags, environment.NewWithValues("dev", map[string]string{
+			environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+		}))
+
+		_, err := action.Run(context.Background())
+		require.ErrorContains(t, err, "'--push' can only be specified when '--dry-run' is set")
+		require.Empty(t, serviceManager.deployed)
+	})
+}
+
+func Test_DeployAction_FromPackageUri(t *testing.T) {
+	projectConfig := &project.ProjectConfig{
+		Name: "test",
+		Services: map[string]*project.ServiceConfig{
+			"api": {Name: "api", Host: project.AppServiceTarget, Language: project.ServiceLanguagePython},
+		},
+	}
+
+	packageUri := "https://account.blob.core.windows.net/packages/test/api/20261015120000/api.zip"
+	store := &fakeArtifactStore{packages: map[string][]byte{packageUri: []byte("package")}}
+
+	flags := NewDeployFlagsFromEnvAndOptions(&internal.EnvFlag{}, &internal.GlobalCommandOptions{})
+	flags.fromPackage = packageUri
+
+	env := environment.NewWithValues("dev", map[string]string{
+		environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+	})
+	envManager := &mockenv.MockEnvManager{}
+	envManager.On("Save", mock.Anything, env).Return(nil)
+
+	// The downloaded package is deployed
+	var deployedContents []byte
+	serviceManager := &fakeServiceManager{
+		onDeploy: func(packageOutput *project.ServicePackageResult) {
+			contents, err := os.ReadFile(packageOutput.PackagePath)
+			require.NoError(t, err)
+			deployedContents = contents
+		},
+	}
+
+	action := &DeployAction{
+		flags:           flags,
+		args:            []string{"api"},
+		projectConfig:   projectConfig,
+		env:             env,
+		envManager:      envManager,
+		projectManager:  &fakeProjectManager{},
+		serviceManager:  serviceManager,
+		resourceManager: &fakeResourceManager{},
+		formatter:       &output.NoneFormatter{},
+		writer:          &bytes.Buffer{},
+		console:         mockinput.NewMockConsole(),
+		importManager: project.NewImportManagerForEnvironment(nil, mockinput.NewMockConsole(), func() string {
+			return env.Name()
+		}),
+		progressReporter: project.ProgressReporterFunc(func(ctx context.Context, event project.ProgressEvent) {
+		}),
+		artifactStore: lazy.From[artifacts.Store](store),
+	}
+
+	actionResult, err := action.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{packageUri}, store.downloaded)
+	require.Equal(t, []string{"api"}, serviceManager.deployed)
+	require.Equal(t, []byte("package"), deployedContents)
+
+	// The downloaded package keeps its name and is removed after the deployment
+	deploymentResult, ok := actionResult.Data.(*DeploymentResult)
+	require.True(t, ok)
+	packagePath := deploymentResult.Services["api"].Package.PackagePath
+	require.Equal(t, "api.zip", filepath.Base(packagePath))
+	require.NoFileExists(t, packagePath)
+}
+
+func Test_DeployAction_InfraDrift(t *testing.T) {
+	projectDir := t.TempDir()
+	infraDir := filepath.Join(projectDir, "infra")
+	require.NoError(t, os.Mkdir(infraDir, osutil.PermissionDirectory))
+	require.NoError(t, os.WriteFile(filepath.Join(infraDir, "main.bicep"), []byte("param location string"), 0600))
+
+	provisionedHash, err := provisioning.InfraHash(infraDir)
+	require.NoError(t, err)
+
+	projectConfig := &project.ProjectConfig{
+		Name: "test",
+		Path: projectDir,
+		Services: map[string]*project.ServiceConfig{
+			"api": {Name: "api", Host: project.AppServiceTarget},
+		},
+	}
+
+	run := func(t *testing.T) []string {
+		flags := NewDeployFlagsFromEnvAndOptions(&internal.EnvFlag{}, &internal.GlobalCommandOptions{})
+		flags.All = true
+
+		env := environment.NewWithValues("dev", map[string]string{
+			environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+		})
+		require.NoError(t, env.Config.Set(provisioning.ProvisionedInfraHashPath, provisionedHash))
+		envManager := &mockenv.MockEnvManager{}
+		envManager.On("Save", mock.Anything, env).Return(nil)
+
+		console := mockinput.NewMockConsole()
+		action := &DeployAction{
+			flags:           flags,
+			projectConfig:   projectConfig,
+			env:             env,
+			envManager:      envManager,
+			projectManager:  &fakeProjectManager{},
+			serviceManager:  &fakeServiceManager{},
+			resourceManager: &fakeResourceManager{},
+			formatter:       &output.NoneFormatter{},
+			writer:          io.Discard,
+			console:         console,
+			importManager: project.NewImportManagerForEnvironment(nil, mockinput.NewMockConsole(), func() string {
+				return env.Name()
+			}),
+			progressReporter: project.ProgressReporterFunc(func(ctx context.Context, event project.ProgressEvent) {
+			}),
+		}
+
+		// The drift warning never fails the deployment
+		_, err := action.Run(context.Background())
+		require.NoError(t, err)
+
+		return console.Output()
+	}
+
+	t.Run("Unchanged", func(t *testing.T) {
+		require.NotContains(t, strings.Join(run(t), "\n"), "changed since the last provision")
+	})
+
+	t.Run("Changed", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(
+			filepath.Join(infraDir, "main.bicep"), []byte("param location string\nparam sku string"), 0600))
+
+		require.Contains(t, strings.Join(run(t), "\n"), "changed since the last provision of environment 'dev'")
+	})
+}
+
+func Test_DeployAction_SaveFailure(t *testing.T) {
+	flags := NewDeployFlagsFromEnvAndOptions(&internal.EnvFlag{}, &internal.GlobalCommandOptions{})
+	flags.All = true
+
+	env := environment.NewWithValues("dev", map[string]string{
+		environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+	})
+	envManager := &mockenv.MockEnvManager{}
+	envManager.On("Save", mock.Anything, env).Return(errors.New("disk full"))
+
+	console := mockinput.NewMockConsole()
+	action := &DeployAction{
+		flags: flags,
+		projectConfig: &project.ProjectConfig{
+			Name: "test",
+			Services: map[string]*project.ServiceConfig{
+				"api": {Name: "api", Host: project.AppServiceTarget, Language: project.ServiceLanguagePython},
+			},
+		},
+		env:             env,
+		envManager:      envManager,
+		projectManager:  &fakeProjectManager{},
+		serviceManager:  &fakeServiceManager{},
+		resourceManager: &fakeResourceManager{},
+		formatter:       &output.NoneFormatter{},
+		writer:          &bytes.Buffer{},
+		console:         console,
+		importManager: project.NewImportManagerForEnvironment(nil, console, func() string {
+			return env.Name()
+		}),
+		progressReporter: project.ProgressReporterFunc(func(ctx context.Context, event project.ProgressEvent) {
+		}),
+	}
+
+	// The services are deployed, so the command succeeds and only warns about the environment
+	actionResult, err := action.Run(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, actionResult.Data)
+	require.Contains(t, strings.Join(console.Output(), "\n"), "The deploy time couldn't be saved in environment 'dev'")
+}
@@ -10,11 +10,16 @@ import (
 
 	"github.com/AlecAivazis/survey/v2/terminal"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/internal/tracing"
 	"github.com/azure/azure-dev/cli/azd/internal/tracing/fields"
 	"github.com/azure/azure-dev/cli/azd/pkg/auth"
 	"github.com/azure/azure-dev/cli/azd/pkg/azapi"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 )
@@ -28,7 +33,11 @@ func MapError(err error, span tracing.Span) {
 	var armDeployErr *azapi.AzureDeploymentError
 	var toolExecErr *exec.ExitError
 	var authFailedErr *auth.AuthFailedError
-	if errors.As(err, &respErr) {
+	if errors.Is(err, internal.ErrCommandTimeout) {
+		// Checked first, since the operation cancelled at the deadline may fail with any of the other errors
+		errCode = "cmd.timeout"
+		span.SetAttributes(fields.CmdErrorCategory.String("timeout"))
+	} else if errors.As(err, &respErr) {
 		serviceName := "other"
 		statusCode := -1
 		errDetails = append(errDetails, fields.ServiceErrorCode.String(respErr.ErrorCode))
@@ -51,23 +60,7 @@ func MapError(err error, span tracing.Span) {
 		errCode = fmt.Sprintf("service.%s.%d", serviceName, statusCode)
 	} else if errors.As(err, &armDeployErr) {
 		errDetails = append(errDetails, fields.ServiceName.String("arm"))
-		codes := []*deploymentErrorCode{}
-		var collect func(details []*azapi.DeploymentErrorLine, frame int)
-		collect = func(details []*azapi.DeploymentErrorLine, frame int) {
-			code := collectCode(details, frame)
-			if code != nil {
-				codes = append(codes, code)
-				frame = frame + 1
-			}
-
-			for _, detail := range details {
-				if detail.Inner != nil {
-					collect(detail.Inner, frame)
-				}
-			}
-		}
-
-		collect([]*azapi.DeploymentErrorLine{armDeployErr.Details}, 0)
+		codes := deploymentErrorCodes(armDeployErr)
 		if len(codes) > 0 {
 			if codesJson, err := json.Marshal(codes); err != nil {
 				log.Println("telemetry: failed to marshal arm error codes", err)
@@ -118,11 +111,69 @@ func MapError(err error, span tracing.Span) {
 	span.SetStatus(codes.Error, errCode)
 }
 
+// ExitCode maps the given error to the exit code of azd, as documented in docs/exit-codes.md.
+func ExitCode(err error) int {
+	if err == nil {
+		return internal.SuccessExitCode
+	}
+
+	var missingToolsErr *tools.MissingToolsError
+	var reLoginErr *auth.ReLoginRequiredError
+	var authFailedErr *auth.AuthFailedError
+	var configErr *project.ConfigError
+	var respErr *azcore.ResponseError
+	var armDeployErr *azapi.AzureDeploymentError
+	switch {
+	// Checked first, since the operation cancelled at the deadline may fail with any of the other errors
+	case errors.Is(err, internal.ErrCommandTimeout):
+		return internal.TimeoutExitCode
+	case errors.As(err, &missingToolsErr):
+		return internal.ToolMissingExitCode
+	case errors.Is(err, auth.ErrNoCurrentUser),
+		errors.As(err, &reLoginErr),
+		errors.As(err, &authFailedErr),
+		errors.As(err, &respErr) && respErr.StatusCode == 401,
+		errors.As(err, &respErr) && respErr.StatusCode == 403:
+		return internal.AuthErrorExitCode
+	case errors.Is(err, azdcontext.ErrNoProject),
+		errors.Is(err, environment.ErrNotFound),
+		errors.Is(err, environment.ErrNameNotSpecified),
+		errors.As(err, &configErr):
+		return internal.ConfigErrorExitCode
+	case errors.As(err, &respErr), errors.As(err, &armDeployErr):
+		return internal.ServiceErrorExitCode
+	default:
+		return internal.UserErrorExitCode
+	}
+}
+
 type deploymentErrorCode struct {
 	Code  string `json:"error.code"`
 	Frame int    `json:"error.frame"`
 }
 
+// deploymentErrorCodes returns the error codes of the failed deployment, from the outermost to the innermost error.
+func deploymentErrorCodes(armDeployErr *azapi.AzureDeploymentError) []*deploymentErrorCode {
+	codes := []*deploymentErrorCode{}
+	var collect func(details []*azapi.DeploymentErrorLine, frame int)
+	collect = func(details []*azapi.DeploymentErrorLine, frame int) {
+		code := collectCode(details, frame)
+		if code != nil {
+			codes = append(codes, code)
+			frame = frame + 1
+		}
+
+		for _, detail := range details {
+			if detail != nil && detail.Inner != nil {
+				collect(detail.Inner, frame)
+			}
+		}
+	}
+
+	collect([]*azapi.DeploymentErrorLine{armDeployErr.Details}, 0)
+	return codes
+}
+
 func collectCode(lines []*azapi.DeploymentErrorLine, frame int) *deploymentErrorCode {
 	if len(lines) == 0 {
 		return nil
@@ -3,14 +3,20 @@ package cmd
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"testing"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/internal/tracing/fields"
 	"github.com/azure/azure-dev/cli/azd/pkg/auth"
 	"github.com/azure/azure-dev/cli/azd/pkg/azapi"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/test/mocks/mocktracing"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel/attribute"
@@ -29,6 +35,17 @@ func Test_MapError(t *testing.T) {
 			wantErrReason:  "UnknownError",
 			wantErrDetails: nil,
 		},
+		{
+			name: "WithTimeoutError",
+			err: fmt.Errorf("%w after 1s: %w", internal.ErrCommandTimeout, &exec.ExitError{
+				Cmd:      "any",
+				ExitCode: -1,
+			}),
+			wantErrReason: "cmd.timeout",
+			wantErrDetails: []attribute.KeyValue{
+				fields.CmdErrorCategory.String("timeout"),
+			},
+		},
 		{
 			name:           "WithOtherError",
 			err:            errors.New("something bad happened!"),
@@ -157,6 +174,44 @@ func Test_MapError(t *testing.T) {
 	}
 }
 
+func Test_ExitCode(t *testing.T) {
+	responseError := func(statusCode int) error {
+		return &azcore.ResponseError{StatusCode: statusCode, RawResponse: &http.Response{StatusCode: statusCode}}
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"WithNilError", nil, 0},
+		{"WithOtherError", errors.New("invalid argument"), 1},
+		{"WithConfigError", &project.ConfigError{Err: errors.New("parsing project file")}, 2},
+		{"WithNoProject", fmt.Errorf("loading project: %w", azdcontext.ErrNoProject), 2},
+		{"WithEnvNotFound", fmt.Errorf("loading environment: %w", environment.ErrNotFound), 2},
+		{"WithEnvNotSpecified", environment.ErrNameNotSpecified, 2},
+		{"WithNotLoggedIn", fmt.Errorf("fetching token: %w", auth.ErrNoCurrentUser), 3},
+		{"WithReLoginRequired", &auth.ReLoginRequiredError{}, 3},
+		{"WithAuthFailed", &auth.AuthFailedError{}, 3},
+		{"WithUnauthorized", responseError(http.StatusUnauthorized), 3},
+		{"WithForbidden", responseError(http.StatusForbidden), 3},
+		{"WithMissingTools", &tools.MissingToolsError{Operation: "building service 'api'"}, 4},
+		{"WithNotFound", responseError(http.StatusNotFound), 5},
+		{"WithServerError", fmt.Errorf("deploying: %w", responseError(http.StatusInternalServerError)), 5},
+		{"WithDeploymentError", &azapi.AzureDeploymentError{}, 5},
+		{
+			name: "WithTimeoutError",
+			err:  fmt.Errorf("%w after 1s: %w", internal.ErrCommandTimeout, responseError(http.StatusForbidden)),
+			want: 124,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, ExitCode(tt.err))
+		})
+	}
+}
+
 func Test_cmdAsName(t *testing.T) {
 	tests := []struct {
 		name string
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/auth"
+	"github.com/azure/azure-dev/cli/azd/pkg/azapi"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+// The categories of the errors written with `--json-errors`, one per exit code.
+var exitCodeCategories = map[int]string{
+	internal.UserErrorExitCode:    "user",
+	internal.ConfigErrorExitCode:  "config",
+	internal.AuthErrorExitCode:    "auth",
+	internal.ToolMissingExitCode:  "tool",
+	internal.ServiceErrorExitCode: "service",
+	internal.TimeoutExitCode:      "timeout",
+}
+
+// JsonError is the error written to stderr when a command fails with `--json-errors`.
+type JsonError struct {
+	// The category of the error, like 'auth' or 'service', matching the exit code of azd.
+	Category string `json:"category"`
+	// The message of the error, as displayed without `--json-errors`.
+	Message string `json:"message"`
+	// Details depending on the error, like the status code of a failed request to Azure.
+	Details map[string]any `json:"details,omitempty"`
+	// The correlation id sent with the requests to Azure, to look up the failed operation. Empty when the command failed
+	// before running.
+	CorrelationId string `json:"correlationId,omitempty"`
+}
+
+// NewJsonError classifies the given error as the JSON error written with `--json-errors`.
+func NewJsonError(err error, correlationId string) *JsonError {
+	jsonErr := &JsonError{
+		Category:      exitCodeCategories[ExitCode(err)],
+		Message:       err.Error(),
+		Details:       map[string]any{},
+		CorrelationId: correlationId,
+	}
+
+	var respErr *azcore.ResponseError
+	var armDeployErr *azapi.AzureDeploymentError
+	var missingToolsErr *tools.MissingToolsError
+	var authFailedErr *auth.AuthFailedError
+	var toolExecErr *exec.ExitError
+	var suggestionErr *azcli.ErrorWithSuggestion
+	if errors.As(err, &respErr) {
+		jsonErr.Details["statusCode"] = respErr.StatusCode
+		if respErr.ErrorCode != "" {
+			jsonErr.Details["errorCode"] = respErr.ErrorCode
+		}
+		if respErr.RawResponse != nil && respErr.RawResponse.Request != nil {
+			jsonErr.Details["service"], _ = mapService(respErr.RawResponse.Request.Host)
+		}
+	} else if errors.As(err, &armDeployErr) {
+		errorCodes := []string{}
+		for _, code := range deploymentErrorCodes(armDeployErr) {
+			errorCodes = append(errorCodes, strings.Split(code.Code, ",")...)
+		}
+		if len(errorCodes) > 0 {
+			jsonErr.Details["errorCodes"] = errorCodes
+		}
+	} else if errors.As(err, &missingToolsErr) {
+		toolNames := make([]string, 0, len(missingToolsErr.Tools))
+		for _, tool := range missingToolsErr.Tools {
+			toolNames = append(toolNames, tool.Name)
+		}
+		jsonErr.Details["tools"] = toolNames
+	} else if errors.As(err, &authFailedErr) && authFailedErr.Parsed != nil {
+		jsonErr.Details["errorCodes"] = authFailedErr.Parsed.ErrorCodes
+	} else if errors.As(err, &toolExecErr) {
+		jsonErr.Details["tool"] = cmdAsName(toolExecErr.Cmd)
+		jsonErr.Details["exitCode"] = toolExecErr.ExitCode
+	}
+
+	if errors.As(err, &suggestionErr) {
+		jsonErr.Details["suggestion"] = suggestionErr.Suggestion
+	}
+
+	return jsonErr
+}
+
+// WriteJsonError writes the given error as a single line of JSON to w.
+func WriteJsonError(w io.Writer, err error, correlationId string) error {
+	jsonErr, marshalErr := json.Marshal(NewJsonError(err, correlationId))
+	if marshalErr != nil {
+		return fmt.Errorf("marshalling error: %w", marshalErr)
+	}
+
+	_, writeErr := fmt.Fprintln(w, string(jsonErr))
+	return writeErr
+}
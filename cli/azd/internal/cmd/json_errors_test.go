@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/azapi"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewJsonError(t *testing.T) {
+	armRequest, err := http.NewRequest(http.MethodGet, "https://management.azure.com/subscriptions", nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		err         error
+		wantErr     *JsonError
+		wantDetails map[string]any
+	}{
+		{
+			name:    "WithOtherError",
+			err:     errors.New("invalid argument"),
+			wantErr: &JsonError{Category: "user", Message: "invalid argument"},
+		},
+		{
+			name:    "WithConfigError",
+			err:     azdcontext.ErrNoProject,
+			wantErr: &JsonError{Category: "config", Message: azdcontext.ErrNoProject.Error()},
+		},
+		{
+			name: "WithResponseError",
+			err: &azcore.ResponseError{
+				ErrorCode:  "AuthorizationFailed",
+				StatusCode: http.StatusForbidden,
+				RawResponse: &http.Response{
+					StatusCode: http.StatusForbidden,
+					Request:    armRequest,
+				},
+			},
+			wantErr: &JsonError{Category: "auth"},
+			wantDetails: map[string]any{
+				"statusCode": http.StatusForbidden,
+				"errorCode":  "AuthorizationFailed",
+				"service":    "arm",
+			},
+		},
+		{
+			name: "WithDeploymentError",
+			err: &azapi.AzureDeploymentError{
+				Details: &azapi.DeploymentErrorLine{
+					Code: "DeploymentFailed",
+					Inner: []*azapi.DeploymentErrorLine{
+						{Code: "Conflict"},
+					},
+				},
+			},
+			wantErr: &JsonError{Category: "service"},
+			wantDetails: map[string]any{
+				"errorCodes": []string{"DeploymentFailed", "Conflict"},
+			},
+		},
+		{
+			name: "WithMissingTools",
+			err: &tools.MissingToolsError{
+				Tools: []tools.MissingTool{{Name: "Docker", Err: errors.New("not found")}},
+			},
+			wantErr: &JsonError{Category: "tool"},
+			wantDetails: map[string]any{
+				"tools": []string{"Docker"},
+			},
+		},
+		{
+			name: "WithSuggestion",
+			err: &azcli.ErrorWithSuggestion{
+				Err:        errors.New("failed"),
+				Suggestion: "run `azd auth login`",
+			},
+			wantErr: &JsonError{Category: "user", Message: "failed"},
+			wantDetails: map[string]any{
+				"suggestion": "run `azd auth login`",
+			},
+		},
+		{
+			name:    "WithTimeoutError",
+			err:     fmt.Errorf("%w after 1s", internal.ErrCommandTimeout),
+			wantErr: &JsonError{Category: "timeout", Message: "command timed out after 1s"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonErr := NewJsonError(tt.err, "trace-id")
+			require.Equal(t, tt.wantErr.Category, jsonErr.Category)
+			require.Equal(t, tt.err.Error(), jsonErr.Message)
+			if tt.wantErr.Message != "" {
+				require.Equal(t, tt.wantErr.Message, jsonErr.Message)
+			}
+			require.Equal(t, "trace-id", jsonErr.CorrelationId)
+
+			if tt.wantDetails == nil {
+				require.Empty(t, jsonErr.Details)
+			} else {
+				require.Equal(t, tt.wantDetails, jsonErr.Details)
+			}
+		})
+	}
+}
+
+func Test_WriteJsonError(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteJsonError(&buf, azdcontext.ErrNoProject, ""))
+	require.Equal(t, 1, bytes.Count(buf.Bytes(), []byte("\n")))
+
+	var written map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &written))
+	require.Equal(t, map[string]any{
+		"category": "config",
+		"message":  azdcontext.ErrNoProject.Error(),
+	}, written)
+}
@@ -2,9 +2,9 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"strings"
 	"time"
 
@@ -12,9 +12,11 @@ import (
 	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/pkg/account"
 	"github.com/azure/azure-dev/cli/azd/pkg/cloud"
+	"github.com/azure/azure-dev/cli/azd/pkg/contracts"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/logging"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
 	"github.com/azure/azure-dev/cli/azd/pkg/project"
@@ -28,6 +30,10 @@ type ProvisionFlags struct {
 	noProgress            bool
 	preview               bool
 	ignoreDeploymentState bool
+	ignoreCompileCache    bool
+	ephemeral             bool
+	ttl                   time.Duration
+	parameters            []string
 	global                *internal.GlobalCommandOptions
 	*internal.EnvFlag
 }
@@ -38,6 +44,9 @@ const (
 	responsibleAITerms          = "until you agree to Responsible AI terms for this resource"
 )
 
+// defaultEphemeralTtl is the time after which the resources of an ephemeral provision can be deleted, when --ttl isn't set.
+const defaultEphemeralTtl = 24 * time.Hour
+
 func (i *ProvisionFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
 	i.BindNonCommon(local, global)
 	i.bindCommon(local, global)
@@ -57,6 +66,28 @@ func (i *ProvisionFlags) bindCommon(local *pflag.FlagSet, global *internal.Globa
 		"no-state",
 		false,
 		"Do not use latest Deployment State (bicep only).")
+	local.BoolVar(
+		&i.ignoreCompileCache,
+		"no-cache",
+		false,
+		"Do not use the cached compiled templates (bicep only).")
+	local.BoolVar(
+		&i.ephemeral,
+		"ephemeral",
+		false,
+		"Provisions a throwaway environment, like a pull request preview, into a uniquely named resource group, "+
+			"which the template reads from AZURE_RESOURCE_GROUP. Only the values needed by azd down are saved.")
+	local.DurationVar(
+		&i.ttl,
+		"ttl",
+		0,
+		"The time after which the resources of an --ephemeral provision can be deleted, set as the azd-expires-on "+
+			"tag of the resource groups (default 24h).")
+	local.StringArrayVar(
+		&i.parameters,
+		"parameter",
+		nil,
+		"Overrides a parameter of the template, as key=value. Values support environment variables (bicep only).")
 
 	i.EnvFlag = &internal.EnvFlag{}
 	i.EnvFlag.Bind(local, global)
@@ -89,6 +120,18 @@ func NewProvisionCmd() *cobra.Command {
 	}
 }
 
+// ProvisionResult is the result of `azd provision`, written when the output format is JSON and returned as the data of
+// the action result.
+type ProvisionResult struct {
+	// The outputs and the provisioned resources of the deployment. The resources are only listed when the output format
+	// is JSON.
+	contracts.EnvRefreshResult
+	// Set when there were no changes to provision, in which case there are no outputs or resources.
+	Skipped bool `json:"skipped,omitempty"`
+	// The time taken to provision, excluding user interaction time.
+	Duration time.Duration `json:"duration"`
+}
+
 type ProvisionAction struct {
 	flags            *ProvisionFlags
 	provisionManager *provisioning.Manager
@@ -158,6 +201,21 @@ func (p *ProvisionAction) Run(ctx context.Context) (*actions.ActionResult, error
 	}
 	previewMode := p.flags.preview
 
+	if p.flags.ttl != 0 && !p.flags.ephemeral {
+		return nil, errors.New("--ttl can only be used with --ephemeral")
+	}
+	if p.flags.ttl < 0 {
+		return nil, errors.New("--ttl must be a positive duration")
+	}
+	if p.flags.ephemeral && previewMode {
+		return nil, errors.New("--ephemeral can't be used with --preview")
+	}
+
+	parameters, err := parseParameterOverrides(p.flags.parameters)
+	if err != nil {
+		return nil, err
+	}
+
 	// Command title
 	defaultTitle := "Provisioning Azure resources (azd provision)"
 	defaultTitleNote := "Provisioning Azure resources can take some time"
@@ -185,6 +243,15 @@ func (p *ProvisionAction) Run(ctx context.Context) (*actions.ActionResult, error
 
 	infraOptions := infra.Options
 	infraOptions.IgnoreDeploymentState = p.flags.ignoreDeploymentState
+	infraOptions.IgnoreCompileCache = p.flags.ignoreCompileCache
+	infraOptions.Parameters = parameters
+	if p.flags.ephemeral {
+		infraOptions.Ephemeral = true
+		infraOptions.Ttl = p.flags.ttl
+		if infraOptions.Ttl == 0 {
+			infraOptions.Ttl = defaultEphemeralTtl
+		}
+	}
 	if err := p.provisionManager.Initialize(ctx, p.projectConfig.Path, infraOptions); err != nil {
 		return nil, fmt.Errorf("initializing provisioning manager: %w", err)
 	}
@@ -199,7 +266,7 @@ func (p *ProvisionAction) Run(ctx context.Context) (*actions.ActionResult, error
 		location, err := p.subManager.GetLocation(ctx, p.env.GetSubscriptionId(), p.env.GetLocation())
 		var locationDisplay string
 		if err != nil {
-			log.Printf("failed getting location: %v", err)
+			logging.Warn("getting the location", "error", err)
 		} else {
 			locationDisplay = location.DisplayName
 		}
@@ -210,7 +277,7 @@ func (p *ProvisionAction) Run(ctx context.Context) (*actions.ActionResult, error
 		)
 
 	} else {
-		log.Printf("failed getting subscriptions. Skip displaying sub and location: %v", subErr)
+		logging.Warn("getting the subscription, the subscription and location aren't displayed", "error", subErr)
 	}
 
 	var deployResult *provisioning.DeployResult
@@ -304,6 +371,10 @@ func (p *ProvisionAction) Run(ctx context.Context) (*actions.ActionResult, error
 			Message: &actions.ResultMessage{
 				Header: "There are no changes to provision for your application.",
 			},
+			Data: &ProvisionResult{
+				Skipped:  true,
+				Duration: since(startTime),
+			},
 		}, nil
 	}
 
@@ -326,6 +397,15 @@ func (p *ProvisionAction) Run(ctx context.Context) (*actions.ActionResult, error
 		}
 	}
 
+	// Listing the provisioned resources requires reading the deployment state, which is only done when the result is
+	// written as JSON
+	provisionResult := &ProvisionResult{
+		EnvRefreshResult: provisioning.NewEnvRefreshResultFromState(&provisioning.State{
+			Outputs: deployResult.Deployment.Outputs,
+		}),
+		Duration: since(startTime),
+	}
+
 	if p.formatter.Kind() == output.JsonFormat {
 		stateResult, err := p.provisionManager.State(ctx, nil)
 		if err != nil {
@@ -335,8 +415,9 @@ func (p *ProvisionAction) Run(ctx context.Context) (*actions.ActionResult, error
 			)
 		}
 
-		if err := p.formatter.Format(
-			provisioning.NewEnvRefreshResultFromState(stateResult.State), p.writer, nil); err != nil {
+		provisionResult.EnvRefreshResult = provisioning.NewEnvRefreshResultFromState(stateResult.State)
+
+		if err := p.formatter.Format(provisionResult, p.writer, nil); err != nil {
 			return nil, fmt.Errorf(
 				"deployment succeeded but the deployment result could not be displayed: %w",
 				multierr.Combine(err, err),
@@ -358,6 +439,7 @@ func (p *ProvisionAction) Run(ctx context.Context) (*actions.ActionResult, error
 				false,
 			),
 		},
+		Data: provisionResult,
 	}, nil
 }
 
@@ -376,6 +458,25 @@ func deployResultToUx(previewResult *provisioning.DeployPreviewResult) ux.UxItem
 	}
 }
 
+// parseParameterOverrides parses the key=value pairs of the --parameter flags.
+func parseParameterOverrides(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	parameters := make(map[string]string, len(values))
+	for _, value := range values {
+		key, paramValue, has := strings.Cut(value, "=")
+		if !has || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid --parameter '%s', expected key=value", value)
+		}
+
+		parameters[strings.TrimSpace(key)] = paramValue
+	}
+
+	return parameters, nil
+}
+
 func GetCmdProvisionHelpDescription(c *cobra.Command) string {
 	return generateCmdHelpDescription(fmt.Sprintf(
 		"Provision the Azure resources for an application."+
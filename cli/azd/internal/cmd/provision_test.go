@@ -0,0 +1,55 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseParameterOverrides(t *testing.T) {
+	parameters, err := parseParameterOverrides([]string{
+		"sku=Standard",
+		"connectionString=Server=tcp:contoso;Database=db",
+		"sku=Premium",
+		"tags=",
+	})
+	require.NoError(t, err)
+
+	// Later flags win, and values may contain '='
+	require.Equal(t, map[string]string{
+		"sku":              "Premium",
+		"connectionString": "Server=tcp:contoso;Database=db",
+		"tags":             "",
+	}, parameters)
+
+	_, err = parseParameterOverrides([]string{"sku"})
+	require.ErrorContains(t, err, "expected key=value")
+
+	_, err = parseParameterOverrides([]string{"=Standard"})
+	require.ErrorContains(t, err, "expected key=value")
+}
+
+func Test_ProvisionAction_EphemeralFlags(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags ProvisionFlags
+		err   string
+	}{
+		{"TtlWithoutEphemeral", ProvisionFlags{ttl: time.Hour}, "--ttl can only be used with --ephemeral"},
+		{"NegativeTtl", ProvisionFlags{ephemeral: true, ttl: -time.Hour}, "--ttl must be a positive duration"},
+		{"EphemeralPreview", ProvisionFlags{ephemeral: true, preview: true}, "--ephemeral can't be used with --preview"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action := &ProvisionAction{flags: &tt.flags}
+			_, err := action.Run(context.Background())
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
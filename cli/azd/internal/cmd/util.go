@@ -11,6 +11,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/warnings"
 )
 
 func getResourceGroupFollowUp(
@@ -53,11 +54,27 @@ func azurePortalLink(portalUrlBase, subscriptionId, resourceGroupName string) st
 		resourceGroupName))
 }
 
-func serviceNameWarningCheck(console input.Console, serviceNameFlag string, commandName string) {
+// serviceNameWarningCheck warns when the deprecated --service flag is used, adding the warning to the warnings of the
+// operation when ctx collects them.
+func serviceNameWarningCheck(
+	ctx context.Context,
+	console input.Console,
+	serviceNameFlag string,
+	commandName string,
+) {
 	if serviceNameFlag == "" {
 		return
 	}
 
+	if warnings.Add(ctx, warnings.Warning{
+		Code: warnings.CodeDeprecatedFlag,
+		Message: fmt.Sprintf(
+			"The `--service` flag is deprecated and will be removed in a future release. Next time use `azd %s <service>`.",
+			commandName),
+	}) {
+		return
+	}
+
 	fmt.Fprintln(
 		console.Handles().Stderr,
 		output.WithWarningFormat("WARNING: The `--service` flag is deprecated and will be removed in a future release."),
@@ -97,10 +114,8 @@ func getTargetServiceName(
 	}
 
 	if targetServiceName != "" {
-		if has, err := importManager.HasService(ctx, projectConfig, targetServiceName); err != nil {
+		if err := importManager.ValidateServiceName(ctx, projectConfig, targetServiceName); err != nil {
 			return "", err
-		} else if !has {
-			return "", fmt.Errorf("service name '%s' doesn't exist", targetServiceName)
 		}
 	}
 
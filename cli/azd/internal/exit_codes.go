@@ -0,0 +1,23 @@
+package internal
+
+// The exit codes of azd, documented in docs/exit-codes.md. Scripts branch on them, so the code of a kind of error must
+// not change once released.
+const (
+	// SuccessExitCode is the exit code of azd when the command succeeds.
+	SuccessExitCode = 0
+	// UserErrorExitCode is the exit code of azd for the errors not classified otherwise, like invalid arguments, a
+	// declined prompt or a failing hook.
+	UserErrorExitCode = 1
+	// ConfigErrorExitCode is the exit code of azd when the project or environment configuration is missing or invalid.
+	ConfigErrorExitCode = 2
+	// AuthErrorExitCode is the exit code of azd when the user isn't logged in, the login expired or the user isn't
+	// authorized to access a resource.
+	AuthErrorExitCode = 3
+	// ToolMissingExitCode is the exit code of azd when external tools required by the command, like docker, are missing
+	// or their version isn't supported.
+	ToolMissingExitCode = 4
+	// ServiceErrorExitCode is the exit code of azd when a request to Azure fails, like a failing deployment.
+	ServiceErrorExitCode = 5
+	// TimeoutExitCode is the exit code of azd when a command times out, the same as the one of the timeout utility.
+	TimeoutExitCode = 124
+)
@@ -1,5 +1,13 @@
 package internal
 
+import (
+	"errors"
+	"time"
+)
+
+// ErrCommandTimeout is returned when a command runs for longer than the timeout set with `--timeout`.
+var ErrCommandTimeout = errors.New("command timed out")
+
 type GlobalCommandOptions struct {
 	// Cwd allows the user to override the current working directory, temporarily.
 	// The root command will take care of cd'ing into that folder before your command
@@ -11,6 +19,25 @@ type GlobalCommandOptions struct {
 	// launched tools. It's enabled with `--debug`, for any command.
 	EnableDebugLogging bool
 
+	// LogLevel is the level of the diagnostics logging, like 'warn' or 'trace', set with `--log-level`.
+	LogLevel string
+
+	// Timeout is the maximum duration of the command, set with `--timeout`. The command is cancelled when it runs for
+	// longer. Zero means no timeout.
+	Timeout time.Duration
+
+	// Offline skips the network calls which aren't needed by the command, like the update check. It's enabled with
+	// `--offline` or AZURE_DEV_OFFLINE.
+	Offline bool
+
+	// JsonErrors writes the error of a failing command as JSON to stderr, instead of the error text. It's enabled with
+	// `--json-errors`.
+	JsonErrors bool
+
+	// EnvironmentName is the environment the command runs against, set with `--env`, instead of the default environment.
+	// The default environment is left unchanged.
+	EnvironmentName string
+
 	// when true, interactive prompts should behave as if the user selected the default value.
 	// if there is no default value the prompt returns an error.
 	NoPrompt bool
@@ -0,0 +1,23 @@
+package runcontext
+
+import (
+	"os"
+	"strconv"
+)
+
+// OfflineEnvVarName is the name of the environment variable enabling the offline mode, which `--offline` enables too.
+//
+// In offline mode, for air-gapped environments, azd skips the network calls which aren't needed by the command, like
+// the update check or the template gallery. The Azure operations of the command, like deployments, still run.
+const OfflineEnvVarName = "AZURE_DEV_OFFLINE"
+
+// IsOffline returns whether the offline mode is enabled.
+func IsOffline() bool {
+	if offline, has := os.LookupEnv(OfflineEnvVarName); has {
+		if enabled, err := strconv.ParseBool(offline); err == nil && enabled {
+			return true
+		}
+	}
+
+	return false
+}
@@ -113,6 +113,8 @@ const (
 	// The command invocation is formatted using [events.GetCommandEventName]. This makes it consistent with how
 	// commands are represented in telemetry.
 	CmdEntry = attribute.Key("cmd.entry")
+	// The category of the error a command failed with, like 'timeout'.
+	CmdErrorCategory = attribute.Key("cmd.error.category")
 )
 
 // All possible enumerations of ExecutionEnvironmentKey
@@ -7,33 +7,32 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"io/fs"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
-	"runtime"
+	"slices"
 	"strconv"
-	"strings"
-	"time"
 
 	azcorelog "github.com/Azure/azure-sdk-for-go/sdk/azcore/log"
 	"github.com/azure/azure-dev/cli/azd/cmd"
 	"github.com/azure/azure-dev/cli/azd/internal"
+	internalcmd "github.com/azure/azure-dev/cli/azd/internal/cmd"
+	"github.com/azure/azure-dev/cli/azd/internal/runcontext"
 	"github.com/azure/azure-dev/cli/azd/internal/telemetry"
-	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/cleanup"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
-	"github.com/azure/azure-dev/cli/azd/pkg/installer"
 	"github.com/azure/azure-dev/cli/azd/pkg/ioc"
+	"github.com/azure/azure-dev/cli/azd/pkg/logging"
 	"github.com/azure/azure-dev/cli/azd/pkg/oneauth"
-	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/update"
 	"github.com/blang/semver/v4"
 	"github.com/fatih/color"
 	"github.com/mattn/go-colorable"
@@ -48,34 +47,70 @@ func main() {
 
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 
-	if isDebugEnabled() {
+	logLevel := getLogLevel()
+	if logLevel <= logging.LevelTrace {
 		azcorelog.SetListener(func(event azcorelog.Event, msg string) {
 			log.Printf("%s: %s\n", event, msg)
 		})
-	} else {
-		log.SetOutput(io.Discard)
 	}
+	logging.Configure(logLevel, os.Stderr)
 
-	log.Printf("azd version: %s", internal.Version)
+	logging.Info("azd started", "version", internal.Version)
 
 	ts := telemetry.GetTelemetrySystem()
 
-	latest := make(chan semver.Version)
-	go fetchLatestVersion(latest)
+	offline := isOffline()
+	if offline {
+		logging.Info("running in offline mode")
+	}
+
+	// An interrupt cancels the command, so the temporary artifacts of the operation are removed. Interrupting again
+	// terminates azd right away.
+	cmdCtx, stopSignals := signal.NotifyContext(ctx, os.Interrupt)
+	go func() {
+		<-cmdCtx.Done()
+		stopSignals()
+	}()
+	cmdCtx, cleanupDone := cleanup.WithCancelCleanup(cmdCtx)
 
 	rootContainer := ioc.NewNestedContainer(nil)
-	ioc.RegisterInstance(rootContainer, ctx)
-	cmdErr := cmd.NewRootCmd(false, nil, rootContainer).ExecuteContext(ctx)
+	ioc.RegisterInstance(rootContainer, cmdCtx)
+	rootCmd := cmd.NewRootCmd(false, nil, rootContainer)
+
+	// The update check sends its request with the client of the azd requests, so it trusts the configured CA bundle.
+	var httpClient *http.Client
+	if err := rootContainer.Resolve(&httpClient); err != nil {
+		logging.Warn("creating the http client", "error", err)
+	}
+
+	latest := make(chan semver.Version)
+	go fetchLatestVersion(latest, offline, httpClient)
+
+	jsonErrors := isJsonErrors()
+	if jsonErrors {
+		rootCmd.SilenceErrors = true
+	}
+	executedCmd, cmdErr := rootCmd.ExecuteContextC(cmdCtx)
+	cleanupDone()
+	stopSignals()
 
 	var suggestionErr *azcli.ErrorWithSuggestion
-	if cmdErr != nil && errors.As(cmdErr, &suggestionErr) {
+	if cmdErr != nil && jsonErrors {
+		// The errors of the actions are written by the command, with their correlation id. The command silences its
+		// errors once its action ran, so the other errors, like an unknown flag, are written here.
+		if executedCmd == nil || executedCmd == rootCmd || !executedCmd.SilenceErrors {
+			if err := internalcmd.WriteJsonError(os.Stderr, cmdErr, ""); err != nil {
+				logging.Error("writing the json error", "error", err)
+			}
+		}
+	} else if cmdErr != nil && errors.As(cmdErr, &suggestionErr) {
 		invokeErr := rootContainer.Invoke(func(console input.Console) {
 			console.Message(ctx, color.RedString("ERROR: %s", cmdErr.Error()))
 			console.Message(ctx, (*azcli.ErrorWithSuggestion)(suggestionErr).Suggestion)
 		})
 
 		if invokeErr != nil {
-			log.Printf("failed to show error suggestion: %v", invokeErr)
+			logging.Error("showing the error suggestion", "error", invokeErr)
 		}
 	}
 
@@ -102,49 +137,6 @@ func main() {
 			// case
 			log.Printf("eliding update message for dev build")
 		} else if latestVersion.GT(internal.VersionInfo().Version) {
-			var upgradeText string
-
-			installedBy := installer.InstalledBy()
-			if runtime.GOOS == "windows" {
-				switch installedBy {
-				case installer.InstallTypePs:
-					//nolint:lll
-					upgradeText = "run:\npowershell -ex AllSigned -c \"Invoke-RestMethod 'https://aka.ms/install-azd.ps1' | Invoke-Expression\"\n\nIf the install script was run with custom parameters, ensure that the same parameters are used for the upgrade. For advanced install instructions, see: https://aka.ms/azd/upgrade/windows"
-				case installer.InstallTypeWinget:
-					upgradeText = "run:\nwinget upgrade Microsoft.Azd"
-				case installer.InstallTypeChoco:
-					upgradeText = "run:\nchoco upgrade azd"
-				default:
-					// Also covers "msi" case where the user installed directly
-					// via MSI
-					upgradeText = "visit https://aka.ms/azd/upgrade/windows"
-				}
-			} else if runtime.GOOS == "linux" {
-				switch installedBy {
-				case installer.InstallTypeSh:
-					//nolint:lll
-					upgradeText = "run:\ncurl -fsSL https://aka.ms/install-azd.sh | bash\n\nIf the install script was run with custom parameters, ensure that the same parameters are used for the upgrade. For advanced install instructions, see: https://aka.ms/azd/upgrade/linux"
-				default:
-					// Also covers "deb" and "rpm" cases which are currently
-					// documented. When package manager distribution support is
-					// added, this will need to be updated.
-					upgradeText = "visit https://aka.ms/azd/upgrade/linux"
-				}
-			} else if runtime.GOOS == "darwin" {
-				switch installedBy {
-				case installer.InstallTypeBrew:
-					upgradeText = "run:\nbrew update && brew upgrade azd"
-				case installer.InstallTypeSh:
-					//nolint:lll
-					upgradeText = "run:\ncurl -fsSL https://aka.ms/install-azd.sh | bash\n\nIf the install script was run with custom parameters, ensure that the same parameters are used for the upgrade. For advanced install instructions, see: https://aka.ms/azd/upgrade/mac"
-				default:
-					upgradeText = "visit https://aka.ms/azd/upgrade/mac"
-				}
-			} else {
-				// Platform is not recognized, use the generic install link
-				upgradeText = "visit https://aka.ms/azd/upgrade"
-			}
-
 			fmt.Fprintln(
 				os.Stderr,
 				output.WithWarningFormat(
@@ -154,7 +146,7 @@ func main() {
 			fmt.Fprintln(
 				os.Stderr,
 				output.WithWarningFormat(`To update to the latest version, %s`,
-					upgradeText))
+					update.UpgradeInstructions()))
 		}
 	}
 
@@ -164,170 +156,74 @@ func main() {
 			log.Printf("non-graceful telemetry shutdown: %v\n", err)
 		}
 
-		if ts.EmittedAnyTelemetry() {
+		// In offline mode, the telemetry stays queued until azd runs online
+		if ts.EmittedAnyTelemetry() && !offline {
 			err := startBackgroundUploadProcess()
 			if err != nil {
-				log.Printf("failed to start background telemetry upload: %v\n", err)
+				logging.Warn("starting the background telemetry upload", "error", err)
 			}
 		}
 	}
 
 	if cmdErr != nil {
-		os.Exit(1)
+		os.Exit(internalcmd.ExitCode(cmdErr))
 	}
 }
 
-// updateCheckCacheFileName is the name of the file created in the azd configuration directory
-// which is used to cache version information for our up to date check.
-const updateCheckCacheFileName = "update-check.json"
-
 // fetchLatestVersion fetches the latest version of the CLI and sends the result
 // across the version channel, which it then closes. If the latest version can not
 // be determined, the channel is closed without writing a value.
-func fetchLatestVersion(version chan<- semver.Version) {
+func fetchLatestVersion(version chan<- semver.Version, offline bool, httpClient *http.Client) {
 	defer close(version)
 
+	if offline {
+		log.Print("skipping update check in offline mode")
+		return
+	}
+
 	// Allow the user to skip the update check if they wish, by setting AZD_SKIP_UPDATE_CHECK to
 	// a truthy value.
-	if value, has := os.LookupEnv("AZD_SKIP_UPDATE_CHECK"); has {
+	if value, has := os.LookupEnv(update.SkipUpdateCheckEnvVarName); has {
 		if setting, err := strconv.ParseBool(value); err == nil && setting {
 			log.Print("skipping update check since AZD_SKIP_UPDATE_CHECK is true")
 			return
-		} else if err != nil {
-			log.Printf("could not parse value for AZD_SKIP_UPDATE_CHECK a boolean "+
-				"(it was: %s), proceeding with update check", value)
 		}
 	}
 
-	// To avoid fetching the latest version of the CLI on every invocation, we cache the result for a period
-	// of time, in the user's home directory.
-	configDir, err := config.GetUserConfigDir()
-	if err != nil {
-		log.Printf("could not determine config directory: %v, skipping update check", err)
+	// `azd version --check` reports the updates itself
+	if isVersionCheck() {
+		log.Print("skipping update check for azd version --check")
 		return
 	}
 
-	cacheFilePath := filepath.Join(configDir, updateCheckCacheFileName)
-	cacheFile, err := os.ReadFile(cacheFilePath)
-	if err != nil && !errors.Is(err, fs.ErrNotExist) {
-		log.Printf("error reading update cache file: %v, skipping update check", err)
+	if httpClient == nil {
+		log.Print("skipping update check, the http client couldn't be created")
 		return
 	}
 
-	// If we were able to read the update file, try to interpret it and use the cached
-	// value if it is still valid. Note the `err == nil` guard here ensures we don't run
-	// this logic when the cache file did not exist (since err will be a form of fs.ErrNotExist)
-	var cachedLatestVersion *semver.Version
-	if err == nil {
-		var cache updateCacheFile
-		if err := json.Unmarshal(cacheFile, &cache); err == nil {
-			parsedVersion, parseVersionErr := semver.Parse(cache.Version)
-			parsedExpiresOn, parseExpiresOnErr := time.Parse(time.RFC3339, cache.ExpiresOn)
-
-			if parseVersionErr == nil && parseExpiresOnErr == nil {
-				if time.Now().UTC().Before(parsedExpiresOn) {
-					log.Printf("using cached latest version: %s (expires on: %s)", cache.Version, cache.ExpiresOn)
-					cachedLatestVersion = &parsedVersion
-				} else {
-					log.Printf("ignoring cached latest version, it is out of date")
-				}
-			} else {
-				if parseVersionErr != nil {
-					log.Printf("failed to parse cached version '%s' as a semver: %v,"+
-						" ignoring cached value", cache.Version, parseVersionErr)
-				}
-				if parseExpiresOnErr != nil {
-					log.Printf(
-						"failed to parse cached version expiration time '%s' as a RFC3339"+
-							" timestamp: %v, ignoring cached value",
-						cache.ExpiresOn,
-						parseExpiresOnErr)
-				}
-			}
-		} else {
-			log.Printf("could not unmarshal cache file: %v, ignoring cache", err)
-		}
+	// To avoid fetching the latest version of the CLI on every invocation, the checker caches the result for a period
+	// of time, in the user's home directory.
+	checker, err := update.NewChecker(httpClient, telemetry.IsTelemetryEnabled())
+	if err != nil {
+		log.Printf("%v, skipping update check", err)
+		return
 	}
 
-	// If we don't have a cached version we can use, fetch one (and cache it)
-	if cachedLatestVersion == nil {
-		log.Print("fetching latest version information for update check")
-		req, err := http.NewRequest(http.MethodGet, "https://aka.ms/azure-dev/versions/cli/latest", nil)
-		if err != nil {
-			log.Printf("failed to create request object: %v, skipping update check", err)
-		}
-
-		req.Header.Set("User-Agent", internal.UserAgent())
-
-		res, err := http.DefaultClient.Do(req)
-		if err != nil {
-			log.Printf("failed to fetch latest version: %v, skipping update check", err)
-			return
-		}
-		body, err := readToEndAndClose(res.Body)
-		if err != nil {
-			log.Printf("failed to read response body: %v, skipping update check", err)
-			return
-		}
-
-		if res.StatusCode != http.StatusOK {
-			log.Printf(
-				"failed to refresh latest version, http status: %v, body: %v, skipping update check",
-				res.StatusCode,
-				body,
-			)
-			return
-		}
-
-		// Parse the body of the response as a semver, and if it's valid, cache it.
-		fetchedVersionText := strings.TrimSpace(body)
-		fetchedVersion, err := semver.Parse(fetchedVersionText)
-		if err != nil {
-			log.Printf("failed to parse latest version '%s' as a semver: %v, skipping update check", fetchedVersionText, err)
-			return
-		}
-
-		cachedLatestVersion = &fetchedVersion
-
-		// Write the value back to the cache. Note that on these logging paths for errors we do not return
-		// eagerly, since we have not yet sent the latest versions across the channel (and we don't want to do that until
-		// we've updated the cache since reader on the other end of the channel will exit the process after it receives this
-		// value and finishes
-		// the up to date check, possibly while this go-routine is still running)
-		if err := os.MkdirAll(filepath.Dir(cacheFilePath), osutil.PermissionFile); err != nil {
-			log.Printf("failed to create cache folder '%s': %v", filepath.Dir(cacheFilePath), err)
-		} else {
-			cacheObject := updateCacheFile{
-				Version:   fetchedVersionText,
-				ExpiresOn: time.Now().UTC().Add(24 * time.Hour).Format(time.RFC3339),
-			}
-
-			// The marshal call can not fail, so we ignore the error.
-			cacheContents, _ := json.Marshal(cacheObject)
-
-			if err := os.WriteFile(cacheFilePath, cacheContents, osutil.PermissionDirectory); err != nil {
-				log.Printf("failed to write update cache file: %v", err)
-			} else {
-				log.Printf("updated cache file to version %s (expires on: %s)", cacheObject.Version, cacheObject.ExpiresOn)
-			}
-		}
+	latestVersion, err := checker.LatestVersion(context.Background(), 0)
+	if err != nil {
+		log.Printf("%v, skipping update check", err)
+		return
 	}
 
 	// Publish our value, the defer above will close the channel.
-	version <- *cachedLatestVersion
-}
-
-type updateCacheFile struct {
-	// The semver of the  latest version the CLI
-	Version string `json:"version"`
-	// A time at which this cached value expires, stored as an RFC3339 timestamp
-	ExpiresOn string `json:"expiresOn"`
+	version <- latestVersion
 }
 
-// isDebugEnabled checks to see if `--debug` was passed with a truthy
-// value.
-func isDebugEnabled() bool {
+// getLogLevel returns the log level set with `--log-level`, or the trace level when `--debug` was passed with a truthy
+// value. Logging is disabled when neither is set, or the log level is invalid, which the root command reports.
+func getLogLevel() slog.Level {
 	debug := false
+	logLevel := ""
 	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
 
 	// Since we are running this parse logic on the full command line, there may be additional flags
@@ -337,13 +233,57 @@ func isDebugEnabled() bool {
 	// found).
 	flags.ParseErrorsWhitelist.UnknownFlags = true
 	flags.BoolVar(&debug, "debug", false, "")
+	flags.StringVar(&logLevel, "log-level", "", "")
 
 	// if flag `-h` of `--help` is within the command, the usage is automatically shown.
 	// Setting `Usage` to a no-op will hide this extra unwanted output.
 	flags.Usage = func() {}
 
 	_ = flags.Parse(os.Args[1:])
-	return debug
+
+	if debug {
+		return logging.LevelTrace
+	}
+
+	if level, err := logging.ParseLevel(logLevel); err == nil {
+		return level
+	}
+
+	return logging.LevelNone
+}
+
+// isVersionCheck checks to see if the command is `azd version --check`
+func isVersionCheck() bool {
+	check := false
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+
+	// Since we are running this parse logic on the full command line, there may be additional flags
+	// which we have not defined in our flag set. Setting UnknownFlags instructs `flags.Parse` to continue
+	// parsing the command line even if a flag is not in the flag set.
+	flags.ParseErrorsWhitelist.UnknownFlags = true
+	flags.BoolVar(&check, "check", false, "")
+	flags.Usage = func() {}
+
+	_ = flags.Parse(os.Args[1:])
+
+	return check && slices.Contains(flags.Args(), "version")
+}
+
+// isOffline checks to see if the offline mode is enabled with AZURE_DEV_OFFLINE or `--offline`
+func isOffline() bool {
+	offline := false
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+
+	// Since we are running this parse logic on the full command line, there may be additional flags
+	// which we have not defined in our flag set. Setting UnknownFlags instructs `flags.Parse` to continue
+	// parsing the command line even if a flag is not in the flag set.
+	flags.ParseErrorsWhitelist.UnknownFlags = true
+	flags.BoolVar(&offline, "offline", false, "")
+	flags.Usage = func() {}
+
+	_ = flags.Parse(os.Args[1:])
+
+	return offline || runcontext.IsOffline()
 }
 
 // isJsonOutput checks to see if `--output` was passed with the value `json`
@@ -368,11 +308,21 @@ func isJsonOutput() bool {
 	return output == "json"
 }
 
-func readToEndAndClose(r io.ReadCloser) (string, error) {
-	defer r.Close()
-	var buf strings.Builder
-	_, err := io.Copy(&buf, r)
-	return buf.String(), err
+// isJsonErrors checks to see if `--json-errors` was passed
+func isJsonErrors() bool {
+	jsonErrors := false
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+
+	// Since we are running this parse logic on the full command line, there may be additional flags
+	// which we have not defined in our flag set. Setting UnknownFlags instructs `flags.Parse` to continue
+	// parsing the command line even if a flag is not in the flag set.
+	flags.ParseErrorsWhitelist.UnknownFlags = true
+	flags.BoolVar(&jsonErrors, "json-errors", false, "")
+	flags.Usage = func() {}
+
+	_ = flags.Parse(os.Args[1:])
+
+	return jsonErrors
 }
 
 func startBackgroundUploadProcess() error {
@@ -5,11 +5,18 @@ package account
 
 import (
 	"context"
+	"fmt"
+	"sync"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/azure/azure-dev/cli/azd/pkg/auth"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
 )
 
+// SubscriptionTenantsConfigPath is the path in environment config that holds explicit subscription id to tenant id
+// mappings. When a subscription has an entry, the configured tenant is used instead of the resolved one.
+const SubscriptionTenantsConfigPath = "auth.subscriptionTenants"
+
 // SubscriptionCredentialProvider provides an [azcore.TokenCredential] configured
 // to use the tenant id that corresponds to the tenant the given subscription
 // is located in.
@@ -20,6 +27,10 @@ type SubscriptionCredentialProvider interface {
 type subscriptionCredentialProvider struct {
 	credProvider auth.MultiTenantCredentialProvider
 	subResolver  SubscriptionTenantResolver
+
+	// In-memory store of the tenant resolved for each subscription, so the lookup is only done once per subscription
+	// for the lifetime of the provider.
+	subscriptionTenants sync.Map
 }
 
 func NewSubscriptionCredentialProvider(
@@ -36,10 +47,65 @@ func (p *subscriptionCredentialProvider) CredentialForSubscription(
 	ctx context.Context,
 	subscriptionId string,
 ) (azcore.TokenCredential, error) {
+	if val, ok := p.subscriptionTenants.Load(subscriptionId); ok {
+		return p.credProvider.GetTokenCredential(ctx, val.(string))
+	}
+
 	tenantId, err := p.subResolver.LookupTenant(ctx, subscriptionId)
 	if err != nil {
 		return nil, err
 	}
 
-	return p.credProvider.GetTokenCredential(ctx, tenantId)
+	credential, err := p.credProvider.GetTokenCredential(ctx, tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	p.subscriptionTenants.Store(subscriptionId, tenantId)
+	return credential, nil
+}
+
+// TenantOverrideFunc returns the tenant explicitly configured for a subscription, if any.
+type TenantOverrideFunc func(ctx context.Context, subscriptionId string) (tenantId string, has bool)
+
+type tenantOverrideResolver struct {
+	inner    SubscriptionTenantResolver
+	override TenantOverrideFunc
+}
+
+// NewTenantOverrideResolver returns a [SubscriptionTenantResolver] which returns the tenant provided by override when
+// one is configured for a subscription, and otherwise delegates to inner.
+func NewTenantOverrideResolver(inner SubscriptionTenantResolver, override TenantOverrideFunc) SubscriptionTenantResolver {
+	return &tenantOverrideResolver{
+		inner:    inner,
+		override: override,
+	}
+}
+
+func (r *tenantOverrideResolver) LookupTenant(ctx context.Context, subscriptionId string) (string, error) {
+	if tenantId, has := r.override(ctx, subscriptionId); has {
+		return tenantId, nil
+	}
+
+	return r.inner.LookupTenant(ctx, subscriptionId)
+}
+
+// TenantOverrideFromConfig returns the tenant configured for subscriptionId under [SubscriptionTenantsConfigPath] in cfg.
+func TenantOverrideFromConfig(cfg config.Config, subscriptionId string) (string, bool, error) {
+	if cfg == nil {
+		return "", false, nil
+	}
+
+	var tenants map[string]string
+	has, err := cfg.GetSection(SubscriptionTenantsConfigPath, &tenants)
+	if err != nil {
+		return "", false, fmt.Errorf("reading %s: %w", SubscriptionTenantsConfigPath, err)
+	}
+
+	if !has {
+		return "", false, nil
+	}
+
+	tenantId, has := tenants[subscriptionId]
+	return tenantId, has && tenantId != "", nil
 }
@@ -11,6 +11,7 @@ import (
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -66,6 +67,90 @@ func TestSubscriptionCredentialProvider(t *testing.T) {
 	})
 }
 
+func TestSubscriptionCredentialProviderCachesTenant(t *testing.T) {
+	t.Parallel()
+
+	tenant1 := "fafbff54-b655-4648-98a2-dc3ada4df86e"
+	tenant2 := "e615e058-6ff1-46e6-ab20-a1d5efd0f68c"
+
+	sub1 := "d0a01878-d7f8-41ce-a4bc-2ead16199965"
+	sub2 := "bbc7e1fa-a1aa-47d8-b05b-91f6ebe569fe"
+
+	subToTenant := map[string]string{
+		sub1: tenant1,
+		sub2: tenant2,
+	}
+
+	lookups := map[string]int{}
+	requestedTenants := []string{}
+
+	provider := NewSubscriptionCredentialProvider(
+		subscriptionTenantResolverFunc(func(ctx context.Context, subscriptionId string) (string, error) {
+			lookups[subscriptionId]++
+			return subToTenant[subscriptionId], nil
+		}),
+		multiTenantCredentialProviderFunc(func(ctx context.Context, tenantId string) (azcore.TokenCredential, error) {
+			requestedTenants = append(requestedTenants, tenantId)
+			return &dummyCredential{}, nil
+		}),
+	)
+
+	for i := 0; i < 3; i++ {
+		_, err := provider.CredentialForSubscription(context.Background(), sub1)
+		assert.NoError(t, err)
+
+		_, err = provider.CredentialForSubscription(context.Background(), sub2)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, map[string]int{sub1: 1, sub2: 1}, lookups)
+	assert.Equal(t, []string{tenant1, tenant2, tenant1, tenant2, tenant1, tenant2}, requestedTenants)
+}
+
+func TestTenantOverrideResolver(t *testing.T) {
+	t.Parallel()
+
+	homeTenant := "fafbff54-b655-4648-98a2-dc3ada4df86e"
+	guestTenant := "e615e058-6ff1-46e6-ab20-a1d5efd0f68c"
+
+	sub1 := "d0a01878-d7f8-41ce-a4bc-2ead16199965"
+	sub2 := "bbc7e1fa-a1aa-47d8-b05b-91f6ebe569fe"
+
+	cfg := config.NewEmptyConfig()
+	assert.NoError(t, cfg.Set(SubscriptionTenantsConfigPath, map[string]any{
+		sub2: guestTenant,
+	}))
+
+	resolver := NewTenantOverrideResolver(
+		subscriptionTenantResolverFunc(func(ctx context.Context, subscriptionId string) (string, error) {
+			return homeTenant, nil
+		}),
+		func(ctx context.Context, subscriptionId string) (string, bool) {
+			tenantId, has, err := TenantOverrideFromConfig(cfg, subscriptionId)
+			assert.NoError(t, err)
+			return tenantId, has
+		},
+	)
+
+	tenantId, err := resolver.LookupTenant(context.Background(), sub1)
+	assert.NoError(t, err)
+	assert.Equal(t, homeTenant, tenantId)
+
+	tenantId, err = resolver.LookupTenant(context.Background(), sub2)
+	assert.NoError(t, err)
+	assert.Equal(t, guestTenant, tenantId)
+
+	t.Run("NoConfig", func(t *testing.T) {
+		_, has, err := TenantOverrideFromConfig(config.NewEmptyConfig(), sub2)
+		assert.NoError(t, err)
+		assert.False(t, has)
+
+		_, has, err = TenantOverrideFromConfig(nil, sub2)
+		assert.NoError(t, err)
+		assert.False(t, has)
+	})
+}
+
 // subscriptionTenantResolverFunc implements [SubscriptionTenantResolver] using a provided function.
 type subscriptionTenantResolverFunc func(ctx context.Context, subscriptionId string) (string, error)
 
@@ -0,0 +1,270 @@
+package account
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+)
+
+// DefaultSource identifies the rule of the [DefaultsPolicy] that produced a default value.
+type DefaultSource string
+
+const (
+	// No rule produced a value.
+	DefaultSourceNone DefaultSource = ""
+	// The value was set explicitly in the process environment (AZURE_SUBSCRIPTION_ID, AZURE_LOCATION).
+	DefaultSourceEnvironment DefaultSource = "environment"
+	// The value came from the azd user configuration (defaults.subscription, defaults.location).
+	DefaultSourceConfig DefaultSource = "config"
+	// The value came from the az CLI default subscription or default location.
+	DefaultSourceAzCli DefaultSource = "azcli"
+	// The logged in account has access to exactly one subscription, which was selected automatically.
+	DefaultSourceSingleSubscription DefaultSource = "singleSubscription"
+)
+
+// ResolvedDefault is a default value along with the rule that produced it.
+type ResolvedDefault struct {
+	Value  string
+	Source DefaultSource
+}
+
+// AzCliDefaults reads the defaults configured for the az CLI from its configuration directory.
+type AzCliDefaults interface {
+	// DefaultSubscriptionId returns the subscription selected with `az account set`, or an empty string.
+	DefaultSubscriptionId() (string, error)
+	// DefaultLocation returns the location configured with `az config set defaults.location`, or an empty string.
+	DefaultLocation() (string, error)
+}
+
+type subscriptionLister interface {
+	GetSubscriptions(ctx context.Context) ([]Subscription, error)
+}
+
+// DefaultsPolicy resolves the subscription and location to offer when the user has not picked one. The rules are
+// evaluated in order and the first one to produce a value wins:
+//
+//  1. An explicit value in the process environment.
+//  2. The azd config default.
+//  3. The az CLI default.
+//  4. For subscriptions only, the single subscription the account has access to.
+type DefaultsPolicy struct {
+	config        config.Config
+	subscriptions subscriptionLister
+	azCli         AzCliDefaults
+	lookupEnv     func(string) (string, bool)
+}
+
+// NewDefaultsPolicy creates a policy backed by the given azd user configuration.
+func NewDefaultsPolicy(config config.Config, subscriptions subscriptionLister, azCli AzCliDefaults) *DefaultsPolicy {
+	return &DefaultsPolicy{
+		config:        config,
+		subscriptions: subscriptions,
+		azCli:         azCli,
+		lookupEnv:     os.LookupEnv,
+	}
+}
+
+// ResolveSubscription returns the default subscription id. A result with [DefaultSourceNone] is returned when no rule
+// applies, in which case the user should be prompted.
+func (p *DefaultsPolicy) ResolveSubscription(ctx context.Context) (ResolvedDefault, error) {
+	resolved, err := p.resolveSubscription(ctx)
+	if err != nil {
+		return ResolvedDefault{}, err
+	}
+
+	logResolved("subscription", resolved)
+	return resolved, nil
+}
+
+func (p *DefaultsPolicy) resolveSubscription(ctx context.Context) (ResolvedDefault, error) {
+	if value := p.fromEnv(environment.SubscriptionIdEnvVarName); value != "" {
+		return ResolvedDefault{Value: value, Source: DefaultSourceEnvironment}, nil
+	}
+
+	if value := p.fromConfig(defaultSubscriptionKeyPath); value != "" {
+		return ResolvedDefault{Value: value, Source: DefaultSourceConfig}, nil
+	}
+
+	if p.azCli != nil {
+		value, err := p.azCli.DefaultSubscriptionId()
+		if err != nil {
+			log.Printf("failed reading az CLI default subscription: %v", err)
+		} else if value != "" {
+			return ResolvedDefault{Value: value, Source: DefaultSourceAzCli}, nil
+		}
+	}
+
+	if p.subscriptions != nil {
+		subscriptions, err := p.subscriptions.GetSubscriptions(ctx)
+		if err != nil {
+			return ResolvedDefault{}, fmt.Errorf("listing subscriptions: %w", err)
+		}
+
+		if len(subscriptions) == 1 {
+			return ResolvedDefault{Value: subscriptions[0].Id, Source: DefaultSourceSingleSubscription}, nil
+		}
+	}
+
+	return ResolvedDefault{}, nil
+}
+
+// ResolveLocation returns the default location name. A result with [DefaultSourceNone] is returned when no rule applies.
+func (p *DefaultsPolicy) ResolveLocation(ctx context.Context) ResolvedDefault {
+	resolved := p.resolveLocation()
+	logResolved("location", resolved)
+	return resolved
+}
+
+func (p *DefaultsPolicy) resolveLocation() ResolvedDefault {
+	if value := p.fromEnv(environment.LocationEnvVarName); value != "" {
+		return ResolvedDefault{Value: value, Source: DefaultSourceEnvironment}
+	}
+
+	if value := p.fromConfig(defaultLocationKeyPath); value != "" {
+		return ResolvedDefault{Value: value, Source: DefaultSourceConfig}
+	}
+
+	if p.azCli != nil {
+		value, err := p.azCli.DefaultLocation()
+		if err != nil {
+			log.Printf("failed reading az CLI default location: %v", err)
+		} else if value != "" {
+			return ResolvedDefault{Value: value, Source: DefaultSourceAzCli}
+		}
+	}
+
+	return ResolvedDefault{}
+}
+
+func (p *DefaultsPolicy) fromEnv(name string) string {
+	value, _ := p.lookupEnv(name)
+	return strings.TrimSpace(value)
+}
+
+func (p *DefaultsPolicy) fromConfig(path string) string {
+	if p.config == nil {
+		return ""
+	}
+
+	value, ok := p.config.Get(path)
+	if !ok {
+		return ""
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		return ""
+	}
+
+	return strings.TrimSpace(str)
+}
+
+func logResolved(kind string, resolved ResolvedDefault) {
+	if resolved.Source == DefaultSourceNone {
+		log.Printf("no default %s resolved", kind)
+		return
+	}
+
+	log.Printf("resolved default %s '%s' from %s", kind, resolved.Value, resolved.Source)
+}
+
+// azCliConfigDir reads the defaults stored by the az CLI in its configuration directory.
+type azCliConfigDir struct {
+	dir string
+}
+
+// NewAzCliDefaults returns an [AzCliDefaults] that reads the az CLI configuration directory, honoring
+// AZURE_CONFIG_DIR when set.
+func NewAzCliDefaults() AzCliDefaults {
+	dir := os.Getenv("AZURE_CONFIG_DIR")
+	if dir == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(home, ".azure")
+		}
+	}
+
+	return &azCliConfigDir{dir: dir}
+}
+
+func (a *azCliConfigDir) DefaultSubscriptionId() (string, error) {
+	if a.dir == "" {
+		return "", nil
+	}
+
+	contents, err := os.ReadFile(filepath.Join(a.dir, "azureProfile.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	// The az CLI writes this file with a UTF-8 byte order mark.
+	contents = bytes.TrimPrefix(contents, []byte("\xef\xbb\xbf"))
+
+	var profile struct {
+		Subscriptions []struct {
+			Id        string `json:"id"`
+			IsDefault bool   `json:"isDefault"`
+		} `json:"subscriptions"`
+	}
+	if err := json.Unmarshal(contents, &profile); err != nil {
+		return "", fmt.Errorf("parsing az CLI profile: %w", err)
+	}
+
+	for _, sub := range profile.Subscriptions {
+		if sub.IsDefault {
+			return sub.Id, nil
+		}
+	}
+
+	return "", nil
+}
+
+func (a *azCliConfigDir) DefaultLocation() (string, error) {
+	if value := os.Getenv("AZURE_DEFAULTS_LOCATION"); value != "" {
+		return value, nil
+	}
+
+	if a.dir == "" {
+		return "", nil
+	}
+
+	contents, err := os.ReadFile(filepath.Join(a.dir, "config"))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	// The az CLI config file is an INI file; the default location is in the [defaults] section.
+	section := ""
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if found && section == "defaults" && strings.TrimSpace(key) == "location" {
+			return strings.TrimSpace(value), nil
+		}
+	}
+
+	return "", scanner.Err()
+}
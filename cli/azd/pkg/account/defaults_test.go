@@ -0,0 +1,164 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAzCliDefaults struct {
+	subscriptionId string
+	location       string
+}
+
+func (f *fakeAzCliDefaults) DefaultSubscriptionId() (string, error) {
+	return f.subscriptionId, nil
+}
+
+func (f *fakeAzCliDefaults) DefaultLocation() (string, error) {
+	return f.location, nil
+}
+
+type fakeSubscriptionLister struct {
+	subscriptions []Subscription
+	err           error
+	calls         int
+}
+
+func (f *fakeSubscriptionLister) GetSubscriptions(ctx context.Context) ([]Subscription, error) {
+	f.calls++
+	return f.subscriptions, f.err
+}
+
+func TestDefaultsPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	newPolicy := func(env map[string]string, cfg config.Config, azCli AzCliDefaults, subs subscriptionLister) *DefaultsPolicy {
+		policy := NewDefaultsPolicy(cfg, subs, azCli)
+		policy.lookupEnv = func(name string) (string, bool) {
+			value, has := env[name]
+			return value, has
+		}
+		return policy
+	}
+
+	azdConfig := config.NewConfig(map[string]any{
+		"defaults": map[string]any{
+			"subscription": "CONFIG_SUB",
+			"location":     "westus",
+		},
+	})
+	azCli := &fakeAzCliDefaults{subscriptionId: "AZCLI_SUB", location: "northeurope"}
+	singleSub := &fakeSubscriptionLister{subscriptions: []Subscription{{Id: "ONLY_SUB"}}}
+
+	t.Run("Environment", func(t *testing.T) {
+		policy := newPolicy(map[string]string{
+			environment.SubscriptionIdEnvVarName: "ENV_SUB",
+			environment.LocationEnvVarName:       "eastus",
+		}, azdConfig, azCli, singleSub)
+
+		sub, err := policy.ResolveSubscription(ctx)
+		require.NoError(t, err)
+		require.Equal(t, ResolvedDefault{Value: "ENV_SUB", Source: DefaultSourceEnvironment}, sub)
+		require.Equal(t, ResolvedDefault{Value: "eastus", Source: DefaultSourceEnvironment}, policy.ResolveLocation(ctx))
+	})
+
+	t.Run("Config", func(t *testing.T) {
+		policy := newPolicy(nil, azdConfig, azCli, singleSub)
+
+		sub, err := policy.ResolveSubscription(ctx)
+		require.NoError(t, err)
+		require.Equal(t, ResolvedDefault{Value: "CONFIG_SUB", Source: DefaultSourceConfig}, sub)
+		require.Equal(t, ResolvedDefault{Value: "westus", Source: DefaultSourceConfig}, policy.ResolveLocation(ctx))
+	})
+
+	t.Run("AzCli", func(t *testing.T) {
+		policy := newPolicy(nil, config.NewEmptyConfig(), azCli, singleSub)
+
+		sub, err := policy.ResolveSubscription(ctx)
+		require.NoError(t, err)
+		require.Equal(t, ResolvedDefault{Value: "AZCLI_SUB", Source: DefaultSourceAzCli}, sub)
+		require.Equal(t, ResolvedDefault{Value: "northeurope", Source: DefaultSourceAzCli}, policy.ResolveLocation(ctx))
+	})
+
+	t.Run("SingleSubscription", func(t *testing.T) {
+		subs := &fakeSubscriptionLister{subscriptions: []Subscription{{Id: "ONLY_SUB"}}}
+		policy := newPolicy(nil, config.NewEmptyConfig(), &fakeAzCliDefaults{}, subs)
+
+		sub, err := policy.ResolveSubscription(ctx)
+		require.NoError(t, err)
+		require.Equal(t, ResolvedDefault{Value: "ONLY_SUB", Source: DefaultSourceSingleSubscription}, sub)
+		require.Equal(t, ResolvedDefault{}, policy.ResolveLocation(ctx))
+	})
+
+	t.Run("MultipleSubscriptions", func(t *testing.T) {
+		subs := &fakeSubscriptionLister{subscriptions: []Subscription{{Id: "SUB_1"}, {Id: "SUB_2"}}}
+		policy := newPolicy(nil, config.NewEmptyConfig(), &fakeAzCliDefaults{}, subs)
+
+		sub, err := policy.ResolveSubscription(ctx)
+		require.NoError(t, err)
+		require.Equal(t, DefaultSourceNone, sub.Source)
+		require.Empty(t, sub.Value)
+	})
+
+	t.Run("SubscriptionsNotListedWhenEarlierRuleApplies", func(t *testing.T) {
+		subs := &fakeSubscriptionLister{subscriptions: []Subscription{{Id: "ONLY_SUB"}}}
+		policy := newPolicy(nil, azdConfig, nil, subs)
+
+		_, err := policy.ResolveSubscription(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 0, subs.calls)
+	})
+
+	t.Run("ListError", func(t *testing.T) {
+		subs := &fakeSubscriptionLister{err: errors.New("boom")}
+		policy := newPolicy(nil, config.NewEmptyConfig(), nil, subs)
+
+		_, err := policy.ResolveSubscription(ctx)
+		require.Error(t, err)
+	})
+}
+
+func TestAzCliDefaults(t *testing.T) {
+	t.Run("NoConfigDir", func(t *testing.T) {
+		azCli := &azCliConfigDir{dir: t.TempDir()}
+
+		sub, err := azCli.DefaultSubscriptionId()
+		require.NoError(t, err)
+		require.Empty(t, sub)
+
+		loc, err := azCli.DefaultLocation()
+		require.NoError(t, err)
+		require.Empty(t, loc)
+	})
+
+	t.Run("ReadsProfileAndConfig", func(t *testing.T) {
+		dir := t.TempDir()
+		profile := "\xef\xbb\xbf" + `{"subscriptions": [
+			{"id": "SUB_1", "isDefault": false},
+			{"id": "SUB_2", "isDefault": true}
+		]}`
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "azureProfile.json"), []byte(profile), 0600))
+		require.NoError(t, os.WriteFile(
+			filepath.Join(dir, "config"),
+			[]byte("[core]\nlocation = wrong\n\n[defaults]\n# comment\nlocation = westus3\ngroup = rg\n"),
+			0600,
+		))
+
+		azCli := &azCliConfigDir{dir: dir}
+
+		sub, err := azCli.DefaultSubscriptionId()
+		require.NoError(t, err)
+		require.Equal(t, "SUB_2", sub)
+
+		loc, err := azCli.DefaultLocation()
+		require.NoError(t, err)
+		require.Equal(t, "westus3", loc)
+	})
+}
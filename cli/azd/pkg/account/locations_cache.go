@@ -0,0 +1,149 @@
+package account
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/benbjohnson/clock"
+)
+
+// The file name of the cache used for storing the locations available to subscriptions of the currently logged in account.
+const cLocationsCacheFile = "locations.cache"
+
+// cachedLocations is the value stored in the locations cache for a single subscription.
+type cachedLocations struct {
+	Locations []Location `json:"locations"`
+	CachedAt  time.Time  `json:"cachedAt"`
+}
+
+// LocationsCache caches the list of locations available to a subscription, per tenant the subscription is accessed through.
+//
+// The cache is backed by an in-memory copy, then by local file system storage. Cached locations expire once they are older
+// than the cache time-to-live, after which Load returns [ErrCacheExpired].
+type LocationsCache struct {
+	cachePath string
+	ttl       time.Duration
+	clock     clock.Clock
+
+	inMemoryCopy map[string]cachedLocations
+	inMemoryLock sync.Mutex
+}
+
+func NewLocationsCache() (*LocationsCache, error) {
+	configDir, err := config.GetUserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("loading stored locations: %w", err)
+	}
+
+	return NewLocationsCacheWithDir(filepath.Join(configDir, cLocationsCacheFile))
+}
+
+func NewLocationsCacheWithDir(cachePath string) (*LocationsCache, error) {
+	return &LocationsCache{
+		cachePath: cachePath,
+		ttl:       accountCacheTTL(),
+		clock:     clock.New(),
+	}, nil
+}
+
+// Load loads the locations of a subscription from cache. Returns any error reading the cache, os.ErrNotExist when there are
+// no locations stored for the subscription or [ErrCacheExpired] when the cached locations are older than the cache
+// time-to-live.
+func (c *LocationsCache) Load(tenantId string, subscriptionId string) ([]Location, error) {
+	c.inMemoryLock.Lock()
+	defer c.inMemoryLock.Unlock()
+
+	entries, err := c.entries()
+	if err != nil {
+		return nil, err
+	}
+
+	entry, has := entries[locationsCacheKey(tenantId, subscriptionId)]
+	if !has {
+		return nil, os.ErrNotExist
+	}
+
+	if c.clock.Since(entry.CachedAt) > c.ttl {
+		return nil, ErrCacheExpired
+	}
+
+	return entry.Locations, nil
+}
+
+// Save saves the locations of a subscription to cache.
+func (c *LocationsCache) Save(tenantId string, subscriptionId string, locations []Location) error {
+	c.inMemoryLock.Lock()
+	defer c.inMemoryLock.Unlock()
+
+	entries, err := c.entries()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	updated := make(map[string]cachedLocations, len(entries)+1)
+	for key, entry := range entries {
+		updated[key] = entry
+	}
+
+	updated[locationsCacheKey(tenantId, subscriptionId)] = cachedLocations{
+		Locations: locations,
+		CachedAt:  c.clock.Now(),
+	}
+
+	content, err := json.Marshal(updated)
+	if err != nil {
+		return fmt.Errorf("failed to marshal locations: %w", err)
+	}
+
+	if err := os.WriteFile(c.cachePath, content, osutil.PermissionFile); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	c.inMemoryCopy = updated
+	return nil
+}
+
+// Clear removes all stored cache information. Returns an error if a filesystem error other than ErrNotExist occurred.
+func (c *LocationsCache) Clear() error {
+	c.inMemoryLock.Lock()
+	defer c.inMemoryLock.Unlock()
+
+	err := os.Remove(c.cachePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	c.inMemoryCopy = map[string]cachedLocations{}
+	return nil
+}
+
+// entries returns all the cached entries, reading them from disk if needed. Callers must hold inMemoryLock.
+func (c *LocationsCache) entries() (map[string]cachedLocations, error) {
+	if c.inMemoryCopy != nil {
+		return c.inMemoryCopy, nil
+	}
+
+	cacheFile, err := os.ReadFile(c.cachePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries map[string]cachedLocations
+	if err := json.Unmarshal(cacheFile, &entries); err != nil {
+		return nil, err
+	}
+
+	c.inMemoryCopy = entries
+	return entries, nil
+}
+
+func locationsCacheKey(tenantId string, subscriptionId string) string {
+	return fmt.Sprintf("%s.%s", tenantId, subscriptionId)
+}
@@ -31,6 +31,8 @@ type Manager interface {
 	GetAccountDefaults(ctx context.Context) (*Account, error)
 	GetDefaultLocationName(ctx context.Context) string
 	GetDefaultSubscriptionID(ctx context.Context) string
+	ResolveDefaultSubscription(ctx context.Context) (ResolvedDefault, error)
+	ResolveDefaultLocation(ctx context.Context) ResolvedDefault
 	GetSubscriptions(ctx context.Context) ([]Subscription, error)
 	GetSubscriptionsWithDefaultSet(ctx context.Context) ([]Subscription, error)
 	GetLocations(ctx context.Context, subscriptionId string) ([]Location, error)
@@ -45,6 +47,7 @@ type manager struct {
 	configManager config.FileConfigManager
 	config        config.Config
 	subManager    *SubscriptionsManager
+	defaults      *DefaultsPolicy
 }
 
 // Creates a new Account Manager instance
@@ -71,6 +74,7 @@ func NewManager(
 		subManager:    subManager,
 		configManager: configManager,
 		config:        azdConfig,
+		defaults:      NewDefaultsPolicy(azdConfig, subManager, NewAzCliDefaults()),
 	}, nil
 }
 
@@ -247,6 +251,16 @@ func (m *manager) GetDefaultSubscriptionID(ctx context.Context) string {
 	return subId
 }
 
+// Resolves the subscription to offer by default, see [DefaultsPolicy] for the precedence rules.
+func (m *manager) ResolveDefaultSubscription(ctx context.Context) (ResolvedDefault, error) {
+	return m.defaults.ResolveSubscription(ctx)
+}
+
+// Resolves the location to offer by default, see [DefaultsPolicy] for the precedence rules.
+func (m *manager) ResolveDefaultLocation(ctx context.Context) ResolvedDefault {
+	return m.defaults.ResolveLocation(ctx)
+}
+
 // Returns the default subscription for the current logged in principal
 // If set in config will return the configured subscription
 // otherwise will return nil.
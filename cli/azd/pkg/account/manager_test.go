@@ -695,6 +695,7 @@ func NewSubscriptionsManagerWithCache(
 	return &SubscriptionsManager{
 		service:       service,
 		cache:         cache,
+		locationCache: NewBypassLocationsCache(),
 		principalInfo: &principalInfoProviderMock{},
 		console:       mockinput.NewMockConsole(),
 	}
@@ -730,3 +731,22 @@ func (b *BypassSubscriptionsCache) Clear() error {
 func NewBypassSubscriptionsCache() *BypassSubscriptionsCache {
 	return &BypassSubscriptionsCache{}
 }
+
+type BypassLocationsCache struct {
+}
+
+func (b *BypassLocationsCache) Load(tenantId string, subscriptionId string) ([]Location, error) {
+	return nil, errors.New("bypass cache")
+}
+
+func (b *BypassLocationsCache) Save(tenantId string, subscriptionId string, locations []Location) error {
+	return nil
+}
+
+func (b *BypassLocationsCache) Clear() error {
+	return nil
+}
+
+func NewBypassLocationsCache() *BypassLocationsCache {
+	return &BypassLocationsCache{}
+}
@@ -4,25 +4,55 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/config"
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/benbjohnson/clock"
 )
 
 // The file name of the cache used for storing subscriptions accessible by the currently logged in account.
 const cSubscriptionsCacheFile = "subscriptions.cache"
 
+// cDefaultAccountCacheTTL is how long listed subscriptions and locations are considered fresh.
+const cDefaultAccountCacheTTL = 24 * time.Hour
+
+// cAccountCacheTTLEnvVarName allows overriding [cDefaultAccountCacheTTL], using the format of [time.ParseDuration].
+const cAccountCacheTTLEnvVarName = "AZD_ACCOUNT_CACHE_TTL"
+
+// ErrCacheExpired is returned when loading cached data that is older than the cache time-to-live.
+var ErrCacheExpired = errors.New("cached data has expired")
+
+// accountCacheTTL returns the time-to-live for cached subscriptions and locations.
+func accountCacheTTL() time.Duration {
+	if val, has := os.LookupEnv(cAccountCacheTTLEnvVarName); has {
+		ttl, err := time.ParseDuration(val)
+		if err == nil {
+			return ttl
+		}
+
+		log.Printf("ignoring invalid value '%s' for %s: %v", val, cAccountCacheTTLEnvVarName, err)
+	}
+
+	return cDefaultAccountCacheTTL
+}
+
 // SubscriptionsCache caches the list of subscriptions accessible by the currently logged in account.
 //
-// The cache is backed by an in-memory copy, then by local file system storage.
+// The cache is backed by an in-memory copy, then by local file system storage. Cached subscriptions expire once they are
+// older than the cache time-to-live, after which Load returns [ErrCacheExpired].
 type SubscriptionsCache struct {
 	cachePath string
+	ttl       time.Duration
+	clock     clock.Clock
 
-	inMemoryCopy []Subscription
-	inMemoryLock sync.RWMutex
+	inMemoryCopy     []Subscription
+	inMemoryCachedAt time.Time
+	inMemoryLock     sync.RWMutex
 }
 
 func NewSubscriptionsCache() (*SubscriptionsCache, error) {
@@ -37,20 +67,36 @@ func NewSubscriptionsCache() (*SubscriptionsCache, error) {
 func NewSubscriptionsCacheWithDir(cachePath string) (*SubscriptionsCache, error) {
 	return &SubscriptionsCache{
 		cachePath: cachePath,
+		ttl:       accountCacheTTL(),
+		clock:     clock.New(),
 	}, nil
 }
 
-// Load loads the subscriptions from cache. Returns any error reading the cache.
+// Load loads the subscriptions from cache. Returns any error reading the cache, or [ErrCacheExpired] when the
+// cached subscriptions are older than the cache time-to-live.
 func (s *SubscriptionsCache) Load() ([]Subscription, error) {
 	s.inMemoryLock.RLock()
 	if s.inMemoryCopy != nil {
 		defer s.inMemoryLock.RUnlock()
+		if s.expired(s.inMemoryCachedAt) {
+			return nil, ErrCacheExpired
+		}
+
 		return s.inMemoryCopy, nil
 	}
 	s.inMemoryLock.RUnlock()
 
 	s.inMemoryLock.Lock()
 	defer s.inMemoryLock.Unlock()
+	info, err := os.Stat(s.cachePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.expired(info.ModTime()) {
+		return nil, ErrCacheExpired
+	}
+
 	cacheFile, err := os.ReadFile(s.cachePath)
 	if err != nil {
 		return nil, err
@@ -63,6 +109,7 @@ func (s *SubscriptionsCache) Load() ([]Subscription, error) {
 	}
 
 	s.inMemoryCopy = subscriptions
+	s.inMemoryCachedAt = info.ModTime()
 	return subscriptions, nil
 }
 
@@ -81,6 +128,7 @@ func (s *SubscriptionsCache) Save(subscriptions []Subscription) error {
 	}
 
 	s.inMemoryCopy = subscriptions
+	s.inMemoryCachedAt = s.clock.Now()
 	return err
 }
 
@@ -94,6 +142,12 @@ func (s *SubscriptionsCache) Clear() error {
 		return err
 	}
 
-	s.inMemoryCopy = []Subscription{}
+	// A nil copy makes the next Load read from disk, which reports the cache miss
+	s.inMemoryCopy = nil
+	s.inMemoryCachedAt = time.Time{}
 	return nil
 }
+
+func (s *SubscriptionsCache) expired(cachedAt time.Time) bool {
+	return s.clock.Since(cachedAt) > s.ttl
+}
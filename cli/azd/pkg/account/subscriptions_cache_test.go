@@ -0,0 +1,154 @@
+package account
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriptionsCache(t *testing.T) {
+	subs := []Subscription{
+		{
+			Id:                 "SUBSCRIPTION_1",
+			Name:               "Subscription 1",
+			TenantId:           "TENANT_ID_1",
+			UserAccessTenantId: "TENANT_ID_1",
+		},
+	}
+
+	newCache := func(t *testing.T, path string) (*SubscriptionsCache, *clock.Mock) {
+		mockClock := clock.NewMock()
+		mockClock.Set(time.Now())
+
+		cache, err := NewSubscriptionsCacheWithDir(path)
+		require.NoError(t, err)
+		cache.clock = mockClock
+		cache.ttl = time.Hour
+
+		return cache, mockClock
+	}
+
+	t.Run("Hit", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), cSubscriptionsCacheFile)
+		cache, _ := newCache(t, path)
+
+		_, err := cache.Load()
+		require.True(t, errors.Is(err, os.ErrNotExist))
+
+		require.NoError(t, cache.Save(subs))
+
+		loaded, err := cache.Load()
+		require.NoError(t, err)
+		require.Equal(t, subs, loaded)
+
+		// A new cache instance reads the stored subscriptions from disk.
+		fromDisk, _ := newCache(t, path)
+		loaded, err = fromDisk.Load()
+		require.NoError(t, err)
+		require.Equal(t, subs, loaded)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), cSubscriptionsCacheFile)
+		cache, mockClock := newCache(t, path)
+
+		require.NoError(t, cache.Save(subs))
+
+		mockClock.Add(2 * time.Hour)
+		_, err := cache.Load()
+		require.True(t, errors.Is(err, ErrCacheExpired))
+
+		fromDisk, diskClock := newCache(t, path)
+		diskClock.Add(2 * time.Hour)
+		_, err = fromDisk.Load()
+		require.True(t, errors.Is(err, ErrCacheExpired))
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), cSubscriptionsCacheFile)
+		cache, _ := newCache(t, path)
+
+		require.NoError(t, cache.Save(subs))
+		require.NoError(t, cache.Clear())
+
+		_, err := os.Stat(path)
+		require.True(t, errors.Is(err, os.ErrNotExist))
+
+		// Loading after clearing is a cache miss, rather than an empty list of subscriptions
+		loaded, err := cache.Load()
+		require.True(t, errors.Is(err, os.ErrNotExist))
+		require.Nil(t, loaded)
+	})
+}
+
+func TestLocationsCache(t *testing.T) {
+	locations := []Location{
+		{
+			Name:                "westus2",
+			DisplayName:         "West US 2",
+			RegionalDisplayName: "(US) West US 2",
+		},
+	}
+
+	newCache := func(t *testing.T, path string) (*LocationsCache, *clock.Mock) {
+		mockClock := clock.NewMock()
+		mockClock.Set(time.Now())
+
+		cache, err := NewLocationsCacheWithDir(path)
+		require.NoError(t, err)
+		cache.clock = mockClock
+		cache.ttl = time.Hour
+
+		return cache, mockClock
+	}
+
+	t.Run("Hit", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), cLocationsCacheFile)
+		cache, _ := newCache(t, path)
+
+		_, err := cache.Load("TENANT_ID_1", "SUBSCRIPTION_1")
+		require.True(t, errors.Is(err, os.ErrNotExist))
+
+		require.NoError(t, cache.Save("TENANT_ID_1", "SUBSCRIPTION_1", locations))
+
+		loaded, err := cache.Load("TENANT_ID_1", "SUBSCRIPTION_1")
+		require.NoError(t, err)
+		require.Equal(t, locations, loaded)
+
+		// Locations are stored per tenant and subscription.
+		_, err = cache.Load("TENANT_ID_2", "SUBSCRIPTION_1")
+		require.True(t, errors.Is(err, os.ErrNotExist))
+
+		fromDisk, _ := newCache(t, path)
+		loaded, err = fromDisk.Load("TENANT_ID_1", "SUBSCRIPTION_1")
+		require.NoError(t, err)
+		require.Equal(t, locations, loaded)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), cLocationsCacheFile)
+		cache, mockClock := newCache(t, path)
+
+		require.NoError(t, cache.Save("TENANT_ID_1", "SUBSCRIPTION_1", locations))
+
+		mockClock.Add(2 * time.Hour)
+		_, err := cache.Load("TENANT_ID_1", "SUBSCRIPTION_1")
+		require.True(t, errors.Is(err, ErrCacheExpired))
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), cLocationsCacheFile)
+		cache, _ := newCache(t, path)
+
+		require.NoError(t, cache.Save("TENANT_ID_1", "SUBSCRIPTION_1", locations))
+		require.NoError(t, cache.Clear())
+
+		_, err := cache.Load("TENANT_ID_1", "SUBSCRIPTION_1")
+		require.True(t, errors.Is(err, os.ErrNotExist))
+	})
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/auth"
 	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/logging"
 	"go.uber.org/multierr"
 )
 
@@ -37,6 +38,12 @@ type subCache interface {
 	Clear() error
 }
 
+type locationCache interface {
+	Load(tenantId string, subscriptionId string) ([]Location, error)
+	Save(tenantId string, subscriptionId string, locations []Location) error
+	Clear() error
+}
+
 // SubscriptionsManager manages listing, storing and retrieving subscriptions for the current account.
 //
 // Since the application supports multi-tenancy, subscriptions can be accessed by the user through different tenants.
@@ -46,6 +53,7 @@ type SubscriptionsManager struct {
 	service       *SubscriptionsService
 	principalInfo principalInfoProvider
 	cache         subCache
+	locationCache locationCache
 	console       input.Console
 }
 
@@ -58,21 +66,32 @@ func NewSubscriptionsManager(
 		return nil, err
 	}
 
+	locationCache, err := NewLocationsCache()
+	if err != nil {
+		return nil, err
+	}
+
 	return &SubscriptionsManager{
 		service:       service,
 		cache:         cache,
+		locationCache: locationCache,
 		principalInfo: auth,
 		console:       console,
 	}, nil
 }
 
-// Clears stored cached subscriptions. This can only return an error is a filesystem error other than ErrNotExist occurred.
+// Clears stored cached subscriptions, along with the cached locations of those subscriptions. This can only return an error
+// is a filesystem error other than ErrNotExist occurred.
 func (m *SubscriptionsManager) ClearSubscriptions(ctx context.Context) error {
 	err := m.cache.Clear()
 	if err != nil {
 		return fmt.Errorf("clearing stored subscriptions: %w", err)
 	}
 
+	if err := m.locationCache.Clear(); err != nil {
+		return fmt.Errorf("clearing stored locations: %w", err)
+	}
+
 	return nil
 }
 
@@ -114,9 +133,17 @@ func (m *SubscriptionsManager) LookupTenant(ctx context.Context, subscriptionId
 		return "", fmt.Errorf("resolving user access to subscription '%s' : %w", subscriptionId, err)
 	}
 
-	for _, sub := range subscriptions {
-		if sub.Id == subscriptionId {
-			return sub.UserAccessTenantId, nil
+	if tenantId, has := userAccessTenant(subscriptions, subscriptionId); has {
+		return tenantId, nil
+	}
+
+	// The stored subscriptions may predate the account being granted access to this subscription. Query ARM once more
+	// before giving up.
+	if err := m.RefreshSubscriptions(ctx); err != nil {
+		logging.Warn("refreshing the subscriptions", "error", err)
+	} else if subscriptions, err := m.cache.Load(); err == nil {
+		if tenantId, has := userAccessTenant(subscriptions, subscriptionId); has {
+			return tenantId, nil
 		}
 	}
 
@@ -128,6 +155,17 @@ func (m *SubscriptionsManager) LookupTenant(ctx context.Context, subscriptionId
 		subscriptionId)
 }
 
+// userAccessTenant returns the tenant through which the current account has access to subscriptionId.
+func userAccessTenant(subscriptions []Subscription, subscriptionId string) (string, bool) {
+	for _, sub := range subscriptions {
+		if sub.Id == subscriptionId {
+			return sub.UserAccessTenantId, true
+		}
+	}
+
+	return "", false
+}
+
 // GetSubscriptions retrieves subscriptions accessible by the current account with caching semantics.
 //
 // Unlike ListSubscriptions, GetSubscriptions first examines the subscriptions cache.
@@ -330,7 +368,22 @@ func (m *SubscriptionsManager) listLocations(
 	if err != nil {
 		return nil, err
 	}
-	return m.service.ListSubscriptionLocations(ctx, subscriptionId, tenantId)
+
+	locations, err := m.locationCache.Load(tenantId, subscriptionId)
+	if err == nil {
+		return locations, nil
+	}
+
+	locations, err = m.service.ListSubscriptionLocations(ctx, subscriptionId, tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.locationCache.Save(tenantId, subscriptionId, locations); err != nil {
+		log.Printf("failed saving locations to cache: %v", err)
+	}
+
+	return locations, nil
 }
 
 func (m *SubscriptionsManager) getSubscription(ctx context.Context, subscriptionId string) (*Subscription, error) {
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"path/filepath"
 	"sort"
 	"testing"
 
@@ -239,3 +241,146 @@ func toExpectedSubscriptions(armTenantSubs map[string][]*armsubscriptions.Subscr
 
 	return results
 }
+
+func TestSubscriptionsManager_LookupTenant(t *testing.T) {
+	ctx := context.Background()
+	mockHttp := mockhttp.NewMockHttpUtil()
+	mockarmresources.MockListTenants(mockHttp, armsubscriptions.TenantListResult{
+		Value: generateTenants(2),
+	})
+
+	tenantSubs := generateSubscriptionsForTenants(1, 2)
+	for tenant, subs := range tenantSubs {
+		tenantID := tenant
+		subs := subs
+		mockHttp.When(func(request *http.Request) bool {
+			return mockarmresources.IsListSubscriptions(request) && mockhttp.HasBearerToken(request, tenantID)
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armsubscriptions.ClientListResponse{
+				SubscriptionListResult: armsubscriptions.SubscriptionListResult{
+					Value: subs,
+				},
+			})
+		})
+	}
+
+	// The stored subscriptions only know about the subscription in the first tenant.
+	cache := &memorySubscriptionsCache{
+		subs: []Subscription{
+			{
+				Id:                 "SUBSCRIPTION_1_TENANT_ID_1",
+				TenantId:           "TENANT_ID_1",
+				UserAccessTenantId: "TENANT_ID_1",
+			},
+		},
+	}
+
+	subManager := &SubscriptionsManager{
+		service: NewSubscriptionsService(
+			&mocks.MockMultiTenantCredentialProvider{},
+			armClientOptions(mockHttp),
+		),
+		cache:         cache,
+		principalInfo: &principalInfoProviderMock{},
+		console:       mockinput.NewMockConsole(),
+	}
+
+	tenantId, err := subManager.LookupTenant(ctx, "SUBSCRIPTION_1_TENANT_ID_1")
+	require.NoError(t, err)
+	require.Equal(t, "TENANT_ID_1", tenantId)
+
+	// Not in the stored subscriptions, resolved by querying ARM again.
+	tenantId, err = subManager.LookupTenant(ctx, "SUBSCRIPTION_1_TENANT_ID_2")
+	require.NoError(t, err)
+	require.Equal(t, "TENANT_ID_2", tenantId)
+	require.Len(t, cache.subs, 2)
+
+	_, err = subManager.LookupTenant(ctx, "SUBSCRIPTION_UNKNOWN")
+	require.Error(t, err)
+}
+
+// memorySubscriptionsCache implements subCache by storing subscriptions in memory.
+type memorySubscriptionsCache struct {
+	subs []Subscription
+}
+
+func (c *memorySubscriptionsCache) Load() ([]Subscription, error) {
+	if c.subs == nil {
+		return nil, errors.New("no subscriptions stored")
+	}
+
+	return c.subs, nil
+}
+
+func (c *memorySubscriptionsCache) Save(subs []Subscription) error {
+	c.subs = subs
+	return nil
+}
+
+func (c *memorySubscriptionsCache) Clear() error {
+	c.subs = nil
+	return nil
+}
+
+func TestSubscriptionsManager_ListLocationsCached(t *testing.T) {
+	ctx := context.Background()
+	mockHttp := mockhttp.NewMockHttpUtil()
+
+	listCalls := 0
+	mockHttp.When(func(request *http.Request) bool {
+		return mockarmresources.IsListLocations(request, "SUBSCRIPTION_1")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		listCalls++
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armsubscriptions.ClientListLocationsResponse{
+			LocationListResult: armsubscriptions.LocationListResult{
+				Value: []*armsubscriptions.Location{
+					{
+						ID:                  convert.RefOf("westus"),
+						Name:                convert.RefOf("westus"),
+						DisplayName:         convert.RefOf("West US"),
+						RegionalDisplayName: convert.RefOf("(US) West US"),
+						Metadata: &armsubscriptions.LocationMetadata{
+							RegionType: convert.RefOf(armsubscriptions.RegionTypePhysical),
+						},
+					},
+				},
+			},
+		})
+	})
+
+	locationCache, err := NewLocationsCacheWithDir(filepath.Join(t.TempDir(), cLocationsCacheFile))
+	require.NoError(t, err)
+
+	subManager := &SubscriptionsManager{
+		service: NewSubscriptionsService(
+			&mocks.MockMultiTenantCredentialProvider{},
+			armClientOptions(mockHttp),
+		),
+		cache:         NewInMemorySubscriptionsCache(),
+		locationCache: locationCache,
+		principalInfo: &principalInfoProviderMock{
+			GetLoggedInServicePrincipalTenantIDFunc: func(context.Context) (*string, error) {
+				return convert.RefOf("TENANT_ID_1"), nil
+			},
+		},
+		console: mockinput.NewMockConsole(),
+	}
+
+	locations, err := subManager.ListLocations(ctx, "SUBSCRIPTION_1")
+	require.NoError(t, err)
+	require.Len(t, locations, 1)
+	require.Equal(t, 1, listCalls)
+
+	// Served from the cache.
+	locations, err = subManager.ListLocations(ctx, "SUBSCRIPTION_1")
+	require.NoError(t, err)
+	require.Len(t, locations, 1)
+	require.Equal(t, 1, listCalls)
+
+	// Clearing subscriptions (done on login, logout and `env new --refresh`) forces locations to be fetched again.
+	require.NoError(t, subManager.ClearSubscriptions(ctx))
+
+	_, err = subManager.ListLocations(ctx, "SUBSCRIPTION_1")
+	require.NoError(t, err)
+	require.Equal(t, 2, listCalls)
+}
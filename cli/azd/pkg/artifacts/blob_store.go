@@ -0,0 +1,125 @@
+package artifacts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/azure/azure-dev/cli/azd/pkg/auth"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk/storage"
+	"github.com/azure/azure-dev/cli/azd/pkg/cloud"
+)
+
+// blobStore uploads artifacts as blobs to an Azure storage account container.
+type blobStore struct {
+	accountConfig *storage.AccountConfig
+	blobClient    storage.BlobClient
+}
+
+// NewBlobStore creates an artifact store uploading to the storage account container set in the 'accountName' and
+// 'containerName' of the artifact store configuration.
+func NewBlobStore(
+	config *Config,
+	credentialProvider auth.MultiTenantCredentialProvider,
+	coreClientOptions *azcore.ClientOptions,
+	cloud *cloud.Cloud,
+) (Store, error) {
+	var accountConfig *storage.AccountConfig
+	if err := decodeConfig(config, &accountConfig); err != nil {
+		return nil, err
+	}
+
+	if accountConfig == nil || accountConfig.AccountName == "" || accountConfig.ContainerName == "" {
+		return nil, errors.New("the 'AzureBlobStorage' artifact store requires 'accountName' and 'containerName'")
+	}
+
+	sdkClient, err := storage.NewBlobSdkClient(credentialProvider, accountConfig, coreClientOptions, cloud)
+	if err != nil {
+		return nil, err
+	}
+
+	return newBlobStore(accountConfig, storage.NewBlobClient(accountConfig, sdkClient)), nil
+}
+
+func newBlobStore(accountConfig *storage.AccountConfig, blobClient storage.BlobClient) *blobStore {
+	return &blobStore{
+		accountConfig: accountConfig,
+		blobClient:    blobClient,
+	}
+}
+
+// Upload uploads the file at path as a blob named name and returns the URL of the blob.
+func (s *blobStore) Upload(ctx context.Context, name string, path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("opening artifact: %w", err)
+	}
+	defer file.Close()
+
+	if err := s.blobClient.Upload(ctx, name, file); err != nil {
+		return "", err
+	}
+
+	uri := url.URL{
+		Scheme: "https",
+		Host:   s.host(),
+		Path:   fmt.Sprintf("/%s/%s", s.accountConfig.ContainerName, name),
+	}
+
+	return uri.String(), nil
+}
+
+// Download downloads the blob at the URL uri to the file at path.
+func (s *blobStore) Download(ctx context.Context, uri string, path string) error {
+	blobPath, err := s.blobPath(uri)
+	if err != nil {
+		return err
+	}
+
+	reader, err := s.blobClient.Download(ctx, blobPath)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating artifact: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, reader); err != nil {
+		return fmt.Errorf("writing artifact: %w", err)
+	}
+
+	return nil
+}
+
+func (s *blobStore) host() string {
+	return fmt.Sprintf("%s.blob.%s", s.accountConfig.AccountName, s.accountConfig.Endpoint)
+}
+
+// blobPath returns the path of the blob at the URL uri within the configured container.
+func (s *blobStore) blobPath(uri string) (string, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("parsing artifact uri '%s': %w", uri, err)
+	}
+
+	containerPrefix := fmt.Sprintf("/%s/", s.accountConfig.ContainerName)
+	if !strings.EqualFold(parsed.Host, s.host()) || !strings.HasPrefix(parsed.Path, containerPrefix) {
+		return "", fmt.Errorf(
+			"artifact '%s' is not stored in the configured container '%s' of storage account '%s'",
+			uri,
+			s.accountConfig.ContainerName,
+			s.accountConfig.AccountName,
+		)
+	}
+
+	return strings.TrimPrefix(parsed.Path, containerPrefix), nil
+}
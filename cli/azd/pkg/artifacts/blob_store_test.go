@@ -0,0 +1,69 @@
+package artifacts
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk/storage"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBlobClient keeps the uploaded blobs in memory.
+type fakeBlobClient struct {
+	storage.BlobClient
+	blobs map[string][]byte
+}
+
+func (c *fakeBlobClient) Upload(ctx context.Context, blobPath string, reader io.Reader) error {
+	contents, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	c.blobs[blobPath] = contents
+	return nil
+}
+
+func (c *fakeBlobClient) Download(ctx context.Context, blobPath string) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(c.blobs[blobPath])), nil
+}
+
+func Test_BlobStore(t *testing.T) {
+	blobClient := &fakeBlobClient{blobs: map[string][]byte{}}
+	store := newBlobStore(&storage.AccountConfig{
+		AccountName:   "account",
+		ContainerName: "packages",
+		Endpoint:      "core.windows.net",
+	}, blobClient)
+
+	dir := t.TempDir()
+	packagePath := filepath.Join(dir, "api.zip")
+	require.NoError(t, os.WriteFile(packagePath, []byte("package"), 0600))
+
+	uri, err := store.Upload(context.Background(), "todo/api/api.zip", packagePath)
+	require.NoError(t, err)
+	require.Equal(t, "https://account.blob.core.windows.net/packages/todo/api/api.zip", uri)
+	require.Equal(t, []byte("package"), blobClient.blobs["todo/api/api.zip"])
+
+	t.Run("Download", func(t *testing.T) {
+		downloadPath := filepath.Join(dir, "downloaded.zip")
+		require.NoError(t, store.Download(context.Background(), uri, downloadPath))
+
+		contents, err := os.ReadFile(downloadPath)
+		require.NoError(t, err)
+		require.Equal(t, []byte("package"), contents)
+	})
+
+	t.Run("DownloadOtherContainer", func(t *testing.T) {
+		err := store.Download(
+			context.Background(),
+			"https://account.blob.core.windows.net/other/todo/api/api.zip",
+			filepath.Join(dir, "other.zip"),
+		)
+		require.ErrorContains(t, err, "is not stored in the configured container 'packages'")
+	})
+}
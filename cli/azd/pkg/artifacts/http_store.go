@@ -0,0 +1,140 @@
+package artifacts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+)
+
+// httpStoreConfig is the configuration of the 'Http' artifact store.
+type httpStoreConfig struct {
+	// The base URL artifacts are uploaded under.
+	Url string `json:"url"`
+	// Headers sent with each request, like an authorization header. Values may reference the values of the azd
+	// environment or environment variables.
+	Headers map[string]string `json:"headers"`
+}
+
+// httpStore uploads artifacts with a PUT request under a base URL, as supported by most artifact feeds.
+type httpStore struct {
+	config     *httpStoreConfig
+	env        *environment.Environment
+	httpClient httputil.HttpClient
+}
+
+// NewHttpStore creates an artifact store uploading to the 'url' of the artifact store configuration.
+func NewHttpStore(config *Config, env *environment.Environment, httpClient httputil.HttpClient) (Store, error) {
+	var storeConfig *httpStoreConfig
+	if err := decodeConfig(config, &storeConfig); err != nil {
+		return nil, err
+	}
+
+	if storeConfig == nil || storeConfig.Url == "" {
+		return nil, errors.New("the 'Http' artifact store requires 'url'")
+	}
+
+	return &httpStore{
+		config:     storeConfig,
+		env:        env,
+		httpClient: httpClient,
+	}, nil
+}
+
+// Upload uploads the file at path to the URL of name under the base URL and returns that URL.
+func (s *httpStore) Upload(ctx context.Context, name string, path string) (string, error) {
+	uri, err := url.JoinPath(s.config.Url, name)
+	if err != nil {
+		return "", fmt.Errorf("building artifact uri: %w", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("opening artifact: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", fmt.Errorf("reading artifact: %w", err)
+	}
+
+	req, err := s.newRequest(ctx, http.MethodPut, uri, file)
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("uploading artifact '%s': %w", name, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return "", fmt.Errorf("uploading artifact '%s': unexpected status code %d", name, res.StatusCode)
+	}
+
+	return uri, nil
+}
+
+// Download downloads the artifact at uri to the file at path.
+func (s *httpStore) Download(ctx context.Context, uri string, path string) error {
+	if !strings.HasPrefix(uri, strings.TrimSuffix(s.config.Url, "/")+"/") {
+		return fmt.Errorf("artifact '%s' is not stored under the configured url '%s'", uri, s.config.Url)
+	}
+
+	req, err := s.newRequest(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("downloading artifact '%s': %w", uri, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("downloading artifact '%s': unexpected status code %d", uri, res.StatusCode)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating artifact: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, res.Body); err != nil {
+		return fmt.Errorf("writing artifact: %w", err)
+	}
+
+	return nil
+}
+
+func (s *httpStore) newRequest(ctx context.Context, method string, uri string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, uri, body)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	for name, value := range s.config.Headers {
+		expanded, err := osutil.NewExpandableString(value).Envsubst(s.env.Getenv)
+		if err != nil {
+			return nil, fmt.Errorf("expanding header '%s': %w", name, err)
+		}
+
+		req.Header.Set(name, expanded)
+	}
+
+	return req, nil
+}
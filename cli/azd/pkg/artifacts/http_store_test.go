@@ -0,0 +1,93 @@
+package artifacts
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockhttp"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_HttpStore(t *testing.T) {
+	env := environment.NewWithValues("dev", map[string]string{"FEED_TOKEN": "TOKEN"})
+
+	config := &Config{
+		Backend: string(StoreKindHttp),
+		Config: map[string]any{
+			"url":     "https://feed.example.com/artifacts/",
+			"headers": map[string]any{"Authorization": "Bearer ${FEED_TOKEN}"},
+		},
+	}
+
+	uploaded := map[string][]byte{}
+	httpClient := mockhttp.NewMockHttpUtil()
+	httpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		require.Equal(t, "Bearer TOKEN", request.Header.Get("Authorization"))
+
+		body, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+		uploaded[request.URL.String()] = body
+
+		return &http.Response{StatusCode: http.StatusCreated, Body: http.NoBody}, nil
+	})
+	httpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		body, has := uploaded[request.URL.String()]
+		if !has {
+			return &http.Response{StatusCode: http.StatusNotFound, Body: http.NoBody}, nil
+		}
+
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body))}, nil
+	})
+
+	store, err := NewHttpStore(config, env, httpClient)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	packagePath := filepath.Join(dir, "api.zip")
+	require.NoError(t, os.WriteFile(packagePath, []byte("package"), 0600))
+
+	t.Run("Upload", func(t *testing.T) {
+		uri, err := store.Upload(context.Background(), "todo/api/api.zip", packagePath)
+		require.NoError(t, err)
+		require.Equal(t, "https://feed.example.com/artifacts/todo/api/api.zip", uri)
+		require.Equal(t, []byte("package"), uploaded[uri])
+	})
+
+	t.Run("Download", func(t *testing.T) {
+		downloadPath := filepath.Join(dir, "downloaded.zip")
+		err := store.Download(
+			context.Background(), "https://feed.example.com/artifacts/todo/api/api.zip", downloadPath)
+		require.NoError(t, err)
+
+		contents, err := os.ReadFile(downloadPath)
+		require.NoError(t, err)
+		require.Equal(t, []byte("package"), contents)
+	})
+
+	t.Run("DownloadMissing", func(t *testing.T) {
+		err := store.Download(
+			context.Background(), "https://feed.example.com/artifacts/todo/web/web.zip", filepath.Join(dir, "web.zip"))
+		require.ErrorContains(t, err, "unexpected status code 404")
+	})
+
+	t.Run("DownloadOtherUrl", func(t *testing.T) {
+		err := store.Download(
+			context.Background(), "https://other.example.com/api.zip", filepath.Join(dir, "other.zip"))
+		require.ErrorContains(t, err, "is not stored under the configured url")
+	})
+}
+
+func Test_NewHttpStore_RequiresUrl(t *testing.T) {
+	_, err := NewHttpStore(&Config{Backend: string(StoreKindHttp)}, environment.New("dev"), mockhttp.NewMockHttpUtil())
+	require.ErrorContains(t, err, "requires 'url'")
+}
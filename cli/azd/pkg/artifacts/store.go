@@ -0,0 +1,81 @@
+package artifacts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/ioc"
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+)
+
+// Config is the configuration of the store packaged artifacts are uploaded to, set in the 'artifacts' section of
+// azure.yaml or the 'artifacts' user config.
+type Config struct {
+	Backend string         `json:"backend" yaml:"backend"`
+	Config  map[string]any `json:"config"  yaml:"config"`
+}
+
+// StoreKind is the kind of backend an artifact store uploads to.
+type StoreKind string
+
+const (
+	StoreKindAzureBlobStorage StoreKind = "AzureBlobStorage"
+	StoreKindHttp             StoreKind = "Http"
+)
+
+var ValidStoreKinds = []string{
+	string(StoreKindAzureBlobStorage),
+	string(StoreKindHttp),
+}
+
+var ErrStoreNotConfigured = errors.New(
+	"no artifact store is configured. Set 'artifacts' in azure.yaml or the 'artifacts' user config",
+)
+
+// Store uploads packaged artifacts so they can be deployed later, possibly from a different machine.
+type Store interface {
+	// Upload uploads the file at path as the artifact with the given name and returns the URI of the uploaded artifact.
+	Upload(ctx context.Context, name string, path string) (string, error)
+
+	// Download downloads the artifact at uri, as returned by Upload, to the file at path.
+	Download(ctx context.Context, uri string, path string) error
+}
+
+// ResolveStore resolves the artifact store of the configured backend, returning ErrStoreNotConfigured when no store
+// is configured.
+func ResolveStore(serviceLocator ioc.ServiceLocator, config *Config) (Store, error) {
+	if config == nil {
+		return nil, ErrStoreNotConfigured
+	}
+
+	var store Store
+	if err := serviceLocator.ResolveNamed(config.Backend, &store); err != nil {
+		if errors.Is(err, ioc.ErrResolveInstance) {
+			return nil, fmt.Errorf(
+				"artifact store configuration is invalid. The specified backend '%s' is not valid. Valid values are '%s'.",
+				config.Backend,
+				ux.ListAsText(ValidStoreKinds),
+			)
+		}
+
+		return nil, fmt.Errorf("resolving artifact store: %w", err)
+	}
+
+	return store, nil
+}
+
+// decodeConfig decodes the backend specific configuration into value.
+func decodeConfig(config *Config, value any) error {
+	jsonBytes, err := json.Marshal(config.Config)
+	if err != nil {
+		return fmt.Errorf("marshalling artifact store config: %w", err)
+	}
+
+	if err := json.Unmarshal(jsonBytes, value); err != nil {
+		return fmt.Errorf("unmarshalling artifact store config: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package auth
+
+import (
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// CredentialSource describes where the credential for the current user comes from.
+type CredentialSource string
+
+const (
+	// The account logged in with `azd auth login`.
+	CredentialSourceAzd CredentialSource = "azd"
+	// The account logged in with `az login`.
+	CredentialSourceAzCli CredentialSource = "azcli"
+	// The account CloudShell is running as.
+	CredentialSourceCloudShell CredentialSource = "cloudshell"
+	// An external authentication provider, configured with AZD_AUTH_ENDPOINT.
+	CredentialSourceExternal CredentialSource = "external"
+)
+
+// CredentialSourceOf returns the source of a credential returned by [Manager.CredentialForCurrentUser].
+func CredentialSourceOf(cred azcore.TokenCredential) CredentialSource {
+	switch cred.(type) {
+	case *azidentity.AzureCLICredential:
+		return CredentialSourceAzCli
+	case *CloudShellCredential:
+		return CredentialSourceCloudShell
+	case *RemoteCredential:
+		return CredentialSourceExternal
+	default:
+		return CredentialSourceAzd
+	}
+}
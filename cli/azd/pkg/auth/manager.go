@@ -12,9 +12,11 @@ import (
 	"log"
 	"net/url"
 	"os"
+	osexec "os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	azcloud "github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
@@ -32,6 +34,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/cli/browser"
 )
 
@@ -48,6 +51,15 @@ const cCurrentUserKey = "auth.account.currentUser"
 // it ourselves. The value should be a string as specified by [strconv.ParseBool].
 const cUseAzCliAuthKey = "auth.useAzCliAuth"
 
+// azCliInstallUrl is the URL of the instructions to install the az CLI.
+const azCliInstallUrl = "https://aka.ms/azure-cli"
+
+// cAzCliCredentialKey is the key we use in config to allow an existing az CLI login to be used as a credential source in
+// addition to the account logged in with azd. Supported values are "preferred" (the az CLI login is tried first),
+// "fallback" (the az CLI login is used when no account is logged in with azd) and "disabled" (the default). When the az CLI
+// login cannot provide a token, the next source is used.
+const cAzCliCredentialKey = "auth.azCliCredential"
+
 // cAuthConfigFileName is the name of the file we store in the user configuration directory which is used to persist
 // auth related configuration information (e.g. the home account id of the current user). This information is not secret.
 const cAuthConfigFileName = "auth.json"
@@ -88,6 +100,21 @@ type Manager struct {
 	httpClient          HttpClient
 	console             input.Console
 	externalAuthCfg     ExternalAuthConfiguration
+
+	// azCliCredentialFactory creates the credential used for the az CLI credential source. When nil, an
+	// azidentity.AzureCLICredential is used.
+	azCliCredentialFactory func(tenantID string) (azcore.TokenCredential, error)
+
+	// azCliProbes are the results of checking the az CLI login by tenant, since the check runs az, which is slow, and
+	// credentials are requested many times by a command.
+	azCliProbesMu sync.Mutex
+	azCliProbes   map[string]azCliProbe
+}
+
+// azCliProbe is the result of checking an access token can be fetched with the az CLI credential of a tenant.
+type azCliProbe struct {
+	cred azcore.TokenCredential
+	err  error
 }
 
 type ExternalAuthConfiguration struct {
@@ -176,6 +203,18 @@ func EnsureLoggedInCredential(
 	credential azcore.TokenCredential,
 	cloud *cloud.Cloud,
 ) (*azcore.AccessToken, error) {
+	// Report a missing az CLI up front, rather than the error of the az CLI credential when fetching the token
+	if _, isAzCli := credential.(*azidentity.AzureCLICredential); isAzCli {
+		if err := tools.ToolInPath("az"); errors.Is(err, osexec.ErrNotFound) {
+			return &azcore.AccessToken{}, &tools.MissingToolsError{
+				Operation: "authenticating with the az CLI",
+				Tools: []tools.MissingTool{
+					{Name: "Azure CLI", InstallUrl: azCliInstallUrl, Err: err},
+				},
+			}
+		}
+	}
+
 	token, err := credential.GetToken(ctx, policy.TokenRequestOptions{
 		Scopes: LoginScopes(cloud),
 	})
@@ -214,15 +253,85 @@ func (m *Manager) CredentialForCurrentUser(
 
 	if shouldUseLegacyAuth(userConfig) {
 		log.Printf("delegating auth to az since %s is set to true", cUseAzCliAuthKey)
-		cred, err := azidentity.NewAzureCLICredential(&azidentity.AzureCLICredentialOptions{
-			TenantID: options.TenantID,
-		})
+		cred, err := m.newAzCliCredential(options.TenantID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create credential: %w: %w", err, ErrNoCurrentUser)
 		}
 		return cred, nil
 	}
 
+	azCliPrecedence := readAzCliCredentialPrecedence(userConfig)
+
+	if azCliPrecedence == azCliCredentialPreferred {
+		cred, err := m.credentialFromAzCli(ctx, options.TenantID)
+		if err == nil {
+			return cred, nil
+		}
+
+		log.Printf("az CLI credential not available, falling back to azd credential: %v", err)
+	}
+
+	cred, err := m.credentialFromAzdLogin(ctx, options)
+	if errors.Is(err, ErrNoCurrentUser) && azCliPrecedence == azCliCredentialFallback {
+		azCliCred, azCliErr := m.credentialFromAzCli(ctx, options.TenantID)
+		if azCliErr == nil {
+			return azCliCred, nil
+		}
+
+		log.Printf("az CLI credential not available: %v", azCliErr)
+	}
+
+	return cred, err
+}
+
+// credentialFromAzCli returns a credential backed by the current az CLI login, after checking an access token can be
+// fetched with it. The result of the check is cached by tenant for the lifetime of the manager.
+func (m *Manager) credentialFromAzCli(ctx context.Context, tenantID string) (azcore.TokenCredential, error) {
+	m.azCliProbesMu.Lock()
+	defer m.azCliProbesMu.Unlock()
+
+	if probe, has := m.azCliProbes[tenantID]; has {
+		return probe.cred, probe.err
+	}
+
+	cred, err := m.newAzCliCredential(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("creating az CLI credential: %w", err)
+	}
+
+	if _, tokenErr := EnsureLoggedInCredential(ctx, cred, m.cloud); tokenErr != nil {
+		cred, err = nil, fmt.Errorf("fetching token from az CLI: %w", tokenErr)
+
+		// A check interrupted by the cancellation of the command says nothing about the az CLI login
+		if ctx.Err() != nil {
+			return nil, err
+		}
+	}
+
+	if m.azCliProbes == nil {
+		m.azCliProbes = map[string]azCliProbe{}
+	}
+	m.azCliProbes[tenantID] = azCliProbe{cred: cred, err: err}
+
+	return cred, err
+}
+
+func (m *Manager) newAzCliCredential(tenantID string) (azcore.TokenCredential, error) {
+	if m.azCliCredentialFactory != nil {
+		return m.azCliCredentialFactory(tenantID)
+	}
+
+	return azidentity.NewAzureCLICredential(&azidentity.AzureCLICredentialOptions{
+		TenantID: tenantID,
+	})
+}
+
+// credentialFromAzdLogin returns a credential for the account logged in with `azd auth login`, or the ambient
+// credential of the current environment (e.g. CloudShell) when there is no such account.
+func (m *Manager) credentialFromAzdLogin(
+	ctx context.Context,
+	options *CredentialForCurrentUserOptions,
+) (azcore.TokenCredential, error) {
 	authConfig, err := m.readAuthConfig()
 	if err != nil {
 		return nil, fmt.Errorf("reading auth config: %w", err)
@@ -326,6 +435,9 @@ func (m *Manager) CredentialForCurrentUser(
 		} else if ps.FederatedAuth != nil && ps.FederatedAuth.TokenProvider != nil {
 			return m.newCredentialFromFederatedTokenProvider(
 				tenantID, *currentUser.ClientID, *ps.FederatedAuth.TokenProvider)
+		} else if ps.FederatedAuth != nil && ps.FederatedAuth.TokenFile != nil {
+			return m.newCredentialFromFederatedTokenFile(
+				tenantID, *currentUser.ClientID, *ps.FederatedAuth.TokenFile)
 		}
 	}
 
@@ -342,6 +454,33 @@ func shouldUseLegacyAuth(cfg config.Config) bool {
 	return false
 }
 
+// azCliCredentialPrecedence controls when the az CLI login is used as a credential source. See [cAzCliCredentialKey].
+type azCliCredentialPrecedence string
+
+const (
+	// The az CLI login is never used (the default).
+	azCliCredentialDisabled azCliCredentialPrecedence = "disabled"
+	// The az CLI login is tried before the account logged in with azd.
+	azCliCredentialPreferred azCliCredentialPrecedence = "preferred"
+	// The az CLI login is used when no account is logged in with azd.
+	azCliCredentialFallback azCliCredentialPrecedence = "fallback"
+)
+
+func readAzCliCredentialPrecedence(cfg config.Config) azCliCredentialPrecedence {
+	if val, has := cfg.Get(cAzCliCredentialKey); has {
+		if precedence, ok := val.(string); ok {
+			switch p := azCliCredentialPrecedence(strings.ToLower(precedence)); p {
+			case azCliCredentialPreferred, azCliCredentialFallback, azCliCredentialDisabled:
+				return p
+			}
+
+			log.Printf("ignoring unknown value '%s' for %s", precedence, cAzCliCredentialKey)
+		}
+	}
+
+	return azCliCredentialDisabled
+}
+
 func ShouldUseCloudShellAuth() bool {
 	if useCloudShellAuth, has := os.LookupEnv(cUseCloudShellAuthEnvVar); has {
 		if use, err := strconv.ParseBool(useCloudShellAuth); err == nil && use {
@@ -509,6 +648,51 @@ func (m *Manager) newCredentialFromFederatedTokenProvider(
 	return cred, nil
 }
 
+// newCredentialFromFederatedTokenFile creates a credential that exchanges the token stored in tokenFile for an access
+// token. The file is read each time a new assertion is needed, so tokens rotated on disk (as is done for Kubernetes
+// workload identity) are picked up without logging in again.
+func (m *Manager) newCredentialFromFederatedTokenFile(
+	tenantID string,
+	clientID string,
+	tokenFile string,
+) (azcore.TokenCredential, error) {
+	options := &azidentity.ClientAssertionCredentialOptions{
+		ClientOptions: policy.ClientOptions{
+			Transport: m.httpClient,
+			// TODO: Inject client options instead? this can be done if we're OK
+			// using the default user agent string.
+			Cloud: m.cloud.Configuration,
+		},
+	}
+	cred, err := azidentity.NewClientAssertionCredential(
+		tenantID,
+		clientID,
+		federatedTokenFromFile(tokenFile),
+		options)
+	if err != nil {
+		return nil, fmt.Errorf("creating credential: %w", err)
+	}
+
+	return cred, nil
+}
+
+// federatedTokenFromFile returns a function which reads the federated token from path. The file is read on every call.
+func federatedTokenFromFile(path string) func(context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("reading federated token file: %w", err)
+		}
+
+		token := strings.TrimSpace(string(content))
+		if token == "" {
+			return "", fmt.Errorf("federated token file '%s' is empty", path)
+		}
+
+		return token, nil
+	}
+}
+
 func (m *Manager) newCredentialFromCloudShell() (azcore.TokenCredential, error) {
 	return NewCloudShellCredential(m.httpClient), nil
 }
@@ -720,6 +904,40 @@ func (m *Manager) LoginWithServicePrincipalFederatedTokenProvider(
 	return cred, nil
 }
 
+// LoginWithServicePrincipalFederatedTokenFile logs in a service principal using a federated token read from tokenFile.
+// Only the path to the file is persisted, the token itself is read from the file whenever a new access token is needed.
+func (m *Manager) LoginWithServicePrincipalFederatedTokenFile(
+	ctx context.Context, tenantId, clientId, tokenFile string,
+) (azcore.TokenCredential, error) {
+	absTokenFile, err := filepath.Abs(tokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("resolving federated token file path: %w", err)
+	}
+
+	if _, err := federatedTokenFromFile(absTokenFile)(ctx); err != nil {
+		return nil, err
+	}
+
+	cred, err := m.newCredentialFromFederatedTokenFile(tenantId, clientId, absTokenFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.saveLoginForServicePrincipal(
+		tenantId,
+		clientId,
+		&persistedSecret{
+			FederatedAuth: &federatedAuth{
+				TokenFile: &absTokenFile,
+			},
+		},
+	); err != nil {
+		return nil, err
+	}
+
+	return cred, nil
+}
+
 // Logout signs out the current user and removes any cached authentication information
 func (m *Manager) Logout(ctx context.Context) error {
 	act, err := m.getSignedInAccount(ctx)
@@ -957,6 +1175,10 @@ type federatedTokenProvider string
 type federatedAuth struct {
 	// The auth token provider. Tokens are obtained by calling the provider as needed.
 	TokenProvider *federatedTokenProvider `json:"tokenProvider,omitempty"`
+
+	// The path to a file containing the federated token. The file is read each time a token is needed, which allows the
+	// token to be rotated on disk.
+	TokenFile *string `json:"tokenFile,omitempty"`
 }
 
 // userProperties is the model type for the value we store in the user's config. It is logically a discriminated union of
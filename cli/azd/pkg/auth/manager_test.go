@@ -10,10 +10,14 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	_ "embed"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/AzureAD/microsoft-authentication-library-for-go/apps/public"
@@ -21,6 +25,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/config"
 	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 	"github.com/azure/azure-dev/cli/azd/pkg/github"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/azure/azure-dev/cli/azd/test/mocks/mockinput"
 	"github.com/stretchr/testify/require"
@@ -170,6 +175,127 @@ func TestServicePrincipalLoginFederatedTokenProvider(t *testing.T) {
 	require.True(t, errors.Is(err, ErrNoCurrentUser))
 }
 
+func TestServicePrincipalLoginFederatedTokenFile(t *testing.T) {
+	credentialCache := &memoryCache{
+		cache: make(map[string][]byte),
+	}
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("first-token"), osutil.PermissionFile))
+
+	var assertions []string
+
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return strings.HasSuffix(request.URL.Path, "/discovery/instance")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{
+			"tenant_discovery_endpoint": "https://login.microsoftonline.com/testTenantId/v2.0/.well-known/openid-configuration",
+			"metadata": []map[string]any{
+				{
+					"preferred_network": "login.microsoftonline.com",
+					"preferred_cache":   "login.windows.net",
+					"aliases":           []string{"login.microsoftonline.com", "login.windows.net"},
+				},
+			},
+		})
+	})
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return strings.HasSuffix(request.URL.Path, "/.well-known/openid-configuration")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{
+			"authorization_endpoint": "https://login.microsoftonline.com/testTenantId/oauth2/v2.0/authorize",
+			"token_endpoint":         "https://login.microsoftonline.com/testTenantId/oauth2/v2.0/token",
+			"issuer":                 "https://login.microsoftonline.com/testTenantId/v2.0",
+		})
+	})
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return strings.HasSuffix(request.URL.Path, "/oauth2/v2.0/token")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		if err := request.ParseForm(); err != nil {
+			return nil, err
+		}
+
+		assertions = append(assertions, request.PostForm.Get("client_assertion"))
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{
+			"access_token": "sample-access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	})
+
+	m := Manager{
+		configManager:     newMemoryConfigManager(),
+		userConfigManager: newMemoryUserConfigManager(),
+		credentialCache:   credentialCache,
+		httpClient:        mockContext.HttpClient,
+		cloud:             cloud.AzurePublic(),
+	}
+
+	_, err := m.LoginWithServicePrincipalFederatedTokenFile(
+		context.Background(), "testTenantId", "testClientId", filepath.Join(t.TempDir(), "missing"),
+	)
+	require.Error(t, err)
+
+	cred, err := m.LoginWithServicePrincipalFederatedTokenFile(
+		context.Background(), "testTenantId", "testClientId", tokenFile,
+	)
+
+	require.NoError(t, err)
+	require.IsType(t, new(azidentity.ClientAssertionCredential), cred)
+
+	cred, err = m.CredentialForCurrentUser(context.Background(), nil)
+
+	require.NoError(t, err)
+	require.IsType(t, new(azidentity.ClientAssertionCredential), cred)
+
+	_, err = cred.GetToken(context.Background(), policy.TokenRequestOptions{
+		Scopes: []string{"https://management.azure.com//.default"},
+	})
+	require.NoError(t, err)
+
+	// Rotate the token on disk, the next token request should use the new value.
+	require.NoError(t, os.WriteFile(tokenFile, []byte("second-token\n"), osutil.PermissionFile))
+
+	_, err = cred.GetToken(context.Background(), policy.TokenRequestOptions{
+		Scopes: []string{"https://storage.azure.com//.default"},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"first-token", "second-token"}, assertions)
+
+	err = m.Logout(context.Background())
+
+	require.NoError(t, err)
+
+	_, err = m.CredentialForCurrentUser(context.Background(), nil)
+
+	require.True(t, errors.Is(err, ErrNoCurrentUser))
+}
+
+func TestFederatedTokenFromFile(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	getToken := federatedTokenFromFile(tokenFile)
+
+	_, err := getToken(context.Background())
+	require.Error(t, err)
+
+	require.NoError(t, os.WriteFile(tokenFile, []byte(""), osutil.PermissionFile))
+	_, err = getToken(context.Background())
+	require.Error(t, err)
+
+	require.NoError(t, os.WriteFile(tokenFile, []byte("first-token\n"), osutil.PermissionFile))
+	token, err := getToken(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "first-token", token)
+
+	require.NoError(t, os.WriteFile(tokenFile, []byte("second-token"), osutil.PermissionFile))
+	token, err = getToken(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "second-token", token)
+}
+
 func TestLegacyAzCliCredentialSupport(t *testing.T) {
 	mgr := newMemoryUserConfigManager()
 
@@ -192,6 +318,136 @@ func TestLegacyAzCliCredentialSupport(t *testing.T) {
 	require.IsType(t, new(azidentity.AzureCLICredential), cred)
 }
 
+func TestAzCliCredentialSource(t *testing.T) {
+	azCliCred := &fakeTokenCredential{token: "az-cli-token"}
+	failingAzCliCred := &fakeTokenCredential{err: errors.New("please run 'az login'")}
+
+	newManager := func(t *testing.T, precedence string, azCliCred azcore.TokenCredential) (*Manager, *int) {
+		mgr := newMemoryUserConfigManager()
+
+		cfg, err := mgr.Load()
+		require.NoError(t, err)
+		require.NoError(t, cfg.Set(cAzCliCredentialKey, precedence))
+		require.NoError(t, mgr.Save(cfg))
+
+		calls := 0
+		return &Manager{
+			configManager:     newMemoryConfigManager(),
+			userConfigManager: mgr,
+			credentialCache:   &memoryCache{cache: make(map[string][]byte)},
+			publicClient:      &mockPublicClient{},
+			cloud:             cloud.AzurePublic(),
+			azCliCredentialFactory: func(tenantID string) (azcore.TokenCredential, error) {
+				calls++
+				return azCliCred, nil
+			},
+		}, &calls
+	}
+
+	t.Run("FallbackWhenNotLoggedIn", func(t *testing.T) {
+		m, calls := newManager(t, "fallback", azCliCred)
+
+		cred, err := m.CredentialForCurrentUser(context.Background(), nil)
+		require.NoError(t, err)
+		require.Same(t, azCliCred, cred)
+		require.Equal(t, 1, *calls)
+	})
+
+	t.Run("FallbackNotUsedWhenLoggedIn", func(t *testing.T) {
+		m, calls := newManager(t, "fallback", azCliCred)
+
+		_, err := m.LoginInteractive(context.Background(), nil, nil)
+		require.NoError(t, err)
+
+		cred, err := m.CredentialForCurrentUser(context.Background(), nil)
+		require.NoError(t, err)
+		require.IsType(t, new(azdCredential), cred)
+		require.Equal(t, 0, *calls)
+	})
+
+	t.Run("FallbackFailure", func(t *testing.T) {
+		m, calls := newManager(t, "fallback", failingAzCliCred)
+
+		_, err := m.CredentialForCurrentUser(context.Background(), nil)
+		require.True(t, errors.Is(err, ErrNoCurrentUser))
+		require.Equal(t, 1, *calls)
+	})
+
+	t.Run("Preferred", func(t *testing.T) {
+		m, calls := newManager(t, "preferred", azCliCred)
+
+		_, err := m.LoginInteractive(context.Background(), nil, nil)
+		require.NoError(t, err)
+
+		cred, err := m.CredentialForCurrentUser(context.Background(), nil)
+		require.NoError(t, err)
+		require.Same(t, azCliCred, cred)
+		require.Equal(t, 1, *calls)
+	})
+
+	t.Run("PreferredFailureFallsThrough", func(t *testing.T) {
+		m, calls := newManager(t, "preferred", failingAzCliCred)
+
+		_, err := m.LoginInteractive(context.Background(), nil, nil)
+		require.NoError(t, err)
+
+		cred, err := m.CredentialForCurrentUser(context.Background(), nil)
+		require.NoError(t, err)
+		require.IsType(t, new(azdCredential), cred)
+		require.Equal(t, 1, *calls)
+	})
+
+	t.Run("ProbeCachedByTenant", func(t *testing.T) {
+		for _, cred := range []*fakeTokenCredential{azCliCred, failingAzCliCred} {
+			m, calls := newManager(t, "fallback", cred)
+
+			for i := 0; i < 2; i++ {
+				_, _ = m.CredentialForCurrentUser(context.Background(), nil)
+			}
+			require.Equal(t, 1, *calls)
+
+			// Each tenant is checked separately
+			_, _ = m.CredentialForCurrentUser(context.Background(), &CredentialForCurrentUserOptions{TenantID: "tenant"})
+			require.Equal(t, 2, *calls)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		m, calls := newManager(t, "disabled", azCliCred)
+
+		_, err := m.CredentialForCurrentUser(context.Background(), nil)
+		require.True(t, errors.Is(err, ErrNoCurrentUser))
+		require.Equal(t, 0, *calls)
+	})
+}
+
+func TestCredentialSourceOf(t *testing.T) {
+	azCliCred, err := azidentity.NewAzureCLICredential(nil)
+	require.NoError(t, err)
+
+	require.Equal(t, CredentialSourceAzCli, CredentialSourceOf(azCliCred))
+	require.Equal(t, CredentialSourceCloudShell, CredentialSourceOf(&CloudShellCredential{}))
+	require.Equal(t, CredentialSourceExternal, CredentialSourceOf(&RemoteCredential{}))
+	require.Equal(t, CredentialSourceAzd, CredentialSourceOf(&azdCredential{}))
+}
+
+// fakeTokenCredential implements azcore.TokenCredential and returns a fixed token or error.
+type fakeTokenCredential struct {
+	token string
+	err   error
+}
+
+func (c *fakeTokenCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	if c.err != nil {
+		return azcore.AccessToken{}, c.err
+	}
+
+	return azcore.AccessToken{
+		Token:     c.token,
+		ExpiresOn: time.Now().Add(time.Hour),
+	}, nil
+}
+
 func TestCloudShellCredentialSupport(t *testing.T) {
 	t.Setenv("AZD_IN_CLOUDSHELL", "1")
 	m := Manager{
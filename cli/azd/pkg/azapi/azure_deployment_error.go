@@ -28,10 +28,23 @@ func newErrorLine(code string, message string, inner []*DeploymentErrorLine) *De
 	}
 }
 
+// DeploymentResourceError is the error of a resource that failed to deploy.
+type DeploymentResourceError struct {
+	ResourceType string `json:"resourceType"`
+	ResourceName string `json:"resourceName"`
+	ResourceId   string `json:"resourceId,omitempty"`
+	Code         string `json:"code"`
+	Message      string `json:"message"`
+}
+
 type AzureDeploymentError struct {
 	Json string
 
 	Details *DeploymentErrorLine
+
+	// The resources that failed to deploy, when resolved from the operations of the deployment.
+	// When set, the error is rendered as a concise summary of the failed resources.
+	Resources []*DeploymentResourceError
 }
 
 func NewAzureDeploymentError(jsonErrorResponse string) *AzureDeploymentError {
@@ -48,6 +61,10 @@ func (e *AzureDeploymentError) init() {
 }
 
 func (e *AzureDeploymentError) Error() string {
+	if len(e.Resources) > 0 {
+		return e.resourcesSummary()
+	}
+
 	// Return the original error string if we can't parse the JSON
 	if e.Details == nil {
 		return e.Json
@@ -64,6 +81,26 @@ func (e *AzureDeploymentError) Error() string {
 	return sb.String()
 }
 
+// resourcesSummary renders the failed resources, one per line.
+func (e *AzureDeploymentError) resourcesSummary() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintln(output.WithErrorFormat("Deployment failed for the following resources:")))
+
+	for _, resource := range e.Resources {
+		line := fmt.Sprintf("- %s '%s'", resource.ResourceType, resource.ResourceName)
+		if resource.Code != "" {
+			line += fmt.Sprintf(": %s", resource.Code)
+		}
+		if resource.Message != "" {
+			line += fmt.Sprintf(": %s", resource.Message)
+		}
+
+		sb.WriteString(fmt.Sprintln(output.WithErrorFormat(line)))
+	}
+
+	return sb.String()
+}
+
 func generateErrorOutput(err *DeploymentErrorLine) []string {
 	lines := []string{}
 
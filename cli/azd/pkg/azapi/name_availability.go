@@ -0,0 +1,259 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/account"
+)
+
+// NameAvailability is the result of checking whether the name of a globally unique resource is available.
+type NameAvailability struct {
+	Available bool   `json:"nameAvailable"`
+	Reason    string `json:"reason,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+// nameAvailabilityApiVersions are the api versions of the name availability APIs of the resource types with a
+// globally unique name.
+var nameAvailabilityApiVersions = map[string]string{
+	"Microsoft.Storage/storageAccounts":       "2023-01-01",
+	"Microsoft.KeyVault/vaults":               "2023-07-01",
+	"Microsoft.ContainerRegistry/registries":  "2023-07-01",
+	"Microsoft.Web/sites":                     "2022-09-01",
+	"Microsoft.AppConfiguration/configStores": "2023-03-01",
+}
+
+// softDeletedResourcesPaths are the paths, relative to the subscription, listing the soft-deleted resources of the
+// resource types which names stay reserved once deleted until they are purged.
+var softDeletedResourcesPaths = map[string]string{
+	"Microsoft.KeyVault/vaults": "providers/Microsoft.KeyVault/deletedVaults?api-version=2023-07-01",
+	"Microsoft.AppConfiguration/configStores": "providers/Microsoft.AppConfiguration/deletedConfigurationStores" +
+		"?api-version=2023-03-01",
+}
+
+// ErrNameAvailabilityNotSupported is returned when checking the name availability of a resource type without a
+// globally unique name, or without a name availability API.
+var ErrNameAvailabilityNotSupported = fmt.Errorf("name availability check is not supported for the resource type")
+
+// NameAvailabilityService checks the availability of the names of globally unique resources, like storage accounts
+// and key vaults.
+type NameAvailabilityService interface {
+	CheckNameAvailability(
+		ctx context.Context,
+		subscriptionId string,
+		resourceType string,
+		name string,
+	) (*NameAvailability, error)
+	// FindResource returns the id of the resource of the subscription with the given type and name, or an empty string
+	// when the subscription has no such resource.
+	FindResource(ctx context.Context, subscriptionId string, resourceType string, name string) (string, error)
+	// IsSoftDeleted returns true when the name is held by a soft-deleted resource of the subscription, which can be
+	// recovered or purged.
+	IsSoftDeleted(ctx context.Context, subscriptionId string, resourceType string, name string) (bool, error)
+}
+
+func NewNameAvailabilityService(
+	credentialProvider account.SubscriptionCredentialProvider,
+	armClientOptions *arm.ClientOptions,
+) NameAvailabilityService {
+	return &nameAvailabilityService{
+		credentialProvider: credentialProvider,
+		armClientOptions:   armClientOptions,
+	}
+}
+
+type nameAvailabilityService struct {
+	credentialProvider account.SubscriptionCredentialProvider
+	armClientOptions   *arm.ClientOptions
+}
+
+// CheckNameAvailability checks whether the name is available for a resource of the given type, like
+// 'Microsoft.Storage/storageAccounts'.
+func (s *nameAvailabilityService) CheckNameAvailability(
+	ctx context.Context,
+	subscriptionId string,
+	resourceType string,
+	name string,
+) (*NameAvailability, error) {
+	apiVersion, has := nameAvailabilityApiVersions[resourceType]
+	if !has {
+		return nil, fmt.Errorf("%w: %s", ErrNameAvailabilityNotSupported, resourceType)
+	}
+
+	client, err := s.client(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace, _, _ := strings.Cut(resourceType, "/")
+	requestUrl := fmt.Sprintf(
+		"%s/subscriptions/%s/providers/%s/checkNameAvailability?api-version=%s",
+		strings.TrimSuffix(client.Endpoint(), "/"),
+		url.PathEscape(subscriptionId),
+		namespace,
+		apiVersion,
+	)
+
+	req, err := runtime.NewRequest(ctx, http.MethodPost, requestUrl)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	if err := runtime.MarshalAsJSON(req, map[string]string{"name": name, "type": resourceType}); err != nil {
+		return nil, fmt.Errorf("setting request body: %w", err)
+	}
+
+	response, err := client.Pipeline().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("checking name availability: %w", err)
+	}
+
+	if !runtime.HasStatusCode(response, http.StatusOK) {
+		return nil, runtime.NewResponseError(response)
+	}
+
+	var availability NameAvailability
+	if err := runtime.UnmarshalAsJSON(response, &availability); err != nil {
+		return nil, fmt.Errorf("reading name availability: %w", err)
+	}
+
+	return &availability, nil
+}
+
+// FindResource returns the id of the resource of the subscription with the given type and name, or an empty string when
+// the subscription has no such resource.
+func (s *nameAvailabilityService) FindResource(
+	ctx context.Context,
+	subscriptionId string,
+	resourceType string,
+	name string,
+) (string, error) {
+	client, err := s.client(ctx, subscriptionId)
+	if err != nil {
+		return "", err
+	}
+
+	filter := fmt.Sprintf("resourceType eq '%s' and name eq '%s'", resourceType, name)
+	requestUrl := fmt.Sprintf(
+		"%s/subscriptions/%s/resources?$filter=%s&api-version=2021-04-01",
+		strings.TrimSuffix(client.Endpoint(), "/"),
+		url.PathEscape(subscriptionId),
+		url.QueryEscape(filter),
+	)
+
+	var found string
+	err = s.listNames(ctx, client, requestUrl, func(id string, resourceName string) bool {
+		if strings.EqualFold(resourceName, name) {
+			found = id
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return "", fmt.Errorf("finding resource '%s': %w", name, err)
+	}
+
+	return found, nil
+}
+
+// IsSoftDeleted returns true when the name is held by a soft-deleted resource of the subscription, like a deleted key
+// vault which isn't purged yet. It returns false for resource types without soft-delete.
+func (s *nameAvailabilityService) IsSoftDeleted(
+	ctx context.Context,
+	subscriptionId string,
+	resourceType string,
+	name string,
+) (bool, error) {
+	path, has := softDeletedResourcesPaths[resourceType]
+	if !has {
+		return false, nil
+	}
+
+	client, err := s.client(ctx, subscriptionId)
+	if err != nil {
+		return false, err
+	}
+
+	requestUrl := fmt.Sprintf(
+		"%s/subscriptions/%s/%s", strings.TrimSuffix(client.Endpoint(), "/"), url.PathEscape(subscriptionId), path)
+
+	softDeleted := false
+	err = s.listNames(ctx, client, requestUrl, func(id string, resourceName string) bool {
+		softDeleted = strings.EqualFold(resourceName, name)
+		return softDeleted
+	})
+	if err != nil {
+		return false, fmt.Errorf("listing soft-deleted resources: %w", err)
+	}
+
+	return softDeleted, nil
+}
+
+func (s *nameAvailabilityService) client(ctx context.Context, subscriptionId string) (*arm.Client, error) {
+	credential, err := s.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := arm.NewClient("azd-name-availability", internal.Version, credential, s.armClientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("creating ARM client: %w", err)
+	}
+
+	return client, nil
+}
+
+// listNames calls visit with the id and name of each resource of a paged list, until visit returns true.
+func (s *nameAvailabilityService) listNames(
+	ctx context.Context,
+	client *arm.Client,
+	requestUrl string,
+	visit func(id string, name string) bool,
+) error {
+	for requestUrl != "" {
+		req, err := runtime.NewRequest(ctx, http.MethodGet, requestUrl)
+		if err != nil {
+			return fmt.Errorf("creating request: %w", err)
+		}
+
+		response, err := client.Pipeline().Do(req)
+		if err != nil {
+			return err
+		}
+
+		if !runtime.HasStatusCode(response, http.StatusOK) {
+			return runtime.NewResponseError(response)
+		}
+
+		var page struct {
+			Value []struct {
+				Id   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"value"`
+			NextLink string `json:"nextLink"`
+		}
+		if err := runtime.UnmarshalAsJSON(response, &page); err != nil {
+			return fmt.Errorf("reading resources: %w", err)
+		}
+
+		for _, resource := range page.Value {
+			if visit(resource.Id, resource.Name) {
+				return nil
+			}
+		}
+
+		requestUrl = page.NextLink
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/account"
+)
+
+// ResourceTypeLocationsService gets the locations where resource types are available, from the metadata of their
+// resource providers.
+type ResourceTypeLocationsService interface {
+	// ResourceTypeLocations returns the locations where the resource type, like 'Microsoft.Web/staticSites', is
+	// available. An empty list means the resource type isn't bound to a location, like global resources or extension
+	// resources.
+	ResourceTypeLocations(ctx context.Context, subscriptionId string, resourceType string) ([]string, error)
+}
+
+func NewResourceTypeLocationsService(
+	credentialProvider account.SubscriptionCredentialProvider,
+	armClientOptions *arm.ClientOptions,
+) ResourceTypeLocationsService {
+	return &resourceTypeLocationsService{
+		credentialProvider: credentialProvider,
+		armClientOptions:   armClientOptions,
+		providers:          map[string]map[string][]string{},
+	}
+}
+
+type resourceTypeLocationsService struct {
+	credentialProvider account.SubscriptionCredentialProvider
+	armClientOptions   *arm.ClientOptions
+
+	// providers caches the locations of the resource types of each provider namespace, by subscription
+	providers   map[string]map[string][]string
+	providersMu sync.Mutex
+}
+
+func (s *resourceTypeLocationsService) ResourceTypeLocations(
+	ctx context.Context,
+	subscriptionId string,
+	resourceType string,
+) ([]string, error) {
+	namespace, typeName, has := strings.Cut(resourceType, "/")
+	if !has {
+		return nil, fmt.Errorf("invalid resource type '%s'", resourceType)
+	}
+
+	resourceTypes, err := s.providerResourceTypes(ctx, subscriptionId, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	locations, has := resourceTypes[strings.ToLower(typeName)]
+	if !has {
+		return nil, fmt.Errorf("resource type '%s' is not registered by provider '%s'", typeName, namespace)
+	}
+
+	return locations, nil
+}
+
+// providerResourceTypes returns the locations of the resource types of the provider namespace, keyed by the lower
+// case name of the resource types. The metadata of each provider is only requested once.
+func (s *resourceTypeLocationsService) providerResourceTypes(
+	ctx context.Context,
+	subscriptionId string,
+	namespace string,
+) (map[string][]string, error) {
+	cacheKey := strings.ToLower(fmt.Sprintf("%s/%s", subscriptionId, namespace))
+
+	s.providersMu.Lock()
+	defer s.providersMu.Unlock()
+
+	if resourceTypes, has := s.providers[cacheKey]; has {
+		return resourceTypes, nil
+	}
+
+	credential, err := s.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := armresources.NewProvidersClient(subscriptionId, credential, s.armClientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("creating providers client: %w", err)
+	}
+
+	provider, err := client.Get(ctx, namespace, nil)
+	if err != nil {
+		return nil, fmt.Errorf("getting metadata of provider '%s': %w", namespace, err)
+	}
+
+	resourceTypes := map[string][]string{}
+	for _, resourceType := range provider.ResourceTypes {
+		if resourceType.ResourceType == nil {
+			continue
+		}
+
+		locations := make([]string, 0, len(resourceType.Locations))
+		for _, location := range resourceType.Locations {
+			if location != nil {
+				locations = append(locations, *location)
+			}
+		}
+
+		resourceTypes[strings.ToLower(*resourceType.ResourceType)] = locations
+	}
+
+	s.providers[cacheKey] = resourceTypes
+	return resourceTypes, nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/azure/azure-dev/cli/azd/pkg/artifacts"
 	"github.com/azure/azure-dev/cli/azd/pkg/azsdk/storage"
 	"github.com/azure/azure-dev/cli/azd/pkg/cosmosdb"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
@@ -47,6 +48,7 @@ func (p *DefaultPlatform) ConfigureContainer(container *ioc.NestedContainer) err
 	// Tools
 	container.MustRegisterSingleton(terraform.NewTerraformCli)
 	container.MustRegisterSingleton(bicep.NewBicepCli)
+	container.MustRegisterSingleton(infraBicep.NewModuleRegistryClient)
 
 	// Provisioning Providers
 	provisionProviderMap := map[provisioning.ProviderKind]any{
@@ -103,6 +105,16 @@ func (p *DefaultPlatform) ConfigureContainer(container *ioc.NestedContainer) err
 		return storageAccountConfig, nil
 	})
 
+	// Artifact stores for packaged services
+	artifactStoreMap := map[artifacts.StoreKind]any{
+		artifacts.StoreKindAzureBlobStorage: artifacts.NewBlobStore,
+		artifacts.StoreKindHttp:             artifacts.NewHttpStore,
+	}
+
+	for storeKind, constructor := range artifactStoreMap {
+		container.MustRegisterNamedScoped(string(storeKind), constructor)
+	}
+
 	// Storage components
 	container.MustRegisterSingleton(storage.NewBlobClient)
 	container.MustRegisterSingleton(storage.NewBlobSdkClient)
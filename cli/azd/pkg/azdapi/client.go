@@ -0,0 +1,321 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package azdapi runs azd operations in-process, for tools that embed azd instead of invoking the CLI.
+//
+// A Client is bound to a project directory and an environment. Each operation runs the same actions as the matching
+// azd command, through the same middleware, like hooks and telemetry, without prompting, writing console output to the
+// configured writers and returning structured results.
+// The actions are provided by the Runtime registered by the cmd package, which embedders import for its side effects:
+//
+//	import _ "github.com/azure/azure-dev/cli/azd/cmd"
+//
+//	client, err := azdapi.NewClient(ctx, azdapi.ClientOptions{
+//		Cwd:                "/path/to/project",
+//		EnvironmentName:    "dev",
+//		CredentialProvider: credentialProvider,
+//		Stdout:             os.Stdout,
+//	})
+//	if err != nil {
+//		return err
+//	}
+//
+//	provisionResult, err := client.Provision(ctx)
+//	if err != nil {
+//		return err
+//	}
+//
+//	deployResult, err := client.Deploy(ctx, azdapi.DeployOptions{})
+package azdapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/auth"
+	"github.com/azure/azure-dev/cli/azd/pkg/cleanup"
+	"github.com/azure/azure-dev/cli/azd/pkg/contracts"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/ioc"
+	"github.com/azure/azure-dev/cli/azd/pkg/lazy"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/warnings"
+	"github.com/mattn/go-colorable"
+)
+
+// ClientOptions configures a Client.
+type ClientOptions struct {
+	// The directory of the project, containing azure.yaml. Required.
+	Cwd string
+	// The name of the environment operations run against. Defaults to the default environment of the project.
+	EnvironmentName string
+	// Values set on the environment before each operation runs. They are saved with the environment.
+	EnvironmentValues map[string]string
+	// The credentials used for Azure requests. Defaults to the credentials of the account logged in with azd.
+	CredentialProvider auth.MultiTenantCredentialProvider
+	// The client used for HTTP requests. Defaults to the azd HTTP client.
+	HttpClient httputil.HttpClient
+	// Receives the console output of operations. Discarded when nil.
+	Stdout io.Writer
+	// Receives the error output of operations. Discarded when nil.
+	Stderr io.Writer
+	// Receives the structured progress of operations, instead of rendering it to Stdout. Use
+	// project.NewChannelProgressReporter or project.ProgressReporterFunc to receive the events.
+	Progress project.ProgressReporter
+	// Additional provisioning providers, keyed by the provider name used in the 'infra' section of azure.yaml.
+	// Each value is a constructor returning a provisioning.Provider, with its dependencies injected.
+	ProvisioningProviders map[provisioning.ProviderKind]any
+}
+
+// DeployOptions configures a deployment.
+type DeployOptions struct {
+	// The service to deploy. All services are deployed when empty.
+	ServiceName string
+}
+
+// ProvisionResult is the result of a successful provisioning.
+type ProvisionResult struct {
+	// The outputs and resources of the deployment. Empty when provisioning was skipped because nothing changed.
+	contracts.EnvRefreshResult
+	// The values of the environment once provisioned.
+	Environment map[string]string `json:"environment"`
+	// The time taken to provision.
+	Duration time.Duration `json:"duration"`
+	// The warnings raised while provisioning.
+	Warnings []warnings.Warning `json:"warnings,omitempty"`
+}
+
+// DeployResult is the result of a successful deployment.
+type DeployResult struct {
+	// The result of each deployed service, keyed by service name.
+	Services map[string]*project.ServiceDeployResult `json:"services"`
+	// The time taken to deploy all the services.
+	Duration time.Duration `json:"duration"`
+	// The warnings raised while deploying, like deprecated options or slow docker builds.
+	Warnings []warnings.Warning `json:"warnings,omitempty"`
+}
+
+// Runtime composes the services of azd and runs the actions of its commands for a Client. The cmd package registers
+// the runtime of the CLI when imported.
+type Runtime interface {
+	// RegisterServices registers the services used when running the CLI in the root container of a client.
+	RegisterServices(rootContainer *ioc.NestedContainer)
+	// Provision runs the action of 'azd provision' with the services of the container.
+	Provision(ctx context.Context, container *ioc.NestedContainer) (*ProvisionResult, error)
+	// Deploy runs the action of 'azd deploy' with the services of the container.
+	Deploy(ctx context.Context, container *ioc.NestedContainer, options DeployOptions) (*DeployResult, error)
+}
+
+var (
+	runtimeMu sync.RWMutex
+	runtime   Runtime
+)
+
+// RegisterRuntime sets the runtime used by the clients created afterwards.
+func RegisterRuntime(r Runtime) {
+	runtimeMu.Lock()
+	defer runtimeMu.Unlock()
+
+	runtime = r
+}
+
+// Client runs azd operations in-process.
+type Client struct {
+	options       ClientOptions
+	runtime       Runtime
+	rootContainer *ioc.NestedContainer
+}
+
+// NewClient creates a Client for the project in options.Cwd.
+func NewClient(ctx context.Context, options ClientOptions) (*Client, error) {
+	if options.Cwd == "" {
+		return nil, errors.New("a project directory is required")
+	}
+
+	runtimeMu.RLock()
+	r := runtime
+	runtimeMu.RUnlock()
+
+	if r == nil {
+		return nil, errors.New(
+			"no azd runtime is registered, import github.com/azure/azure-dev/cli/azd/cmd to register it")
+	}
+
+	if options.Stdout == nil {
+		options.Stdout = io.Discard
+	}
+
+	if options.Stderr == nil {
+		options.Stderr = io.Discard
+	}
+
+	// The runtime composes the same registrations used when running the CLI.
+	rootContainer := ioc.NewNestedContainer(nil)
+	ioc.RegisterInstance(rootContainer, ctx)
+	r.RegisterServices(rootContainer)
+
+	return &Client{
+		options:       options,
+		runtime:       r,
+		rootContainer: rootContainer,
+	}, nil
+}
+
+// Provision provisions the Azure resources of the project, like 'azd provision'.
+func (c *Client) Provision(ctx context.Context) (*ProvisionResult, error) {
+	ctx, cleanupDone := cleanup.WithCancelCleanup(ctx)
+	defer cleanupDone()
+
+	container, err := c.newContainer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *ProvisionResult
+	env, raised, err := c.run(ctx, container, func(ctx context.Context) error {
+		result, err = c.runtime.Provision(ctx, container)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Environment = env.Dotenv()
+	result.Warnings = raised
+
+	return result, nil
+}
+
+// Deploy deploys the services of the project, like 'azd deploy'.
+func (c *Client) Deploy(ctx context.Context, options DeployOptions) (*DeployResult, error) {
+	ctx, cleanupDone := cleanup.WithCancelCleanup(ctx)
+	defer cleanupDone()
+
+	container, err := c.newContainer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *DeployResult
+	_, raised, err := c.run(ctx, container, func(ctx context.Context) error {
+		result, err = c.runtime.Deploy(ctx, container, options)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Warnings = raised
+
+	return result, nil
+}
+
+// run applies the environment values of the client and runs the operation, returning the environment it ran against
+// and the warnings raised while it ran.
+func (c *Client) run(
+	ctx context.Context,
+	container *ioc.NestedContainer,
+	operation func(ctx context.Context) error,
+) (*environment.Environment, []warnings.Warning, error) {
+	var env *environment.Environment
+	if err := container.Resolve(&env); err != nil {
+		return nil, nil, err
+	}
+
+	if len(c.options.EnvironmentValues) > 0 {
+		for key, value := range c.options.EnvironmentValues {
+			env.DotenvSet(key, value)
+		}
+
+		// The hooks of the operation reload the environment before they run, which would drop unsaved values
+		var envManager environment.Manager
+		if err := container.Resolve(&envManager); err != nil {
+			return nil, nil, err
+		}
+
+		if err := envManager.Save(ctx, env); err != nil {
+			return nil, nil, fmt.Errorf("saving environment values: %w", err)
+		}
+	}
+
+	sink := warnings.NewSink()
+	if err := operation(warnings.WithSink(ctx, sink)); err != nil {
+		return nil, nil, err
+	}
+
+	return env, sink.Warnings(), nil
+}
+
+// newContainer creates the container of a single operation, with the console, project, credentials and providers of
+// the client.
+func (c *Client) newContainer(ctx context.Context) (*ioc.NestedContainer, error) {
+	container, err := c.rootContainer.NewScopeRegistrationsOnly()
+	if err != nil {
+		return nil, err
+	}
+
+	azdCtx := azdcontext.NewAzdContextWithDirectory(c.options.Cwd)
+	globalOptions := c.globalOptions()
+	envFlag := c.envFlag()
+
+	ioc.RegisterInstance(container, ctx)
+	ioc.RegisterInstance(container, globalOptions)
+	ioc.RegisterInstance(container, *envFlag)
+	ioc.RegisterInstance(container, azdCtx)
+	ioc.RegisterInstance(container, lazy.From(azdCtx))
+	ioc.RegisterInstance[output.Formatter](container, &output.JsonFormatter{})
+
+	container.MustRegisterSingleton(func() input.Console {
+		return input.NewConsole(true, false, input.Writers{
+			Output: colorable.NewNonColorable(c.options.Stdout),
+		}, input.ConsoleHandles{
+			Stdin:  strings.NewReader(""),
+			Stdout: c.options.Stdout,
+			Stderr: c.options.Stderr,
+		}, &output.NoneFormatter{}, nil)
+	})
+
+	if c.options.CredentialProvider != nil {
+		ioc.RegisterInstance(container, c.options.CredentialProvider)
+	}
+
+	if c.options.HttpClient != nil {
+		ioc.RegisterInstance(container, c.options.HttpClient)
+	}
+
+	if c.options.Progress != nil {
+		ioc.RegisterInstance(container, c.options.Progress)
+	}
+
+	for kind, constructor := range c.options.ProvisioningProviders {
+		if err := container.RegisterNamedTransient(string(kind), constructor); err != nil {
+			return nil, fmt.Errorf("registering provisioning provider '%s': %w", kind, err)
+		}
+	}
+
+	return container, nil
+}
+
+func (c *Client) envFlag() *internal.EnvFlag {
+	return &internal.EnvFlag{
+		EnvironmentName: c.options.EnvironmentName,
+	}
+}
+
+func (c *Client) globalOptions() *internal.GlobalCommandOptions {
+	return &internal.GlobalCommandOptions{
+		Cwd:      c.options.Cwd,
+		NoPrompt: true,
+	}
+}
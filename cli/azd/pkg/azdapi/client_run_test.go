@@ -0,0 +1,45 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azdapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/ioc"
+	"github.com/azure/azure-dev/cli/azd/pkg/warnings"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockenv"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_RunReturnsWarnings(t *testing.T) {
+	client := &Client{
+		options: ClientOptions{EnvironmentValues: map[string]string{"KEY": "value"}},
+	}
+
+	env := environment.New("dev")
+	envManager := &mockenv.MockEnvManager{}
+	envManager.On("Save", mock.Anything, env).Return(nil)
+
+	container := ioc.NewNestedContainer(nil)
+	ioc.RegisterInstance(container, env)
+	ioc.RegisterInstance[environment.Manager](container, envManager)
+
+	// A fake operation raising two warnings while it runs
+	ranEnv, raised, err := client.run(context.Background(), container, func(ctx context.Context) error {
+		warnings.Add(ctx, warnings.Warning{Code: warnings.CodeDeprecatedFlag, Message: "first"})
+		warnings.Add(ctx, warnings.Warning{Code: warnings.CodeDockerignore, Message: "second"})
+		return nil
+	})
+	require.NoError(t, err)
+	require.Same(t, env, ranEnv)
+	require.Equal(t, "value", env.Getenv("KEY"))
+	envManager.AssertCalled(t, "Save", mock.Anything, env)
+	require.Equal(t, []warnings.Warning{
+		{Code: warnings.CodeDeprecatedFlag, Message: "first"},
+		{Code: warnings.CodeDockerignore, Message: "second"},
+	}, raised)
+}
@@ -0,0 +1,177 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azdapi_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	_ "github.com/azure/azure-dev/cli/azd/cmd"
+	"github.com/azure/azure-dev/cli/azd/internal/runcontext"
+	. "github.com/azure/azure-dev/cli/azd/pkg/azdapi"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning/test"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockhttp"
+	"github.com/stretchr/testify/require"
+)
+
+const testProject = `name: inproc
+infra:
+  provider: test
+`
+
+func newTestProject(t *testing.T) string {
+	t.Setenv("AZD_CONFIG_DIR", t.TempDir())
+	// The operations run the middleware of the commands, which skips the variant assignments in offline mode
+	t.Setenv(runcontext.OfflineEnvVarName, "true")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(
+		filepath.Join(dir, azdcontext.ProjectFileName), []byte(testProject), osutil.PermissionFile))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "infra"), osutil.PermissionDirectory))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "infra", "main.tf"), []byte{}, osutil.PermissionFile))
+
+	return dir
+}
+
+func Test_Client_Provision(t *testing.T) {
+	dir := newTestProject(t)
+	ctx := context.Background()
+
+	var stdout bytes.Buffer
+	client, err := NewClient(ctx, ClientOptions{
+		Cwd:             dir,
+		EnvironmentName: "dev",
+		EnvironmentValues: map[string]string{
+			environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+			environment.LocationEnvVarName:       "eastus2",
+		},
+		CredentialProvider: &mocks.MockMultiTenantCredentialProvider{},
+		HttpClient:         mockhttp.NewMockHttpUtil(),
+		Stdout:             &stdout,
+		ProvisioningProviders: map[provisioning.ProviderKind]any{
+			provisioning.Test: test.NewTestProvider,
+		},
+	})
+	require.NoError(t, err)
+
+	result, err := client.Provision(ctx)
+	require.NoError(t, err)
+
+	require.Equal(t, "SUBSCRIPTION_ID", result.Environment[environment.SubscriptionIdEnvVarName])
+	require.Equal(t, "dev", result.Environment[environment.EnvNameEnvVarName])
+	require.Empty(t, result.Outputs)
+	require.Positive(t, result.Duration)
+	require.Contains(t, stdout.String(), "Provisioning Azure resources")
+
+	// The environment is saved in the project like when running 'azd provision'
+	envFile, err := os.ReadFile(filepath.Join(dir, azdcontext.EnvironmentDirectoryName, "dev", ".env"))
+	require.NoError(t, err)
+	require.Contains(t, string(envFile), `AZURE_LOCATION="eastus2"`)
+}
+
+func Test_Client_ProvisionUnknownProvider(t *testing.T) {
+	dir := newTestProject(t)
+	ctx := context.Background()
+
+	client, err := NewClient(ctx, ClientOptions{
+		Cwd:             dir,
+		EnvironmentName: "dev",
+		EnvironmentValues: map[string]string{
+			environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+			environment.LocationEnvVarName:       "eastus2",
+		},
+		CredentialProvider: &mocks.MockMultiTenantCredentialProvider{},
+		HttpClient:         mockhttp.NewMockHttpUtil(),
+	})
+	require.NoError(t, err)
+
+	// The error of the operation is returned as-is rather than as a process exit code
+	_, err = client.Provision(ctx)
+	require.ErrorContains(t, err, "failed resolving IaC provider 'test'")
+}
+
+func Test_NewClient_RequiresProjectDirectory(t *testing.T) {
+	_, err := NewClient(context.Background(), ClientOptions{})
+	require.Error(t, err)
+}
+
+func Test_Client_Deploy(t *testing.T) {
+	dir := newTestProject(t)
+	ctx := context.Background()
+
+	client, err := NewClient(ctx, ClientOptions{
+		Cwd:             dir,
+		EnvironmentName: "dev",
+		EnvironmentValues: map[string]string{
+			environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+			environment.LocationEnvVarName:       "eastus2",
+		},
+		CredentialProvider: &mocks.MockMultiTenantCredentialProvider{},
+		HttpClient:         mockhttp.NewMockHttpUtil(),
+	})
+	require.NoError(t, err)
+
+	t.Run("AllServices", func(t *testing.T) {
+		result, err := client.Deploy(ctx, DeployOptions{})
+		require.NoError(t, err)
+		require.Empty(t, result.Services)
+	})
+
+	t.Run("UnknownService", func(t *testing.T) {
+		_, err := client.Deploy(ctx, DeployOptions{ServiceName: "api"})
+		require.EqualError(t, err, "service name 'api' doesn't exist")
+	})
+}
+
+func Test_Client_ProvisionRunsHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hooks run with sh")
+	}
+
+	dir := newTestProject(t)
+	ctx := context.Background()
+
+	// The operations run the middleware of the commands, like the hooks of 'azd provision'
+	project := testProject + `hooks:
+  preprovision:
+    shell: sh
+    run: echo preprovision >> hooks.log
+  postprovision:
+    shell: sh
+    run: echo postprovision >> hooks.log
+`
+	require.NoError(t, os.WriteFile(
+		filepath.Join(dir, azdcontext.ProjectFileName), []byte(project), osutil.PermissionFile))
+
+	client, err := NewClient(ctx, ClientOptions{
+		Cwd:             dir,
+		EnvironmentName: "dev",
+		EnvironmentValues: map[string]string{
+			environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+			environment.LocationEnvVarName:       "eastus2",
+		},
+		CredentialProvider: &mocks.MockMultiTenantCredentialProvider{},
+		HttpClient:         mockhttp.NewMockHttpUtil(),
+		ProvisioningProviders: map[provisioning.ProviderKind]any{
+			provisioning.Test: test.NewTestProvider,
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = client.Provision(ctx)
+	require.NoError(t, err)
+
+	hooksLog, err := os.ReadFile(filepath.Join(dir, "hooks.log"))
+	require.NoError(t, err)
+	require.Equal(t, "preprovision\npostprovision\n", string(hooksLog))
+}
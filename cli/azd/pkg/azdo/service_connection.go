@@ -82,7 +82,7 @@ func CreateServiceConnection(
 	ctx context.Context,
 	connection *azuredevops.Connection,
 	projectId string,
-	azdEnvironment environment.Environment,
+	azdEnvironment *environment.Environment,
 	credentials *azcli.AzureCredentials,
 	console input.Console) error {
 
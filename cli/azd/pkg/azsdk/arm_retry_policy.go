@@ -0,0 +1,317 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azsdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// RetryClass is how the ARM retry policy handles a failed request.
+type RetryClass int
+
+const (
+	// RetryClassNone doesn't retry the request, like for an invalid template.
+	RetryClassNone RetryClass = iota
+	// RetryClassTransient retries the request with an exponential backoff, like for a server error or throttling.
+	RetryClassTransient
+	// RetryClassConflict retries the request after the longer ConflictRetryDelay, like when another operation on the
+	// resource is in progress or the resource is being deleted.
+	RetryClassConflict
+)
+
+// ArmRetryOptions configures the ARM retry policy.
+type ArmRetryOptions struct {
+	// MaxRetries is the number of times a request is retried, where a negative value disables the retries. Defaults
+	// to 3.
+	MaxRetries int
+	// RetryDelay is the initial delay of the exponential backoff of transient errors. Defaults to 800 milliseconds.
+	RetryDelay time.Duration
+	// ConflictRetryDelay is the delay before retrying a conflict. Defaults to 10 seconds.
+	ConflictRetryDelay time.Duration
+	// MaxRetryDelay caps the delay between retries. Requests whose Retry-After header asks for a longer delay aren't
+	// retried. Defaults to 60 seconds.
+	MaxRetryDelay time.Duration
+	// ErrorCodes overrides the class of ARM error codes, like 'AnotherOperationInProgress'.
+	ErrorCodes map[string]RetryClass
+}
+
+const (
+	defaultArmMaxRetries         = 3
+	defaultArmRetryDelay         = 800 * time.Millisecond
+	defaultArmConflictRetryDelay = 10 * time.Second
+	defaultArmMaxRetryDelay      = 60 * time.Second
+)
+
+// armErrorCodeClasses is the class of the common ARM error codes. Errors with other codes are classified by their
+// status code.
+var armErrorCodeClasses = map[string]RetryClass{
+	"AnotherOperationInProgress": RetryClassConflict,
+	"ResourceGroupBeingDeleted":  RetryClassConflict,
+	"OperationPreempted":         RetryClassConflict,
+	"RetryableError":             RetryClassTransient,
+	"InternalServerError":        RetryClassTransient,
+	"ServerTimeout":              RetryClassTransient,
+	"GatewayTimeout":             RetryClassTransient,
+	"ServiceUnavailable":         RetryClassTransient,
+}
+
+// statusCodeClasses is the class of the status codes of failed requests whose error code isn't classified.
+var statusCodeClasses = map[int]RetryClass{
+	http.StatusRequestTimeout:      RetryClassTransient,
+	http.StatusTooManyRequests:     RetryClassTransient,
+	http.StatusInternalServerError: RetryClassTransient,
+	http.StatusBadGateway:          RetryClassTransient,
+	http.StatusServiceUnavailable:  RetryClassTransient,
+	http.StatusGatewayTimeout:      RetryClassTransient,
+}
+
+// armRetryPolicy retries the requests failing with transient ARM errors, with a backoff tailored to the error.
+type armRetryPolicy struct {
+	options ArmRetryOptions
+	// sleep waits for the delay before a retry, or until ctx is done.
+	sleep func(ctx context.Context, delay time.Duration) error
+	// jitter randomizes the delay before a retry, so concurrent clients don't retry at once.
+	jitter func(delay time.Duration) time.Duration
+}
+
+// NewArmRetryPolicy creates a policy classifying the failures of ARM requests by their error code:
+//   - conflicts, like another operation in progress on the resource, are retried after a longer delay.
+//   - transient errors, like throttling or server errors, are retried with an exponential backoff.
+//   - other errors, like bad requests, aren't retried.
+//
+// The delays of the backoff are randomized by up to 20% and capped at MaxRetryDelay. The delay requested by the
+// Retry-After header of the response is honored instead, unless it exceeds MaxRetryDelay, in which case the request
+// isn't retried. The policy replaces the retry policy of the azcore pipeline, see ClientOptionsBuilder.WithRetryPolicy.
+func NewArmRetryPolicy(options *ArmRetryOptions) policy.Policy {
+	p := &armRetryPolicy{
+		options: ArmRetryOptions{
+			MaxRetries:         defaultArmMaxRetries,
+			RetryDelay:         defaultArmRetryDelay,
+			ConflictRetryDelay: defaultArmConflictRetryDelay,
+			MaxRetryDelay:      defaultArmMaxRetryDelay,
+		},
+		sleep:  sleep,
+		jitter: jitter,
+	}
+
+	if options != nil {
+		if options.MaxRetries != 0 {
+			p.options.MaxRetries = max(options.MaxRetries, 0)
+		}
+		if options.RetryDelay > 0 {
+			p.options.RetryDelay = options.RetryDelay
+		}
+		if options.ConflictRetryDelay > 0 {
+			p.options.ConflictRetryDelay = options.ConflictRetryDelay
+		}
+		if options.MaxRetryDelay > 0 {
+			p.options.MaxRetryDelay = options.MaxRetryDelay
+		}
+		p.options.ErrorCodes = options.ErrorCodes
+	}
+
+	return p
+}
+
+func (p *armRetryPolicy) Do(req *policy.Request) (*http.Response, error) {
+	ctx := req.Raw().Context()
+
+	var body *retryableRequestBody
+	if req.Body() != nil {
+		// The transport closes the request body once sent, which would prevent the retries from sending it again
+		body = &retryableRequestBody{body: req.Body()}
+		defer body.realClose()
+	}
+
+	for try := 1; ; try++ {
+		if err := req.RewindBody(); err != nil {
+			return nil, err
+		}
+		// RewindBody restores the original body, which must be wrapped again
+		if body != nil {
+			req.Raw().Body = body
+		}
+
+		res, err := req.Clone(ctx).Next()
+		if ctx.Err() != nil {
+			return res, err
+		}
+
+		class, errorCode := p.classify(res, err)
+		if class == RetryClassNone || try > p.options.MaxRetries {
+			return res, err
+		}
+
+		delay := p.delay(class, try)
+		if retryAfter := retryAfter(res); retryAfter > p.options.MaxRetryDelay {
+			log.Printf("not retrying request to '%s', Retry-After of %s exceeds %s",
+				req.Raw().URL.Path, retryAfter, p.options.MaxRetryDelay)
+			return res, err
+		} else if retryAfter > 0 {
+			delay = retryAfter
+		}
+
+		if res != nil {
+			log.Printf("retrying request to '%s' in %s after status %d (%s)",
+				req.Raw().URL.Path, delay, res.StatusCode, errorCode)
+			drain(res)
+		} else {
+			log.Printf("retrying request to '%s' in %s after error: %v", req.Raw().URL.Path, delay, err)
+		}
+
+		if err := p.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// classify returns the class of a failed request, with the ARM error code of the response.
+func (p *armRetryPolicy) classify(res *http.Response, err error) (RetryClass, string) {
+	if err != nil {
+		var nonRetriable interface{ NonRetriable() }
+		if errors.As(err, &nonRetriable) {
+			return RetryClassNone, ""
+		}
+
+		// Connection failures are transient
+		return RetryClassTransient, ""
+	}
+
+	if res.StatusCode < http.StatusBadRequest {
+		return RetryClassNone, ""
+	}
+
+	errorCode := armErrorCode(res)
+	if class, has := p.options.ErrorCodes[errorCode]; has {
+		return class, errorCode
+	}
+	if class, has := armErrorCodeClasses[errorCode]; has {
+		return class, errorCode
+	}
+
+	return statusCodeClasses[res.StatusCode], errorCode
+}
+
+// delay returns the delay before the retry following the given try.
+func (p *armRetryPolicy) delay(class RetryClass, try int) time.Duration {
+	delay := p.options.ConflictRetryDelay
+	if class != RetryClassConflict {
+		// Exponential backoff: (2^try - 1) * delay
+		delay = time.Duration((1<<min(try, 16))-1) * p.options.RetryDelay
+	}
+
+	return min(p.jitter(delay), p.options.MaxRetryDelay)
+}
+
+// jitter returns a random delay between 80% and 120% of delay.
+func jitter(delay time.Duration) time.Duration {
+	return time.Duration(float64(delay) * (0.8 + 0.4*rand.Float64()))
+}
+
+// armErrorCode returns the code of the ARM error in the body of res, like 'AnotherOperationInProgress', or an empty
+// string when the body isn't an ARM error. The body of res is restored so it can be read again.
+func armErrorCode(res *http.Response) string {
+	if res.Body == nil {
+		return ""
+	}
+
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+
+	var armError struct {
+		Code  string `json:"code"`
+		Error *struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &armError); err != nil {
+		return ""
+	}
+
+	if armError.Error != nil {
+		return armError.Error.Code
+	}
+
+	return armError.Code
+}
+
+// retryAfter returns the delay requested by the retry-after-ms, x-ms-retry-after-ms or Retry-After headers of res, or
+// 0 when there's none.
+func retryAfter(res *http.Response) time.Duration {
+	if res == nil {
+		return 0
+	}
+
+	for _, header := range []string{"retry-after-ms", "x-ms-retry-after-ms"} {
+		if ms, err := strconv.Atoi(res.Header.Get(header)); err == nil && ms > 0 {
+			return time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	value := res.Header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+
+	return 0
+}
+
+// drain reads and closes the body of res so the connection can be reused.
+func drain(res *http.Response) {
+	if res.Body != nil {
+		_, _ = io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+	}
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryableRequestBody is a request body which isn't closed by the transport, so it can be sent again by retries.
+type retryableRequestBody struct {
+	body io.ReadSeekCloser
+}
+
+func (b *retryableRequestBody) Read(p []byte) (int, error) {
+	return b.body.Read(p)
+}
+
+func (b *retryableRequestBody) Seek(offset int64, whence int) (int64, error) {
+	return b.body.Seek(offset, whence)
+}
+
+func (b *retryableRequestBody) Close() error {
+	return nil
+}
+
+func (b *retryableRequestBody) realClose() error {
+	return b.body.Close()
+}
@@ -0,0 +1,184 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azsdk
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockhttp"
+	"github.com/stretchr/testify/require"
+)
+
+type armRetryResponse struct {
+	status     int
+	code       string
+	retryAfter string
+}
+
+// newArmRetryClient creates a client whose requests receive the responses in order, followed by a success. The returned
+// values record the delays of the retries and the number of requests sent.
+func newArmRetryClient(
+	t *testing.T,
+	options *ArmRetryOptions,
+	responses ...armRetryResponse,
+) (*armresources.Client, *[]time.Duration, *int) {
+	requests := 0
+	httpClient := mockhttp.NewMockHttpUtil()
+	httpClient.When(func(request *http.Request) bool {
+		return true
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		requests++
+		if requests > len(responses) {
+			return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armresources.GenericResource{})
+		}
+
+		response := responses[requests-1]
+		res, err := mocks.CreateHttpResponseWithBody(request, response.status, map[string]any{
+			"error": map[string]any{
+				"code":    response.code,
+				"message": "request failed",
+			},
+		})
+		if response.retryAfter != "" {
+			res.Header.Set("Retry-After", response.retryAfter)
+		}
+
+		return res, err
+	})
+
+	delays := []time.Duration{}
+	retryPolicy := NewArmRetryPolicy(options)
+	retryPolicy.(*armRetryPolicy).sleep = func(ctx context.Context, delay time.Duration) error {
+		delays = append(delays, delay)
+		return nil
+	}
+	retryPolicy.(*armRetryPolicy).jitter = func(delay time.Duration) time.Duration {
+		return delay
+	}
+
+	clientOptions := NewClientOptionsBuilder().
+		WithTransport(httpClient).
+		WithRetryPolicy(retryPolicy).
+		BuildArmClientOptions()
+
+	client, err := armresources.NewClient("SUBSCRIPTION_ID", &mocks.MockCredentials{}, clientOptions)
+	require.NoError(t, err)
+
+	return client, &delays, &requests
+}
+
+func Test_ArmRetryPolicy(t *testing.T) {
+	t.Run("ConflictRetriedWithDelay", func(t *testing.T) {
+		client, delays, requests := newArmRetryClient(t, &ArmRetryOptions{ConflictRetryDelay: 20 * time.Second},
+			armRetryResponse{status: http.StatusConflict, code: "AnotherOperationInProgress"},
+			armRetryResponse{status: http.StatusConflict, code: "ResourceGroupBeingDeleted"},
+		)
+
+		_, err := client.GetByID(context.Background(), "RESOURCE_ID", "2021-04-01", nil)
+		require.NoError(t, err)
+		require.Equal(t, 3, *requests)
+		require.Equal(t, []time.Duration{20 * time.Second, 20 * time.Second}, *delays)
+	})
+
+	t.Run("ConflictNotRetried", func(t *testing.T) {
+		client, delays, requests := newArmRetryClient(t, nil,
+			armRetryResponse{status: http.StatusConflict, code: "InvalidResourceLocation"},
+		)
+
+		_, err := client.GetByID(context.Background(), "RESOURCE_ID", "2021-04-01", nil)
+
+		var respErr *azcore.ResponseError
+		require.ErrorAs(t, err, &respErr)
+		require.Equal(t, "InvalidResourceLocation", respErr.ErrorCode)
+		require.Equal(t, 1, *requests)
+		require.Empty(t, *delays)
+	})
+
+	t.Run("BadRequestNotRetried", func(t *testing.T) {
+		client, delays, requests := newArmRetryClient(t, nil,
+			armRetryResponse{status: http.StatusBadRequest, code: "InvalidTemplate"},
+		)
+
+		_, err := client.GetByID(context.Background(), "RESOURCE_ID", "2021-04-01", nil)
+
+		var respErr *azcore.ResponseError
+		require.ErrorAs(t, err, &respErr)
+		require.Equal(t, http.StatusBadRequest, respErr.StatusCode)
+		require.Equal(t, 1, *requests)
+		require.Empty(t, *delays)
+	})
+
+	t.Run("ThrottlingHonorsRetryAfter", func(t *testing.T) {
+		client, delays, requests := newArmRetryClient(t, nil,
+			armRetryResponse{status: http.StatusTooManyRequests, code: "TooManyRequests", retryAfter: "17"},
+			armRetryResponse{status: http.StatusTooManyRequests, code: "TooManyRequests"},
+		)
+
+		_, err := client.GetByID(context.Background(), "RESOURCE_ID", "2021-04-01", nil)
+		require.NoError(t, err)
+		require.Equal(t, 3, *requests)
+		// The second response has no Retry-After, falling back to the exponential backoff
+		require.Equal(t, []time.Duration{17 * time.Second, 3 * defaultArmRetryDelay}, *delays)
+	})
+
+	t.Run("RetryAfterExceedsMaxRetryDelay", func(t *testing.T) {
+		client, delays, requests := newArmRetryClient(t, &ArmRetryOptions{MaxRetryDelay: 10 * time.Second},
+			armRetryResponse{status: http.StatusTooManyRequests, code: "TooManyRequests", retryAfter: "600"},
+		)
+
+		_, err := client.GetByID(context.Background(), "RESOURCE_ID", "2021-04-01", nil)
+		require.Error(t, err)
+		require.Equal(t, 1, *requests)
+		require.Empty(t, *delays)
+	})
+
+	t.Run("MaxRetries", func(t *testing.T) {
+		client, delays, requests := newArmRetryClient(t, &ArmRetryOptions{MaxRetries: 2},
+			armRetryResponse{status: http.StatusServiceUnavailable},
+			armRetryResponse{status: http.StatusServiceUnavailable},
+			armRetryResponse{status: http.StatusServiceUnavailable},
+		)
+
+		_, err := client.GetByID(context.Background(), "RESOURCE_ID", "2021-04-01", nil)
+		require.Error(t, err)
+		require.Equal(t, 3, *requests)
+		require.Equal(t, []time.Duration{defaultArmRetryDelay, 3 * defaultArmRetryDelay}, *delays)
+	})
+
+	t.Run("ErrorCodeOverride", func(t *testing.T) {
+		client, delays, requests := newArmRetryClient(t,
+			&ArmRetryOptions{
+				ErrorCodes: map[string]RetryClass{
+					"AnotherOperationInProgress": RetryClassNone,
+				},
+			},
+			armRetryResponse{status: http.StatusConflict, code: "AnotherOperationInProgress"},
+		)
+
+		_, err := client.GetByID(context.Background(), "RESOURCE_ID", "2021-04-01", nil)
+		require.Error(t, err)
+		require.Equal(t, 1, *requests)
+		require.Empty(t, *delays)
+	})
+}
+
+func Test_ArmRetryPolicy_Jitter(t *testing.T) {
+	retryPolicy := NewArmRetryPolicy(&ArmRetryOptions{MaxRetryDelay: 5 * time.Second}).(*armRetryPolicy)
+
+	for try := 1; try <= 3; try++ {
+		backoff := time.Duration((1<<try)-1) * defaultArmRetryDelay
+		delay := retryPolicy.delay(RetryClassTransient, try)
+		require.GreaterOrEqual(t, delay, backoff*8/10)
+		require.LessOrEqual(t, delay, backoff*12/10)
+	}
+
+	// The randomized delay is still capped
+	require.Equal(t, 5*time.Second, retryPolicy.delay(RetryClassConflict, 1))
+}
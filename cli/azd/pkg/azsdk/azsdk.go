@@ -24,9 +24,14 @@ func NewClientOptionsBuilderFactory(
 	}
 }
 
+// NewClientOptionsBuilder creates a ClientOptionsBuilder with the defaults shared by all the clients of azd. Requests
+// carry the trace id of the active span as their correlation id, so all the requests of an azd run can be correlated
+// server-side with its trace id.
 func (c *ClientOptionsBuilderFactory) NewClientOptionsBuilder() *ClientOptionsBuilder {
 	return NewClientOptionsBuilder().
 		WithTransport(c.defaultTransport).
 		WithPerCallPolicy(NewUserAgentPolicy(c.defaultUserAgent)).
+		WithPerCallPolicy(NewMsCorrelationPolicy()).
+		WithPerRetryPolicy(NewTraceLoggingPolicy()).
 		WithCloud(c.cloud.Configuration)
 }
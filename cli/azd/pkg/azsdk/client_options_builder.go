@@ -1,6 +1,8 @@
 package azsdk
 
 import (
+	"slices"
+
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
@@ -11,6 +13,7 @@ type ClientOptionsBuilder struct {
 	transport        policy.Transporter
 	perCallPolicies  []policy.Policy
 	perRetryPolicies []policy.Policy
+	retryPolicy      policy.Policy
 	cloud            cloud.Configuration
 }
 
@@ -36,6 +39,13 @@ func (b *ClientOptionsBuilder) WithPerRetryPolicy(policy policy.Policy) *ClientO
 	return b
 }
 
+// Sets the policy retrying failed requests, like NewArmRetryPolicy, in place of the default retry policy of the
+// HTTP pipeline
+func (b *ClientOptionsBuilder) WithRetryPolicy(policy policy.Policy) *ClientOptionsBuilder {
+	b.retryPolicy = policy
+	return b
+}
+
 func (b *ClientOptionsBuilder) WithCloud(cloud cloud.Configuration) *ClientOptionsBuilder {
 	b.cloud = cloud
 	return b
@@ -44,13 +54,16 @@ func (b *ClientOptionsBuilder) WithCloud(cloud cloud.Configuration) *ClientOptio
 // Builds the az core client options for data plane operations
 // These options include the underlying transport to be used.
 func (b *ClientOptionsBuilder) BuildCoreClientOptions() *azcore.ClientOptions {
+	perCallPolicies, retryOptions := b.retry()
+
 	return &azcore.ClientOptions{
 		// Supports mocking for unit tests
 		Transport: b.transport,
 		// Per request policies to inject into HTTP pipeline
-		PerCallPolicies: b.perCallPolicies,
+		PerCallPolicies: perCallPolicies,
 		// Per retry policies to inject into HTTP pipeline
 		PerRetryPolicies: b.perRetryPolicies,
+		Retry:            retryOptions,
 
 		Cloud: b.cloud,
 	}
@@ -59,14 +72,17 @@ func (b *ClientOptionsBuilder) BuildCoreClientOptions() *azcore.ClientOptions {
 // Builds the ARM module client options for control plane operations
 // These options include the underlying transport to be used.
 func (b *ClientOptionsBuilder) BuildArmClientOptions() *arm.ClientOptions {
+	perCallPolicies, retryOptions := b.retry()
+
 	return &arm.ClientOptions{
 		ClientOptions: policy.ClientOptions{
 			// Supports mocking for unit tests
 			Transport: b.transport,
 			// Per request policies to inject into HTTP pipeline
-			PerCallPolicies: b.perCallPolicies,
+			PerCallPolicies: perCallPolicies,
 			// Per retry policies to inject into HTTP pipeline
 			PerRetryPolicies: b.perRetryPolicies,
+			Retry:            retryOptions,
 			// Logging policy options.
 			// Always allow Azure correlation header
 			Logging: policy.LogOptions{
@@ -77,3 +93,14 @@ func (b *ClientOptionsBuilder) BuildArmClientOptions() *arm.ClientOptions {
 		},
 	}
 }
+
+// retry returns the per-call policies and the retry options of the HTTP pipeline. The retry policy, when set, runs
+// last of the per-call policies, right before the default retry policy which is disabled.
+func (b *ClientOptionsBuilder) retry() ([]policy.Policy, policy.RetryOptions) {
+	if b.retryPolicy == nil {
+		return b.perCallPolicies, policy.RetryOptions{}
+	}
+
+	perCallPolicies := append(slices.Clone(b.perCallPolicies), b.retryPolicy)
+	return perCallPolicies, policy.RetryOptions{MaxRetries: -1}
+}
@@ -5,13 +5,16 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/cloud"
 	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/azure/azure-dev/cli/azd/test/mocks/mockhttp"
 	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -127,3 +130,43 @@ func Test_simpleCorrelationPolicy_Do(t *testing.T) {
 		})
 	}
 }
+
+func Test_ClientOptionsBuilderFactory_CorrelationId(t *testing.T) {
+	httpClient := mockhttp.NewMockHttpUtil()
+	httpClient.When(func(request *http.Request) bool {
+		return true
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateEmptyHttpResponse(request, http.StatusOK)
+	})
+
+	factory := NewClientOptionsBuilderFactory(httpClient, "azd", cloud.AzurePublic())
+
+	tracer := sdktrace.NewTracerProvider().Tracer("test")
+	ctx, span := tracer.Start(context.Background(), "cmd.provision")
+	defer span.End()
+
+	// Requests sent from nested spans of the same run share the trace id
+	childCtx, childSpan := tracer.Start(ctx, "deployment")
+	defer childSpan.End()
+
+	armClient, err := armresources.NewClient(
+		"SUBSCRIPTION_ID", &mocks.MockCredentials{}, factory.NewClientOptionsBuilder().BuildArmClientOptions())
+	require.NoError(t, err)
+
+	var armResponse *http.Response
+	_, _ = armClient.GetByID(runtime.WithCaptureResponse(ctx, &armResponse), "RESOURCE_ID", "", nil)
+
+	coreClient, err := armresources.NewClient(
+		"SUBSCRIPTION_ID",
+		&mocks.MockCredentials{},
+		&arm.ClientOptions{ClientOptions: *factory.NewClientOptionsBuilder().BuildCoreClientOptions()},
+	)
+	require.NoError(t, err)
+
+	var coreResponse *http.Response
+	_, _ = coreClient.GetByID(runtime.WithCaptureResponse(childCtx, &coreResponse), "RESOURCE_ID", "", nil)
+
+	traceId := span.SpanContext().TraceID().String()
+	require.Equal(t, traceId, armResponse.Request.Header.Get(cMsCorrelationIdHeader))
+	require.Equal(t, traceId, coreResponse.Request.Header.Get(cMsCorrelationIdHeader))
+}
@@ -0,0 +1,107 @@
+package azsdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// LogsQueryClient runs KQL queries against the Log Analytics query API.
+// More info can be found at the following:
+// https://learn.microsoft.com/rest/api/loganalytics/dataaccess/query/resource-execute
+type LogsQueryClient struct {
+	endpoint string
+	pipeline runtime.Pipeline
+}
+
+// LogsQueryResult is the result of a logs query. Queries return a single table unless they use `fork`.
+type LogsQueryResult struct {
+	Tables []LogsTable `json:"tables"`
+}
+
+type LogsTable struct {
+	Name    string       `json:"name"`
+	Columns []LogsColumn `json:"columns"`
+	Rows    [][]any      `json:"rows"`
+}
+
+type LogsColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Records returns the rows of the table as a list of column name to value maps.
+func (t *LogsTable) Records() []map[string]any {
+	records := make([]map[string]any, 0, len(t.Rows))
+	for _, row := range t.Rows {
+		record := make(map[string]any, len(t.Columns))
+		for index, column := range t.Columns {
+			if index < len(row) {
+				record[column.Name] = row[index]
+			}
+		}
+		records = append(records, record)
+	}
+
+	return records
+}
+
+// Creates a new LogsQueryClient instance for the given Log Analytics endpoint
+// (e.g. https://api.loganalytics.io for Azure public cloud).
+func NewLogsQueryClient(
+	endpoint string,
+	credential azcore.TokenCredential,
+	options *arm.ClientOptions,
+) (*LogsQueryClient, error) {
+	if options == nil {
+		options = &arm.ClientOptions{}
+	}
+
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	pipeline := runtime.NewPipeline("logs-query", "1.0.0", runtime.PipelineOptions{
+		PerRetry: []policy.Policy{
+			runtime.NewBearerTokenPolicy(credential, []string{endpoint + "/.default"}, nil),
+		},
+	}, &options.ClientOptions)
+
+	return &LogsQueryClient{
+		endpoint: endpoint,
+		pipeline: pipeline,
+	}, nil
+}
+
+// QueryResource runs the query against the logs of an Azure resource, for example an Application Insights component
+// or a Log Analytics workspace.
+func (c *LogsQueryClient) QueryResource(ctx context.Context, resourceId string, query string) (*LogsQueryResult, error) {
+	requestUrl := fmt.Sprintf("%s/v1%s/query", c.endpoint, resourceId)
+	request, err := runtime.NewRequest(ctx, http.MethodPost, requestUrl)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	if err := runtime.MarshalAsJSON(request, map[string]string{"query": query}); err != nil {
+		return nil, fmt.Errorf("setting request body: %w", err)
+	}
+
+	response, err := c.pipeline.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if !runtime.HasStatusCode(response, http.StatusOK) {
+		return nil, runtime.NewResponseError(response)
+	}
+
+	var result LogsQueryResult
+	if err := runtime.UnmarshalAsJSON(response, &result); err != nil {
+		return nil, fmt.Errorf("reading query result: %w", err)
+	}
+
+	return &result, nil
+}
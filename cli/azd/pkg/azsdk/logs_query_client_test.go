@@ -0,0 +1,81 @@
+package azsdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+const testInsightsId = "/subscriptions/SUB/resourceGroups/RG/providers/microsoft.insights/components/appi"
+
+func TestLogsQueryClient(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+
+		var query string
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodPost &&
+				request.URL.Host == "api.loganalytics.io" &&
+				request.URL.Path == "/v1"+testInsightsId+"/query"
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			var body map[string]string
+			if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+				return nil, err
+			}
+			query = body["query"]
+
+			return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{
+				"tables": []any{
+					map[string]any{
+						"name": "PrimaryResult",
+						"columns": []any{
+							map[string]any{"name": "name", "type": "string"},
+							map[string]any{"name": "count_", "type": "long"},
+						},
+						"rows": []any{
+							[]any{"GET /", 42},
+							[]any{"GET /api/items", 7},
+						},
+					},
+				},
+			})
+		})
+
+		client, err := NewLogsQueryClient(
+			"https://api.loganalytics.io/", &mocks.MockCredentials{}, mockContext.ArmClientOptions)
+		require.NoError(t, err)
+
+		result, err := client.QueryResource(*mockContext.Context, testInsightsId, "requests | summarize count() by name")
+		require.NoError(t, err)
+		require.Equal(t, "requests | summarize count() by name", query)
+
+		require.Len(t, result.Tables, 1)
+		require.Equal(t, []LogsColumn{{Name: "name", Type: "string"}, {Name: "count_", Type: "long"}}, result.Tables[0].Columns)
+		require.Equal(t, []map[string]any{
+			{"name": "GET /", "count_": float64(42)},
+			{"name": "GET /api/items", "count_": float64(7)},
+		}, result.Tables[0].Records())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodPost
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return mocks.CreateHttpResponseWithBody(request, http.StatusBadRequest, map[string]any{
+				"error": map[string]any{"code": "BadArgumentError", "message": "The request had some invalid properties"},
+			})
+		})
+
+		client, err := NewLogsQueryClient("https://api.loganalytics.io", &mocks.MockCredentials{}, mockContext.ArmClientOptions)
+		require.NoError(t, err)
+
+		result, err := client.QueryResource(*mockContext.Context, testInsightsId, "not kql")
+		require.Nil(t, result)
+		require.ErrorContains(t, err, "BadArgumentError")
+	})
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// UploadDirectoryOptions configures the upload of the files of a directory.
+type UploadDirectoryOptions struct {
+	// Parallelism is the number of files uploaded concurrently. Defaults to 8.
+	Parallelism int
+	// Progress, when set, is called after each file is uploaded, successfully or not, with the number of files done
+	// and the total number of files.
+	Progress func(done int, total int)
+}
+
+const defaultParallelism = 8
+
+// UploadDirectory uploads the files of dir, like the files of a static site, as the blobs named after their path
+// relative to dir. Files are uploaded concurrently, up to the parallelism of the options, in no particular order, and
+// the failures of all the files are returned together.
+//
+// Files are uploaded with the resumable upload of the client, which backs off when throttled by the storage account.
+// Uploading the directory again after a failure, like on the next deploy of a static site, resumes the uploads of the
+// files from their staged chunks.
+func UploadDirectory(ctx context.Context, client BlobClient, dir string, options *UploadDirectoryOptions) error {
+	parallelism := defaultParallelism
+	var progress func(int, int)
+	if options != nil {
+		if options.Parallelism > 0 {
+			parallelism = options.Parallelism
+		}
+		progress = options.Progress
+	}
+
+	files := []string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() {
+			files = append(files, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("listing files of '%s': %w", dir, err)
+	}
+
+	// mu serializes the progress updates
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	done := 0
+
+	errs := make([]error, len(files))
+	semaphore := make(chan struct{}, parallelism)
+
+	for i, path := range files {
+		wg.Add(1)
+		go func(i int, path string) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			rel, err := filepath.Rel(dir, path)
+			if err == nil {
+				err = uploadFile(ctx, client, filepath.ToSlash(rel), path)
+			}
+			if err != nil {
+				errs[i] = fmt.Errorf("uploading '%s': %w", path, err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			done++
+			if progress != nil {
+				progress(done, len(files))
+			}
+		}(i, path)
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// uploadFile uploads the file at path as blobPath.
+func uploadFile(ctx context.Context, client BlobClient, blobPath string, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return client.Upload(ctx, blobPath, file)
+}
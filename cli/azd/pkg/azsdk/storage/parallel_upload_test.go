@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockhttp"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBlobClient records the uploaded blobs and the maximum number of concurrent uploads.
+type fakeBlobClient struct {
+	BlobClient
+
+	mu         sync.Mutex
+	blobs      map[string]string
+	running    int
+	maxRunning int
+	failures   map[string]error
+}
+
+func (c *fakeBlobClient) Upload(ctx context.Context, blobPath string, reader io.Reader) error {
+	c.mu.Lock()
+	c.running++
+	c.maxRunning = max(c.maxRunning, c.running)
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		c.running--
+		c.mu.Unlock()
+	}()
+
+	// Leaves time for the other uploads to start
+	time.Sleep(5 * time.Millisecond)
+
+	contents, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err, has := c.failures[blobPath]; has {
+		return err
+	}
+
+	c.blobs[blobPath] = string(contents)
+	return nil
+}
+
+func writeSiteFiles(t *testing.T, count int) string {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "assets"), osutil.PermissionDirectory))
+
+	for i := 0; i < count; i++ {
+		path := filepath.Join(dir, "assets", fmt.Sprintf("file%d.js", i))
+		require.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf("contents %d", i)), osutil.PermissionFile))
+	}
+
+	return dir
+}
+
+func Test_UploadDirectory(t *testing.T) {
+	t.Run("Concurrent", func(t *testing.T) {
+		dir := writeSiteFiles(t, 50)
+		client := &fakeBlobClient{blobs: map[string]string{}}
+
+		var progress []int
+		err := UploadDirectory(context.Background(), client, dir, &UploadDirectoryOptions{
+			Parallelism: 4,
+			Progress: func(done int, total int) {
+				require.Equal(t, 50, total)
+				progress = append(progress, done)
+			},
+		})
+		require.NoError(t, err)
+
+		require.Len(t, client.blobs, 50)
+		require.Equal(t, "contents 7", client.blobs["assets/file7.js"])
+		require.Equal(t, 4, client.maxRunning)
+		require.Len(t, progress, 50)
+		require.Equal(t, 50, progress[len(progress)-1])
+	})
+
+	t.Run("AggregatesErrors", func(t *testing.T) {
+		dir := writeSiteFiles(t, 20)
+		client := &fakeBlobClient{
+			blobs: map[string]string{},
+			failures: map[string]error{
+				"assets/file3.js":  errors.New("blob is leased"),
+				"assets/file12.js": errors.New("invalid blob name"),
+			},
+		}
+
+		err := UploadDirectory(context.Background(), client, dir, &UploadDirectoryOptions{Parallelism: 4})
+		require.ErrorContains(t, err, "file3.js': blob is leased")
+		require.ErrorContains(t, err, "file12.js': invalid blob name")
+		// The other files are still uploaded
+		require.Len(t, client.blobs, 18)
+	})
+}
+
+func Test_UploadDirectory_ResumesBlobUploads(t *testing.T) {
+	dir := t.TempDir()
+	// app.js spans two blocks, and index.html is small enough to be uploaded at once
+	firstChunk := bytes.Repeat([]byte("/"), defaultChunkSize)
+	appJs := append(bytes.Clone(firstChunk), []byte("app()")...)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html>"), osutil.PermissionFile))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.js"), appJs, osutil.PermissionFile))
+
+	// A previous deploy staged the first block of app.js before failing
+	stagedBlockID := chunkBlockID(0, firstChunk)
+
+	var mu sync.Mutex
+	stagedBlobs := []string{}
+	uploadedBlobs := []string{}
+	contentTypes := map[string]string{}
+
+	httpClient := mockhttp.NewMockHttpUtil()
+	httpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && request.URL.Query().Get("comp") == "list"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return xmlResponse(request, `<EnumerationResults><Containers>`+
+			`<Container><Name>$web</Name><Properties></Properties></Container>`+
+			`</Containers></EnumerationResults>`), nil
+	})
+	httpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && request.URL.Query().Get("comp") == "blocklist"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		uncommitted := ""
+		if strings.HasSuffix(request.URL.Path, "/app.js") {
+			uncommitted = fmt.Sprintf("<Block><Name>%s</Name><Size>%d</Size></Block>", stagedBlockID, defaultChunkSize)
+		}
+
+		return xmlResponse(
+			request, "<BlockList><CommittedBlocks></CommittedBlocks><UncommittedBlocks>"+uncommitted+
+				"</UncommittedBlocks></BlockList>"), nil
+	})
+	httpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut && request.URL.Query().Get("comp") == "block"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		stagedBlobs = append(stagedBlobs, path.Base(request.URL.Path))
+
+		return &http.Response{Request: request, StatusCode: http.StatusCreated, Header: http.Header{}, Body: http.NoBody}, nil
+	})
+	httpClient.When(func(request *http.Request) bool {
+		comp := request.URL.Query().Get("comp")
+		return request.Method == http.MethodPut && (comp == "blocklist" || comp == "")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if request.URL.Query().Get("comp") == "" {
+			uploadedBlobs = append(uploadedBlobs, path.Base(request.URL.Path))
+		}
+		// The storage SDK doesn't canonicalize the names of its headers
+		contentTypes[path.Base(request.URL.Path)] = strings.Join(request.Header["x-ms-blob-content-type"], ",")
+
+		return &http.Response{Request: request, StatusCode: http.StatusCreated, Header: http.Header{}, Body: http.NoBody}, nil
+	})
+
+	sdkClient, err := azblob.NewClientWithNoCredential(
+		"https://account.blob.core.windows.net/",
+		&azblob.ClientOptions{ClientOptions: azcore.ClientOptions{Transport: httpClient}},
+	)
+	require.NoError(t, err)
+
+	client := NewBlobClient(&AccountConfig{AccountName: "account", ContainerName: "$web"}, sdkClient)
+	require.NoError(t, UploadDirectory(context.Background(), client, dir, nil))
+
+	// index.html is uploaded at once, and the upload of app.js resumes from its staged block
+	require.Equal(t, []string{"index.html"}, uploadedBlobs)
+	require.Equal(t, []string{"app.js"}, stagedBlobs)
+	require.Equal(t, map[string]string{
+		"index.html": "text/html; charset=utf-8",
+		"app.js":     "text/javascript; charset=utf-8",
+	}, contentTypes)
+}
+
+func xmlResponse(request *http.Request, body string) *http.Response {
+	return &http.Response{
+		Request:    request,
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/xml"}},
+		Body:       io.NopCloser(strings.NewReader(`<?xml version="1.0" encoding="utf-8"?>` + body)),
+	}
+}
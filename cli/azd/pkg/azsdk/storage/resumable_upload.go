@@ -0,0 +1,237 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
+	"github.com/sethvargo/go-retry"
+)
+
+// BlockUploader stages the chunks of a block blob, and commits them to the blob once all of them are staged.
+type BlockUploader interface {
+	// Upload writes the blob from contents at once, without staging blocks.
+	Upload(ctx context.Context, contents []byte) error
+
+	// StagedBlocks returns the IDs of the blocks staged, but not committed yet, by a previous upload.
+	StagedBlocks(ctx context.Context) ([]string, error)
+
+	// StageBlock uploads a chunk of the blob as the block with the given ID.
+	StageBlock(ctx context.Context, blockID string, chunk []byte) error
+
+	// CommitBlocks writes the blob from the staged blocks, in the given order.
+	CommitBlocks(ctx context.Context, blockIDs []string) error
+}
+
+// ResumableUploadOptions configures the chunks of a resumable upload.
+type ResumableUploadOptions struct {
+	// ChunkSize is the size of the chunks, in bytes. Defaults to 4 MiB.
+	ChunkSize int
+	// MaxRetries is the number of times the upload of a chunk is retried on a transient failure before failing the
+	// upload. Defaults to 3.
+	MaxRetries int
+	// RetryDelay is the delay before the first retry of a chunk, doubled for each retry. Defaults to 1 second.
+	RetryDelay time.Duration
+}
+
+const (
+	defaultChunkSize  = 4 * 1024 * 1024
+	defaultMaxRetries = 3
+	defaultRetryDelay = time.Second
+)
+
+// UploadResumable uploads the contents of reader in chunks, retrying each chunk on transient failures, like network
+// errors or throttled requests, with an exponential back off. Contents smaller than a chunk are uploaded at once.
+//
+// The ID of a block is derived from the position and the contents of its chunk, so when an upload fails, uploading the
+// same contents again resumes the upload: the chunks already staged by the failed upload are skipped.
+func UploadResumable(
+	ctx context.Context,
+	uploader BlockUploader,
+	reader io.Reader,
+	options *ResumableUploadOptions,
+) error {
+	chunkSize, maxRetries, retryDelay := defaultChunkSize, defaultMaxRetries, defaultRetryDelay
+	if options != nil {
+		if options.ChunkSize > 0 {
+			chunkSize = options.ChunkSize
+		}
+		if options.MaxRetries > 0 {
+			maxRetries = options.MaxRetries
+		}
+		if options.RetryDelay > 0 {
+			retryDelay = options.RetryDelay
+		}
+	}
+
+	backoff := retry.WithMaxRetries(uint64(maxRetries), retry.NewExponential(retryDelay))
+	uploadChunk := func(index int, upload func(ctx context.Context) error) error {
+		return retry.Do(ctx, backoff, func(ctx context.Context) error {
+			err := upload(ctx)
+			if err != nil && isTransient(err) {
+				log.Printf("failed to upload chunk %d: %v", index, err)
+				return retry.RetryableError(err)
+			}
+
+			return err
+		})
+	}
+
+	chunk := make([]byte, chunkSize)
+	n, err := io.ReadFull(reader, chunk)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("reading chunk 0: %w", err)
+	}
+
+	if n < chunkSize {
+		if err := uploadChunk(0, func(ctx context.Context) error {
+			return uploader.Upload(ctx, chunk[:n])
+		}); err != nil {
+			return fmt.Errorf("uploading: %w", err)
+		}
+
+		return nil
+	}
+
+	stagedBlocks, err := uploader.StagedBlocks(ctx)
+	if err != nil {
+		return fmt.Errorf("getting the staged blocks: %w", err)
+	}
+
+	staged := map[string]bool{}
+	for _, blockID := range stagedBlocks {
+		staged[blockID] = true
+	}
+
+	blockIDs := []string{}
+	for index := 0; ; index++ {
+		// The first chunk is read before deciding whether the contents are staged in blocks
+		if index > 0 {
+			n, err = io.ReadFull(reader, chunk)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+				return fmt.Errorf("reading chunk %d: %w", index, err)
+			}
+		}
+
+		blockID := chunkBlockID(index, chunk[:n])
+		blockIDs = append(blockIDs, blockID)
+
+		if staged[blockID] {
+			log.Printf("skipping chunk %d, staged by a previous upload", index)
+		} else if err := uploadChunk(index, func(ctx context.Context) error {
+			return uploader.StageBlock(ctx, blockID, chunk[:n])
+		}); err != nil {
+			return fmt.Errorf("uploading chunk %d: %w", index, err)
+		}
+
+		if n < chunkSize {
+			break
+		}
+	}
+
+	if err := uploader.CommitBlocks(ctx, blockIDs); err != nil {
+		return fmt.Errorf("committing %d chunks: %w", len(blockIDs), err)
+	}
+
+	return nil
+}
+
+// isTransient returns whether err is a failure which may not happen again when retried: a network error, or a response of
+// the storage account for a request which timed out, was throttled or failed on the server.
+func isTransient(err error) bool {
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode == http.StatusRequestTimeout ||
+			respErr.StatusCode == http.StatusTooManyRequests ||
+			respErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// chunkBlockID returns the ID of the block of a chunk. The IDs of the blocks of a blob must all have the same length.
+func chunkBlockID(index int, chunk []byte) string {
+	hash := sha256.Sum256(chunk)
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%08d-%x", index, hash[:8])))
+}
+
+// NewBlockUploader creates a BlockUploader for a block blob of the Azure Storage SDK. The blob is committed with the
+// given content type, unless empty.
+func NewBlockUploader(client *blockblob.Client, contentType string) BlockUploader {
+	return &blockUploader{
+		client:      client,
+		contentType: contentType,
+	}
+}
+
+type blockUploader struct {
+	client      *blockblob.Client
+	contentType string
+}
+
+func (u *blockUploader) Upload(ctx context.Context, contents []byte) error {
+	var options *blockblob.UploadOptions
+	if u.contentType != "" {
+		options = &blockblob.UploadOptions{
+			HTTPHeaders: &blob.HTTPHeaders{BlobContentType: &u.contentType},
+		}
+	}
+
+	_, err := u.client.Upload(ctx, streaming.NopCloser(bytes.NewReader(contents)), options)
+	return err
+}
+
+func (u *blockUploader) StagedBlocks(ctx context.Context) ([]string, error) {
+	resp, err := u.client.GetBlockList(ctx, blockblob.BlockListTypeUncommitted, nil)
+	if err != nil {
+		// The blob doesn't exist until blocks are staged
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	blockIDs := []string{}
+	for _, block := range resp.UncommittedBlocks {
+		if block.Name != nil {
+			blockIDs = append(blockIDs, *block.Name)
+		}
+	}
+
+	return blockIDs, nil
+}
+
+func (u *blockUploader) StageBlock(ctx context.Context, blockID string, chunk []byte) error {
+	_, err := u.client.StageBlock(ctx, blockID, streaming.NopCloser(bytes.NewReader(chunk)), nil)
+	return err
+}
+
+func (u *blockUploader) CommitBlocks(ctx context.Context, blockIDs []string) error {
+	var options *blockblob.CommitBlockListOptions
+	if u.contentType != "" {
+		options = &blockblob.CommitBlockListOptions{
+			HTTPHeaders: &blob.HTTPHeaders{BlobContentType: &u.contentType},
+		}
+	}
+
+	_, err := u.client.CommitBlockList(ctx, blockIDs, options)
+	return err
+}
@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeUploader stages blocks in memory, failing the uploads whose attempt number is in failures with failure.
+type fakeUploader struct {
+	staged    map[string][]byte
+	committed []byte
+	uploads   int
+	failures  map[int]int
+	failure   error
+}
+
+func newFakeUploader() *fakeUploader {
+	return &fakeUploader{
+		staged:   map[string][]byte{},
+		failures: map[int]int{},
+		failure:  &net.OpError{Op: "write", Net: "tcp", Err: syscall.ECONNRESET},
+	}
+}
+
+func (u *fakeUploader) fail() error {
+	index := u.uploads
+	u.uploads++
+
+	if u.failures[index] > 0 {
+		u.failures[index]--
+		return u.failure
+	}
+
+	return nil
+}
+
+func (u *fakeUploader) Upload(ctx context.Context, contents []byte) error {
+	if err := u.fail(); err != nil {
+		return err
+	}
+
+	u.committed = bytes.Clone(contents)
+	u.staged = map[string][]byte{}
+	return nil
+}
+
+func (u *fakeUploader) StagedBlocks(ctx context.Context) ([]string, error) {
+	blockIDs := []string{}
+	for blockID := range u.staged {
+		blockIDs = append(blockIDs, blockID)
+	}
+
+	return blockIDs, nil
+}
+
+func (u *fakeUploader) StageBlock(ctx context.Context, blockID string, chunk []byte) error {
+	if err := u.fail(); err != nil {
+		return err
+	}
+
+	u.staged[blockID] = bytes.Clone(chunk)
+	return nil
+}
+
+func (u *fakeUploader) CommitBlocks(ctx context.Context, blockIDs []string) error {
+	committed := []byte{}
+	for _, blockID := range blockIDs {
+		chunk, has := u.staged[blockID]
+		if !has {
+			return errors.New("block not staged")
+		}
+
+		committed = append(committed, chunk...)
+	}
+
+	u.committed = committed
+	u.staged = map[string][]byte{}
+	return nil
+}
+
+func Test_UploadResumable(t *testing.T) {
+	contents := []byte("the contents of a large static site")
+	options := &ResumableUploadOptions{
+		ChunkSize:  8,
+		MaxRetries: 1,
+		RetryDelay: time.Millisecond,
+	}
+
+	t.Run("RetriesChunk", func(t *testing.T) {
+		uploader := newFakeUploader()
+		// The second upload is the first attempt of the second chunk
+		uploader.failures[1] = 1
+
+		err := UploadResumable(context.Background(), uploader, bytes.NewReader(contents), options)
+		require.NoError(t, err)
+		require.Equal(t, contents, uploader.committed)
+		// 5 chunks, and a retry of the second one
+		require.Equal(t, 6, uploader.uploads)
+	})
+
+	t.Run("ResumesFailedUpload", func(t *testing.T) {
+		uploader := newFakeUploader()
+		// The third chunk fails, and so does its retry
+		uploader.failures[2] = 1
+		uploader.failures[3] = 1
+
+		err := UploadResumable(context.Background(), uploader, bytes.NewReader(contents), options)
+		require.ErrorContains(t, err, "uploading chunk 2: write tcp: connection reset by peer")
+		require.Nil(t, uploader.committed)
+		require.Len(t, uploader.staged, 2)
+
+		// Uploading again only uploads the chunks which were not staged
+		uploader.uploads = 0
+		err = UploadResumable(context.Background(), uploader, bytes.NewReader(contents), options)
+		require.NoError(t, err)
+		require.Equal(t, contents, uploader.committed)
+		require.Equal(t, 3, uploader.uploads)
+	})
+
+	t.Run("ChangedContentsRestart", func(t *testing.T) {
+		uploader := newFakeUploader()
+		uploader.failures[1] = 1
+		uploader.failures[2] = 1
+
+		err := UploadResumable(context.Background(), uploader, bytes.NewReader(contents), options)
+		require.Error(t, err)
+
+		// A chunk staged for other contents isn't reused
+		changed := []byte("THE contents of a large static site")
+		uploader.uploads = 0
+		err = UploadResumable(context.Background(), uploader, bytes.NewReader(changed), options)
+		require.NoError(t, err)
+		require.Equal(t, changed, uploader.committed)
+		require.Equal(t, 5, uploader.uploads)
+	})
+
+	t.Run("BacksOffWhenThrottled", func(t *testing.T) {
+		uploader := newFakeUploader()
+		uploader.failure = &azcore.ResponseError{StatusCode: http.StatusTooManyRequests}
+		uploader.failures[1] = 1
+
+		err := UploadResumable(context.Background(), uploader, bytes.NewReader(contents), options)
+		require.NoError(t, err)
+		require.Equal(t, contents, uploader.committed)
+		require.Equal(t, 6, uploader.uploads)
+	})
+
+	t.Run("PermanentFailureNotRetried", func(t *testing.T) {
+		uploader := newFakeUploader()
+		uploader.failure = &azcore.ResponseError{StatusCode: http.StatusForbidden}
+		uploader.failures[1] = 1
+
+		err := UploadResumable(context.Background(), uploader, bytes.NewReader(contents), options)
+		require.Error(t, err)
+		require.Nil(t, uploader.committed)
+		require.Equal(t, 2, uploader.uploads)
+	})
+
+	t.Run("SmallerThanChunk", func(t *testing.T) {
+		uploader := newFakeUploader()
+		uploader.failures[0] = 1
+
+		// The contents are uploaded at once, without staging blocks, and retried when failing
+		err := UploadResumable(context.Background(), uploader, bytes.NewReader(contents[:5]), options)
+		require.NoError(t, err)
+		require.Equal(t, contents[:5], uploader.committed)
+		require.Equal(t, 2, uploader.uploads)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		uploader := newFakeUploader()
+
+		err := UploadResumable(context.Background(), uploader, bytes.NewReader(nil), options)
+		require.NoError(t, err)
+		require.Equal(t, []byte{}, uploader.committed)
+		require.Equal(t, 1, uploader.uploads)
+	})
+}
@@ -5,7 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"mime"
+	"path"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
@@ -29,7 +32,8 @@ type BlobClient interface {
 	// Download downloads a blob from the configured storage account container.
 	Download(ctx context.Context, blobPath string) (io.ReadCloser, error)
 
-	// Upload uploads a blob to the configured storage account container.
+	// Upload uploads a blob to the configured storage account container. The blob is uploaded in chunks, retried on
+	// failure, and uploading the same contents again after a failure resumes the upload.
 	Upload(ctx context.Context, blobPath string, reader io.Reader) error
 
 	// Delete deletes a blob from the configured storage account container.
@@ -53,6 +57,10 @@ func NewBlobClient(
 type blobClient struct {
 	config *AccountConfig
 	client *azblob.Client
+
+	// containerMu serializes the checks of the container, so concurrent uploads create it once
+	containerMu     sync.Mutex
+	containerExists bool
 }
 
 // Blob represents a blob within a storage account container.
@@ -111,7 +119,14 @@ func (bc *blobClient) Upload(ctx context.Context, blobPath string, reader io.Rea
 		return err
 	}
 
-	_, err := bc.client.UploadStream(ctx, bc.config.ContainerName, blobPath, reader, nil)
+	blockBlobClient := bc.client.ServiceClient().
+		NewContainerClient(bc.config.ContainerName).
+		NewBlockBlobClient(blobPath)
+
+	// Blobs served over HTTP, like the files of a static site, need their content type
+	contentType := mime.TypeByExtension(path.Ext(blobPath))
+
+	err := UploadResumable(ctx, NewBlockUploader(blockBlobClient, contentType), reader, nil)
 	if err != nil {
 		return fmt.Errorf("failed to upload blob '%s', %w", blobPath, err)
 	}
@@ -136,6 +151,13 @@ func (bc *blobClient) Delete(ctx context.Context, blobPath string) error {
 // Check if the specified container exists
 // If it doesn't already exist then create it
 func (bc *blobClient) ensureContainerExists(ctx context.Context) error {
+	bc.containerMu.Lock()
+	defer bc.containerMu.Unlock()
+
+	if bc.containerExists {
+		return nil
+	}
+
 	exists := false
 
 	pager := bc.client.NewListContainersPager(nil)
@@ -160,6 +182,7 @@ func (bc *blobClient) ensureContainerExists(ctx context.Context) error {
 		}
 	}
 
+	bc.containerExists = true
 	return nil
 }
 
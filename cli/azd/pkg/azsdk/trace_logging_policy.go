@@ -0,0 +1,81 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azsdk
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/logging"
+)
+
+// maxLoggedErrorBodySize is the maximum size of the body of a failed response logged by the trace logging policy.
+const maxLoggedErrorBodySize = 4 * 1024
+
+// traceLoggingPolicy logs the requests sent to Azure at the trace level, with their method, URL, status and
+// correlation id. Headers are never logged, so the Authorization header doesn't leak, and the known secrets are
+// redacted from the URL and the body of failed responses.
+type traceLoggingPolicy struct {
+}
+
+// NewTraceLoggingPolicy creates a policy logging each attempt of the requests sent to Azure when the log level is
+// trace, like with `--log-level trace`.
+func NewTraceLoggingPolicy() policy.Policy {
+	return &traceLoggingPolicy{}
+}
+
+func (p *traceLoggingPolicy) Do(req *policy.Request) (*http.Response, error) {
+	if !logging.Enabled(logging.LevelTrace) {
+		return req.Next()
+	}
+
+	rawRequest := req.Raw()
+	url := exec.RedactSensitiveValues(rawRequest.URL.String())
+	start := time.Now()
+
+	res, err := req.Next()
+	if err != nil {
+		logging.Trace("azure request failed",
+			"method", rawRequest.Method,
+			"url", url,
+			"correlationId", rawRequest.Header.Get(cMsCorrelationIdHeader),
+			"duration", time.Since(start),
+			"error", exec.RedactSensitiveValues(err.Error()))
+		return res, err
+	}
+
+	correlationId := res.Header.Get(cMsCorrelationIdHeader)
+	if correlationId == "" {
+		correlationId = rawRequest.Header.Get(cMsCorrelationIdHeader)
+	}
+
+	args := []any{
+		"method", rawRequest.Method,
+		"url", url,
+		"status", res.StatusCode,
+		"correlationId", correlationId,
+		"duration", time.Since(start),
+	}
+
+	// The error returned by Azure explains why the request failed
+	if res.StatusCode >= http.StatusBadRequest && res.Body != nil {
+		body, readErr := io.ReadAll(res.Body)
+		res.Body.Close()
+		res.Body = io.NopCloser(bytes.NewReader(body))
+
+		if readErr == nil {
+			if len(body) > maxLoggedErrorBodySize {
+				body = body[:maxLoggedErrorBodySize]
+			}
+			args = append(args, "body", exec.RedactSensitiveValues(string(body)))
+		}
+	}
+
+	logging.Trace("azure request", args...)
+	return res, nil
+}
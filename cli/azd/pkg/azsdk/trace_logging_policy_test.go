@@ -0,0 +1,119 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azsdk
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/logging"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockhttp"
+	"github.com/stretchr/testify/require"
+)
+
+func newTraceLoggingClient(t *testing.T, status int, body string) *armresources.Client {
+	httpClient := mockhttp.NewMockHttpUtil()
+	httpClient.When(func(request *http.Request) bool {
+		return true
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		header := http.Header{}
+		header.Set(cMsCorrelationIdHeader, "CORRELATION_ID")
+		header.Set("Content-Type", "application/json")
+
+		return &http.Response{
+			Request:    request,
+			StatusCode: status,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil
+	})
+
+	clientOptions := NewClientOptionsBuilder().
+		WithTransport(httpClient).
+		WithPerRetryPolicy(NewTraceLoggingPolicy()).
+		BuildArmClientOptions()
+	clientOptions.Retry = policy.RetryOptions{MaxRetries: -1}
+
+	credential := &mocks.MockCredentials{
+		GetTokenFn: func(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+			return azcore.AccessToken{Token: "SECRET_ACCESS_TOKEN"}, nil
+		},
+	}
+
+	client, err := armresources.NewClient("SUBSCRIPTION_ID", credential, clientOptions)
+	require.NoError(t, err)
+
+	return client
+}
+
+func configureLogging(t *testing.T, level slog.Level) *bytes.Buffer {
+	var buf bytes.Buffer
+	logging.Configure(level, &buf)
+
+	t.Cleanup(func() {
+		logging.Configure(logging.LevelNone, io.Discard)
+	})
+
+	return &buf
+}
+
+func Test_TraceLoggingPolicy(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		buf := configureLogging(t, logging.LevelTrace)
+		client := newTraceLoggingClient(t, http.StatusOK, `{"id": "RESOURCE_ID"}`)
+
+		_, err := client.GetByID(context.Background(), "/subscriptions/SUBSCRIPTION_ID/resourceGroups/RG", "2021-04-01", nil)
+		require.NoError(t, err)
+
+		logs := buf.String()
+		require.Contains(t, logs, "level=TRACE msg=\"azure request\" method=GET")
+		require.Contains(t, logs, "url=\"https://management.azure.com/subscriptions/SUBSCRIPTION_ID/resourceGroups/RG")
+		require.Contains(t, logs, "status=200")
+		require.Contains(t, logs, "correlationId=CORRELATION_ID")
+		require.NotContains(t, logs, "RESOURCE_ID\"")
+
+		// The access token of the Authorization header is never logged
+		require.NotContains(t, logs, "Authorization")
+		require.NotContains(t, logs, "SECRET_ACCESS_TOKEN")
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		buf := configureLogging(t, logging.LevelTrace)
+		exec.AddSensitiveData("rg-secret-value")
+		client := newTraceLoggingClient(t, http.StatusNotFound,
+			`{"error": {"code": "ResourceGroupNotFound", "message": "Resource group 'rg-secret-value' not found."}}`)
+
+		_, err := client.GetByID(context.Background(), "/subscriptions/SUBSCRIPTION_ID/resourceGroups/RG", "2021-04-01", nil)
+		require.Error(t, err)
+
+		// The error of the response is logged, and still returned to the caller
+		logs := buf.String()
+		require.Contains(t, logs, "status=404")
+		require.Contains(t, logs, "ResourceGroupNotFound")
+		require.ErrorContains(t, err, "ResourceGroupNotFound")
+		require.NotContains(t, logs, "SECRET_ACCESS_TOKEN")
+
+		// Secrets in the body are redacted
+		require.Contains(t, logs, "Resource group '<redacted>' not found.")
+	})
+
+	t.Run("NotTraceLevel", func(t *testing.T) {
+		buf := configureLogging(t, logging.LevelDebug)
+		client := newTraceLoggingClient(t, http.StatusOK, `{}`)
+
+		_, err := client.GetByID(context.Background(), "/subscriptions/SUBSCRIPTION_ID/resourceGroups/RG", "2021-04-01", nil)
+		require.NoError(t, err)
+		require.NotContains(t, buf.String(), "azure request")
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -92,6 +93,51 @@ func (c *ZipDeployClient) BeginDeploy(
 		return nil, err
 	}
 
+	return c.beginDeploy(request, "")
+}
+
+// Deploys the specified application zip to the azure app service and waits for completion
+func (c *ZipDeployClient) Deploy(ctx context.Context, zipFile io.Reader) (*DeployResponse, error) {
+	poller, err := c.BeginDeploy(ctx, zipFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.pollUntilDone(ctx, poller)
+}
+
+// Begins a deployment to a function app hosted on the Flex Consumption plan and returns a poller to check for status.
+// Flex Consumption apps don't support the zipdeploy API and are deployed with the publish (one deploy) API instead.
+// When remoteBuild is true, the package is built on the platform, e.g. to install Python dependencies.
+func (c *ZipDeployClient) BeginFlexDeploy(
+	ctx context.Context,
+	zipFile io.Reader,
+	remoteBuild bool,
+) (*runtime.Poller[*DeployResponse], error) {
+	request, err := c.createFlexDeployRequest(ctx, zipFile, remoteBuild)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.beginDeploy(request, fmt.Sprintf("https://%s/api/deployments/latest", c.hostName))
+}
+
+// Deploys the specified application zip to the Flex Consumption function app and waits for completion
+func (c *ZipDeployClient) FlexDeploy(ctx context.Context, zipFile io.Reader, remoteBuild bool) (*DeployResponse, error) {
+	poller, err := c.BeginFlexDeploy(ctx, zipFile, remoteBuild)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.pollUntilDone(ctx, poller)
+}
+
+// beginDeploy sends the deploy request and returns a poller for the deployment status. The status is polled at the
+// location returned by the service, or at defaultLocation when the service doesn't return one.
+func (c *ZipDeployClient) beginDeploy(
+	request *policy.Request,
+	defaultLocation string,
+) (*runtime.Poller[*DeployResponse], error) {
 	response, err := c.pipeline.Do(request)
 	if err != nil {
 		return nil, err
@@ -103,23 +149,25 @@ func (c *ZipDeployClient) BeginDeploy(
 		return nil, runtime.NewResponseError(response)
 	}
 
+	location := response.Header.Get("Location")
+	if strings.TrimSpace(location) == "" {
+		location = defaultLocation
+	}
+
 	var finalResponse *DeployResponse
 
 	pollerOptions := &runtime.NewPollerOptions[*DeployResponse]{
 		Response: &finalResponse,
-		Handler:  newDeployPollingHandler(c.pipeline, response),
+		Handler:  newDeployPollingHandler(c.pipeline, location),
 	}
 
 	return runtime.NewPoller(response, c.pipeline, pollerOptions)
 }
 
-// Deploys the specified application zip to the azure app service and waits for completion
-func (c *ZipDeployClient) Deploy(ctx context.Context, zipFile io.Reader) (*DeployResponse, error) {
-	poller, err := c.BeginDeploy(ctx, zipFile)
-	if err != nil {
-		return nil, err
-	}
-
+func (c *ZipDeployClient) pollUntilDone(
+	ctx context.Context,
+	poller *runtime.Poller[*DeployResponse],
+) (*DeployResponse, error) {
 	response, err := poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{
 		Frequency: deployStatusInterval,
 	})
@@ -152,17 +200,41 @@ func (c *ZipDeployClient) createDeployRequest(
 	return req, nil
 }
 
+// Creates the HTTP request for the Flex Consumption publish operation
+func (c *ZipDeployClient) createFlexDeployRequest(
+	ctx context.Context,
+	zipFile io.Reader,
+	remoteBuild bool,
+) (*policy.Request, error) {
+	endpoint := fmt.Sprintf("https://%s/api/publish", c.hostName)
+	req, err := runtime.NewRequest(ctx, http.MethodPost, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("creating deploy request: %w", err)
+	}
+
+	rawRequest := req.Raw()
+	rawRequest.Body = io.NopCloser(zipFile)
+	query := rawRequest.URL.Query()
+	query.Set("RemoteBuild", strconv.FormatBool(remoteBuild))
+	query.Set("Deployer", "azd")
+	rawRequest.Header.Set("Content-Type", "application/zip")
+	rawRequest.Header.Set("Accept", "application/json")
+	rawRequest.URL.RawQuery = query.Encode()
+
+	return req, nil
+}
+
 // Implementation of a Go SDK polling handler for async zip deploy operations
 type deployPollingHandler struct {
 	pipeline runtime.Pipeline
-	response *http.Response
+	location string
 	result   *DeployStatusResponse
 }
 
-func newDeployPollingHandler(pipeline runtime.Pipeline, response *http.Response) *deployPollingHandler {
+func newDeployPollingHandler(pipeline runtime.Pipeline, location string) *deployPollingHandler {
 	return &deployPollingHandler{
 		pipeline: pipeline,
-		response: response,
+		location: location,
 	}
 }
 
@@ -173,12 +245,11 @@ func (h *deployPollingHandler) Done() bool {
 
 // Executing the polling logic to check the status of the deploy operation
 func (h *deployPollingHandler) Poll(ctx context.Context) (*http.Response, error) {
-	location := h.response.Header.Get("Location")
-	if strings.TrimSpace(location) == "" {
+	if strings.TrimSpace(h.location) == "" {
 		return nil, fmt.Errorf("missing polling location header")
 	}
 
-	req, err := runtime.NewRequest(ctx, http.MethodGet, location)
+	req, err := runtime.NewRequest(ctx, http.MethodGet, h.location)
 	if err != nil {
 		return nil, err
 	}
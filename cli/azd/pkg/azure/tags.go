@@ -14,4 +14,13 @@ const (
 	// TagKeyAzdServiceName is the name of the key in the tags map of a resource
 	// used to store the azd service a resource is associated with.
 	TagKeyAzdServiceName = "azd-service-name"
+	// TagKeyAzdTemplateId is the name of the key in the tags map of a resource group
+	// used to store the template the project was created from.
+	TagKeyAzdTemplateId = "azd-template-id"
+	// TagKeyAzdProvisionTime is the name of the key in the tags map of a resource group
+	// used to store the time of the last provision, in RFC 3339 format.
+	TagKeyAzdProvisionTime = "azd-provision-time"
+	// TagKeyAzdExpiresOn is the name of the key in the tags map of a resource group
+	// used to store the time after which an ephemeral environment can be deleted, in RFC 3339 format.
+	TagKeyAzdExpiresOn = "azd-expires-on"
 )
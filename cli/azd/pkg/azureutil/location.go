@@ -6,12 +6,10 @@ package azureutil
 import (
 	"context"
 	"fmt"
-	"os"
 	"slices"
 	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/account"
-	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 )
 
@@ -53,11 +51,11 @@ func PromptLocationWithFilter(
 			strings.ToLower(a.RegionalDisplayName), strings.ToLower(b.RegionalDisplayName))
 	})
 
-	// Allow the environment variable `AZURE_LOCATION` to control the default value for the location
-	// selection.
-	defaultLocation := os.Getenv(environment.LocationEnvVarName)
+	// The environment variable `AZURE_LOCATION` controls the default value for the location selection, falling back
+	// to the azd config default and then the az CLI default.
+	defaultLocation := accountManager.ResolveDefaultLocation(ctx).Value
 
-	// If no location is set in the process environment, see what the azd config default is.
+	// If no rule applies, use azd's built-in default location.
 	if defaultLocation == "" {
 		defaultLocation = accountManager.GetDefaultLocationName(ctx)
 	}
@@ -0,0 +1,150 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package cleanup removes the temporary artifacts of an operation, such as packages and generated scripts, when the
+// operation is interrupted before it could remove them itself.
+package cleanup
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"sync"
+)
+
+type registryKey struct{}
+
+// Registry holds the cleanup callbacks of an operation. It is safe for concurrent use.
+type Registry struct {
+	mu        sync.Mutex
+	callbacks []func() error
+	ran       bool
+	released  bool
+
+	runOnce sync.Once
+	runErr  error
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a callback to the registry. Callbacks registered once the registry ran are run immediately, so
+// artifacts created by work that outlives the interruption are removed as well. Callbacks registered once the registry
+// is released are ignored.
+func (r *Registry) Register(callback func() error) {
+	r.mu.Lock()
+	if r.released {
+		r.mu.Unlock()
+		return
+	}
+
+	if !r.ran {
+		r.callbacks = append(r.callbacks, callback)
+		r.mu.Unlock()
+		return
+	}
+	r.mu.Unlock()
+
+	if err := callback(); err != nil {
+		log.Printf("cleanup failed: %v", err)
+	}
+}
+
+// Run runs the registered callbacks in the reverse order of registration. Callbacks run at most once: concurrent and
+// subsequent calls wait for the first run to complete and return its error.
+func (r *Registry) Run() error {
+	r.runOnce.Do(func() {
+		r.mu.Lock()
+		if r.released {
+			r.mu.Unlock()
+			return
+		}
+
+		r.ran = true
+		callbacks := r.callbacks
+		r.callbacks = nil
+		r.mu.Unlock()
+
+		var errs []error
+		for i := len(callbacks) - 1; i >= 0; i-- {
+			if err := callbacks[i](); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		r.runErr = errors.Join(errs...)
+	})
+
+	return r.runErr
+}
+
+// Release discards the registered callbacks without running them, once the operation completed and its artifacts
+// are either removed or kept on purpose.
+func (r *Registry) Release() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.released = true
+	r.callbacks = nil
+}
+
+// WithRegistry returns a copy of ctx carrying the registry.
+func WithRegistry(ctx context.Context, registry *Registry) context.Context {
+	return context.WithValue(ctx, registryKey{}, registry)
+}
+
+// FromContext returns the registry carried by ctx, if any.
+func FromContext(ctx context.Context) (*Registry, bool) {
+	registry, ok := ctx.Value(registryKey{}).(*Registry)
+	return registry, ok
+}
+
+// WithCancelCleanup returns a copy of ctx carrying a new registry, which runs as soon as ctx is cancelled. Call done
+// when the operation returns: it waits for the registry to run when ctx was cancelled, and discards it otherwise.
+func WithCancelCleanup(ctx context.Context) (cleanupCtx context.Context, done func()) {
+	registry := NewRegistry()
+	completed := make(chan struct{})
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			if err := registry.Run(); err != nil {
+				log.Printf("cleaning up after cancellation: %v", err)
+			}
+		case <-completed:
+		}
+	}()
+
+	var once sync.Once
+	return WithRegistry(ctx, registry), func() {
+		once.Do(func() {
+			close(completed)
+			if ctx.Err() != nil {
+				if err := registry.Run(); err != nil {
+					log.Printf("cleaning up after cancellation: %v", err)
+				}
+				return
+			}
+
+			registry.Release()
+		})
+	}
+}
+
+// Register adds a callback to the registry carried by ctx. It does nothing when ctx has no registry, in which case
+// the caller remains responsible for cleaning up on interruption.
+func Register(ctx context.Context, callback func() error) {
+	if registry, ok := FromContext(ctx); ok {
+		registry.Register(callback)
+	}
+}
+
+// RemoveOnCancel registers the removal of a temporary file or directory with the registry carried by ctx.
+func RemoveOnCancel(ctx context.Context, path string) {
+	Register(ctx, func() error {
+		return os.RemoveAll(path)
+	})
+}
@@ -0,0 +1,153 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cleanup
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/stretchr/testify/require"
+)
+
+func createTempFile(t *testing.T, dir string, name string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte("temp"), osutil.PermissionFile))
+	return path
+}
+
+func Test_WithCancelCleanup_CancelledMidOperation(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	cleanupCtx, done := WithCancelCleanup(ctx)
+
+	started := make(chan struct{})
+	var paths []string
+
+	// Simulates an operation creating temp artifacts and waiting on a long running step when interrupted
+	operationErr := make(chan error)
+	go func() {
+		paths = append(paths, createTempFile(t, dir, "package.zip"))
+		RemoveOnCancel(cleanupCtx, paths[0])
+
+		tempDir := filepath.Join(dir, "package")
+		require.NoError(t, os.MkdirAll(tempDir, osutil.PermissionDirectory))
+		createTempFile(t, tempDir, "index.js")
+		paths = append(paths, tempDir)
+		RemoveOnCancel(cleanupCtx, tempDir)
+
+		close(started)
+		<-cleanupCtx.Done()
+		operationErr <- cleanupCtx.Err()
+	}()
+
+	<-started
+	cancel()
+	require.ErrorIs(t, <-operationErr, context.Canceled)
+	done()
+
+	for _, path := range paths {
+		require.NoFileExists(t, path)
+		require.NoDirExists(t, path)
+	}
+}
+
+func Test_WithCancelCleanup_Completed(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cleanupCtx, done := WithCancelCleanup(ctx)
+	path := createTempFile(t, dir, "package.zip")
+	RemoveOnCancel(cleanupCtx, path)
+
+	// Artifacts of a completed operation, like the output of 'azd package', are kept
+	done()
+	cancel()
+	require.FileExists(t, path)
+}
+
+func Test_Registry_RunsOnce(t *testing.T) {
+	registry := NewRegistry()
+	var calls atomic.Int32
+	var order []int
+
+	for i := 0; i < 3; i++ {
+		i := i
+		registry.Register(func() error {
+			calls.Add(1)
+			order = append(order, i)
+			return nil
+		})
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, registry.Run())
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, int32(3), calls.Load())
+	require.Equal(t, []int{2, 1, 0}, order)
+
+	// Callbacks registered once the registry ran are run right away
+	registry.Register(func() error {
+		calls.Add(1)
+		return nil
+	})
+	require.Equal(t, int32(4), calls.Load())
+}
+
+func Test_Registry_ConcurrentRegistration(t *testing.T) {
+	registry := NewRegistry()
+	var calls atomic.Int32
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			registry.Register(func() error {
+				calls.Add(1)
+				return nil
+			})
+		}()
+	}
+
+	// Registrations racing with the run are either run by it or right away, never skipped or run twice
+	go func() {
+		_ = registry.Run()
+	}()
+	wg.Wait()
+	require.NoError(t, registry.Run())
+
+	require.Equal(t, int32(50), calls.Load())
+}
+
+func Test_Registry_Errors(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(func() error { return errors.New("first") })
+	registry.Register(func() error { return nil })
+	registry.Register(func() error { return errors.New("second") })
+
+	err := registry.Run()
+	require.ErrorContains(t, err, "first")
+	require.ErrorContains(t, err, "second")
+}
+
+func Test_Register_WithoutRegistry(t *testing.T) {
+	// Registering without a registry is a no-op, the caller stays responsible for the artifact
+	path := createTempFile(t, t.TempDir(), "script.sh")
+	RemoveOnCancel(context.Background(), path)
+	require.FileExists(t, path)
+}
@@ -33,6 +33,9 @@ type Cloud struct {
 	// known values and can be found at:
 	// https://<management-endpoint>/metadata/endpoints?api-version=2023-12-01
 	ContainerRegistryEndpointSuffix string
+
+	// The endpoint of the Log Analytics query API (e.g. https://api.loganalytics.io for Azure public cloud).
+	LogAnalyticsEndpoint string
 }
 
 type Config struct {
@@ -68,6 +71,7 @@ func AzurePublic() *Cloud {
 		PortalUrlBase:                   "https://portal.azure.com",
 		StorageEndpointSuffix:           "core.windows.net",
 		ContainerRegistryEndpointSuffix: "azurecr.io",
+		LogAnalyticsEndpoint:            "https://api.loganalytics.io",
 	}
 }
 
@@ -77,6 +81,7 @@ func AzureGovernment() *Cloud {
 		PortalUrlBase:                   "https://portal.azure.us",
 		StorageEndpointSuffix:           "core.usgovcloudapi.net",
 		ContainerRegistryEndpointSuffix: "azurecr.us",
+		LogAnalyticsEndpoint:            "https://api.loganalytics.us",
 	}
 }
 
@@ -86,6 +91,7 @@ func AzureChina() *Cloud {
 		PortalUrlBase:                   "https://portal.azure.cn",
 		StorageEndpointSuffix:           "core.chinacloudapi.cn",
 		ContainerRegistryEndpointSuffix: "azurecr.cn",
+		LogAnalyticsEndpoint:            "https://api.loganalytics.azure.cn",
 	}
 }
 
@@ -5,9 +5,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"regexp"
 	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
@@ -38,19 +42,39 @@ type ContainerAppService interface {
 		appName string,
 		containerAppYaml []byte,
 	) error
-	// Adds and activates a new revision to the specified container app
+	// Adds and activates a new revision to the specified container app, configured with the options, and returns the
+	// name of the revision. When registry credentials are provided, the container app is configured to pull the image
+	// from that registry with them.
 	AddRevision(
 		ctx context.Context,
 		subscriptionId string,
 		resourceGroupName string,
 		appName string,
 		imageName string,
-	) error
+		options RevisionOptions,
+		registry *RegistryCredentials,
+	) (string, error)
 	ListSecrets(ctx context.Context,
 		subscriptionId string,
 		resourceGroupName string,
 		appName string,
 	) ([]*armappcontainers.ContainerAppSecret, error)
+	// Gets the name of the latest ready revision of the specified container app
+	GetActiveRevision(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		appName string,
+	) (string, error)
+	// Opens a stream that follows the console logs of the specified revision
+	OpenLogStream(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		appName string,
+		revisionName string,
+		tailLines int,
+	) (io.ReadCloser, error)
 }
 
 // NewContainerAppService creates a new ContainerAppService
@@ -77,10 +101,119 @@ type containerAppService struct {
 	armClientOptions   *arm.ClientOptions
 }
 
+// RevisionOptions configures the revision added to a container app.
+type RevisionOptions struct {
+	// The suffix of the name of the revision. Defaults to a suffix based on the current time
+	Suffix string
+	// The ingress of the container app. The ingress is unchanged when nil
+	Ingress *IngressOptions
+	// The scale of the revision. The scale of the previous revision is kept when nil
+	Scale *ScaleOptions
+	// The Dapr settings of the container app. The Dapr settings are unchanged when nil
+	Dapr *DaprOptions
+	// The environment variables of the main container of the revision, merged with the ones of the previous revision
+	Env map[string]string
+	// The environment variables of the main container of the revision whose values are secrets, like secure outputs.
+	// The values are stored as container app secrets, which the variables reference
+	SecretEnv map[string]string
+	// The containers running alongside the main container of the revision, like sidecars. The containers of the
+	// previous revision are updated by name, and the other containers are added
+	Containers []ContainerOptions
+}
+
+// ContainerOptions are the settings of a container running alongside the main container of a revision.
+type ContainerOptions struct {
+	// The name of the container, unique in the revision
+	Name string
+	// The image of the container
+	Image string
+	// The environment variables of the container, merged with the ones of the previous revision
+	Env map[string]string
+	// The number of CPU cores allocated to the container. Unchanged when 0
+	Cpu float64
+	// The memory allocated to the container, like '0.5Gi'. Unchanged when empty
+	Memory string
+}
+
+// IngressOptions are the settings of the ingress of a container app, unchanged when nil.
+type IngressOptions struct {
+	// Whether the container app is accessible from the internet, or only from its container apps environment
+	External *bool
+	// The port of the container receiving the requests
+	TargetPort *int32
+}
+
+// ScaleOptions are the settings of the scale of a revision, unchanged when nil.
+type ScaleOptions struct {
+	// The minimum number of replicas
+	MinReplicas *int32
+	// The maximum number of replicas
+	MaxReplicas *int32
+	// The number of concurrent HTTP requests per replica above which the revision scales out
+	ConcurrentRequests *int32
+}
+
+// DaprOptions are the Dapr settings of a container app.
+type DaprOptions struct {
+	// Whether the Dapr sidecar runs alongside the container app. The state of the container app is kept when nil, and
+	// Dapr is enabled when the container app doesn't have Dapr settings yet
+	Enabled *bool
+	// The Dapr application identifier of the container app. The identifier of the container app is kept when empty
+	AppId string
+	// The Dapr application identifier used when neither AppId nor the container app have one
+	DefaultAppId string
+	// The port the container app listens on, which the Dapr sidecar sends requests to
+	AppPort *int32
+}
+
+// httpScaleRuleName is the name of the HTTP scale rule created by azd when the revision doesn't have one.
+const httpScaleRuleName = "http-scaling"
+
+// revisionSuffixRegex matches the suffixes accepted by Azure Container Apps: lower case alphanumeric characters or
+// '-', starting with a letter and ending with an alphanumeric character.
+var revisionSuffixRegex = regexp.MustCompile(`^[a-z]([a-z0-9-]*[a-z0-9])?$`)
+
+// maxRevisionNameLength is the maximum length of the name of a revision, '<app name>--<revision suffix>'.
+const maxRevisionNameLength = 64
+
+// ValidateRevisionSuffix checks that the revision suffix is accepted by Azure Container Apps for the container app.
+func ValidateRevisionSuffix(appName string, revisionSuffix string) error {
+	if !revisionSuffixRegex.MatchString(revisionSuffix) || strings.Contains(revisionSuffix, "--") {
+		return fmt.Errorf(
+			"invalid revision suffix '%s', it must consist of lower case alphanumeric characters or '-', "+
+				"start with a letter, end with an alphanumeric character and not contain '--'",
+			revisionSuffix,
+		)
+	}
+
+	if revisionName := revisionName(appName, revisionSuffix); len(revisionName) > maxRevisionNameLength {
+		return fmt.Errorf(
+			"invalid revision suffix '%s', the name of the revision '%s' exceeds %d characters",
+			revisionSuffix,
+			revisionName,
+			maxRevisionNameLength,
+		)
+	}
+
+	return nil
+}
+
+func revisionName(appName string, revisionSuffix string) string {
+	return fmt.Sprintf("%s--%s", appName, revisionSuffix)
+}
+
 type ContainerAppIngressConfiguration struct {
 	HostNames []string
 }
 
+// RegistryCredentials are the credentials a container app uses to pull images from a registry other than Azure
+// Container Registry.
+type RegistryCredentials struct {
+	Server   string
+	Username string
+	Password string
+}
+
 // Gets the ingress configuration for the specified container app
 func (cas *containerAppService) GetIngressConfiguration(
 	ctx context.Context,
@@ -208,52 +341,83 @@ func (cas *containerAppService) AddRevision(
 	resourceGroupName string,
 	appName string,
 	imageName string,
-) error {
+	options RevisionOptions,
+	registry *RegistryCredentials,
+) (string, error) {
+	revisionSuffix := options.Suffix
+	if revisionSuffix == "" {
+		revisionSuffix = fmt.Sprintf("azd-%d", cas.clock.Now().Unix())
+	} else if err := ValidateRevisionSuffix(appName, revisionSuffix); err != nil {
+		return "", err
+	}
+
 	containerApp, err := cas.getContainerApp(ctx, subscriptionId, resourceGroupName, appName)
 	if err != nil {
-		return fmt.Errorf("getting container app: %w", err)
+		return "", fmt.Errorf("getting container app: %w", err)
 	}
 
 	// Get the latest revision name
 	currentRevisionName := *containerApp.Properties.LatestRevisionName
 	revisionsClient, err := cas.createRevisionsClient(ctx, subscriptionId)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	revisionResponse, err := revisionsClient.GetRevision(ctx, resourceGroupName, appName, currentRevisionName, nil)
 	if err != nil {
-		return fmt.Errorf("getting revision '%s': %w", currentRevisionName, err)
+		return "", fmt.Errorf("getting revision '%s': %w", currentRevisionName, err)
 	}
 
 	// Update the revision with the new image name and suffix
 	revision := revisionResponse.Revision
-	revision.Properties.Template.RevisionSuffix = convert.RefOf(fmt.Sprintf("azd-%d", cas.clock.Now().Unix()))
+	revision.Properties.Template.RevisionSuffix = convert.RefOf(revisionSuffix)
 	revision.Properties.Template.Containers[0].Image = convert.RefOf(imageName)
+	setEnv(revision.Properties.Template.Containers[0], options.Env)
 
 	// Update the container app with the new revision
 	containerApp.Properties.Template = revision.Properties.Template
+	if options.Ingress != nil {
+		setIngress(containerApp, options.Ingress)
+	}
+	if options.Scale != nil {
+		setScale(containerApp.Properties.Template, options.Scale)
+	}
+	if options.Dapr != nil {
+		setDapr(containerApp, options.Dapr)
+	}
+	for _, container := range options.Containers {
+		setContainer(containerApp.Properties.Template, container)
+	}
+
 	containerApp, err = cas.syncSecrets(ctx, subscriptionId, resourceGroupName, appName, containerApp)
 	if err != nil {
-		return fmt.Errorf("syncing secrets: %w", err)
+		return "", fmt.Errorf("syncing secrets: %w", err)
+	}
+
+	// The secrets are set once the existing ones are synced, which replaces them
+	setSecretEnv(containerApp, containerApp.Properties.Template.Containers[0], options.SecretEnv)
+
+	if registry != nil {
+		setRegistryCredentials(containerApp, registry)
 	}
 
 	// Update the container app
 	err = cas.updateContainerApp(ctx, subscriptionId, resourceGroupName, appName, containerApp)
 	if err != nil {
-		return fmt.Errorf("updating container app revision: %w", err)
+		return "", fmt.Errorf("updating container app revision: %w", err)
 	}
 
+	newRevisionName := revisionName(appName, revisionSuffix)
+
 	// If the container app is in multiple revision mode, update the traffic to point to the new revision
 	if *containerApp.Properties.Configuration.ActiveRevisionsMode == armappcontainers.ActiveRevisionsModeMultiple {
-		newRevisionName := fmt.Sprintf("%s--%s", appName, *revision.Properties.Template.RevisionSuffix)
 		err = cas.setTrafficWeights(ctx, subscriptionId, resourceGroupName, appName, containerApp, newRevisionName)
 		if err != nil {
-			return fmt.Errorf("setting traffic weights: %w", err)
+			return "", fmt.Errorf("setting traffic weights: %w", err)
 		}
 	}
 
-	return nil
+	return newRevisionName, nil
 }
 
 func (cas *containerAppService) ListSecrets(
@@ -313,6 +477,257 @@ func (cas *containerAppService) syncSecrets(
 	return containerApp, nil
 }
 
+// setRegistryCredentials stores the registry password as a container app secret and adds or replaces the registry
+// configuration for the registry server to reference it.
+func setRegistryCredentials(containerApp *armappcontainers.ContainerApp, registry *RegistryCredentials) {
+	configuration := containerApp.Properties.Configuration
+	secretName := registrySecretName(registry.Server)
+	setSecret(configuration, secretName, registry.Password)
+
+	registries := []*armappcontainers.RegistryCredentials{}
+	for _, existing := range configuration.Registries {
+		if existing.Server == nil || !strings.EqualFold(*existing.Server, registry.Server) {
+			registries = append(registries, existing)
+		}
+	}
+	configuration.Registries = append(registries, &armappcontainers.RegistryCredentials{
+		Server:            convert.RefOf(registry.Server),
+		Username:          convert.RefOf(registry.Username),
+		PasswordSecretRef: convert.RefOf(secretName),
+	})
+}
+
+// setSecret adds the secret to the configuration of the container app, replacing the secret with the same name.
+func setSecret(configuration *armappcontainers.Configuration, name string, value string) {
+	secrets := []*armappcontainers.Secret{}
+	for _, secret := range configuration.Secrets {
+		if secret.Name == nil || *secret.Name != name {
+			secrets = append(secrets, secret)
+		}
+	}
+	configuration.Secrets = append(secrets, &armappcontainers.Secret{
+		Name:  convert.RefOf(name),
+		Value: convert.RefOf(value),
+	})
+}
+
+// setIngress applies the ingress settings to the container app, enabling its ingress when it doesn't have one.
+func setIngress(containerApp *armappcontainers.ContainerApp, options *IngressOptions) {
+	if containerApp.Properties.Configuration == nil {
+		containerApp.Properties.Configuration = &armappcontainers.Configuration{}
+	}
+
+	configuration := containerApp.Properties.Configuration
+	if configuration.Ingress == nil {
+		configuration.Ingress = &armappcontainers.Ingress{}
+	}
+
+	if options.External != nil {
+		configuration.Ingress.External = options.External
+	}
+	if options.TargetPort != nil {
+		configuration.Ingress.TargetPort = options.TargetPort
+	}
+}
+
+// setDapr applies the Dapr settings to the container app, keeping the settings of the container app which aren't set,
+// like the ones set by the infrastructure. Disabling Dapr keeps the other Dapr settings.
+func setDapr(containerApp *armappcontainers.ContainerApp, options *DaprOptions) {
+	if containerApp.Properties.Configuration == nil {
+		containerApp.Properties.Configuration = &armappcontainers.Configuration{}
+	}
+
+	configuration := containerApp.Properties.Configuration
+	if configuration.Dapr == nil {
+		configuration.Dapr = &armappcontainers.Dapr{}
+	}
+
+	if options.Enabled != nil {
+		configuration.Dapr.Enabled = options.Enabled
+	} else if configuration.Dapr.Enabled == nil {
+		configuration.Dapr.Enabled = convert.RefOf(true)
+	}
+
+	if options.AppId != "" {
+		configuration.Dapr.AppID = convert.RefOf(options.AppId)
+	} else if (configuration.Dapr.AppID == nil || *configuration.Dapr.AppID == "") && options.DefaultAppId != "" {
+		configuration.Dapr.AppID = convert.RefOf(options.DefaultAppId)
+	}
+	if options.AppPort != nil {
+		configuration.Dapr.AppPort = options.AppPort
+	}
+}
+
+// setContainer updates the container of the template with the same name as options, or adds it after the main
+// container of the template when there's none.
+func setContainer(template *armappcontainers.Template, options ContainerOptions) {
+	var container *armappcontainers.Container
+	for _, c := range template.Containers[1:] {
+		if c.Name != nil && *c.Name == options.Name {
+			container = c
+			break
+		}
+	}
+
+	if container == nil {
+		container = &armappcontainers.Container{
+			Name: convert.RefOf(options.Name),
+		}
+		template.Containers = append(template.Containers, container)
+	}
+
+	container.Image = convert.RefOf(options.Image)
+
+	setEnv(container, options.Env)
+
+	if options.Cpu == 0 && options.Memory == "" {
+		return
+	}
+
+	if container.Resources == nil {
+		container.Resources = &armappcontainers.ContainerResources{}
+	}
+	if options.Cpu != 0 {
+		container.Resources.CPU = convert.RefOf(options.Cpu)
+	}
+	if options.Memory != "" {
+		container.Resources.Memory = convert.RefOf(options.Memory)
+	}
+}
+
+// setEnv sets the environment variables of the container, replacing the values of the existing variables, including
+// the ones referencing secrets.
+func setEnv(container *armappcontainers.Container, env map[string]string) {
+	// Sort the names so the environment variables added to the container are in a stable order
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		value := convert.RefOf(env[name])
+		idx := slices.IndexFunc(container.Env, func(v *armappcontainers.EnvironmentVar) bool {
+			return v.Name != nil && *v.Name == name
+		})
+
+		if idx >= 0 {
+			container.Env[idx].Value = value
+			container.Env[idx].SecretRef = nil
+		} else {
+			container.Env = append(container.Env, &armappcontainers.EnvironmentVar{
+				Name:  convert.RefOf(name),
+				Value: value,
+			})
+		}
+	}
+}
+
+// setSecretEnv stores the values of the environment variables as secrets of the container app, and sets the environment
+// variables of the container to reference them, replacing the values of the existing variables.
+func setSecretEnv(
+	containerApp *armappcontainers.ContainerApp,
+	container *armappcontainers.Container,
+	env map[string]string,
+) {
+	if len(env) == 0 {
+		return
+	}
+
+	if containerApp.Properties.Configuration == nil {
+		containerApp.Properties.Configuration = &armappcontainers.Configuration{}
+	}
+
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		secretName := envSecretName(name)
+		setSecret(containerApp.Properties.Configuration, secretName, env[name])
+
+		idx := slices.IndexFunc(container.Env, func(v *armappcontainers.EnvironmentVar) bool {
+			return v.Name != nil && *v.Name == name
+		})
+
+		if idx >= 0 {
+			container.Env[idx].Value = nil
+			container.Env[idx].SecretRef = convert.RefOf(secretName)
+		} else {
+			container.Env = append(container.Env, &armappcontainers.EnvironmentVar{
+				Name:      convert.RefOf(name),
+				SecretRef: convert.RefOf(secretName),
+			})
+		}
+	}
+}
+
+// setScale applies the scale settings to the template of a revision. The concurrent requests are set on the HTTP scale
+// rule of the revision, which is created when the revision doesn't have one.
+func setScale(template *armappcontainers.Template, options *ScaleOptions) {
+	if template.Scale == nil {
+		template.Scale = &armappcontainers.Scale{}
+	}
+
+	scale := template.Scale
+	if options.MinReplicas != nil {
+		scale.MinReplicas = options.MinReplicas
+	}
+	if options.MaxReplicas != nil {
+		scale.MaxReplicas = options.MaxReplicas
+	}
+
+	if options.ConcurrentRequests == nil {
+		return
+	}
+
+	concurrentRequests := convert.RefOf(strconv.Itoa(int(*options.ConcurrentRequests)))
+	for _, rule := range scale.Rules {
+		if rule.HTTP != nil {
+			if rule.HTTP.Metadata == nil {
+				rule.HTTP.Metadata = map[string]*string{}
+			}
+
+			rule.HTTP.Metadata["concurrentRequests"] = concurrentRequests
+			return
+		}
+	}
+
+	scale.Rules = append(scale.Rules, &armappcontainers.ScaleRule{
+		Name: convert.RefOf(httpScaleRuleName),
+		HTTP: &armappcontainers.HTTPScaleRule{
+			Metadata: map[string]*string{
+				"concurrentRequests": concurrentRequests,
+			},
+		},
+	})
+}
+
+// registrySecretName returns the name of the secret holding the password for the registry server.
+func registrySecretName(server string) string {
+	return fmt.Sprintf("azd-registry-%s", secretNamePart(server))
+}
+
+// envSecretName returns the name of the secret holding the value of the environment variable.
+func envSecretName(name string) string {
+	return fmt.Sprintf("azd-env-%s", secretNamePart(name))
+}
+
+// secretNamePart converts value to a part of a secret name. Secret names may only contain lower case alphanumeric
+// characters and '-'.
+func secretNamePart(value string) string {
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(value))
+
+	return strings.Trim(name, "-")
+}
+
 func (cas *containerAppService) setTrafficWeights(
 	ctx context.Context,
 	subscriptionId string,
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appcontainers/armappcontainers/v3"
@@ -138,8 +139,10 @@ func Test_ContainerApp_AddRevision(t *testing.T) {
 		clock.NewMock(),
 		mockContext.ArmClientOptions,
 	)
-	err := cas.AddRevision(*mockContext.Context, subscriptionId, resourceGroup, appName, updatedImageName)
+	revisionName, err := cas.AddRevision(
+		*mockContext.Context, subscriptionId, resourceGroup, appName, updatedImageName, RevisionOptions{}, nil)
 	require.NoError(t, err)
+	require.Equal(t, "APP_NAME--azd-0", revisionName)
 
 	// Verify lastest revision is read
 	expectedGetRevisionPath := fmt.Sprintf(
@@ -160,3 +163,345 @@ func Test_ContainerApp_AddRevision(t *testing.T) {
 	require.Equal(t, updatedImageName, *updatedContainerApp.Properties.Template.Containers[0].Image)
 	require.Equal(t, "azd-0", *updatedContainerApp.Properties.Template.RevisionSuffix)
 }
+
+// addRevision adds a revision with the options to the container app named 'api', whose latest revision is the
+// revision, and returns the container app sent by the update request.
+func addRevision(
+	t *testing.T,
+	configuration *armappcontainers.Configuration,
+	revision *armappcontainers.Revision,
+	options RevisionOptions,
+) *armappcontainers.ContainerApp {
+	subscriptionId := "SUBSCRIPTION_ID"
+	resourceGroup := "RESOURCE_GROUP"
+	appName := "api"
+	revisionName := "api--azd-0"
+
+	configuration.ActiveRevisionsMode = convert.RefOf(armappcontainers.ActiveRevisionsModeSingle)
+	containerApp := &armappcontainers.ContainerApp{
+		Location: convert.RefOf("eastus2"),
+		Name:     &appName,
+		Properties: &armappcontainers.ContainerAppProperties{
+			LatestRevisionName: &revisionName,
+			Configuration:      configuration,
+		},
+	}
+
+	mockContext := mocks.NewMockContext(context.Background())
+	_ = mockazsdk.MockContainerAppGet(mockContext, subscriptionId, resourceGroup, appName, containerApp)
+	_ = mockazsdk.MockContainerAppRevisionGet(mockContext, subscriptionId, resourceGroup, appName, revisionName, revision)
+	_ = mockazsdk.MockContainerAppSecretsList(
+		mockContext, subscriptionId, resourceGroup, appName, &armappcontainers.SecretsCollection{})
+	updateContainerAppRequest := mockazsdk.MockContainerAppUpdate(
+		mockContext,
+		subscriptionId,
+		resourceGroup,
+		appName,
+		containerApp,
+	)
+
+	cas := NewContainerAppService(
+		mockContext.SubscriptionCredentialProvider,
+		mockContext.HttpClient,
+		clock.NewMock(),
+		mockContext.ArmClientOptions,
+	)
+	_, err := cas.AddRevision(
+		*mockContext.Context, subscriptionId, resourceGroup, appName, "UPDATED_IMAGE_NAME", options, nil)
+	require.NoError(t, err)
+
+	var updatedContainerApp *armappcontainers.ContainerApp
+	require.NoError(t, json.NewDecoder(updateContainerAppRequest.Body).Decode(&updatedContainerApp))
+
+	return updatedContainerApp
+}
+
+func newTestRevision() *armappcontainers.Revision {
+	return &armappcontainers.Revision{
+		Properties: &armappcontainers.RevisionProperties{
+			Template: &armappcontainers.Template{
+				Containers: []*armappcontainers.Container{
+					{Image: convert.RefOf("ORIGINAL_IMAGE_NAME")},
+				},
+				Scale: &armappcontainers.Scale{
+					MinReplicas: convert.RefOf[int32](1),
+					MaxReplicas: convert.RefOf[int32](10),
+				},
+			},
+		},
+	}
+}
+
+func Test_ContainerApp_AddRevision_IngressAndScale(t *testing.T) {
+	configuration := &armappcontainers.Configuration{
+		Ingress: &armappcontainers.Ingress{
+			External:   convert.RefOf(true),
+			TargetPort: convert.RefOf[int32](80),
+		},
+	}
+
+	updatedContainerApp := addRevision(t, configuration, newTestRevision(), RevisionOptions{
+		Suffix: "v2",
+		Ingress: &IngressOptions{
+			External:   convert.RefOf(false),
+			TargetPort: convert.RefOf[int32](8080),
+		},
+		Scale: &ScaleOptions{
+			MaxReplicas:        convert.RefOf[int32](5),
+			ConcurrentRequests: convert.RefOf[int32](50),
+		},
+	})
+
+	ingress := updatedContainerApp.Properties.Configuration.Ingress
+	require.False(t, *ingress.External)
+	require.Equal(t, int32(8080), *ingress.TargetPort)
+
+	template := updatedContainerApp.Properties.Template
+	require.Equal(t, "v2", *template.RevisionSuffix)
+	// The settings which aren't set are kept from the previous revision
+	require.Equal(t, int32(1), *template.Scale.MinReplicas)
+	require.Equal(t, int32(5), *template.Scale.MaxReplicas)
+	require.Len(t, template.Scale.Rules, 1)
+	require.Equal(t, "50", *template.Scale.Rules[0].HTTP.Metadata["concurrentRequests"])
+}
+
+func Test_ContainerApp_AddRevision_Dapr(t *testing.T) {
+	t.Run("Enabled", func(t *testing.T) {
+		updatedContainerApp := addRevision(t, &armappcontainers.Configuration{}, newTestRevision(), RevisionOptions{
+			Dapr: &DaprOptions{
+				Enabled: convert.RefOf(true),
+				AppId:   "orders",
+				AppPort: convert.RefOf[int32](3000),
+			},
+		})
+
+		dapr := updatedContainerApp.Properties.Configuration.Dapr
+		require.True(t, *dapr.Enabled)
+		require.Equal(t, "orders", *dapr.AppID)
+		require.Equal(t, int32(3000), *dapr.AppPort)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		configuration := &armappcontainers.Configuration{
+			Dapr: &armappcontainers.Dapr{
+				Enabled: convert.RefOf(true),
+				AppID:   convert.RefOf("orders"),
+				AppPort: convert.RefOf[int32](3000),
+			},
+		}
+
+		updatedContainerApp := addRevision(t, configuration, newTestRevision(), RevisionOptions{
+			Dapr: &DaprOptions{Enabled: convert.RefOf(false)},
+		})
+
+		dapr := updatedContainerApp.Properties.Configuration.Dapr
+		require.False(t, *dapr.Enabled)
+		require.Equal(t, "orders", *dapr.AppID)
+	})
+
+	t.Run("KeepsInfraSettings", func(t *testing.T) {
+		configuration := &armappcontainers.Configuration{
+			Dapr: &armappcontainers.Dapr{
+				Enabled: convert.RefOf(false),
+				AppID:   convert.RefOf("orders"),
+			},
+		}
+
+		updatedContainerApp := addRevision(t, configuration, newTestRevision(), RevisionOptions{
+			Dapr: &DaprOptions{DefaultAppId: "api", AppPort: convert.RefOf[int32](3000)},
+		})
+
+		dapr := updatedContainerApp.Properties.Configuration.Dapr
+		require.False(t, *dapr.Enabled)
+		require.Equal(t, "orders", *dapr.AppID)
+		require.Equal(t, int32(3000), *dapr.AppPort)
+	})
+
+	t.Run("Defaults", func(t *testing.T) {
+		updatedContainerApp := addRevision(t, &armappcontainers.Configuration{}, newTestRevision(), RevisionOptions{
+			Dapr: &DaprOptions{DefaultAppId: "api", AppPort: convert.RefOf[int32](3000)},
+		})
+
+		dapr := updatedContainerApp.Properties.Configuration.Dapr
+		require.True(t, *dapr.Enabled)
+		require.Equal(t, "api", *dapr.AppID)
+	})
+
+	t.Run("Unchanged", func(t *testing.T) {
+		updatedContainerApp := addRevision(t, &armappcontainers.Configuration{}, newTestRevision(), RevisionOptions{})
+		require.Nil(t, updatedContainerApp.Properties.Configuration.Dapr)
+	})
+}
+
+func Test_ContainerApp_AddRevision_Containers(t *testing.T) {
+	revision := newTestRevision()
+	revision.Properties.Template.Containers = append(revision.Properties.Template.Containers,
+		&armappcontainers.Container{
+			Name:  convert.RefOf("logger"),
+			Image: convert.RefOf("ORIGINAL_LOGGER_IMAGE"),
+			Env: []*armappcontainers.EnvironmentVar{
+				{Name: convert.RefOf("LEVEL"), Value: convert.RefOf("debug")},
+				{Name: convert.RefOf("TOKEN"), SecretRef: convert.RefOf("token")},
+			},
+			Resources: &armappcontainers.ContainerResources{
+				CPU:    convert.RefOf(0.25),
+				Memory: convert.RefOf("0.5Gi"),
+			},
+		},
+	)
+
+	updatedContainerApp := addRevision(t, &armappcontainers.Configuration{}, revision, RevisionOptions{
+		Containers: []ContainerOptions{
+			{
+				Name:  "logger",
+				Image: "LOGGER_IMAGE",
+				Env:   map[string]string{"LEVEL": "info"},
+			},
+			{
+				Name:   "proxy",
+				Image:  "PROXY_IMAGE",
+				Env:    map[string]string{"PORT": "8080", "HOST": "localhost"},
+				Cpu:    0.5,
+				Memory: "1Gi",
+			},
+		},
+	})
+
+	containers := updatedContainerApp.Properties.Template.Containers
+	require.Len(t, containers, 3)
+	require.Equal(t, "UPDATED_IMAGE_NAME", *containers[0].Image)
+
+	// The existing container is updated, keeping its other settings
+	logger := containers[1]
+	require.Equal(t, "LOGGER_IMAGE", *logger.Image)
+	require.Equal(t, []*armappcontainers.EnvironmentVar{
+		{Name: convert.RefOf("LEVEL"), Value: convert.RefOf("info")},
+		{Name: convert.RefOf("TOKEN"), SecretRef: convert.RefOf("token")},
+	}, logger.Env)
+	require.Equal(t, 0.25, *logger.Resources.CPU)
+	require.Equal(t, "0.5Gi", *logger.Resources.Memory)
+
+	// The new container is added after the main container
+	proxy := containers[2]
+	require.Equal(t, "proxy", *proxy.Name)
+	require.Equal(t, "PROXY_IMAGE", *proxy.Image)
+	require.Equal(t, []*armappcontainers.EnvironmentVar{
+		{Name: convert.RefOf("HOST"), Value: convert.RefOf("localhost")},
+		{Name: convert.RefOf("PORT"), Value: convert.RefOf("8080")},
+	}, proxy.Env)
+	require.Equal(t, 0.5, *proxy.Resources.CPU)
+	require.Equal(t, "1Gi", *proxy.Resources.Memory)
+}
+
+func Test_ContainerApp_AddRevision_Env(t *testing.T) {
+	revision := newTestRevision()
+	revision.Properties.Template.Containers[0].Env = []*armappcontainers.EnvironmentVar{
+		{Name: convert.RefOf("PORT"), Value: convert.RefOf("8080")},
+		{Name: convert.RefOf("DATABASE_URL"), SecretRef: convert.RefOf("database-url")},
+	}
+
+	updatedContainerApp := addRevision(t, &armappcontainers.Configuration{}, revision, RevisionOptions{
+		Env: map[string]string{"DATABASE_URL": "postgres://db", "CACHE_HOST": "cache"},
+	})
+
+	// The variables of the main container are merged with the ones of the previous revision
+	require.Equal(t, []*armappcontainers.EnvironmentVar{
+		{Name: convert.RefOf("PORT"), Value: convert.RefOf("8080")},
+		{Name: convert.RefOf("DATABASE_URL"), Value: convert.RefOf("postgres://db")},
+		{Name: convert.RefOf("CACHE_HOST"), Value: convert.RefOf("cache")},
+	}, updatedContainerApp.Properties.Template.Containers[0].Env)
+}
+
+func Test_ContainerApp_AddRevision_SecretEnv(t *testing.T) {
+	revision := newTestRevision()
+	revision.Properties.Template.Containers[0].Env = []*armappcontainers.EnvironmentVar{
+		{Name: convert.RefOf("PORT"), Value: convert.RefOf("8080")},
+		{Name: convert.RefOf("DATABASE_URL"), Value: convert.RefOf("postgres://old")},
+	}
+
+	updatedContainerApp := addRevision(t, &armappcontainers.Configuration{}, revision, RevisionOptions{
+		Env:       map[string]string{"CACHE_HOST": "cache"},
+		SecretEnv: map[string]string{"DATABASE_URL": "postgres://db", "API_KEY": "key"},
+	})
+
+	// The secret values are stored as secrets, which the variables reference instead of holding the values
+	require.Equal(t, []*armappcontainers.Secret{
+		{Name: convert.RefOf("azd-env-api-key"), Value: convert.RefOf("key")},
+		{Name: convert.RefOf("azd-env-database-url"), Value: convert.RefOf("postgres://db")},
+	}, updatedContainerApp.Properties.Configuration.Secrets)
+	require.Equal(t, []*armappcontainers.EnvironmentVar{
+		{Name: convert.RefOf("PORT"), Value: convert.RefOf("8080")},
+		{Name: convert.RefOf("DATABASE_URL"), SecretRef: convert.RefOf("azd-env-database-url")},
+		{Name: convert.RefOf("CACHE_HOST"), Value: convert.RefOf("cache")},
+		{Name: convert.RefOf("API_KEY"), SecretRef: convert.RefOf("azd-env-api-key")},
+	}, updatedContainerApp.Properties.Template.Containers[0].Env)
+}
+
+func Test_ContainerApp_ValidateRevisionSuffix(t *testing.T) {
+	tests := []struct {
+		name           string
+		appName        string
+		revisionSuffix string
+		expectedErr    string
+	}{
+		{name: "Valid", appName: "api", revisionSuffix: "v1-2-3"},
+		{name: "SingleLetter", appName: "api", revisionSuffix: "a"},
+		{name: "UpperCase", appName: "api", revisionSuffix: "V1", expectedErr: "lower case alphanumeric characters"},
+		{name: "StartsWithDigit", appName: "api", revisionSuffix: "1abc", expectedErr: "start with a letter"},
+		{name: "EndsWithDash", appName: "api", revisionSuffix: "abc-", expectedErr: "end with an alphanumeric"},
+		{name: "DoubleDash", appName: "api", revisionSuffix: "a--b", expectedErr: "not contain '--'"},
+		{name: "InvalidCharacter", appName: "api", revisionSuffix: "a.b", expectedErr: "invalid revision suffix"},
+		{
+			name:           "TooLong",
+			appName:        strings.Repeat("a", 40),
+			revisionSuffix: "g" + strings.Repeat("0", 30),
+			expectedErr:    "exceeds 64 characters",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRevisionSuffix(tt.appName, tt.revisionSuffix)
+			if tt.expectedErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func Test_ContainerApp_SetRegistryCredentials(t *testing.T) {
+	containerApp := &armappcontainers.ContainerApp{
+		Properties: &armappcontainers.ContainerAppProperties{
+			Configuration: &armappcontainers.Configuration{
+				Secrets: []*armappcontainers.Secret{
+					{Name: convert.RefOf("secret"), Value: convert.RefOf("value")},
+					{Name: convert.RefOf("azd-registry-ghcr-io"), Value: convert.RefOf("old-token")},
+				},
+				Registries: []*armappcontainers.RegistryCredentials{
+					{Server: convert.RefOf("contoso.azurecr.io"), Identity: convert.RefOf("system")},
+					{Server: convert.RefOf("ghcr.io"), Username: convert.RefOf("old-user")},
+				},
+			},
+		},
+	}
+
+	setRegistryCredentials(containerApp, &RegistryCredentials{
+		Server:   "ghcr.io",
+		Username: "user",
+		Password: "token",
+	})
+
+	configuration := containerApp.Properties.Configuration
+	require.Len(t, configuration.Secrets, 2)
+	require.Equal(t, "secret", *configuration.Secrets[0].Name)
+	require.Equal(t, "azd-registry-ghcr-io", *configuration.Secrets[1].Name)
+	require.Equal(t, "token", *configuration.Secrets[1].Value)
+
+	require.Len(t, configuration.Registries, 2)
+	require.Equal(t, "contoso.azurecr.io", *configuration.Registries[0].Server)
+	require.Equal(t, "ghcr.io", *configuration.Registries[1].Server)
+	require.Equal(t, "user", *configuration.Registries[1].Username)
+	require.Equal(t, "azd-registry-ghcr-io", *configuration.Registries[1].PasswordSecretRef)
+}
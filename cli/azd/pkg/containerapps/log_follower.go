@@ -0,0 +1,217 @@
+package containerapps
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/clock"
+)
+
+const (
+	// The number of history lines requested when a log stream is opened.
+	defaultLogTailLines = 300
+	// How often the active revision is checked while following logs.
+	defaultLogPollInterval = 10 * time.Second
+)
+
+// LogSource provides the console logs of the revisions of a container app.
+type LogSource interface {
+	// ActiveRevision returns the name of the revision currently receiving traffic.
+	ActiveRevision(ctx context.Context) (string, error)
+	// Open opens a stream of log lines for the revision that follows new lines until closed.
+	Open(ctx context.Context, revisionName string, tailLines int) (io.ReadCloser, error)
+}
+
+// NewLogSource returns a [LogSource] for the container app backed by the container app service.
+func NewLogSource(
+	containerAppService ContainerAppService,
+	subscriptionId string,
+	resourceGroupName string,
+	appName string,
+) LogSource {
+	return &serviceLogSource{
+		service:           containerAppService,
+		subscriptionId:    subscriptionId,
+		resourceGroupName: resourceGroupName,
+		appName:           appName,
+	}
+}
+
+type serviceLogSource struct {
+	service           ContainerAppService
+	subscriptionId    string
+	resourceGroupName string
+	appName           string
+}
+
+func (s *serviceLogSource) ActiveRevision(ctx context.Context) (string, error) {
+	return s.service.GetActiveRevision(ctx, s.subscriptionId, s.resourceGroupName, s.appName)
+}
+
+func (s *serviceLogSource) Open(ctx context.Context, revisionName string, tailLines int) (io.ReadCloser, error) {
+	return s.service.OpenLogStream(ctx, s.subscriptionId, s.resourceGroupName, s.appName, revisionName, tailLines)
+}
+
+// LogFollowerOptions configures a [LogFollower].
+type LogFollowerOptions struct {
+	// Since limits the history to lines logged within the duration before following started. Zero shows all the
+	// history returned by the log stream.
+	Since time.Duration
+	// PollInterval is how often the active revision is checked. Defaults to 10 seconds.
+	PollInterval time.Duration
+}
+
+// LogFollower writes the console logs of the active revision of a container app until its context is cancelled.
+// When another revision becomes active, for example after a new deployment, the follower switches to its logs.
+type LogFollower struct {
+	source       LogSource
+	writer       io.Writer
+	clock        clock.Clock
+	since        time.Duration
+	pollInterval time.Duration
+	// lastSeen is the timestamp of the newest line written, so lines aren't repeated when a stream is reopened.
+	lastSeen time.Time
+}
+
+// NewLogFollower creates a follower that writes the lines of the source to the writer.
+func NewLogFollower(source LogSource, writer io.Writer, clock clock.Clock, options LogFollowerOptions) *LogFollower {
+	pollInterval := options.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultLogPollInterval
+	}
+
+	return &LogFollower{
+		source:       source,
+		writer:       writer,
+		clock:        clock,
+		since:        options.Since,
+		pollInterval: pollInterval,
+	}
+}
+
+// Follow streams logs until the context is cancelled.
+func (f *LogFollower) Follow(ctx context.Context) error {
+	if f.since > 0 {
+		f.lastSeen = f.clock.Now().Add(-f.since)
+	}
+
+	current := ""
+	for {
+		revision, err := f.source.ActiveRevision(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("getting active revision: %w", err)
+		}
+
+		if current != "" && revision != current {
+			fmt.Fprintf(f.writer, "Revision '%s' is now active\n", revision)
+		}
+		current = revision
+
+		f.followRevision(ctx, revision)
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// followRevision writes the logs of the revision until the context is cancelled, the stream ends or another revision
+// becomes active.
+func (f *LogFollower) followRevision(ctx context.Context, revision string) {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := f.source.Open(streamCtx, revision, defaultLogTailLines)
+	if err != nil {
+		// Replicas of a new revision may still be starting, retry on the next poll.
+		log.Printf("failed opening log stream for revision '%s': %v", revision, err)
+		f.wait(ctx)
+		return
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- f.writeLines(stream)
+	}()
+
+	stop := func() {
+		cancel()
+		stream.Close()
+		<-done
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			stop()
+			return
+		case err := <-done:
+			stream.Close()
+			if err != nil {
+				log.Printf("log stream for revision '%s' ended: %v", revision, err)
+			}
+
+			f.wait(ctx)
+			return
+		case <-f.clock.After(f.pollInterval):
+			active, err := f.source.ActiveRevision(ctx)
+			if err != nil {
+				log.Printf("failed checking active revision: %v", err)
+				continue
+			}
+
+			if active != revision {
+				stop()
+				return
+			}
+		}
+	}
+}
+
+// writeLines copies the lines of the stream to the writer, skipping lines older than the newest line already written.
+func (f *LogFollower) writeLines(stream io.Reader) error {
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if timestamp, ok := parseLogTimestamp(line); ok {
+			if !timestamp.After(f.lastSeen) {
+				continue
+			}
+
+			f.lastSeen = timestamp
+		}
+
+		if _, err := fmt.Fprintln(f.writer, line); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+func (f *LogFollower) wait(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+	case <-f.clock.After(f.pollInterval):
+	}
+}
+
+// parseLogTimestamp parses the RFC 3339 timestamp that log stream lines start with.
+func parseLogTimestamp(line string) (time.Time, bool) {
+	field, _, _ := strings.Cut(strings.TrimSpace(line), " ")
+	timestamp, err := time.Parse(time.RFC3339Nano, field)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return timestamp, true
+}
@@ -0,0 +1,144 @@
+package containerapps
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLogSource serves fixed log lines for each revision. The streams stay open until they are closed by the follower,
+// like the streams of the container apps service.
+type fakeLogSource struct {
+	mu        sync.Mutex
+	revisions []string
+	lines     map[string][]string
+	opened    []string
+}
+
+func (s *fakeLogSource) ActiveRevision(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	revision := s.revisions[0]
+	if len(s.revisions) > 1 {
+		s.revisions = s.revisions[1:]
+	}
+
+	return revision, nil
+}
+
+func (s *fakeLogSource) Open(ctx context.Context, revisionName string, tailLines int) (io.ReadCloser, error) {
+	s.mu.Lock()
+	s.opened = append(s.opened, revisionName)
+	lines := s.lines[revisionName]
+	s.mu.Unlock()
+
+	reader, writer := io.Pipe()
+	go func() {
+		for _, line := range lines {
+			if _, err := io.WriteString(writer, line+"\n"); err != nil {
+				return
+			}
+		}
+
+		<-ctx.Done()
+		writer.Close()
+	}()
+
+	return reader, nil
+}
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func followUntil(t *testing.T, follower *LogFollower, output *syncBuffer, expected string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- follower.Follow(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		return strings.Contains(output.String(), expected)
+	}, 5*time.Second, 5*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func Test_LogFollower_Since(t *testing.T) {
+	now := time.Now().UTC()
+	source := &fakeLogSource{
+		revisions: []string{"app--rev1"},
+		lines: map[string][]string{
+			"app--rev1": {
+				now.Add(-2*time.Hour).Format(time.RFC3339Nano) + " stdout F old line",
+				now.Add(-time.Minute).Format(time.RFC3339Nano) + " stdout F recent line",
+				"line without timestamp",
+				now.Add(time.Second).Format(time.RFC3339Nano) + " stdout F last line",
+			},
+		},
+	}
+
+	output := &syncBuffer{}
+	follower := NewLogFollower(source, output, clock.New(), LogFollowerOptions{
+		Since:        10 * time.Minute,
+		PollInterval: 10 * time.Millisecond,
+	})
+
+	followUntil(t, follower, output, "last line")
+
+	require.NotContains(t, output.String(), "old line")
+	require.Contains(t, output.String(), "recent line")
+	require.Contains(t, output.String(), "line without timestamp")
+}
+
+func Test_LogFollower_RevisionSwitch(t *testing.T) {
+	now := time.Now().UTC()
+	source := &fakeLogSource{
+		revisions: []string{"app--rev1", "app--rev1", "app--rev2"},
+		lines: map[string][]string{
+			"app--rev1": {now.Format(time.RFC3339Nano) + " stdout F rev1 started"},
+			"app--rev2": {now.Add(time.Second).Format(time.RFC3339Nano) + " stdout F rev2 started"},
+		},
+	}
+
+	output := &syncBuffer{}
+	follower := NewLogFollower(source, output, clock.New(), LogFollowerOptions{
+		PollInterval: 10 * time.Millisecond,
+	})
+
+	followUntil(t, follower, output, "rev2 started")
+
+	require.Equal(t, []string{
+		now.Format(time.RFC3339Nano) + " stdout F rev1 started",
+		"Revision 'app--rev2' is now active",
+		now.Add(time.Second).Format(time.RFC3339Nano) + " stdout F rev2 started",
+	}, strings.Split(strings.TrimSpace(output.String()), "\n"))
+
+	source.mu.Lock()
+	defer source.mu.Unlock()
+	require.Equal(t, "app--rev1", source.opened[0])
+	require.Equal(t, "app--rev2", source.opened[len(source.opened)-1])
+}
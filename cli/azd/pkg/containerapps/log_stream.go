@@ -0,0 +1,155 @@
+package containerapps
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appcontainers/armappcontainers/v3"
+)
+
+// GetActiveRevision returns the name of the latest ready revision of the container app, which receives traffic in
+// single revision mode.
+func (cas *containerAppService) GetActiveRevision(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	appName string,
+) (string, error) {
+	containerApp, err := cas.getContainerApp(ctx, subscriptionId, resourceGroupName, appName)
+	if err != nil {
+		return "", err
+	}
+
+	if containerApp.Properties.LatestReadyRevisionName == nil {
+		return "", fmt.Errorf("container app '%s' has no ready revision", appName)
+	}
+
+	return *containerApp.Properties.LatestReadyRevisionName, nil
+}
+
+// OpenLogStream opens a stream of the console logs of the first container of a replica of the revision. The stream
+// follows new log lines until it is closed, and starts with up to tailLines lines of history.
+func (cas *containerAppService) OpenLogStream(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	appName string,
+	revisionName string,
+	tailLines int,
+) (io.ReadCloser, error) {
+	containerApp, err := cas.getContainerApp(ctx, subscriptionId, resourceGroupName, appName)
+	if err != nil {
+		return nil, err
+	}
+
+	if containerApp.Properties.EventStreamEndpoint == nil {
+		return nil, fmt.Errorf("container app '%s' does not expose a log stream endpoint", appName)
+	}
+
+	replicaName, containerName, err := cas.getReplicaContainer(
+		ctx, subscriptionId, resourceGroupName, appName, revisionName)
+	if err != nil {
+		return nil, err
+	}
+
+	appClient, err := cas.createContainerAppsClient(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenResponse, err := appClient.GetAuthToken(ctx, resourceGroupName, appName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("getting log stream token: %w", err)
+	}
+
+	if tokenResponse.Properties == nil || tokenResponse.Properties.Token == nil {
+		return nil, errors.New("log stream token was not returned")
+	}
+
+	// The event stream endpoint is '<base>/subscriptions/.../containerApps/<app>/eventstream'. Console logs are
+	// served from the same base, scoped to a single container of a replica.
+	base, _, found := strings.Cut(*containerApp.Properties.EventStreamEndpoint, "/subscriptions/")
+	if !found {
+		return nil, fmt.Errorf("unexpected event stream endpoint '%s'", *containerApp.Properties.EventStreamEndpoint)
+	}
+
+	query := url.Values{}
+	query.Set("follow", "true")
+	query.Set("output", "text")
+	query.Set("tailLines", strconv.Itoa(tailLines))
+
+	streamUrl := fmt.Sprintf(
+		"%s/subscriptions/%s/resourceGroups/%s/containerApps/%s/revisions/%s/replicas/%s/containers/%s/logstream?%s",
+		base,
+		subscriptionId,
+		resourceGroupName,
+		appName,
+		revisionName,
+		replicaName,
+		containerName,
+		query.Encode(),
+	)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, streamUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", *tokenResponse.Properties.Token))
+
+	res, err := cas.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("opening log stream: %w", err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		return nil, fmt.Errorf("opening log stream: unexpected status %d: %s", res.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return res.Body, nil
+}
+
+func (cas *containerAppService) getReplicaContainer(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	appName string,
+	revisionName string,
+) (replicaName string, containerName string, err error) {
+	credential, err := cas.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return "", "", err
+	}
+
+	client, err := armappcontainers.NewContainerAppsRevisionReplicasClient(
+		subscriptionId, credential, cas.armClientOptions)
+	if err != nil {
+		return "", "", fmt.Errorf("creating ContainerAppsRevisionReplicas client: %w", err)
+	}
+
+	replicas, err := client.ListReplicas(ctx, resourceGroupName, appName, revisionName, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("listing replicas of revision '%s': %w", revisionName, err)
+	}
+
+	for _, replica := range replicas.Value {
+		if replica.Name == nil || replica.Properties == nil {
+			continue
+		}
+
+		for _, container := range replica.Properties.Containers {
+			if container.Name != nil {
+				return *replica.Name, *container.Name, nil
+			}
+		}
+	}
+
+	return "", "", fmt.Errorf("revision '%s' has no running replicas", revisionName)
+}
@@ -24,4 +24,6 @@ type LoginResult struct {
 	// When status is `LoginStatusSuccess`, the time at which the access token
 	// expires.
 	ExpiresOn *time.Time `json:"expiresOn,omitempty"`
+	// When status is `LoginStatusSuccess`, where the credential comes from (e.g. "azd" or "azcli").
+	Source string `json:"source,omitempty"`
 }
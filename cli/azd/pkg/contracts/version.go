@@ -8,4 +8,12 @@ type VersionResult struct {
 		Version string `json:"version"`
 		Commit  string `json:"commit"`
 	} `json:"azd"`
+	// Update is the result of the update check, set by `azd version --check`.
+	Update *VersionUpdateResult `json:"update,omitempty"`
+}
+
+// VersionUpdateResult is the contract for the update check of `azd version --check`
+type VersionUpdateResult struct {
+	Latest          string `json:"latest"`
+	UpdateAvailable bool   `json:"updateAvailable"`
 }
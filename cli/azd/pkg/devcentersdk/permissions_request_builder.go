@@ -35,7 +35,6 @@ func (c *PermissionListRequestBuilder) Get(ctx context.Context) ([]*armauthoriza
 
 	options := azsdk.NewClientOptionsBuilderFactory(c.client.options.Transport, "azd", c.client.cloud).
 		NewClientOptionsBuilder().
-		WithPerCallPolicy(azsdk.NewMsCorrelationPolicy()).
 		BuildArmClientOptions()
 	permissionsClient, err := armauthorization.NewPermissionsClient(project.SubscriptionId, c.client.credential, options)
 	if err != nil {
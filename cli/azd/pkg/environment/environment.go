@@ -11,6 +11,8 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"maps"
 
@@ -48,9 +50,15 @@ const PlatformTypeEnvVarName = "AZD_PLATFORM_TYPE"
 
 // The zero value of an Environment is not valid. Use [New] to create one. When writing tests,
 // [Ephemeral] and [EphemeralWithValues] are useful to create environments which are not persisted to disk.
+//
+// The values of an Environment are safe for concurrent use: Getenv, LookupEnv, Dotenv, DotenvSet, DotenvDelete and
+// Environ may be called from multiple goroutines, like services deployed in parallel.
 type Environment struct {
 	name string
 
+	// mu guards dotenv, deletedKeys, secretKeys, loadedSecrets and overlay
+	mu sync.RWMutex
+
 	// dotenv is a map of keys to values, persisted to the `.env` file stored in this environment's [Root].
 	dotenv map[string]string
 
@@ -58,6 +66,17 @@ type Environment struct {
 	// happens in Save
 	deletedKeys map[string]struct{}
 
+	// secretKeys keeps track of the keys stored in the encrypted `.env.secret` file, loaded from it or set with
+	// DotenvSetSecret, which are never written to the `.env` file
+	secretKeys map[string]struct{}
+
+	// loadedSecrets are the secrets last loaded from the `.env.secret` file, which is only rewritten when they change
+	loadedSecrets map[string]string
+
+	// overlay are values set with SetOverlay, which are read on top of dotenv but never saved, and are kept when the
+	// environment is reloaded
+	overlay map[string]string
+
 	// Config is environment specific config
 	Config config.Config
 }
@@ -144,44 +163,168 @@ func CleanName(name string) string {
 }
 
 // Getenv behaves like os.Getenv, except that any keys in the `.env` file associated with this environment are considered
-// first.
+// first. When AZD_ENV_EXPAND_REFERENCES is enabled, the references to other values in the value are expanded, as with
+// [Environment.Resolve].
 func (e *Environment) Getenv(key string) string {
-	if v, has := e.dotenv[key]; has {
-		return v
-	}
-
-	return os.Getenv(key)
+	v, _ := e.lookupExpanded(key)
+	return v
 }
 
 // LookupEnv behaves like os.LookupEnv, except that any keys in the `.env` file associated with this environment are
-// considered first.
+// considered first. When AZD_ENV_EXPAND_REFERENCES is enabled, the references to other values in the value are expanded,
+// as with [Environment.Resolve].
 func (e *Environment) LookupEnv(key string) (string, bool) {
-	if v, has := e.dotenv[key]; has {
-		return v, true
-	}
-
-	return os.LookupEnv(key)
+	return e.lookupExpanded(key)
 }
 
 // DotenvDelete removes the given key from the .env file in the environment, it is a no-op if the key
 // does not exist. [Save] should be called to ensure this change is persisted.
 func (e *Environment) DotenvDelete(key string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	delete(e.dotenv, key)
 	e.deletedKeys[key] = struct{}{}
 }
 
 // Dotenv returns a copy of the key value pairs from the .env file in the environment.
 func (e *Environment) Dotenv() map[string]string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
 	return maps.Clone(e.dotenv)
 }
 
 // DotenvSet sets the value of [key] to [value] in the .env file associated with the environment. [Save] should be
 // called to ensure this change is persisted.
 func (e *Environment) DotenvSet(key string, value string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	e.dotenv[key] = value
 	delete(e.deletedKeys, key)
 }
 
+// DotenvSetMany sets the values of the keys in the .env file associated with the environment, as a single update, so
+// concurrent readers see either none or all of the values. [Save] should be called once to persist the changes.
+func (e *Environment) DotenvSetMany(values map[string]string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for key, value := range values {
+		e.dotenv[key] = value
+		delete(e.deletedKeys, key)
+	}
+}
+
+// SetOverlay sets values read by Getenv, LookupEnv and Environ on top of the values of the `.env` file. Unlike
+// DotenvSet, the values aren't saved and are kept when the environment is reloaded, like the outputs of an ephemeral
+// provision, which are only available to the hooks and commands run by the current azd process.
+func (e *Environment) SetOverlay(values map[string]string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.overlay == nil {
+		e.overlay = make(map[string]string, len(values))
+	}
+	maps.Copy(e.overlay, values)
+}
+
+// lookupOverlay returns the value of key set with SetOverlay.
+func (e *Environment) lookupOverlay(key string) (string, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	value, has := e.overlay[key]
+	return value, has
+}
+
+// DotenvSetSecret sets the value of [key] to [value] like [DotenvSet], storing it in the encrypted `.env.secret` file
+// associated with the environment rather than in its `.env` file. [Save] should be called to ensure this change is
+// persisted.
+func (e *Environment) DotenvSetSecret(key string, value string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.dotenv[key] = value
+	delete(e.deletedKeys, key)
+
+	if e.secretKeys == nil {
+		e.secretKeys = make(map[string]struct{})
+	}
+	e.secretKeys[key] = struct{}{}
+}
+
+// secrets returns the values of the keys stored in the `.env.secret` file, and whether they changed since they were
+// loaded, in which case the file should be rewritten.
+func (e *Environment) secrets() (map[string]string, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	secrets := make(map[string]string, len(e.secretKeys))
+	for key := range e.secretKeys {
+		if value, has := e.dotenv[key]; has {
+			secrets[key] = value
+		}
+	}
+
+	return secrets, !maps.Equal(secrets, e.loadedSecrets)
+}
+
+// reload replaces the values of the environment with the values loaded from its data store, and clears the deleted
+// keys. The secrets, loaded from the `.env.secret` file, take precedence over the other values.
+func (e *Environment) reload(values map[string]string, secrets map[string]string) {
+	if values == nil {
+		values = make(map[string]string)
+	}
+
+	secretKeys := make(map[string]struct{}, len(secrets))
+	for key, value := range secrets {
+		values[key] = value
+		secretKeys[key] = struct{}{}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.dotenv = values
+	e.deletedKeys = make(map[string]struct{})
+	e.secretKeys = secretKeys
+	e.loadedSecrets = maps.Clone(secrets)
+}
+
+// changes returns a copy of the values, the deleted keys and the secret keys of the environment, to be replayed with
+// [Environment.replay] once the environment is reloaded.
+func (e *Environment) changes() (map[string]string, map[string]struct{}, map[string]struct{}) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return maps.Clone(e.dotenv), maps.Clone(e.deletedKeys), maps.Clone(e.secretKeys)
+}
+
+// replay overlays the values, replays the deletion of the keys and marks the secret keys returned by
+// [Environment.changes].
+func (e *Environment) replay(
+	values map[string]string,
+	deletedKeys map[string]struct{},
+	secretKeys map[string]struct{},
+) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for key, value := range values {
+		e.dotenv[key] = value
+	}
+
+	for key := range deletedKeys {
+		delete(e.dotenv, key)
+	}
+
+	for key := range secretKeys {
+		e.secretKeys[key] = struct{}{}
+	}
+}
+
 // Name gets the name of the environment
 // If empty will fallback to the value of the AZURE_ENV_NAME environment variable
 func (e *Environment) Name() string {
@@ -217,6 +360,46 @@ func (e *Environment) SetLocation(location string) {
 	e.DotenvSet(LocationEnvVarName, location)
 }
 
+// adoptedResourceGroupConfigPath is the path of the environment config holding the existing resource group the
+// environment was created from, with 'azd env new --from-azure'.
+const adoptedResourceGroupConfigPath = "adopted.resourceGroup"
+
+// SetAdoptedResourceGroup marks the environment as adopted from an existing resource group, which resources were not
+// provisioned by azd.
+func (e *Environment) SetAdoptedResourceGroup(resourceGroup string) error {
+	return e.Config.Set(adoptedResourceGroupConfigPath, resourceGroup)
+}
+
+// GetAdoptedResourceGroup returns the existing resource group the environment was adopted from, if any.
+func (e *Environment) GetAdoptedResourceGroup() (string, bool) {
+	return e.Config.GetString(adoptedResourceGroupConfigPath)
+}
+
+// lastDeployTimeConfigPath is the path of the environment config holding the time of the last successful deployment of
+// the services of the environment, in RFC 3339 format.
+const lastDeployTimeConfigPath = "deploy.lastDeployTime"
+
+// SetLastDeployTime records the time of the last successful deployment of the services of the environment.
+func (e *Environment) SetLastDeployTime(t time.Time) error {
+	return e.Config.Set(lastDeployTimeConfigPath, t.UTC().Format(time.RFC3339))
+}
+
+// GetLastDeployTime returns the time of the last successful deployment of the services of the environment, if any.
+func (e *Environment) GetLastDeployTime() (time.Time, bool) {
+	value, has := e.Config.GetString(lastDeployTimeConfigPath)
+	if !has {
+		return time.Time{}, false
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		log.Printf("ignoring invalid last deploy time '%s': %v", value, err)
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
 func normalize(key string) string {
 	return strings.ReplaceAll(strings.ToUpper(key), "-", "_")
 }
@@ -232,10 +415,30 @@ func (e *Environment) SetServiceProperty(serviceName string, propertyName string
 }
 
 // Creates a slice of key value pairs, based on the entries in the `.env` file like `KEY=VALUE` that
-// can be used to pass into command runner or similar constructs.
+// can be used to pass into command runner or similar constructs. When AZD_ENV_EXPAND_REFERENCES is enabled, the
+// references to other values in the values are expanded.
 func (e *Environment) Environ() []string {
+	values := e.Dotenv()
+	expand := e.expandReferences()
+
+	e.mu.RLock()
+	overlay := maps.Clone(e.overlay)
+	e.mu.RUnlock()
+
 	envVars := []string{}
-	for k, v := range e.dotenv {
+	for k, v := range values {
+		if _, has := overlay[k]; has {
+			continue
+		}
+
+		if expand {
+			v, _ = e.lookupExpanded(k)
+		}
+
+		envVars = append(envVars, fmt.Sprintf("%s=%s", k, v))
+	}
+
+	for k, v := range overlay {
 		envVars = append(envVars, fmt.Sprintf("%s=%s", k, v))
 	}
 
@@ -280,11 +483,23 @@ func fixupUnquotedDotenv(values map[string]string, dotenv string) string {
 // Prepare dotenv for saving and returns a marshalled string that can be save to the underlying data store
 // Instead of calling `godotenv.Write` directly, we need to save the file ourselves, so we can fixup any numeric values
 // that were incorrectly unquoted.
+// Secrets stored in the `.env.secret` file are excluded, so their values are never written in plaintext.
 func marshallDotEnv(env *Environment) (string, error) {
-	marshalled, err := godotenv.Marshal(env.dotenv)
+	env.mu.RLock()
+	defer env.mu.RUnlock()
+
+	values := env.dotenv
+	if len(env.secretKeys) > 0 {
+		values = maps.Clone(env.dotenv)
+		for key := range env.secretKeys {
+			delete(values, key)
+		}
+	}
+
+	marshalled, err := godotenv.Marshal(values)
 	if err != nil {
 		return "", fmt.Errorf("marshalling .env: %w", err)
 	}
 
-	return fixupUnquotedDotenv(env.dotenv, marshalled), nil
+	return fixupUnquotedDotenv(values, marshalled), nil
 }
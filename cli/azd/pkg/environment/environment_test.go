@@ -6,8 +6,11 @@ package environment
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/config"
@@ -179,6 +182,176 @@ func Test_SaveAndReload(t *testing.T) {
 	require.Equal(t, "http://api.example.com/updated", value)
 }
 
+func Test_ConcurrentDotenvSet(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	envManager, _ := createEnvManager(t, mockContext, t.TempDir())
+
+	env := New("test")
+
+	// Services deployed in parallel set and read values of the same environment
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			key := fmt.Sprintf("SERVICE_SVC%d_ENDPOINT_URL", i)
+			env.DotenvSet(key, fmt.Sprintf("http://svc%d.example.com", i))
+			require.NotEmpty(t, env.Getenv(key))
+			_ = env.Environ()
+			_ = env.Dotenv()
+		}()
+	}
+	wg.Wait()
+
+	require.NoError(t, envManager.Save(*mockContext.Context, env))
+
+	reloaded := New("test")
+	require.NoError(t, envManager.Reload(*mockContext.Context, reloaded))
+	for i := 0; i < 50; i++ {
+		require.Equal(t,
+			fmt.Sprintf("http://svc%d.example.com", i), reloaded.Getenv(fmt.Sprintf("SERVICE_SVC%d_ENDPOINT_URL", i)))
+	}
+}
+
+func Test_DotenvSetMany(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	envManager, azdCtx := createEnvManager(t, mockContext, t.TempDir())
+
+	env := New("test")
+	env.DotenvSet("SERVICE_API_ENDPOINT_URL", "http://api.example.com")
+	env.DotenvDelete("SERVICE_WEB_ENDPOINT_URL")
+	require.NoError(t, envManager.Save(*mockContext.Context, env))
+
+	values := map[string]string{
+		"SERVICE_WEB_ENDPOINT_URL": "http://web.example.com",
+		"SERVICE_WEB_NAME":         "web",
+	}
+
+	// Readers see either none or all of the values of a batch
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			dotenv := env.Dotenv()
+			_, hasUrl := dotenv["SERVICE_WEB_ENDPOINT_URL"]
+			_, hasName := dotenv["SERVICE_WEB_NAME"]
+			if hasUrl != hasName {
+				t.Error("observed a partial update")
+				return
+			}
+		}
+	}()
+	env.DotenvSetMany(values)
+	<-done
+
+	require.NoError(t, envManager.Save(*mockContext.Context, env))
+
+	envPath := filepath.Join(azdCtx.EnvironmentRoot("test"), azdcontext.DotEnvFileName)
+	saved, err := godotenv.Read(envPath)
+	require.NoError(t, err)
+	require.Equal(t, "http://api.example.com", saved["SERVICE_API_ENDPOINT_URL"])
+	require.Equal(t, "http://web.example.com", saved["SERVICE_WEB_ENDPOINT_URL"])
+	require.Equal(t, "web", saved["SERVICE_WEB_NAME"])
+
+	// The .env file is replaced by a temporary file once written, which doesn't remain
+	entries, err := os.ReadDir(azdCtx.EnvironmentRoot("test"))
+	require.NoError(t, err)
+	for _, entry := range entries {
+		require.NotContains(t, entry.Name(), ".tmp")
+	}
+}
+
+func Test_SetOverlay(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	envManager, azdCtx := createEnvManager(t, mockContext, t.TempDir())
+
+	env := New("test")
+	env.DotenvSet("SERVICE_API_ENDPOINT_URL", "http://api.example.com")
+	env.SetOverlay(map[string]string{
+		"SERVICE_API_ENDPOINT_URL": "http://api.preview.example.com",
+		"WEBSITE_URL":              "http://web.preview.example.com",
+	})
+
+	// The overlay takes precedence over the values of the .env file
+	require.Equal(t, "http://api.preview.example.com", env.Getenv("SERVICE_API_ENDPOINT_URL"))
+	require.Equal(t, "http://web.preview.example.com", env.Getenv("WEBSITE_URL"))
+	require.Contains(t, env.Environ(), "SERVICE_API_ENDPOINT_URL=http://api.preview.example.com")
+	require.NotContains(t, env.Environ(), "SERVICE_API_ENDPOINT_URL=http://api.example.com")
+
+	// The overlay isn't saved, and is kept once the environment is reloaded
+	require.NoError(t, envManager.Save(*mockContext.Context, env))
+	require.NoError(t, envManager.Reload(*mockContext.Context, env))
+	require.Equal(t, "http://web.preview.example.com", env.Getenv("WEBSITE_URL"))
+	require.Contains(t, env.Environ(), "WEBSITE_URL=http://web.preview.example.com")
+
+	saved, err := godotenv.Read(filepath.Join(azdCtx.EnvironmentRoot("test"), azdcontext.DotEnvFileName))
+	require.NoError(t, err)
+	require.Equal(t, "http://api.example.com", saved["SERVICE_API_ENDPOINT_URL"])
+	require.NotContains(t, saved, "WEBSITE_URL")
+}
+
+func Test_ExpandReferences(t *testing.T) {
+	values := map[string]string{
+		"AZURE_SQL_HOST":     "${AZURE_SQL_SERVER}.database.windows.net",
+		"AZURE_SQL_SERVER":   "sql-${AZURE_ENV_NAME}",
+		"AZURE_ENV_NAME":     "dev",
+		"AZURE_CONNSTRING":   "Server=${AZURE_SQL_HOST};Database=${AZURE_SQL_DATABASE:-todo}",
+		"AZURE_SQL_PASSWORD": "pa$word",
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		env := NewWithValues("dev", maps.Clone(values))
+
+		require.Equal(t, "Server=${AZURE_SQL_HOST};Database=${AZURE_SQL_DATABASE:-todo}", env.Getenv("AZURE_CONNSTRING"))
+		require.Equal(t, "pa$word", env.Getenv("AZURE_SQL_PASSWORD"))
+	})
+
+	t.Run("ChainedReferences", func(t *testing.T) {
+		env := NewWithValues("dev", maps.Clone(values))
+		env.DotenvSet(ExpandReferencesEnvVarName, "true")
+
+		require.Equal(t, "Server=sql-dev.database.windows.net;Database=todo", env.Getenv("AZURE_CONNSTRING"))
+		require.Contains(t, env.Environ(), "AZURE_SQL_HOST=sql-dev.database.windows.net")
+
+		// The values are expanded when read, the .env values are kept as is
+		require.Equal(t, "${AZURE_SQL_SERVER}.database.windows.net", env.Dotenv()["AZURE_SQL_HOST"])
+
+		value, err := env.Resolve("AZURE_CONNSTRING")
+		require.NoError(t, err)
+		require.Equal(t, "Server=sql-dev.database.windows.net;Database=todo", value)
+	})
+
+	t.Run("ProcessEnvironment", func(t *testing.T) {
+		t.Setenv(ExpandReferencesEnvVarName, "true")
+		t.Setenv("AZURE_SQL_DATABASE", "orders")
+
+		env := NewWithValues("dev", maps.Clone(values))
+		require.Equal(t, "Server=sql-dev.database.windows.net;Database=orders", env.Getenv("AZURE_CONNSTRING"))
+	})
+
+	t.Run("CircularReference", func(t *testing.T) {
+		env := NewWithValues("dev", map[string]string{
+			ExpandReferencesEnvVarName: "true",
+			"AZURE_A":                  "a-${AZURE_B}",
+			"AZURE_B":                  "b-${AZURE_C}",
+			"AZURE_C":                  "c-${AZURE_A}",
+			"AZURE_SELF":               "${AZURE_SELF}",
+		})
+
+		_, err := env.Resolve("AZURE_A")
+		require.ErrorIs(t, err, ErrCircularReference)
+		require.ErrorContains(t, err, "AZURE_A -> AZURE_B -> AZURE_C -> AZURE_A")
+
+		_, err = env.Resolve("AZURE_SELF")
+		require.ErrorIs(t, err, ErrCircularReference)
+
+		// Values which can't be expanded are read as is
+		require.Equal(t, "a-${AZURE_B}", env.Getenv("AZURE_A"))
+	})
+}
+
 func TestCleanName(t *testing.T) {
 	require.Equal(t, "already-clean-name", CleanName("already-clean-name"))
 	require.Equal(t, "was-CLEANED-with--bad--things-(123)", CleanName("was CLEANED with *bad* things (123)"))
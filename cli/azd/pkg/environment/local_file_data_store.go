@@ -12,6 +12,8 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/config"
 	"github.com/azure/azure-dev/cli/azd/pkg/contracts"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 	"github.com/google/uuid"
 	"github.com/joho/godotenv"
 	"golang.org/x/exp/slices"
@@ -36,6 +38,11 @@ func (fs *LocalFileDataStore) EnvPath(env *Environment) string {
 	return filepath.Join(fs.azdContext.EnvironmentRoot(env.name), DotEnvFileName)
 }
 
+// SecretEnvPath returns the path to the encrypted .env.secret file for the given environment
+func (fs *LocalFileDataStore) SecretEnvPath(env *Environment) string {
+	return filepath.Join(fs.azdContext.EnvironmentRoot(env.name), SecretDotEnvFileName)
+}
+
 // ConfigPath returns the path to the config.json file for the given environment
 func (fs *LocalFileDataStore) ConfigPath(env *Environment) string {
 	return filepath.Join(fs.azdContext.EnvironmentRoot(env.name), ConfigFileName)
@@ -99,16 +106,18 @@ func (fs *LocalFileDataStore) Get(ctx context.Context, name string) (*Environmen
 // Reload reloads the environment from the persistent data store
 func (fs *LocalFileDataStore) Reload(ctx context.Context, env *Environment) error {
 	// Reload env values
-	if envMap, err := godotenv.Read(fs.EnvPath(env)); errors.Is(err, os.ErrNotExist) {
-		env.dotenv = make(map[string]string)
-		env.deletedKeys = make(map[string]struct{})
-	} else if err != nil {
+	envMap, err := godotenv.Read(fs.EnvPath(env))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
 		return fmt.Errorf("loading .env: %w", err)
-	} else {
-		env.dotenv = envMap
-		env.deletedKeys = make(map[string]struct{})
 	}
 
+	secrets, err := fs.loadSecrets(env)
+	if err != nil {
+		return err
+	}
+
+	env.reload(envMap, secrets)
+
 	// Reload env config
 	if cfg, err := fs.configManager.Load(fs.ConfigPath(env)); errors.Is(err, os.ErrNotExist) {
 		env.Config = config.NewEmptyConfig()
@@ -139,20 +148,17 @@ func (fs *LocalFileDataStore) Save(ctx context.Context, env *Environment) error
 	}
 
 	// Cache current values & reload to get any new env vars
-	currentValues := env.dotenv
-	deletedValues := env.deletedKeys
+	currentValues, deletedValues, secretKeys := env.changes()
 	if err := fs.Reload(ctx, env); err != nil {
 		return fmt.Errorf("failed reloading env vars, %w", err)
 	}
 
-	// Overlay current values before saving
-	for key, value := range currentValues {
-		env.dotenv[key] = value
-	}
+	// Overlay current values and replay deletion before saving
+	env.replay(currentValues, deletedValues, secretKeys)
 
-	// Replay deletion
-	for key := range deletedValues {
-		delete(env.dotenv, key)
+	// Secrets are saved first, so a secret is never lost from both files when saving fails
+	if err := fs.saveSecrets(ctx, env); err != nil {
+		return err
 	}
 
 	marshalled, err := marshallDotEnv(env)
@@ -160,25 +166,131 @@ func (fs *LocalFileDataStore) Save(ctx context.Context, env *Environment) error
 		return fmt.Errorf("marshalling .env: %w", err)
 	}
 
-	envFile, err := os.Create(fs.EnvPath(env))
+	// Write the contents to a temporary file, which replaces the .env file once written, so the .env file is updated
+	// atomically and left untouched if saving fails.
+	envPath := fs.EnvPath(env)
+	envFile, err := os.CreateTemp(filepath.Dir(envPath), fmt.Sprintf("%s.tmp*", filepath.Base(envPath)))
 	if err != nil {
 		return fmt.Errorf("saving .env: %w", err)
 	}
-	defer envFile.Close()
+	defer func() {
+		_ = envFile.Close()
+		_ = os.Remove(envFile.Name())
+	}()
 
 	// Write the contents (with a trailing newline), and sync the file, as godotenv.Write would have.
 	if _, err := envFile.WriteString(marshalled + "\n"); err != nil {
 		return fmt.Errorf("saving .env: %w", err)
 	}
 
+	if err := envFile.Chmod(osutil.PermissionFile); err != nil {
+		return fmt.Errorf("saving .env: %w", err)
+	}
+
 	if err := envFile.Sync(); err != nil {
 		return fmt.Errorf("saving .env: %w", err)
 	}
 
+	if err := envFile.Close(); err != nil {
+		return fmt.Errorf("saving .env: %w", err)
+	}
+
+	if err := osutil.Rename(ctx, envFile.Name(), envPath); err != nil {
+		return fmt.Errorf("saving .env: %w", err)
+	}
+
 	tracing.SetUsageAttributes(fields.StringHashed(fields.EnvNameKey, env.Name()))
 	return nil
 }
 
+// loadSecrets decrypts the .env.secret file of the environment, when it has one, returning its secrets, which take
+// precedence over the values of the .env file. The values of the secrets are redacted from the logs.
+func (fs *LocalFileDataStore) loadSecrets(env *Environment) (map[string]string, error) {
+	contents, err := os.ReadFile(fs.SecretEnvPath(env))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("loading %s: %w", SecretDotEnvFileName, err)
+	}
+
+	passphrase, has := os.LookupEnv(SecretPassphraseEnvVarName)
+	if !has || passphrase == "" {
+		return nil, ErrSecretPassphraseRequired
+	}
+
+	secrets, err := DecryptSecrets(contents, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, value := range secrets {
+		exec.AddSensitiveData(value)
+	}
+
+	return secrets, nil
+}
+
+// saveSecrets encrypts the secrets of the environment to its .env.secret file, when they changed since they were loaded.
+// The file is removed once the environment has no secrets left.
+func (fs *LocalFileDataStore) saveSecrets(ctx context.Context, env *Environment) error {
+	secrets, changed := env.secrets()
+	if !changed {
+		return nil
+	}
+
+	secretPath := fs.SecretEnvPath(env)
+	if len(secrets) == 0 {
+		if err := os.Remove(secretPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("saving %s: %w", SecretDotEnvFileName, err)
+		}
+
+		return nil
+	}
+
+	passphrase, has := os.LookupEnv(SecretPassphraseEnvVarName)
+	if !has || passphrase == "" {
+		return ErrSecretPassphraseRequired
+	}
+
+	contents, err := EncryptSecrets(secrets, passphrase)
+	if err != nil {
+		return fmt.Errorf("saving %s: %w", SecretDotEnvFileName, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(secretPath), osutil.PermissionDirectory); err != nil {
+		return fmt.Errorf("saving %s: %w", SecretDotEnvFileName, err)
+	}
+
+	// Like the .env file, the file is replaced once written, so it's left untouched if saving fails
+	secretFile, err := os.CreateTemp(filepath.Dir(secretPath), fmt.Sprintf("%s.tmp*", SecretDotEnvFileName))
+	if err != nil {
+		return fmt.Errorf("saving %s: %w", SecretDotEnvFileName, err)
+	}
+	defer func() {
+		_ = secretFile.Close()
+		_ = os.Remove(secretFile.Name())
+	}()
+
+	if _, err := secretFile.Write(contents); err != nil {
+		return fmt.Errorf("saving %s: %w", SecretDotEnvFileName, err)
+	}
+
+	if err := secretFile.Close(); err != nil {
+		return fmt.Errorf("saving %s: %w", SecretDotEnvFileName, err)
+	}
+
+	if err := osutil.Rename(ctx, secretFile.Name(), secretPath); err != nil {
+		return fmt.Errorf("saving %s: %w", SecretDotEnvFileName, err)
+	}
+
+	for _, value := range secrets {
+		exec.AddSensitiveData(value)
+	}
+
+	env.reload(env.Dotenv(), secrets)
+	return nil
+}
+
 func (fs *LocalFileDataStore) Delete(ctx context.Context, name string) error {
 	envRoot := fs.azdContext.EnvironmentRoot(name)
 	_, err := os.Stat(envRoot)
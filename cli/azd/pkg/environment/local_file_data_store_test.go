@@ -2,11 +2,14 @@ package environment
 
 import (
 	"context"
+	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/config"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/stretchr/testify/require"
 )
@@ -83,3 +86,129 @@ func Test_LocalFileDataStore_ConfigPath(t *testing.T) {
 
 	require.Equal(t, expected, actual)
 }
+
+func Test_LocalFileDataStore_Secrets(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	azdContext := azdcontext.NewAzdContextWithDirectory(t.TempDir())
+	fileConfigManager := config.NewFileConfigManager(config.NewManager())
+	dataStore := NewLocalFileDataStore(azdContext, fileConfigManager)
+
+	env := New("env1")
+	env.DotenvSet("DB_HOST", "localhost")
+	env.DotenvSet("DB_PASSWORD", "placeholder")
+	require.NoError(t, dataStore.Save(*mockContext.Context, env))
+
+	secrets, err := EncryptSecrets(map[string]string{"DB_PASSWORD": "s3cr3t-P@ssw0rd"}, "correct horse")
+	require.NoError(t, err)
+	require.NotContains(t, string(secrets), "s3cr3t-P@ssw0rd")
+	err = os.WriteFile(
+		filepath.Join(azdContext.EnvironmentRoot("env1"), SecretDotEnvFileName), secrets, osutil.PermissionFile)
+	require.NoError(t, err)
+
+	t.Run("PassphraseRequired", func(t *testing.T) {
+		t.Setenv(SecretPassphraseEnvVarName, "")
+
+		_, err := dataStore.Get(*mockContext.Context, "env1")
+		require.ErrorIs(t, err, ErrSecretPassphraseRequired)
+	})
+
+	t.Run("WrongPassphrase", func(t *testing.T) {
+		t.Setenv(SecretPassphraseEnvVarName, "battery staple")
+
+		_, err := dataStore.Get(*mockContext.Context, "env1")
+		require.ErrorContains(t, err, "the passphrase is incorrect")
+	})
+
+	t.Run("DecryptAndMerge", func(t *testing.T) {
+		t.Setenv(SecretPassphraseEnvVarName, "correct horse")
+
+		env, err := dataStore.Get(*mockContext.Context, "env1")
+		require.NoError(t, err)
+		require.Equal(t, "localhost", env.Getenv("DB_HOST"))
+		// Secrets take precedence over the values of the .env file
+		require.Equal(t, "s3cr3t-P@ssw0rd", env.Getenv("DB_PASSWORD"))
+
+		// Secrets are redacted from the logs
+		require.Equal(t, "--connection-string <redacted>", exec.RedactSensitiveData("--connection-string s3cr3t-P@ssw0rd"))
+	})
+
+	t.Run("SaveNeverWritesSecrets", func(t *testing.T) {
+		t.Setenv(SecretPassphraseEnvVarName, "correct horse")
+
+		env, err := dataStore.Get(*mockContext.Context, "env1")
+		require.NoError(t, err)
+
+		env.DotenvSet("DB_NAME", "todos")
+		require.NoError(t, dataStore.Save(*mockContext.Context, env))
+		require.Equal(t, "s3cr3t-P@ssw0rd", env.Getenv("DB_PASSWORD"))
+
+		dotenv, err := os.ReadFile(dataStore.EnvPath(env))
+		require.NoError(t, err)
+		require.NotContains(t, string(dotenv), "s3cr3t-P@ssw0rd")
+		require.NotContains(t, string(dotenv), "DB_PASSWORD")
+		require.Contains(t, string(dotenv), `DB_NAME="todos"`)
+		require.Contains(t, string(dotenv), `DB_HOST="localhost"`)
+	})
+
+	t.Run("SetExistingSecret", func(t *testing.T) {
+		t.Setenv(SecretPassphraseEnvVarName, "correct horse")
+
+		env, err := dataStore.Get(*mockContext.Context, "env1")
+		require.NoError(t, err)
+
+		// Keys stored in the .env.secret file stay there when updated
+		env.DotenvSet("DB_PASSWORD", "n3w-s3cr3t-P@ssw0rd")
+		require.NoError(t, dataStore.Save(*mockContext.Context, env))
+
+		dotenv, err := os.ReadFile(dataStore.EnvPath(env))
+		require.NoError(t, err)
+		require.NotContains(t, string(dotenv), "DB_PASSWORD")
+
+		env, err = dataStore.Get(*mockContext.Context, "env1")
+		require.NoError(t, err)
+		require.Equal(t, "n3w-s3cr3t-P@ssw0rd", env.Getenv("DB_PASSWORD"))
+	})
+
+	t.Run("SetSecret", func(t *testing.T) {
+		t.Setenv(SecretPassphraseEnvVarName, "correct horse")
+
+		env, err := dataStore.Get(*mockContext.Context, "env1")
+		require.NoError(t, err)
+
+		env.DotenvSetSecret("API_KEY", "k3y-v@lue-0123")
+		require.NoError(t, dataStore.Save(*mockContext.Context, env))
+
+		dotenv, err := os.ReadFile(dataStore.EnvPath(env))
+		require.NoError(t, err)
+		require.NotContains(t, string(dotenv), "k3y-v@lue-0123")
+
+		contents, err := os.ReadFile(filepath.Join(azdContext.EnvironmentRoot("env1"), SecretDotEnvFileName))
+		require.NoError(t, err)
+		secrets, err := DecryptSecrets(contents, "correct horse")
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{
+			"DB_PASSWORD": "n3w-s3cr3t-P@ssw0rd",
+			"API_KEY":     "k3y-v@lue-0123",
+		}, secrets)
+	})
+
+	t.Run("DeleteSecrets", func(t *testing.T) {
+		t.Setenv(SecretPassphraseEnvVarName, "correct horse")
+
+		env, err := dataStore.Get(*mockContext.Context, "env1")
+		require.NoError(t, err)
+
+		env.DotenvDelete("DB_PASSWORD")
+		env.DotenvDelete("API_KEY")
+		require.NoError(t, dataStore.Save(*mockContext.Context, env))
+
+		// The file is removed once the environment has no secrets left
+		_, err = os.Stat(filepath.Join(azdContext.EnvironmentRoot("env1"), SecretDotEnvFileName))
+		require.ErrorIs(t, err, os.ErrNotExist)
+
+		t.Setenv(SecretPassphraseEnvVarName, "")
+		env, err = dataStore.Get(*mockContext.Context, "env1")
+		require.NoError(t, err)
+		require.Equal(t, "", env.Getenv("DB_PASSWORD"))
+	})
+}
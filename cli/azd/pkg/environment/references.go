@@ -0,0 +1,110 @@
+package environment
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+)
+
+// ExpandReferencesEnvVarName is the name of the key enabling the expansion of references to other values, like
+// `Server=${AZURE_SQL_HOST}`, in the values of the environment. It may be set in the `.env` file of the environment or in
+// the environment of the azd process. Expansion is disabled by default, so values with a literal `$` are read as is.
+const ExpandReferencesEnvVarName = "AZD_ENV_EXPAND_REFERENCES"
+
+// ErrCircularReference is returned when resolving a value which references itself, directly or through other values.
+var ErrCircularReference = errors.New("circular reference")
+
+// Resolve returns the value of key, with the `${NAME}` references in the values of the `.env` file expanded to the
+// values of the environment. References are resolved recursively, so a value may reference a value with references
+// itself, and fail with ErrCircularReference when a value references itself.
+func (e *Environment) Resolve(key string) (string, error) {
+	value, _, err := e.resolve(key, nil)
+	return value, err
+}
+
+func (e *Environment) resolve(key string, visiting []string) (string, bool, error) {
+	e.mu.RLock()
+	value, has := e.dotenv[key]
+	e.mu.RUnlock()
+
+	// Only the values of the .env file are expanded, the ones of the azd process are read as is
+	if !has {
+		value, has := os.LookupEnv(key)
+		return value, has, nil
+	}
+
+	if slices.Contains(visiting, key) {
+		return "", true, fmt.Errorf("%w: %s", ErrCircularReference, strings.Join(append(visiting, key), " -> "))
+	}
+
+	visiting = append(visiting, key)
+
+	var referenceErr error
+	expanded, err := osutil.NewExpandableString(value).Envsubst(func(name string) string {
+		reference, _, err := e.resolve(name, visiting)
+		if err != nil && referenceErr == nil {
+			referenceErr = err
+		}
+
+		return reference
+	})
+	if referenceErr != nil {
+		return "", true, referenceErr
+	}
+	if err != nil {
+		return "", true, fmt.Errorf("expanding references in '%s': %w", key, err)
+	}
+
+	return expanded, true, nil
+}
+
+// expandReferences returns whether the references in the values of the environment are expanded when read.
+func (e *Environment) expandReferences() bool {
+	e.mu.RLock()
+	value, has := e.dotenv[ExpandReferencesEnvVarName]
+	e.mu.RUnlock()
+
+	if !has {
+		value = os.Getenv(ExpandReferencesEnvVarName)
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	return err == nil && enabled
+}
+
+// lookupExpanded looks up the value of key, expanding its references when enabled. Values which can't be expanded, like
+// values with circular references, are returned as is.
+func (e *Environment) lookupExpanded(key string) (string, bool) {
+	if value, has := e.lookupOverlay(key); has {
+		return value, true
+	}
+
+	if !e.expandReferences() {
+		e.mu.RLock()
+		value, has := e.dotenv[key]
+		e.mu.RUnlock()
+
+		if has {
+			return value, true
+		}
+
+		return os.LookupEnv(key)
+	}
+
+	value, has, err := e.resolve(key, nil)
+	if err != nil {
+		log.Printf("reading '%s' without expanding its references: %v", key, err)
+
+		e.mu.RLock()
+		value = e.dotenv[key]
+		e.mu.RUnlock()
+	}
+
+	return value, has
+}
@@ -0,0 +1,132 @@
+package environment
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"golang.org/x/crypto/scrypt"
+)
+
+// SecretDotEnvFileName is the name of the encrypted file with the secrets of an environment, stored next to its `.env`
+// file.
+const SecretDotEnvFileName = ".env.secret"
+
+// SecretPassphraseEnvVarName is the name of the environment variable with the passphrase used to decrypt the
+// `.env.secret` file of an environment.
+const SecretPassphraseEnvVarName = "AZD_ENV_SECRET_PASSPHRASE"
+
+// ErrSecretPassphraseRequired is returned when an environment has a `.env.secret` file but no passphrase is set to
+// decrypt it.
+var ErrSecretPassphraseRequired = fmt.Errorf(
+	"%s is encrypted, set %s to the passphrase to decrypt it", SecretDotEnvFileName, SecretPassphraseEnvVarName)
+
+// secretFileHeader prefixes the contents of an encrypted `.env.secret` file, versioning its format. The header is followed
+// by the base64 encoded salt, nonce and ciphertext, separated by colons.
+const secretFileHeader = "azd-env-secret:v1"
+
+// The scrypt parameters used to derive the AES-256 key from the passphrase.
+const (
+	secretKeyScryptN = 1 << 15
+	secretKeyScryptR = 8
+	secretKeyScryptP = 1
+	secretKeyLength  = 32
+	secretSaltLength = 16
+)
+
+// EncryptSecrets encrypts the secrets with the passphrase, returning the contents of a `.env.secret` file. The secrets
+// are marshalled as a dotenv file and encrypted with AES-GCM, using a key derived from the passphrase with scrypt.
+func EncryptSecrets(secrets map[string]string, passphrase string) ([]byte, error) {
+	plaintext, err := godotenv.Marshal(secrets)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling secrets: %w", err)
+	}
+
+	salt := make([]byte, secretSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generating salt: %w", err)
+	}
+
+	aead, err := newSecretCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generating nonce: %w", err)
+	}
+
+	ciphertext := aead.Seal(nil, nonce, []byte(plaintext), []byte(secretFileHeader))
+
+	return []byte(strings.Join([]string{
+		secretFileHeader,
+		base64.StdEncoding.EncodeToString(salt),
+		base64.StdEncoding.EncodeToString(nonce),
+		base64.StdEncoding.EncodeToString(ciphertext),
+	}, ":") + "\n"), nil
+}
+
+// DecryptSecrets decrypts the contents of a `.env.secret` file encrypted with [EncryptSecrets], returning the secrets.
+func DecryptSecrets(contents []byte, passphrase string) (map[string]string, error) {
+	encoded, has := strings.CutPrefix(strings.TrimSpace(string(contents)), secretFileHeader+":")
+	if !has {
+		return nil, fmt.Errorf("%s is not an encrypted secrets file", SecretDotEnvFileName)
+	}
+
+	parts := strings.Split(encoded, ":")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%s is malformed", SecretDotEnvFileName)
+	}
+
+	decoded := make([][]byte, len(parts))
+	for i, part := range parts {
+		value, err := base64.StdEncoding.DecodeString(part)
+		if err != nil {
+			return nil, fmt.Errorf("%s is malformed: %w", SecretDotEnvFileName, err)
+		}
+
+		decoded[i] = value
+	}
+
+	salt, nonce, ciphertext := decoded[0], decoded[1], decoded[2]
+	aead, err := newSecretCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("%s is malformed: invalid nonce", SecretDotEnvFileName)
+	}
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(secretFileHeader))
+	if err != nil {
+		return nil, errors.New("decrypting " + SecretDotEnvFileName + ": the passphrase is incorrect or the file is corrupted")
+	}
+
+	secrets, err := godotenv.Unmarshal(string(plaintext))
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", SecretDotEnvFileName, err)
+	}
+
+	return secrets, nil
+}
+
+func newSecretCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, secretKeyScryptN, secretKeyScryptR, secretKeyScryptP, secretKeyLength)
+	if err != nil {
+		return nil, fmt.Errorf("deriving key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("creating cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
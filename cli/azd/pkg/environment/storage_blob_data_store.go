@@ -117,6 +117,11 @@ func (sbd *StorageBlobDataStore) Get(ctx context.Context, name string) (*Environ
 }
 
 func (sbd *StorageBlobDataStore) Save(ctx context.Context, env *Environment) error {
+	// Secrets would otherwise be dropped, since they are never written to the .env file
+	if secrets, _ := env.secrets(); len(secrets) > 0 {
+		return fmt.Errorf("%s secrets are not supported by remote environments", SecretDotEnvFileName)
+	}
+
 	// Update configuration
 	cfgWriter := new(bytes.Buffer)
 
@@ -154,13 +159,11 @@ func (sbd *StorageBlobDataStore) Reload(ctx context.Context, env *Environment) e
 
 	envMap, err := godotenv.Parse(dotEnvBuffer)
 	if err != nil {
-		env.dotenv = make(map[string]string)
-		env.deletedKeys = make(map[string]struct{})
-	} else {
-		env.dotenv = envMap
-		env.deletedKeys = make(map[string]struct{})
+		envMap = nil
 	}
 
+	env.reload(envMap, nil)
+
 	// Reload config file
 	configBuffer, err := sbd.blobClient.Download(ctx, sbd.ConfigPath(env))
 	if err != nil {
@@ -109,7 +109,7 @@ func (r *commandRunner) Run(ctx context.Context, args RunArgs) (RunResult, error
 
 	var stdout, stderr bytes.Buffer
 
-	cmd.Env = appendEnv(args.Env)
+	cmd.Env = commandEnv(args)
 
 	if args.Interactive {
 		cmd.Stdin = r.stdin
@@ -196,7 +196,7 @@ func (r *commandRunner) RunList(ctx context.Context, commands []string, args Run
 	}
 
 	process.Cmd.Dir = args.Cwd
-	process.Env = appendEnv(args.Env)
+	process.Env = commandEnv(args)
 
 	var stdOutBuf bytes.Buffer
 	var stdErrBuf bytes.Buffer
@@ -250,14 +250,6 @@ func (r *commandRunner) RunList(ctx context.Context, commands []string, args Run
 	return result, err
 }
 
-func appendEnv(env []string) []string {
-	if len(env) > 0 {
-		return append(os.Environ(), env...)
-	}
-
-	return nil
-}
-
 // logBuilder builds messages for running of commands.
 type logBuilder struct {
 	args []string
@@ -0,0 +1,91 @@
+package exec
+
+import (
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// EnvAllowListEnvVarName is the name of the environment variable with the comma separated names of the variables of
+// the azd process passed to commands run with a restricted environment, like hooks. A name ending with '*' allows all
+// the variables starting with it, like 'ARM_*'. When not set, the whole environment of the azd process is passed.
+const EnvAllowListEnvVarName = "AZD_SUBPROCESS_ENV_ALLOWLIST"
+
+// baseEnvAllowList are the variables always passed to commands run with a restricted environment, which most commands
+// need to run at all.
+var baseEnvAllowList = []string{
+	"PATH",
+	"PATHEXT",
+	"HOME",
+	"USER",
+	"USERPROFILE",
+	"SHELL",
+	"LANG",
+	"TERM",
+	"TMPDIR",
+	"TEMP",
+	"TMP",
+	"APPDATA",
+	"LOCALAPPDATA",
+	"COMSPEC",
+	"SYSTEMDRIVE",
+	"SYSTEMROOT",
+	"WINDIR",
+}
+
+// commandEnv returns the environment of a command run with args, nil meaning the environment of the azd process.
+//
+// The variables of args.Env are added to the environment of the azd process. When args.RestrictEnv is set and an
+// allow-list is set with AZD_SUBPROCESS_ENV_ALLOWLIST, only the allowed variables of the azd process are kept.
+func commandEnv(args RunArgs) []string {
+	allowList, restricted := os.LookupEnv(EnvAllowListEnvVarName)
+	if !args.RestrictEnv || !restricted {
+		if len(args.Env) > 0 {
+			return append(os.Environ(), args.Env...)
+		}
+
+		return nil
+	}
+
+	allowed := slices.Clone(baseEnvAllowList)
+	for _, name := range strings.Split(allowList, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			allowed = append(allowed, name)
+		}
+	}
+
+	// An empty, non-nil environment, so the command doesn't inherit the environment of the azd process
+	env := []string{}
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if envAllowed(name, allowed) {
+			env = append(env, kv)
+		}
+	}
+
+	return append(env, args.Env...)
+}
+
+func envAllowed(name string, allowList []string) bool {
+	// Environment variables are case-insensitive on Windows
+	if runtime.GOOS == "windows" {
+		name = strings.ToUpper(name)
+	}
+
+	for _, allowed := range allowList {
+		if runtime.GOOS == "windows" {
+			allowed = strings.ToUpper(allowed)
+		}
+
+		if prefix, wildcard := strings.CutSuffix(allowed, "*"); wildcard {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if name == allowed {
+			return true
+		}
+	}
+
+	return false
+}
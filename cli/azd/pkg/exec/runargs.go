@@ -13,6 +13,10 @@ type RunArgs struct {
 	Cwd           string
 	Env           []string
 
+	// When set, only the variables of the azd process allowed by AZD_SUBPROCESS_ENV_ALLOWLIST are passed to the command,
+	// along with Env
+	RestrictEnv bool
+
 	// Stderr will receive a copy of the text written to Stderr by
 	// the command.
 	// NOTE: RunResult.Stderr will still contain stderr output.
@@ -70,6 +74,13 @@ func (b RunArgs) WithEnv(env []string) RunArgs {
 	return b
 }
 
+// Updates whether or not the environment of the azd process passed to the command is restricted to the variables
+// allowed by AZD_SUBPROCESS_ENV_ALLOWLIST
+func (b RunArgs) WithRestrictedEnv(restrict bool) RunArgs {
+	b.RestrictEnv = restrict
+	return b
+}
+
 // Updates whether or not this will be an interactive commands
 // Interactive command sets stdin, stdout & stderr to the OS console/terminal
 func (b RunArgs) WithInteractive(interactive bool) RunArgs {
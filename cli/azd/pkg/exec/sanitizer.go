@@ -2,7 +2,9 @@ package exec
 
 import (
 	"regexp"
+	"slices"
 	"strings"
+	"sync"
 )
 
 type redactData struct {
@@ -27,7 +29,44 @@ func RedactSensitiveArgs(args []string, sensitiveDataMatch []string) []string {
 	return redactedArgs
 }
 
+var (
+	sensitiveData   []string
+	sensitiveDataMu sync.RWMutex
+)
+
+// minSensitiveDataLength is the length of the shortest value redacted by RedactSensitiveData. Shorter values, like
+// 'true' or a port number, would redact unrelated parts of the messages.
+const minSensitiveDataLength = 8
+
+// AddSensitiveData registers values, like the secrets of an environment, which are redacted from the messages
+// sanitized with RedactSensitiveData. Values shorter than 8 characters are ignored.
+func AddSensitiveData(values ...string) {
+	sensitiveDataMu.Lock()
+	defer sensitiveDataMu.Unlock()
+
+	for _, value := range values {
+		if len(value) >= minSensitiveDataLength && !slices.Contains(sensitiveData, value) {
+			sensitiveData = append(sensitiveData, value)
+		}
+	}
+}
+
+// RedactSensitiveValues redacts the values registered with AddSensitiveData from msg. Unlike RedactSensitiveData, it
+// doesn't redact the values of arguments, so it suits messages like URLs and JSON documents.
+func RedactSensitiveValues(msg string) string {
+	sensitiveDataMu.RLock()
+	defer sensitiveDataMu.RUnlock()
+
+	for _, value := range sensitiveData {
+		msg = strings.ReplaceAll(msg, value, cRedacted)
+	}
+
+	return msg
+}
+
 func RedactSensitiveData(msg string) string {
+	msg = RedactSensitiveValues(msg)
+
 	var regexpRedactRules = map[string]redactData{
 		"access token": {
 			regexp.MustCompile("\"accessToken\": \".*\""),
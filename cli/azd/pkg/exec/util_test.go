@@ -85,19 +85,72 @@ func TestKillCommand(t *testing.T) {
 }
 
 func TestAppendEnv(t *testing.T) {
-	require.Nil(t, appendEnv([]string{}))
-	require.Nil(t, appendEnv(nil))
+	require.Nil(t, commandEnv(RunArgs{Env: []string{}}))
+	require.Nil(t, commandEnv(RunArgs{}))
 
 	expectedEnv := os.Environ()
 	expectedEnv = append(expectedEnv, "azd_random_var=world")
 	sort.Strings(expectedEnv)
 
-	actualEnv := appendEnv([]string{"azd_random_var=world"})
+	actualEnv := commandEnv(RunArgs{Env: []string{"azd_random_var=world"}})
 	sort.Strings(actualEnv)
 
 	require.Equal(t, expectedEnv, actualEnv)
 }
 
+func TestCommandEnv_AllowList(t *testing.T) {
+	t.Setenv("AZD_TEST_ALLOWED", "allowed")
+	t.Setenv("ARM_TEST_CLIENT_ID", "client")
+	t.Setenv("AZD_TEST_SECRET", "secret")
+
+	t.Run("NotRestricted", func(t *testing.T) {
+		t.Setenv(EnvAllowListEnvVarName, "AZD_TEST_ALLOWED")
+
+		// Commands which don't restrict their environment, like tools, inherit the whole environment
+		require.Nil(t, commandEnv(RunArgs{}))
+	})
+
+	t.Run("NoAllowList", func(t *testing.T) {
+		// Restores the allow-list, if any, once the test completes
+		t.Setenv(EnvAllowListEnvVarName, "")
+		os.Unsetenv(EnvAllowListEnvVarName)
+
+		env := commandEnv(RunArgs{RestrictEnv: true, Env: []string{"AZURE_ENV_NAME=dev"}})
+		require.Contains(t, env, "AZD_TEST_SECRET=secret")
+		require.Contains(t, env, "AZURE_ENV_NAME=dev")
+	})
+
+	t.Run("AllowList", func(t *testing.T) {
+		t.Setenv(EnvAllowListEnvVarName, "AZD_TEST_ALLOWED, ARM_TEST_*")
+
+		env := commandEnv(RunArgs{RestrictEnv: true, Env: []string{"AZURE_ENV_NAME=dev"}})
+		require.Contains(t, env, "AZD_TEST_ALLOWED=allowed")
+		require.Contains(t, env, "ARM_TEST_CLIENT_ID=client")
+		require.Contains(t, env, "AZURE_ENV_NAME=dev")
+		require.Contains(t, env, "PATH="+os.Getenv("PATH"))
+		require.NotContains(t, env, "AZD_TEST_SECRET=secret")
+	})
+
+	t.Run("SpawnedProcess", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("uses sh")
+		}
+
+		t.Setenv(EnvAllowListEnvVarName, "AZD_TEST_ALLOWED")
+
+		runner := NewCommandRunner(nil)
+		res, err := runner.Run(context.Background(), NewRunArgs("sh", "-c", "env").
+			WithEnv([]string{"AZURE_ENV_NAME=dev"}).
+			WithRestrictedEnv(true))
+		require.NoError(t, err)
+
+		require.Contains(t, res.Stdout, "AZD_TEST_ALLOWED=allowed")
+		require.Contains(t, res.Stdout, "AZURE_ENV_NAME=dev")
+		require.NotContains(t, res.Stdout, "AZD_TEST_SECRET")
+		require.NotContains(t, res.Stdout, "ARM_TEST_CLIENT_ID")
+	})
+}
+
 func TestRunList(t *testing.T) {
 	runner := NewCommandRunner(nil)
 	res, err := runner.RunList(context.Background(), []string{
@@ -225,3 +278,19 @@ func TestRedactSensitiveData(t *testing.T) {
 		})
 	}
 }
+
+func TestRedactSensitiveValues(t *testing.T) {
+	AddSensitiveData("registeredSecretValue")
+
+	// Only the registered values are redacted, the values of arguments are kept
+	require.Equal(t,
+		"https://management.azure.com/resource?api-version=2021-04-01&key=<redacted>",
+		RedactSensitiveValues("https://management.azure.com/resource?api-version=2021-04-01&key=registeredSecretValue"))
+}
+
+func TestAddSensitiveDataMinLength(t *testing.T) {
+	AddSensitiveData("true", "8080")
+
+	// Short values would redact unrelated parts of the messages
+	require.Equal(t, "enabled: true, port: 8080", RedactSensitiveData("enabled: true, port: 8080"))
+}
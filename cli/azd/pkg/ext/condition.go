@@ -0,0 +1,273 @@
+package ext
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidCondition is returned when the condition of a hook is not a valid expression.
+var ErrInvalidCondition = errors.New("invalid condition")
+
+// LookupFn looks up the value of a variable referenced by a condition.
+type LookupFn func(name string) (string, bool)
+
+// EvaluateCondition evaluates the condition of a hook, like `${CI} == true && ${SKIP_TESTS} != true`, with the variables
+// referenced as `${NAME}` looked up with lookup.
+//
+// Conditions support the `==` and `!=` comparisons, the `&&`, `||` and `!` operators and parentheses. Values are
+// variables, quoted strings, or bare words like `true`. Values are compared as booleans when both are booleans, like
+// `True` and `1`, and as strings otherwise. A value on its own, like `${DEPLOY_DOCS}`, is true unless it is empty or a
+// false boolean.
+func EvaluateCondition(condition string, lookup LookupFn) (bool, error) {
+	tokens, err := tokenizeCondition(condition)
+	if err != nil {
+		return false, fmt.Errorf("%w '%s': %w", ErrInvalidCondition, condition, err)
+	}
+
+	parser := &conditionParser{tokens: tokens, lookup: lookup}
+	result, err := parser.parseOr()
+	if err == nil && parser.pos < len(tokens) {
+		err = fmt.Errorf("unexpected '%s' at position %d", tokens[parser.pos].text, tokens[parser.pos].offset+1)
+	}
+	if err != nil {
+		return false, fmt.Errorf("%w '%s': %w", ErrInvalidCondition, condition, err)
+	}
+
+	return result, nil
+}
+
+type conditionTokenKind int
+
+const (
+	tokenValue conditionTokenKind = iota
+	tokenVariable
+	tokenOperator
+	tokenOpenParen
+	tokenCloseParen
+)
+
+type conditionToken struct {
+	kind conditionTokenKind
+	// The operator, the name of the variable, or the value
+	text string
+	// The offset of the token in the condition, to report errors
+	offset int
+}
+
+var conditionOperators = []string{"==", "!=", "&&", "||", "!"}
+
+func tokenizeCondition(condition string) ([]conditionToken, error) {
+	var tokens []conditionToken
+	for i := 0; i < len(condition); {
+		c := rune(condition[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(':
+			tokens = append(tokens, conditionToken{kind: tokenOpenParen, text: "(", offset: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, conditionToken{kind: tokenCloseParen, text: ")", offset: i})
+			i++
+		case strings.HasPrefix(condition[i:], "${"):
+			end := strings.IndexByte(condition[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("missing '}' for variable at position %d", i+1)
+			}
+
+			name := strings.TrimSpace(condition[i+2 : i+end])
+			if name == "" {
+				return nil, fmt.Errorf("missing variable name at position %d", i+1)
+			}
+
+			tokens = append(tokens, conditionToken{kind: tokenVariable, text: name, offset: i})
+			i += end + 1
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(condition[i+1:], condition[i])
+			if end < 0 {
+				return nil, fmt.Errorf("missing closing quote for string at position %d", i+1)
+			}
+
+			tokens = append(tokens, conditionToken{kind: tokenValue, text: condition[i+1 : i+1+end], offset: i})
+			i += end + 2
+		default:
+			operator := ""
+			for _, candidate := range conditionOperators {
+				if strings.HasPrefix(condition[i:], candidate) {
+					operator = candidate
+					break
+				}
+			}
+
+			if operator != "" {
+				tokens = append(tokens, conditionToken{kind: tokenOperator, text: operator, offset: i})
+				i += len(operator)
+				continue
+			}
+
+			if strings.ContainsRune("=&|$", c) {
+				return nil, fmt.Errorf("unexpected '%c' at position %d", c, i+1)
+			}
+
+			start := i
+			for i < len(condition) && !unicode.IsSpace(rune(condition[i])) &&
+				!strings.ContainsRune("()!=&|'\"$", rune(condition[i])) {
+				i++
+			}
+
+			tokens = append(tokens, conditionToken{kind: tokenValue, text: condition[start:i], offset: start})
+		}
+	}
+
+	if len(tokens) == 0 {
+		return nil, errors.New("empty expression")
+	}
+
+	return tokens, nil
+}
+
+// conditionParser evaluates the tokens of a condition with a recursive descent parser, following the grammar:
+//
+//	or         = and { "||" and }
+//	and        = unary { "&&" unary }
+//	unary      = "!" unary | "(" or ")" | comparison
+//	comparison = value [ ( "==" | "!=" ) value ]
+type conditionParser struct {
+	tokens []conditionToken
+	pos    int
+	lookup LookupFn
+}
+
+func (p *conditionParser) peekOperator(operator string) bool {
+	return p.pos < len(p.tokens) && p.tokens[p.pos].kind == tokenOperator && p.tokens[p.pos].text == operator
+}
+
+func (p *conditionParser) parseOr() (bool, error) {
+	result, err := p.parseAnd()
+	if err != nil {
+		return false, err
+	}
+
+	for p.peekOperator("||") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return false, err
+		}
+
+		result = result || right
+	}
+
+	return result, nil
+}
+
+func (p *conditionParser) parseAnd() (bool, error) {
+	result, err := p.parseUnary()
+	if err != nil {
+		return false, err
+	}
+
+	for p.peekOperator("&&") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return false, err
+		}
+
+		result = result && right
+	}
+
+	return result, nil
+}
+
+func (p *conditionParser) parseUnary() (bool, error) {
+	if p.peekOperator("!") {
+		p.pos++
+		result, err := p.parseUnary()
+		return !result, err
+	}
+
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == tokenOpenParen {
+		open := p.tokens[p.pos]
+		p.pos++
+		result, err := p.parseOr()
+		if err != nil {
+			return false, err
+		}
+
+		if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != tokenCloseParen {
+			return false, fmt.Errorf("missing ')' for '(' at position %d", open.offset+1)
+		}
+
+		p.pos++
+		return result, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *conditionParser) parseComparison() (bool, error) {
+	left, err := p.parseValue()
+	if err != nil {
+		return false, err
+	}
+
+	if !p.peekOperator("==") && !p.peekOperator("!=") {
+		return isTruthy(left), nil
+	}
+
+	operator := p.tokens[p.pos].text
+	p.pos++
+
+	right, err := p.parseValue()
+	if err != nil {
+		return false, err
+	}
+
+	equal := valuesEqual(left, right)
+	if operator == "!=" {
+		return !equal, nil
+	}
+
+	return equal, nil
+}
+
+func (p *conditionParser) parseValue() (string, error) {
+	if p.pos >= len(p.tokens) {
+		return "", errors.New("expected a value at the end of the expression")
+	}
+
+	token := p.tokens[p.pos]
+	switch token.kind {
+	case tokenValue:
+		p.pos++
+		return token.text, nil
+	case tokenVariable:
+		p.pos++
+		value, _ := p.lookup(token.text)
+		return value, nil
+	default:
+		return "", fmt.Errorf("expected a value but found '%s' at position %d", token.text, token.offset+1)
+	}
+}
+
+func isTruthy(value string) bool {
+	if boolValue, err := strconv.ParseBool(value); err == nil {
+		return boolValue
+	}
+
+	return value != ""
+}
+
+func valuesEqual(left string, right string) bool {
+	leftBool, leftErr := strconv.ParseBool(left)
+	rightBool, rightErr := strconv.ParseBool(right)
+	if leftErr == nil && rightErr == nil {
+		return leftBool == rightBool
+	}
+
+	return left == right
+}
@@ -0,0 +1,74 @@
+package ext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_EvaluateCondition(t *testing.T) {
+	vars := map[string]string{
+		"CI":          "True",
+		"SKIP_TESTS":  "false",
+		"DEPLOY_DOCS": "docs",
+		"REGION":      "east us",
+	}
+	lookup := func(name string) (string, bool) {
+		value, has := vars[name]
+		return value, has
+	}
+
+	tests := []struct {
+		condition string
+		expected  bool
+	}{
+		{"${CI} == true", true},
+		{"${CI} != true", false},
+		{"${CI} == 1", true},
+		{"${SKIP_TESTS}", false},
+		{"!${SKIP_TESTS}", true},
+		{"${DEPLOY_DOCS}", true},
+		{"${NOT_SET}", false},
+		{"${NOT_SET} == ''", true},
+		{"${REGION} == 'east us'", true},
+		{"${REGION} == \"west us\"", false},
+		{"${CI} == true && ${SKIP_TESTS} != true", true},
+		{"${CI} == false || ${DEPLOY_DOCS} == docs", true},
+		{"${CI} == true && (${SKIP_TESTS} == true || ${NOT_SET})", false},
+		{"!(${CI} == true)", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			result, err := EvaluateCondition(tt.condition, lookup)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func Test_EvaluateCondition_Invalid(t *testing.T) {
+	lookup := func(name string) (string, bool) { return "", false }
+
+	tests := []struct {
+		condition     string
+		expectedError string
+	}{
+		{"", "empty expression"},
+		{"${CI} ==", "expected a value at the end of the expression"},
+		{"${CI == true", "missing '}' for variable at position 1"},
+		{"${CI} = true", "unexpected '=' at position 7"},
+		{"(${CI} == true", "missing ')' for '(' at position 1"},
+		{"${CI} == 'true", "missing closing quote for string at position 10"},
+		{"${CI} true", "unexpected 'true' at position 7"},
+		{"&& ${CI}", "expected a value but found '&&' at position 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			_, err := EvaluateCondition(tt.condition, lookup)
+			require.ErrorIs(t, err, ErrInvalidCondition)
+			require.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
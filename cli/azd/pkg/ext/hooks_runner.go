@@ -2,17 +2,21 @@ package ext
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"slices"
 	"strings"
 
+	"github.com/azure/azure-dev/cli/azd/pkg/cleanup"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/bash"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/powershell"
 )
 
@@ -26,8 +30,22 @@ type HooksRunner struct {
 	hooks         map[string]*HookConfig
 	env           *environment.Environment
 	envManager    environment.Manager
+	services      []HookService
 }
 
+// HookService is a service of the project, which hooks configured with forEachService run for
+type HookService struct {
+	Name string
+	Path string
+}
+
+const (
+	// ServiceNameEnvVarName is the environment variable set to the name of the service hooks run for
+	ServiceNameEnvVarName = "AZURE_SERVICE_NAME"
+	// ServicePathEnvVarName is the environment variable set to the path of the service hooks run for
+	ServicePathEnvVarName = "AZURE_SERVICE_PATH"
+)
+
 // NewHooks creates a new instance of CommandHooks
 // When `cwd` is empty defaults to current shell working directory
 func NewHooksRunner(
@@ -59,6 +77,13 @@ func NewHooksRunner(
 	}
 }
 
+// WithServices sets the services of the project, which hooks configured with forEachService run for. Runners of
+// service hooks don't set services, and fail hooks configured with forEachService.
+func (h *HooksRunner) WithServices(services []HookService) *HooksRunner {
+	h.services = services
+	return h
+}
+
 // Invokes an action run runs any registered pre or post script hooks for the specified command.
 func (h *HooksRunner) Invoke(ctx context.Context, commands []string, actionFn InvokeFn) error {
 	err := h.RunHooks(ctx, HookTypePre, nil, commands...)
@@ -96,11 +121,35 @@ func (h *HooksRunner) RunHooks(
 			return fmt.Errorf("reloading environment before running hook: %w", err)
 		}
 
-		err := h.execHook(ctx, hookConfig, options)
+		if hookConfig.Condition != "" {
+			run, err := EvaluateCondition(hookConfig.Condition, h.env.LookupEnv)
+			if err != nil {
+				return fmt.Errorf("'%s' hook: %w", hookConfig.Name, err)
+			}
+
+			if !run {
+				log.Printf("skipping '%s' hook since its condition '%s' is false", hookConfig.Name, hookConfig.Condition)
+				h.console.Message(ctx, output.WithGrayFormat(
+					"Skipping '%s' hook since its condition '%s' is false", hookConfig.Name, hookConfig.Condition))
+				continue
+			}
+		}
+
+		if hookConfig.ForEachService {
+			err = h.execHookForEachService(ctx, hookConfig, options)
+		} else {
+			err = h.execHook(ctx, hookConfig, options, h.env.Environ())
+		}
 		if err != nil {
 			return err
 		}
 
+		// Delete any temporary inline scripts after execution
+		// Removing temp scripts only on success to support better debugging with failing scripts.
+		if hookConfig.location == ScriptLocationInline {
+			os.Remove(hookConfig.path)
+		}
+
 		if err := h.envManager.Reload(ctx, h.env); err != nil {
 			return fmt.Errorf("reloading environment after running hook: %w", err)
 		}
@@ -112,15 +161,28 @@ func (h *HooksRunner) RunHooks(
 // Gets the script to execute based on the hook configuration values
 // For inline scripts this will also create a temporary script file to execute
 func (h *HooksRunner) GetScript(hookConfig *HookConfig) (tools.Script, error) {
+	return h.getScript(hookConfig, h.env.Environ())
+}
+
+func (h *HooksRunner) getScript(hookConfig *HookConfig, envVars []string) (tools.Script, error) {
 	if err := hookConfig.validate(); err != nil {
 		return nil, err
 	}
 
+	if hookConfig.RunInContainer != nil {
+		switch hookConfig.Shell {
+		case ShellTypeBash, ShellTypePowershell:
+			return docker.NewContainerScript(
+				h.commandRunner, hookConfig.RunInContainer.Image, string(hookConfig.Shell), h.cwd, envVars,
+			), nil
+		}
+	}
+
 	switch hookConfig.Shell {
 	case ShellTypeBash:
-		return bash.NewBashScript(h.commandRunner, h.cwd, h.env.Environ()), nil
+		return bash.NewBashScript(h.commandRunner, h.cwd, envVars), nil
 	case ShellTypePowershell:
-		return powershell.NewPowershellScript(h.commandRunner, h.cwd, h.env.Environ()), nil
+		return powershell.NewPowershellScript(h.commandRunner, h.cwd, envVars), nil
 	default:
 		return nil, fmt.Errorf(
 			"shell type '%s' is not a valid option. Only 'sh' and 'pwsh' are supported",
@@ -129,16 +191,92 @@ func (h *HooksRunner) GetScript(hookConfig *HookConfig) (tools.Script, error) {
 	}
 }
 
-func (h *HooksRunner) execHook(ctx context.Context, hookConfig *HookConfig, options *tools.ExecOptions) error {
-	if options == nil {
-		options = &tools.ExecOptions{}
+// execHookForEachService runs the hook once for each service of the project, with the name and path of the service
+// set in the environment of the hook. The hook runs for all the services even when it fails for some of them.
+func (h *HooksRunner) execHookForEachService(
+	ctx context.Context,
+	hookConfig *HookConfig,
+	options *tools.ExecOptions,
+) error {
+	// Services are only set for project hooks
+	if h.services == nil {
+		return fmt.Errorf("hook '%s': %w", hookConfig.Name, ErrForEachServiceNotSupported)
+	}
+
+	if len(h.services) == 0 {
+		log.Printf("no services to run hook '%s' for", hookConfig.Name)
+		return nil
+	}
+
+	var errs []error
+	for _, service := range h.services {
+		envVars := append(
+			h.env.Environ(),
+			fmt.Sprintf("%s=%s", ServiceNameEnvVarName, service.Name),
+			fmt.Sprintf("%s=%s", ServicePathEnvVarName, service.Path),
+		)
+
+		if err := h.execHook(ctx, hookConfig, options, envVars); err != nil {
+			errs = append(errs, fmt.Errorf("service '%s': %w", service.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// envVarsMapping returns a mapping looking up the values of variables in the list of KEY=VALUE pairs envVars, and in
+// fallback for the variables missing from the list.
+func envVarsMapping(envVars []string, fallback func(string) string) func(string) string {
+	values := make(map[string]string, len(envVars))
+	for _, envVar := range envVars {
+		name, value, _ := strings.Cut(envVar, "=")
+		values[name] = value
+	}
+
+	return func(name string) string {
+		if value, has := values[name]; has {
+			return value
+		}
+
+		return fallback(name)
+	}
+}
+
+func (h *HooksRunner) execHook(
+	ctx context.Context,
+	hookConfig *HookConfig,
+	options *tools.ExecOptions,
+	envVars []string,
+) error {
+	// The options are updated for the execution, like with the stdout of the previewer, so they're copied to leave the
+	// options of the caller unchanged
+	execOptions := tools.ExecOptions{}
+	if options != nil {
+		execOptions = *options
 	}
+	options = &execOptions
+
+	expandedConfig, args, hookEnvVars, err := hookConfig.expand(envVarsMapping(envVars, h.env.Getenv))
+	if err != nil {
+		return fmt.Errorf("'%s' hook: %w", hookConfig.Name, err)
+	}
+	hookConfig = expandedConfig
+
+	// The variables of the hook take precedence over the values of the environment
+	envVars = append(envVars, hookEnvVars...)
+	// The arguments of the hook follow the ones of the caller
+	options.Args = append(slices.Clone(options.Args), args...)
 
-	script, err := h.GetScript(hookConfig)
+	script, err := h.getScript(hookConfig, envVars)
 	if err != nil {
 		return err
 	}
 
+	// Inline scripts are written to temporary files, which are kept when the hook fails but not when interrupted
+	if hookConfig.location == ScriptLocationInline {
+		cleanup.RemoveOnCancel(ctx, hookConfig.path)
+	}
+
 	formatter := h.console.GetFormatter()
 	consoleInteractive := (formatter == nil || formatter.Kind() == output.NoneFormat)
 	scriptInteractive := consoleInteractive && hookConfig.Interactive
@@ -183,11 +321,5 @@ func (h *HooksRunner) execHook(ctx context.Context, hookConfig *HookConfig, opti
 		}
 	}
 
-	// Delete any temporary inline scripts after execution
-	// Removing temp scripts only on success to support better debugging with failing scripts.
-	if hookConfig.location == ScriptLocationInline {
-		defer os.Remove(hookConfig.path)
-	}
-
 	return nil
 }
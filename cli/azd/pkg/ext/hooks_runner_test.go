@@ -2,7 +2,9 @@ package ext
 
 import (
 	"context"
+	"errors"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -10,6 +12,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/azure/azure-dev/cli/azd/test/mocks/mockenv"
 	"github.com/azure/azure-dev/cli/azd/test/ostest"
@@ -205,6 +208,304 @@ func Test_Hooks_Execute(t *testing.T) {
 	})
 }
 
+func Test_Hooks_RunInContainer(t *testing.T) {
+	cwd := t.TempDir()
+	ostest.Chdir(t, cwd)
+
+	env := environment.NewWithValues("test", map[string]string{"a": "apple"})
+	hooks := map[string]*HookConfig{
+		"predeploy": {
+			Run:            "scripts/predeploy.sh",
+			RunInContainer: &HookContainerConfig{Image: "mcr.microsoft.com/azure-cli:latest"},
+		},
+	}
+
+	ensureScriptsExist(t, hooks)
+
+	envManager := &mockenv.MockEnvManager{}
+	envManager.On("Reload", mock.Anything, env).Return(nil)
+
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "docker run")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		require.Contains(t, strings.Join(args.Args, " "), "-v "+cwd+":/workspace")
+		require.Contains(t, strings.Join(args.Args, " "), "-e a")
+		require.Equal(t, env.Environ(), args.Env)
+		require.Equal(t, []string{"mcr.microsoft.com/azure-cli:latest", "sh", "/workspace/scripts/predeploy.sh"},
+			args.Args[len(args.Args)-3:])
+
+		return exec.NewRunResult(2, "", ""), errors.New("exit code: '2'")
+	})
+
+	hooksManager := NewHooksManager(cwd)
+	runner := NewHooksRunner(hooksManager, mockContext.CommandRunner, envManager, mockContext.Console, cwd, hooks, env)
+	err := runner.RunHooks(*mockContext.Context, HookTypePre, nil, "deploy")
+
+	// The exit code of the hook in the container fails the hook
+	require.ErrorContains(t, err, "'predeploy' hook failed with exit code: '2'")
+}
+
+func Test_Hooks_ForEachService(t *testing.T) {
+	cwd := t.TempDir()
+	ostest.Chdir(t, cwd)
+
+	env := environment.NewWithValues("test", map[string]string{"a": "apple"})
+	hooks := map[string]*HookConfig{
+		"prelint": {
+			Run:            "scripts/lint.sh",
+			ForEachService: true,
+		},
+	}
+
+	ensureScriptsExist(t, hooks)
+
+	envManager := &mockenv.MockEnvManager{}
+	envManager.On("Reload", mock.Anything, env).Return(nil)
+
+	services := []HookService{
+		{Name: "api", Path: filepath.Join(cwd, "src", "api")},
+		{Name: "web", Path: filepath.Join(cwd, "src", "web")},
+		{Name: "worker", Path: filepath.Join(cwd, "src", "worker")},
+	}
+
+	ranServices := map[string]string{}
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "lint.sh")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		require.Equal(t, cwd, args.Cwd)
+		require.Contains(t, args.Env, "a=apple")
+
+		var serviceName, servicePath string
+		for _, envVar := range args.Env {
+			if value, has := strings.CutPrefix(envVar, ServiceNameEnvVarName+"="); has {
+				serviceName = value
+			} else if value, has := strings.CutPrefix(envVar, ServicePathEnvVarName+"="); has {
+				servicePath = value
+			}
+		}
+		ranServices[serviceName] = servicePath
+
+		if serviceName == "web" {
+			return exec.NewRunResult(1, "", "lint failed"), errors.New("exit code: '1'")
+		}
+
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	hooksManager := NewHooksManager(cwd)
+	runner := NewHooksRunner(hooksManager, mockContext.CommandRunner, envManager, mockContext.Console, cwd, hooks, env).
+		WithServices(services)
+	err := runner.RunHooks(*mockContext.Context, HookTypePre, nil, "lint")
+
+	// The hook runs for all the services, even after failing for one of them
+	require.Equal(t, map[string]string{
+		"api":    services[0].Path,
+		"web":    services[1].Path,
+		"worker": services[2].Path,
+	}, ranServices)
+	require.ErrorContains(t, err, "service 'web': 'prelint' hook failed with exit code: '1'")
+	require.NotContains(t, err.Error(), "service 'api'")
+
+	t.Run("ServiceHook", func(t *testing.T) {
+		// Runners of service hooks don't set the services of the project
+		runner := NewHooksRunner(hooksManager, mockContext.CommandRunner, envManager, mockContext.Console, cwd, hooks, env)
+		err := runner.RunHooks(*mockContext.Context, HookTypePre, nil, "lint")
+		require.ErrorIs(t, err, ErrForEachServiceNotSupported)
+	})
+}
+
+func Test_Hooks_Expand(t *testing.T) {
+	cwd := t.TempDir()
+	ostest.Chdir(t, cwd)
+
+	env := environment.NewWithValues("test", map[string]string{
+		"AZURE_ENV_NAME": "test",
+		"SCRIPT_NAME":    "deploy",
+		"a":              "apple",
+	})
+	hooks := map[string]*HookConfig{
+		"predeploy": {
+			Run: "scripts/${SCRIPT_NAME}.sh",
+			Args: []osutil.ExpandableString{
+				osutil.NewExpandableString("--env=${AZURE_ENV_NAME}"),
+				// REGION is unset, its default value is used
+				osutil.NewExpandableString("${REGION:-eastus2}"),
+			},
+			Env: map[string]osutil.ExpandableString{
+				"TARGET": osutil.NewExpandableString("${a}-target"),
+				"TIER":   osutil.NewExpandableString("${TIER:-basic}"),
+			},
+		},
+		"prelint": {
+			Run:            "scripts/lint.sh",
+			ForEachService: true,
+			Args:           []osutil.ExpandableString{osutil.NewExpandableString("${AZURE_SERVICE_NAME}")},
+		},
+		"prepackage": {
+			Shell: ShellTypeBash,
+			Run:   "echo ${LOCAL_VAR}",
+			Args:  []osutil.ExpandableString{osutil.NewExpandableString("${a}")},
+		},
+		"prerestore": {
+			Shell: ShellTypeBash,
+			Run:   "arr=(a b)\nref=arr\necho ${arr[@]} ${#arr[@]} ${!ref}",
+		},
+	}
+
+	ensureScriptsExist(t, hooks)
+	ensureScriptsExist(t, map[string]*HookConfig{"predeploy": {Run: "scripts/deploy.sh"}})
+
+	envManager := &mockenv.MockEnvManager{}
+	envManager.On("Reload", mock.Anything, env).Return(nil)
+
+	var runs []exec.RunArgs
+	var inlineScript string
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return true
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		runs = append(runs, args)
+
+		// Inline scripts are removed once they ran successfully
+		if path := hooks["prepackage"].path; path != "" && strings.Contains(args.Cmd+" "+strings.Join(args.Args, " "), path) {
+			contents, err := os.ReadFile(path)
+			require.NoError(t, err)
+			inlineScript = string(contents)
+		}
+
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	hooksManager := NewHooksManager(cwd)
+	runner := NewHooksRunner(hooksManager, mockContext.CommandRunner, envManager, mockContext.Console, cwd, hooks, env).
+		WithServices([]HookService{{Name: "api", Path: filepath.Join(cwd, "src", "api")}})
+
+	// The arguments are the last arguments of the command running the script
+	scriptArgs := func(args exec.RunArgs, count int) []string {
+		return args.Args[len(args.Args)-count:]
+	}
+
+	t.Run("RunArgsAndEnv", func(t *testing.T) {
+		runs = nil
+		err := runner.RunHooks(*mockContext.Context, HookTypePre, nil, "deploy")
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+
+		require.Contains(t, strings.Join(append([]string{runs[0].Cmd}, runs[0].Args...), " "), "scripts/deploy.sh")
+		require.Equal(t, []string{"--env=test", "eastus2"}, scriptArgs(runs[0], 2))
+		require.Contains(t, runs[0].Env, "TARGET=apple-target")
+		require.Contains(t, runs[0].Env, "TIER=basic")
+		require.Contains(t, runs[0].Env, "a=apple")
+	})
+
+	t.Run("ForEachService", func(t *testing.T) {
+		runs = nil
+		err := runner.RunHooks(*mockContext.Context, HookTypePre, nil, "lint")
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+		require.Equal(t, []string{"api"}, scriptArgs(runs[0], 1))
+	})
+
+	t.Run("InlineScriptNotExpanded", func(t *testing.T) {
+		runs = nil
+		err := runner.RunHooks(*mockContext.Context, HookTypePre, nil, "package")
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+		require.Equal(t, []string{"apple"}, scriptArgs(runs[0], 1))
+
+		// The shell expands the references of inline scripts, like to variables defined by the script itself
+		require.Contains(t, inlineScript, "echo ${LOCAL_VAR}")
+	})
+
+	t.Run("InlineScriptShellSyntax", func(t *testing.T) {
+		runs = nil
+		// The references use bash syntax which isn't valid for azd, like arrays and indirections
+		err := runner.RunHooks(*mockContext.Context, HookTypePre, nil, "restore")
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+	})
+
+	t.Run("CallerOptionsUnchanged", func(t *testing.T) {
+		runs = nil
+		options := &tools.ExecOptions{Args: []string{"--caller"}}
+		err := runner.RunHooks(*mockContext.Context, HookTypePre, options, "deploy")
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+
+		require.Equal(t, []string{"--caller", "--env=test", "eastus2"}, scriptArgs(runs[0], 3))
+		require.Equal(t, &tools.ExecOptions{Args: []string{"--caller"}}, options)
+	})
+}
+
+func Test_Hooks_Condition(t *testing.T) {
+	cwd := t.TempDir()
+	ostest.Chdir(t, cwd)
+
+	env := environment.NewWithValues("test", map[string]string{"DEPLOY_DOCS": "true"})
+	hooks := map[string]*HookConfig{
+		"predeploy": {
+			Run:       "scripts/docs.sh",
+			Condition: "${DEPLOY_DOCS} == true",
+		},
+		"postdeploy": {
+			Run:       "scripts/notify.sh",
+			Condition: "${CI_NOTIFY_URL} != ''",
+		},
+		"prepackage": {
+			Run:       "scripts/package.sh",
+			Condition: "${DEPLOY_DOCS} = true",
+		},
+	}
+
+	ensureScriptsExist(t, hooks)
+
+	envManager := &mockenv.MockEnvManager{}
+	envManager.On("Reload", mock.Anything, env).Return(nil)
+
+	ranScripts := []string{}
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, ".sh")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		ranScripts = append(ranScripts, args.Args[0])
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	hooksManager := NewHooksManager(cwd)
+	runner := NewHooksRunner(hooksManager, mockContext.CommandRunner, envManager, mockContext.Console, cwd, hooks, env)
+
+	t.Run("True", func(t *testing.T) {
+		err := runner.RunHooks(*mockContext.Context, HookTypePre, nil, "deploy")
+		require.NoError(t, err)
+		require.Equal(t, []string{"scripts/docs.sh"}, ranScripts)
+	})
+
+	t.Run("False", func(t *testing.T) {
+		t.Setenv("CI_NOTIFY_URL", "")
+		ranScripts = []string{}
+
+		err := runner.RunHooks(*mockContext.Context, HookTypePost, nil, "deploy")
+		require.NoError(t, err)
+		require.Empty(t, ranScripts)
+		require.Contains(
+			t,
+			mockContext.Console.Output(),
+			"Skipping 'postdeploy' hook since its condition '${CI_NOTIFY_URL} != ''' is false",
+		)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		ranScripts = []string{}
+
+		err := runner.RunHooks(*mockContext.Context, HookTypePre, nil, "package")
+		require.ErrorIs(t, err, ErrInvalidCondition)
+		require.ErrorContains(t, err, "'prepackage' hook: invalid condition '${DEPLOY_DOCS} = true'")
+		require.Empty(t, ranScripts)
+	})
+}
+
 func Test_Hooks_GetScript(t *testing.T) {
 	cwd := t.TempDir()
 	ostest.Chdir(t, cwd)
@@ -365,6 +666,16 @@ func Test_GetScript_Validation(t *testing.T) {
 			},
 			expectedError: ErrRunRequired,
 		},
+		{
+			name: "Missing Container Image",
+			config: &HookConfig{
+				Name:           "test3",
+				Shell:          ShellTypeBash,
+				Run:            "echo 'Hello'",
+				RunInContainer: &HookContainerConfig{},
+			},
+			expectedError: ErrContainerImageRequired,
+		},
 		{
 			name: "Unsupported Script Type",
 			config: &HookConfig{
@@ -36,8 +36,10 @@ var (
 	ErrScriptTypeUnknown error = errors.New(
 		"unable to determine script type. Ensure 'Shell' parameter is set in configuration options",
 	)
-	ErrRunRequired           error = errors.New("run is always required")
-	ErrUnsupportedScriptType error = errors.New("script type is not valid. Only '.sh' and '.ps1' are supported")
+	ErrRunRequired                error = errors.New("run is always required")
+	ErrUnsupportedScriptType      error = errors.New("script type is not valid. Only '.sh' and '.ps1' are supported")
+	ErrContainerImageRequired     error = errors.New("image is required when running in a container")
+	ErrForEachServiceNotSupported error = errors.New("forEachService is only supported by project hooks")
 )
 
 // Generic action function that may return an error
@@ -66,12 +68,38 @@ type HookConfig struct {
 	ContinueOnError bool `yaml:"continueOnError,omitempty"`
 	// When set to true will bind the stdin, stdout & stderr to the running console
 	Interactive bool `yaml:"interactive,omitempty"`
+	// When set the hook only runs when the condition evaluated against the environment is true, like `${CI} == true`
+	Condition string `yaml:"condition,omitempty"`
+	// When set to true on a project hook will run the hook once for each service of the project, with the name and path
+	// of the service set in the AZURE_SERVICE_NAME and AZURE_SERVICE_PATH environment variables
+	ForEachService bool `yaml:"forEachService,omitempty"`
+	// When set will run the hook in a container of the specified image instead of on the host
+	RunInContainer *HookContainerConfig `yaml:"runInContainer,omitempty"`
+	// The arguments passed to the script, which may reference environment variables like `${AZURE_ENV_NAME}`
+	Args []osutil.ExpandableString `yaml:"args,omitempty"`
+	// Environment variables set for the script in addition to the azd environment, which may reference environment
+	// variables like `${AZURE_ENV_NAME}`
+	Env map[string]osutil.ExpandableString `yaml:"env,omitempty"`
 	// When running on windows use this override config
 	Windows *HookConfig `yaml:"windows,omitempty"`
 	// When running on linux/macos use this override config
 	Posix *HookConfig `yaml:"posix,omitempty"`
 }
 
+// RunsForEachService returns whether the hook, or one of its OS specific overrides, is configured with forEachService
+func (hc *HookConfig) RunsForEachService() bool {
+	return hc.ForEachService ||
+		(hc.Windows != nil && hc.Windows.ForEachService) ||
+		(hc.Posix != nil && hc.Posix.ForEachService)
+}
+
+// Container configuration of hooks running in a container, with the project directory mounted as its working
+// directory and the azd environment passed as environment variables
+type HookContainerConfig struct {
+	// The image of the container, like 'mcr.microsoft.com/azure-cli:latest', which must include the hook shell
+	Image string `yaml:"image"`
+}
+
 // Validates and normalizes the hook configuration
 func (hc *HookConfig) validate() error {
 	if hc.validated {
@@ -82,6 +110,10 @@ func (hc *HookConfig) validate() error {
 		return ErrRunRequired
 	}
 
+	if hc.RunInContainer != nil && hc.RunInContainer.Image == "" {
+		return ErrContainerImageRequired
+	}
+
 	relativeCheckPath := strings.ReplaceAll(hc.Run, "/", string(os.PathSeparator))
 	fullCheckPath := relativeCheckPath
 	if hc.cwd != "" {
@@ -98,7 +130,17 @@ func (hc *HookConfig) validate() error {
 	}
 
 	if hc.Shell == ScriptTypeUnknown && hc.path == "" {
-		return ErrScriptTypeUnknown
+		// The path of a script may reference environment variables, which are only expanded when the hook runs
+		if !hc.isExpandablePath() {
+			return ErrScriptTypeUnknown
+		}
+
+		scriptType, err := inferScriptTypeFromFilePath(hc.Run)
+		if err != nil {
+			return ErrScriptTypeUnknown
+		}
+
+		hc.Shell = scriptType
 	}
 
 	if hc.location == ScriptLocationUnknown {
@@ -132,6 +174,64 @@ func (hc *HookConfig) validate() error {
 	return nil
 }
 
+// expand returns the hook configuration to run, with the references to environment variables in its script path
+// expanded, along with its expanded arguments and environment variables. References are expanded with the syntax of
+// [osutil.ExpandableString], like `${AZURE_ENV_NAME}` or `${AZURE_ENV_TYPE:-dev}` for a default value. Inline scripts
+// aren't expanded, since the shell already expands the environment variables they reference.
+func (hc *HookConfig) expand(mapping func(string) string) (*HookConfig, []string, []string, error) {
+	expanded := hc
+
+	if hc.isExpandablePath() {
+		run, err := osutil.NewExpandableString(hc.Run).Envsubst(mapping)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("expanding run: %w", err)
+		}
+
+		runPath := run
+		if hc.cwd != "" {
+			runPath = filepath.Join(hc.cwd, run)
+		}
+
+		if stats, err := os.Stat(runPath); err == nil && !stats.IsDir() {
+			hookCopy := *hc
+			hookCopy.Run = run
+			hookCopy.validated = false
+			hookCopy.location = ScriptLocationUnknown
+			hookCopy.path = ""
+			hookCopy.script = ""
+			expanded = &hookCopy
+		}
+	}
+
+	args := make([]string, 0, len(hc.Args))
+	for i, arg := range hc.Args {
+		value, err := arg.Envsubst(mapping)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("expanding args[%d]: %w", i, err)
+		}
+
+		args = append(args, value)
+	}
+
+	envVars := make([]string, 0, len(hc.Env))
+	for name, envValue := range hc.Env {
+		value, err := envValue.Envsubst(mapping)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("expanding env '%s': %w", name, err)
+		}
+
+		envVars = append(envVars, fmt.Sprintf("%s=%s", name, value))
+	}
+
+	return expanded, args, envVars, nil
+}
+
+// isExpandablePath returns true when run is the path of a script referencing environment variables, like
+// `scripts/${AZURE_ENV_TYPE}.sh`. Inline scripts, which span multiple words or lines, are left to the shell.
+func (hc *HookConfig) isExpandablePath() bool {
+	return strings.Contains(hc.Run, "${") && !strings.ContainsAny(hc.Run, " \t\r\n")
+}
+
 func InferHookType(name string) (HookType, string) {
 	// Validate name length so go doesn't PANIC for string slicing below
 	if len(name) < 4 {
@@ -0,0 +1,64 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package httputil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// CaBundleEnvVarName is the name of the environment variable with the path of a PEM bundle of CA certificates trusted
+// by azd in addition to the system ones, like the CA of a corporate proxy. It takes precedence over the
+// CaBundleConfigPath setting.
+const CaBundleEnvVarName = "AZD_CA_BUNDLE"
+
+// CaBundleConfigPath is the user config setting of the path of the additional CA bundle, set with
+// `azd config set http.caBundle <path>`.
+const CaBundleConfigPath = "http.caBundle"
+
+// NewTransport returns a http.Transport, inheriting the defaults of http.DefaultTransport, which routes requests
+// through the proxy set by the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables and trusts the certificates
+// of the PEM bundle at caBundlePath in addition to the system ones. No additional certificates are trusted when
+// caBundlePath is empty.
+func NewTransport(caBundlePath string) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	// The proxy settings are read when the transport is created, unlike http.ProxyFromEnvironment which reads them
+	// once per process
+	proxy := httpproxy.FromEnvironment().ProxyFunc()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}
+
+	if caBundlePath == "" {
+		return transport, nil
+	}
+
+	pemBytes, err := os.ReadFile(caBundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading CA bundle: %w", err)
+	}
+
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil {
+		rootCAs = x509.NewCertPool()
+	}
+
+	if !rootCAs.AppendCertsFromPEM(pemBytes) {
+		return nil, fmt.Errorf("CA bundle '%s' doesn't contain any PEM certificate", caBundlePath)
+	}
+
+	transport.TLSClientConfig = &tls.Config{
+		RootCAs:    rootCAs,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	return transport, nil
+}
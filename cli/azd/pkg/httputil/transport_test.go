@@ -0,0 +1,71 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package httputil
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTransport_Proxy(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "http://proxy.contoso.com:3128")
+	t.Setenv("NO_PROXY", "internal.contoso.com")
+
+	transport, err := NewTransport("")
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "https://management.azure.com/subscriptions", nil)
+	require.NoError(t, err)
+	proxyUrl, err := transport.Proxy(req)
+	require.NoError(t, err)
+	require.Equal(t, "http://proxy.contoso.com:3128", proxyUrl.String())
+
+	req, err = http.NewRequest(http.MethodGet, "https://internal.contoso.com/api", nil)
+	require.NoError(t, err)
+	proxyUrl, err = transport.Proxy(req)
+	require.NoError(t, err)
+	require.Nil(t, proxyUrl)
+}
+
+func TestNewTransport_CaBundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// The certificate of the test server isn't trusted by default
+	transport, err := NewTransport("")
+	require.NoError(t, err)
+	_, err = (&http.Client{Transport: transport}).Get(server.URL)
+	require.Error(t, err)
+
+	caBundlePath := filepath.Join(t.TempDir(), "ca.pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caBundlePath, pemBytes, osutil.PermissionFile))
+
+	transport, err = NewTransport(caBundlePath)
+	require.NoError(t, err)
+	res, err := (&http.Client{Transport: transport}).Get(server.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestNewTransport_InvalidCaBundle(t *testing.T) {
+	caBundlePath := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caBundlePath, []byte("not a certificate"), osutil.PermissionFile))
+
+	_, err := NewTransport(caBundlePath)
+	require.ErrorContains(t, err, "doesn't contain any PEM certificate")
+
+	_, err = NewTransport(filepath.Join(t.TempDir(), "missing.pem"))
+	require.ErrorContains(t, err, "reading CA bundle")
+}
@@ -11,11 +11,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/azure/azure-dev/cli/azd/pkg/azapi"
 	"github.com/azure/azure-dev/cli/azd/pkg/azure"
 	"github.com/azure/azure-dev/cli/azd/pkg/azureutil"
 	"github.com/azure/azure-dev/cli/azd/pkg/compare"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 )
 
@@ -124,6 +126,108 @@ func (rm *AzureResourceManager) GetDeploymentResourceOperations(
 	return allLevelsDeploymentOperations, nil
 }
 
+// GetFailedDeploymentResources gets the resources that failed to deploy as part of the provided deployment, with the
+// error reported for each of them. Failed nested deployments are traversed recursively to find the failed resources
+// within them.
+func (rm *AzureResourceManager) GetFailedDeploymentResources(
+	ctx context.Context,
+	deployment Deployment,
+) ([]*azapi.DeploymentResourceError, error) {
+	operations, err := deployment.Operations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting deployment operations: %w", err)
+	}
+
+	return rm.failedDeploymentResources(ctx, deployment.SubscriptionId(), operations), nil
+}
+
+// failedDeploymentResources returns the resources of the failed operations. Failed nested deployments are replaced by
+// the failed resources within them, when these can be resolved.
+func (rm *AzureResourceManager) failedDeploymentResources(
+	ctx context.Context,
+	subscriptionId string,
+	operations []*armresources.DeploymentOperation,
+) []*azapi.DeploymentResourceError {
+	var failed []*azapi.DeploymentResourceError
+	for _, operation := range operations {
+		if operation.Properties == nil ||
+			operation.Properties.TargetResource == nil ||
+			!compare.PtrValueEquals(operation.Properties.ProvisioningState, "Failed") {
+			continue
+		}
+
+		target := operation.Properties.TargetResource
+		if compare.PtrValueEquals(target.ResourceType, string(AzureResourceTypeDeployment)) {
+			nested := rm.failedNestedDeploymentResources(ctx, subscriptionId, target)
+			if len(nested) > 0 {
+				failed = append(failed, nested...)
+				continue
+			}
+		}
+
+		resourceError := &azapi.DeploymentResourceError{
+			ResourceType: convert.ToValueWithDefault(target.ResourceType, ""),
+			ResourceName: convert.ToValueWithDefault(target.ResourceName, ""),
+			ResourceId:   convert.ToValueWithDefault(target.ID, ""),
+		}
+		if operation.Properties.StatusMessage != nil && operation.Properties.StatusMessage.Error != nil {
+			rootCause := deploymentErrorRootCause(operation.Properties.StatusMessage.Error)
+			resourceError.Code = convert.ToValueWithDefault(rootCause.Code, "")
+			resourceError.Message = convert.ToValueWithDefault(rootCause.Message, "")
+		}
+
+		failed = append(failed, resourceError)
+	}
+
+	return failed
+}
+
+// failedNestedDeploymentResources returns the failed resources of a nested deployment, at the resource group or
+// subscription scope.
+func (rm *AzureResourceManager) failedNestedDeploymentResources(
+	ctx context.Context,
+	subscriptionId string,
+	target *armresources.TargetResource,
+) []*azapi.DeploymentResourceError {
+	if target.ID == nil || target.ResourceName == nil {
+		return nil
+	}
+
+	var resourceGroupName string
+	if resourceId, err := arm.ParseResourceID(*target.ID); err == nil {
+		resourceGroupName = resourceId.ResourceGroupName
+	}
+
+	var operations []*armresources.DeploymentOperation
+	var err error
+	if resourceGroupName != "" {
+		operations, err = rm.deploymentOperations.ListResourceGroupDeploymentOperations(
+			ctx, subscriptionId, resourceGroupName, *target.ResourceName)
+	} else {
+		operations, err = rm.deploymentOperations.ListSubscriptionDeploymentOperations(
+			ctx, subscriptionId, *target.ResourceName)
+	}
+	if err != nil {
+		// The failed nested deployment is reported instead of the resources within it
+		log.Printf("getting operations of nested deployment '%s': %v", *target.ResourceName, err)
+		return nil
+	}
+
+	return rm.failedDeploymentResources(ctx, subscriptionId, operations)
+}
+
+// deploymentErrorRootCause skips the generic errors wrapping the details of a deployment failure.
+func deploymentErrorRootCause(err *armresources.ErrorResponse) *armresources.ErrorResponse {
+	for len(err.Details) > 0 &&
+		(compare.PtrValueEquals(err.Code, "DeploymentFailed") ||
+			compare.PtrValueEquals(err.Code, "ResourceDeploymentFailure") ||
+			compare.IsStringNilOrEmpty(err.Message)) {
+		err = err.Details[0]
+	}
+
+	return err
+}
+
 // GetResourceGroupsForEnvironment gets all resources groups for a given environment
 func (rm *AzureResourceManager) GetResourceGroupsForEnvironment(
 	ctx context.Context,
@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/azapi"
 	"github.com/azure/azure-dev/cli/azd/pkg/cloud"
 	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
@@ -507,3 +508,188 @@ func TestFindResourceGroupForEnvironment(t *testing.T) {
 		})
 	}
 }
+
+// Operations recorded from a failed subscription deployment, where resources of nested deployments failed.
+var mockFailedSubDeploymentOperations string = `
+{
+	"value": [
+		{
+			"operationId": "op1",
+			"properties": {
+				"provisioningOperation": "Create",
+				"provisioningState": "Succeeded",
+				"targetResource": {
+					"resourceType": "Microsoft.Resources/resourceGroups",
+					"id": "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-dev",
+					"resourceName": "rg-dev"
+				}
+			}
+		},
+		{
+			"operationId": "op2",
+			"properties": {
+				"provisioningOperation": "Create",
+				"provisioningState": "Failed",
+				"statusMessage": {
+					"status": "Failed",
+					"error": {
+						"code": "DeploymentFailed",
+						"message": "At least one resource deployment operation failed.",
+						"details": [{ "code": "Conflict", "message": "Website with given name already exists." }]
+					}
+				},
+				"targetResource": {
+					"resourceType": "Microsoft.Resources/deployments",
+					"id": "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-dev/providers/Microsoft.Resources/deployments/resources",
+					"resourceName": "resources"
+				}
+			}
+		}
+	]
+}
+`
+
+var mockFailedGroupDeploymentOperations string = `
+{
+	"value": [
+		{
+			"operationId": "op3",
+			"properties": {
+				"provisioningOperation": "Create",
+				"provisioningState": "Succeeded",
+				"targetResource": {
+					"resourceType": "Microsoft.Storage/storageAccounts",
+					"id": "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-dev/providers/Microsoft.Storage/storageAccounts/stdev",
+					"resourceName": "stdev"
+				}
+			}
+		},
+		{
+			"operationId": "op4",
+			"properties": {
+				"provisioningOperation": "Create",
+				"provisioningState": "Failed",
+				"statusMessage": {
+					"status": "Failed",
+					"error": { "code": "Conflict", "message": "Website with given name already exists." }
+				},
+				"targetResource": {
+					"resourceType": "Microsoft.Web/sites",
+					"id": "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-dev/providers/Microsoft.Web/sites/app-dev",
+					"resourceName": "app-dev"
+				}
+			}
+		},
+		{
+			"operationId": "op5",
+			"properties": {
+				"provisioningOperation": "Create",
+				"provisioningState": "Failed",
+				"statusMessage": {
+					"status": "Failed",
+					"error": {
+						"code": "DeploymentFailed",
+						"message": "At least one resource deployment operation failed.",
+						"details": [{ "code": "BadRequest", "message": "Unsupported region." }]
+					}
+				},
+				"targetResource": {
+					"resourceType": "Microsoft.Resources/deployments",
+					"id": "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-dev/providers/Microsoft.Resources/deployments/monitoring",
+					"resourceName": "monitoring"
+				}
+			}
+		}
+	]
+}
+`
+
+var mockFailedNestedGroupDeploymentOperations string = `
+{
+	"value": [
+		{
+			"operationId": "op6",
+			"properties": {
+				"provisioningOperation": "Create",
+				"provisioningState": "Failed",
+				"statusMessage": {
+					"status": "Failed",
+					"error": {
+						"code": "ResourceDeploymentFailure",
+						"message": "The resource provision operation did not complete within the allowed timeout period.",
+						"details": [{ "code": "BadRequest", "message": "Unsupported region." }]
+					}
+				},
+				"targetResource": {
+					"resourceType": "Microsoft.Insights/components",
+					"id": "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-dev/providers/Microsoft.Insights/components/appi-dev",
+					"resourceName": "appi-dev"
+				}
+			}
+		}
+	]
+}
+`
+
+func TestGetFailedDeploymentResources(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	azCli := mockazcli.NewAzCliFromMockContext(mockContext)
+	depOpService := mockazcli.NewDeploymentOperationsServiceFromMockContext(mockContext)
+	depService := mockazcli.NewDeploymentsServiceFromMockContext(mockContext)
+	scope := NewSubscriptionDeployment(
+		depService,
+		depOpService,
+		"eastus2",
+		"SUBSCRIPTION_ID",
+		"DEPLOYMENT_NAME",
+		cloud.AzurePublic().PortalUrlBase,
+	)
+
+	mockOperations := func(path string, operations string) {
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, path)
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBuffer([]byte(operations))),
+				Request:    request,
+			}, nil
+		})
+	}
+	mockOperations(
+		"/subscriptions/SUBSCRIPTION_ID/providers/Microsoft.Resources/deployments/DEPLOYMENT_NAME/operations",
+		mockFailedSubDeploymentOperations)
+	mockOperations(
+		"/subscriptions/SUBSCRIPTION_ID/resourcegroups/rg-dev/deployments/resources/operations",
+		mockFailedGroupDeploymentOperations)
+	mockOperations(
+		"/subscriptions/SUBSCRIPTION_ID/resourcegroups/rg-dev/deployments/monitoring/operations",
+		mockFailedNestedGroupDeploymentOperations)
+
+	arm := NewAzureResourceManager(azCli, depOpService)
+	failedResources, err := arm.GetFailedDeploymentResources(*mockContext.Context, scope)
+	require.NoError(t, err)
+	require.Equal(t, []*azapi.DeploymentResourceError{
+		{
+			ResourceType: "Microsoft.Web/sites",
+			ResourceName: "app-dev",
+			ResourceId:   "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-dev/providers/Microsoft.Web/sites/app-dev",
+			Code:         "Conflict",
+			Message:      "Website with given name already exists.",
+		},
+		{
+			ResourceType: "Microsoft.Insights/components",
+			ResourceName: "appi-dev",
+			ResourceId:   "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-dev/providers/Microsoft.Insights/components/appi-dev",
+			Code:         "BadRequest",
+			Message:      "Unsupported region.",
+		},
+	}, failedResources)
+
+	deploymentErr := &azapi.AzureDeploymentError{Resources: failedResources}
+	require.Equal(t, []string{
+		"Deployment failed for the following resources:",
+		"- Microsoft.Web/sites 'app-dev': Conflict: Website with given name already exists.",
+		"- Microsoft.Insights/components 'appi-dev': BadRequest: Unsupported region.",
+	}, strings.Split(strings.TrimSpace(deploymentErr.Error()), "\n"))
+}
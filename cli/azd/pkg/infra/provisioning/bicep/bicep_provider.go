@@ -75,11 +75,15 @@ type BicepProvider struct {
 	alphaFeatureManager   *alpha.FeatureManager
 	clock                 clock.Clock
 	ignoreDeploymentState bool
+	ignoreCompileCache    bool
 	// compileBicepResult is cached to avoid recompiling the same bicep file multiple times in the same azd run.
 	compileBicepMemoryCache *compileBicepResult
 	// prevent resolving parameters multiple times in the same azd run.
-	ensureParamsInMemoryCache azure.ArmParameters
-	keyvaultService           keyvault.KeyVaultService
+	ensureParamsInMemoryCache    azure.ArmParameters
+	keyvaultService              keyvault.KeyVaultService
+	nameAvailabilityService      azapi.NameAvailabilityService
+	resourceTypeLocationsService azapi.ResourceTypeLocationsService
+	moduleRegistryClient         ModuleRegistryClient
 
 	portalUrlBase string
 }
@@ -113,6 +117,7 @@ func (p *BicepProvider) Initialize(ctx context.Context, projectPath string, opti
 		return err
 	}
 	p.ignoreDeploymentState = options.IgnoreDeploymentState
+	p.ignoreCompileCache = options.IgnoreCompileCache
 
 	p.console.ShowSpinner(ctx, "Initialize bicep provider", input.Step)
 	err := p.EnsureEnv(ctx)
@@ -374,6 +379,16 @@ func (p *BicepProvider) plan(ctx context.Context) (*deploymentDetails, error) {
 	p.console.ShowSpinner(ctx, "Creating a deployment plan", input.Step)
 
 	modulePath := p.modulePath()
+	if isBicepParamFile(modulePath) && len(p.options.Parameters) > 0 {
+		return nil, errors.New("--parameter isn't supported with .bicepparam files, set the parameter in the file instead")
+	}
+
+	if p.compileBicepMemoryCache == nil {
+		if err := p.checkRegistryModules(ctx, modulePath); err != nil {
+			return nil, err
+		}
+	}
+
 	// TODO: Report progress, "Compiling Bicep template"
 	compileResult, err := p.compileBicep(ctx, modulePath)
 	if err != nil {
@@ -607,6 +622,16 @@ func (p *BicepProvider) Deploy(ctx context.Context) (*DeployResult, error) {
 		logDS(err.Error())
 	}
 
+	if err := p.checkNameAvailability(
+		ctx, bicepDeploymentData.CompiledBicep.Template, bicepDeploymentData.CompiledBicep.Parameters); err != nil {
+		return nil, err
+	}
+
+	if err := p.checkLocationAvailability(
+		ctx, bicepDeploymentData.CompiledBicep.RawArmTemplate, bicepDeploymentData.CompiledBicep.Parameters); err != nil {
+		return nil, err
+	}
+
 	cancelProgress := make(chan bool)
 	defer func() { cancelProgress <- true }()
 	go func() {
@@ -645,9 +670,12 @@ func (p *BicepProvider) Deploy(ctx context.Context) (*DeployResult, error) {
 	// Start the deployment
 	p.console.ShowSpinner(ctx, "Creating/Updating resources", input.Step)
 
-	deploymentTags := map[string]*string{
-		azure.TagKeyAzdEnvName: to.Ptr(p.env.Name()),
+	resourceGroupTags, err := ResourceGroupTags(p.env, p.options, p.clock.Now())
+	if err != nil {
+		return nil, err
 	}
+
+	deploymentTags := maps.Clone(resourceGroupTags)
 	if parametersHashErr == nil {
 		deploymentTags[azure.TagKeyAzdDeploymentStateParamHashName] = to.Ptr(currentParamsHash)
 	}
@@ -659,9 +687,11 @@ func (p *BicepProvider) Deploy(ctx context.Context) (*DeployResult, error) {
 		deploymentTags,
 	)
 	if err != nil {
-		return nil, err
+		return nil, p.withFailedResources(ctx, bicepDeploymentData.Target, err)
 	}
 
+	p.tagResourceGroups(ctx, deployResult, resourceGroupTags)
+
 	deployment.Outputs = p.createOutputParameters(
 		bicepDeploymentData.CompiledBicep.Template.Outputs,
 		azapi.CreateDeploymentOutput(deployResult.Properties.Outputs),
@@ -838,10 +868,21 @@ func (p *BicepProvider) Destroy(ctx context.Context, options DestroyOptions) (*D
 		return nil, fmt.Errorf("getting resources to delete: %w", err)
 	}
 
+	// Tagged resources are opt-in: the name of an environment is not unique, other projects might use the same one.
+	var taggedResources []azcli.AzCliResource
+	if options.TaggedResources() {
+		// TODO: Report progress, "Fetching tagged resources"
+		taggedResources, err = p.getTaggedResourcesToDelete(ctx, groupedResources)
+		if err != nil {
+			return nil, fmt.Errorf("getting tagged resources to delete: %w", err)
+		}
+	}
+
 	allResources := []azcli.AzCliResource{}
 	for _, groupResources := range groupedResources {
 		allResources = append(allResources, groupResources...)
 	}
+	allResources = append(allResources, taggedResources...)
 
 	// TODO: Report progress, "Getting Key Vaults to purge"
 	keyVaults, err := p.getKeyVaultsToPurge(ctx, groupedResources)
@@ -873,7 +914,7 @@ func (p *BicepProvider) Destroy(ctx context.Context, options DestroyOptions) (*D
 		return nil, fmt.Errorf("getting cognitive accounts to purge: %w", err)
 	}
 
-	if err := p.destroyResourceGroups(ctx, options, groupedResources, len(allResources)); err != nil {
+	if err := p.destroyResourceGroups(ctx, options, groupedResources, taggedResources, len(allResources)); err != nil {
 		return nil, fmt.Errorf("deleting resource groups: %w", err)
 	}
 
@@ -1127,7 +1168,51 @@ func (p *BicepProvider) getAllResourcesToDelete(
 	return allResources, nil
 }
 
-func (p *BicepProvider) generateResourceGroupsToDelete(groupedResources map[string][]azcli.AzCliResource) []string {
+// getTaggedResourcesToDelete finds the resources tagged with the name of the environment outside of the resource groups
+// being deleted, like resources a template deploys into a shared resource group. Those resources would be left behind
+// by deleting the resource groups of the deployment. The name of an environment isn't unique across projects, so these
+// resources are only deleted when the user opts in.
+func (p *BicepProvider) getTaggedResourcesToDelete(
+	ctx context.Context,
+	groupedResources map[string][]azcli.AzCliResource,
+) ([]azcli.AzCliResource, error) {
+	resources, err := p.azCli.ListResources(ctx, p.env.GetSubscriptionId(), &azcli.ListResourcesOptions{
+		TagFilter: &azcli.Filter{Key: azure.TagKeyAzdEnvName, Value: p.env.Name()},
+	})
+	var errDetails *azcore.ResponseError
+	if errors.As(err, &errDetails) && errDetails.StatusCode == 403 {
+		// Listing the resources of the subscription requires more permissions than listing the resource groups
+		log.Printf("skipping tagged resources outside of the resource groups: %v", err)
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Resource group names are case insensitive
+	deletedGroups := map[string]bool{}
+	for resourceGroup := range groupedResources {
+		deletedGroups[strings.ToLower(resourceGroup)] = true
+	}
+
+	taggedResources := []azcli.AzCliResource{}
+	for _, resource := range resources {
+		resourceGroup := azure.GetResourceGroupName(resource.Id)
+		if resourceGroup != nil && deletedGroups[strings.ToLower(*resourceGroup)] {
+			continue
+		}
+
+		taggedResources = append(taggedResources, resource)
+	}
+
+	return taggedResources, nil
+}
+
+func (p *BicepProvider) generateResourceGroupsToDelete(
+	groupedResources map[string][]azcli.AzCliResource,
+	taggedResources []azcli.AzCliResource,
+) []string {
 	lines := []string{"Resource group(s) to be deleted:", ""}
 
 	for rg := range groupedResources {
@@ -1141,6 +1226,27 @@ func (p *BicepProvider) generateResourceGroupsToDelete(groupedResources map[stri
 			),
 		))
 	}
+
+	if len(taggedResources) > 0 {
+		lines = append(lines, "")
+		return append(lines, p.generateTaggedResourcesToDelete(taggedResources)...)
+	}
+
+	return append(lines, "")
+}
+
+func (p *BicepProvider) generateTaggedResourcesToDelete(taggedResources []azcli.AzCliResource) []string {
+	lines := []string{"Resource(s) tagged with the environment outside of the resource groups to be deleted:", ""}
+
+	for _, resource := range taggedResources {
+		lines = append(lines, fmt.Sprintf(
+			"  • %s (%s): %s",
+			resource.Name,
+			resource.Type,
+			output.WithLinkFormat("%s/#@/resource%s/overview", p.portalUrlBase, resource.Id),
+		))
+	}
+
 	return append(lines, "")
 }
 
@@ -1149,11 +1255,12 @@ func (p *BicepProvider) destroyResourceGroups(
 	ctx context.Context,
 	options DestroyOptions,
 	groupedResources map[string][]azcli.AzCliResource,
+	taggedResources []azcli.AzCliResource,
 	resourceCount int,
 ) error {
 	if !options.Force() {
 		p.console.MessageUxItem(ctx, &ux.MultilineMessage{
-			Lines: p.generateResourceGroupsToDelete(groupedResources)},
+			Lines: p.generateResourceGroupsToDelete(groupedResources, taggedResources)},
 		)
 		confirmDestroy, err := p.console.Confirm(ctx, input.ConsoleOptions{
 			Message: fmt.Sprintf(
@@ -1171,10 +1278,32 @@ func (p *BicepProvider) destroyResourceGroups(
 		if !confirmDestroy {
 			return errors.New("user denied delete confirmation")
 		}
+	} else if len(taggedResources) > 0 {
+		// Without confirmation, the tagged resources are still listed before being deleted
+		p.console.MessageUxItem(ctx, &ux.MultilineMessage{Lines: p.generateTaggedResourcesToDelete(taggedResources)})
 	}
 
 	p.console.Message(ctx, output.WithGrayFormat("Deleting your resources can take some time.\n"))
 
+	// Resources outside of the resource groups are deleted first, since they commonly depend on the resources in
+	// the groups, like role assignments or diagnostic settings.
+	for _, resource := range taggedResources {
+		message := fmt.Sprintf("Deleting resource: %s", output.WithHighLightFormat(resource.Name))
+		p.console.ShowSpinner(ctx, message, input.Step)
+		err := p.azCli.DeleteResource(ctx, p.env.GetSubscriptionId(), resource.Id)
+
+		var errDetails *azcore.ResponseError
+		if errors.As(err, &errDetails) && errDetails.StatusCode == 404 {
+			// Already deleted, like a child resource deleted along with its parent
+			err = nil
+		}
+
+		p.console.StopSpinner(ctx, message, input.GetStepResultFormat(err))
+		if err != nil {
+			return err
+		}
+	}
+
 	for resourceGroup := range groupedResources {
 		message := fmt.Sprintf("Deleting resource group: %s",
 			output.WithHighLightFormat(resourceGroup),
@@ -1230,7 +1359,10 @@ func (p *BicepProvider) purgeItems(
 		p.console.Message(ctx, fmt.Sprintf(
 			"These resources have soft delete enabled allowing them to be recovered for a period or time "+
 				"after deletion. During this period, their names may not be reused. In the future, you can use "+
-				"the argument %s to skip this confirmation.\n", output.WithHighLightFormat("--purge")))
+				"the argument %s to permanently delete them, and %s to also skip the confirmation.\n",
+			output.WithHighLightFormat("--purge"),
+			output.WithHighLightFormat("--force"),
+		))
 
 		purgeItems, err := p.console.Confirm(ctx, input.ConsoleOptions{
 			Message: fmt.Sprintf(
@@ -1248,10 +1380,23 @@ func (p *BicepProvider) purgeItems(
 		if !purgeItems {
 			skipPurge = true
 		}
+	} else if !options.Force() {
+		// Purging can't be undone, so even when requested with --purge it is confirmed unless --force is also set.
+		purgeItems, err := p.console.Confirm(ctx, input.ConsoleOptions{
+			Message: fmt.Sprintf(
+				"%s %s? Purged resources can't be recovered.",
+				output.WithErrorFormat("Permanently delete"),
+				itemsCountAsText(items),
+			),
+			DefaultValue: false,
+		})
+		p.console.Message(ctx, "")
 
 		if err != nil {
-			return err
+			return fmt.Errorf("prompting for purge confirmation: %w", err)
 		}
+
+		skipPurge = !purgeItems
 	}
 	for index, item := range items {
 		if err := item.purge(skipPurge, &items[index]); err != nil {
@@ -1617,28 +1762,107 @@ func (p *BicepProvider) createOutputParameters(
 		}
 
 		outputParams[paramName] = OutputParameter{
-			Type:  p.mapBicepTypeToInterfaceType(azureParam.Type),
-			Value: azureParam.Value,
+			Type:   p.mapBicepTypeToInterfaceType(azureParam.Type),
+			Value:  azureParam.Value,
+			Secure: strings.HasPrefix(strings.ToLower(azureParam.Type), "secure"),
 		}
 	}
 
 	return outputParams
 }
 
-// loadParameters reads the parameters file template for environment/module specified by Options,
-// doing environment and command substitutions, and returns the values.
+// loadParameters reads the parameters file templates for environment/module specified by Options, doing environment
+// and command substitutions, and returns the values. The parameters of the environment specific file,
+// '<module>.<environment>.parameters.json', override the ones of '<module>.parameters.json' when present.
 func (p *BicepProvider) loadParameters(ctx context.Context) (map[string]azure.ArmParameterValue, error) {
-	parametersFilename := fmt.Sprintf("%s.parameters.json", p.options.Module)
 	parametersRoot := p.options.Path
 
 	if !filepath.IsAbs(parametersRoot) {
 		parametersRoot = filepath.Join(p.projectPath, parametersRoot)
 	}
 
-	paramFilePath := filepath.Join(parametersRoot, parametersFilename)
+	paramFilePath := filepath.Join(parametersRoot, fmt.Sprintf("%s.parameters.json", p.options.Module))
+	envParamFilePath := filepath.Join(parametersRoot, fmt.Sprintf("%s.%s.parameters.json", p.options.Module, p.env.Name()))
+
+	parameters, err := p.readParametersFile(ctx, paramFilePath)
+	if errors.Is(err, os.ErrNotExist) {
+		// The environment specific file is enough on its own when the project doesn't share parameters
+		if _, statErr := os.Stat(envParamFilePath); statErr != nil {
+			return nil, fmt.Errorf("reading parameters.json: %w", err)
+		}
+	} else if err != nil {
+		return nil, err
+	} else {
+		log.Printf("loaded parameters from %s", paramFilePath)
+	}
+
+	envParameters, err := p.readParametersFile(ctx, envParamFilePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return parameters, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	log.Printf("loaded parameters from %s, overriding %s", envParamFilePath, paramFilePath)
+	return mergeParameters(parameters, envParameters), nil
+}
+
+// applyParameterOverrides overrides the values of the parameters files with the values of the --parameter flags,
+// substituting environment variables in them. The values of secure parameters are redacted from the logs.
+func (p *BicepProvider) applyParameterOverrides(
+	template azure.ArmTemplate,
+	parameters map[string]azure.ArmParameterValue,
+) (map[string]azure.ArmParameterValue, error) {
+	if len(p.options.Parameters) == 0 {
+		return parameters, nil
+	}
+
+	overridden := maps.Clone(parameters)
+	if overridden == nil {
+		overridden = map[string]azure.ArmParameterValue{}
+	}
+
+	keys := maps.Keys(p.options.Parameters)
+	slices.Sort(keys)
+	for _, key := range keys {
+		param, has := template.Parameters[key]
+		if !has {
+			return nil, fmt.Errorf("parameter '%s' set with --parameter isn't defined by the template", key)
+		}
+
+		value, err := envsubst.Eval(p.options.Parameters[key], p.env.Getenv)
+		if err != nil {
+			return nil, fmt.Errorf("substituting environment variables in parameter '%s': %w", key, err)
+		}
+
+		var paramValue any = value
+		switch p.mapBicepTypeToInterfaceType(param.Type) {
+		case ParameterTypeArray, ParameterTypeObject:
+			if err := json.Unmarshal([]byte(value), &paramValue); err != nil {
+				return nil, fmt.Errorf("parameter '%s' of type %s expects a JSON value: %w", key, param.Type, err)
+			}
+		}
+
+		loggedValue := value
+		if param.Secure() {
+			loggedValue = "<redacted>"
+		}
+		log.Printf("overriding parameter '%s' with '%s' from --parameter", key, loggedValue)
+
+		overridden[key] = azure.ArmParameterValue{Value: paramValue}
+	}
+
+	return overridden, nil
+}
+
+// readParametersFile reads a parameters file template, doing environment and command substitutions, and returns the
+// values.
+func (p *BicepProvider) readParametersFile(
+	ctx context.Context, paramFilePath string,
+) (map[string]azure.ArmParameterValue, error) {
 	parametersBytes, err := os.ReadFile(paramFilePath)
 	if err != nil {
-		return nil, fmt.Errorf("reading parameters.json: %w", err)
+		return nil, err
 	}
 
 	principalId, err := p.curPrincipal.CurrentPrincipalId(ctx)
@@ -1667,12 +1891,23 @@ func (p *BicepProvider) loadParameters(ctx context.Context) (map[string]azure.Ar
 
 	var armParameters azure.ArmParameterFile
 	if err := json.Unmarshal([]byte(replaced), &armParameters); err != nil {
-		return nil, fmt.Errorf("error unmarshalling Bicep template parameters: %w", err)
+		return nil, fmt.Errorf("error unmarshalling Bicep template parameters from %s: %w", paramFilePath, err)
 	}
 
 	return armParameters.Parameters, nil
 }
 
+// mergeParameters returns the parameters of base with the ones of overrides, overrides winning per parameter.
+func mergeParameters(
+	base map[string]azure.ArmParameterValue,
+	overrides map[string]azure.ArmParameterValue,
+) map[string]azure.ArmParameterValue {
+	merged := make(map[string]azure.ArmParameterValue, len(base)+len(overrides))
+	maps.Copy(merged, base)
+	maps.Copy(merged, overrides)
+	return merged
+}
+
 type compiledBicepParamResult struct {
 	TemplateJson   string `json:"templateJson"`
 	ParametersJson string `json:"parametersJson"`
@@ -1706,11 +1941,14 @@ func (p *BicepProvider) compileBicep(
 			}
 			azdEnv = append(azdEnv, fmt.Sprintf("%s=%s", environment.PrincipalIdEnvVarName, currentPrincipalId))
 		}
-		compiledResult, err := p.bicepCli.BuildBicepParam(ctx, modulePath, azdEnv)
+		compiledBicepParam, err := p.cachedCompile(ctx, modulePath, azdEnv, func() (string, error) {
+			compiledResult, err := p.bicepCli.BuildBicepParam(ctx, modulePath, azdEnv)
+			return compiledResult.Compiled, err
+		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to compile bicepparam template: %w", err)
 		}
-		compiled = compiledResult.Compiled
+		compiled = compiledBicepParam
 
 		var bicepParamOutput compiledBicepParamResult
 		if err := json.Unmarshal([]byte(compiled), &bicepParamOutput); err != nil {
@@ -1725,11 +1963,14 @@ func (p *BicepProvider) compileBicep(
 		}
 		parameters = params.Parameters
 	} else {
-		res, err := p.bicepCli.Build(ctx, modulePath)
+		compiledBicep, err := p.cachedCompile(ctx, modulePath, nil, func() (string, error) {
+			res, err := p.bicepCli.Build(ctx, modulePath)
+			return res.Compiled, err
+		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to compile bicep template: %w", err)
 		}
-		compiled = res.Compiled
+		compiled = compiledBicep
 	}
 
 	rawTemplate := azure.RawArmTemplate(compiled)
@@ -1859,6 +2100,25 @@ func (p *BicepProvider) convertToDeployment(bicepTemplate azure.ArmTemplate) (*D
 	return &template, nil
 }
 
+// tagResourceGroups applies the azd tags to the resource groups the deployment provisioned into. Templates are not
+// required to tag their resource groups, so azd tags them after the deployment. Failing to tag a group doesn't fail
+// the provision.
+func (p *BicepProvider) tagResourceGroups(
+	ctx context.Context,
+	deployment *armresources.DeploymentExtended,
+	tags map[string]*string,
+) {
+	if deployment == nil || deployment.Properties == nil || deployment.Properties.ProvisioningState == nil {
+		return
+	}
+
+	for _, resourceGroup := range resourceGroupsToDelete(deployment) {
+		if err := p.azCli.MergeResourceGroupTags(ctx, p.env.GetSubscriptionId(), resourceGroup, tags); err != nil {
+			log.Printf("failed tagging resource group '%s': %v", resourceGroup, err)
+		}
+	}
+}
+
 // Deploys the specified Bicep module and parameters with the selected provisioning scope (subscription vs resource group)
 func (p *BicepProvider) deployModule(
 	ctx context.Context,
@@ -1870,6 +2130,27 @@ func (p *BicepProvider) deployModule(
 	return target.Deploy(ctx, armTemplate, armParameters, tags)
 }
 
+// withFailedResources resolves the resources that failed to deploy from the operations of a failed deployment, so the
+// deployment error is rendered as a concise summary of the failed resources. The full error is logged.
+func (p *BicepProvider) withFailedResources(ctx context.Context, target infra.Deployment, err error) error {
+	var deploymentErr *azapi.AzureDeploymentError
+	if !errors.As(err, &deploymentErr) {
+		return err
+	}
+
+	log.Printf("deployment failed: %s", deploymentErr.Json)
+
+	resourceManager := infra.NewAzureResourceManager(p.azCli, p.deploymentOperations)
+	failedResources, opErr := resourceManager.GetFailedDeploymentResources(ctx, target)
+	if opErr != nil {
+		log.Printf("resolving failed resources of deployment: %v", opErr)
+		return err
+	}
+
+	deploymentErr.Resources = failedResources
+	return err
+}
+
 // Gets the folder path to the specified module
 func (p *BicepProvider) modulePath() string {
 	infraRoot := p.options.Path
@@ -1952,6 +2233,11 @@ func (p *BicepProvider) ensureParameters(
 		return nil, fmt.Errorf("resolving bicep parameters file: %w", err)
 	}
 
+	parameters, err = p.applyParameterOverrides(template, parameters)
+	if err != nil {
+		return nil, err
+	}
+
 	if len(template.Parameters) == 0 {
 		return azure.ArmParameters{}, nil
 	}
@@ -2170,21 +2456,27 @@ func NewBicepProvider(
 	alphaFeatureManager *alpha.FeatureManager,
 	clock clock.Clock,
 	keyvaultService keyvault.KeyVaultService,
+	nameAvailabilityService azapi.NameAvailabilityService,
+	resourceTypeLocationsService azapi.ResourceTypeLocationsService,
+	moduleRegistryClient ModuleRegistryClient,
 	portalUrlBase string,
 ) Provider {
 	return &BicepProvider{
-		envManager:           envManager,
-		env:                  env,
-		console:              console,
-		bicepCli:             bicepCli,
-		azCli:                azCli,
-		deploymentsService:   deploymentsService,
-		deploymentOperations: deploymentOperations,
-		prompters:            prompters,
-		curPrincipal:         curPrincipal,
-		alphaFeatureManager:  alphaFeatureManager,
-		clock:                clock,
-		keyvaultService:      keyvaultService,
-		portalUrlBase:        portalUrlBase,
+		envManager:                   envManager,
+		env:                          env,
+		console:                      console,
+		bicepCli:                     bicepCli,
+		azCli:                        azCli,
+		deploymentsService:           deploymentsService,
+		deploymentOperations:         deploymentOperations,
+		prompters:                    prompters,
+		curPrincipal:                 curPrincipal,
+		alphaFeatureManager:          alphaFeatureManager,
+		clock:                        clock,
+		keyvaultService:              keyvaultService,
+		nameAvailabilityService:      nameAvailabilityService,
+		resourceTypeLocationsService: resourceTypeLocationsService,
+		moduleRegistryClient:         moduleRegistryClient,
+		portalUrlBase:                portalUrlBase,
 	}
 }
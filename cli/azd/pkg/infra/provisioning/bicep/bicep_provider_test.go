@@ -10,8 +10,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"testing"
@@ -33,6 +35,7 @@ import (
 	. "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/keyvault"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 	"github.com/azure/azure-dev/cli/azd/pkg/prompt"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/bicep"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
@@ -169,6 +172,73 @@ func TestBicepDestroy(t *testing.T) {
 		require.Contains(t, consoleOutput[7], "")
 	})
 
+	t.Run("PurgeRequiresConfirmation", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		prepareBicepMocks(mockContext)
+		prepareStateMocks(mockContext)
+		prepareDestroyMocks(mockContext)
+
+		var purgedVaults []string
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodPost && strings.Contains(request.URL.Path, "deletedVaults/")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			purgedVaults = append(purgedVaults, request.URL.Path)
+			return httpRespondFn(request)
+		})
+
+		mockContext.Console.WhenConfirm(func(options input.ConsoleOptions) bool {
+			return strings.Contains(options.Message, "are you sure you want to continue")
+		}).Respond(true)
+
+		mockContext.Console.WhenConfirm(func(options input.ConsoleOptions) bool {
+			return strings.Contains(options.Message, "Purged resources can't be recovered")
+		}).Respond(true)
+
+		infraProvider := createBicepProvider(t, mockContext)
+
+		destroyOptions := NewDestroyOptions(false, true)
+		destroyResult, err := infraProvider.Destroy(*mockContext.Context, destroyOptions)
+
+		require.Nil(t, err)
+		require.NotNil(t, destroyResult)
+		require.Len(t, purgedVaults, 2)
+
+		consoleOutput := mockContext.Console.Output()
+		require.Contains(t, strings.Join(consoleOutput, "\n"), "Purged resources can't be recovered")
+		require.NotContains(t, strings.Join(consoleOutput, "\n"), "These resources have soft delete enabled")
+	})
+
+	t.Run("PurgeDeclined", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		prepareBicepMocks(mockContext)
+		prepareStateMocks(mockContext)
+		prepareDestroyMocks(mockContext)
+
+		var purgedVaults []string
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodPost && strings.Contains(request.URL.Path, "deletedVaults/")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			purgedVaults = append(purgedVaults, request.URL.Path)
+			return httpRespondFn(request)
+		})
+
+		mockContext.Console.WhenConfirm(func(options input.ConsoleOptions) bool {
+			return strings.Contains(options.Message, "are you sure you want to continue")
+		}).Respond(true)
+
+		mockContext.Console.WhenConfirm(func(options input.ConsoleOptions) bool {
+			return strings.Contains(options.Message, "Purged resources can't be recovered")
+		}).Respond(false)
+
+		infraProvider := createBicepProvider(t, mockContext)
+
+		destroyOptions := NewDestroyOptions(false, true)
+		_, err := infraProvider.Destroy(*mockContext.Context, destroyOptions)
+
+		require.Nil(t, err)
+		require.Empty(t, purgedVaults)
+	})
+
 	t.Run("InteractiveForceAndPurge", func(t *testing.T) {
 		mockContext := mocks.NewMockContext(context.Background())
 		prepareBicepMocks(mockContext)
@@ -189,6 +259,180 @@ func TestBicepDestroy(t *testing.T) {
 		require.Contains(t, consoleOutput[0], "Deleting your resources can take some time")
 		require.Contains(t, consoleOutput[1], "")
 	})
+
+	t.Run("TaggedResourcesOutsideGroup", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		prepareBicepMocks(mockContext)
+		prepareStateMocks(mockContext)
+		prepareDestroyMocks(mockContext)
+
+		identityId := "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-shared/providers/" +
+			"Microsoft.ManagedIdentity/userAssignedIdentities/id-123"
+
+		// Resources tagged with the environment, in and outside of the resource group of the deployment
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet &&
+				strings.HasSuffix(request.URL.Path, "/subscriptions/SUBSCRIPTION_ID/resources")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			require.Equal(t,
+				"tagName eq 'azd-env-name' and tagValue eq 'test-env'", request.URL.Query().Get("$filter"))
+
+			return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armresources.ResourceListResult{
+				Value: []*armresources.GenericResourceExpanded{
+					{
+						ID: convert.RefOf(
+							"/subscriptions/SUBSCRIPTION_ID/resourceGroups/resource_group/providers/Microsoft.Web/sites/app-123"),
+						Name:     convert.RefOf("app-123"),
+						Type:     convert.RefOf(string(infra.AzureResourceTypeWebSite)),
+						Location: convert.RefOf("eastus2"),
+					},
+					{
+						ID:       convert.RefOf(identityId),
+						Name:     convert.RefOf("id-123"),
+						Type:     convert.RefOf("Microsoft.ManagedIdentity/userAssignedIdentities"),
+						Location: convert.RefOf("eastus2"),
+					},
+				},
+			})
+		})
+
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet &&
+				strings.HasSuffix(request.URL.Path, "/subscriptions/SUBSCRIPTION_ID/providers/Microsoft.ManagedIdentity")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armresources.Provider{
+				Namespace: convert.RefOf("Microsoft.ManagedIdentity"),
+				ResourceTypes: []*armresources.ProviderResourceType{
+					{
+						ResourceType: convert.RefOf("userAssignedIdentities"),
+						APIVersions:  []*string{convert.RefOf("2024-11-30-preview"), convert.RefOf("2023-01-31")},
+					},
+				},
+			})
+		})
+
+		var deletedResources []string
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodDelete && request.URL.Path == identityId
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			deletedResources = append(
+				deletedResources, fmt.Sprintf("%s?api-version=%s", request.URL.Path, request.URL.Query().Get("api-version")))
+			return mocks.CreateEmptyHttpResponse(request, http.StatusOK)
+		})
+
+		mockContext.Console.WhenConfirm(func(options input.ConsoleOptions) bool {
+			return strings.Contains(options.Message, "are you sure you want to continue")
+		}).RespondFn(func(options input.ConsoleOptions) (any, error) {
+			// The tagged resource is counted in the resources to delete, along with the 9 resources of the group
+			require.Contains(t, options.Message, "Total resources to delete: 10")
+			return true, nil
+		})
+
+		mockContext.Console.WhenConfirm(func(options input.ConsoleOptions) bool {
+			return strings.Contains(options.Message, "Purged resources can't be recovered")
+		}).Respond(true)
+
+		infraProvider := createBicepProvider(t, mockContext)
+
+		destroyOptions := NewDestroyOptions(false, true).WithTaggedResources(true)
+		_, err := infraProvider.Destroy(*mockContext.Context, destroyOptions)
+		require.NoError(t, err)
+
+		consoleOutput := strings.Join(mockContext.Console.Output(), "\n")
+		require.Contains(t, consoleOutput, "outside of the resource groups to be deleted")
+		require.Contains(t, consoleOutput, "id-123 (Microsoft.ManagedIdentity/userAssignedIdentities)")
+		require.NotContains(t, consoleOutput, "app-123 (")
+		require.Equal(t, []string{identityId + "?api-version=2023-01-31"}, deletedResources)
+	})
+
+	t.Run("TaggedResourcesNotIncludedByDefault", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		prepareBicepMocks(mockContext)
+		prepareStateMocks(mockContext)
+		prepareDestroyMocks(mockContext)
+
+		listedTaggedResources := false
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet &&
+				strings.HasSuffix(request.URL.Path, "/subscriptions/SUBSCRIPTION_ID/resources")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			listedTaggedResources = true
+			return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armresources.ResourceListResult{})
+		})
+
+		infraProvider := createBicepProvider(t, mockContext)
+
+		destroyOptions := NewDestroyOptions(true, true)
+		_, err := infraProvider.Destroy(*mockContext.Context, destroyOptions)
+		require.NoError(t, err)
+		require.False(t, listedTaggedResources)
+	})
+}
+
+func TestBicepDeployTags(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	prepareBicepMocks(mockContext)
+	prepareStateMocks(mockContext)
+
+	var deploymentTags map[string]*string
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut &&
+			strings.Contains(request.URL.Path, "/subscriptions/SUBSCRIPTION_ID/providers/Microsoft.Resources/deployments/")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		var body armresources.Deployment
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		deploymentTags = body.Tags
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, cTestEnvDeployment)
+	})
+
+	var resourceGroupTags map[string]*string
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPatch && strings.HasSuffix(
+			request.URL.Path,
+			"/subscriptions/SUBSCRIPTION_ID/resourceGroups/RESOURCE_GROUP/providers/Microsoft.Resources/tags/default",
+		)
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		var body armresources.TagsPatchResource
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		require.Equal(t, armresources.TagsPatchOperationMerge, *body.Operation)
+		resourceGroupTags = body.Properties.Tags
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armresources.TagsResource{})
+	})
+
+	infraProvider := createBicepProvider(t, mockContext)
+	infraProvider.ignoreDeploymentState = true
+	infraProvider.options.TemplateId = "todo-nodejs-mongo"
+	infraProvider.options.Ttl = 2 * time.Hour
+	infraProvider.options.Tags = map[string]string{
+		"cost-center":          "${AZURE_ENV_NAME}-cc",
+		azure.TagKeyAzdEnvName: "overridden",
+	}
+
+	_, err := infraProvider.Deploy(*mockContext.Context)
+	require.NoError(t, err)
+
+	expected := map[string]string{
+		azure.TagKeyAzdEnvName:       "test-env",
+		azure.TagKeyAzdTemplateId:    "todo-nodejs-mongo",
+		azure.TagKeyAzdProvisionTime: clock.NewMock().Now().UTC().Format(time.RFC3339),
+		azure.TagKeyAzdExpiresOn:     clock.NewMock().Now().Add(2 * time.Hour).UTC().Format(time.RFC3339),
+		"cost-center":                "test-env-cc",
+	}
+
+	for key, value := range expected {
+		require.Contains(t, deploymentTags, key)
+		require.Equal(t, value, *deploymentTags[key])
+		require.Contains(t, resourceGroupTags, key)
+		require.Equal(t, value, *resourceGroupTags[key])
+	}
+
+	require.NotContains(t, resourceGroupTags, azure.TagKeyAzdDeploymentStateParamHashName)
 }
 
 func TestPlanForResourceGroup(t *testing.T) {
@@ -344,6 +588,8 @@ func createBicepProvider(t *testing.T, mockContext *mocks.MockContext) *BicepPro
 	options := Options{
 		Path:   "infra",
 		Module: "main",
+		// The compiled templates of the sample are mocked differently by each test
+		IgnoreCompileCache: true,
 	}
 
 	env := environment.NewWithValues("test-env", map[string]string{
@@ -396,6 +642,9 @@ func createBicepProvider(t *testing.T, mockContext *mocks.MockContext) *BicepPro
 			mockContext.ArmClientOptions,
 			mockContext.CoreClientOptions,
 		),
+		nil,
+		nil,
+		nil,
 		cloud.AzurePublic().PortalUrlBase,
 	)
 
@@ -943,10 +1192,14 @@ func TestUserDefinedTypes(t *testing.T) {
 			mockContext.ArmClientOptions,
 			mockContext.CoreClientOptions,
 		),
+		nil,
+		nil,
+		nil,
 		cloud.AzurePublic().PortalUrlBase,
 	)
 	bicepProvider, gooCast := provider.(*BicepProvider)
 	require.True(t, gooCast)
+	bicepProvider.ignoreCompileCache = true
 
 	compiled, err := bicepProvider.compileBicep(*mockContext.Context, "user-defined-types")
 
@@ -1044,6 +1297,79 @@ func TestUserDefinedTypes(t *testing.T) {
 	}, customOutput.Metadata)
 }
 
+func writeParametersFile(t *testing.T, dir string, name string, parameters string) {
+	content := fmt.Sprintf(`{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentParameters.json#",
+  "contentVersion": "1.0.0.0",
+  "parameters": %s
+}`, parameters)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), osutil.PermissionFile))
+}
+
+func TestLoadParameters(t *testing.T) {
+	t.Run("EnvironmentFileOverrides", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		prepareBicepMocks(mockContext)
+		infraProvider := createBicepProvider(t, mockContext)
+		infraProvider.projectPath = t.TempDir()
+		infraDir := filepath.Join(infraProvider.projectPath, "infra")
+		require.NoError(t, os.MkdirAll(infraDir, osutil.PermissionDirectory))
+
+		writeParametersFile(t, infraDir, "main.parameters.json", `{
+    "environmentName": { "value": "${AZURE_ENV_NAME}" },
+    "location": { "value": "${AZURE_LOCATION}" },
+    "sku": { "value": "B1" }
+  }`)
+		writeParametersFile(t, infraDir, "main.test-env.parameters.json", `{
+    "sku": { "value": "P1v3" },
+    "replicas": { "value": 3 }
+  }`)
+
+		parameters, err := infraProvider.loadParameters(*mockContext.Context)
+		require.NoError(t, err)
+
+		require.Equal(t, map[string]azure.ArmParameterValue{
+			"environmentName": {Value: "test-env"},
+			"location":        {Value: "westus2"},
+			"sku":             {Value: "P1v3"},
+			"replicas":        {Value: float64(3)},
+		}, parameters)
+	})
+
+	t.Run("FallbackWithoutEnvironmentFile", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		prepareBicepMocks(mockContext)
+		infraProvider := createBicepProvider(t, mockContext)
+		infraProvider.projectPath = t.TempDir()
+		infraDir := filepath.Join(infraProvider.projectPath, "infra")
+		require.NoError(t, os.MkdirAll(infraDir, osutil.PermissionDirectory))
+
+		writeParametersFile(t, infraDir, "main.parameters.json", `{
+    "sku": { "value": "B1" }
+  }`)
+		// Files of other environments are ignored
+		writeParametersFile(t, infraDir, "main.prod.parameters.json", `{
+    "sku": { "value": "P1v3" }
+  }`)
+
+		parameters, err := infraProvider.loadParameters(*mockContext.Context)
+		require.NoError(t, err)
+		require.Equal(t, map[string]azure.ArmParameterValue{
+			"sku": {Value: "B1"},
+		}, parameters)
+	})
+
+	t.Run("NoParametersFile", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		prepareBicepMocks(mockContext)
+		infraProvider := createBicepProvider(t, mockContext)
+		infraProvider.projectPath = t.TempDir()
+
+		_, err := infraProvider.loadParameters(*mockContext.Context)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
 func Test_armParameterFileValue(t *testing.T) {
 	t.Run("NilValue", func(t *testing.T) {
 		actual := armParameterFileValue(ParameterTypeString, nil, nil)
@@ -1300,3 +1626,65 @@ func TestInputsParameter(t *testing.T) {
 
 	require.Equal(t, expectedInputsUpdated, inputsUpdated)
 }
+
+func TestApplyParameterOverrides(t *testing.T) {
+	template := azure.ArmTemplate{
+		Parameters: azure.ArmTemplateParameterDefinitions{
+			"sku":           {Type: "string"},
+			"replicas":      {Type: "int"},
+			"tags":          {Type: "object"},
+			"adminPassword": {Type: "securestring"},
+		},
+	}
+	newProvider := func(overrides map[string]string) *BicepProvider {
+		return &BicepProvider{
+			env: environment.NewWithValues("dev", map[string]string{
+				"SKU_NAME":       "Standard",
+				"ADMIN_PASSWORD": "P@ssw0rd!",
+			}),
+			options: Options{Parameters: overrides},
+		}
+	}
+
+	t.Run("OverridesParametersFile", func(t *testing.T) {
+		var logs bytes.Buffer
+		log.SetOutput(&logs)
+		t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+		provider := newProvider(map[string]string{
+			"sku":           "${SKU_NAME}",
+			"replicas":      "3",
+			"tags":          `{"team": "web"}`,
+			"adminPassword": "${ADMIN_PASSWORD}",
+		})
+
+		parameters, err := provider.applyParameterOverrides(template, map[string]azure.ArmParameterValue{
+			"sku":      {Value: "B1"},
+			"replicas": {Value: float64(1)},
+		})
+		require.NoError(t, err)
+		require.Equal(t, map[string]azure.ArmParameterValue{
+			"sku":           {Value: "Standard"},
+			"replicas":      {Value: "3"},
+			"tags":          {Value: map[string]any{"team": "web"}},
+			"adminPassword": {Value: "P@ssw0rd!"},
+		}, parameters)
+
+		// Secure values are never logged
+		require.Contains(t, logs.String(), "overriding parameter 'sku' with 'Standard'")
+		require.Contains(t, logs.String(), "overriding parameter 'adminPassword' with '<redacted>'")
+		require.NotContains(t, logs.String(), "P@ssw0rd!")
+	})
+
+	t.Run("UnknownParameter", func(t *testing.T) {
+		provider := newProvider(map[string]string{"skuName": "Standard"})
+		_, err := provider.applyParameterOverrides(template, map[string]azure.ArmParameterValue{})
+		require.ErrorContains(t, err, "parameter 'skuName' set with --parameter isn't defined by the template")
+	})
+
+	t.Run("InvalidJson", func(t *testing.T) {
+		provider := newProvider(map[string]string{"tags": "team=web"})
+		_, err := provider.applyParameterOverrides(template, map[string]azure.ArmParameterValue{})
+		require.ErrorContains(t, err, "parameter 'tags' of type object expects a JSON value")
+	})
+}
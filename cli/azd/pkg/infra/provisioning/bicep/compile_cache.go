@@ -0,0 +1,269 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+)
+
+// compileCacheDirectory returns the directory where the compiled templates of the project are cached.
+func (p *BicepProvider) compileCacheDirectory() string {
+	return filepath.Join(p.projectPath, azdcontext.EnvironmentDirectoryName, ".cache", "bicep")
+}
+
+// cachedCompile returns the template compiled from the module when none of its inputs changed since it was last
+// compiled, and otherwise compiles it with compile and caches the result. env is the environment used for the
+// compilation, when it depends on it.
+func (p *BicepProvider) cachedCompile(
+	ctx context.Context,
+	modulePath string,
+	env []string,
+	compile func() (string, error),
+) (string, error) {
+	if p.ignoreCompileCache {
+		return compile()
+	}
+
+	bicepVersion, err := p.bicepCli.Version(ctx)
+	if err != nil {
+		log.Printf("checking bicep version for the compile cache: %v", err)
+		return compile()
+	}
+
+	key, err := compileCacheKey(modulePath, env, bicepVersion.String())
+	if err != nil {
+		log.Printf("computing compile cache key of '%s': %v", modulePath, err)
+		return compile()
+	}
+
+	cachePath := filepath.Join(p.compileCacheDirectory(), key+".json")
+	if cached, err := os.ReadFile(cachePath); err == nil {
+		log.Printf("using cached compiled template of '%s'", modulePath)
+		return string(cached), nil
+	}
+
+	compiled, err := compile()
+	if err != nil {
+		return "", err
+	}
+
+	// The compilation restores the registry modules to the local module cache, which is part of the key
+	key, err = compileCacheKey(modulePath, env, bicepVersion.String())
+	if err != nil {
+		log.Printf("computing compile cache key of '%s': %v", modulePath, err)
+		return compiled, nil
+	}
+
+	cachePath = filepath.Join(p.compileCacheDirectory(), key+".json")
+	if err := writeCompileCache(cachePath, compiled); err != nil {
+		log.Printf("caching compiled template of '%s': %v", modulePath, err)
+	}
+
+	return compiled, nil
+}
+
+// fileReferenceRegex matches the paths of the files referenced by Bicep files: the modules and imports of Bicep files,
+// the template of parameters files, and the files loaded with functions like loadTextContent and loadJsonContent.
+var fileReferenceRegex = regexp.MustCompile(
+	`(?m)(?:^\s*(?:module\s+\w+|import\b[^'\n]*\bfrom|using|extends)\s+|\bload\w+\(\s*)'([^']+)'`)
+
+// compileCacheKey hashes the inputs of the compilation of the module: the version of the Bicep CLI, the environment
+// used for the compilation, the bicepconfig.json file configuring it, and the files the module references, directly or
+// through other modules, including the ones outside of its directory, like '../shared/app.bicep'.
+//
+// Registry modules referenced by tag may be republished, so the manifest of the version restored to the local module
+// cache of the Bicep CLI, which is the one compiled, is hashed with their reference.
+func compileCacheKey(modulePath string, env []string, bicepVersion string) (string, error) {
+	hash := sha256.New()
+	moduleDir := filepath.Dir(modulePath)
+
+	fmt.Fprintf(hash, "bicep:%s\n", bicepVersion)
+	fmt.Fprintf(hash, "module:%s\n", filepath.Base(modulePath))
+
+	config, configPath, err := loadBicepConfig(moduleDir)
+	if err != nil {
+		return "", err
+	}
+
+	aliases := maps.Clone(builtInModuleAliases)
+	maps.Copy(aliases, config.ModuleAliases.Br)
+
+	files, modules, err := compileInputs(modulePath, aliases)
+	if err != nil {
+		return "", err
+	}
+
+	if configPath != "" {
+		files = append([]string{configPath}, files...)
+	}
+
+	for _, path := range files {
+		if err := hashFile(hash, moduleDir, path); err != nil {
+			return "", err
+		}
+	}
+
+	cacheRoot := config.CacheRootDirectory
+	if cacheRoot == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+
+		cacheRoot = filepath.Join(home, ".bicep")
+	}
+
+	for _, module := range modules {
+		fmt.Fprintf(hash, "registry:%s\n", module.Reference)
+
+		// Digests are immutable
+		if strings.HasPrefix(module.Version, "sha256:") {
+			continue
+		}
+
+		manifest, err := os.ReadFile(restoredModuleManifestPath(cacheRoot, module))
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(hash, "not restored\n")
+			continue
+		} else if err != nil {
+			return "", err
+		}
+
+		hash.Write(manifest)
+	}
+
+	sortedEnv := slices.Clone(env)
+	slices.Sort(sortedEnv)
+	for _, value := range sortedEnv {
+		fmt.Fprintf(hash, "env:%s\n", value)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// compileInputs returns the files the compilation of the module reads, found by following the file references of the
+// Bicep files from the module, and the registry modules they reference.
+func compileInputs(modulePath string, aliases map[string]moduleAlias) ([]string, []RegistryModule, error) {
+	var files []string
+	var modules []RegistryModule
+	seen := map[string]bool{}
+	pending := []string{filepath.Clean(modulePath)}
+
+	for len(pending) > 0 {
+		path := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		files = append(files, path)
+
+		if ext := filepath.Ext(path); ext != bicepFileExtension && ext != bicepparamFileExtension {
+			continue
+		}
+
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, match := range fileReferenceRegex.FindAllStringSubmatch(string(contents), -1) {
+			reference := match[1]
+			switch {
+			case strings.HasPrefix(reference, "br:") || strings.HasPrefix(reference, "br/"):
+				if seen[reference] {
+					continue
+				}
+				seen[reference] = true
+
+				module, err := parseRegistryModuleReference(reference, aliases)
+				if err != nil {
+					return nil, nil, fmt.Errorf("parsing module reference '%s': %w", reference, err)
+				}
+
+				modules = append(modules, module)
+			case strings.HasPrefix(reference, "ts:") || strings.HasPrefix(reference, "ts/"):
+				// Template specs can be updated in place, and aren't restored to a local cache which could be hashed
+				return nil, nil, fmt.Errorf("template spec '%s' can't be cached", reference)
+			default:
+				pending = append(pending, filepath.Join(filepath.Dir(path), filepath.FromSlash(reference)))
+			}
+		}
+	}
+
+	slices.Sort(files)
+	slices.SortFunc(modules, func(a, b RegistryModule) int {
+		return strings.Compare(a.Reference, b.Reference)
+	})
+
+	return files, modules, nil
+}
+
+// hashFile writes the path of the file, relative to the directory of the module, and its contents to the hash.
+func hashFile(hash io.Writer, moduleDir string, path string) error {
+	relPath, err := filepath.Rel(moduleDir, path)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	fmt.Fprintf(hash, "file:%s\n", filepath.ToSlash(relPath))
+	_, err = io.Copy(hash, file)
+	return err
+}
+
+// restoredModuleManifestPath returns the path of the manifest of a registry module restored to the local module cache
+// of the Bicep CLI, like '~/.bicep/br/mcr.microsoft.com/bicep$avm$res$web$site/0.3.0$/manifest'.
+func restoredModuleManifestPath(cacheRoot string, module RegistryModule) string {
+	return filepath.Join(
+		cacheRoot,
+		"br",
+		module.Registry,
+		strings.ReplaceAll(module.Repository, "/", "$"),
+		module.Version+"$",
+		"manifest",
+	)
+}
+
+// writeCompileCache caches the compiled template, replacing the templates previously cached, which are stale.
+func writeCompileCache(cachePath string, compiled string) error {
+	cacheDir := filepath.Dir(cachePath)
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := os.Remove(filepath.Join(cacheDir, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(cacheDir, osutil.PermissionDirectory); err != nil {
+		return err
+	}
+
+	return os.WriteFile(cachePath, []byte(compiled), osutil.PermissionFile)
+}
@@ -0,0 +1,223 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/bicep"
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/require"
+)
+
+// countingBicepCli compiles every template to the same ARM template, counting the compilations.
+type countingBicepCli struct {
+	builds  int
+	version semver.Version
+}
+
+func (c *countingBicepCli) Version(ctx context.Context) (semver.Version, error) {
+	return c.version, nil
+}
+
+func (c *countingBicepCli) Build(ctx context.Context, file string) (bicep.BuildResult, error) {
+	c.builds++
+	return bicep.BuildResult{Compiled: `{"parameters": {}, "outputs": {}}`}, nil
+}
+
+func (c *countingBicepCli) BuildBicepParam(ctx context.Context, file string, env []string) (bicep.BuildResult, error) {
+	c.builds++
+	return bicep.BuildResult{}, nil
+}
+
+func Test_CompileBicep_Cache(t *testing.T) {
+	projectPath := t.TempDir()
+	infraPath := filepath.Join(projectPath, "infra")
+	require.NoError(t, os.MkdirAll(infraPath, osutil.PermissionDirectory))
+	modulePath := filepath.Join(infraPath, "main.bicep")
+	require.NoError(t, os.WriteFile(
+		modulePath, []byte("module app 'app.bicep' = {\n  name: 'app'\n}"), osutil.PermissionFile))
+	appPath := filepath.Join(infraPath, "app.bicep")
+	require.NoError(t, os.WriteFile(appPath, []byte("param location string"), osutil.PermissionFile))
+
+	bicepCli := &countingBicepCli{version: semver.MustParse("0.25.3")}
+	compile := func(ignoreCompileCache bool) {
+		// Each provision uses a new provider, without the in-memory cache of the previous one
+		provider := &BicepProvider{
+			projectPath:        projectPath,
+			bicepCli:           bicepCli,
+			ignoreCompileCache: ignoreCompileCache,
+		}
+		_, err := provider.compileBicep(context.Background(), modulePath)
+		require.NoError(t, err)
+	}
+
+	compile(false)
+	require.Equal(t, 1, bicepCli.builds)
+
+	// Unchanged templates aren't compiled again
+	compile(false)
+	require.Equal(t, 1, bicepCli.builds)
+
+	// The cache is ignored with --no-cache
+	compile(true)
+	require.Equal(t, 2, bicepCli.builds)
+
+	// Changes to the modules referenced by the template invalidate the cache
+	require.NoError(t, os.WriteFile(appPath, []byte("param location string = 'eastus2'"), osutil.PermissionFile))
+	compile(false)
+	require.Equal(t, 3, bicepCli.builds)
+
+	compile(false)
+	require.Equal(t, 3, bicepCli.builds)
+
+	// Files which aren't referenced don't
+	require.NoError(t, os.WriteFile(
+		filepath.Join(infraPath, "main.parameters.json"), []byte(`{"parameters": {}}`), osutil.PermissionFile))
+	compile(false)
+	require.Equal(t, 3, bicepCli.builds)
+
+	// Upgrading the Bicep CLI invalidates the cache
+	bicepCli.version = semver.MustParse("0.26.54")
+	compile(false)
+	require.Equal(t, 4, bicepCli.builds)
+
+	// Only the latest compiled template is cached
+	entries, err := os.ReadDir(filepath.Join(projectPath, ".azure", ".cache", "bicep"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func Test_CompileCacheKey_Environment(t *testing.T) {
+	modulePath := filepath.Join(t.TempDir(), "main.bicepparam")
+	require.NoError(t, os.WriteFile(modulePath, []byte("using 'main.bicep'"), osutil.PermissionFile))
+
+	require.NoError(t, os.WriteFile(
+		filepath.Join(filepath.Dir(modulePath), "main.bicep"), []byte("param location string"), osutil.PermissionFile))
+
+	key, err := compileCacheKey(modulePath, []string{"AZURE_LOCATION=eastus2", "AZURE_ENV_NAME=dev"}, "0.25.3")
+	require.NoError(t, err)
+
+	// The order of the environment doesn't matter, its values do
+	sameKey, err := compileCacheKey(modulePath, []string{"AZURE_ENV_NAME=dev", "AZURE_LOCATION=eastus2"}, "0.25.3")
+	require.NoError(t, err)
+	require.Equal(t, key, sameKey)
+
+	otherKey, err := compileCacheKey(modulePath, []string{"AZURE_ENV_NAME=dev", "AZURE_LOCATION=westus"}, "0.25.3")
+	require.NoError(t, err)
+	require.NotEqual(t, key, otherKey)
+}
+
+func Test_CompileCacheKey_References(t *testing.T) {
+	root := t.TempDir()
+	infraPath := filepath.Join(root, "infra")
+	sharedPath := filepath.Join(root, "shared")
+	require.NoError(t, os.MkdirAll(infraPath, osutil.PermissionDirectory))
+	require.NoError(t, os.MkdirAll(sharedPath, osutil.PermissionDirectory))
+
+	modulePath := filepath.Join(infraPath, "main.bicep")
+	writeFile := func(path string, contents string) {
+		require.NoError(t, os.WriteFile(path, []byte(contents), osutil.PermissionFile))
+	}
+
+	writeFile(modulePath, `
+module app '../shared/app.bicep' = {
+  name: 'app'
+}
+var policy = loadJsonContent('policy.json')
+`)
+	writeFile(filepath.Join(sharedPath, "app.bicep"), "var script = loadTextContent('scripts/init.sh')")
+	require.NoError(t, os.MkdirAll(filepath.Join(sharedPath, "scripts"), osutil.PermissionDirectory))
+	writeFile(filepath.Join(sharedPath, "scripts", "init.sh"), "echo hello")
+	writeFile(filepath.Join(infraPath, "policy.json"), "{}")
+
+	key := func() string {
+		key, err := compileCacheKey(modulePath, nil, "0.25.3")
+		require.NoError(t, err)
+		return key
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		contents string
+	}{
+		{
+			"ParentModule",
+			filepath.Join(sharedPath, "app.bicep"),
+			"var script = loadTextContent('scripts/init.sh')\nparam location string",
+		},
+		{"LoadTextContent", filepath.Join(sharedPath, "scripts", "init.sh"), "echo world"},
+		{"LoadJsonContent", filepath.Join(infraPath, "policy.json"), `{"effect": "deny"}`},
+		{"BicepConfig", filepath.Join(root, "bicepconfig.json"), "{}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := key()
+			writeFile(tt.path, tt.contents)
+			require.NotEqual(t, before, key())
+		})
+	}
+}
+
+func Test_CompileCacheKey_RegistryModules(t *testing.T) {
+	root := t.TempDir()
+	cacheRoot := filepath.Join(root, "bicep-cache")
+	require.NoError(t, os.WriteFile(
+		filepath.Join(root, "bicepconfig.json"),
+		[]byte(`{"cacheRootDirectory": "`+filepath.ToSlash(cacheRoot)+`"}`),
+		osutil.PermissionFile,
+	))
+
+	modulePath := filepath.Join(root, "main.bicep")
+	require.NoError(t, os.WriteFile(modulePath, []byte(`
+module site 'br/public:avm/res/web/site:0.3.0' = {
+  name: 'site'
+}
+module plan 'br:contoso.azurecr.io/modules/plan@sha256:abc' = {
+  name: 'plan'
+}
+`), osutil.PermissionFile))
+
+	key := func() string {
+		key, err := compileCacheKey(modulePath, nil, "0.25.3")
+		require.NoError(t, err)
+		return key
+	}
+
+	notRestored := key()
+
+	// Tags are mutable, so the restored version of the module is part of the key
+	manifestDir := filepath.Join(cacheRoot, "br", "mcr.microsoft.com", "bicep$avm$res$web$site", "0.3.0$")
+	require.NoError(t, os.MkdirAll(manifestDir, osutil.PermissionDirectory))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(manifestDir, "manifest"), []byte(`{"digest": "1"}`), osutil.PermissionFile))
+	restored := key()
+	require.NotEqual(t, notRestored, restored)
+
+	require.NoError(t, os.WriteFile(
+		filepath.Join(manifestDir, "manifest"), []byte(`{"digest": "2"}`), osutil.PermissionFile))
+	require.NotEqual(t, restored, key())
+}
+
+func Test_CompileBicep_CacheInInfraDirectory(t *testing.T) {
+	// With infra.path set to '.', the cache directory is in the infra directory, which must not invalidate the cache
+	projectPath := t.TempDir()
+	modulePath := filepath.Join(projectPath, "main.bicep")
+	require.NoError(t, os.WriteFile(modulePath, []byte("param location string"), osutil.PermissionFile))
+
+	bicepCli := &countingBicepCli{version: semver.MustParse("0.25.3")}
+	for i := 0; i < 2; i++ {
+		provider := &BicepProvider{projectPath: projectPath, bicepCli: bicepCli}
+		_, err := provider.compileBicep(context.Background(), modulePath)
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, 1, bicepCli.builds)
+}
@@ -0,0 +1,390 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+// skipLocationCheckEnvVarName is the environment variable to set to skip checking that the resource types of the
+// template are available in the locations they are deployed to before provisioning.
+const skipLocationCheckEnvVarName = "AZD_SKIP_LOCATION_CHECK"
+
+// strictLocationCheckEnvVarName is the environment variable to set to fail provisioning when a resource type isn't
+// available in its location, instead of warning.
+const strictLocationCheckEnvVarName = "AZD_STRICT_LOCATION_CHECK"
+
+const nestedDeploymentResourceType = "Microsoft.Resources/deployments"
+
+// globalLocation is the location of the resources which aren't bound to a region, like DNS zones.
+const globalLocation = "global"
+
+// parameterReference matches a template expression referencing a parameter, like `[parameters('location')]`.
+var parameterReference = regexp.MustCompile(`^\[parameters\('([^']+)'\)\]$`)
+
+// armTemplateResource is a resource of an ARM template, with the properties needed to find the resource types it
+// deploys and their locations.
+type armTemplateResource struct {
+	Type       string          `json:"type"`
+	Location   json.RawMessage `json:"location"`
+	Existing   bool            `json:"existing"`
+	Properties json.RawMessage `json:"properties"`
+	// Child resources, which type is relative to the type of their parent
+	Resources json.RawMessage `json:"resources"`
+}
+
+// armTemplateResources returns the resources of a template, which are either an array, or an object keyed by their
+// symbolic name for templates using language version 2.0.
+func armTemplateResources(rawResources json.RawMessage) ([]armTemplateResource, error) {
+	if len(rawResources) == 0 {
+		return nil, nil
+	}
+
+	var resources []armTemplateResource
+	if err := json.Unmarshal(rawResources, &resources); err == nil {
+		return resources, nil
+	}
+
+	var symbolicResources map[string]armTemplateResource
+	if err := json.Unmarshal(rawResources, &symbolicResources); err != nil {
+		return nil, err
+	}
+
+	for _, resource := range symbolicResources {
+		resources = append(resources, resource)
+	}
+
+	return resources, nil
+}
+
+// templateResource is a resource type deployed by a template, with the location it is deployed to.
+type templateResource struct {
+	Type     string
+	Location string
+}
+
+// locationScope resolves the locations of the resources of a template, from the values of its parameters.
+type locationScope struct {
+	// The resolved string values of the parameters of the template, by name
+	parameters map[string]string
+	// The location of the environment, which the resource group and the deployment are assumed to be in
+	envLocation string
+}
+
+// resolve returns the location set by the `location` property of a resource, and false when it can't be known before
+// provisioning, like a location computed by a function.
+func (s *locationScope) resolve(location string) (string, bool) {
+	if !strings.HasPrefix(location, "[") {
+		return location, true
+	}
+
+	switch location {
+	case "[resourceGroup().location]", "[deployment().location]":
+		return s.envLocation, s.envLocation != ""
+	}
+
+	if match := parameterReference.FindStringSubmatch(location); match != nil {
+		value, has := s.parameters[match[1]]
+		return value, has
+	}
+
+	return "", false
+}
+
+// nestedScope returns the scope of the template of a nested deployment, from the parameters it's deployed with and the
+// default values of the parameters of its template.
+func (s *locationScope) nestedScope(rawParameters json.RawMessage, rawTemplate json.RawMessage) (*locationScope, error) {
+	var passed map[string]struct {
+		Value any `json:"value"`
+	}
+	if len(rawParameters) > 0 {
+		if err := json.Unmarshal(rawParameters, &passed); err != nil {
+			return nil, err
+		}
+	}
+
+	values := map[string]any{}
+	for name, parameter := range passed {
+		values[name] = parameter.Value
+	}
+
+	return newLocationScope(rawTemplate, values, s, s.envLocation)
+}
+
+// newLocationScope returns the scope of a template deployed with the given parameter values. String values which are
+// expressions are resolved in the parent scope, when set.
+func newLocationScope(
+	rawTemplate json.RawMessage,
+	values map[string]any,
+	parent *locationScope,
+	envLocation string,
+) (*locationScope, error) {
+	var template struct {
+		Parameters map[string]struct {
+			DefaultValue any `json:"defaultValue"`
+		} `json:"parameters"`
+	}
+	if err := json.Unmarshal(rawTemplate, &template); err != nil {
+		return nil, err
+	}
+
+	scope := &locationScope{parameters: map[string]string{}, envLocation: envLocation}
+	for name, definition := range template.Parameters {
+		value, has := values[name]
+		resolveIn := parent
+		if !has {
+			// Default values are evaluated by the template itself
+			value = definition.DefaultValue
+			resolveIn = scope
+		}
+
+		stringValue, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		if resolveIn == nil && strings.HasPrefix(stringValue, "[") {
+			continue
+		}
+
+		if resolveIn != nil {
+			if stringValue, ok = resolveIn.resolve(stringValue); !ok {
+				continue
+			}
+		}
+
+		scope.parameters[name] = stringValue
+	}
+
+	return scope, nil
+}
+
+// templateResources returns the resource types deployed by the template and the locations they are deployed to, sorted
+// by type, including the resources of the templates of its nested deployments, like Bicep modules. Existing resources,
+// resources without a location or in the global location, and resources which location can't be resolved before
+// provisioning are excluded.
+func templateResources(
+	rawTemplate azure.RawArmTemplate,
+	parameters azure.ArmParameters,
+	envLocation string,
+) ([]templateResource, error) {
+	values := map[string]any{}
+	for name, parameter := range parameters {
+		values[name] = parameter.Value
+	}
+
+	scope, err := newLocationScope(json.RawMessage(rawTemplate), values, nil, envLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	found := map[templateResource]struct{}{}
+	if err := collectResources(json.RawMessage(rawTemplate), scope, found); err != nil {
+		return nil, err
+	}
+
+	resources := make([]templateResource, 0, len(found))
+	for resource := range found {
+		resources = append(resources, resource)
+	}
+
+	slices.SortFunc(resources, func(a, b templateResource) int {
+		if c := strings.Compare(a.Type, b.Type); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Location, b.Location)
+	})
+	return resources, nil
+}
+
+func collectResources(rawTemplate json.RawMessage, scope *locationScope, found map[templateResource]struct{}) error {
+	var template struct {
+		Resources json.RawMessage `json:"resources"`
+	}
+	if err := json.Unmarshal(rawTemplate, &template); err != nil {
+		return err
+	}
+
+	return collectTemplateResources(template.Resources, "", scope, found)
+}
+
+func collectTemplateResources(
+	rawResources json.RawMessage,
+	parentType string,
+	scope *locationScope,
+	found map[templateResource]struct{},
+) error {
+	resources, err := armTemplateResources(rawResources)
+	if err != nil {
+		return err
+	}
+
+	for _, resource := range resources {
+		resourceType := resource.Type
+		if parentType != "" && !strings.Contains(resourceType, "/") {
+			resourceType = parentType + "/" + resourceType
+		}
+
+		if resource.Existing || resourceType == "" {
+			continue
+		}
+
+		if strings.EqualFold(resourceType, nestedDeploymentResourceType) {
+			var properties struct {
+				Parameters json.RawMessage `json:"parameters"`
+				Template   json.RawMessage `json:"template"`
+			}
+			if len(resource.Properties) > 0 {
+				if err := json.Unmarshal(resource.Properties, &properties); err != nil {
+					return err
+				}
+			}
+
+			// Deployments of linked templates have no inline template
+			if len(properties.Template) > 0 {
+				nestedScope, err := scope.nestedScope(properties.Parameters, properties.Template)
+				if err != nil {
+					return err
+				}
+
+				if err := collectResources(properties.Template, nestedScope, found); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		var location string
+		if len(resource.Location) > 0 {
+			if err := json.Unmarshal(resource.Location, &location); err != nil {
+				return err
+			}
+		}
+
+		if location != "" {
+			if resolved, ok := scope.resolve(location); !ok {
+				log.Printf("skipping location check of resource type '%s': location '%s' can't be resolved",
+					resourceType, location)
+			} else if resolved = normalizeLocation(resolved); resolved != globalLocation {
+				found[templateResource{Type: resourceType, Location: resolved}] = struct{}{}
+			}
+		}
+
+		if err := collectTemplateResources(resource.Resources, resourceType, scope, found); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// normalizeLocation converts the display name of a location, like 'East US 2', to its name, like 'eastus2'.
+func normalizeLocation(location string) string {
+	return strings.ToLower(strings.ReplaceAll(location, " ", ""))
+}
+
+// checkLocationAvailability warns about the resources of the template deployed to a location which doesn't offer their
+// resource type, like a Static Web App outside of its handful of regions. Each resource is checked against its own
+// location, resolved from the parameters of the template. With AZD_STRICT_LOCATION_CHECK, the unavailable resource
+// types fail provisioning instead. Resource types which locations can't be listed aren't checked.
+func (p *BicepProvider) checkLocationAvailability(
+	ctx context.Context,
+	rawTemplate azure.RawArmTemplate,
+	parameters azure.ArmParameters,
+) error {
+	if skip, err := strconv.ParseBool(os.Getenv(skipLocationCheckEnvVarName)); err == nil && skip {
+		log.Printf("skipping location check since %s was set", skipLocationCheckEnvVarName)
+		return nil
+	}
+
+	if p.resourceTypeLocationsService == nil {
+		return nil
+	}
+
+	resources, err := templateResources(rawTemplate, parameters, normalizeLocation(p.env.GetLocation()))
+	if err != nil {
+		log.Printf("finding resources of the template: %v", err)
+		return nil
+	}
+
+	var unsupported []string
+	// The locations supporting all the unsupported resource types, to suggest as alternatives
+	var alternatives []string
+	for _, resource := range resources {
+		locations, err := p.resourceTypeLocationsService.ResourceTypeLocations(
+			ctx, p.env.GetSubscriptionId(), resource.Type)
+		if err != nil {
+			log.Printf("getting locations of resource type '%s': %v", resource.Type, err)
+			continue
+		}
+
+		// Resource types not bound to a location are available everywhere
+		if len(locations) == 0 {
+			continue
+		}
+
+		supported := make([]string, 0, len(locations))
+		for _, supportedLocation := range locations {
+			supported = append(supported, normalizeLocation(supportedLocation))
+		}
+
+		if slices.Contains(supported, resource.Location) {
+			continue
+		}
+
+		if len(unsupported) == 0 {
+			alternatives = supported
+		} else {
+			alternatives = slices.DeleteFunc(alternatives, func(alternative string) bool {
+				return !slices.Contains(supported, alternative)
+			})
+		}
+
+		unsupported = append(unsupported, fmt.Sprintf("%s in location '%s'", resource.Type, resource.Location))
+	}
+
+	if len(unsupported) == 0 {
+		return nil
+	}
+
+	suggestion := fmt.Sprintf(
+		"Choose other locations for these resources, or set %s to skip this check.",
+		output.WithHighLightFormat("%s=true", skipLocationCheckEnvVarName))
+	if len(alternatives) > 0 {
+		slices.Sort(alternatives)
+		suggestion = fmt.Sprintf(
+			"%s\nLocations where these resource types are available: %s",
+			suggestion,
+			strings.Join(alternatives, ", "))
+	}
+
+	unavailableErr := fmt.Errorf(
+		"the following resource types are not available in their location:\n  - %s",
+		strings.Join(unsupported, "\n  - "))
+
+	if strict, err := strconv.ParseBool(os.Getenv(strictLocationCheckEnvVarName)); err == nil && strict {
+		return &azcli.ErrorWithSuggestion{
+			Err:        unavailableErr,
+			Suggestion: suggestion,
+		}
+	}
+
+	// The list of locations of a resource type isn't always accurate, so the deployment is still attempted
+	p.console.Message(ctx, output.WithWarningFormat("WARNING: %s", unavailableErr.Error()))
+	p.console.Message(ctx, fmt.Sprintf("%s\n", suggestion))
+	return nil
+}
@@ -0,0 +1,176 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockinput"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeResourceTypeLocationsService returns the locations of the resource types in locations, and fails for the
+// other resource types.
+type fakeResourceTypeLocationsService struct {
+	locations map[string][]string
+	requested []string
+}
+
+func (f *fakeResourceTypeLocationsService) ResourceTypeLocations(
+	ctx context.Context,
+	subscriptionId string,
+	resourceType string,
+) ([]string, error) {
+	f.requested = append(f.requested, resourceType)
+
+	locations, has := f.locations[resourceType]
+	if !has {
+		return nil, errors.New("provider not registered")
+	}
+
+	return locations, nil
+}
+
+const locationAvailabilityTemplate = `{
+  "$schema": "https://schema.management.azure.com/schemas/2018-05-01/subscriptionDeploymentTemplate.json#",
+  "parameters": {
+    "location": { "type": "string" },
+    "webLocation": { "type": "string", "defaultValue": "westeurope" }
+  },
+  "resources": [
+    { "type": "Microsoft.Resources/resourceGroups", "name": "rg-dev", "location": "[parameters('location')]" },
+    {
+      "type": "Microsoft.Resources/deployments",
+      "name": "resources",
+      "properties": {
+        "parameters": {
+          "webLocation": { "value": "[parameters('webLocation')]" }
+        },
+        "template": {
+          "languageVersion": "2.0",
+          "parameters": {
+            "location": { "type": "string", "defaultValue": "[resourceGroup().location]" },
+            "webLocation": { "type": "string" }
+          },
+          "resources": {
+            "web": { "type": "Microsoft.Web/staticSites", "name": "web", "location": "[parameters('webLocation')]" },
+            "vault": {
+              "type": "Microsoft.KeyVault/vaults", "name": "kv", "location": "eastus", "existing": true
+            },
+            "openai": {
+              "type": "Microsoft.CognitiveServices/accounts",
+              "name": "oai",
+              "location": "[parameters('location')]",
+              "resources": [{ "type": "deployments", "name": "gpt" }]
+            },
+            "roles": { "type": "Microsoft.Authorization/roleAssignments", "name": "role" },
+            "dns": { "type": "Microsoft.Network/privateDnsZones", "name": "zone", "location": "global" },
+            "insights": {
+              "type": "Microsoft.Insights/components", "name": "appi", "location": "[format('{0}', 'eastus')]"
+            }
+          }
+        }
+      }
+    }
+  ]
+}`
+
+var locationAvailabilityParameters = azure.ArmParameters{
+	"location": {Value: "eastus"},
+}
+
+func Test_TemplateResources(t *testing.T) {
+	resources, err := templateResources(
+		azure.RawArmTemplate(locationAvailabilityTemplate), locationAvailabilityParameters, "eastus")
+	require.NoError(t, err)
+
+	// Each resource has its own location. Child resources without a location, resources in the global location and
+	// resources which location is computed by the template are excluded.
+	require.Equal(t, []templateResource{
+		{Type: "Microsoft.CognitiveServices/accounts", Location: "eastus"},
+		{Type: "Microsoft.Resources/resourceGroups", Location: "eastus"},
+		{Type: "Microsoft.Web/staticSites", Location: "westeurope"},
+	}, resources)
+}
+
+func Test_CheckLocationAvailability(t *testing.T) {
+	newProvider := func(location string) (*BicepProvider, *fakeResourceTypeLocationsService, *mockinput.MockConsole) {
+		resourceTypeLocations := &fakeResourceTypeLocationsService{
+			locations: map[string][]string{
+				"Microsoft.Resources/resourceGroups":   {"East US", "West Europe", "West US 2"},
+				"Microsoft.Web/staticSites":            {"West Europe", "West US 2", "East Asia"},
+				"Microsoft.CognitiveServices/accounts": {"West Europe", "West US 2"},
+			},
+		}
+		console := mockinput.NewMockConsole()
+
+		return &BicepProvider{
+			env: environment.NewWithValues("dev", map[string]string{
+				environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+				environment.LocationEnvVarName:       location,
+			}),
+			console:                      console,
+			resourceTypeLocationsService: resourceTypeLocations,
+		}, resourceTypeLocations, console
+	}
+
+	t.Run("Warning", func(t *testing.T) {
+		provider, resourceTypeLocations, console := newProvider("eastus")
+
+		err := provider.checkLocationAvailability(
+			context.Background(), azure.RawArmTemplate(locationAvailabilityTemplate), locationAvailabilityParameters)
+		require.NoError(t, err)
+
+		// The Static Web App is deployed to its own location, which supports it
+		consoleOutput := strings.Join(console.Output(), "\n")
+		require.Contains(t, consoleOutput, "Microsoft.CognitiveServices/accounts in location 'eastus'")
+		require.NotContains(t, consoleOutput, "Microsoft.Web/staticSites in location")
+		require.Contains(t, consoleOutput, "westeurope, westus2")
+
+		// Existing, location-less and global resources aren't checked
+		require.NotContains(t, resourceTypeLocations.requested, "Microsoft.KeyVault/vaults")
+		require.NotContains(t, resourceTypeLocations.requested, "Microsoft.Authorization/roleAssignments")
+		require.NotContains(t, resourceTypeLocations.requested, "Microsoft.Network/privateDnsZones")
+	})
+
+	t.Run("Strict", func(t *testing.T) {
+		t.Setenv(strictLocationCheckEnvVarName, "true")
+		provider, _, _ := newProvider("eastus")
+
+		err := provider.checkLocationAvailability(
+			context.Background(), azure.RawArmTemplate(locationAvailabilityTemplate), locationAvailabilityParameters)
+
+		var suggestionErr *azcli.ErrorWithSuggestion
+		require.ErrorAs(t, err, &suggestionErr)
+		require.ErrorContains(t, err, "Microsoft.CognitiveServices/accounts in location 'eastus'")
+		require.Equal(t, 1, strings.Count(err.Error(), "\n  - "))
+	})
+
+	t.Run("Supported", func(t *testing.T) {
+		t.Setenv(strictLocationCheckEnvVarName, "true")
+		provider, _, _ := newProvider("westus2")
+
+		err := provider.checkLocationAvailability(
+			context.Background(),
+			azure.RawArmTemplate(locationAvailabilityTemplate),
+			azure.ArmParameters{"location": {Value: "westus2"}})
+		require.NoError(t, err)
+	})
+
+	t.Run("Skipped", func(t *testing.T) {
+		t.Setenv(skipLocationCheckEnvVarName, "true")
+		provider, resourceTypeLocations, _ := newProvider("eastus")
+
+		err := provider.checkLocationAvailability(
+			context.Background(), azure.RawArmTemplate(locationAvailabilityTemplate), locationAvailabilityParameters)
+		require.NoError(t, err)
+		require.Empty(t, resourceTypeLocations.requested)
+	})
+}
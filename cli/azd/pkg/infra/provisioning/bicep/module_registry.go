@@ -0,0 +1,183 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	azruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/cloud"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+var (
+	// ErrRegistryModuleUnauthorized is returned when the registry denies access to a module.
+	ErrRegistryModuleUnauthorized = errors.New("access to the module was denied by the registry")
+	// ErrRegistryModuleNotFound is returned when the module or its version doesn't exist in the registry.
+	ErrRegistryModuleNotFound = errors.New("the module doesn't exist in the registry")
+)
+
+// ModuleRegistryClient resolves Bicep modules published to OCI registries, like Azure Container Registry.
+type ModuleRegistryClient interface {
+	// ResolveModule checks that the module exists in the registry and can be pulled. It returns
+	// ErrRegistryModuleUnauthorized or ErrRegistryModuleNotFound when the registry rejects the module, or another
+	// error when the registry can't be reached.
+	ResolveModule(ctx context.Context, subscriptionId string, module RegistryModule) error
+}
+
+type moduleRegistryClient struct {
+	containerRegistryService azcli.ContainerRegistryService
+	coreClientOptions        *azcore.ClientOptions
+	cloud                    *cloud.Cloud
+}
+
+// NewModuleRegistryClient creates a new ModuleRegistryClient, which authenticates to the Azure Container Registries of
+// the cloud with the credentials of the logged in user.
+func NewModuleRegistryClient(
+	containerRegistryService azcli.ContainerRegistryService,
+	coreClientOptions *azcore.ClientOptions,
+	cloud *cloud.Cloud,
+) ModuleRegistryClient {
+	return &moduleRegistryClient{
+		containerRegistryService: containerRegistryService,
+		coreClientOptions:        coreClientOptions,
+		cloud:                    cloud,
+	}
+}
+
+type registryAccessToken struct {
+	AccessToken string `json:"access_token"`
+}
+
+func (c *moduleRegistryClient) ResolveModule(ctx context.Context, subscriptionId string, module RegistryModule) error {
+	pipeline := azruntime.NewPipeline(
+		"azd-bicep-registry", internal.Version, azruntime.PipelineOptions{}, c.coreClientOptions)
+
+	// Public registries, like the Bicep public module registry, allow anonymous pulls
+	response, err := c.getManifest(ctx, pipeline, module, "")
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode == http.StatusUnauthorized {
+		if !strings.HasSuffix(module.Registry, "."+c.cloud.ContainerRegistryEndpointSuffix) {
+			return ErrRegistryModuleUnauthorized
+		}
+
+		authorization, err := c.authorization(ctx, pipeline, subscriptionId, module)
+		if err != nil {
+			return err
+		}
+
+		response, err = c.getManifest(ctx, pipeline, module, authorization)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch response.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrRegistryModuleUnauthorized
+	case http.StatusNotFound:
+		return ErrRegistryModuleNotFound
+	default:
+		return azruntime.NewResponseError(response)
+	}
+}
+
+// getManifest requests the manifest of the module version, using the authorization header when set.
+func (c *moduleRegistryClient) getManifest(
+	ctx context.Context,
+	pipeline azruntime.Pipeline,
+	module RegistryModule,
+	authorization string,
+) (*http.Response, error) {
+	manifestUrl := fmt.Sprintf("https://%s/v2/%s/manifests/%s", module.Registry, module.Repository, module.Version)
+	req, err := azruntime.NewRequest(ctx, http.MethodHead, manifestUrl)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Raw().Header.Set("Accept", "application/vnd.oci.image.manifest.v1+json")
+	if authorization != "" {
+		req.Raw().Header.Set("Authorization", authorization)
+	}
+
+	response, err := pipeline.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	return response, nil
+}
+
+// authorization gets the authorization header for pulling the module from an Azure Container Registry, exchanging
+// the registry refresh token of the logged in user for an access token scoped to the module repository.
+func (c *moduleRegistryClient) authorization(
+	ctx context.Context,
+	pipeline azruntime.Pipeline,
+	subscriptionId string,
+	module RegistryModule,
+) (string, error) {
+	credentials, err := c.containerRegistryService.Credentials(ctx, subscriptionId, module.Registry)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrRegistryModuleUnauthorized, err)
+	}
+
+	// Credentials of the admin user are used with basic authentication
+	if credentials.Username != "00000000-0000-0000-0000-000000000000" {
+		basicAuth := base64.StdEncoding.EncodeToString([]byte(credentials.Username + ":" + credentials.Password))
+		return fmt.Sprintf("Basic %s", basicAuth), nil
+	}
+
+	// Implementation based on docs @ https://azure.github.io/acr/AAD-OAuth.html
+	formData := url.Values{}
+	formData.Set("grant_type", "refresh_token")
+	formData.Set("service", module.Registry)
+	formData.Set("scope", fmt.Sprintf("repository:%s:pull", module.Repository))
+	formData.Set("refresh_token", credentials.Password)
+
+	req, err := azruntime.NewRequest(ctx, http.MethodPost, fmt.Sprintf("https://%s/oauth2/token", module.Registry))
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+
+	body := streaming.NopCloser(strings.NewReader(formData.Encode()))
+	if err := req.SetBody(body, "application/x-www-form-urlencoded"); err != nil {
+		return "", err
+	}
+
+	response, err := pipeline.Do(req)
+	if err != nil {
+		return "", err
+	}
+
+	if azruntime.HasStatusCode(response, http.StatusUnauthorized, http.StatusForbidden) {
+		return "", fmt.Errorf("%w: %w", ErrRegistryModuleUnauthorized, azruntime.NewResponseError(response))
+	}
+
+	if !azruntime.HasStatusCode(response, http.StatusOK) {
+		return "", azruntime.NewResponseError(response)
+	}
+
+	token, err := httputil.ReadRawResponse[registryAccessToken](response)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Bearer %s", token.AccessToken), nil
+}
@@ -0,0 +1,147 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/azure/azure-dev/cli/azd/pkg/cloud"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockhttp"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeContainerRegistryService returns a registry refresh token for the logged in user.
+type fakeContainerRegistryService struct {
+	azcli.ContainerRegistryService
+}
+
+func (f *fakeContainerRegistryService) Credentials(
+	ctx context.Context,
+	subscriptionId string,
+	loginServer string,
+) (*azcli.DockerCredentials, error) {
+	return &azcli.DockerCredentials{
+		Username:    "00000000-0000-0000-0000-000000000000",
+		Password:    "REFRESH_TOKEN",
+		LoginServer: loginServer,
+	}, nil
+}
+
+func Test_ModuleRegistryClient_ResolveModule(t *testing.T) {
+	tests := []struct {
+		name string
+		// The status code of the manifest request, without and with authorization
+		anonymousStatus  int
+		authorizedStatus int
+		tokenStatus      int
+		registry         string
+		expectErr        error
+		expectStatusCode int
+	}{
+		{name: "Anonymous", anonymousStatus: http.StatusOK, registry: "mcr.microsoft.com"},
+		{
+			name:            "NotFound",
+			anonymousStatus: http.StatusNotFound,
+			registry:        "mcr.microsoft.com",
+			expectErr:       ErrRegistryModuleNotFound,
+		},
+		{
+			name:            "UnauthorizedNotAcr",
+			anonymousStatus: http.StatusUnauthorized,
+			registry:        "registry.contoso.com",
+			expectErr:       ErrRegistryModuleUnauthorized,
+		},
+		{
+			name:             "AcrOfCloud",
+			anonymousStatus:  http.StatusUnauthorized,
+			authorizedStatus: http.StatusOK,
+			tokenStatus:      http.StatusOK,
+			registry:         "contoso.azurecr.us",
+		},
+		{
+			name:            "AcrTokenDenied",
+			anonymousStatus: http.StatusUnauthorized,
+			tokenStatus:     http.StatusUnauthorized,
+			registry:        "contoso.azurecr.us",
+			expectErr:       ErrRegistryModuleUnauthorized,
+		},
+		{
+			name:             "AcrTokenUnavailable",
+			anonymousStatus:  http.StatusUnauthorized,
+			tokenStatus:      http.StatusServiceUnavailable,
+			registry:         "contoso.azurecr.us",
+			expectStatusCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:             "UnexpectedStatus",
+			anonymousStatus:  http.StatusBadGateway,
+			registry:         "mcr.microsoft.com",
+			expectStatusCode: http.StatusBadGateway,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := mockhttp.NewMockHttpUtil()
+			httpClient.When(func(request *http.Request) bool {
+				return request.Method == http.MethodHead
+			}).RespondFn(func(request *http.Request) (*http.Response, error) {
+				require.Equal(t, tt.registry, request.URL.Host)
+				status := tt.anonymousStatus
+				if request.Header.Get("Authorization") != "" {
+					require.Equal(t, "Bearer ACCESS_TOKEN", request.Header.Get("Authorization"))
+					status = tt.authorizedStatus
+				}
+
+				return &http.Response{Request: request, StatusCode: status, Header: http.Header{}, Body: http.NoBody}, nil
+			})
+			httpClient.When(func(request *http.Request) bool {
+				return request.Method == http.MethodPost && request.URL.Path == "/oauth2/token"
+			}).RespondFn(func(request *http.Request) (*http.Response, error) {
+				return &http.Response{
+					Request:    request,
+					StatusCode: tt.tokenStatus,
+					Header:     http.Header{},
+					Body:       io.NopCloser(strings.NewReader(`{"access_token": "ACCESS_TOKEN"}`)),
+				}, nil
+			})
+
+			client := NewModuleRegistryClient(
+				&fakeContainerRegistryService{},
+				&azcore.ClientOptions{
+					Transport: httpClient,
+					// Unexpected status codes aren't retried in the tests
+					Retry: policy.RetryOptions{MaxRetries: -1},
+				},
+				cloud.AzureGovernment(),
+			)
+
+			err := client.ResolveModule(context.Background(), "SUBSCRIPTION_ID", RegistryModule{
+				Registry:   tt.registry,
+				Repository: "bicep/modules/storage",
+				Version:    "v1",
+			})
+
+			switch {
+			case tt.expectErr != nil:
+				require.ErrorIs(t, err, tt.expectErr)
+			case tt.expectStatusCode != 0:
+				var respErr *azcore.ResponseError
+				require.True(t, errors.As(err, &respErr), "expected a response error, got %v", err)
+				require.Equal(t, tt.expectStatusCode, respErr.StatusCode)
+				require.NotErrorIs(t, err, ErrRegistryModuleUnauthorized)
+			default:
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+// skipNameAvailabilityCheckEnvVarName is the environment variable to set to skip checking the availability of the
+// globally unique names of the resources before provisioning.
+const skipNameAvailabilityCheckEnvVarName = "AZD_SKIP_NAME_AVAILABILITY_CHECK"
+
+// globallyUniqueNameParameters maps the suffixes of the names of the parameters holding the name of a globally unique
+// resource, like 'storageAccountName' or 'apiKeyVaultName', to the type of the resource.
+var globallyUniqueNameParameters = []struct {
+	suffix       string
+	resourceType string
+}{
+	{"storageaccountname", "Microsoft.Storage/storageAccounts"},
+	{"keyvaultname", "Microsoft.KeyVault/vaults"},
+	{"containerregistryname", "Microsoft.ContainerRegistry/registries"},
+	{"webappname", "Microsoft.Web/sites"},
+	{"functionappname", "Microsoft.Web/sites"},
+	{"appservicename", "Microsoft.Web/sites"},
+	{"appconfigurationname", "Microsoft.AppConfiguration/configStores"},
+	{"appconfigname", "Microsoft.AppConfiguration/configStores"},
+}
+
+// globallyUniqueName is the name of a globally unique resource set by a parameter of the template.
+type globallyUniqueName struct {
+	parameter    string
+	resourceType string
+	name         string
+}
+
+// globallyUniqueNames returns the names of globally unique resources set by the parameters of the template, either by
+// the parameters file or by their default value. Names already stored in the environment, as the outputs of a previous
+// provisioning, belong to resources of the environment and are excluded.
+func (p *BicepProvider) globallyUniqueNames(
+	template azure.ArmTemplate,
+	parameters azure.ArmParameters,
+) []globallyUniqueName {
+	envValues := map[string]struct{}{}
+	for _, value := range p.env.Dotenv() {
+		envValues[strings.ToLower(value)] = struct{}{}
+	}
+
+	var names []globallyUniqueName
+	for parameterName, definition := range template.Parameters {
+		resourceType, has := globallyUniqueResourceType(parameterName)
+		if !has {
+			continue
+		}
+
+		var name string
+		if parameter, has := parameters[parameterName]; has {
+			name, _ = parameter.Value.(string)
+		} else {
+			name, _ = definition.DefaultValue.(string)
+		}
+
+		// Names computed by the template, like '[format(...)]', aren't known before provisioning
+		if name == "" || strings.HasPrefix(name, "[") {
+			continue
+		}
+
+		if _, has := envValues[strings.ToLower(name)]; has {
+			continue
+		}
+
+		names = append(names, globallyUniqueName{
+			parameter:    parameterName,
+			resourceType: resourceType,
+			name:         name,
+		})
+	}
+
+	slices.SortFunc(names, func(a, b globallyUniqueName) int {
+		return strings.Compare(a.parameter, b.parameter)
+	})
+
+	return names
+}
+
+func globallyUniqueResourceType(parameterName string) (string, bool) {
+	lowerName := strings.ToLower(parameterName)
+	for _, parameter := range globallyUniqueNameParameters {
+		if strings.HasSuffix(lowerName, parameter.suffix) {
+			return parameter.resourceType, true
+		}
+	}
+
+	return "", false
+}
+
+// checkNameAvailability checks that the globally unique names of the resources set by the parameters are available,
+// reporting all the names already taken at once rather than failing deep into provisioning. Names taken by a resource
+// of the subscription, like the resources of the environment on re-provision, aren't conflicts. Names held by a
+// soft-deleted resource are reported as a warning, since the resource can be recovered or purged. The check is
+// best-effort: names which availability can't be checked are ignored.
+func (p *BicepProvider) checkNameAvailability(
+	ctx context.Context,
+	template azure.ArmTemplate,
+	parameters azure.ArmParameters,
+) error {
+	if skip, err := strconv.ParseBool(os.Getenv(skipNameAvailabilityCheckEnvVarName)); err == nil && skip {
+		log.Printf("skipping name availability check since %s was set", skipNameAvailabilityCheckEnvVarName)
+		return nil
+	}
+
+	if p.nameAvailabilityService == nil {
+		return nil
+	}
+
+	var conflicts []string
+	for _, name := range p.globallyUniqueNames(template, parameters) {
+		availability, err := p.nameAvailabilityService.CheckNameAvailability(
+			ctx, p.env.GetSubscriptionId(), name.resourceType, name.name)
+		if err != nil {
+			log.Printf("checking availability of name '%s' for parameter '%s': %v", name.name, name.parameter, err)
+			continue
+		}
+
+		if availability.Available {
+			continue
+		}
+
+		subscriptionId := p.env.GetSubscriptionId()
+		if resourceId, err := p.nameAvailabilityService.FindResource(
+			ctx, subscriptionId, name.resourceType, name.name); err != nil {
+			log.Printf("finding resource '%s' in subscription: %v", name.name, err)
+		} else if resourceId != "" {
+			log.Printf("name '%s' is taken by resource '%s' of the subscription", name.name, resourceId)
+			continue
+		}
+
+		if softDeleted, err := p.nameAvailabilityService.IsSoftDeleted(
+			ctx, subscriptionId, name.resourceType, name.name); err != nil {
+			log.Printf("checking soft-deleted resources for name '%s': %v", name.name, err)
+		} else if softDeleted {
+			p.console.Message(ctx, output.WithWarningFormat(
+				"WARNING: '%s' (parameter '%s', %s) is held by a soft-deleted resource of the subscription. "+
+					"Recover or purge it before provisioning.\n",
+				name.name,
+				name.parameter,
+				name.resourceType))
+			continue
+		}
+
+		conflict := fmt.Sprintf("'%s' (parameter '%s', %s)", name.name, name.parameter, name.resourceType)
+		if availability.Message != "" {
+			conflict = fmt.Sprintf("%s: %s", conflict, availability.Message)
+		}
+
+		conflicts = append(conflicts, conflict)
+	}
+
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	return &azcli.ErrorWithSuggestion{
+		Err: fmt.Errorf(
+			"the following globally unique resource names are not available:\n  - %s",
+			strings.Join(conflicts, "\n  - ")),
+		Suggestion: fmt.Sprintf(
+			"Choose different names for the resources, or set %s to skip this check.",
+			output.WithHighLightFormat("%s=true", skipNameAvailabilityCheckEnvVarName)),
+	}
+}
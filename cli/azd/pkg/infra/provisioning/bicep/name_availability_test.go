@@ -0,0 +1,185 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azapi"
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockinput"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNameAvailabilityService reports the names in taken as not available, and fails for the names in failing. Taken
+// names in owned belong to resources of the subscription, and those in softDeleted to soft-deleted resources.
+type fakeNameAvailabilityService struct {
+	taken       map[string]bool
+	failing     map[string]bool
+	owned       map[string]bool
+	softDeleted map[string]bool
+	checked     []string
+}
+
+func (f *fakeNameAvailabilityService) CheckNameAvailability(
+	ctx context.Context,
+	subscriptionId string,
+	resourceType string,
+	name string,
+) (*azapi.NameAvailability, error) {
+	f.checked = append(f.checked, name)
+
+	if f.failing[name] {
+		return nil, errors.New("forbidden")
+	}
+
+	if f.taken[name] {
+		return &azapi.NameAvailability{
+			Available: false,
+			Reason:    "AlreadyExists",
+			Message:   "The name is already taken.",
+		}, nil
+	}
+
+	return &azapi.NameAvailability{Available: true}, nil
+}
+
+func (f *fakeNameAvailabilityService) FindResource(
+	ctx context.Context,
+	subscriptionId string,
+	resourceType string,
+	name string,
+) (string, error) {
+	if f.owned[name] {
+		return "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-dev/providers/" + resourceType + "/" + name, nil
+	}
+
+	return "", nil
+}
+
+func (f *fakeNameAvailabilityService) IsSoftDeleted(
+	ctx context.Context,
+	subscriptionId string,
+	resourceType string,
+	name string,
+) (bool, error) {
+	return f.softDeleted[name], nil
+}
+
+func newNameAvailabilityTemplate() azure.ArmTemplate {
+	return azure.ArmTemplate{
+		Parameters: azure.ArmTemplateParameterDefinitions{
+			"environmentName":       {Type: "string"},
+			"storageAccountName":    {Type: "string"},
+			"apiKeyVaultName":       {Type: "string", DefaultValue: "kv-contoso"},
+			"containerRegistryName": {Type: "string", DefaultValue: "[format('cr{0}', uniqueString(resourceGroup().id))]"},
+			"appConfigName":         {Type: "string"},
+			"webAppName":            {Type: "string"},
+			"backupKeyVaultName":    {Type: "string"},
+			"openAiName":            {Type: "string"},
+		},
+	}
+}
+
+func Test_CheckNameAvailability(t *testing.T) {
+	nameAvailability := &fakeNameAvailabilityService{
+		taken: map[string]bool{
+			"stcontoso": true, "kv-contoso": true, "appcs-existing": true, "app-contoso": true, "kv-deleted": true,
+		},
+		failing:     map[string]bool{"appcs-contoso": true},
+		owned:       map[string]bool{"app-contoso": true},
+		softDeleted: map[string]bool{"kv-deleted": true},
+	}
+	console := mockinput.NewMockConsole()
+
+	provider := &BicepProvider{
+		env: environment.NewWithValues("dev", map[string]string{
+			environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+			// Output of a previous provisioning of the environment
+			"AZURE_APP_CONFIG_NAME": "appcs-existing",
+		}),
+		console:                 console,
+		nameAvailabilityService: nameAvailability,
+	}
+
+	err := provider.checkNameAvailability(context.Background(), newNameAvailabilityTemplate(), azure.ArmParameters{
+		"environmentName":    {Value: "dev"},
+		"storageAccountName": {Value: "stcontoso"},
+		"appConfigName":      {Value: "appcs-contoso"},
+		"webAppName":         {Value: "app-contoso"},
+		"backupKeyVaultName": {Value: "kv-deleted"},
+		"openAiName":         {Value: "oai-contoso"},
+	})
+
+	// All the conflicts are reported at once
+	require.Error(t, err)
+	require.ErrorContains(t, err, "'kv-contoso' (parameter 'apiKeyVaultName', Microsoft.KeyVault/vaults)")
+	require.ErrorContains(t, err, "'stcontoso' (parameter 'storageAccountName', Microsoft.Storage/storageAccounts)")
+
+	// Names of resources of the subscription aren't conflicts, failures to check are ignored
+	require.NotContains(t, err.Error(), "app-contoso")
+	require.NotContains(t, err.Error(), "appcs-contoso")
+
+	// Names held by soft-deleted resources are reported as a warning
+	require.NotContains(t, err.Error(), "kv-deleted")
+	require.Contains(t, strings.Join(console.Output(), "\n"), "'kv-deleted' (parameter 'backupKeyVaultName'")
+
+	// Computed names and the names of resource types without a name availability API aren't checked
+	require.ElementsMatch(t,
+		[]string{"kv-contoso", "appcs-contoso", "stcontoso", "app-contoso", "kv-deleted"}, nameAvailability.checked)
+}
+
+func Test_CheckNameAvailability_EnvironmentNames(t *testing.T) {
+	nameAvailability := &fakeNameAvailabilityService{
+		taken: map[string]bool{"appcs-existing": true},
+	}
+
+	provider := &BicepProvider{
+		env: environment.NewWithValues("dev", map[string]string{
+			// Output of a previous provisioning of the environment
+			"AZURE_APP_CONFIG_NAME": "appcs-existing",
+		}),
+		nameAvailabilityService: nameAvailability,
+	}
+
+	err := provider.checkNameAvailability(context.Background(), newNameAvailabilityTemplate(), azure.ArmParameters{
+		"appConfigName": {Value: "appcs-existing"},
+	})
+	require.NoError(t, err)
+	require.NotContains(t, nameAvailability.checked, "appcs-existing")
+}
+
+func Test_CheckNameAvailability_Available(t *testing.T) {
+	provider := &BicepProvider{
+		env:                     environment.New("dev"),
+		nameAvailabilityService: &fakeNameAvailabilityService{},
+	}
+
+	err := provider.checkNameAvailability(context.Background(), newNameAvailabilityTemplate(), azure.ArmParameters{
+		"storageAccountName": {Value: "stcontoso"},
+	})
+	require.NoError(t, err)
+}
+
+func Test_CheckNameAvailability_Skipped(t *testing.T) {
+	t.Setenv(skipNameAvailabilityCheckEnvVarName, "true")
+
+	nameAvailability := &fakeNameAvailabilityService{
+		taken: map[string]bool{"stcontoso": true},
+	}
+	provider := &BicepProvider{
+		env:                     environment.New("dev"),
+		nameAvailabilityService: nameAvailability,
+	}
+
+	err := provider.checkNameAvailability(context.Background(), newNameAvailabilityTemplate(), azure.ArmParameters{
+		"storageAccountName": {Value: "stcontoso"},
+	})
+	require.NoError(t, err)
+	require.Empty(t, nameAvailability.checked)
+}
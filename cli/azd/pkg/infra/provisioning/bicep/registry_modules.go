@@ -0,0 +1,277 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+// RegistryModule is a Bicep module published to an OCI registry, referenced with 'br:<registry>/<path>:<version>' or
+// 'br/<alias>:<path>:<version>'.
+type RegistryModule struct {
+	// The reference to the module, as written in the Bicep file
+	Reference string
+	// The Bicep file referencing the module, relative to the infra directory
+	File string
+	// The login server of the registry, like 'mcr.microsoft.com'
+	Registry string
+	// The repository of the module in the registry, like 'bicep/avm/res/web/site'
+	Repository string
+	// The tag or digest of the module version
+	Version string
+}
+
+// registryModuleReferenceRegex matches the references to registry modules in the module declarations of Bicep files.
+var registryModuleReferenceRegex = regexp.MustCompile(`(?m)^\s*module\s+\w+\s+'(br[:/][^']+)'`)
+
+// moduleAlias is an alias of a module registry, configured in the 'moduleAliases.br' section of bicepconfig.json.
+type moduleAlias struct {
+	Registry   string `json:"registry"`
+	ModulePath string `json:"modulePath"`
+}
+
+// builtInModuleAliases are the aliases available without configuration, like the Bicep public module registry.
+var builtInModuleAliases = map[string]moduleAlias{
+	"public": {Registry: "mcr.microsoft.com", ModulePath: "bicep"},
+}
+
+type bicepConfig struct {
+	ModuleAliases struct {
+		Br map[string]moduleAlias `json:"br"`
+	} `json:"moduleAliases"`
+	// The directory of the local module cache of the Bicep CLI, '~/.bicep' when empty
+	CacheRootDirectory string `json:"cacheRootDirectory"`
+}
+
+// loadBicepConfig loads the bicepconfig.json file applying to the Bicep files of the directory, which is the closest
+// one in the directory or its parents, like the Bicep CLI does. The path of the file is empty when there's none.
+func loadBicepConfig(dir string) (bicepConfig, string, error) {
+	var config bicepConfig
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return config, "", err
+	}
+
+	for {
+		configPath := filepath.Join(dir, "bicepconfig.json")
+		contents, err := os.ReadFile(configPath)
+		if err == nil {
+			if err := json.Unmarshal(contents, &config); err != nil {
+				return config, "", fmt.Errorf("parsing %s: %w", configPath, err)
+			}
+
+			return config, configPath, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return config, "", err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return config, "", nil
+		}
+		dir = parent
+	}
+}
+
+// registryModules finds the registry modules referenced by the Bicep files of the infra directory. Each module is only
+// returned once, even when referenced by multiple files.
+func registryModules(infraDir string) ([]RegistryModule, error) {
+	config, _, err := loadBicepConfig(infraDir)
+	if err != nil {
+		return nil, err
+	}
+
+	aliases := maps.Clone(builtInModuleAliases)
+	maps.Copy(aliases, config.ModuleAliases.Br)
+
+	var modules []RegistryModule
+	seen := map[string]bool{}
+	err = filepath.WalkDir(infraDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || filepath.Ext(path) != bicepFileExtension {
+			return nil
+		}
+
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		file, err := filepath.Rel(infraDir, path)
+		if err != nil {
+			return err
+		}
+
+		for _, match := range registryModuleReferenceRegex.FindAllStringSubmatch(string(contents), -1) {
+			reference := match[1]
+			if seen[reference] {
+				continue
+			}
+			seen[reference] = true
+
+			module, err := parseRegistryModuleReference(reference, aliases)
+			if err != nil {
+				return fmt.Errorf("parsing module reference '%s' in '%s': %w", reference, file, err)
+			}
+
+			module.File = file
+			modules = append(modules, module)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return modules, nil
+}
+
+// parseRegistryModuleReference parses a reference to a registry module, resolving the registry of aliases.
+func parseRegistryModuleReference(reference string, aliases map[string]moduleAlias) (RegistryModule, error) {
+	module := RegistryModule{Reference: reference}
+
+	var path string
+	if after, has := strings.CutPrefix(reference, "br:"); has {
+		registry, modulePath, has := strings.Cut(after, "/")
+		if !has {
+			return module, errors.New("missing module path")
+		}
+
+		module.Registry = registry
+		path = modulePath
+	} else {
+		aliasName, modulePath, has := strings.Cut(strings.TrimPrefix(reference, "br/"), ":")
+		if !has {
+			return module, errors.New("missing module path")
+		}
+
+		alias, has := aliases[aliasName]
+		if !has {
+			return module, fmt.Errorf("module alias '%s' isn't defined in bicepconfig.json", aliasName)
+		}
+
+		module.Registry = alias.Registry
+		path = modulePath
+		if alias.ModulePath != "" {
+			path = strings.TrimSuffix(alias.ModulePath, "/") + "/" + modulePath
+		}
+	}
+
+	// The version is either a digest, like 'path@sha256:...', or a tag, like 'path:1.0.0'
+	if repository, digest, has := strings.Cut(path, "@"); has {
+		module.Repository = repository
+		module.Version = digest
+	} else if i := strings.LastIndex(path, ":"); i >= 0 {
+		module.Repository = path[:i]
+		module.Version = path[i+1:]
+	}
+
+	if module.Registry == "" || module.Repository == "" || module.Version == "" {
+		return module, errors.New("expected a reference like 'br:<registry>/<path>:<version>'")
+	}
+
+	return module, nil
+}
+
+// checkRegistryModules resolves the registry modules referenced by the Bicep files before they are compiled, so
+// failures to restore them are reported with their cause, like missing access to the registry or a module that doesn't
+// exist. Registries that can't be reached only cause a warning, since the Bicep CLI may restore the modules from its
+// local cache.
+func (p *BicepProvider) checkRegistryModules(ctx context.Context, modulePath string) error {
+	if p.moduleRegistryClient == nil {
+		return nil
+	}
+
+	modules, err := registryModules(filepath.Dir(modulePath))
+	if err != nil {
+		// Invalid references are reported by the Bicep CLI when compiling
+		log.Printf("finding registry modules: %v", err)
+		return nil
+	}
+
+	for _, module := range modules {
+		err := p.moduleRegistryClient.ResolveModule(ctx, p.env.GetSubscriptionId(), module)
+		if errors.Is(err, ErrRegistryModuleUnauthorized) || errors.Is(err, ErrRegistryModuleNotFound) {
+			return registryModuleError(module, err)
+		} else if err != nil {
+			p.console.Message(ctx, output.WithWarningFormat("WARNING: %s", registryModuleWarning(module, err)))
+		}
+	}
+
+	return nil
+}
+
+// registryModuleWarning describes the failure to check a registry module when the registry can't be reached or
+// responds with an unexpected status code.
+func registryModuleWarning(module RegistryModule, err error) string {
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) {
+		return fmt.Sprintf(
+			"Module '%s' referenced in '%s' couldn't be checked, registry '%s' responded with status code %d.",
+			module.Reference,
+			module.File,
+			module.Registry,
+			respErr.StatusCode,
+		)
+	}
+
+	return fmt.Sprintf(
+		"Module '%s' referenced in '%s' couldn't be checked, registry '%s' can't be reached: %v. Check your network "+
+			"connection, and that your proxy and firewall settings allow access to '%s'.",
+		module.Reference,
+		module.File,
+		module.Registry,
+		err,
+		module.Registry,
+	)
+}
+
+// registryModuleError describes the rejection of a registry module by its registry, with a suggestion depending on
+// its cause.
+func registryModuleError(module RegistryModule, err error) error {
+	switch {
+	case errors.Is(err, ErrRegistryModuleUnauthorized):
+		return &azcli.ErrorWithSuggestion{
+			Err: fmt.Errorf(
+				"resolving module '%s' referenced in '%s': %w", module.Reference, module.File, err),
+			Suggestion: fmt.Sprintf(
+				"Make sure you are logged in with 'azd auth login' using an account that can pull from registry '%s', "+
+					"for example with the AcrPull role.",
+				module.Registry,
+			),
+		}
+	case errors.Is(err, ErrRegistryModuleNotFound):
+		return &azcli.ErrorWithSuggestion{
+			Err: fmt.Errorf(
+				"resolving module '%s' referenced in '%s': %w", module.Reference, module.File, err),
+			Suggestion: fmt.Sprintf(
+				"Check that version '%s' of module '%s' is published to registry '%s'.",
+				module.Version,
+				module.Repository,
+				module.Registry,
+			),
+		}
+	default:
+		return fmt.Errorf("resolving module '%s' referenced in '%s': %w", module.Reference, module.File, err)
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeModuleRegistryClient fails to resolve the modules of the registries in errs.
+type fakeModuleRegistryClient struct {
+	errs     map[string]error
+	resolved []string
+}
+
+func (f *fakeModuleRegistryClient) ResolveModule(
+	ctx context.Context,
+	subscriptionId string,
+	module RegistryModule,
+) error {
+	f.resolved = append(f.resolved, module.Reference)
+	return f.errs[module.Registry]
+}
+
+const registryModulesMainBicep = `targetScope = 'subscription'
+
+module web 'br/public:avm/res/web/site:0.3.0' = {
+  name: 'web'
+}
+
+module storage 'br:contoso.azurecr.io/bicep/modules/storage:v1' = {
+  name: 'storage'
+}
+
+module local './app/api.bicep' = {
+  name: 'api'
+}
+`
+
+const registryModulesApiBicep = `module shared 'br/contoso:shared/network@sha256:0123' = {
+  name: 'network'
+}
+
+module storage 'br:contoso.azurecr.io/bicep/modules/storage:v1' = {
+  name: 'storage'
+}
+`
+
+const registryModulesBicepConfig = `{
+  "moduleAliases": {
+    "br": {
+      "contoso": { "registry": "contoso.azurecr.io", "modulePath": "bicep/modules" }
+    }
+  }
+}`
+
+func createRegistryModulesInfra(t *testing.T) string {
+	infraDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(infraDir, "app"), osutil.PermissionDirectory))
+	files := map[string]string{
+		"main.bicep":       registryModulesMainBicep,
+		"app/api.bicep":    registryModulesApiBicep,
+		"bicepconfig.json": registryModulesBicepConfig,
+	}
+	for name, contents := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(infraDir, name), []byte(contents), osutil.PermissionFile))
+	}
+
+	return infraDir
+}
+
+func Test_RegistryModules(t *testing.T) {
+	modules, err := registryModules(createRegistryModulesInfra(t))
+	require.NoError(t, err)
+	require.Equal(t, []RegistryModule{
+		{
+			Reference:  "br/contoso:shared/network@sha256:0123",
+			File:       filepath.Join("app", "api.bicep"),
+			Registry:   "contoso.azurecr.io",
+			Repository: "bicep/modules/shared/network",
+			Version:    "sha256:0123",
+		},
+		{
+			Reference:  "br:contoso.azurecr.io/bicep/modules/storage:v1",
+			File:       filepath.Join("app", "api.bicep"),
+			Registry:   "contoso.azurecr.io",
+			Repository: "bicep/modules/storage",
+			Version:    "v1",
+		},
+		{
+			Reference:  "br/public:avm/res/web/site:0.3.0",
+			File:       "main.bicep",
+			Registry:   "mcr.microsoft.com",
+			Repository: "bicep/avm/res/web/site",
+			Version:    "0.3.0",
+		},
+	}, modules)
+}
+
+func Test_ParseRegistryModuleReference_Invalid(t *testing.T) {
+	for _, reference := range []string{
+		"br:contoso.azurecr.io",
+		"br:contoso.azurecr.io/modules/storage",
+		"br/unknown:modules/storage:v1",
+	} {
+		_, err := parseRegistryModuleReference(reference, builtInModuleAliases)
+		require.Error(t, err, reference)
+	}
+}
+
+func Test_CheckRegistryModules(t *testing.T) {
+	tests := []struct {
+		name             string
+		err              error
+		expectError      string
+		expectSuggestion string
+	}{
+		{
+			name:             "Unauthorized",
+			err:              ErrRegistryModuleUnauthorized,
+			expectError:      "access to the module was denied by the registry",
+			expectSuggestion: "Make sure you are logged in with 'azd auth login'",
+		},
+		{
+			name:             "NotFound",
+			err:              ErrRegistryModuleNotFound,
+			expectError:      "the module doesn't exist in the registry",
+			expectSuggestion: "Check that version 'sha256:0123' of module 'bicep/modules/shared/network'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registryClient := &fakeModuleRegistryClient{
+				errs: map[string]error{"contoso.azurecr.io": tt.err},
+			}
+			provider := &BicepProvider{
+				env:                  environment.New("dev"),
+				moduleRegistryClient: registryClient,
+			}
+
+			err := provider.checkRegistryModules(
+				context.Background(), filepath.Join(createRegistryModulesInfra(t), "main.bicep"))
+
+			var suggestionErr *azcli.ErrorWithSuggestion
+			require.ErrorAs(t, err, &suggestionErr)
+			require.ErrorContains(t, err, tt.expectError)
+			require.ErrorContains(t, err, "br/contoso:shared/network@sha256:0123")
+			require.Contains(t, suggestionErr.Suggestion, tt.expectSuggestion)
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func Test_CheckRegistryModules_Unreachable(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		expectWarning string
+	}{
+		{
+			name:          "Network",
+			err:           errors.New("dial tcp: lookup contoso.azurecr.io: no such host"),
+			expectWarning: "registry 'contoso.azurecr.io' can't be reached: dial tcp",
+		},
+		{
+			name:          "UnexpectedStatus",
+			err:           &azcore.ResponseError{StatusCode: http.StatusBadGateway},
+			expectWarning: "registry 'contoso.azurecr.io' responded with status code 502",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockContext := mocks.NewMockContext(context.Background())
+			registryClient := &fakeModuleRegistryClient{
+				errs: map[string]error{"contoso.azurecr.io": tt.err},
+			}
+			provider := &BicepProvider{
+				env:                  environment.New("dev"),
+				console:              mockContext.Console,
+				moduleRegistryClient: registryClient,
+			}
+
+			// The Bicep CLI may restore the modules from its cache, so the compilation isn't prevented
+			err := provider.checkRegistryModules(
+				*mockContext.Context, filepath.Join(createRegistryModulesInfra(t), "main.bicep"))
+			require.NoError(t, err)
+			require.Len(t, registryClient.resolved, 3)
+			require.Contains(t, strings.Join(mockContext.Console.Output(), "\n"), tt.expectWarning)
+		})
+	}
+}
+
+func Test_CheckRegistryModules_Resolved(t *testing.T) {
+	registryClient := &fakeModuleRegistryClient{}
+	provider := &BicepProvider{
+		env:                  environment.New("dev"),
+		moduleRegistryClient: registryClient,
+	}
+
+	err := provider.checkRegistryModules(
+		context.Background(), filepath.Join(createRegistryModulesInfra(t), "main.bicep"))
+	require.NoError(t, err)
+	require.Len(t, registryClient.resolved, 3)
+}
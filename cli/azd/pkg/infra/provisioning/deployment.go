@@ -33,6 +33,8 @@ type InputParameter struct {
 type OutputParameter struct {
 	Type  ParameterType
 	Value interface{}
+	// Whether the value is a secret, like a secure Bicep output or a sensitive Terraform output
+	Secure bool
 }
 
 // State represents the "current state" of the infrastructure, which is the result of the most recent deployment. For ARM
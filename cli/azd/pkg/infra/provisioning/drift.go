@@ -0,0 +1,86 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// ProvisionedInfraHashPath is the environment config path of the hash of the infrastructure files as of the last
+// provision, used to detect infrastructure changes that haven't been provisioned yet.
+const ProvisionedInfraHashPath = "provision.infraHash"
+
+// InfraHash returns a hash of the files under the infrastructure directory infraRoot, like templates and parameter
+// files. The hash changes when a file is added, removed, renamed or edited.
+func InfraHash(infraRoot string) (string, error) {
+	hash := sha256.New()
+
+	err := filepath.WalkDir(infraRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(infraRoot, path)
+		if err != nil {
+			return err
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		// The files are walked in lexical order, so the hash doesn't depend on the order the files are listed in
+		fmt.Fprintf(hash, "%s\x00", filepath.ToSlash(relPath))
+		if _, err := io.Copy(hash, file); err != nil {
+			return err
+		}
+		_, err = hash.Write([]byte{0})
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("hashing infrastructure files: %w", err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// recordInfraHash stores the hash of the provisioned infrastructure files in the environment state, so `azd deploy`
+// can warn when the infrastructure changed since.
+func (m *Manager) recordInfraHash(ctx context.Context) error {
+	infraRoot := m.options.Path
+	if !filepath.IsAbs(infraRoot) {
+		infraRoot = filepath.Join(m.projectPath, infraRoot)
+	}
+
+	infraHash, err := InfraHash(infraRoot)
+	if err != nil {
+		// Drift detection is best effort, it shouldn't fail a successful provision
+		log.Printf("skipping recording the infrastructure hash: %v", err)
+		return nil
+	}
+
+	if recordedHash, _ := m.env.Config.GetString(ProvisionedInfraHashPath); recordedHash == infraHash {
+		return nil
+	}
+
+	if err := m.env.Config.Set(ProvisionedInfraHashPath, infraHash); err != nil {
+		return err
+	}
+
+	return m.envManager.Save(ctx, m.env)
+}
@@ -0,0 +1,135 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/password"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+// EphemeralResourceGroupPath is the environment config path of the resource group generated for an ephemeral
+// provision. The resource group is removed from the environment once its resources are deleted.
+const EphemeralResourceGroupPath = "provision.ephemeralResourceGroup"
+
+// ephemeralSuffixAlphabet is the alphabet of the random suffix of ephemeral resource group names, which is limited to
+// characters valid in the names of all resources a template may derive from the group name.
+const ephemeralSuffixAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// ensureEphemeralResourceGroup points an ephemeral environment at a uniquely named resource group, so each provision
+// of a preview environment deploys into its own group that can be deleted without affecting others. A resource group
+// set explicitly in the environment is kept.
+//
+// The group is passed to the template with AZURE_RESOURCE_GROUP, so the parameters file of the template must map it to
+// a parameter, like '"resourceGroupName": {"value": "${AZURE_RESOURCE_GROUP}"}'. Otherwise, the template would deploy
+// to a group shared with other environments, and the provision fails.
+func (m *Manager) ensureEphemeralResourceGroup() error {
+	if m.env.Getenv(environment.ResourceGroupEnvVarName) != "" {
+		return nil
+	}
+
+	parametersFiles, reads, err := m.readsResourceGroup()
+	if err != nil {
+		return fmt.Errorf("reading the parameters of the template: %w", err)
+	}
+
+	if !reads {
+		return &azcli.ErrorWithSuggestion{
+			Err: fmt.Errorf(
+				"an ephemeral provision deploys to the resource group in %s, which the parameters of the template "+
+					"don't read",
+				environment.ResourceGroupEnvVarName,
+			),
+			Suggestion: fmt.Sprintf(
+				"Set a parameter of the template to '${%s}' in %s, and deploy the resources to this resource group, "+
+					"or set %s in the environment.",
+				environment.ResourceGroupEnvVarName,
+				strings.Join(parametersFiles, " or "),
+				environment.ResourceGroupEnvVarName,
+			),
+		}
+	}
+
+	suffix, err := password.FromAlphabet(ephemeralSuffixAlphabet, 6)
+	if err != nil {
+		return fmt.Errorf("generating resource group name: %w", err)
+	}
+
+	resourceGroup := fmt.Sprintf("rg-%s-%s", m.env.Name(), suffix)
+	m.env.DotenvSet(environment.ResourceGroupEnvVarName, resourceGroup)
+
+	// Recorded so the generated group is removed from the environment by `azd down`, unlike a group set explicitly
+	return m.env.Config.Set(EphemeralResourceGroupPath, resourceGroup)
+}
+
+// readsResourceGroup returns whether the parameters file of the template references AZURE_RESOURCE_GROUP, along with
+// the names of the parameters files the providers read.
+func (m *Manager) readsResourceGroup() ([]string, bool, error) {
+	infraRoot := m.options.Path
+	if !filepath.IsAbs(infraRoot) {
+		infraRoot = filepath.Join(m.projectPath, infraRoot)
+	}
+
+	// The parameters files of the bicep and terraform providers
+	parametersFiles := []string{
+		m.options.Module + ".parameters.json",
+		m.options.Module + ".bicepparam",
+		m.options.Module + ".tfvars.json",
+	}
+
+	for _, parametersFile := range parametersFiles {
+		contents, err := os.ReadFile(filepath.Join(infraRoot, parametersFile))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return parametersFiles, false, err
+		}
+
+		if strings.Contains(string(contents), environment.ResourceGroupEnvVarName) {
+			return parametersFiles, true, nil
+		}
+	}
+
+	return parametersFiles, false, nil
+}
+
+// clearEphemeralResourceGroup removes the resource group generated for an ephemeral provision from the environment,
+// once its resources are deleted, so a later provision doesn't reuse it.
+func (m *Manager) clearEphemeralResourceGroup() error {
+	resourceGroup, has := m.env.Config.GetString(EphemeralResourceGroupPath)
+	if !has {
+		return nil
+	}
+
+	if resourceGroup == m.env.Getenv(environment.ResourceGroupEnvVarName) {
+		m.env.DotenvDelete(environment.ResourceGroupEnvVarName)
+	}
+
+	return m.env.Config.Unset(EphemeralResourceGroupPath)
+}
+
+// deployEphemeral finishes the provision of an ephemeral environment. Only the values `azd down` needs to target the
+// provisioned resources are saved, like the environment name, subscription, location and resource group. The outputs
+// of the deployment are set as an overlay of the environment, for the hooks and commands running as part of this
+// provision, which isn't saved nor cleared when the hooks reload the environment.
+func (m *Manager) deployEphemeral(ctx context.Context, outputs map[string]OutputParameter) error {
+	if err := m.envManager.Save(ctx, m.env); err != nil {
+		return fmt.Errorf("saving environment: %w", err)
+	}
+
+	values, err := outputValues(outputs)
+	if err != nil {
+		return err
+	}
+
+	m.env.SetOverlay(values)
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/alpha"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
@@ -46,9 +47,20 @@ func (m *Manager) Initialize(ctx context.Context, projectPath string, options Op
 		options.Path = defaultPath
 	}
 
+	if options.Ephemeral {
+		// Ephemeral environments are provisioned from scratch, the state of previous deployments doesn't apply.
+		options.IgnoreDeploymentState = true
+	}
+
 	m.projectPath = projectPath
 	m.options = &options
 
+	if options.Ephemeral {
+		if err := m.ensureEphemeralResourceGroup(); err != nil {
+			return err
+		}
+	}
+
 	provider, err := m.newProvider(ctx)
 	if err != nil {
 		return fmt.Errorf("initializing infrastructure provider: %w", err)
@@ -70,6 +82,17 @@ func (m *Manager) State(ctx context.Context, options *StateOptions) (*StateResul
 
 // Deploys the Azure infrastructure for the specified project
 func (m *Manager) Deploy(ctx context.Context) (*DeployResult, error) {
+	ephemeral := m.options != nil && m.options.Ephemeral
+
+	var previousResourceGroup string
+	if !ephemeral {
+		group, err := m.ensureResourceGroupReuse(ctx)
+		if err != nil {
+			return nil, err
+		}
+		previousResourceGroup = group
+	}
+
 	// Apply the infrastructure deployment
 	deployResult, err := m.provider.Deploy(ctx)
 	if err != nil {
@@ -82,10 +105,29 @@ func (m *Manager) Deploy(ctx context.Context) (*DeployResult, error) {
 		m.console.StopSpinner(ctx, "Didn't find new changes.", input.StepSkipped)
 	}
 
+	if ephemeral {
+		if err := m.deployEphemeral(ctx, deployResult.Deployment.Outputs); err != nil {
+			return nil, fmt.Errorf("updating environment with deployment outputs: %w", err)
+		}
+
+		// make sure any spinner is stopped
+		m.console.StopSpinner(ctx, "", input.StepDone)
+
+		return deployResult, nil
+	}
+
 	if err := m.UpdateEnvironment(ctx, deployResult.Deployment.Outputs); err != nil {
 		return nil, fmt.Errorf("updating environment with deployment outputs: %w", err)
 	}
 
+	if err := m.recordResourceGroup(ctx, previousResourceGroup); err != nil {
+		return nil, fmt.Errorf("recording provisioned resource group: %w", err)
+	}
+
+	if err := m.recordInfraHash(ctx); err != nil {
+		return nil, fmt.Errorf("recording provisioned infrastructure: %w", err)
+	}
+
 	// make sure any spinner is stopped
 	m.console.StopSpinner(ctx, "", input.StepDone)
 
@@ -139,6 +181,10 @@ func (m *Manager) Destroy(ctx context.Context, options DestroyOptions) (*Destroy
 		m.env.DotenvDelete(key)
 	}
 
+	if err := m.clearEphemeralResourceGroup(); err != nil {
+		return nil, fmt.Errorf("clearing the resource group of the ephemeral provision: %w", err)
+	}
+
 	// Update environment files to remove invalid infrastructure parameters
 	if err := m.envManager.Save(ctx, m.env); err != nil {
 		return nil, fmt.Errorf("saving environment: %w", err)
@@ -152,17 +198,15 @@ func (m *Manager) UpdateEnvironment(
 	outputs map[string]OutputParameter,
 ) error {
 	if len(outputs) > 0 {
-		for key, param := range outputs {
-			// Complex types marshalled as JSON strings, simple types marshalled as simple strings
-			if param.Type == ParameterTypeArray || param.Type == ParameterTypeObject {
-				bytes, err := json.Marshal(param.Value)
-				if err != nil {
-					return fmt.Errorf("invalid value for output parameter '%s' (%s): %w", key, string(param.Type), err)
-				}
-				m.env.DotenvSet(key, string(bytes))
-			} else {
-				m.env.DotenvSet(key, fmt.Sprintf("%v", param.Value))
-			}
+		// The outputs are all set at once, so the environment isn't partially updated when an output is invalid
+		values, err := outputValues(outputs)
+		if err != nil {
+			return err
+		}
+
+		m.env.DotenvSetMany(values)
+		if err := setSecureOutputs(m.env, outputs); err != nil {
+			return err
 		}
 
 		if err := m.envManager.Save(ctx, m.env); err != nil {
@@ -173,6 +217,69 @@ func (m *Manager) UpdateEnvironment(
 	return nil
 }
 
+// SecureOutputsConfigPath is the path in environment config that holds the names of the outputs whose values are secrets,
+// so the services they're passed to can store them as secrets.
+const SecureOutputsConfigPath = "provision.secureOutputs"
+
+// SecureOutputs returns the names of the outputs of the environment whose values are secrets.
+func SecureOutputs(env *environment.Environment) ([]string, error) {
+	var names []string
+	if _, err := env.Config.GetSection(SecureOutputsConfigPath, &names); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", SecureOutputsConfigPath, err)
+	}
+
+	return names, nil
+}
+
+// setSecureOutputs records which of the outputs are secrets, keeping the outputs of previous deployments.
+func setSecureOutputs(env *environment.Environment, outputs map[string]OutputParameter) error {
+	names, err := SecureOutputs(env)
+	if err != nil {
+		return err
+	}
+
+	previousCount := len(names)
+	names = slices.DeleteFunc(names, func(name string) bool {
+		_, has := outputs[name]
+		return has
+	})
+	for key, param := range outputs {
+		if param.Secure {
+			names = append(names, key)
+		}
+	}
+
+	if len(names) == 0 {
+		if previousCount == 0 {
+			return nil
+		}
+
+		return env.Config.Unset(SecureOutputsConfigPath)
+	}
+
+	slices.Sort(names)
+	return env.Config.Set(SecureOutputsConfigPath, names)
+}
+
+// outputValues returns the environment values of the deployment outputs.
+func outputValues(outputs map[string]OutputParameter) (map[string]string, error) {
+	values := make(map[string]string, len(outputs))
+	for key, param := range outputs {
+		// Complex types marshalled as JSON strings, simple types marshalled as simple strings
+		if param.Type == ParameterTypeArray || param.Type == ParameterTypeObject {
+			bytes, err := json.Marshal(param.Value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for output parameter '%s' (%s): %w", key, string(param.Type), err)
+			}
+			values[key] = string(bytes)
+		} else {
+			values[key] = fmt.Sprintf("%v", param.Value)
+		}
+	}
+
+	return values, nil
+}
+
 // EnsureSubscriptionAndLocation ensures that that that subscription (AZURE_SUBSCRIPTION_ID) and location (AZURE_LOCATION)
 // variables are set in the environment, prompting the user for the values if they do not exist.
 func EnsureSubscriptionAndLocation(
@@ -5,16 +5,26 @@ package provisioning_test
 
 import (
 	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/account"
 	"github.com/azure/azure-dev/cli/azd/pkg/cloud"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/ext"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
 	. "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning/test"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 	"github.com/azure/azure-dev/cli/azd/pkg/prompt"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
@@ -22,6 +32,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
 	"github.com/azure/azure-dev/cli/azd/test/mocks/mockenv"
 	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -141,6 +152,125 @@ func TestManagerDeploy(t *testing.T) {
 	require.Nil(t, err)
 }
 
+func TestManagerDeployRecordsResourceGroup(t *testing.T) {
+	env := environment.NewWithValues("test-env", map[string]string{
+		"AZURE_ENV_NAME":        "test-env",
+		"AZURE_SUBSCRIPTION_ID": "SUBSCRIPTION_ID",
+		"AZURE_LOCATION":        "eastus2",
+		"AZURE_RESOURCE_GROUP":  "rg-test-env",
+	})
+
+	mockContext := mocks.NewMockContext(context.Background())
+	registerContainerDependencies(mockContext, env)
+
+	envManager := &mockenv.MockEnvManager{}
+	envManager.On("Save", *mockContext.Context, env).Return(nil)
+
+	mgr := NewManager(
+		mockContext.Container,
+		defaultProvider,
+		envManager,
+		env,
+		mockContext.Console,
+		mockContext.AlphaFeaturesManager,
+	)
+	err := mgr.Initialize(*mockContext.Context, "", Options{Provider: "test"})
+	require.NoError(t, err)
+
+	_, err = mgr.Deploy(*mockContext.Context)
+	require.NoError(t, err)
+
+	resourceGroup, _ := env.Config.GetString(ProvisionedResourceGroupPath)
+	envName, _ := env.Config.GetString(ProvisionedEnvNamePath)
+	require.Equal(t, "rg-test-env", resourceGroup)
+	require.Equal(t, "test-env", envName)
+}
+
+func TestManagerDeployRecordsInfraHash(t *testing.T) {
+	env := environment.NewWithValues("test-env", map[string]string{
+		"AZURE_SUBSCRIPTION_ID": "SUBSCRIPTION_ID",
+		"AZURE_LOCATION":        "eastus2",
+	})
+
+	projectPath := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(projectPath, "infra"), osutil.PermissionDirectory))
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "infra", "main.bicep"), []byte("param location string"), 0600))
+
+	mockContext := mocks.NewMockContext(context.Background())
+	registerContainerDependencies(mockContext, env)
+
+	envManager := &mockenv.MockEnvManager{}
+	envManager.On("Save", *mockContext.Context, env).Return(nil)
+
+	mgr := NewManager(
+		mockContext.Container,
+		defaultProvider,
+		envManager,
+		env,
+		mockContext.Console,
+		mockContext.AlphaFeaturesManager,
+	)
+	err := mgr.Initialize(*mockContext.Context, projectPath, Options{Provider: "test"})
+	require.NoError(t, err)
+
+	_, err = mgr.Deploy(*mockContext.Context)
+	require.NoError(t, err)
+
+	infraHash, err := InfraHash(filepath.Join(projectPath, "infra"))
+	require.NoError(t, err)
+
+	recordedHash, _ := env.Config.GetString(ProvisionedInfraHashPath)
+	require.Equal(t, infraHash, recordedHash)
+
+	// Editing the infrastructure changes the hash
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "infra", "main.bicep"), []byte("param sku string"), 0600))
+	changedHash, err := InfraHash(filepath.Join(projectPath, "infra"))
+	require.NoError(t, err)
+	require.NotEqual(t, infraHash, changedHash)
+}
+
+func TestManagerDeployWarnsAndReusesResourceGroup(t *testing.T) {
+	env := environment.NewWithValues("test-env", map[string]string{
+		"AZURE_ENV_NAME":        "renamed-env",
+		"AZURE_SUBSCRIPTION_ID": "SUBSCRIPTION_ID",
+		"AZURE_LOCATION":        "eastus2",
+	})
+	require.NoError(t, env.Config.Set(ProvisionedResourceGroupPath, "rg-test-env"))
+	require.NoError(t, env.Config.Set(ProvisionedEnvNamePath, "test-env"))
+
+	mockContext := mocks.NewMockContext(context.Background())
+	registerContainerDependencies(mockContext, env)
+
+	envManager := &mockenv.MockEnvManager{}
+	envManager.On("Save", *mockContext.Context, env).Return(nil)
+
+	mockContext.Console.WhenSelect(func(options input.ConsoleOptions) bool {
+		return options.Message == "How do you want to continue?"
+	}).Respond(0)
+
+	mgr := NewManager(
+		mockContext.Container,
+		defaultProvider,
+		envManager,
+		env,
+		mockContext.Console,
+		mockContext.AlphaFeaturesManager,
+	)
+	err := mgr.Initialize(*mockContext.Context, "", Options{Provider: "test"})
+	require.NoError(t, err)
+
+	_, err = mgr.Deploy(*mockContext.Context)
+	require.NoError(t, err)
+
+	require.Contains(t, strings.Join(mockContext.Console.Output(), "\n"), "leave 'rg-test-env' orphaned")
+	require.Equal(t, "rg-test-env", env.Getenv(environment.ResourceGroupEnvVarName))
+
+	resourceGroup, _ := env.Config.GetString(ProvisionedResourceGroupPath)
+	envName, _ := env.Config.GetString(ProvisionedEnvNamePath)
+	require.Equal(t, "rg-test-env", resourceGroup)
+	require.Equal(t, "renamed-env", envName)
+}
+
 func TestManagerDestroyWithPositiveConfirmation(t *testing.T) {
 	env := environment.NewWithValues("test-env", map[string]string{
 		"AZURE_SUBSCRIPTION_ID": "SUBSCRIPTION_ID",
@@ -210,6 +340,93 @@ func TestManagerDestroyWithNegativeConfirmation(t *testing.T) {
 	require.Contains(t, mockContext.Console.Output(), "Are you sure you want to destroy?")
 }
 
+func TestManagerUpdateEnvironment(t *testing.T) {
+	t.Run("SavesOnce", func(t *testing.T) {
+		env := environment.NewWithValues("test-env", nil)
+		mockContext := mocks.NewMockContext(context.Background())
+		registerContainerDependencies(mockContext, env)
+
+		envManager := &mockenv.MockEnvManager{}
+		envManager.On("Save", *mockContext.Context, env).Return(nil)
+		mgr := NewManager(
+			mockContext.Container,
+			defaultProvider,
+			envManager,
+			env,
+			mockContext.Console,
+			mockContext.AlphaFeaturesManager,
+		)
+
+		err := mgr.UpdateEnvironment(*mockContext.Context, map[string]OutputParameter{
+			"WEBSITE_URL":   {Type: ParameterTypeString, Value: "https://web.example.com"},
+			"WEBSITE_PORT":  {Type: ParameterTypeNumber, Value: 443},
+			"WEBSITE_HOSTS": {Type: ParameterTypeArray, Value: []any{"web.example.com"}},
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, "https://web.example.com", env.Getenv("WEBSITE_URL"))
+		require.Equal(t, "443", env.Getenv("WEBSITE_PORT"))
+		require.Equal(t, `["web.example.com"]`, env.Getenv("WEBSITE_HOSTS"))
+		envManager.AssertNumberOfCalls(t, "Save", 1)
+	})
+
+	t.Run("SecureOutputs", func(t *testing.T) {
+		env := environment.NewWithValues("test-env", nil)
+		require.NoError(t, env.Config.Set(SecureOutputsConfigPath, []string{"SQL_PASSWORD", "STORAGE_KEY"}))
+		mockContext := mocks.NewMockContext(context.Background())
+		registerContainerDependencies(mockContext, env)
+
+		envManager := &mockenv.MockEnvManager{}
+		envManager.On("Save", *mockContext.Context, env).Return(nil)
+		mgr := NewManager(
+			mockContext.Container,
+			defaultProvider,
+			envManager,
+			env,
+			mockContext.Console,
+			mockContext.AlphaFeaturesManager,
+		)
+
+		err := mgr.UpdateEnvironment(*mockContext.Context, map[string]OutputParameter{
+			"SQL_CONN":     {Type: ParameterTypeString, Value: "Server=sql.example.com", Secure: true},
+			"SQL_PASSWORD": {Type: ParameterTypeString, Value: "not-a-secret-anymore"},
+		})
+		require.NoError(t, err)
+
+		// The secure outputs of previous deployments are kept, unless the output isn't secure anymore
+		secureOutputs, err := SecureOutputs(env)
+		require.NoError(t, err)
+		require.Equal(t, []string{"SQL_CONN", "STORAGE_KEY"}, secureOutputs)
+	})
+
+	t.Run("InvalidOutput", func(t *testing.T) {
+		env := environment.NewWithValues("test-env", nil)
+		mockContext := mocks.NewMockContext(context.Background())
+		registerContainerDependencies(mockContext, env)
+
+		envManager := &mockenv.MockEnvManager{}
+		mgr := NewManager(
+			mockContext.Container,
+			defaultProvider,
+			envManager,
+			env,
+			mockContext.Console,
+			mockContext.AlphaFeaturesManager,
+		)
+
+		err := mgr.UpdateEnvironment(*mockContext.Context, map[string]OutputParameter{
+			"WEBSITE_URL":    {Type: ParameterTypeString, Value: "https://web.example.com"},
+			"WEBSITE_CONFIG": {Type: ParameterTypeObject, Value: map[string]any{"handler": func() {}}},
+		})
+		require.ErrorContains(t, err, "invalid value for output parameter 'WEBSITE_CONFIG'")
+
+		// None of the outputs are set when one of them is invalid
+		_, has := env.LookupEnv("WEBSITE_URL")
+		require.False(t, has)
+		envManager.AssertNotCalled(t, "Save")
+	})
+}
+
 func registerContainerDependencies(mockContext *mocks.MockContext, env *environment.Environment) {
 	envManager := &mockenv.MockEnvManager{}
 	envManager.On("Save", *mockContext.Context, env).Return(nil)
@@ -259,3 +476,233 @@ func registerContainerDependencies(mockContext *mocks.MockContext, env *environm
 func defaultProvider() (ProviderKind, error) {
 	return Bicep, nil
 }
+
+// ephemeralTestProvider returns deployment outputs and records the resource group the environment targets on destroy.
+type ephemeralTestProvider struct {
+	Provider
+	env                  *environment.Environment
+	destroyResourceGroup string
+}
+
+func (p *ephemeralTestProvider) Deploy(ctx context.Context) (*DeployResult, error) {
+	return &DeployResult{
+		Deployment: &Deployment{
+			Parameters: map[string]InputParameter{},
+			Outputs: map[string]OutputParameter{
+				"WEBSITE_URL": {Type: ParameterTypeString, Value: "https://web.example.com"},
+			},
+		},
+	}, nil
+}
+
+func (p *ephemeralTestProvider) Destroy(ctx context.Context, options DestroyOptions) (*DestroyResult, error) {
+	p.destroyResourceGroup = p.env.Getenv(environment.ResourceGroupEnvVarName)
+	return &DestroyResult{InvalidatedEnvKeys: []string{"WEBSITE_URL"}}, nil
+}
+
+func TestManagerDeployEphemeral(t *testing.T) {
+	env := environment.NewWithValues("pr-42", map[string]string{
+		"AZURE_ENV_NAME":        "pr-42",
+		"AZURE_SUBSCRIPTION_ID": "SUBSCRIPTION_ID",
+		"AZURE_LOCATION":        "eastus2",
+	})
+
+	mockContext := mocks.NewMockContext(context.Background())
+	registerContainerDependencies(mockContext, env)
+
+	var provider *ephemeralTestProvider
+	mockContext.Container.MustRegisterNamedTransient(string(provisioning.Test), func(
+		envManager environment.Manager,
+		env *environment.Environment,
+		console input.Console,
+		prompter prompt.Prompter,
+	) Provider {
+		provider = &ephemeralTestProvider{
+			Provider: test.NewTestProvider(envManager, env, console, prompter),
+			env:      env,
+		}
+		return provider
+	})
+
+	// The values written to the environment file
+	var saved map[string]string
+	envManager := &mockenv.MockEnvManager{}
+	envManager.On("Save", *mockContext.Context, env).Run(func(args mock.Arguments) {
+		saved = maps.Clone(env.Dotenv())
+	}).Return(nil)
+
+	mgr := NewManager(
+		mockContext.Container,
+		defaultProvider,
+		envManager,
+		env,
+		mockContext.Console,
+		mockContext.AlphaFeaturesManager,
+	)
+	projectPath := newEphemeralTestProject(t, `{"parameters": {"resourceGroupName": {"value": "${AZURE_RESOURCE_GROUP}"}}}`)
+	err := mgr.Initialize(
+		*mockContext.Context, projectPath, Options{Provider: "test", Ephemeral: true, Ttl: time.Hour})
+	require.NoError(t, err)
+
+	_, err = mgr.Deploy(*mockContext.Context)
+	require.NoError(t, err)
+
+	resourceGroup := env.Getenv(environment.ResourceGroupEnvVarName)
+	require.Regexp(t, `^rg-pr-42-[a-z0-9]{6}$`, resourceGroup)
+
+	// Only the values needed to deprovision are saved, the outputs are only available in memory
+	require.Equal(t, map[string]string{
+		"AZURE_ENV_NAME":        "pr-42",
+		"AZURE_SUBSCRIPTION_ID": "SUBSCRIPTION_ID",
+		"AZURE_LOCATION":        "eastus2",
+		"AZURE_RESOURCE_GROUP":  resourceGroup,
+	}, saved)
+	require.Equal(t, "https://web.example.com", env.Getenv("WEBSITE_URL"))
+
+	recordedGroup, _ := env.Config.GetString(ProvisionedResourceGroupPath)
+	require.Empty(t, recordedGroup)
+
+	t.Run("PostprovisionHook", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("the hook runs with bash")
+		}
+
+		// The hooks runner reloads the environment from its file, with the saved values, before running each hook
+		dataStore := environment.NewLocalFileDataStore(
+			azdcontext.NewAzdContextWithDirectory(projectPath), config.NewFileConfigManager(config.NewManager()))
+		require.NoError(t, dataStore.Save(*mockContext.Context, environment.NewWithValues("pr-42", maps.Clone(saved))))
+
+		hooksEnvManager := &mockenv.MockEnvManager{}
+		hooksEnvManager.On("Reload", mock.Anything, env).Run(func(args mock.Arguments) {
+			require.NoError(t, dataStore.Reload(args.Get(0).(context.Context), env))
+		}).Return(nil)
+
+		hooks := map[string]*ext.HookConfig{
+			"postprovision": {
+				Shell: ext.ShellTypeBash,
+				Run:   `echo "$WEBSITE_URL" > hook.out`,
+			},
+		}
+		hooksRunner := ext.NewHooksRunner(
+			ext.NewHooksManager(projectPath),
+			exec.NewCommandRunner(nil),
+			hooksEnvManager,
+			mockContext.Console,
+			projectPath,
+			hooks,
+			env,
+		)
+
+		err := hooksRunner.RunHooks(*mockContext.Context, ext.HookTypePost, nil, "provision")
+		require.NoError(t, err)
+
+		// The outputs are still available once the environment is reloaded
+		hookOutput, err := os.ReadFile(filepath.Join(projectPath, "hook.out"))
+		require.NoError(t, err)
+		require.Equal(t, "https://web.example.com\n", string(hookOutput))
+	})
+
+	t.Run("Down", func(t *testing.T) {
+		// `azd down` runs in a new process, from the saved environment
+		savedEnv := environment.NewWithValues("pr-42", saved)
+		require.NoError(t, savedEnv.Config.Set(EphemeralResourceGroupPath, resourceGroup))
+		mockContext := mocks.NewMockContext(context.Background())
+		registerContainerDependencies(mockContext, savedEnv)
+		mockContext.Container.MustRegisterNamedTransient(string(provisioning.Test), func(
+			envManager environment.Manager,
+			env *environment.Environment,
+			console input.Console,
+			prompter prompt.Prompter,
+		) Provider {
+			provider = &ephemeralTestProvider{
+				Provider: test.NewTestProvider(envManager, env, console, prompter),
+				env:      env,
+			}
+			return provider
+		})
+
+		envManager := &mockenv.MockEnvManager{}
+		envManager.On("Save", *mockContext.Context, savedEnv).Return(nil)
+
+		mgr := NewManager(
+			mockContext.Container,
+			defaultProvider,
+			envManager,
+			savedEnv,
+			mockContext.Console,
+			mockContext.AlphaFeaturesManager,
+		)
+		err := mgr.Initialize(*mockContext.Context, "", Options{Provider: "test"})
+		require.NoError(t, err)
+
+		_, err = mgr.Destroy(*mockContext.Context, NewDestroyOptions(true, true))
+		require.NoError(t, err)
+		require.Equal(t, resourceGroup, provider.destroyResourceGroup)
+
+		// The generated resource group is removed once its resources are deleted
+		require.Empty(t, savedEnv.Getenv(environment.ResourceGroupEnvVarName))
+		_, has := savedEnv.Config.GetString(EphemeralResourceGroupPath)
+		require.False(t, has)
+	})
+}
+
+// newEphemeralTestProject creates a project with the parameters file of its template.
+func newEphemeralTestProject(t *testing.T, parameters string) string {
+	projectPath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(projectPath, "infra"), osutil.PermissionDirectory))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(projectPath, "infra", "main.parameters.json"), []byte(parameters), osutil.PermissionFile))
+
+	return projectPath
+}
+
+func TestManagerInitializeEphemeralRequiresResourceGroupParameter(t *testing.T) {
+	env := environment.NewWithValues("pr-42", map[string]string{
+		"AZURE_SUBSCRIPTION_ID": "SUBSCRIPTION_ID",
+		"AZURE_LOCATION":        "eastus2",
+	})
+
+	mockContext := mocks.NewMockContext(context.Background())
+	registerContainerDependencies(mockContext, env)
+
+	mgr := NewManager(
+		mockContext.Container,
+		defaultProvider,
+		&mockenv.MockEnvManager{},
+		env,
+		mockContext.Console,
+		mockContext.AlphaFeaturesManager,
+	)
+
+	// The template would deploy to a resource group shared with other environments
+	projectPath := newEphemeralTestProject(t, `{"parameters": {"environmentName": {"value": "${AZURE_ENV_NAME}"}}}`)
+	err := mgr.Initialize(*mockContext.Context, projectPath, Options{Provider: "test", Ephemeral: true})
+
+	var suggestionErr *azcli.ErrorWithSuggestion
+	require.ErrorAs(t, err, &suggestionErr)
+	require.Contains(t, suggestionErr.Suggestion, "main.parameters.json")
+	require.Empty(t, env.Getenv(environment.ResourceGroupEnvVarName))
+}
+
+func TestManagerInitializeEphemeralKeepsResourceGroup(t *testing.T) {
+	env := environment.NewWithValues("pr-42", map[string]string{
+		"AZURE_SUBSCRIPTION_ID": "SUBSCRIPTION_ID",
+		"AZURE_LOCATION":        "eastus2",
+		"AZURE_RESOURCE_GROUP":  "rg-previews",
+	})
+
+	mockContext := mocks.NewMockContext(context.Background())
+	registerContainerDependencies(mockContext, env)
+
+	mgr := NewManager(
+		mockContext.Container,
+		defaultProvider,
+		&mockenv.MockEnvManager{},
+		env,
+		mockContext.Console,
+		mockContext.AlphaFeaturesManager,
+	)
+	err := mgr.Initialize(*mockContext.Context, "", Options{Provider: "test", Ephemeral: true})
+	require.NoError(t, err)
+	require.Equal(t, "rg-previews", env.Getenv(environment.ResourceGroupEnvVarName))
+}
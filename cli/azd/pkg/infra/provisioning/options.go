@@ -34,6 +34,8 @@ type DestroyOptions struct {
 	force bool
 	// Whether or not to purge any key vaults associated with the deployment
 	purge bool
+	// Whether or not to delete the resources tagged with the environment outside of the deployment's resource groups
+	taggedResources bool
 }
 
 type StateOptions struct {
@@ -59,6 +61,17 @@ func (o *DestroyOptions) Force() bool {
 	return o.force
 }
 
+func (o *DestroyOptions) TaggedResources() bool {
+	return o.taggedResources
+}
+
+// WithTaggedResources returns a copy of the options that also deletes the resources tagged with the name of the
+// environment outside of the resource groups of the deployment.
+func (o DestroyOptions) WithTaggedResources(taggedResources bool) DestroyOptions {
+	o.taggedResources = taggedResources
+	return o
+}
+
 func NewDestroyOptions(force bool, purge bool) DestroyOptions {
 	return DestroyOptions{
 		force: force,
@@ -5,6 +5,7 @@ package provisioning
 
 import (
 	"context"
+	"time"
 )
 
 type ProviderKind string
@@ -22,8 +23,23 @@ type Options struct {
 	Provider ProviderKind `yaml:"provider,omitempty"`
 	Path     string       `yaml:"path,omitempty"`
 	Module   string       `yaml:"module,omitempty"`
+	// Additional tags to apply to the provisioned resource groups. Values support environment variable substitution.
+	Tags map[string]string `yaml:"tags,omitempty"`
 	// Not expected to be defined at azure.yaml
 	IgnoreDeploymentState bool `yaml:"-"`
+	// Compile the templates even when a compiled template is cached. Not expected to be defined at azure.yaml
+	IgnoreCompileCache bool `yaml:"-"`
+	// Values overriding the parameters of the template, from --parameter flags. Values support environment variable
+	// substitution. Not expected to be defined at azure.yaml
+	Parameters map[string]string `yaml:"-"`
+	// The template the project was created from, used for tagging. Not expected to be defined at azure.yaml
+	TemplateId string `yaml:"-"`
+	// Provisions a throwaway environment into a uniquely named resource group, saving only the values needed to
+	// deprovision it. Not expected to be defined at azure.yaml
+	Ephemeral bool `yaml:"-"`
+	// The time after which the provisioned resource groups can be deleted, tagged as azd-expires-on. Not expected to be
+	// defined at azure.yaml
+	Ttl time.Duration `yaml:"-"`
 }
 
 type SkippedReasonType string
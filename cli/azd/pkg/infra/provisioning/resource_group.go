@@ -0,0 +1,114 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+)
+
+// Environment config paths used to remember the resource group a previous provision deployed into.
+const (
+	ProvisionedResourceGroupPath = "provision.resourceGroup"
+	ProvisionedEnvNamePath       = "provision.envName"
+)
+
+// ensureResourceGroupReuse checks whether the environment name changed since the last provision. Templates commonly
+// derive the resource group name from the environment name, so the next provision would create a new group and leave
+// the previous one orphaned. The user is offered to keep deploying into the previous group instead. When they decide
+// to create a new group, the previous group is returned so it can be reported once the provision completes.
+func (m *Manager) ensureResourceGroupReuse(ctx context.Context) (string, error) {
+	previousGroup, previousEnvName := m.provisionedResourceGroup()
+	if previousGroup == "" || previousEnvName == "" {
+		return "", nil
+	}
+
+	envName := m.env.Getenv(environment.EnvNameEnvVarName)
+	if envName == previousEnvName {
+		return "", nil
+	}
+
+	// The user already pointed the environment at a different group, nothing would be orphaned by us.
+	if rg := m.env.Getenv(environment.ResourceGroupEnvVarName); rg != "" && rg != previousGroup {
+		return previousGroup, nil
+	}
+
+	m.console.Message(ctx, output.WithWarningFormat(
+		"WARNING: The environment name changed from '%s' to '%s' since the last provision into resource group '%s'.\n"+
+			"Provisioning may create a new resource group and leave '%s' orphaned.",
+		previousEnvName, envName, previousGroup, previousGroup,
+	))
+
+	reuseOption := fmt.Sprintf("Reuse the existing resource group '%s'", previousGroup)
+	choice, err := m.console.Select(ctx, input.ConsoleOptions{
+		Message:      "How do you want to continue?",
+		Options:      []string{reuseOption, "Create a new resource group"},
+		DefaultValue: reuseOption,
+	})
+	if err != nil {
+		return "", fmt.Errorf("prompting for resource group reuse: %w", err)
+	}
+
+	reuse := choice == 0
+	if reuse {
+		m.env.DotenvSet(environment.ResourceGroupEnvVarName, previousGroup)
+	} else {
+		// Let the template derive the name of the new group instead of passing the previous one.
+		m.env.DotenvDelete(environment.ResourceGroupEnvVarName)
+	}
+
+	if err := m.envManager.Save(ctx, m.env); err != nil {
+		return "", fmt.Errorf("saving environment: %w", err)
+	}
+
+	if reuse {
+		return "", nil
+	}
+
+	return previousGroup, nil
+}
+
+// recordResourceGroup stores the resource group the environment was provisioned into in the environment state and
+// reports the previous group when it is no longer in use.
+func (m *Manager) recordResourceGroup(ctx context.Context, previousGroup string) error {
+	resourceGroup := m.env.Getenv(environment.ResourceGroupEnvVarName)
+	if resourceGroup == "" {
+		return nil
+	}
+
+	if previousGroup != "" && previousGroup != resourceGroup {
+		m.console.Message(ctx, output.WithWarningFormat(
+			"WARNING: Resource group '%s' from a previous provision is no longer used by this environment.\n"+
+				"If it is not needed anymore, delete it with: %s",
+			previousGroup,
+			output.WithHighLightFormat("az group delete --name %s", previousGroup),
+		))
+	}
+
+	recordedGroup, recordedEnvName := m.provisionedResourceGroup()
+	envName := m.env.Getenv(environment.EnvNameEnvVarName)
+	if recordedGroup == resourceGroup && recordedEnvName == envName {
+		return nil
+	}
+
+	if err := m.env.Config.Set(ProvisionedResourceGroupPath, resourceGroup); err != nil {
+		return err
+	}
+
+	if err := m.env.Config.Set(ProvisionedEnvNamePath, envName); err != nil {
+		return err
+	}
+
+	return m.envManager.Save(ctx, m.env)
+}
+
+func (m *Manager) provisionedResourceGroup() (resourceGroup string, envName string) {
+	resourceGroup, _ = m.env.Config.GetString(ProvisionedResourceGroupPath)
+	envName, _ = m.env.Config.GetString(ProvisionedEnvNamePath)
+	return resourceGroup, envName
+}
@@ -0,0 +1,53 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/drone/envsubst"
+)
+
+// ResourceGroupTags returns the tags applied to the resource groups of a provision. The azd-env-name,
+// azd-template-id and azd-provision-time tags are always set; the tags from the project infra configuration are
+// added to them after expanding any environment variable references. Tags set by azd can't be overridden, since
+// commands like `azd down` rely on them.
+func ResourceGroupTags(env *environment.Environment, options Options, provisionTime time.Time) (map[string]*string, error) {
+	tags := map[string]*string{}
+
+	for key, value := range options.Tags {
+		expanded, err := envsubst.Eval(value, env.Getenv)
+		if err != nil {
+			return nil, fmt.Errorf("expanding value of tag '%s': %w", key, err)
+		}
+
+		tags[key] = to.Ptr(expanded)
+	}
+
+	azdTags := map[string]string{
+		azure.TagKeyAzdEnvName:       env.Name(),
+		azure.TagKeyAzdProvisionTime: provisionTime.UTC().Format(time.RFC3339),
+	}
+	if options.TemplateId != "" {
+		azdTags[azure.TagKeyAzdTemplateId] = options.TemplateId
+	}
+	if options.Ttl > 0 {
+		azdTags[azure.TagKeyAzdExpiresOn] = provisionTime.Add(options.Ttl).UTC().Format(time.RFC3339)
+	}
+
+	for key, value := range azdTags {
+		if _, has := tags[key]; has {
+			log.Printf("ignoring tag '%s' from project configuration, it is set by azd", key)
+		}
+
+		tags[key] = to.Ptr(value)
+	}
+
+	return tags, nil
+}
@@ -443,8 +443,9 @@ func (t *TerraformProvider) convertOutputs(outputMap map[string]terraformOutput)
 		}
 
 		outputParameters[k] = OutputParameter{
-			Type:  t.mapTerraformTypeToInterfaceType(v.Type),
-			Value: v.Value,
+			Type:   t.mapTerraformTypeToInterfaceType(v.Type),
+			Value:  v.Value,
+			Secure: v.Sensitive,
 		}
 	}
 	return outputParameters
@@ -7,7 +7,6 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -15,18 +14,15 @@ import (
 	"os/signal"
 	"path/filepath"
 	"runtime"
-	"slices"
 	"strconv"
 	"sync"
 	"syscall"
 	"time"
 
 	"github.com/AlecAivazis/survey/v2"
-	"github.com/AlecAivazis/survey/v2/terminal"
 	"github.com/azure/azure-dev/cli/azd/internal/tracing"
 	"github.com/azure/azure-dev/cli/azd/internal/tracing/resource"
 	"github.com/azure/azure-dev/cli/azd/pkg/alpha"
-	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
@@ -145,8 +141,10 @@ type AskerConsole struct {
 	formatter  output.Formatter
 	isTerminal bool
 	noPrompt   bool
-	// when non nil, use this client instead of prompting ourselves on the console.
+	// when non nil, use this client for prompt dialogs.
 	promptClient *externalPromptClient
+	// prompter renders the prompts, on the terminal unless another backend is provided.
+	prompter Prompter
 
 	showProgressMu sync.Mutex // ensures atomicity when swapping the current progress renderer (spinner or previewer)
 
@@ -565,301 +563,35 @@ func (c *AskerConsole) PromptDialog(ctx context.Context, dialog PromptDialog) (m
 
 // Prompts the user for a single value
 func (c *AskerConsole) Prompt(ctx context.Context, options ConsoleOptions) (string, error) {
-	var response string
-
-	if c.promptClient != nil {
-		opts := promptOptions{
-			Type: "string",
-			Options: promptOptionsOptions{
-				Message: options.Message,
-				Help:    options.Help,
-			},
-		}
-
-		if options.IsPassword {
-			opts.Type = "password"
-		}
-
-		if value, ok := options.DefaultValue.(string); ok {
-			opts.Options.DefaultValue = convert.RefOf[any](value)
-		}
-
-		result, err := c.promptClient.Prompt(ctx, opts)
-		if errors.Is(err, promptCancelledErr) {
-			return "", terminal.InterruptErr
-		} else if err != nil {
-			return "", err
-		}
-
-		if err := json.Unmarshal(result, &response); err != nil {
-			return "", fmt.Errorf("unmarshalling response: %w", err)
-		}
-
-		return response, nil
+	if options.IsPassword {
+		return c.prompter.Password(ctx, options)
 	}
 
-	err := c.doInteraction(func(c *AskerConsole) error {
-		return c.asker(promptFromOptions(options), &response)
-	})
-	if err != nil {
-		return response, err
-	}
-	c.updateLastBytes(cAfterIO)
-	return response, nil
+	return c.prompter.Input(ctx, options)
 }
 
-// Prompts the user for a single value
+// Prompts the user for a directory path. Prompters which can't suggest directories ask for an input.
 func (c *AskerConsole) PromptDir(ctx context.Context, options ConsoleOptions) (string, error) {
-	var response string
-
-	if c.promptClient != nil {
-		opts := promptOptions{
-			Type: "directory",
-			Options: promptOptionsOptions{
-				Message: options.Message,
-				Help:    options.Help,
-			},
-		}
-
-		if value, ok := options.DefaultValue.(string); ok {
-			opts.Options.DefaultValue = convert.RefOf[any](value)
-		}
-
-		result, err := c.promptClient.Prompt(ctx, opts)
-		if errors.Is(err, promptCancelledErr) {
-			return "", terminal.InterruptErr
-		} else if err != nil {
-			return "", err
-		}
-
-		if err := json.Unmarshal(result, &response); err != nil {
-			return "", fmt.Errorf("unmarshalling response: %w", err)
-		}
-
-		return response, nil
+	if prompter, ok := c.prompter.(dirPrompter); ok {
+		return prompter.Dir(ctx, options)
 	}
 
-	err := c.doInteraction(func(c *AskerConsole) error {
-		prompt := &survey.Input{
-			Message: options.Message,
-			Help:    options.Help,
-			Suggest: dirSuggestions,
-		}
-
-		return c.asker(prompt, &response)
-	})
-	if err != nil {
-		return response, err
-	}
-	c.updateLastBytes(cAfterIO)
-	return response, nil
-}
-
-func choicesFromOptions(options ConsoleOptions) []promptChoice {
-	choices := make([]promptChoice, len(options.Options))
-	for i, option := range options.Options {
-		choices[i] = promptChoice{
-			Value: option,
-		}
-
-		if i < len(options.OptionDetails) && options.OptionDetails[i] != "" {
-			choices[i].Detail = &options.OptionDetails[i]
-		}
-	}
-	return choices
-
+	return c.prompter.Input(ctx, options)
 }
 
 // Prompts the user to select from a set of values
 func (c *AskerConsole) Select(ctx context.Context, options ConsoleOptions) (int, error) {
-	if c.promptClient != nil {
-		opts := promptOptions{
-			Type: "select",
-			Options: promptOptionsOptions{
-				Message: options.Message,
-				Help:    options.Help,
-				Choices: convert.RefOf(choicesFromOptions(options)),
-			},
-		}
-
-		if value, ok := options.DefaultValue.(string); ok {
-			opts.Options.DefaultValue = convert.RefOf[any](value)
-		}
-
-		result, err := c.promptClient.Prompt(ctx, opts)
-		if errors.Is(err, promptCancelledErr) {
-			return -1, terminal.InterruptErr
-		} else if err != nil {
-			return -1, err
-		}
-
-		var choice string
-
-		if err := json.Unmarshal(result, &choice); err != nil {
-			return -1, fmt.Errorf("unmarshalling response: %w", err)
-		}
-
-		res := slices.Index(options.Options, choice)
-		if res == -1 {
-			return -1, fmt.Errorf("invalid choice: %s", choice)
-		}
-
-		return res, nil
-	}
-
-	surveyOptions := make([]string, len(options.Options))
-	for i, option := range options.Options {
-		surveyOptions[i] = option
-
-		if c.IsSpinnerInteractive() && i < len(options.OptionDetails) {
-			if options.OptionDetails[i] != "" {
-				detailString := output.WithGrayFormat("(%s)", options.OptionDetails[i])
-				surveyOptions[i] += fmt.Sprintf("\n  %s\n", detailString)
-			} else {
-				surveyOptions[i] += "\n"
-			}
-		}
-	}
-
-	survey := &survey.Select{
-		Message: options.Message,
-		Options: surveyOptions,
-		Default: options.DefaultValue,
-		Help:    options.Help,
-	}
-
-	var response int
-
-	err := c.doInteraction(func(c *AskerConsole) error {
-		return c.asker(survey, &response)
-	})
-	if err != nil {
-		return -1, err
-	}
-
-	c.updateLastBytes(cAfterIO)
-	return response, nil
+	return c.prompter.Select(ctx, options)
 }
 
+// Prompts the user to select zero or more values from a set of values
 func (c *AskerConsole) MultiSelect(ctx context.Context, options ConsoleOptions) ([]string, error) {
-	var response []string
-
-	if c.promptClient != nil {
-		opts := promptOptions{
-			Type: "multiSelect",
-			Options: promptOptionsOptions{
-				Message: options.Message,
-				Help:    options.Help,
-				Choices: convert.RefOf(choicesFromOptions(options)),
-			},
-		}
-
-		if value, ok := options.DefaultValue.([]string); ok {
-			opts.Options.DefaultValue = convert.RefOf[any](value)
-		}
-
-		result, err := c.promptClient.Prompt(ctx, opts)
-		if errors.Is(err, promptCancelledErr) {
-			return nil, terminal.InterruptErr
-		} else if err != nil {
-			return nil, err
-		}
-
-		if err := json.Unmarshal(result, &response); err != nil {
-			return nil, fmt.Errorf("unmarshalling response: %w", err)
-		}
-
-		return response, nil
-	}
-
-	surveyOptions := make([]string, len(options.Options))
-	for i, option := range options.Options {
-		surveyOptions[i] = option
-
-		if c.IsSpinnerInteractive() && i < len(options.OptionDetails) {
-			detailString := output.WithGrayFormat("%s", options.OptionDetails[i])
-			surveyOptions[i] += fmt.Sprintf("\n  %s\n", detailString)
-		}
-	}
-
-	survey := &survey.MultiSelect{
-		Message: options.Message,
-		Options: surveyOptions,
-		Default: options.DefaultValue,
-		Help:    options.Help,
-	}
-
-	err := c.doInteraction(func(c *AskerConsole) error {
-		return c.asker(survey, &response)
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	return response, nil
+	return c.prompter.MultiSelect(ctx, options)
 }
 
 // Prompts the user to confirm an operation
 func (c *AskerConsole) Confirm(ctx context.Context, options ConsoleOptions) (bool, error) {
-	if c.promptClient != nil {
-		opts := promptOptions{
-			Type: "confirm",
-			Options: promptOptionsOptions{
-				Message: options.Message,
-				Help:    options.Help,
-			},
-		}
-
-		if value, ok := options.DefaultValue.(bool); ok {
-			opts.Options.DefaultValue = convert.RefOf[any](value)
-		}
-
-		result, err := c.promptClient.Prompt(ctx, opts)
-		if errors.Is(err, promptCancelledErr) {
-			return false, terminal.InterruptErr
-		} else if err != nil {
-			return false, err
-		}
-
-		var response string
-
-		if err := json.Unmarshal(result, &response); err != nil {
-			return false, fmt.Errorf("unmarshalling response: %w", err)
-		}
-
-		switch response {
-		case "true":
-			return true, nil
-		case "false":
-			return false, nil
-		default:
-			return false, fmt.Errorf("invalid response: %s", response)
-
-		}
-	}
-
-	var defaultValue bool
-	if value, ok := options.DefaultValue.(bool); ok {
-		defaultValue = value
-	}
-
-	survey := &survey.Confirm{
-		Message: options.Message,
-		Help:    options.Help,
-		Default: defaultValue,
-	}
-
-	var response bool
-
-	err := c.doInteraction(func(c *AskerConsole) error {
-		return c.asker(survey, &response)
-	})
-	if err != nil {
-		return false, err
-	}
-
-	c.updateLastBytes(cAfterIO)
-	return response, nil
+	return c.prompter.Confirm(ctx, options)
 }
 
 const c_newLine = '\n'
@@ -983,6 +715,25 @@ func NewConsole(
 	handles ConsoleHandles,
 	formatter output.Formatter,
 	externalPromptCfg *ExternalPromptConfiguration) Console {
+	var prompter Prompter
+	if externalPromptCfg != nil {
+		prompter = &externalPrompter{
+			client: newExternalPromptClient(externalPromptCfg.Endpoint, externalPromptCfg.Key, externalPromptCfg.Client),
+		}
+	}
+
+	return NewConsoleWithPrompter(noPrompt, isTerminal, writers, handles, formatter, prompter)
+}
+
+// Creates a new console with the specified writers, handles and formatter, rendering the prompts with prompter. When
+// prompter is nil, prompts are rendered on the console. The prompter decides how to handle prompts when noPrompt is set.
+func NewConsoleWithPrompter(
+	noPrompt bool,
+	isTerminal bool,
+	writers Writers,
+	handles ConsoleHandles,
+	formatter output.Formatter,
+	prompter Prompter) Console {
 	asker := NewAsker(noPrompt, isTerminal, handles.Stdout, handles.Stdin)
 
 	c := &AskerConsole{
@@ -1000,8 +751,14 @@ func NewConsole(
 		writers.Spinner = writers.Output
 	}
 
-	if externalPromptCfg != nil {
-		c.promptClient = newExternalPromptClient(externalPromptCfg.Endpoint, externalPromptCfg.Key, externalPromptCfg.Client)
+	switch prompter := prompter.(type) {
+	case nil:
+		c.prompter = &terminalPrompter{console: c}
+	case *externalPrompter:
+		c.prompter = prompter
+		c.promptClient = prompter.client
+	default:
+		c.prompter = prompter
 	}
 
 	spinnerConfig := yacspin.Config{
@@ -0,0 +1,157 @@
+package input
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+)
+
+// externalPrompter delegates the prompts to the external prompting service.
+type externalPrompter struct {
+	client *externalPromptClient
+}
+
+// prompt sends the prompt to the service, and unmarshals the answer of the user to response.
+func (p *externalPrompter) prompt(ctx context.Context, opts promptOptions, response any) error {
+	result, err := p.client.Prompt(ctx, opts)
+	if errors.Is(err, promptCancelledErr) {
+		return terminal.InterruptErr
+	} else if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(result, response); err != nil {
+		return fmt.Errorf("unmarshalling response: %w", err)
+	}
+
+	return nil
+}
+
+func (p *externalPrompter) Input(ctx context.Context, options ConsoleOptions) (string, error) {
+	return p.promptString(ctx, "string", options)
+}
+
+func (p *externalPrompter) Password(ctx context.Context, options ConsoleOptions) (string, error) {
+	return p.promptString(ctx, "password", options)
+}
+
+func (p *externalPrompter) Dir(ctx context.Context, options ConsoleOptions) (string, error) {
+	return p.promptString(ctx, "directory", options)
+}
+
+func (p *externalPrompter) promptString(ctx context.Context, kind string, options ConsoleOptions) (string, error) {
+	opts := promptOptions{
+		Type: kind,
+		Options: promptOptionsOptions{
+			Message: options.Message,
+			Help:    options.Help,
+		},
+	}
+
+	if value, ok := options.DefaultValue.(string); ok {
+		opts.Options.DefaultValue = convert.RefOf[any](value)
+	}
+
+	var response string
+	if err := p.prompt(ctx, opts, &response); err != nil {
+		return "", err
+	}
+
+	return response, nil
+}
+
+func choicesFromOptions(options ConsoleOptions) []promptChoice {
+	choices := make([]promptChoice, len(options.Options))
+	for i, option := range options.Options {
+		choices[i] = promptChoice{
+			Value: option,
+		}
+
+		if i < len(options.OptionDetails) && options.OptionDetails[i] != "" {
+			choices[i].Detail = &options.OptionDetails[i]
+		}
+	}
+	return choices
+}
+
+func (p *externalPrompter) Select(ctx context.Context, options ConsoleOptions) (int, error) {
+	opts := promptOptions{
+		Type: "select",
+		Options: promptOptionsOptions{
+			Message: options.Message,
+			Help:    options.Help,
+			Choices: convert.RefOf(choicesFromOptions(options)),
+		},
+	}
+
+	if value, ok := options.DefaultValue.(string); ok {
+		opts.Options.DefaultValue = convert.RefOf[any](value)
+	}
+
+	var choice string
+	if err := p.prompt(ctx, opts, &choice); err != nil {
+		return -1, err
+	}
+
+	res := slices.Index(options.Options, choice)
+	if res == -1 {
+		return -1, fmt.Errorf("invalid choice: %s", choice)
+	}
+
+	return res, nil
+}
+
+func (p *externalPrompter) MultiSelect(ctx context.Context, options ConsoleOptions) ([]string, error) {
+	opts := promptOptions{
+		Type: "multiSelect",
+		Options: promptOptionsOptions{
+			Message: options.Message,
+			Help:    options.Help,
+			Choices: convert.RefOf(choicesFromOptions(options)),
+		},
+	}
+
+	if value, ok := options.DefaultValue.([]string); ok {
+		opts.Options.DefaultValue = convert.RefOf[any](value)
+	}
+
+	var response []string
+	if err := p.prompt(ctx, opts, &response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (p *externalPrompter) Confirm(ctx context.Context, options ConsoleOptions) (bool, error) {
+	opts := promptOptions{
+		Type: "confirm",
+		Options: promptOptionsOptions{
+			Message: options.Message,
+			Help:    options.Help,
+		},
+	}
+
+	if value, ok := options.DefaultValue.(bool); ok {
+		opts.Options.DefaultValue = convert.RefOf[any](value)
+	}
+
+	var response string
+	if err := p.prompt(ctx, opts, &response); err != nil {
+		return false, err
+	}
+
+	switch response {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid response: %s", response)
+	}
+}
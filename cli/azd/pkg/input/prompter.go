@@ -0,0 +1,169 @@
+package input
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+)
+
+// Prompter renders the prompts of the console and returns the answers of the user. The console prompts on the terminal
+// by default, or with the external prompting service when configured. Embedders, like IDE extensions, can render the
+// prompts in their own UI by creating the console with their Prompter, see NewConsoleWithPrompter.
+//
+// Prompters return terminal.InterruptErr when the user cancels a prompt.
+type Prompter interface {
+	// Confirm asks the user to confirm an operation.
+	Confirm(ctx context.Context, options ConsoleOptions) (bool, error)
+	// Select asks the user to select one of the options, and returns its index.
+	Select(ctx context.Context, options ConsoleOptions) (int, error)
+	// MultiSelect asks the user to select zero or more of the options, and returns the selected options.
+	MultiSelect(ctx context.Context, options ConsoleOptions) ([]string, error)
+	// Input asks the user for a single value.
+	Input(ctx context.Context, options ConsoleOptions) (string, error)
+	// Password asks the user for a secret value, which must not be displayed.
+	Password(ctx context.Context, options ConsoleOptions) (string, error)
+}
+
+// dirPrompter is implemented by the prompters which ask for directories, suggesting the existing ones.
+type dirPrompter interface {
+	Dir(ctx context.Context, options ConsoleOptions) (string, error)
+}
+
+// terminalPrompter prompts on the terminal of the console.
+type terminalPrompter struct {
+	console *AskerConsole
+}
+
+func (p *terminalPrompter) Input(ctx context.Context, options ConsoleOptions) (string, error) {
+	options.IsPassword = false
+	return p.prompt(options)
+}
+
+func (p *terminalPrompter) Password(ctx context.Context, options ConsoleOptions) (string, error) {
+	options.IsPassword = true
+	return p.prompt(options)
+}
+
+func (p *terminalPrompter) prompt(options ConsoleOptions) (string, error) {
+	var response string
+
+	err := p.console.doInteraction(func(c *AskerConsole) error {
+		return c.asker(promptFromOptions(options), &response)
+	})
+	if err != nil {
+		return response, err
+	}
+	p.console.updateLastBytes(cAfterIO)
+	return response, nil
+}
+
+func (p *terminalPrompter) Dir(ctx context.Context, options ConsoleOptions) (string, error) {
+	var response string
+
+	err := p.console.doInteraction(func(c *AskerConsole) error {
+		prompt := &survey.Input{
+			Message: options.Message,
+			Help:    options.Help,
+			Suggest: dirSuggestions,
+		}
+
+		return c.asker(prompt, &response)
+	})
+	if err != nil {
+		return response, err
+	}
+	p.console.updateLastBytes(cAfterIO)
+	return response, nil
+}
+
+func (p *terminalPrompter) Select(ctx context.Context, options ConsoleOptions) (int, error) {
+	surveyOptions := make([]string, len(options.Options))
+	for i, option := range options.Options {
+		surveyOptions[i] = option
+
+		if p.console.IsSpinnerInteractive() && i < len(options.OptionDetails) {
+			if options.OptionDetails[i] != "" {
+				detailString := output.WithGrayFormat("(%s)", options.OptionDetails[i])
+				surveyOptions[i] += fmt.Sprintf("\n  %s\n", detailString)
+			} else {
+				surveyOptions[i] += "\n"
+			}
+		}
+	}
+
+	survey := &survey.Select{
+		Message: options.Message,
+		Options: surveyOptions,
+		Default: options.DefaultValue,
+		Help:    options.Help,
+	}
+
+	var response int
+
+	err := p.console.doInteraction(func(c *AskerConsole) error {
+		return c.asker(survey, &response)
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	p.console.updateLastBytes(cAfterIO)
+	return response, nil
+}
+
+func (p *terminalPrompter) MultiSelect(ctx context.Context, options ConsoleOptions) ([]string, error) {
+	var response []string
+
+	surveyOptions := make([]string, len(options.Options))
+	for i, option := range options.Options {
+		surveyOptions[i] = option
+
+		if p.console.IsSpinnerInteractive() && i < len(options.OptionDetails) {
+			detailString := output.WithGrayFormat("%s", options.OptionDetails[i])
+			surveyOptions[i] += fmt.Sprintf("\n  %s\n", detailString)
+		}
+	}
+
+	survey := &survey.MultiSelect{
+		Message: options.Message,
+		Options: surveyOptions,
+		Default: options.DefaultValue,
+		Help:    options.Help,
+	}
+
+	err := p.console.doInteraction(func(c *AskerConsole) error {
+		return c.asker(survey, &response)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (p *terminalPrompter) Confirm(ctx context.Context, options ConsoleOptions) (bool, error) {
+	var defaultValue bool
+	if value, ok := options.DefaultValue.(bool); ok {
+		defaultValue = value
+	}
+
+	survey := &survey.Confirm{
+		Message: options.Message,
+		Help:    options.Help,
+		Default: defaultValue,
+	}
+
+	var response bool
+
+	err := p.console.doInteraction(func(c *AskerConsole) error {
+		return c.asker(survey, &response)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	p.console.updateLastBytes(cAfterIO)
+	return response, nil
+}
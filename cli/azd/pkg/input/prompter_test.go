@@ -0,0 +1,79 @@
+package input
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordingPrompter records the kinds of the prompts it is asked for.
+type recordingPrompter struct {
+	kinds []string
+}
+
+func (p *recordingPrompter) Confirm(ctx context.Context, options ConsoleOptions) (bool, error) {
+	p.kinds = append(p.kinds, "confirm")
+	return true, nil
+}
+
+func (p *recordingPrompter) Select(ctx context.Context, options ConsoleOptions) (int, error) {
+	p.kinds = append(p.kinds, "select")
+	return len(options.Options) - 1, nil
+}
+
+func (p *recordingPrompter) MultiSelect(ctx context.Context, options ConsoleOptions) ([]string, error) {
+	p.kinds = append(p.kinds, "multiSelect")
+	return options.Options, nil
+}
+
+func (p *recordingPrompter) Input(ctx context.Context, options ConsoleOptions) (string, error) {
+	p.kinds = append(p.kinds, "input")
+	return "value", nil
+}
+
+func (p *recordingPrompter) Password(ctx context.Context, options ConsoleOptions) (string, error) {
+	p.kinds = append(p.kinds, "password")
+	return "secret", nil
+}
+
+func TestAskerConsole_Prompter(t *testing.T) {
+	ctx := context.Background()
+	prompter := &recordingPrompter{}
+	c := NewConsoleWithPrompter(
+		false,
+		false,
+		Writers{Output: io.Discard},
+		ConsoleHandles{Stdout: io.Discard, Stderr: io.Discard},
+		nil,
+		prompter)
+
+	confirmed, err := c.Confirm(ctx, ConsoleOptions{Message: "Continue?"})
+	require.NoError(t, err)
+	require.True(t, confirmed)
+
+	selected, err := c.Select(ctx, ConsoleOptions{Message: "Pick one", Options: []string{"a", "b"}})
+	require.NoError(t, err)
+	require.Equal(t, 1, selected)
+
+	multiSelected, err := c.MultiSelect(ctx, ConsoleOptions{Message: "Pick some", Options: []string{"a", "b"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, multiSelected)
+
+	value, err := c.Prompt(ctx, ConsoleOptions{Message: "Name"})
+	require.NoError(t, err)
+	require.Equal(t, "value", value)
+
+	secret, err := c.Prompt(ctx, ConsoleOptions{Message: "Password", IsPassword: true})
+	require.NoError(t, err)
+	require.Equal(t, "secret", secret)
+
+	// Prompters which can't suggest directories are asked for an input
+	dir, err := c.PromptDir(ctx, ConsoleOptions{Message: "Directory"})
+	require.NoError(t, err)
+	require.Equal(t, "value", dir)
+
+	require.Equal(t, []string{"confirm", "select", "multiSelect", "input", "password", "input"}, prompter.kinds)
+	require.False(t, c.SupportsPromptDialog())
+}
@@ -0,0 +1,122 @@
+// Package logging provides leveled, structured logging for azd, configured with the --log-level flag.
+//
+// Messages logged with the standard log package, which most of azd uses for diagnostics, are debug messages: they are
+// only written at the debug and trace levels.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+const (
+	// LevelTrace is the most verbose level, including the environment and output of the commands run by azd, and the
+	// requests of the Azure SDK.
+	LevelTrace = slog.Level(-8)
+	// LevelDebug includes the diagnostics logged by azd, like the commands it runs.
+	LevelDebug = slog.LevelDebug
+	LevelInfo  = slog.LevelInfo
+	LevelWarn  = slog.LevelWarn
+	LevelError = slog.LevelError
+	// LevelNone disables logging, which is the default.
+	LevelNone = slog.Level(16)
+)
+
+// LevelNames are the names of the levels accepted by --log-level, from the least to the most verbose.
+var LevelNames = []string{"error", "warn", "info", "debug", "trace"}
+
+var levelsByName = map[string]slog.Level{
+	"error": LevelError,
+	"warn":  LevelWarn,
+	"info":  LevelInfo,
+	"debug": LevelDebug,
+	"trace": LevelTrace,
+}
+
+var (
+	mu     sync.RWMutex
+	level  = LevelNone
+	logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+)
+
+// ParseLevel parses the name of a level, like 'warn'.
+func ParseLevel(name string) (slog.Level, error) {
+	if level, has := levelsByName[strings.ToLower(name)]; has {
+		return level, nil
+	}
+
+	return LevelNone, fmt.Errorf(
+		"invalid log level '%s', supported levels are: %s", name, strings.Join(LevelNames, ", "))
+}
+
+// Configure writes the messages of the level, and the more severe levels, to w. The messages of the standard log package
+// are written to w when the level is debug or trace, and discarded otherwise.
+func Configure(l slog.Level, w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	level = l
+	logger = slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{
+		Level: l,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			// slog names the trace level DEBUG-4
+			if attr.Key == slog.LevelKey && attr.Value.Any() == LevelTrace {
+				attr.Value = slog.StringValue("TRACE")
+			}
+
+			return attr
+		},
+	}))
+
+	if l <= LevelDebug {
+		log.SetOutput(w)
+	} else {
+		log.SetOutput(io.Discard)
+	}
+}
+
+// Enabled returns whether messages of the level are logged.
+func Enabled(l slog.Level) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return l >= level
+}
+
+func logAt(l slog.Level, msg string, args ...any) {
+	mu.RLock()
+	current := logger
+	mu.RUnlock()
+
+	current.Log(context.Background(), l, msg, args...)
+}
+
+// Error logs an error message, with the key value pairs of args as attributes.
+func Error(msg string, args ...any) {
+	logAt(LevelError, msg, args...)
+}
+
+// Warn logs a warning message, with the key value pairs of args as attributes.
+func Warn(msg string, args ...any) {
+	logAt(LevelWarn, msg, args...)
+}
+
+// Info logs an informational message, with the key value pairs of args as attributes.
+func Info(msg string, args ...any) {
+	logAt(LevelInfo, msg, args...)
+}
+
+// Debug logs a debug message, with the key value pairs of args as attributes.
+func Debug(msg string, args ...any) {
+	logAt(LevelDebug, msg, args...)
+}
+
+// Trace logs a trace message, with the key value pairs of args as attributes.
+func Trace(msg string, args ...any) {
+	logAt(LevelTrace, msg, args...)
+}
@@ -0,0 +1,109 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"os"
+	osexec "os/exec"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/stretchr/testify/require"
+)
+
+func configureForTest(t *testing.T, name string) *bytes.Buffer {
+	level, err := ParseLevel(name)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	Configure(level, buf)
+	t.Cleanup(func() {
+		Configure(LevelNone, io.Discard)
+		log.SetOutput(os.Stderr)
+	})
+
+	return buf
+}
+
+func Test_ParseLevel(t *testing.T) {
+	level, err := ParseLevel("WARN")
+	require.NoError(t, err)
+	require.Equal(t, LevelWarn, level)
+
+	_, err = ParseLevel("verbose")
+	require.EqualError(t, err, "invalid log level 'verbose', supported levels are: error, warn, info, debug, trace")
+}
+
+func Test_Levels(t *testing.T) {
+	t.Run("Warn", func(t *testing.T) {
+		buf := configureForTest(t, "warn")
+
+		Error("deployment failed", "service", "api")
+		Warn("quota almost reached", "usage", 95)
+		Info("provisioning resources")
+		Debug("loaded project")
+		log.Printf("unleveled diagnostics")
+
+		output := buf.String()
+		require.Contains(t, output, `level=ERROR msg="deployment failed" service=api`)
+		require.Contains(t, output, `level=WARN msg="quota almost reached" usage=95`)
+		require.NotContains(t, output, "provisioning resources")
+		require.NotContains(t, output, "loaded project")
+		require.NotContains(t, output, "unleveled diagnostics")
+
+		require.True(t, Enabled(LevelWarn))
+		require.False(t, Enabled(LevelInfo))
+	})
+
+	t.Run("Debug", func(t *testing.T) {
+		buf := configureForTest(t, "debug")
+
+		Debug("loaded project")
+		Trace("request sent")
+		log.Printf("unleveled diagnostics")
+
+		output := buf.String()
+		require.Contains(t, output, `level=DEBUG msg="loaded project"`)
+		require.NotContains(t, output, "request sent")
+		// Messages of the standard log package are debug messages
+		require.Contains(t, output, "unleveled diagnostics")
+	})
+
+	t.Run("Trace", func(t *testing.T) {
+		buf := configureForTest(t, "trace")
+
+		Trace("request sent")
+		require.Contains(t, buf.String(), `level=TRACE msg="request sent"`)
+	})
+}
+
+func Test_TraceLogsCommands(t *testing.T) {
+	python, err := osexec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 is not installed")
+	}
+
+	run := func(t *testing.T, level string) string {
+		buf := configureForTest(t, level)
+
+		// The command runner of azd logs the output of the commands at the trace level
+		runner := exec.NewCommandRunner(&exec.RunnerOptions{DebugLogging: Enabled(LevelTrace)})
+		_, err := runner.Run(context.Background(), exec.NewRunArgs(python, "-c", "print('hello from python')"))
+		require.NoError(t, err)
+
+		return buf.String()
+	}
+
+	t.Run("Trace", func(t *testing.T) {
+		output := run(t, "trace")
+		require.Contains(t, output, "Run exec: '"+python+" -c print('hello from python')'")
+		require.Contains(t, output, "hello from python")
+	})
+
+	t.Run("Warn", func(t *testing.T) {
+		output := run(t, "warn")
+		require.NotContains(t, output, "Run exec")
+	})
+}
@@ -14,6 +14,7 @@ const (
 	EnvVarsFormat Format = "dotenv"
 	JsonFormat    Format = "json"
 	TableFormat   Format = "table"
+	TsvFormat     Format = "tsv"
 	NoneFormat    Format = "none"
 )
 
@@ -30,6 +31,8 @@ func NewFormatter(format string) (Formatter, error) {
 		return &EnvVarsFormatter{}, nil
 	case string(TableFormat):
 		return &TableFormatter{}, nil
+	case string(TsvFormat):
+		return &TsvFormatter{}, nil
 	case string(NoneFormat):
 		return &NoneFormatter{}, nil
 	default:
@@ -0,0 +1,93 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package output
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// tsvEscaper escapes the characters that can't appear in a field of tab-separated values, so each row stays on a
+// single line with one tab between fields.
+var tsvEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"\t", `\t`,
+	"\n", `\n`,
+	"\r", `\r`,
+)
+
+// EscapeTsvField escapes the backslashes, tabs and line breaks of a field of tab-separated values as `\\`, `\t`,
+// `\n` and `\r`.
+func EscapeTsvField(value string) string {
+	return tsvEscaper.Replace(value)
+}
+
+// TsvFormatter writes tab-separated values, with the same TableFormatterOptions as the table format: a row with the
+// headings of the columns, followed by a row for each value. Fields are escaped with EscapeTsvField.
+type TsvFormatter struct {
+}
+
+func (f *TsvFormatter) Kind() Format {
+	return TsvFormat
+}
+
+func (f *TsvFormatter) Format(obj interface{}, writer io.Writer, opts interface{}) error {
+	options, ok := opts.(TableFormatterOptions)
+	if !ok {
+		return errors.New("invalid formatter options, TableFormatterOptions expected")
+	}
+
+	if len(options.Columns) == 0 {
+		return errors.New("no columns were defined, tsv format is not supported for this command")
+	}
+
+	rows, err := convertToSlice(obj)
+	if err != nil {
+		return err
+	}
+
+	headings := make([]string, 0, len(options.Columns))
+	templates := make([]*template.Template, 0, len(options.Columns))
+	for _, c := range options.Columns {
+		headings = append(headings, EscapeTsvField(c.Heading))
+
+		t, err := template.New(c.Heading).Parse(c.ValueTemplate)
+		if err != nil {
+			return err
+		}
+		templates = append(templates, t)
+	}
+
+	if _, err := io.WriteString(writer, strings.Join(headings, "\t")+"\n"); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		fields := make([]string, 0, len(templates))
+		for i, t := range templates {
+			buf := bytes.Buffer{}
+			if err := t.Execute(&buf, row); err != nil {
+				return err
+			}
+
+			field := buf.String()
+			if xfm := options.Columns[i].Transformer; xfm != nil {
+				field = xfm(field)
+			}
+
+			fields = append(fields, EscapeTsvField(field))
+		}
+
+		if _, err := io.WriteString(writer, strings.Join(fields, "\t")+"\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var _ Formatter = (*TsvFormatter)(nil)
@@ -0,0 +1,40 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTsvFormat(t *testing.T) {
+	obj := []tableInput{
+		{Size: "Large\tWide", IsCool: true},
+		{Size: "Small\nShort", IsCool: false},
+	}
+
+	buf := &bytes.Buffer{}
+	formatter := &TsvFormatter{}
+	err := formatter.Format(obj, buf, tableInputOptions)
+	require.NoError(t, err)
+
+	require.Equal(t,
+		"Size\tCoolness\tStatic\tLowered\n"+
+			"Large\\tWide\ttrue\tSome-Value\tsome-value\n"+
+			"Small\\nShort\tfalse\tSome-Value\tsome-value\n",
+		buf.String())
+}
+
+func TestTsvFormatRequiresColumns(t *testing.T) {
+	formatter := &TsvFormatter{}
+	err := formatter.Format([]tableInput{}, &bytes.Buffer{}, TableFormatterOptions{})
+	require.ErrorContains(t, err, "no columns were defined")
+}
+
+func TestEscapeTsvField(t *testing.T) {
+	require.Equal(t, `C:\\path\tvalue\r\nnext`, EscapeTsvField("C:\\path\tvalue\r\nnext"))
+	require.Equal(t, `"quoted" 'value' $HOME`, EscapeTsvField(`"quoted" 'value' $HOME`))
+}
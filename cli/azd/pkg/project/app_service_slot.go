@@ -0,0 +1,195 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/sethvargo/go-retry"
+)
+
+// SlotOptions configures deploying an App Service or Function App to a deployment slot.
+type SlotOptions struct {
+	// The name of the deployment slot. The slot is created when it doesn't exist.
+	Name osutil.ExpandableString `yaml:"name,omitempty"`
+	// When set, the slot is swapped with production once its health check passes
+	Swap bool `yaml:"swap,omitempty"`
+	// The path requested on the slot to check its health before swapping. Defaults to '/'
+	HealthCheckPath string `yaml:"healthCheckPath,omitempty"`
+}
+
+var (
+	// How long the health check of a slot is retried before the swap is abandoned.
+	slotHealthCheckTimeout = 5 * time.Minute
+	// How long to wait between health check requests.
+	slotHealthCheckInterval = 10 * time.Second
+)
+
+// slotDeployment deploys web and function apps to a deployment slot and swaps the slot into production.
+type slotDeployment struct {
+	env        *environment.Environment
+	cli        azcli.AzCli
+	httpClient httputil.HttpClient
+}
+
+// slotName returns the name of the deployment slot configured for the service, or an empty string when the service
+// is deployed to production.
+func (s *slotDeployment) slotName(serviceConfig *ServiceConfig) (string, error) {
+	name, err := serviceConfig.Slot.Name.Envsubst(s.env.Getenv)
+	if err != nil {
+		return "", fmt.Errorf("expanding slot name: %w", err)
+	}
+
+	return strings.TrimSpace(name), nil
+}
+
+// Deploy uploads the zip package to the slot, creating the slot when missing. When the service is configured to swap,
+// the slot is swapped with production after its health check passes. Returns the status of the deployment and the
+// endpoints now serving the package.
+func (s *slotDeployment) Deploy(
+	ctx context.Context,
+	task *async.TaskContextWithProgress[*ServiceDeployResult, ServiceProgress],
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+	slotName string,
+	zipFile io.Reader,
+) (*string, []string, error) {
+	task.SetProgress(NewServicePhaseProgress(
+		ProgressPhaseCreatingDeployment, fmt.Sprintf("Preparing deployment slot '%s'", slotName)))
+	if _, err := s.cli.EnsureAppServiceSlot(
+		ctx,
+		targetResource.SubscriptionId(),
+		targetResource.ResourceGroupName(),
+		targetResource.ResourceName(),
+		slotName,
+	); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.updateAppSettings(ctx, task, serviceConfig, targetResource, slotName); err != nil {
+		return nil, nil, err
+	}
+
+	task.SetProgress(NewServicePhaseProgress(
+		ProgressPhaseUploading, fmt.Sprintf("Uploading deployment package to slot '%s'", slotName)))
+	res, err := s.cli.DeployAppServiceSlotZip(
+		ctx,
+		targetResource.SubscriptionId(),
+		targetResource.ResourceGroupName(),
+		targetResource.ResourceName(),
+		slotName,
+		zipFile,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	slotProperties, err := s.cli.GetAppServiceSlotProperties(
+		ctx,
+		targetResource.SubscriptionId(),
+		targetResource.ResourceGroupName(),
+		targetResource.ResourceName(),
+		slotName,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching slot properties: %w", err)
+	}
+
+	slotEndpoints := make([]string, len(slotProperties.HostNames))
+	for idx, hostName := range slotProperties.HostNames {
+		slotEndpoints[idx] = fmt.Sprintf("https://%s/", hostName)
+	}
+
+	if !serviceConfig.Slot.Swap {
+		return res, slotEndpoints, nil
+	}
+
+	task.SetProgress(NewServicePhaseProgress(ProgressPhaseVerifying, fmt.Sprintf("Checking health of slot '%s'", slotName)))
+	if err := s.checkHealth(ctx, slotEndpoints, serviceConfig.Slot.HealthCheckPath); err != nil {
+		return nil, nil, fmt.Errorf("slot '%s' is not healthy, skipping swap: %w", slotName, err)
+	}
+
+	task.SetProgress(NewServicePhaseProgress(
+		ProgressPhaseCreatingDeployment, fmt.Sprintf("Swapping slot '%s' into production", slotName)))
+	if err := s.cli.SwapAppServiceSlot(
+		ctx,
+		targetResource.SubscriptionId(),
+		targetResource.ResourceGroupName(),
+		targetResource.ResourceName(),
+		slotName,
+	); err != nil {
+		return nil, nil, err
+	}
+
+	return res, nil, nil
+}
+
+// updateAppSettings sets the provisioning outputs mapped by outputEnv as app settings of the web or function app, or of
+// its deployment slot when slotName is set, before the package is uploaded.
+func (s *slotDeployment) updateAppSettings(
+	ctx context.Context,
+	task *async.TaskContextWithProgress[*ServiceDeployResult, ServiceProgress],
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+	slotName string,
+) error {
+	outputEnv, err := serviceConfig.ResolveOutputEnv(s.env)
+	if err != nil || len(outputEnv) == 0 {
+		return err
+	}
+
+	task.SetProgress(NewServicePhaseProgress(ProgressPhaseCreatingDeployment, "Updating app settings"))
+	return s.cli.UpdateAppServiceAppSettings(
+		ctx,
+		targetResource.SubscriptionId(),
+		targetResource.ResourceGroupName(),
+		targetResource.ResourceName(),
+		slotName,
+		outputEnv,
+	)
+}
+
+// checkHealth requests the health check path of the slot until it responds with a success status code or the health
+// check times out.
+func (s *slotDeployment) checkHealth(ctx context.Context, endpoints []string, healthCheckPath string) error {
+	if len(endpoints) == 0 {
+		return fmt.Errorf("slot has no endpoints")
+	}
+
+	healthCheckUrl := strings.TrimSuffix(endpoints[0], "/") + "/" + strings.TrimPrefix(healthCheckPath, "/")
+
+	return retry.Do(
+		ctx,
+		retry.WithMaxDuration(slotHealthCheckTimeout, retry.NewConstant(slotHealthCheckInterval)),
+		func(ctx context.Context) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, healthCheckUrl, nil)
+			if err != nil {
+				return err
+			}
+
+			res, err := s.httpClient.Do(req)
+			if err != nil {
+				return retry.RetryableError(fmt.Errorf("requesting %s: %w", healthCheckUrl, err))
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode < 200 || res.StatusCode >= 300 {
+				return retry.RetryableError(
+					fmt.Errorf("%s responded with status code %d", healthCheckUrl, res.StatusCode))
+			}
+
+			return nil
+		},
+	)
+}
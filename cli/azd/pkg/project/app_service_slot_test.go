@@ -0,0 +1,277 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/stretchr/testify/require"
+)
+
+type slotMockState struct {
+	slotExists     bool
+	slotCreated    bool
+	deployHost     string
+	healthStatus   int
+	healthRequests int
+	swapped        bool
+}
+
+func registerSlotMocks(mockContext *mocks.MockContext, state *slotMockState) {
+	site := func(hostName string, scmHost string) armappservice.Site {
+		return armappservice.Site{
+			Location: convert.RefOf("eastus2"),
+			Properties: &armappservice.SiteProperties{
+				DefaultHostName: convert.RefOf(hostName),
+				ServerFarmID: convert.RefOf(
+					"/subscriptions/SUB_ID/resourceGroups/RG_ID/providers/Microsoft.Web/serverfarms/PLAN"),
+				HostNameSSLStates: []*armappservice.HostNameSSLState{
+					{
+						HostType: convert.RefOf(armappservice.HostTypeRepository),
+						Name:     convert.RefOf(scmHost),
+					},
+				},
+			},
+		}
+	}
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/Microsoft.Web/sites/WEB_APP")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(
+			request, http.StatusOK, site("WEB_APP.azurewebsites.net", "WEB_APP_SCM_HOST"))
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return strings.HasSuffix(request.URL.Path, "/Microsoft.Web/sites/WEB_APP/slots/staging")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		if request.Method == http.MethodPut {
+			state.slotCreated = true
+			state.slotExists = true
+		}
+
+		if !state.slotExists {
+			return mocks.CreateEmptyHttpResponse(request, http.StatusNotFound)
+		}
+
+		return mocks.CreateHttpResponseWithBody(
+			request, http.StatusOK, site("WEB_APP-staging.azurewebsites.net", "WEB_APP_STAGING_SCM_HOST"))
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && strings.HasSuffix(request.URL.Path, "/api/zipdeploy")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		state.deployHost = request.URL.Host
+		response, err := mocks.CreateEmptyHttpResponse(request, http.StatusAccepted)
+		response.Header.Set("Location", "https://"+request.URL.Host+"/api/deployments/latest")
+		return response, err
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && request.URL.Path == "/api/deployments/latest"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, azsdk.DeployStatusResponse{
+			DeployStatus: azsdk.DeployStatus{StatusText: "OK", Complete: true},
+		})
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && request.URL.Host == "WEB_APP-staging.azurewebsites.net"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		state.healthRequests++
+		return mocks.CreateEmptyHttpResponse(request, state.healthStatus)
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && strings.HasSuffix(request.URL.Path, "/slots/staging/slotsswap")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		state.swapped = true
+		return mocks.CreateEmptyHttpResponse(request, http.StatusOK)
+	})
+}
+
+func Test_AppServiceTarget_DeployToSlot(t *testing.T) {
+	originalTimeout, originalInterval := slotHealthCheckTimeout, slotHealthCheckInterval
+	slotHealthCheckTimeout, slotHealthCheckInterval = 50*time.Millisecond, 5*time.Millisecond
+	t.Cleanup(func() {
+		slotHealthCheckTimeout, slotHealthCheckInterval = originalTimeout, originalInterval
+	})
+
+	tests := []struct {
+		name              string
+		slotExists        bool
+		swap              bool
+		healthStatus      int
+		expectError       bool
+		expectSwapped     bool
+		expectHealthCheck bool
+		expectEndpoints   []string
+	}{
+		{
+			name:            "CreatesMissingSlot",
+			expectEndpoints: []string{"https://WEB_APP-staging.azurewebsites.net/"},
+		},
+		{
+			name:              "SwapAfterHealthCheck",
+			slotExists:        true,
+			swap:              true,
+			healthStatus:      http.StatusOK,
+			expectSwapped:     true,
+			expectHealthCheck: true,
+			expectEndpoints:   []string{"https://WEB_APP.azurewebsites.net/"},
+		},
+		{
+			name:              "NoSwapWhenUnhealthy",
+			slotExists:        true,
+			swap:              true,
+			healthStatus:      http.StatusServiceUnavailable,
+			expectError:       true,
+			expectHealthCheck: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockContext := mocks.NewMockContext(context.Background())
+			state := &slotMockState{slotExists: tt.slotExists, healthStatus: tt.healthStatus}
+			registerSlotMocks(mockContext, state)
+
+			zipPath := filepath.Join(t.TempDir(), "package.zip")
+			require.NoError(t, os.WriteFile(zipPath, []byte("zip"), osutil.PermissionFile))
+
+			env := environment.NewWithValues("dev", map[string]string{"SLOT_NAME": "staging"})
+			serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguageJavaScript)
+			serviceConfig.Slot = SlotOptions{
+				Name:            osutil.NewExpandableString("${SLOT_NAME}"),
+				Swap:            tt.swap,
+				HealthCheckPath: "/health",
+			}
+
+			serviceTarget := NewAppServiceTarget(env, mockazcli.NewAzCliFromMockContext(mockContext), mockContext.HttpClient)
+			targetResource := environment.NewTargetResource(
+				"SUB_ID", "RG_ID", "WEB_APP", string(infra.AzureResourceTypeWebSite))
+
+			deployTask := serviceTarget.Deploy(
+				*mockContext.Context, serviceConfig, &ServicePackageResult{PackagePath: zipPath}, targetResource)
+			logProgress(deployTask)
+			result, err := deployTask.Await()
+
+			require.Equal(t, "WEB_APP_STAGING_SCM_HOST", state.deployHost)
+			require.Equal(t, !tt.slotExists, state.slotCreated)
+			require.Equal(t, tt.expectSwapped, state.swapped)
+			require.Equal(t, tt.expectHealthCheck, state.healthRequests > 0)
+
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expectEndpoints, result.Endpoints)
+		})
+	}
+}
+
+func Test_AppServiceTarget_OutputEnv(t *testing.T) {
+	tests := []struct {
+		name       string
+		slotName   string
+		expectPath string
+	}{
+		{
+			name:       "Production",
+			expectPath: "/Microsoft.Web/sites/WEB_APP/config/appsettings",
+		},
+		{
+			name:       "Slot",
+			slotName:   "staging",
+			expectPath: "/Microsoft.Web/sites/WEB_APP/slots/staging/config/appsettings",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockContext := mocks.NewMockContext(context.Background())
+			registerSlotMocks(mockContext, &slotMockState{slotExists: true})
+
+			mockContext.HttpClient.When(func(request *http.Request) bool {
+				return request.Method == http.MethodPost && strings.HasSuffix(request.URL.Path, "/config/appsettings/list")
+			}).RespondFn(func(request *http.Request) (*http.Response, error) {
+				return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armappservice.StringDictionary{
+					Properties: map[string]*string{"EXISTING": convert.RefOf("value")},
+				})
+			})
+
+			var updatedPath string
+			var updatedSettings armappservice.StringDictionary
+			mockContext.HttpClient.When(func(request *http.Request) bool {
+				return request.Method == http.MethodPut && strings.HasSuffix(request.URL.Path, "/config/appsettings")
+			}).RespondFn(func(request *http.Request) (*http.Response, error) {
+				updatedPath = request.URL.Path
+				require.NoError(t, json.NewDecoder(request.Body).Decode(&updatedSettings))
+				return mocks.CreateHttpResponseWithBody(request, http.StatusOK, updatedSettings)
+			})
+
+			zipPath := filepath.Join(t.TempDir(), "package.zip")
+			require.NoError(t, os.WriteFile(zipPath, []byte("zip"), osutil.PermissionFile))
+
+			env := environment.NewWithValues("dev", map[string]string{"SQL_CONN": "Server=db"})
+			serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguageJavaScript)
+			serviceConfig.OutputEnv = map[string]string{"DATABASE_URL": "SQL_CONN"}
+			serviceConfig.Slot.Name = osutil.NewExpandableString(tt.slotName)
+
+			serviceTarget := NewAppServiceTarget(env, mockazcli.NewAzCliFromMockContext(mockContext), mockContext.HttpClient)
+			targetResource := environment.NewTargetResource(
+				"SUB_ID", "RG_ID", "WEB_APP", string(infra.AzureResourceTypeWebSite))
+
+			deployTask := serviceTarget.Deploy(
+				*mockContext.Context, serviceConfig, &ServicePackageResult{PackagePath: zipPath}, targetResource)
+			logProgress(deployTask)
+			_, err := deployTask.Await()
+			require.NoError(t, err)
+
+			// The outputs are merged into the existing app settings
+			require.True(t, strings.HasSuffix(updatedPath, tt.expectPath), updatedPath)
+			require.Equal(t, map[string]*string{
+				"EXISTING":     convert.RefOf("value"),
+				"DATABASE_URL": convert.RefOf("Server=db"),
+			}, updatedSettings.Properties)
+		})
+	}
+}
+
+func Test_FunctionAppTarget_SlotNotSupportedOnFlexConsumption(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	zipPath := filepath.Join(t.TempDir(), "package.zip")
+	require.NoError(t, os.WriteFile(zipPath, []byte("zip"), osutil.PermissionFile))
+
+	env := environment.NewWithValues("dev", map[string]string{"SERVICE_API_PLAN_SKU": "FC1"})
+	serviceConfig := createTestServiceConfig("./src/api", AzureFunctionTarget, ServiceLanguageJavaScript)
+	serviceConfig.Slot.Name = osutil.NewExpandableString("staging")
+
+	serviceTarget := NewFunctionAppTarget(env, mockazcli.NewAzCliFromMockContext(mockContext), mockContext.HttpClient)
+	targetResource := environment.NewTargetResource(
+		"SUB_ID", "RG_ID", "FUNC_APP", string(infra.AzureResourceTypeWebSite))
+
+	deployTask := serviceTarget.Deploy(
+		*mockContext.Context, serviceConfig, &ServicePackageResult{PackagePath: zipPath}, targetResource)
+	logProgress(deployTask)
+	_, err := deployTask.Await()
+	require.ErrorContains(t, err, "Flex Consumption")
+}
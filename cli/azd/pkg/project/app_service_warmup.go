@@ -0,0 +1,105 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/sethvargo/go-retry"
+)
+
+// WarmUpOptions configures the requests sent to an App Service or Function App after it is deployed, so the first
+// requests of users aren't served by cold instances.
+type WarmUpOptions struct {
+	// The paths requested on the app. Warm-up is skipped when empty.
+	Paths []string `yaml:"paths,omitempty"`
+	// The number of successful responses required for each path. Defaults to 1
+	Count int `yaml:"count,omitempty"`
+	// The status code of a successful response. Defaults to 200
+	ExpectedStatus int `yaml:"expectedStatus,omitempty"`
+	// How long to keep sending requests before the deployment fails, e.g. '90s' or '5m'. Defaults to 5 minutes
+	Timeout string `yaml:"timeout,omitempty"`
+}
+
+const defaultWarmUpTimeout = 5 * time.Minute
+
+// How long to wait before retrying a warm-up request that didn't return the expected status.
+var warmUpInterval = 5 * time.Second
+
+// warmUp requests each path of the warm-up options on the endpoint until the expected number of successful responses
+// is returned for every path, or the warm-up times out.
+func warmUp(ctx context.Context, httpClient httputil.HttpClient, endpoint string, options WarmUpOptions) error {
+	if len(options.Paths) == 0 {
+		return nil
+	}
+
+	count := options.Count
+	if count <= 0 {
+		count = 1
+	}
+
+	expectedStatus := options.ExpectedStatus
+	if expectedStatus == 0 {
+		expectedStatus = http.StatusOK
+	}
+
+	timeout := defaultWarmUpTimeout
+	if options.Timeout != "" {
+		parsed, err := time.ParseDuration(options.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid warm-up timeout '%s': %w", options.Timeout, err)
+		}
+
+		timeout = parsed
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for _, path := range options.Paths {
+		url := strings.TrimSuffix(endpoint, "/") + "/" + strings.TrimPrefix(path, "/")
+		successes := 0
+		var lastErr error
+
+		err := retry.Do(ctx, retry.NewConstant(warmUpInterval), func(ctx context.Context) error {
+			for successes < count {
+				req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+				if err != nil {
+					return err
+				}
+
+				res, err := httpClient.Do(req)
+				if err != nil {
+					lastErr = fmt.Errorf("requesting %s: %w", url, err)
+					return retry.RetryableError(lastErr)
+				}
+				res.Body.Close()
+
+				if res.StatusCode != expectedStatus {
+					lastErr = fmt.Errorf("%s responded with status code %d, expected %d", url, res.StatusCode, expectedStatus)
+					return retry.RetryableError(lastErr)
+				}
+
+				successes++
+			}
+
+			return nil
+		})
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) && lastErr != nil {
+				return fmt.Errorf("warm-up timed out after %s: %w", timeout, lastErr)
+			}
+
+			return fmt.Errorf("warming up %s: %w", url, err)
+		}
+	}
+
+	return nil
+}
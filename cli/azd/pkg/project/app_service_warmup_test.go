@@ -0,0 +1,138 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/stretchr/testify/require"
+)
+
+// slowServer responds with 503 to the first coldRequests requests of each path, like an instance that is starting.
+type slowServer struct {
+	mu           sync.Mutex
+	coldRequests int
+	requests     map[string]int
+}
+
+func (s *slowServer) register(mockContext *mocks.MockContext, host string) {
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && request.URL.Host == host
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.requests[request.URL.Path]++
+		if s.requests[request.URL.Path] <= s.coldRequests {
+			return mocks.CreateEmptyHttpResponse(request, http.StatusServiceUnavailable)
+		}
+
+		return mocks.CreateEmptyHttpResponse(request, http.StatusOK)
+	})
+}
+
+func setWarmUpInterval(t *testing.T, interval time.Duration) {
+	original := warmUpInterval
+	warmUpInterval = interval
+	t.Cleanup(func() {
+		warmUpInterval = original
+	})
+}
+
+func Test_WarmUp(t *testing.T) {
+	setWarmUpInterval(t, time.Millisecond)
+
+	t.Run("SlowToWarm", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		server := &slowServer{coldRequests: 3, requests: map[string]int{}}
+		server.register(mockContext, "app.azurewebsites.net")
+
+		err := warmUp(*mockContext.Context, mockContext.HttpClient, "https://app.azurewebsites.net/", WarmUpOptions{
+			Paths: []string{"/", "api/health"},
+			Count: 2,
+		})
+		require.NoError(t, err)
+		require.Equal(t, map[string]int{"/": 5, "/api/health": 5}, server.requests)
+	})
+
+	t.Run("ExpectedStatus", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		server := &slowServer{coldRequests: 0, requests: map[string]int{}}
+		server.register(mockContext, "app.azurewebsites.net")
+
+		err := warmUp(*mockContext.Context, mockContext.HttpClient, "https://app.azurewebsites.net", WarmUpOptions{
+			Paths:          []string{"/"},
+			ExpectedStatus: http.StatusNoContent,
+			Timeout:        "20ms",
+		})
+		require.ErrorContains(t, err, "timed out")
+		require.ErrorContains(t, err, "expected 204")
+	})
+
+	t.Run("TimesOut", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		server := &slowServer{coldRequests: 1000, requests: map[string]int{}}
+		server.register(mockContext, "app.azurewebsites.net")
+
+		err := warmUp(*mockContext.Context, mockContext.HttpClient, "https://app.azurewebsites.net", WarmUpOptions{
+			Paths:   []string{"/"},
+			Timeout: "20ms",
+		})
+		require.ErrorContains(t, err, "status code 503")
+	})
+
+	t.Run("InvalidTimeout", func(t *testing.T) {
+		err := warmUp(context.Background(), nil, "https://app.azurewebsites.net", WarmUpOptions{
+			Paths:   []string{"/"},
+			Timeout: "soon",
+		})
+		require.ErrorContains(t, err, "invalid warm-up timeout")
+	})
+}
+
+func Test_AppServiceTarget_WarmUpAfterSwap(t *testing.T) {
+	setWarmUpInterval(t, time.Millisecond)
+
+	mockContext := mocks.NewMockContext(context.Background())
+	state := &slotMockState{slotExists: true, healthStatus: http.StatusOK}
+	registerSlotMocks(mockContext, state)
+
+	server := &slowServer{coldRequests: 2, requests: map[string]int{}}
+	server.register(mockContext, "WEB_APP.azurewebsites.net")
+
+	zipPath := filepath.Join(t.TempDir(), "package.zip")
+	require.NoError(t, os.WriteFile(zipPath, []byte("zip"), osutil.PermissionFile))
+
+	env := environment.NewWithValues("dev", map[string]string{})
+	serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguageJavaScript)
+	serviceConfig.Slot = SlotOptions{Name: osutil.NewExpandableString("staging"), Swap: true}
+	serviceConfig.WarmUp = WarmUpOptions{Paths: []string{"/warmup"}}
+
+	serviceTarget := NewAppServiceTarget(env, mockazcli.NewAzCliFromMockContext(mockContext), mockContext.HttpClient)
+	targetResource := environment.NewTargetResource(
+		"SUB_ID", "RG_ID", "WEB_APP", string(infra.AzureResourceTypeWebSite))
+
+	deployTask := serviceTarget.Deploy(
+		*mockContext.Context, serviceConfig, &ServicePackageResult{PackagePath: zipPath}, targetResource)
+	logProgress(deployTask)
+	_, err := deployTask.Await()
+	require.NoError(t, err)
+
+	// The production endpoint is warmed up once the slot is swapped
+	require.True(t, state.swapped)
+	require.Equal(t, 3, server.requests["/warmup"])
+	require.True(t, strings.HasPrefix(state.deployHost, "WEB_APP_STAGING"))
+}
@@ -2,17 +2,21 @@ package project
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"sync"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/async"
 	"github.com/azure/azure-dev/cli/azd/pkg/cloud"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/cosign"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
 	"github.com/benbjohnson/clock"
 )
 
@@ -21,8 +25,14 @@ type ContainerHelper struct {
 	envManager               environment.Manager
 	containerRegistryService azcli.ContainerRegistryService
 	docker                   docker.Docker
+	git                      git.GitCli
 	clock                    clock.Clock
 	cloud                    *cloud.Cloud
+	cosign                   cosign.CosignCli
+
+	// The registries other than Azure Container Registry logged into, by host and username
+	externalLogins     map[string]struct{}
+	externalLoginsLock sync.Mutex
 }
 
 func NewContainerHelper(
@@ -31,15 +41,19 @@ func NewContainerHelper(
 	clock clock.Clock,
 	containerRegistryService azcli.ContainerRegistryService,
 	docker docker.Docker,
+	git git.GitCli,
 	cloud *cloud.Cloud,
+	cosignCli cosign.CosignCli,
 ) *ContainerHelper {
 	return &ContainerHelper{
 		env:                      env,
 		envManager:               envManager,
 		containerRegistryService: containerRegistryService,
 		docker:                   docker,
+		git:                      git,
 		clock:                    clock,
 		cloud:                    cloud,
+		cosign:                   cosignCli,
 	}
 }
 
@@ -106,19 +120,12 @@ func (ch *ContainerHelper) GeneratedImage(
 	}
 
 	if parsedImage.Tag == "" {
-		configuredTag, err := serviceConfig.Docker.Tag.Envsubst(ch.env.Getenv)
+		imageTag, err := ch.resolveImageTag(ctx, serviceConfig)
 		if err != nil {
-			return nil, fmt.Errorf("failed parsing 'tag' from docker configuration, %w", err)
-		}
-
-		// Set default tag if not configured
-		if configuredTag == "" {
-			configuredTag = fmt.Sprintf("azd-deploy-%d",
-				ch.clock.Now().Unix(),
-			)
+			return nil, err
 		}
 
-		parsedImage.Tag = configuredTag
+		parsedImage.Tag = imageTag
 	}
 
 	// Set default registry if not configured
@@ -181,14 +188,85 @@ func (ch *ContainerHelper) Login(
 		return "", err
 	}
 
-	// Only perform automatic login for ACR
-	// Other registries require manual login via external 'docker login' command
-	hostParts := strings.Split(registryName, ".")
-	if len(hostParts) == 1 || strings.HasSuffix(registryName, ch.cloud.ContainerRegistryEndpointSuffix) {
+	registry, err := ParseContainerRegistry(registryName, ch.cloud.ContainerRegistryEndpointSuffix)
+	if err != nil {
+		return "", err
+	}
+
+	if registry.IsAzure() {
 		return registryName, ch.containerRegistryService.Login(ctx, ch.env.GetSubscriptionId(), registryName)
 	}
 
-	return registryName, nil
+	return registryName, ch.loginExternal(ctx, serviceConfig, registry)
+}
+
+// ValidateRegistry verifies that a registry other than Azure Container Registry configured for the service is reachable
+// and that the configured credentials are accepted, so that problems surface before an image is built. Azure Container
+// Registries are validated when the image is pushed, since they may not have been provisioned yet.
+func (ch *ContainerHelper) ValidateRegistry(ctx context.Context, serviceConfig *ServiceConfig) error {
+	registryName, err := ch.RegistryName(ctx, serviceConfig)
+	if err != nil || registryName == "" {
+		// The registry is not known yet, it may be created during provisioning.
+		return nil
+	}
+
+	registry, err := ParseContainerRegistry(registryName, ch.cloud.ContainerRegistryEndpointSuffix)
+	if err != nil {
+		return err
+	}
+
+	if registry.IsAzure() {
+		return nil
+	}
+
+	return ch.loginExternal(ctx, serviceConfig, registry)
+}
+
+// loginExternal logs into a registry other than Azure Container Registry when credentials are configured. Without
+// credentials, a previous 'docker login' is relied on.
+func (ch *ContainerHelper) loginExternal(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	registry *ContainerRegistry,
+) error {
+	credentials, err := registryCredentials(registry, serviceConfig.Docker, ch.env.Getenv, true)
+	if err != nil {
+		return err
+	}
+
+	if credentials == nil {
+		log.Printf("no credentials configured for container registry '%s', relying on 'docker login'", registry.Host)
+		return nil
+	}
+
+	// The registry is validated before building the image, then logged into again before pushing it
+	ch.externalLoginsLock.Lock()
+	defer ch.externalLoginsLock.Unlock()
+
+	loginKey := credentials.LoginServer + "\x00" + credentials.Username
+	if _, has := ch.externalLogins[loginKey]; has {
+		return nil
+	}
+
+	log.Printf("logging into %s container registry '%s'", registry.Kind, registry.Host)
+	if err := ch.docker.Login(ctx, credentials.LoginServer, credentials.Username, credentials.Password); err != nil {
+		return &azcli.ErrorWithSuggestion{
+			Err: fmt.Errorf("logging into container registry '%s': %w", registry.Host, err),
+			Suggestion: fmt.Sprintf(
+				"Ensure the registry is reachable and the credentials set in 'docker.username' and 'docker.password' "+
+					"or the %s and %s environment variables are valid",
+				RegistryUsernameEnvVarName,
+				RegistryPasswordEnvVarName,
+			),
+		}
+	}
+
+	if ch.externalLogins == nil {
+		ch.externalLogins = map[string]struct{}{}
+	}
+	ch.externalLogins[loginKey] = struct{}{}
+
+	return nil
 }
 
 func (ch *ContainerHelper) Credentials(
@@ -201,9 +279,57 @@ func (ch *ContainerHelper) Credentials(
 		return nil, err
 	}
 
+	registry, err := ParseContainerRegistry(loginServer, ch.cloud.ContainerRegistryEndpointSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	if !registry.IsAzure() {
+		credentials, err := registryCredentials(registry, serviceConfig.Docker, ch.env.Getenv, false)
+		if err != nil {
+			return nil, err
+		}
+
+		if credentials == nil {
+			return nil, fmt.Errorf(
+				"no credentials configured for container registry '%s', set 'docker.username' and 'docker.password' "+
+					"or the %s and %s environment variables",
+				registry.Host,
+				RegistryUsernameEnvVarName,
+				RegistryPasswordEnvVarName,
+			)
+		}
+
+		return credentials, nil
+	}
+
 	return ch.containerRegistryService.Credentials(ctx, targetResource.SubscriptionId(), loginServer)
 }
 
+// PullCredentials returns the credentials Azure hosts need to pull the image of the service. nil is returned for Azure
+// Container Registries, which are accessed with the identity of the host, and for external registries without
+// configured credentials, which are assumed to be public.
+func (ch *ContainerHelper) PullCredentials(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) (*azcli.DockerCredentials, error) {
+	registryName, err := ch.RegistryName(ctx, serviceConfig)
+	if err != nil || registryName == "" {
+		return nil, err
+	}
+
+	registry, err := ParseContainerRegistry(registryName, ch.cloud.ContainerRegistryEndpointSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	if registry.IsAzure() {
+		return nil, nil
+	}
+
+	return registryCredentials(registry, serviceConfig.Docker, ch.env.Getenv, false)
+}
+
 // Deploy pushes and image to a remote server, and optionally writes the fully qualified remote image name to the
 // environment on success.
 func (ch *ContainerHelper) Deploy(
@@ -234,10 +360,16 @@ func (ch *ContainerHelper) Deploy(
 			// Default to the local image tag
 			remoteImage := targetImage
 
-			// If we don't have a registry specified and the service does not reference a project path
-			// then we are referencing a public/pre-existing image and don't have anything to tag or push
-			if registryName == "" && serviceConfig.RelativePath == "" && sourceImage != "" {
+			// If we don't have a registry specified, or the existing image is deployed directly from its registry, and
+			// the service does not reference a project path then we are referencing a public/pre-existing image and
+			// don't have anything to tag or push
+			if (registryName == "" || !serviceConfig.Docker.pushImage()) &&
+				serviceConfig.RelativePath == "" && sourceImage != "" {
 				remoteImage = sourceImage
+			} else if packageDetails == nil && registryName != "" && strings.HasPrefix(targetImage, registryName+"/") {
+				// A prebuilt image already pushed to the registry, like one passed to 'azd deploy --from-package',
+				// is deployed as-is
+				log.Printf("deploying image '%s' from registry '%s'", targetImage, registryName)
 			} else {
 				if targetImage == "" {
 					task.SetError(errors.New("failed retrieving package result details"))
@@ -246,11 +378,19 @@ func (ch *ContainerHelper) Deploy(
 
 				// If a registry has not been defined then there is no need to tag or push any images
 				if registryName != "" {
+					// Fail before pushing the image when it can't be signed once pushed
+					if serviceConfig.Docker.Sign != nil {
+						if err := ensureTools(ctx, "signing the image of", serviceConfig, ch.cosign); err != nil {
+							task.SetError(err)
+							return
+						}
+					}
+
 					// When the project does not contain source and we are using an external image we first need to pull the image
 					// before we're able to push it to a remote registry
 					// In most cases this pull will have already been part of the package step
 					if packageDetails != nil && serviceConfig.RelativePath == "" {
-						task.SetProgress(NewServiceProgress("Pulling container image"))
+						task.SetProgress(NewServicePhaseProgress(ProgressPhasePullingImage, "Pulling container image"))
 						err = ch.docker.Pull(ctx, sourceImage)
 						if err != nil {
 							task.SetError(fmt.Errorf("pulling image: %w", err))
@@ -268,14 +408,14 @@ func (ch *ContainerHelper) Deploy(
 
 					remoteImage = remoteImageWithTag
 
-					task.SetProgress(NewServiceProgress("Tagging container image"))
+					task.SetProgress(NewServicePhaseProgress(ProgressPhasePushingImage, "Tagging container image"))
 					if err := ch.docker.Tag(ctx, serviceConfig.Path(), targetImage, remoteImage); err != nil {
 						task.SetError(err)
 						return
 					}
 
 					log.Printf("logging into container registry '%s'\n", registryName)
-					task.SetProgress(NewServiceProgress("Logging into container registry"))
+					task.SetProgress(NewServicePhaseProgress(ProgressPhasePushingImage, "Logging into container registry"))
 
 					_, err = ch.Login(ctx, serviceConfig)
 					if err != nil {
@@ -285,17 +425,45 @@ func (ch *ContainerHelper) Deploy(
 
 					// Push image.
 					log.Printf("pushing %s to registry", remoteImage)
-					task.SetProgress(NewServiceProgress("Pushing container image"))
-					if err := ch.docker.Push(ctx, serviceConfig.Path(), remoteImage); err != nil {
+					task.SetProgress(NewServicePhaseProgress(ProgressPhasePushingImage, "Pushing container image"))
+					pushProgress := func(progress docker.PushProgress) {
+						task.SetProgress(NewServicePhaseProgress(
+							ProgressPhasePushingImage,
+							fmt.Sprintf("Pushing container image (%d/%d layers)", progress.Pushed, progress.Layers),
+						))
+					}
+					if err := ch.docker.Push(ctx, serviceConfig.Path(), remoteImage, pushProgress); err != nil {
 						errSuggestion := &azcli.ErrorWithSuggestion{
 							Err: err,
 							//nolint:lll
-							Suggestion: "When pushing to an external registry, ensure you have successfully authenticated by calling 'docker login' and run 'azd deploy' again",
+							Suggestion: "When pushing to an external registry, ensure you have successfully authenticated by calling 'docker login' or by setting 'docker.username' and 'docker.password' and run 'azd deploy' again",
 						}
 
 						task.SetError(errSuggestion)
 						return
 					}
+
+					if serviceConfig.Docker.PushLatest {
+						latestImage, err := ch.tagLatest(ctx, serviceConfig, remoteImage)
+						if err != nil {
+							task.SetError(err)
+							return
+						}
+
+						log.Printf("pushing %s to registry", latestImage)
+						if err := ch.docker.Push(ctx, serviceConfig.Path(), latestImage, nil); err != nil {
+							task.SetError(fmt.Errorf("pushing '%s': %w", latestImage, err))
+							return
+						}
+					}
+
+					if serviceConfig.Docker.Sign != nil {
+						task.SetProgress(NewServicePhaseProgress(ProgressPhasePushingImage, "Signing container image"))
+						if err := ch.signImage(ctx, serviceConfig, remoteImage); err != nil {
+							task.SetError(err)
+							return
+						}
+					}
 				}
 			}
 
@@ -303,6 +471,9 @@ func (ch *ContainerHelper) Deploy(
 				// Save the name of the image we pushed into the environment with a well known key.
 				log.Printf("writing image name to environment")
 				ch.env.SetServiceProperty(serviceConfig.Name, "IMAGE_NAME", remoteImage)
+				if parsedImage, err := docker.ParseContainerImage(remoteImage); err == nil && parsedImage.Tag != "" {
+					ch.env.SetServiceProperty(serviceConfig.Name, "IMAGE_TAG", parsedImage.Tag)
+				}
 
 				if err := ch.envManager.Save(ctx, ch.env); err != nil {
 					task.SetError(fmt.Errorf("saving image name to environment: %w", err))
@@ -319,6 +490,87 @@ func (ch *ContainerHelper) Deploy(
 		})
 }
 
+// signImage signs the pushed remote image with cosign by its digest, and attests the configured SBOM to it.
+func (ch *ContainerHelper) signImage(ctx context.Context, serviceConfig *ServiceConfig, remoteImage string) error {
+	signConfig := serviceConfig.Docker.Sign
+
+	key, err := signConfig.Key.Envsubst(ch.env.Getenv)
+	if err != nil {
+		return fmt.Errorf("expanding 'docker.sign.key': %w", err)
+	}
+
+	identityToken, err := signConfig.IdentityToken.Envsubst(ch.env.Getenv)
+	if err != nil {
+		return fmt.Errorf("expanding 'docker.sign.identityToken': %w", err)
+	}
+
+	// Images are signed by digest, since a tag can be moved to another image after it's signed
+	digestImage, err := ch.pushedDigest(ctx, remoteImage)
+	if err != nil {
+		return err
+	}
+
+	options := cosign.SignOptions{Key: key, IdentityToken: identityToken}
+	log.Printf("signing %s", digestImage)
+	if err := ch.cosign.Sign(ctx, serviceConfig.Path(), digestImage, options); err != nil {
+		return err
+	}
+
+	if signConfig.Sbom != "" {
+		sbomType := signConfig.SbomType
+		if sbomType == "" {
+			sbomType = "spdxjson"
+		}
+
+		// cosign runs from the service path, which relative SBOM paths are resolved from
+		log.Printf("attesting SBOM %s to %s", signConfig.Sbom, digestImage)
+		err := ch.cosign.Attest(ctx, serviceConfig.Path(), digestImage, signConfig.Sbom, sbomType, options)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pushedDigest returns the reference by digest, like 'registry/repository@sha256:...', of the remote image pushed to
+// its registry.
+func (ch *ContainerHelper) pushedDigest(ctx context.Context, remoteImage string) (string, error) {
+	output, err := ch.docker.Inspect(ctx, remoteImage, "{{json .RepoDigests}}")
+	if err != nil {
+		return "", fmt.Errorf("getting the digest of '%s': %w", remoteImage, err)
+	}
+
+	var repoDigests []string
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &repoDigests); err != nil {
+		return "", fmt.Errorf("getting the digest of '%s': %w", remoteImage, err)
+	}
+
+	repository, _ := docker.SplitDockerImage(remoteImage)
+	for _, repoDigest := range repoDigests {
+		if strings.HasPrefix(repoDigest, repository+"@") {
+			return repoDigest, nil
+		}
+	}
+
+	return "", fmt.Errorf("the digest of '%s' was not found after pushing it", remoteImage)
+}
+
+// tagLatest tags the remote image with the 'latest' tag in addition to its resolved tag and returns the new image name.
+func (ch *ContainerHelper) tagLatest(ctx context.Context, serviceConfig *ServiceConfig, remoteImage string) (string, error) {
+	latestImage, err := docker.ParseContainerImage(remoteImage)
+	if err != nil {
+		return "", err
+	}
+
+	latestImage.Tag = latestTag
+	if err := ch.docker.Tag(ctx, serviceConfig.Path(), remoteImage, latestImage.Remote()); err != nil {
+		return "", err
+	}
+
+	return latestImage.Remote(), nil
+}
+
 type dockerDeployResult struct {
 	RemoteImageTag string
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	osexec "os/exec"
 	"strings"
 	"testing"
 
@@ -12,7 +13,9 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/cosign"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/azure/azure-dev/cli/azd/test/mocks/mockenv"
@@ -58,7 +61,7 @@ func Test_ContainerHelper_LocalImageTag(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			env := environment.NewWithValues("dev", map[string]string{})
-			containerHelper := NewContainerHelper(env, nil, clock.NewMock(), nil, nil, cloud.AzurePublic())
+			containerHelper := NewContainerHelper(env, nil, clock.NewMock(), nil, nil, nil, cloud.AzurePublic(), nil)
 			serviceConfig.Docker = tt.dockerConfig
 
 			tag, err := containerHelper.LocalImageTag(*mockContext.Context, serviceConfig)
@@ -107,7 +110,7 @@ func Test_ContainerHelper_RemoteImageTag(t *testing.T) {
 
 	mockContext := mocks.NewMockContext(context.Background())
 	env := environment.NewWithValues("dev", map[string]string{})
-	containerHelper := NewContainerHelper(env, nil, clock.NewMock(), nil, nil, cloud.AzurePublic())
+	containerHelper := NewContainerHelper(env, nil, clock.NewMock(), nil, nil, nil, cloud.AzurePublic(), nil)
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -134,7 +137,7 @@ func Test_ContainerHelper_Resolve_RegistryName(t *testing.T) {
 			environment.ContainerRegistryEndpointEnvVarName: "contoso.azurecr.io",
 		})
 		envManager := &mockenv.MockEnvManager{}
-		containerHelper := NewContainerHelper(env, envManager, clock.NewMock(), nil, nil, cloud.AzurePublic())
+		containerHelper := NewContainerHelper(env, envManager, clock.NewMock(), nil, nil, nil, cloud.AzurePublic(), nil)
 		serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
 		registryName, err := containerHelper.RegistryName(*mockContext.Context, serviceConfig)
 
@@ -146,7 +149,7 @@ func Test_ContainerHelper_Resolve_RegistryName(t *testing.T) {
 		mockContext := mocks.NewMockContext(context.Background())
 		env := environment.NewWithValues("dev", map[string]string{})
 		envManager := &mockenv.MockEnvManager{}
-		containerHelper := NewContainerHelper(env, envManager, clock.NewMock(), nil, nil, cloud.AzurePublic())
+		containerHelper := NewContainerHelper(env, envManager, clock.NewMock(), nil, nil, nil, cloud.AzurePublic(), nil)
 		serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
 		serviceConfig.Docker.Registry = osutil.NewExpandableString("contoso.azurecr.io")
 		registryName, err := containerHelper.RegistryName(*mockContext.Context, serviceConfig)
@@ -160,7 +163,7 @@ func Test_ContainerHelper_Resolve_RegistryName(t *testing.T) {
 		env := environment.NewWithValues("dev", map[string]string{})
 		env.DotenvSet("MY_CUSTOM_REGISTRY", "custom.azurecr.io")
 		envManager := &mockenv.MockEnvManager{}
-		containerHelper := NewContainerHelper(env, envManager, clock.NewMock(), nil, nil, cloud.AzurePublic())
+		containerHelper := NewContainerHelper(env, envManager, clock.NewMock(), nil, nil, nil, cloud.AzurePublic(), nil)
 		serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
 		serviceConfig.Docker.Registry = osutil.NewExpandableString("${MY_CUSTOM_REGISTRY}")
 		registryName, err := containerHelper.RegistryName(*mockContext.Context, serviceConfig)
@@ -173,7 +176,7 @@ func Test_ContainerHelper_Resolve_RegistryName(t *testing.T) {
 		mockContext := mocks.NewMockContext(context.Background())
 		env := environment.NewWithValues("dev", map[string]string{})
 		envManager := &mockenv.MockEnvManager{}
-		containerHelper := NewContainerHelper(env, envManager, clock.NewMock(), nil, nil, cloud.AzurePublic())
+		containerHelper := NewContainerHelper(env, envManager, clock.NewMock(), nil, nil, nil, cloud.AzurePublic(), nil)
 		serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
 		registryName, err := containerHelper.RegistryName(*mockContext.Context, serviceConfig)
 
@@ -297,6 +300,18 @@ func Test_ContainerHelper_Deploy(t *testing.T) {
 			expectDockerPushCalled:  true,
 			expectError:             false,
 		},
+		{
+			name:                    "Prebuilt image in registry",
+			project:                 "./src/api",
+			registry:                osutil.NewExpandableString("contoso.azurecr.io"),
+			packagePath:             "contoso.azurecr.io/my-project/my-service:azd-deploy-0",
+			expectedRemoteImage:     "contoso.azurecr.io/my-project/my-service:azd-deploy-0",
+			expectDockerLoginCalled: false,
+			expectDockerPullCalled:  false,
+			expectDockerTagCalled:   false,
+			expectDockerPushCalled:  false,
+			expectError:             false,
+		},
 		{
 			name:                    "Empty package details",
 			dockerDetails:           &dockerPackageResult{},
@@ -342,7 +357,9 @@ func Test_ContainerHelper_Deploy(t *testing.T) {
 				clock.NewMock(),
 				mockContainerRegistryService,
 				dockerCli,
+				nil,
 				cloud.AzurePublic(),
+				nil,
 			)
 			serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
 
@@ -399,10 +416,144 @@ func Test_ContainerHelper_Deploy(t *testing.T) {
 	}
 }
 
+// fakeCosignCli records the images signed and attested with cosign.
+type fakeCosignCli struct {
+	installed bool
+	signed    []string
+	attested  []string
+	options   []cosign.SignOptions
+}
+
+func (c *fakeCosignCli) CheckInstalled(ctx context.Context) error {
+	if !c.installed {
+		return osexec.ErrNotFound
+	}
+
+	return nil
+}
+
+func (c *fakeCosignCli) InstallUrl() string {
+	return "https://docs.sigstore.dev/system_config/installation"
+}
+
+func (c *fakeCosignCli) Name() string {
+	return "cosign"
+}
+
+func (c *fakeCosignCli) Sign(ctx context.Context, cwd string, image string, options cosign.SignOptions) error {
+	c.signed = append(c.signed, image)
+	c.options = append(c.options, options)
+	return nil
+}
+
+func (c *fakeCosignCli) Attest(
+	ctx context.Context,
+	cwd string,
+	image string,
+	predicatePath string,
+	predicateType string,
+	options cosign.SignOptions,
+) error {
+	c.attested = append(c.attested, fmt.Sprintf("%s %s %s", image, predicateType, predicatePath))
+	return nil
+}
+
+func Test_ContainerHelper_Deploy_Sign(t *testing.T) {
+	targetResource := environment.NewTargetResource(
+		"SUBSCRIPTION_ID",
+		"RESOURCE_GROUP",
+		"CONTAINER_APP",
+		"Microsoft.App/containerApps",
+	)
+	pushedDigest := "contoso.azurecr.io/my-project/my-service@sha256:0123456789abcdef"
+
+	deploy := func(t *testing.T, cosignCli *fakeCosignCli, sign *DockerSignOptions) (map[string]exec.RunArgs, error) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockResults := setupDockerMocks(mockContext)
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "docker image inspect")
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			mockResults["docker-inspect"] = args
+			// The image was also pushed to another registry before
+			return exec.NewRunResult(0, fmt.Sprintf(
+				`["other.azurecr.io/my-project/my-service@sha256:fedcba9876543210","%s"]`, pushedDigest), ""), nil
+		})
+
+		env := environment.NewWithValues("dev", map[string]string{"SIGNING_KEY": "azurekms://contoso.vault.azure.net/key"})
+		envManager := &mockenv.MockEnvManager{}
+		envManager.On("Save", *mockContext.Context, env).Return(nil)
+
+		mockContainerRegistryService := &mockContainerRegistryService{}
+		setupContainerRegistryMocks(mockContext, &mockContainerRegistryService.Mock)
+
+		containerHelper := NewContainerHelper(
+			env,
+			envManager,
+			clock.NewMock(),
+			mockContainerRegistryService,
+			docker.NewDocker(mockContext.CommandRunner),
+			nil,
+			cloud.AzurePublic(),
+			cosignCli,
+		)
+		serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
+		serviceConfig.Docker.Registry = osutil.NewExpandableString("contoso.azurecr.io")
+		serviceConfig.Docker.Sign = sign
+
+		packageOutput := &ServicePackageResult{
+			Details: &dockerPackageResult{
+				ImageHash:   "IMAGE_ID",
+				TargetImage: "my-project/my-service:azd-deploy-0",
+			},
+		}
+
+		deployTask := containerHelper.Deploy(*mockContext.Context, serviceConfig, packageOutput, targetResource, true)
+		logProgress(deployTask)
+		_, err := deployTask.Await()
+		return mockResults, err
+	}
+
+	t.Run("SignsPushedDigest", func(t *testing.T) {
+		cosignCli := &fakeCosignCli{installed: true}
+		_, err := deploy(t, cosignCli, &DockerSignOptions{
+			Key:  osutil.NewExpandableString("${SIGNING_KEY}"),
+			Sbom: "sbom.spdx.json",
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, []string{pushedDigest}, cosignCli.signed)
+		require.Equal(t, "azurekms://contoso.vault.azure.net/key", cosignCli.options[0].Key)
+
+		require.Equal(t, []string{pushedDigest + " spdxjson sbom.spdx.json"}, cosignCli.attested)
+	})
+
+	t.Run("NotSigned", func(t *testing.T) {
+		cosignCli := &fakeCosignCli{installed: true}
+		mockResults, err := deploy(t, cosignCli, nil)
+		require.NoError(t, err)
+		require.Empty(t, cosignCli.signed)
+		require.NotContains(t, mockResults, "docker-inspect")
+	})
+
+	t.Run("CosignMissing", func(t *testing.T) {
+		cosignCli := &fakeCosignCli{}
+		mockResults, err := deploy(t, cosignCli, &DockerSignOptions{})
+
+		var missingErr *tools.MissingToolsError
+		require.ErrorAs(t, err, &missingErr)
+		require.ErrorContains(t, err, "cosign")
+		require.ErrorContains(t, err, "signing the image of service 'api'")
+
+		// The image isn't pushed when it can't be signed
+		require.NotContains(t, mockResults, "docker-push")
+		require.Empty(t, cosignCli.signed)
+	})
+}
+
 func Test_ContainerHelper_ConfiguredImage(t *testing.T) {
 	mockContext := mocks.NewMockContext(context.Background())
 	env := environment.NewWithValues("dev", map[string]string{})
-	containerHelper := NewContainerHelper(env, nil, clock.NewMock(), nil, nil, cloud.AzurePublic())
+	containerHelper := NewContainerHelper(env, nil, clock.NewMock(), nil, nil, nil, cloud.AzurePublic(), nil)
 
 	tests := []struct {
 		name                 string
@@ -0,0 +1,134 @@
+package project
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+// RegistryKind is the kind of container registry images are pushed to.
+type RegistryKind string
+
+const (
+	// Azure Container Registry, logged into automatically with the current Azure credentials.
+	RegistryKindAzure RegistryKind = "acr"
+	// Docker Hub (docker.io).
+	RegistryKindDockerHub RegistryKind = "dockerhub"
+	// GitHub Container Registry (ghcr.io).
+	RegistryKindGitHub RegistryKind = "ghcr"
+	// Any other registry that supports 'docker login' with a username and password.
+	RegistryKindGeneric RegistryKind = "generic"
+)
+
+// Environment variables that provide credentials for registries other than Azure Container Registry.
+const (
+	RegistryUsernameEnvVarName = "AZD_REGISTRY_USERNAME"
+	RegistryPasswordEnvVarName = "AZD_REGISTRY_PASSWORD"
+)
+
+const dockerHubHost = "docker.io"
+
+// ContainerRegistry is a parsed container registry endpoint.
+type ContainerRegistry struct {
+	Kind RegistryKind
+	// Host is the normalized login server of the registry, e.g. 'ghcr.io' or 'myregistry.azurecr.io'.
+	Host string
+	// Namespace is the optional repository prefix following the host, e.g. 'my-org' in 'ghcr.io/my-org'.
+	Namespace string
+}
+
+// IsAzure returns true when the registry is an Azure Container Registry.
+func (r *ContainerRegistry) IsAzure() bool {
+	return r.Kind == RegistryKindAzure
+}
+
+// ParseContainerRegistry parses a registry endpoint as found in 'docker.registry' or AZURE_CONTAINER_REGISTRY_ENDPOINT.
+// Endpoints may contain a scheme, which is removed, and a namespace after the host. Names without a domain or port are
+// treated as Azure Container Registry names, as are hosts ending with the ACR endpoint suffix of the current cloud.
+func ParseContainerRegistry(endpoint string, acrEndpointSuffix string) (*ContainerRegistry, error) {
+	host := strings.TrimSpace(endpoint)
+	if strings.Contains(host, "://") {
+		parsed, err := url.Parse(host)
+		if err != nil {
+			return nil, fmt.Errorf("parsing container registry '%s': %w", endpoint, err)
+		}
+
+		host = parsed.Host + parsed.Path
+	}
+
+	host, namespace, _ := strings.Cut(strings.Trim(host, "/"), "/")
+	host = strings.ToLower(host)
+	if host == "" {
+		return nil, fmt.Errorf("container registry '%s' is not valid", endpoint)
+	}
+
+	switch {
+	case !strings.ContainsAny(host, ".:"):
+		return &ContainerRegistry{Kind: RegistryKindAzure, Host: host, Namespace: namespace}, nil
+	case acrEndpointSuffix != "" && strings.HasSuffix(host, acrEndpointSuffix):
+		return &ContainerRegistry{Kind: RegistryKindAzure, Host: host, Namespace: namespace}, nil
+	case host == dockerHubHost || host == "index.docker.io" || host == "registry-1.docker.io":
+		return &ContainerRegistry{Kind: RegistryKindDockerHub, Host: dockerHubHost, Namespace: namespace}, nil
+	case host == "ghcr.io":
+		return &ContainerRegistry{Kind: RegistryKindGitHub, Host: host, Namespace: namespace}, nil
+	default:
+		return &ContainerRegistry{Kind: RegistryKindGeneric, Host: host, Namespace: namespace}, nil
+	}
+}
+
+// registryCredentials selects the credentials used to log into a registry other than Azure Container Registry.
+// The first complete username and password pair found is used:
+//
+//  1. 'docker.username' and 'docker.password' from the service configuration.
+//  2. AZD_REGISTRY_USERNAME and AZD_REGISTRY_PASSWORD.
+//  3. Well known variables for the registry kind, DOCKER_USERNAME and DOCKER_PASSWORD for Docker Hub, and when
+//     forPush is set, GITHUB_ACTOR and GITHUB_TOKEN for GHCR.
+//
+// The GITHUB_TOKEN of a GitHub Actions workflow expires when the job ends, so it's only used to push images, and never
+// returned as credentials hosts keep pulling images with.
+//
+// nil is returned when no credentials are configured, in which case an existing 'docker login' session is relied on.
+func registryCredentials(
+	registry *ContainerRegistry,
+	options DockerProjectOptions,
+	getenv func(string) string,
+	forPush bool,
+) (*azcli.DockerCredentials, error) {
+	username, err := options.Username.Envsubst(getenv)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing 'username' from docker configuration, %w", err)
+	}
+
+	password, err := options.Password.Envsubst(getenv)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing 'password' from docker configuration, %w", err)
+	}
+
+	candidates := [][2]string{
+		{username, password},
+		{getenv(RegistryUsernameEnvVarName), getenv(RegistryPasswordEnvVarName)},
+	}
+
+	switch registry.Kind {
+	case RegistryKindGitHub:
+		if forPush {
+			candidates = append(candidates, [2]string{getenv("GITHUB_ACTOR"), getenv("GITHUB_TOKEN")})
+		}
+	case RegistryKindDockerHub:
+		candidates = append(candidates, [2]string{getenv("DOCKER_USERNAME"), getenv("DOCKER_PASSWORD")})
+	}
+
+	for _, candidate := range candidates {
+		if candidate[0] != "" && candidate[1] != "" {
+			return &azcli.DockerCredentials{
+				Username:    candidate[0],
+				Password:    candidate[1],
+				LoginServer: registry.Host,
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
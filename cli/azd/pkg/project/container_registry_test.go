@@ -0,0 +1,263 @@
+package project
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/cloud"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockenv"
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseContainerRegistry(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		expected ContainerRegistry
+	}{
+		{"contoso", ContainerRegistry{Kind: RegistryKindAzure, Host: "contoso"}},
+		{"contoso.azurecr.io", ContainerRegistry{Kind: RegistryKindAzure, Host: "contoso.azurecr.io"}},
+		{"https://Contoso.azurecr.io/", ContainerRegistry{Kind: RegistryKindAzure, Host: "contoso.azurecr.io"}},
+		{"docker.io", ContainerRegistry{Kind: RegistryKindDockerHub, Host: "docker.io"}},
+		{"index.docker.io/contoso", ContainerRegistry{Kind: RegistryKindDockerHub, Host: "docker.io", Namespace: "contoso"}},
+		{"registry-1.docker.io", ContainerRegistry{Kind: RegistryKindDockerHub, Host: "docker.io"}},
+		{"ghcr.io/contoso/apps", ContainerRegistry{Kind: RegistryKindGitHub, Host: "ghcr.io", Namespace: "contoso/apps"}},
+		{"registry.contoso.com", ContainerRegistry{Kind: RegistryKindGeneric, Host: "registry.contoso.com"}},
+		{"localhost:5000", ContainerRegistry{Kind: RegistryKindGeneric, Host: "localhost:5000"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			registry, err := ParseContainerRegistry(tt.endpoint, cloud.AzurePublic().ContainerRegistryEndpointSuffix)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, *registry)
+		})
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		_, err := ParseContainerRegistry(" / ", "azurecr.io")
+		require.Error(t, err)
+	})
+
+	t.Run("SovereignCloud", func(t *testing.T) {
+		registry, err := ParseContainerRegistry("contoso.azurecr.us", cloud.AzureGovernment().ContainerRegistryEndpointSuffix)
+		require.NoError(t, err)
+		require.True(t, registry.IsAzure())
+	})
+}
+
+func Test_RegistryCredentials(t *testing.T) {
+	ghcr := &ContainerRegistry{Kind: RegistryKindGitHub, Host: "ghcr.io"}
+	dockerHub := &ContainerRegistry{Kind: RegistryKindDockerHub, Host: "docker.io"}
+	generic := &ContainerRegistry{Kind: RegistryKindGeneric, Host: "registry.contoso.com"}
+
+	allEnv := map[string]string{
+		"REGISTRY_USER":            "config-user",
+		"REGISTRY_PASS":            "config-pass",
+		RegistryUsernameEnvVarName: "azd-user",
+		RegistryPasswordEnvVarName: "azd-pass",
+		"GITHUB_ACTOR":             "gh-user",
+		"GITHUB_TOKEN":             "gh-token",
+		"DOCKER_USERNAME":          "hub-user",
+		"DOCKER_PASSWORD":          "hub-pass",
+	}
+
+	configured := DockerProjectOptions{
+		Username: osutil.NewExpandableString("${REGISTRY_USER}"),
+		Password: osutil.NewExpandableString("${REGISTRY_PASS}"),
+	}
+
+	tests := []struct {
+		name     string
+		registry *ContainerRegistry
+		options  DockerProjectOptions
+		env      map[string]string
+		forPush  bool
+		expected *azcli.DockerCredentials
+	}{
+		{
+			name:     "ServiceConfiguration",
+			registry: ghcr,
+			options:  configured,
+			env:      allEnv,
+			expected: &azcli.DockerCredentials{Username: "config-user", Password: "config-pass", LoginServer: "ghcr.io"},
+		},
+		{
+			name:     "AzdEnvironmentVariables",
+			registry: ghcr,
+			env:      allEnv,
+			expected: &azcli.DockerCredentials{Username: "azd-user", Password: "azd-pass", LoginServer: "ghcr.io"},
+		},
+		{
+			name:     "GitHub",
+			registry: ghcr,
+			forPush:  true,
+			env: map[string]string{
+				RegistryUsernameEnvVarName: "incomplete",
+				"GITHUB_ACTOR":             "gh-user",
+				"GITHUB_TOKEN":             "gh-token",
+				"DOCKER_USERNAME":          "hub-user",
+				"DOCKER_PASSWORD":          "hub-pass",
+			},
+			expected: &azcli.DockerCredentials{Username: "gh-user", Password: "gh-token", LoginServer: "ghcr.io"},
+		},
+		{
+			name:     "GitHubNotUsedToPull",
+			registry: ghcr,
+			env: map[string]string{
+				RegistryUsernameEnvVarName: "incomplete",
+				"GITHUB_ACTOR":             "gh-user",
+				"GITHUB_TOKEN":             "gh-token",
+				"DOCKER_USERNAME":          "hub-user",
+				"DOCKER_PASSWORD":          "hub-pass",
+			},
+			expected: nil,
+		},
+		{
+			name:     "DockerHub",
+			registry: dockerHub,
+			env: map[string]string{
+				"GITHUB_ACTOR":    "gh-user",
+				"GITHUB_TOKEN":    "gh-token",
+				"DOCKER_USERNAME": "hub-user",
+				"DOCKER_PASSWORD": "hub-pass",
+			},
+			expected: &azcli.DockerCredentials{Username: "hub-user", Password: "hub-pass", LoginServer: "docker.io"},
+		},
+		{
+			name:     "GenericIgnoresWellKnownVariables",
+			registry: generic,
+			env: map[string]string{
+				"GITHUB_ACTOR":    "gh-user",
+				"GITHUB_TOKEN":    "gh-token",
+				"DOCKER_USERNAME": "hub-user",
+				"DOCKER_PASSWORD": "hub-pass",
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			credentials, err := registryCredentials(tt.registry, tt.options, func(name string) string {
+				return tt.env[name]
+			}, tt.forPush)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, credentials)
+		})
+	}
+}
+
+func Test_ContainerHelper_ValidateRegistry(t *testing.T) {
+	tests := []struct {
+		name             string
+		registry         string
+		env              map[string]string
+		expectLoginCalls bool
+	}{
+		{
+			name:     "ExternalWithCredentials",
+			registry: "ghcr.io/contoso",
+			env: map[string]string{
+				RegistryUsernameEnvVarName: "user",
+				RegistryPasswordEnvVarName: "pass",
+			},
+			expectLoginCalls: true,
+		},
+		{
+			name:     "ExternalWithoutCredentials",
+			registry: "registry.contoso.com",
+			env:      map[string]string{},
+		},
+		{
+			name:     "Azure",
+			registry: "contoso.azurecr.io",
+			env: map[string]string{
+				RegistryUsernameEnvVarName: "user",
+				RegistryPasswordEnvVarName: "pass",
+			},
+		},
+		{
+			name: "NotProvisioned",
+			env:  map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockContext := mocks.NewMockContext(context.Background())
+			mockResults := setupDockerMocks(mockContext)
+			env := environment.NewWithValues("dev", tt.env)
+
+			containerHelper := NewContainerHelper(
+				env,
+				&mockenv.MockEnvManager{},
+				clock.NewMock(),
+				&mockContainerRegistryService{},
+				docker.NewDocker(mockContext.CommandRunner),
+				nil,
+				cloud.AzurePublic(),
+				nil,
+			)
+			serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
+			serviceConfig.Docker.Registry = osutil.NewExpandableString(tt.registry)
+
+			err := containerHelper.ValidateRegistry(*mockContext.Context, serviceConfig)
+			require.NoError(t, err)
+
+			loginArgs, loginCalled := mockResults["docker-login"]
+			require.Equal(t, tt.expectLoginCalls, loginCalled)
+			if loginCalled {
+				require.Equal(t, []string{"login", "--username", "user", "--password-stdin", "ghcr.io"}, loginArgs.Args)
+			}
+		})
+	}
+}
+
+func Test_ContainerHelper_LoginExternalOnce(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	setupDockerMocks(mockContext)
+
+	loginCalls := 0
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "docker login")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		loginCalls++
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	env := environment.NewWithValues("dev", map[string]string{
+		"GITHUB_ACTOR": "gh-user",
+		"GITHUB_TOKEN": "gh-token",
+	})
+
+	containerHelper := NewContainerHelper(
+		env,
+		&mockenv.MockEnvManager{},
+		clock.NewMock(),
+		&mockContainerRegistryService{},
+		docker.NewDocker(mockContext.CommandRunner),
+		nil,
+		cloud.AzurePublic(),
+		nil,
+	)
+	serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
+	serviceConfig.Docker.Registry = osutil.NewExpandableString("ghcr.io/contoso")
+
+	require.NoError(t, containerHelper.ValidateRegistry(*mockContext.Context, serviceConfig))
+	_, err := containerHelper.Login(*mockContext.Context, serviceConfig)
+	require.NoError(t, err)
+	require.Equal(t, 1, loginCalls)
+
+	// The job scoped GITHUB_TOKEN is used to push, but never handed to hosts to pull the image with
+	credentials, err := containerHelper.PullCredentials(*mockContext.Context, serviceConfig)
+	require.NoError(t, err)
+	require.Nil(t, credentials)
+}
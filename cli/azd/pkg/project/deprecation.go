@@ -0,0 +1,92 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/warnings"
+	"gopkg.in/yaml.v3"
+)
+
+// deprecatedField is a field of azure.yaml that is replaced by another field and will be removed from the schema.
+type deprecatedField struct {
+	// Path is the path of the field in azure.yaml, where '*' matches any key, like 'services.*.module'.
+	Path string
+	// Replacement is the path of the field replacing the deprecated one.
+	Replacement string
+	// Hint describes how to migrate from the deprecated field to its replacement.
+	Hint string
+	// Removal is when the field stops being accepted.
+	Removal string
+}
+
+// deprecatedFields is the registry of the deprecated fields of azure.yaml, warned about when the project is parsed.
+var deprecatedFields = []deprecatedField{
+	{
+		Path:        "services.*.module",
+		Replacement: "infra.module",
+		Hint:        "it's ignored, the infrastructure of all the services is provisioned from the module of the project",
+		Removal:     "the next major version of azd",
+	},
+}
+
+// warnDeprecatedFields adds a warning, with the migration hint, for each deprecated field set in the azure.yaml
+// content to the warnings sink of ctx. Without a sink, the warnings are logged.
+func warnDeprecatedFields(ctx context.Context, yamlContent string) {
+	var document map[string]any
+	if err := yaml.Unmarshal([]byte(yamlContent), &document); err != nil {
+		// Errors are reported when parsing the project itself
+		return
+	}
+
+	for _, field := range deprecatedFields {
+		for _, path := range findFieldPaths(document, strings.Split(field.Path, "."), nil) {
+			warning := warnings.Warning{
+				Code: warnings.CodeDeprecatedField,
+				Message: fmt.Sprintf(
+					"'%s' in azure.yaml is deprecated and will be removed in %s: %s. Use '%s' instead.",
+					path,
+					field.Removal,
+					field.Hint,
+					field.Replacement,
+				),
+			}
+
+			if !warnings.Add(ctx, warning) {
+				log.Println(warning.Message)
+			}
+		}
+	}
+}
+
+// findFieldPaths returns the sorted paths of the fields of node matching the segments of a deprecated field path.
+func findFieldPaths(node any, segments []string, prefix []string) []string {
+	values, ok := node.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	var paths []string
+	for key, value := range values {
+		if segments[0] != "*" && segments[0] != key {
+			continue
+		}
+
+		path := append(slices.Clone(prefix), key)
+		if len(segments) == 1 {
+			paths = append(paths, strings.Join(path, "."))
+			continue
+		}
+
+		paths = append(paths, findFieldPaths(value, segments[1:], path)...)
+	}
+
+	slices.Sort(paths)
+	return paths
+}
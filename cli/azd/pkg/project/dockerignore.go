@@ -0,0 +1,221 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/azure/azure-dev/cli/azd/pkg/warnings"
+)
+
+// largeDockerContextSize is the size of a build context above which sending it to the docker daemon noticeably slows
+// down builds.
+const largeDockerContextSize int64 = 100 * 1024 * 1024
+
+// heavyDockerContextDirectories are directories commonly found in projects which are large and rarely needed by the
+// build, since dependencies and build outputs are restored or built inside the image.
+var heavyDockerContextDirectories = []string{
+	".git",
+	"node_modules",
+	".venv",
+	"venv",
+	"__pycache__",
+	"bin",
+	"obj",
+}
+
+// defaultDockerignore is the .dockerignore generated when none excludes the heavy directories of a build context.
+const defaultDockerignore = `# Generated by azd. Excludes directories rebuilt inside the image from the build context.
+**/.git
+**/node_modules
+**/.venv
+**/venv
+**/__pycache__
+**/bin
+**/obj
+**/.azure
+`
+
+// checkDockerignore warns when the build context is large and contains heavy directories, like 'node_modules' or
+// '.git', that no .dockerignore excludes, offering to generate a default .dockerignore. The check never fails the
+// build: errors are logged and ignored.
+func (p *dockerProject) checkDockerignore(ctx context.Context, buildContext string, dockerfilePath string) {
+	missing, size, err := unignoredHeavyDirectories(buildContext, dockerfilePath)
+	if err != nil {
+		log.Printf("checking .dockerignore of build context '%s': %v", buildContext, err)
+		return
+	}
+
+	if len(missing) == 0 || size < largeDockerContextSize {
+		return
+	}
+
+	warning := warnings.Warning{
+		Code: warnings.CodeDockerignore,
+		Message: fmt.Sprintf(
+			"The docker build context '%s' is larger than %d MB and its .dockerignore doesn't exclude %s, "+
+				"which slows down builds.",
+			buildContext,
+			largeDockerContextSize/(1024*1024),
+			strings.Join(missing, ", "),
+		),
+	}
+
+	dockerignorePath := filepath.Join(buildContext, ".dockerignore")
+	if _, err := os.Stat(dockerignorePath); err == nil {
+		// Never overwrite the exclusions of an existing .dockerignore. The warning is rendered once the operation
+		// completes when warnings are collected.
+		if sink, has := warnings.FromContext(ctx); has {
+			sink.Add(warning)
+		} else {
+			p.console.MessageUxItem(ctx, &ux.WarningMessage{Description: warning.Message})
+		}
+		return
+	}
+
+	// The warning explains the prompt, so it's shown before prompting even when warnings are collected
+	p.console.MessageUxItem(ctx, &ux.WarningMessage{Description: warning.Message})
+
+	generate, err := p.console.Confirm(ctx, input.ConsoleOptions{
+		Message: fmt.Sprintf(
+			"Generate a default %s excluding %s?",
+			output.WithHighLightFormat(dockerignorePath),
+			strings.Join(missing, ", "),
+		),
+		DefaultValue: false,
+	})
+	if err != nil {
+		log.Printf("prompting to generate .dockerignore: %v", err)
+		return
+	}
+
+	if !generate {
+		return
+	}
+
+	if err := os.WriteFile(dockerignorePath, []byte(defaultDockerignore), osutil.PermissionFile); err != nil {
+		log.Printf("generating .dockerignore: %v", err)
+	}
+}
+
+// unignoredHeavyDirectories returns the heavy directories at the root of the build context that aren't excluded by
+// its .dockerignore, along with the size of the build context. The size is only computed up to
+// largeDockerContextSize.
+func unignoredHeavyDirectories(buildContext string, dockerfilePath string) ([]string, int64, error) {
+	patterns, err := readDockerignore(buildContext, dockerfilePath)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var missing []string
+	for _, dir := range heavyDockerContextDirectories {
+		info, err := os.Stat(filepath.Join(buildContext, dir))
+		if err != nil || !info.IsDir() {
+			continue
+		}
+
+		if !dockerignoreExcludes(patterns, dir) {
+			missing = append(missing, dir)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil, 0, nil
+	}
+
+	var size int64
+	errLargeContext := errors.New("large build context")
+	err = filepath.WalkDir(buildContext, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+
+			size += info.Size()
+			if size >= largeDockerContextSize {
+				return errLargeContext
+			}
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errLargeContext) {
+		return nil, 0, err
+	}
+
+	return missing, size, nil
+}
+
+// readDockerignore reads the patterns of the .dockerignore used by docker for the build, either the one next to the
+// Dockerfile, like 'Dockerfile.dockerignore', or the one at the root of the build context.
+func readDockerignore(buildContext string, dockerfilePath string) ([]string, error) {
+	candidates := []string{filepath.Join(buildContext, ".dockerignore")}
+	if dockerfilePath != "" {
+		candidates = append([]string{dockerfilePath + ".dockerignore"}, candidates...)
+	}
+
+	for _, candidate := range candidates {
+		file, err := os.Open(candidate)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		var patterns []string
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			patterns = append(patterns, line)
+		}
+
+		return patterns, scanner.Err()
+	}
+
+	return nil, nil
+}
+
+// dockerignoreExcludes returns true when one of the patterns excludes the directory at the root of the build context.
+func dockerignoreExcludes(patterns []string, dir string) bool {
+	excluded := false
+	for _, pattern := range patterns {
+		negated := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(pattern, "!")
+		pattern = filepath.ToSlash(strings.TrimSpace(pattern))
+		pattern = strings.TrimPrefix(pattern, "/")
+		pattern = strings.TrimPrefix(pattern, "**/")
+		pattern = strings.TrimSuffix(pattern, "/**")
+		pattern = strings.TrimSuffix(pattern, "/")
+
+		if matched, err := filepath.Match(pattern, dir); err == nil && matched {
+			// Later patterns take precedence, like in docker
+			excluded = !negated
+		}
+	}
+
+	return excluded
+}
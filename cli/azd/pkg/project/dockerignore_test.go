@@ -0,0 +1,178 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/warnings"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockinput"
+	"github.com/stretchr/testify/require"
+)
+
+// createBuildContext creates a build context with a 'node_modules' directory of the given size.
+func createBuildContext(t *testing.T, nodeModulesSize int64) string {
+	buildContext := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(buildContext, "node_modules", "pkg"), osutil.PermissionDirectory))
+	require.NoError(t, os.WriteFile(filepath.Join(buildContext, "Dockerfile"), []byte("FROM node:18"), osutil.PermissionFile))
+
+	// Sparse file, so large contexts don't use disk space
+	file, err := os.Create(filepath.Join(buildContext, "node_modules", "pkg", "index.js"))
+	require.NoError(t, err)
+	require.NoError(t, file.Truncate(nodeModulesSize))
+	require.NoError(t, file.Close())
+
+	return buildContext
+}
+
+func Test_DockerProject_CheckDockerignore(t *testing.T) {
+	tests := []struct {
+		name              string
+		nodeModulesSize   int64
+		dockerignore      string
+		confirmGenerate   bool
+		expectWarning     bool
+		expectGeneratedTo bool
+	}{
+		{
+			name:            "LargeContextWithoutDockerignore",
+			nodeModulesSize: largeDockerContextSize + 1,
+			expectWarning:   true,
+		},
+		{
+			name:              "LargeContextGenerateDockerignore",
+			nodeModulesSize:   largeDockerContextSize + 1,
+			confirmGenerate:   true,
+			expectWarning:     true,
+			expectGeneratedTo: true,
+		},
+		{
+			name:            "LargeContextWithDockerignore",
+			nodeModulesSize: largeDockerContextSize + 1,
+			dockerignore:    "# dependencies\n/node_modules/\n",
+		},
+		{
+			name:            "LargeContextWithNegatedDockerignore",
+			nodeModulesSize: largeDockerContextSize + 1,
+			dockerignore:    "**/node_modules\n!node_modules\n",
+			expectWarning:   true,
+		},
+		{
+			name:            "SmallContextWithoutDockerignore",
+			nodeModulesSize: 1024,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buildContext := createBuildContext(t, tt.nodeModulesSize)
+			dockerignorePath := filepath.Join(buildContext, ".dockerignore")
+			if tt.dockerignore != "" {
+				require.NoError(t, os.WriteFile(dockerignorePath, []byte(tt.dockerignore), osutil.PermissionFile))
+			}
+
+			console := mockinput.NewMockConsole()
+			confirmed := false
+			console.WhenConfirm(func(options input.ConsoleOptions) bool {
+				return strings.Contains(options.Message, "Generate a default")
+			}).RespondFn(func(options input.ConsoleOptions) (any, error) {
+				// The warning explaining the prompt is shown before prompting
+				require.Contains(t, strings.Join(console.Output(), "\n"), "doesn't exclude node_modules")
+				confirmed = true
+				return tt.confirmGenerate, nil
+			})
+
+			dockerProject := &dockerProject{console: console}
+			dockerProject.checkDockerignore(
+				context.Background(), buildContext, filepath.Join(buildContext, "Dockerfile"))
+
+			consoleOutput := strings.Join(console.Output(), "\n")
+			if tt.expectWarning {
+				require.Contains(t, consoleOutput, "doesn't exclude node_modules")
+			} else {
+				require.Empty(t, consoleOutput)
+			}
+
+			// Generating a .dockerignore is only offered when there is none
+			require.Equal(t, tt.expectWarning && tt.dockerignore == "", confirmed)
+
+			if tt.expectGeneratedTo {
+				content, err := os.ReadFile(dockerignorePath)
+				require.NoError(t, err)
+				require.Equal(t, defaultDockerignore, string(content))
+			} else if tt.dockerignore == "" {
+				require.NoFileExists(t, dockerignorePath)
+			}
+		})
+	}
+}
+
+func Test_CheckDockerignore_CollectsWarning(t *testing.T) {
+	t.Run("ExistingDockerignore", func(t *testing.T) {
+		buildContext := createBuildContext(t, largeDockerContextSize+1)
+		require.NoError(t, os.WriteFile(
+			filepath.Join(buildContext, ".dockerignore"), []byte("bin\n"), osutil.PermissionFile))
+
+		sink := warnings.NewSink()
+		console := mockinput.NewMockConsole()
+		dockerProject := &dockerProject{console: console}
+		dockerProject.checkDockerignore(
+			warnings.WithSink(context.Background(), sink), buildContext, filepath.Join(buildContext, "Dockerfile"))
+
+		// The warning is collected with the warnings of the operation instead of being shown inline
+		require.NotContains(t, strings.Join(console.Output(), "\n"), "doesn't exclude")
+		require.Len(t, sink.Warnings(), 1)
+		require.Equal(t, warnings.CodeDockerignore, sink.Warnings()[0].Code)
+		require.Contains(t, sink.Warnings()[0].Message, "doesn't exclude node_modules")
+	})
+
+	t.Run("Prompted", func(t *testing.T) {
+		buildContext := createBuildContext(t, largeDockerContextSize+1)
+
+		console := mockinput.NewMockConsole()
+		console.WhenConfirm(func(options input.ConsoleOptions) bool {
+			return strings.Contains(options.Message, "Generate a default")
+		}).RespondFn(func(options input.ConsoleOptions) (any, error) {
+			require.Contains(t, strings.Join(console.Output(), "\n"), "doesn't exclude node_modules")
+			return false, nil
+		})
+
+		sink := warnings.NewSink()
+		dockerProject := &dockerProject{console: console}
+		dockerProject.checkDockerignore(
+			warnings.WithSink(context.Background(), sink), buildContext, filepath.Join(buildContext, "Dockerfile"))
+
+		// The warning is shown before the prompt it explains, instead of once the operation completes
+		require.Empty(t, sink.Warnings())
+	})
+}
+
+func Test_DockerignoreExcludes(t *testing.T) {
+	require.True(t, dockerignoreExcludes([]string{"node_modules"}, "node_modules"))
+	require.True(t, dockerignoreExcludes([]string{"**/.git"}, ".git"))
+	require.True(t, dockerignoreExcludes([]string{"/bin/**"}, "bin"))
+	require.True(t, dockerignoreExcludes([]string{"*"}, "obj"))
+	require.False(t, dockerignoreExcludes([]string{"node_modules/.cache"}, "node_modules"))
+	require.False(t, dockerignoreExcludes([]string{".git", "!.git"}, ".git"))
+	require.False(t, dockerignoreExcludes(nil, ".venv"))
+}
+
+func Test_ReadDockerignore_DockerfileSpecific(t *testing.T) {
+	buildContext := createBuildContext(t, 0)
+	dockerfilePath := filepath.Join(buildContext, "Dockerfile")
+	require.NoError(t, os.WriteFile(
+		filepath.Join(buildContext, ".dockerignore"), []byte("bin\n"), osutil.PermissionFile))
+	require.NoError(t, os.WriteFile(dockerfilePath+".dockerignore", []byte("node_modules\n"), osutil.PermissionFile))
+
+	// The .dockerignore of the Dockerfile takes precedence over the one of the build context, like in docker
+	patterns, err := readDockerignore(buildContext, dockerfilePath)
+	require.NoError(t, err)
+	require.Equal(t, []string{"node_modules"}, patterns)
+}
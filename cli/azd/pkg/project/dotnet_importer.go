@@ -222,7 +222,7 @@ func (ai *DotNetImporter) Services(
 			Host:         DotNetContainerAppTarget,
 			Docker: DockerProjectOptions{
 				Path:      dockerfile.Path,
-				Context:   dockerfile.Context,
+				Context:   osutil.NewExpandableString(dockerfile.Context),
 				BuildArgs: mapToStringSlice(dockerfile.BuildArgs, "="),
 			},
 		}
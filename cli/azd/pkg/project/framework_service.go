@@ -48,6 +48,17 @@ func parseServiceLanguage(kind ServiceLanguageKind) (ServiceLanguageKind, error)
 	return ServiceLanguageKind("Unsupported"), fmt.Errorf("unsupported language '%s'", kind)
 }
 
+// ParseLanguageFilter parses the language selecting the services of a command, like `azd restore --language python`.
+func ParseLanguageFilter(name string) (ServiceLanguageKind, error) {
+	language, err := parseServiceLanguage(ServiceLanguageKind(name))
+	if err != nil || language == ServiceLanguageNone {
+		return ServiceLanguageNone, fmt.Errorf(
+			"unsupported language '%s', expected one of: dotnet, csharp, fsharp, js, ts, python, java", name)
+	}
+
+	return language, nil
+}
+
 type FrameworkRequirements struct {
 	Package FrameworkPackageRequirements
 }
@@ -33,14 +33,52 @@ import (
 )
 
 type DockerProjectOptions struct {
-	Path      string                  `yaml:"path,omitempty"      json:"path,omitempty"`
-	Context   string                  `yaml:"context,omitempty"   json:"context,omitempty"`
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+	// Dockerfile is the path of the Dockerfile, taking precedence over Path. Unlike Path, building fails when it doesn't
+	// exist rather than building the image from source.
+	Dockerfile osutil.ExpandableString `yaml:"dockerfile,omitempty" json:"dockerfile,omitempty"`
+	// Context is the path of the build context. Relative paths, like the one of the Dockerfile, are resolved from the
+	// service path, or from the project path when they don't exist relative to the service.
+	Context   osutil.ExpandableString `yaml:"context,omitempty"   json:"context,omitempty"`
 	Platform  string                  `yaml:"platform,omitempty"  json:"platform,omitempty"`
 	Target    string                  `yaml:"target,omitempty"    json:"target,omitempty"`
 	Registry  osutil.ExpandableString `yaml:"registry,omitempty"  json:"registry,omitempty"`
 	Image     osutil.ExpandableString `yaml:"image,omitempty"     json:"image,omitempty"`
 	Tag       osutil.ExpandableString `yaml:"tag,omitempty"       json:"tag,omitempty"`
 	BuildArgs []string                `yaml:"buildArgs,omitempty" json:"buildArgs,omitempty"`
+	// VersionFile is the file read by the 'semver' tag strategy, relative to the service path. Defaults to VERSION.
+	VersionFile string `yaml:"versionFile,omitempty" json:"versionFile,omitempty"`
+	// PushLatest additionally tags and pushes the image as 'latest'.
+	PushLatest bool `yaml:"pushLatest,omitempty" json:"pushLatest,omitempty"`
+	// Push copies the existing image set by the image of the service to the container registry before deploying it.
+	// When false, the image is deployed directly from its registry, without being pulled. Defaults to true.
+	Push *bool `yaml:"push,omitempty" json:"push,omitempty"`
+	// Username and Password are used to log into registries other than Azure Container Registry.
+	Username osutil.ExpandableString `yaml:"username,omitempty" json:"username,omitempty"`
+	Password osutil.ExpandableString `yaml:"password,omitempty" json:"password,omitempty"`
+	// Sign signs the image with cosign after it's pushed to the container registry.
+	Sign *DockerSignOptions `yaml:"sign,omitempty" json:"sign,omitempty"`
+	// Mirror is the registry base images from Docker Hub are pulled through, like an Azure Container Registry with cache
+	// rules for Docker Hub. The FROM instructions of the Dockerfile are rewritten to the mirror for the build.
+	Mirror osutil.ExpandableString `yaml:"mirror,omitempty" json:"mirror,omitempty"`
+}
+
+// DockerSignOptions configures signing the image of a service with cosign, by the digest it was pushed with.
+type DockerSignOptions struct {
+	// Key is the cosign key reference, like a key file or a KMS URI. The image is signed keyless when empty.
+	Key osutil.ExpandableString `yaml:"key,omitempty" json:"key,omitempty"`
+	// IdentityToken is the OIDC identity token used to sign the image keyless, like one issued to a CI workflow.
+	IdentityToken osutil.ExpandableString `yaml:"identityToken,omitempty" json:"identityToken,omitempty"`
+	// Sbom is the path of an SBOM of the image, relative to the service path, attested to the image once it's signed.
+	Sbom string `yaml:"sbom,omitempty" json:"sbom,omitempty"`
+	// SbomType is the cosign predicate type of the SBOM. Defaults to spdxjson.
+	SbomType string `yaml:"sbomType,omitempty" json:"sbomType,omitempty"`
+}
+
+// pushImage returns whether the existing image of the service is copied to the container registry before being
+// deployed.
+func (o DockerProjectOptions) pushImage() bool {
+	return o.Push == nil || *o.Push
 }
 
 type dockerBuildResult struct {
@@ -68,6 +106,8 @@ type dockerPackageResult struct {
 	SourceImage string `json:"sourceImage"`
 	// The target image with tag that is used for publishing and deployment when targeting a container registry
 	TargetImage string `json:"targetImage"`
+	// The packages of the additional containers of a container app built by azd, by container name
+	Containers map[string]*dockerPackageResult `json:"containers,omitempty"`
 }
 
 func (dpr *dockerPackageResult) ToString(currentIndentation string) string {
@@ -200,17 +240,35 @@ func (p *dockerProject) Build(
 				return
 			}
 
+			// Surface registry connectivity and credential problems before spending time on the build
+			if err := p.containerHelper.ValidateRegistry(ctx, serviceConfig); err != nil {
+				task.SetError(err)
+				return
+			}
+
 			buildArgs := []string{}
 			for _, arg := range dockerOptions.BuildArgs {
 				buildArgs = append(buildArgs, exec.RedactSensitiveData(arg))
 			}
 
+			dockerfilePath, dockerfileExists, err := p.resolveDockerfile(serviceConfig, dockerOptions)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			buildContext, err := p.resolveBuildContext(serviceConfig, dockerOptions)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
 			log.Printf(
 				"building image for service %s, cwd: %s, path: %s, context: %s, buildArgs: %s)",
 				serviceConfig.Name,
 				serviceConfig.Path(),
-				dockerOptions.Path,
-				dockerOptions.Context,
+				dockerfilePath,
+				buildContext,
 				buildArgs,
 			)
 
@@ -220,20 +278,9 @@ func (p *dockerProject) Build(
 				strings.ToLower(serviceConfig.Name),
 			)
 
-			path := dockerOptions.Path
-			if !filepath.IsAbs(path) {
-				path = filepath.Join(serviceConfig.Path(), path)
-			}
-
-			_, err := os.Stat(path)
-			if err != nil && !errors.Is(err, os.ErrNotExist) {
-				task.SetError(fmt.Errorf("reading dockerfile: %w", err))
-				return
-			}
-
-			if errors.Is(err, os.ErrNotExist) {
+			if !dockerfileExists {
 				// Build the container from source
-				task.SetProgress(NewServiceProgress("Building Docker image from source"))
+				task.SetProgress(NewServicePhaseProgress(ProgressPhaseBuildingImage, "Building Docker image from source"))
 				res, err := p.packBuild(ctx, serviceConfig, dockerOptions, imageName)
 				if err != nil {
 					task.SetError(err)
@@ -245,8 +292,18 @@ func (p *dockerProject) Build(
 				return
 			}
 
+			p.checkDockerignore(
+				ctx, resolvePathFrom(serviceConfig.Path(), buildContext), resolvePathFrom(serviceConfig.Path(), dockerfilePath))
+
+			dockerfilePath, removeMirroredDockerfile, err := p.mirrorDockerfile(serviceConfig, dockerOptions, dockerfilePath)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+			defer removeMirroredDockerfile()
+
 			// Build the container
-			task.SetProgress(NewServiceProgress("Building Docker image"))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhaseBuildingImage, "Building Docker image"))
 			previewerWriter := p.console.ShowPreviewer(ctx,
 				&input.ShowPreviewerOptions{
 					Prefix:       "  ",
@@ -256,17 +313,17 @@ func (p *dockerProject) Build(
 			imageId, err := p.docker.Build(
 				ctx,
 				serviceConfig.Path(),
-				dockerOptions.Path,
+				dockerfilePath,
 				dockerOptions.Platform,
 				dockerOptions.Target,
-				dockerOptions.Context,
+				buildContext,
 				imageName,
 				dockerOptions.BuildArgs,
 				previewerWriter,
 			)
 			p.console.StopPreviewer(ctx, false)
 			if err != nil {
-				task.SetError(fmt.Errorf("building container: %s at %s: %w", serviceConfig.Name, dockerOptions.Context, err))
+				task.SetError(fmt.Errorf("building container: %s at %s: %w", serviceConfig.Name, buildContext, err))
 				return
 			}
 
@@ -283,6 +340,44 @@ func (p *dockerProject) Build(
 	)
 }
 
+// mirrorDockerfile returns the path of a copy of the Dockerfile pulling its Docker Hub base images through the mirror of
+// the docker options, and a function removing the copy once the image is built. The Dockerfile is used as is when no
+// mirror is set.
+func (p *dockerProject) mirrorDockerfile(
+	serviceConfig *ServiceConfig,
+	dockerOptions DockerProjectOptions,
+	dockerfilePath string,
+) (string, func(), error) {
+	mirror, err := dockerOptions.Mirror.Envsubst(p.env.Getenv)
+	if err != nil {
+		return "", nil, fmt.Errorf("expanding docker.mirror: %w", err)
+	}
+
+	if mirror == "" {
+		return dockerfilePath, func() {}, nil
+	}
+
+	dockerfile, err := os.ReadFile(resolvePathFrom(serviceConfig.Path(), dockerfilePath))
+	if err != nil {
+		return "", nil, fmt.Errorf("reading dockerfile: %w", err)
+	}
+
+	mirroredDockerfile, err := os.CreateTemp("", "azd-mirrored-*.Dockerfile")
+	if err != nil {
+		return "", nil, fmt.Errorf("creating mirrored dockerfile: %w", err)
+	}
+	defer mirroredDockerfile.Close()
+
+	remove := func() { os.Remove(mirroredDockerfile.Name()) }
+	if _, err := mirroredDockerfile.WriteString(docker.MirrorBaseImages(string(dockerfile), mirror)); err != nil {
+		remove()
+		return "", nil, fmt.Errorf("writing mirrored dockerfile: %w", err)
+	}
+
+	log.Printf("pulling the docker hub base images of %s through %s", dockerfilePath, mirror)
+	return mirroredDockerfile.Name(), remove, nil
+}
+
 func (p *dockerProject) Package(
 	ctx context.Context,
 	serviceConfig *ServiceConfig,
@@ -310,7 +405,19 @@ func (p *dockerProject) Package(
 
 				remoteImageUrl := sourceImage.Remote()
 
-				task.SetProgress(NewServiceProgress("Pulling container source image"))
+				// An image deployed directly from its registry doesn't need to be pulled and tagged
+				if !serviceConfig.Docker.pushImage() {
+					packageDetails.SourceImage = remoteImageUrl
+					packageDetails.TargetImage = remoteImageUrl
+					task.SetResult(&ServicePackageResult{
+						Build:       buildOutput,
+						PackagePath: remoteImageUrl,
+						Details:     packageDetails,
+					})
+					return
+				}
+
+				task.SetProgress(NewServicePhaseProgress(ProgressPhasePullingImage, "Pulling container source image"))
 				if err := p.docker.Pull(ctx, remoteImageUrl); err != nil {
 					task.SetError(fmt.Errorf("pulling source container image: %w", err))
 					return
@@ -329,7 +436,7 @@ func (p *dockerProject) Package(
 
 			// Tag image.
 			log.Printf("tagging image %s as %s", imageId, imageWithTag)
-			task.SetProgress(NewServiceProgress("Tagging container image"))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhasePushingImage, "Tagging container image"))
 			if err := p.docker.Tag(ctx, serviceConfig.Path(), imageId, imageWithTag); err != nil {
 				task.SetError(fmt.Errorf("tagging image: %w", err))
 				return
@@ -511,9 +618,108 @@ func getDockerOptionsWithDefaults(options DockerProjectOptions) DockerProjectOpt
 		options.Platform = docker.DefaultPlatform
 	}
 
-	if options.Context == "" {
-		options.Context = "."
+	if options.Context.Empty() {
+		options.Context = osutil.NewExpandableString(".")
 	}
 
 	return options
 }
+
+// resolveDockerfile returns the path of the Dockerfile of the service, and whether it exists. The path is returned
+// as configured when it exists relative to the service path, the directory docker runs from, and made absolute
+// otherwise.
+func (p *dockerProject) resolveDockerfile(
+	serviceConfig *ServiceConfig,
+	dockerOptions DockerProjectOptions,
+) (string, bool, error) {
+	if dockerOptions.Dockerfile.Empty() {
+		path, exists, err := resolveServicePath(serviceConfig, dockerOptions.Path)
+		if err != nil {
+			return "", false, fmt.Errorf("reading dockerfile: %w", err)
+		}
+
+		return path, exists, nil
+	}
+
+	dockerfile, err := dockerOptions.Dockerfile.Envsubst(p.env.Getenv)
+	if err != nil {
+		return "", false, fmt.Errorf("expanding dockerfile path: %w", err)
+	}
+
+	path, exists, err := resolveServicePath(serviceConfig, dockerfile)
+	if err != nil {
+		return "", false, fmt.Errorf("reading dockerfile: %w", err)
+	}
+	if !exists {
+		return "", false, fmt.Errorf(
+			"dockerfile '%s' of service '%s' not found relative to the service path '%s' or the project path '%s'",
+			dockerfile, serviceConfig.Name, serviceConfig.Path(), serviceConfig.Project.Path)
+	}
+
+	return path, true, nil
+}
+
+// resolveBuildContext returns the path of the docker build context of the service, as configured when it exists
+// relative to the service path, and made absolute otherwise.
+func (p *dockerProject) resolveBuildContext(
+	serviceConfig *ServiceConfig,
+	dockerOptions DockerProjectOptions,
+) (string, error) {
+	buildContext, err := dockerOptions.Context.Envsubst(p.env.Getenv)
+	if err != nil {
+		return "", fmt.Errorf("expanding docker build context: %w", err)
+	}
+
+	path, exists, err := resolveServicePath(serviceConfig, buildContext)
+	if err != nil {
+		return "", fmt.Errorf("reading docker build context: %w", err)
+	}
+	if !exists {
+		return "", fmt.Errorf(
+			"docker build context '%s' of service '%s' not found relative to the service path '%s' or the project path '%s'",
+			buildContext, serviceConfig.Name, serviceConfig.Path(), serviceConfig.Project.Path)
+	}
+
+	return path, nil
+}
+
+// resolvePathFrom returns path when absolute, or joined to dir otherwise.
+func resolvePathFrom(dir string, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(dir, path)
+}
+
+// resolveServicePath resolves a path configured for a service, relative to the service path first and then to the
+// project path. The path is returned unchanged when absolute or found relative to the service path.
+func resolveServicePath(serviceConfig *ServiceConfig, path string) (string, bool, error) {
+	candidates := []string{path}
+	if !filepath.IsAbs(path) {
+		candidates = []string{filepath.Join(serviceConfig.Path(), path), filepath.Join(serviceConfig.Project.Path, path)}
+	}
+
+	for i, candidate := range candidates {
+		_, err := os.Stat(candidate)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", false, err
+		}
+
+		if i == 0 {
+			return path, true, nil
+		}
+
+		absPath, err := filepath.Abs(candidate)
+		if err != nil {
+			return "", false, err
+		}
+
+		return absPath, true, nil
+	}
+
+	return path, false, nil
+}
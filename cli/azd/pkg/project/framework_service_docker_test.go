@@ -108,7 +108,7 @@ services:
 	framework := NewDockerProject(
 		env,
 		docker,
-		NewContainerHelper(env, envManager, clock.NewMock(), nil, docker, cloud.AzurePublic()),
+		NewContainerHelper(env, envManager, clock.NewMock(), nil, docker, nil, cloud.AzurePublic(), nil),
 		mockinput.NewMockConsole(),
 		mockContext.AlphaFeaturesManager,
 		mockContext.CommandRunner)
@@ -216,7 +216,7 @@ services:
 	framework := NewDockerProject(
 		env,
 		docker,
-		NewContainerHelper(env, envManager, clock.NewMock(), nil, docker, cloud.AzurePublic()),
+		NewContainerHelper(env, envManager, clock.NewMock(), nil, docker, nil, cloud.AzurePublic(), nil),
 		mockinput.NewMockConsole(),
 		mockContext.AlphaFeaturesManager,
 		mockContext.CommandRunner)
@@ -280,7 +280,7 @@ func Test_DockerProject_Build(t *testing.T) {
 			hasDockerFile: true,
 			dockerOptions: DockerProjectOptions{
 				Path:     "./Dockerfile.dev",
-				Context:  "../",
+				Context:  osutil.NewExpandableString("../"),
 				Platform: "custom/platform",
 				Target:   "custom-target",
 			},
@@ -418,7 +418,7 @@ func Test_DockerProject_Build(t *testing.T) {
 			dockerProject := NewDockerProject(
 				env,
 				dockerCli,
-				NewContainerHelper(env, envManager, clock.NewMock(), nil, dockerCli, cloud.AzurePublic()),
+				NewContainerHelper(env, envManager, clock.NewMock(), nil, dockerCli, nil, cloud.AzurePublic(), nil),
 				mockinput.NewMockConsole(),
 				mockContext.AlphaFeaturesManager,
 				mockContext.CommandRunner)
@@ -440,6 +440,194 @@ func Test_DockerProject_Build(t *testing.T) {
 	}
 }
 
+func Test_DockerProject_Build_ContextAndDockerfile(t *testing.T) {
+	tests := []struct {
+		name                    string
+		dockerOptions           DockerProjectOptions
+		expectedDockerfile      func(projectPath string) string
+		expectedContext         func(projectPath string) string
+		expectedErrorContaining string
+	}{
+		{
+			name: "Root context and nested dockerfile",
+			dockerOptions: DockerProjectOptions{
+				Dockerfile: osutil.NewExpandableString("docker/${SERVICE_NAME}/Dockerfile"),
+				Context:    osutil.NewExpandableString("../.."),
+			},
+			// The dockerfile isn't under the service and is resolved from the project path
+			expectedDockerfile: func(projectPath string) string {
+				return filepath.Join(projectPath, "docker", "api", "Dockerfile")
+			},
+			expectedContext: func(string) string { return "../.." },
+		},
+		{
+			name: "Project relative context",
+			dockerOptions: DockerProjectOptions{
+				Dockerfile: osutil.NewExpandableString("Dockerfile.api"),
+				Context:    osutil.NewExpandableString("shared"),
+			},
+			expectedDockerfile: func(string) string { return "Dockerfile.api" },
+			expectedContext: func(projectPath string) string {
+				return filepath.Join(projectPath, "shared")
+			},
+		},
+		{
+			name: "Missing dockerfile",
+			dockerOptions: DockerProjectOptions{
+				Dockerfile: osutil.NewExpandableString("docker/web/Dockerfile"),
+			},
+			expectedErrorContaining: "dockerfile 'docker/web/Dockerfile' of service 'api' not found",
+		},
+		{
+			name: "Missing context",
+			dockerOptions: DockerProjectOptions{
+				Dockerfile: osutil.NewExpandableString("Dockerfile.api"),
+				Context:    osutil.NewExpandableString("${MISSING_DIR}/src"),
+			},
+			expectedErrorContaining: "docker build context '/src' of service 'api' not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dockerBuildArgs exec.RunArgs
+			mockContext := mocks.NewMockContext(context.Background())
+			mockContext.CommandRunner.
+				When(func(args exec.RunArgs, command string) bool {
+					return strings.Contains(command, "docker build")
+				}).
+				RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+					dockerBuildArgs = args
+					err := os.WriteFile(args.Args[len(args.Args)-1], []byte("IMAGE_ID"), 0600)
+					require.NoError(t, err)
+					return exec.NewRunResult(0, "IMAGE_ID", ""), nil
+				})
+
+			// A monorepo with shared code at the root and the dockerfiles of the services in a separate directory
+			projectPath := t.TempDir()
+			for _, dir := range []string{"src/api", "shared", "docker/api"} {
+				require.NoError(t, os.MkdirAll(filepath.Join(projectPath, dir), osutil.PermissionDirectory))
+			}
+			for _, file := range []string{"docker/api/Dockerfile", "src/api/Dockerfile.api"} {
+				require.NoError(t, os.WriteFile(filepath.Join(projectPath, file), []byte("FROM node:14"), 0600))
+			}
+
+			env := environment.NewWithValues("test", map[string]string{"SERVICE_NAME": "api"})
+			dockerCli := docker.NewDocker(mockContext.CommandRunner)
+			serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageDocker)
+			serviceConfig.Project.Path = projectPath
+			serviceConfig.Docker = tt.dockerOptions
+
+			dockerProject := NewDockerProject(
+				env,
+				dockerCli,
+				NewContainerHelper(env, &mockenv.MockEnvManager{}, clock.NewMock(), nil, dockerCli, nil, cloud.AzurePublic(), nil),
+				mockinput.NewMockConsole(),
+				mockContext.AlphaFeaturesManager,
+				mockContext.CommandRunner)
+
+			buildTask := dockerProject.Build(*mockContext.Context, serviceConfig, nil)
+			logProgress(buildTask)
+			_, err := buildTask.Await()
+
+			if tt.expectedErrorContaining != "" {
+				require.ErrorContains(t, err, tt.expectedErrorContaining)
+				require.Nil(t, dockerBuildArgs.Args)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, filepath.Join(projectPath, "src", "api"), dockerBuildArgs.Cwd)
+			require.Equal(t, []string{
+				"build",
+				"-f",
+				tt.expectedDockerfile(projectPath),
+				"--platform",
+				"linux/amd64",
+				"-t",
+				"test-app-api",
+				tt.expectedContext(projectPath),
+			}, dockerBuildArgs.Args[:len(dockerBuildArgs.Args)-2])
+		})
+	}
+}
+
+func Test_DockerProject_Build_Mirror(t *testing.T) {
+	tests := []struct {
+		name               string
+		mirror             string
+		expectedDockerfile string
+	}{
+		{
+			name:   "Mirror",
+			mirror: "${MIRROR_REGISTRY}",
+			expectedDockerfile: "FROM contoso.azurecr.io/library/node:18 AS build\n" +
+				"FROM mcr.microsoft.com/cbl-mariner/base/core:2.0\n",
+		},
+		{
+			name:               "NoMirror",
+			expectedDockerfile: "FROM node:18 AS build\nFROM mcr.microsoft.com/cbl-mariner/base/core:2.0\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dockerfilePath string
+			var dockerfile []byte
+			mockContext := mocks.NewMockContext(context.Background())
+			mockContext.CommandRunner.
+				When(func(args exec.RunArgs, command string) bool {
+					return strings.Contains(command, "docker build")
+				}).
+				RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+					// The mirrored dockerfile is removed once the image is built, so it's read during the build
+					dockerfilePath = args.Args[2]
+					contents, err := os.ReadFile(resolvePathFrom(args.Cwd, dockerfilePath))
+					require.NoError(t, err)
+					dockerfile = contents
+
+					err = os.WriteFile(args.Args[len(args.Args)-1], []byte("IMAGE_ID"), 0600)
+					require.NoError(t, err)
+					return exec.NewRunResult(0, "IMAGE_ID", ""), nil
+				})
+
+			projectPath := t.TempDir()
+			require.NoError(t, os.MkdirAll(filepath.Join(projectPath, "src", "api"), osutil.PermissionDirectory))
+			err := os.WriteFile(
+				filepath.Join(projectPath, "src", "api", "Dockerfile"),
+				[]byte("FROM node:18 AS build\nFROM mcr.microsoft.com/cbl-mariner/base/core:2.0\n"),
+				0600)
+			require.NoError(t, err)
+
+			env := environment.NewWithValues("test", map[string]string{"MIRROR_REGISTRY": "contoso.azurecr.io"})
+			dockerCli := docker.NewDocker(mockContext.CommandRunner)
+			serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageDocker)
+			serviceConfig.Project.Path = projectPath
+			serviceConfig.Docker.Mirror = osutil.NewExpandableString(tt.mirror)
+
+			dockerProject := NewDockerProject(
+				env,
+				dockerCli,
+				NewContainerHelper(env, &mockenv.MockEnvManager{}, clock.NewMock(), nil, dockerCli, nil, cloud.AzurePublic(), nil),
+				mockinput.NewMockConsole(),
+				mockContext.AlphaFeaturesManager,
+				mockContext.CommandRunner)
+
+			buildTask := dockerProject.Build(*mockContext.Context, serviceConfig, nil)
+			logProgress(buildTask)
+			_, err = buildTask.Await()
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedDockerfile, string(dockerfile))
+			if tt.mirror == "" {
+				require.Equal(t, "./Dockerfile", dockerfilePath)
+			} else {
+				require.NoFileExists(t, dockerfilePath)
+			}
+		})
+	}
+}
+
 func Test_DockerProject_Package(t *testing.T) {
 	tests := []struct {
 		name                   string
@@ -532,7 +720,7 @@ func Test_DockerProject_Package(t *testing.T) {
 			dockerProject := NewDockerProject(
 				env,
 				dockerCli,
-				NewContainerHelper(env, envManager, clock.NewMock(), nil, dockerCli, cloud.AzurePublic()),
+				NewContainerHelper(env, envManager, clock.NewMock(), nil, dockerCli, nil, cloud.AzurePublic(), nil),
 				mockinput.NewMockConsole(),
 				mockContext.AlphaFeaturesManager,
 				mockContext.CommandRunner)
@@ -5,13 +5,16 @@ package project
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/cleanup"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
@@ -22,6 +25,44 @@ const (
 	defaultDotNetBuildConfiguration string = "Release"
 )
 
+// DotNetOptions are the options of the build and publish of dotnet services.
+type DotNetOptions struct {
+	// The build configuration, like 'Debug'. Defaults to 'Release'
+	Configuration string `yaml:"configuration,omitempty"`
+	// The runtime identifier (RID) the service is published for, like 'linux-x64'
+	Runtime string `yaml:"runtime,omitempty"`
+	// Whether the .NET runtime is published with the service. Defaults to the setting of the project
+	SelfContained *bool `yaml:"selfContained,omitempty"`
+	// Whether the unused code is trimmed from the published service, which must be self-contained
+	Trimmed bool `yaml:"trimmed,omitempty"`
+}
+
+// runtimeIdentifierRegex matches runtime identifiers, like 'linux-x64', 'linux-musl-arm64' or 'ubuntu.22.04-x64'.
+var runtimeIdentifierRegex = regexp.MustCompile(
+	`^[a-z][a-z0-9]*(\.[0-9]+)*(-[a-z0-9]+)*-(x64|x86|arm|arm64|armel|armv6|s390x|ppc64le|loongarch64|riscv64)$`)
+
+// Validate checks the runtime identifier, and that trimmed services are self-contained.
+func (o *DotNetOptions) Validate() error {
+	if o.Runtime != "" && !runtimeIdentifierRegex.MatchString(o.Runtime) {
+		return fmt.Errorf(
+			"invalid dotnet.runtime '%s', expected a runtime identifier like 'linux-x64' or 'win-arm64'", o.Runtime)
+	}
+
+	if o.Trimmed && (o.SelfContained == nil || !*o.SelfContained || o.Runtime == "") {
+		return errors.New("dotnet.trimmed requires dotnet.selfContained and dotnet.runtime to be set")
+	}
+
+	return nil
+}
+
+func (o *DotNetOptions) configuration() string {
+	if o.Configuration == "" {
+		return defaultDotNetBuildConfiguration
+	}
+
+	return o.Configuration
+}
+
 type dotnetProject struct {
 	env       *environment.Environment
 	dotnetCli dotnet.DotNetCli
@@ -88,7 +129,7 @@ func (dp *dotnetProject) Restore(
 ) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
 	return async.RunTaskWithProgress(
 		func(task *async.TaskContextWithProgress[*ServiceRestoreResult, ServiceProgress]) {
-			task.SetProgress(NewServiceProgress("Restoring .NET project dependencies"))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhaseRestoring, "Restoring .NET project dependencies"))
 			projFile, err := findProjectFile(serviceConfig.Name, serviceConfig.Path())
 			if err != nil {
 				task.SetError(err)
@@ -112,18 +153,19 @@ func (dp *dotnetProject) Build(
 ) *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress] {
 	return async.RunTaskWithProgress(
 		func(task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress]) {
-			task.SetProgress(NewServiceProgress("Building .NET project"))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhaseBuilding, "Building .NET project"))
 			projFile, err := findProjectFile(serviceConfig.Name, serviceConfig.Path())
 			if err != nil {
 				task.SetError(err)
 				return
 			}
-			if err := dp.dotnetCli.Build(ctx, projFile, defaultDotNetBuildConfiguration, ""); err != nil {
+			configuration := serviceConfig.DotNet.configuration()
+			if err := dp.dotnetCli.Build(ctx, projFile, configuration, ""); err != nil {
 				task.SetError(err)
 				return
 			}
 
-			defaultOutputDir := filepath.Join("./bin", defaultDotNetBuildConfiguration)
+			defaultOutputDir := filepath.Join("./bin", configuration)
 
 			// Attempt to find the default build output location
 			buildOutputDir := serviceConfig.Path()
@@ -174,14 +216,21 @@ func (dp *dotnetProject) Package(
 				task.SetError(fmt.Errorf("creating package directory for %s: %w", serviceConfig.Name, err))
 				return
 			}
+			cleanup.RemoveOnCancel(ctx, packageDest)
 
-			task.SetProgress(NewServiceProgress("Publishing .NET project"))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhasePackaging, "Publishing .NET project"))
 			projFile, err := findProjectFile(serviceConfig.Name, serviceConfig.Path())
 			if err != nil {
 				task.SetError(err)
 				return
 			}
-			if err := dp.dotnetCli.Publish(ctx, projFile, defaultDotNetBuildConfiguration, packageDest); err != nil {
+			publishOptions := dotnet.PublishOptions{
+				Runtime:       serviceConfig.DotNet.Runtime,
+				SelfContained: serviceConfig.DotNet.SelfContained,
+				Trimmed:       serviceConfig.DotNet.Trimmed,
+			}
+			configuration := serviceConfig.DotNet.configuration()
+			if err := dp.dotnetCli.Publish(ctx, projFile, configuration, packageDest, publishOptions); err != nil {
 				task.SetError(err)
 				return
 			}
@@ -12,6 +12,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
@@ -272,3 +273,90 @@ func Test_DotNetProject_Package(t *testing.T) {
 		runArgs.Args,
 	)
 }
+
+func Test_DotNetProject_Package_PublishOptions(t *testing.T) {
+	tempDir := t.TempDir()
+	ostest.Chdir(t, tempDir)
+	require.NoError(t, os.MkdirAll("./src/api", osutil.PermissionDirectory))
+	require.NoError(t, os.WriteFile("./src/api/api.csproj", nil, osutil.PermissionFile))
+
+	var runArgs exec.RunArgs
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.
+		When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "dotnet publish")
+		}).
+		RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			runArgs = args
+			err := os.WriteFile(filepath.Join(args.Args[5], "api.dll"), nil, osutil.PermissionFile)
+			return exec.NewRunResult(0, "", ""), err
+		})
+
+	serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguageCsharp)
+	serviceConfig.DotNet = DotNetOptions{
+		Configuration: "Debug",
+		Runtime:       "linux-musl-x64",
+		SelfContained: to.Ptr(true),
+		Trimmed:       true,
+	}
+
+	dotnetProject := NewDotNetProject(dotnet.NewDotNetCli(mockContext.CommandRunner), environment.New("test"))
+	packageTask := dotnetProject.Package(*mockContext.Context, serviceConfig, &ServiceBuildResult{})
+	logProgress(packageTask)
+
+	result, err := packageTask.Await()
+	require.NoError(t, err)
+	require.Equal(t,
+		[]string{
+			"publish",
+			filepath.Join(serviceConfig.RelativePath, "api.csproj"),
+			"-c",
+			"Debug",
+			"--output",
+			result.PackagePath,
+			"-r",
+			"linux-musl-x64",
+			"--self-contained",
+			"true",
+			"-p:PublishTrimmed=true",
+		},
+		runArgs.Args,
+	)
+}
+
+func Test_DotNetOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     DotNetOptions
+		expectedErr string
+	}{
+		{name: "Empty"},
+		{name: "Runtime", options: DotNetOptions{Runtime: "win-arm64", SelfContained: to.Ptr(false)}},
+		{name: "VersionedRuntime", options: DotNetOptions{Runtime: "ubuntu.22.04-x64"}},
+		{
+			name:    "Trimmed",
+			options: DotNetOptions{Runtime: "linux-x64", SelfContained: to.Ptr(true), Trimmed: true},
+		},
+		{
+			name:        "InvalidRuntime",
+			options:     DotNetOptions{Runtime: "linux"},
+			expectedErr: "invalid dotnet.runtime 'linux', expected a runtime identifier like 'linux-x64' or 'win-arm64'",
+		},
+		{
+			name:        "TrimmedNotSelfContained",
+			options:     DotNetOptions{Runtime: "linux-x64", Trimmed: true},
+			expectedErr: "dotnet.trimmed requires dotnet.selfContained and dotnet.runtime to be set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.options.Validate()
+			if tt.expectedErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
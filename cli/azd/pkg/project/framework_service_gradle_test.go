@@ -0,0 +1,119 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/gradle"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/javac"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GradleProject_Package(t *testing.T) {
+	tests := []struct {
+		name         string
+		java         JavaOptions
+		outputPath   string
+		archives     []string
+		expectedArgs []string
+		want         string
+		wantErr      string
+	}{
+		{
+			name:         "SpringBoot",
+			archives:     []string{"build/libs/api-1.0.jar", "build/libs/api-1.0-plain.jar"},
+			expectedArgs: []string{"assemble"},
+			want:         "build/libs/api-1.0.jar",
+		},
+		{
+			name:         "Shadow",
+			java:         JavaOptions{Goals: []string{"shadowJar"}, Properties: map[string]string{"env": "prod"}},
+			archives:     []string{"build/libs/api-1.0.jar", "build/libs/api-1.0-all.jar"},
+			expectedArgs: []string{"assemble", "shadowJar", "-Denv=prod"},
+			want:         "build/libs/api-1.0-all.jar",
+		},
+		{
+			name:         "SpecifyOutputDir",
+			outputPath:   "out",
+			archives:     []string{"out/api.war"},
+			expectedArgs: []string{"assemble"},
+			want:         "out/api.war",
+		},
+		{
+			name:    "ErrProfiles",
+			java:    JavaOptions{Profiles: []string{"prod"}},
+			wantErr: "java.profiles are only supported by maven, use java.properties with gradle",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			temp := t.TempDir()
+			svcDir := filepath.Join(temp, "src", "api")
+			require.NoError(t, os.MkdirAll(svcDir, osutil.PermissionDirectory))
+			err := os.WriteFile(filepath.Join(svcDir, getGradlewCmd()), nil, osutil.PermissionExecutableFile)
+			require.NoError(t, err)
+
+			var runArgs exec.RunArgs
+			mockContext := mocks.NewMockContext(context.Background())
+			mockContext.CommandRunner.
+				When(func(args exec.RunArgs, command string) bool {
+					return strings.Contains(command, getGradlewCmd()+" assemble")
+				}).
+				RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+					runArgs = args
+					for _, archive := range tt.archives {
+						archivePath := filepath.Join(svcDir, archive)
+						require.NoError(t, os.MkdirAll(filepath.Dir(archivePath), osutil.PermissionDirectory))
+						require.NoError(t, os.WriteFile(archivePath, []byte(archive), osutil.PermissionFile))
+					}
+					return exec.NewRunResult(0, "", ""), nil
+				})
+
+			serviceConfig := createTestServiceConfig("src/api", AppServiceTarget, ServiceLanguageJava)
+			serviceConfig.Project.Path = temp
+			serviceConfig.OutputPath = tt.outputPath
+			serviceConfig.Java = tt.java
+
+			gradleProject := NewGradleProject(
+				environment.New("test"),
+				gradle.NewGradleCli(mockContext.CommandRunner),
+				javac.NewCli(mockContext.CommandRunner),
+			)
+			require.NoError(t, gradleProject.Initialize(*mockContext.Context, serviceConfig))
+
+			packageTask := gradleProject.Package(*mockContext.Context, serviceConfig, &ServiceBuildResult{})
+			logProgress(packageTask)
+
+			result, err := packageTask.Await()
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedArgs, runArgs.Args)
+
+			ext := filepath.Ext(tt.want)
+			contents, err := os.ReadFile(filepath.Join(result.PackagePath, AppServiceJavaPackageName+ext))
+			require.NoError(t, err)
+			require.Equal(t, tt.want, string(contents))
+		})
+	}
+}
+
+func getGradlewCmd() string {
+	if runtime.GOOS == "windows" {
+		return "gradlew.bat"
+	} else {
+		return "gradlew"
+	}
+}
@@ -2,12 +2,15 @@ package project
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/cleanup"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/javac"
@@ -18,6 +21,81 @@ import (
 // The default, conventional App Service Java package name
 const AppServiceJavaPackageName = "app"
 
+type JavaBuildTool string
+
+const (
+	JavaBuildToolMaven  JavaBuildTool = "maven"
+	JavaBuildToolGradle JavaBuildTool = "gradle"
+)
+
+// JavaOptions are the options of the build and package of java services.
+type JavaOptions struct {
+	// The build tool of the service. Detected from the files of the project when empty
+	BuildTool JavaBuildTool `yaml:"buildTool,omitempty"`
+	// The Maven profiles activated when packaging, passed as -P
+	Profiles []string `yaml:"profiles,omitempty"`
+	// The system properties set when packaging, passed as -Dkey=value
+	Properties map[string]string `yaml:"properties,omitempty"`
+	// The additional Maven goals or Gradle tasks run when packaging, like 'spring-boot:repackage'
+	Goals []string `yaml:"goals,omitempty"`
+}
+
+func (o *JavaOptions) Validate() error {
+	if o.BuildTool != "" && o.BuildTool != JavaBuildToolMaven && o.BuildTool != JavaBuildToolGradle {
+		return fmt.Errorf("invalid java.buildTool '%s', expected 'maven' or 'gradle'", o.BuildTool)
+	}
+
+	if len(o.Profiles) > 0 && o.BuildTool == JavaBuildToolGradle {
+		return errors.New("java.profiles are only supported by maven, use java.properties with gradle")
+	}
+
+	return nil
+}
+
+func (o *JavaOptions) isEmpty() bool {
+	return o.BuildTool == "" && len(o.Profiles) == 0 && len(o.Properties) == 0 && len(o.Goals) == 0
+}
+
+// buildTool returns the build tool of the java project in projectPath. Projects with a Gradle build script and no
+// pom.xml are built with Gradle, all others with Maven.
+func (o *JavaOptions) buildTool(projectPath string) JavaBuildTool {
+	if o.BuildTool != "" {
+		return o.BuildTool
+	}
+
+	if _, err := os.Stat(filepath.Join(projectPath, "pom.xml")); err == nil {
+		return JavaBuildToolMaven
+	}
+
+	for _, script := range []string{"build.gradle", "build.gradle.kts", "settings.gradle", "settings.gradle.kts"} {
+		if _, err := os.Stat(filepath.Join(projectPath, script)); err == nil {
+			return JavaBuildToolGradle
+		}
+	}
+
+	return JavaBuildToolMaven
+}
+
+// packageArgs returns the goals, profiles and properties passed to the build tool when packaging.
+func (o *JavaOptions) packageArgs() []string {
+	args := slices.Clone(o.Goals)
+	if len(o.Profiles) > 0 {
+		args = append(args, "-P"+strings.Join(o.Profiles, ","))
+	}
+
+	keys := make([]string, 0, len(o.Properties))
+	for key := range o.Properties {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		args = append(args, fmt.Sprintf("-D%s=%s", key, o.Properties[key]))
+	}
+
+	return args
+}
+
 type mavenProject struct {
 	env      *environment.Environment
 	mavenCli maven.MavenCli
@@ -64,7 +142,7 @@ func (m *mavenProject) Restore(
 ) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
 	return async.RunTaskWithProgress(
 		func(task *async.TaskContextWithProgress[*ServiceRestoreResult, ServiceProgress]) {
-			task.SetProgress(NewServiceProgress("Resolving maven dependencies"))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhaseRestoring, "Resolving maven dependencies"))
 			if err := m.mavenCli.ResolveDependencies(ctx, serviceConfig.Path()); err != nil {
 				task.SetError(fmt.Errorf("resolving maven dependencies: %w", err))
 				return
@@ -83,7 +161,7 @@ func (m *mavenProject) Build(
 ) *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress] {
 	return async.RunTaskWithProgress(
 		func(task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress]) {
-			task.SetProgress(NewServiceProgress("Compiling maven project"))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhaseBuilding, "Compiling maven project"))
 			if err := m.mavenCli.Compile(ctx, serviceConfig.Path()); err != nil {
 				task.SetError(err)
 				return
@@ -109,58 +187,17 @@ func (m *mavenProject) Package(
 				task.SetError(fmt.Errorf("creating staging directory: %w", err))
 				return
 			}
+			cleanup.RemoveOnCancel(ctx, packageDest)
 
-			task.SetProgress(NewServiceProgress("Packaging maven project"))
-			if err := m.mavenCli.Package(ctx, serviceConfig.Path()); err != nil {
+			task.SetProgress(NewServicePhaseProgress(ProgressPhasePackaging, "Packaging maven project"))
+			if err := m.mavenCli.Package(ctx, serviceConfig.Path(), serviceConfig.Java.packageArgs()...); err != nil {
 				task.SetError(err)
 				return
 			}
 
-			packageSrcPath := buildOutput.BuildOutputPath
-			if packageSrcPath == "" {
-				packageSrcPath = serviceConfig.Path()
-			}
-
-			if serviceConfig.OutputPath != "" {
-				packageSrcPath = filepath.Join(packageSrcPath, serviceConfig.OutputPath)
-			} else {
-				packageSrcPath = filepath.Join(packageSrcPath, "target")
-			}
-
-			packageSrcFileInfo, err := os.Stat(packageSrcPath)
-			if err != nil {
-				if serviceConfig.OutputPath == "" {
-					task.SetError(fmt.Errorf("reading default maven target path %s: %w", packageSrcPath, err))
-				} else {
-					task.SetError(fmt.Errorf("reading dist path %s: %w", packageSrcPath, err))
-				}
-				return
-			}
-
-			archive := ""
-			if packageSrcFileInfo.IsDir() {
-				archive, err = m.discoverArchive(packageSrcPath)
-				if err != nil {
-					task.SetError(err)
-					return
-				}
-			} else {
-				archive = packageSrcPath
-				if !isSupportedJavaArchive(archive) {
-					ext := filepath.Ext(archive)
-					task.SetError(
-						fmt.Errorf(
-							//nolint:lll
-							"file %s with extension %s is not a supported java archive file (.ear, .war, .jar)", ext, archive))
-					return
-				}
-			}
-
-			task.SetProgress(NewServiceProgress("Copying deployment package"))
-			ext := strings.ToLower(filepath.Ext(archive))
-			err = copy.Copy(archive, filepath.Join(packageDest, AppServiceJavaPackageName+ext))
-			if err != nil {
-				task.SetError(fmt.Errorf("copying to staging directory failed: %w", err))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhasePackaging, "Copying deployment package"))
+			if err := copyJavaArchive(serviceConfig, buildOutput, "maven target", "target", packageDest); err != nil {
+				task.SetError(err)
 				return
 			}
 
@@ -172,12 +209,85 @@ func (m *mavenProject) Package(
 	)
 }
 
+// copyJavaArchive copies the java archive of the service to packageDest. The archive is the dist path of the service,
+// or the archive found in the dist directory, or else in the defaultOutputPath of the build tool.
+func copyJavaArchive(
+	serviceConfig *ServiceConfig,
+	buildOutput *ServiceBuildResult,
+	defaultOutputName string,
+	defaultOutputPath string,
+	packageDest string,
+) error {
+	packageSrcPath := buildOutput.BuildOutputPath
+	if packageSrcPath == "" {
+		packageSrcPath = serviceConfig.Path()
+	}
+
+	if serviceConfig.OutputPath != "" {
+		packageSrcPath = filepath.Join(packageSrcPath, serviceConfig.OutputPath)
+	} else {
+		packageSrcPath = filepath.Join(packageSrcPath, defaultOutputPath)
+	}
+
+	packageSrcFileInfo, err := os.Stat(packageSrcPath)
+	if err != nil {
+		if serviceConfig.OutputPath == "" {
+			return fmt.Errorf("reading default %s path %s: %w", defaultOutputName, packageSrcPath, err)
+		}
+		return fmt.Errorf("reading dist path %s: %w", packageSrcPath, err)
+	}
+
+	archive := ""
+	if packageSrcFileInfo.IsDir() {
+		archive, err = discoverArchive(packageSrcPath)
+		if err != nil {
+			return err
+		}
+	} else {
+		archive = packageSrcPath
+		if !isSupportedJavaArchive(archive) {
+			ext := filepath.Ext(archive)
+			return fmt.Errorf(
+				"file %s with extension %s is not a supported java archive file (.ear, .war, .jar)", archive, ext)
+		}
+	}
+
+	ext := strings.ToLower(filepath.Ext(archive))
+	if err := copy.Copy(archive, filepath.Join(packageDest, AppServiceJavaPackageName+ext)); err != nil {
+		return fmt.Errorf("copying to staging directory failed: %w", err)
+	}
+
+	return nil
+}
+
 func isSupportedJavaArchive(archiveFile string) bool {
 	ext := strings.ToLower(filepath.Ext(archiveFile))
 	return ext == ".jar" || ext == ".war" || ext == ".ear"
 }
 
-func (m *mavenProject) discoverArchive(dir string) (string, error) {
+// isSecondaryJavaArchive reports whether the archive is a by-product of the build rather than the deployable archive:
+// the original archive kept by the Maven Shade plugin, the plain archive of the Spring Boot Gradle plugin, or the
+// sources, javadoc and tests archives.
+func isSecondaryJavaArchive(archiveFile string) bool {
+	name := strings.ToLower(strings.TrimSuffix(archiveFile, filepath.Ext(archiveFile)))
+	if strings.HasPrefix(name, "original-") {
+		return true
+	}
+
+	for _, classifier := range []string{"-plain", "-sources", "-javadoc", "-tests"} {
+		if strings.HasSuffix(name, classifier) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fatJavaArchiveClassifiers are the classifiers of the archives bundling their dependencies, built next to the thin
+// archive by the Gradle Shadow plugin, and the Maven Assembly and Shade plugins.
+var fatJavaArchiveClassifiers = []string{"-all", "-jar-with-dependencies", "-shaded"}
+
+func discoverArchive(dir string) (string, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
 		return "", fmt.Errorf("discovering java archive files in %s: %w", dir, err)
@@ -190,11 +300,28 @@ func (m *mavenProject) discoverArchive(dir string) (string, error) {
 		}
 
 		name := entry.Name()
-		if isSupportedJavaArchive(name) {
+		if isSupportedJavaArchive(name) && !isSecondaryJavaArchive(name) {
 			archiveFiles = append(archiveFiles, name)
 		}
 	}
 
+	// Prefer the archive bundling the dependencies over the thin archive built next to it
+	if len(archiveFiles) > 1 {
+		fatArchiveFiles := []string{}
+		for _, name := range archiveFiles {
+			base := strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name)))
+			if slices.ContainsFunc(fatJavaArchiveClassifiers, func(classifier string) bool {
+				return strings.HasSuffix(base, classifier)
+			}) {
+				fatArchiveFiles = append(fatArchiveFiles, name)
+			}
+		}
+
+		if len(fatArchiveFiles) == 1 {
+			archiveFiles = fatArchiveFiles
+		}
+	}
+
 	switch len(archiveFiles) {
 	case 0:
 		return "", fmt.Errorf("no java archive files (.jar, .ear, .war) found in %s", dir)
@@ -319,3 +319,127 @@ func getMvnwCmd() string {
 		return "mvnw"
 	}
 }
+
+func Test_MavenProject_Package_Options(t *testing.T) {
+	temp := t.TempDir()
+	svcDir := filepath.Join(temp, "src", "api")
+	require.NoError(t, os.MkdirAll(svcDir, osutil.PermissionDirectory))
+	err := os.WriteFile(filepath.Join(svcDir, getMvnwCmd()), nil, osutil.PermissionExecutableFile)
+	require.NoError(t, err)
+
+	var runArgs exec.RunArgs
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.
+		When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, fmt.Sprintf("%s package", getMvnwCmd()))
+		}).
+		RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			runArgs = args
+
+			// The shade plugin keeps the original archive next to the shaded archive
+			targetDir := filepath.Join(svcDir, "target")
+			require.NoError(t, os.MkdirAll(targetDir, osutil.PermissionDirectory))
+			for _, name := range []string{"api-1.0.jar", "original-api-1.0.jar", "api-1.0-sources.jar"} {
+				err := os.WriteFile(filepath.Join(targetDir, name), []byte(name), osutil.PermissionFile)
+				require.NoError(t, err)
+			}
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+	serviceConfig := createTestServiceConfig("src/api", AppServiceTarget, ServiceLanguageJava)
+	serviceConfig.Project.Path = temp
+	serviceConfig.Java = JavaOptions{
+		Profiles:   []string{"prod", "azure"},
+		Properties: map[string]string{"skipITs": "true", "app.version": "1.0"},
+		Goals:      []string{"spring-boot:repackage"},
+	}
+
+	mavenProject := NewMavenProject(
+		environment.New("test"), maven.NewMavenCli(mockContext.CommandRunner), javac.NewCli(mockContext.CommandRunner))
+	require.NoError(t, mavenProject.Initialize(*mockContext.Context, serviceConfig))
+
+	packageTask := mavenProject.Package(*mockContext.Context, serviceConfig, &ServiceBuildResult{})
+	logProgress(packageTask)
+
+	result, err := packageTask.Await()
+	require.NoError(t, err)
+	require.Equal(t,
+		[]string{"package", "-DskipTests", "spring-boot:repackage", "-Pprod,azure", "-Dapp.version=1.0", "-DskipITs=true"},
+		runArgs.Args,
+	)
+
+	contents, err := os.ReadFile(filepath.Join(result.PackagePath, AppServiceJavaPackageName+".jar"))
+	require.NoError(t, err)
+	require.Equal(t, "api-1.0.jar", string(contents))
+}
+
+func Test_discoverArchive(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []string
+		want    string
+		wantErr bool
+	}{
+		{name: "Single", files: []string{"api.war"}, want: "api.war"},
+		{name: "MavenShade", files: []string{"api.jar", "original-api.jar"}, want: "api.jar"},
+		{name: "SpringBootGradle", files: []string{"api.jar", "api-plain.jar"}, want: "api.jar"},
+		{name: "SpringBootMaven", files: []string{"api.jar", "api.jar.original"}, want: "api.jar"},
+		{name: "GradleShadow", files: []string{"api.jar", "api-all.jar"}, want: "api-all.jar"},
+		{
+			name:  "MavenAssembly",
+			files: []string{"api.jar", "api-jar-with-dependencies.jar", "api-javadoc.jar"},
+			want:  "api-jar-with-dependencies.jar",
+		},
+		{name: "Multiple", files: []string{"api.jar", "worker.jar"}, wantErr: true},
+		{name: "None", files: []string{"api-sources.jar"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range tt.files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, osutil.PermissionFile))
+			}
+
+			archive, err := discoverArchive(dir)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, filepath.Join(dir, tt.want), archive)
+			}
+		})
+	}
+}
+
+func Test_JavaOptions_buildTool(t *testing.T) {
+	tests := []struct {
+		name    string
+		options JavaOptions
+		files   []string
+		want    JavaBuildTool
+	}{
+		{name: "Default", want: JavaBuildToolMaven},
+		{name: "Maven", files: []string{"pom.xml"}, want: JavaBuildToolMaven},
+		{name: "Gradle", files: []string{"build.gradle"}, want: JavaBuildToolGradle},
+		{name: "GradleKotlin", files: []string{"build.gradle.kts"}, want: JavaBuildToolGradle},
+		{name: "MavenFirst", files: []string{"pom.xml", "build.gradle"}, want: JavaBuildToolMaven},
+		{
+			name:    "Explicit",
+			options: JavaOptions{BuildTool: JavaBuildToolGradle},
+			files:   []string{"pom.xml"},
+			want:    JavaBuildToolGradle,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range tt.files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, osutil.PermissionFile))
+			}
+
+			require.Equal(t, tt.want, tt.options.buildTool(dir))
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/cleanup"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/npm"
@@ -55,7 +56,26 @@ func (np *npmProject) Restore(
 ) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
 	return async.RunTaskWithProgress(
 		func(task *async.TaskContextWithProgress[*ServiceRestoreResult, ServiceProgress]) {
-			task.SetProgress(NewServiceProgress("Installing NPM dependencies"))
+			// The members of a workspace share the node_modules installed at the root of the workspace
+			workspace, err := npm.FindWorkspace(serviceConfig.Path(), serviceConfig.Project.Path)
+			if err != nil {
+				task.SetError(fmt.Errorf("finding workspace of %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			if workspace != nil {
+				task.SetProgress(NewServicePhaseProgress(
+					ProgressPhaseRestoring, fmt.Sprintf("Installing %s workspace dependencies", workspace.PackageManager)))
+				if err := np.cli.InstallWorkspace(ctx, workspace); err != nil {
+					task.SetError(err)
+					return
+				}
+
+				task.SetResult(&ServiceRestoreResult{})
+				return
+			}
+
+			task.SetProgress(NewServicePhaseProgress(ProgressPhaseRestoring, "Installing NPM dependencies"))
 			if err := np.cli.Install(ctx, serviceConfig.Path()); err != nil {
 				task.SetError(err)
 				return
@@ -76,7 +96,7 @@ func (np *npmProject) Build(
 		func(task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress]) {
 			// Exec custom `build` script if available
 			// If `build`` script is not defined in the package.json the NPM script will NOT fail
-			task.SetProgress(NewServiceProgress("Running NPM build script"))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhaseBuilding, "Running NPM build script"))
 			if err := np.cli.RunScript(ctx, serviceConfig.Path(), "build"); err != nil {
 				task.SetError(err)
 				return
@@ -108,8 +128,9 @@ func (np *npmProject) Package(
 				task.SetError(fmt.Errorf("creating package directory for %s: %w", serviceConfig.Name, err))
 				return
 			}
+			cleanup.RemoveOnCancel(ctx, packageDest)
 
-			task.SetProgress(NewServiceProgress("Running NPM package script"))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhasePackaging, "Running NPM package script"))
 
 			// Long term this script we call should better align with our inner-loop scenarios
 			// Keeping this defaulted to `build` will create confusion for users when we start to support
@@ -137,7 +158,7 @@ func (np *npmProject) Package(
 				return
 			}
 
-			task.SetProgress(NewServiceProgress("Copying deployment package"))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhasePackaging, "Copying deployment package"))
 
 			if err := buildForZip(
 				packageSource,
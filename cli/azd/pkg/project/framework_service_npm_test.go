@@ -48,6 +48,42 @@ func Test_NpmProject_Restore(t *testing.T) {
 	)
 }
 
+func Test_NpmProject_Restore_Workspace(t *testing.T) {
+	projectPath := t.TempDir()
+	require.NoError(t, os.WriteFile(
+		filepath.Join(projectPath, "package.json"), []byte(`{"workspaces": ["apps/*"]}`), osutil.PermissionFile))
+
+	var installs []exec.RunArgs
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.
+		When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "npm install")
+		}).
+		RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			installs = append(installs, args)
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+	env := environment.New("test")
+	npmProject := NewNpmProject(npm.NewNpmCli(mockContext.CommandRunner), env)
+
+	for _, service := range []string{"api", "web"} {
+		serviceConfig := createTestServiceConfig(
+			filepath.Join("apps", service), AppServiceTarget, ServiceLanguageTypeScript)
+		serviceConfig.Project.Path = projectPath
+
+		restoreTask := npmProject.Restore(*mockContext.Context, serviceConfig)
+		logProgress(restoreTask)
+
+		_, err := restoreTask.Await()
+		require.NoError(t, err)
+	}
+
+	// The services share the node_modules installed once at the root of the workspace
+	require.Len(t, installs, 1)
+	require.Equal(t, projectPath, installs[0].Cwd)
+}
+
 func Test_NpmProject_Build(t *testing.T) {
 	var runArgs exec.RunArgs
 
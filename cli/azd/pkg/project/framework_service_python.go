@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/cleanup"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/python"
@@ -47,7 +48,8 @@ func (pp *pythonProject) RequiredExternalTools(context.Context) []tools.External
 
 // Initializes the Python project
 func (pp *pythonProject) Initialize(ctx context.Context, serviceConfig *ServiceConfig) error {
-	return nil
+	// A pinned interpreter which doesn't exist fails early, rather than when restoring the dependencies
+	return pp.cli.ValidatePinned()
 }
 
 // Restores the project dependencies using PIP requirements.txt
@@ -57,14 +59,14 @@ func (pp *pythonProject) Restore(
 ) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
 	return async.RunTaskWithProgress(
 		func(task *async.TaskContextWithProgress[*ServiceRestoreResult, ServiceProgress]) {
-			task.SetProgress(NewServiceProgress("Checking for Python virtual environment"))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhaseRestoring, "Checking for Python virtual environment"))
 			vEnvName := pp.getVenvName(serviceConfig)
 			vEnvPath := path.Join(serviceConfig.Path(), vEnvName)
 
 			_, err := os.Stat(vEnvPath)
 			if err != nil {
 				if os.IsNotExist(err) {
-					task.SetProgress(NewServiceProgress("Creating Python virtual environment"))
+					task.SetProgress(NewServicePhaseProgress(ProgressPhaseRestoring, "Creating Python virtual environment"))
 					err = pp.cli.CreateVirtualEnv(ctx, serviceConfig.Path(), vEnvName)
 					if err != nil {
 						task.SetError(fmt.Errorf(
@@ -82,7 +84,7 @@ func (pp *pythonProject) Restore(
 				}
 			}
 
-			task.SetProgress(NewServiceProgress("Installing Python PIP dependencies"))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhaseRestoring, "Installing Python PIP dependencies"))
 			err = pp.cli.InstallRequirements(ctx, serviceConfig.Path(), vEnvName, "requirements.txt")
 			if err != nil {
 				task.SetError(
@@ -130,6 +132,7 @@ func (pp *pythonProject) Package(
 				task.SetError(fmt.Errorf("creating package directory for %s: %w", serviceConfig.Name, err))
 				return
 			}
+			cleanup.RemoveOnCancel(ctx, packageDest)
 
 			packageSource := buildOutput.BuildOutputPath
 			if packageSource == "" {
@@ -141,7 +144,7 @@ func (pp *pythonProject) Package(
 				return
 			}
 
-			task.SetProgress(NewServiceProgress("Copying deployment package"))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhasePackaging, "Copying deployment package"))
 			if err := buildForZip(
 				packageSource,
 				packageDest,
@@ -12,6 +12,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
@@ -37,7 +38,7 @@ func Test_PythonProject_Restore(t *testing.T) {
 
 	mockContext.CommandRunner.
 		When(func(args exec.RunArgs, command string) bool {
-			return strings.Contains(command, fmt.Sprintf("%s -m pip install", pythonExe()))
+			return strings.Contains(command, "python.exe -m pip install")
 		}).
 		RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
 			pipArgs = args
@@ -47,7 +48,7 @@ func Test_PythonProject_Restore(t *testing.T) {
 		// Linux & mac run a command list
 	mockContext.CommandRunner.
 		When(func(args exec.RunArgs, command string) bool {
-			return strings.Contains(command, "activate && python3 -m pip install")
+			return strings.Contains(command, "activate' && python -m pip install")
 		}).
 		RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
 			pipArgs = args
@@ -55,7 +56,7 @@ func Test_PythonProject_Restore(t *testing.T) {
 		})
 
 	env := environment.New("test")
-	pythonCli := python.NewPythonCli(mockContext.CommandRunner)
+	pythonCli := python.NewPythonCli(mockContext.CommandRunner, config.NewUserConfigManager(mockContext.ConfigManager))
 	serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguagePython)
 
 	pythonProject := NewPythonProject(pythonCli, env)
@@ -73,15 +74,17 @@ func Test_PythonProject_Restore(t *testing.T) {
 	)
 
 	if runtime.GOOS == "windows" {
-		require.Equal(t, pythonExe(), pipArgs.Cmd)
+		// The requirements are installed with the interpreter of the virtual environment
+		require.Equal(t, "python.exe", filepath.Base(pipArgs.Cmd))
+		require.Equal(t, "api_env", filepath.Base(filepath.Dir(filepath.Dir(pipArgs.Cmd))))
 		require.Equal(t,
 			[]string{"-m", "pip", "install", "-r", "requirements.txt"},
 			pipArgs.Args,
 		)
 	} else {
 		require.Len(t, pipArgs.Args, 2)
-		require.Equal(t, ". api_env/bin/activate", pipArgs.Args[0])
-		require.Equal(t, "python3 -m pip install -r requirements.txt", pipArgs.Args[1])
+		require.Equal(t, ". 'api_env/bin/activate'", pipArgs.Args[0])
+		require.Equal(t, "python -m pip install -r 'requirements.txt'", pipArgs.Args[1])
 	}
 }
 
@@ -89,7 +92,7 @@ func Test_PythonProject_Build(t *testing.T) {
 	mockContext := mocks.NewMockContext(context.Background())
 
 	env := environment.New("test")
-	pythonCli := python.NewPythonCli(mockContext.CommandRunner)
+	pythonCli := python.NewPythonCli(mockContext.CommandRunner, config.NewUserConfigManager(mockContext.ConfigManager))
 	serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguagePython)
 
 	pythonProject := NewPythonProject(pythonCli, env)
@@ -107,7 +110,7 @@ func Test_PythonProject_Package(t *testing.T) {
 	mockContext := mocks.NewMockContext(context.Background())
 
 	env := environment.New("test")
-	pythonCli := python.NewPythonCli(mockContext.CommandRunner)
+	pythonCli := python.NewPythonCli(mockContext.CommandRunner, config.NewUserConfigManager(mockContext.ConfigManager))
 	serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguagePython)
 	err := os.MkdirAll(serviceConfig.Path(), osutil.PermissionDirectory)
 	require.NoError(t, err)
@@ -0,0 +1,114 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/blang/semver/v4"
+)
+
+// Tag strategies that can be set in 'docker.tag' instead of an explicit tag.
+const (
+	// Tags the image with the abbreviated hash of the commit checked out in the service directory.
+	TagStrategyGitSha = "git-sha"
+	// Tags the image with the semantic version from AZD_IMAGE_VERSION or the 'docker.versionFile' file.
+	TagStrategySemver = "semver"
+	// Tags the image with the current UTC time, e.g. 20240131153045.
+	TagStrategyTimestamp = "timestamp"
+)
+
+const (
+	// ImageVersionEnvVarName is the environment variable that provides the version for the semver tag strategy.
+	ImageVersionEnvVarName = "AZD_IMAGE_VERSION"
+	// The file read by the semver tag strategy when 'docker.versionFile' is not set.
+	defaultVersionFile = "VERSION"
+	// The additional tag pushed when 'docker.pushLatest' is enabled.
+	latestTag = "latest"
+)
+
+// resolveImageTag returns the tag for the image of the service from 'docker.tag', which is either one of the tag
+// strategies or an explicit tag. When no tag is configured, a unique tag based on the current time is used.
+func (ch *ContainerHelper) resolveImageTag(ctx context.Context, serviceConfig *ServiceConfig) (string, error) {
+	configuredTag, err := serviceConfig.Docker.Tag.Envsubst(ch.env.Getenv)
+	if err != nil {
+		return "", fmt.Errorf("failed parsing 'tag' from docker configuration, %w", err)
+	}
+
+	switch configuredTag {
+	case "":
+		return ch.defaultImageTag(), nil
+	case TagStrategyGitSha:
+		return ch.gitShaTag(ctx, serviceConfig), nil
+	case TagStrategySemver:
+		return ch.semverTag(serviceConfig)
+	case TagStrategyTimestamp:
+		return ch.clock.Now().UTC().Format("20060102150405"), nil
+	default:
+		return configuredTag, nil
+	}
+}
+
+func (ch *ContainerHelper) defaultImageTag() string {
+	return fmt.Sprintf("azd-deploy-%d", ch.clock.Now().Unix())
+}
+
+// gitShaTag returns the abbreviated commit hash of the service source. Projects that are not in a git repository, or
+// machines without git installed, fall back to the default tag so deployments are not blocked.
+func (ch *ContainerHelper) gitShaTag(ctx context.Context, serviceConfig *ServiceConfig) string {
+	if ch.git != nil {
+		commit, err := ch.git.GetShortCommit(ctx, serviceConfig.Path())
+		if err == nil && commit != "" {
+			return commit
+		}
+
+		log.Printf("failed resolving git commit for service '%s': %v", serviceConfig.Name, err)
+	}
+
+	tag := ch.defaultImageTag()
+	log.Printf("using tag '%s' for service '%s' since the git commit could not be resolved", tag, serviceConfig.Name)
+	return tag
+}
+
+// semverTag returns the version from AZD_IMAGE_VERSION or, when not set, from the version file of the service.
+func (ch *ContainerHelper) semverTag(serviceConfig *ServiceConfig) (string, error) {
+	version := ch.env.Getenv(ImageVersionEnvVarName)
+	source := ImageVersionEnvVarName
+
+	if version == "" {
+		versionFile := serviceConfig.Docker.VersionFile
+		if versionFile == "" {
+			versionFile = defaultVersionFile
+		}
+
+		if !filepath.IsAbs(versionFile) {
+			versionFile = filepath.Join(serviceConfig.Path(), versionFile)
+		}
+
+		contents, err := os.ReadFile(versionFile)
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf(
+				"the semver tag strategy requires the %s environment variable or a version file at '%s'",
+				ImageVersionEnvVarName,
+				versionFile,
+			)
+		} else if err != nil {
+			return "", fmt.Errorf("reading version file: %w", err)
+		}
+
+		version = string(contents)
+		source = versionFile
+	}
+
+	parsed, err := semver.ParseTolerant(strings.TrimSpace(version))
+	if err != nil {
+		return "", fmt.Errorf("'%s' from %s is not a valid semantic version: %w", strings.TrimSpace(version), source, err)
+	}
+
+	// Docker tags can't contain '+', which separates build metadata in semantic versions.
+	return strings.ReplaceAll(parsed.String(), "+", "-"), nil
+}
@@ -0,0 +1,188 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/cloud"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockenv"
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ContainerHelper_ResolveImageTag(t *testing.T) {
+	mockClock := clock.NewMock()
+	mockClock.Set(time.Date(2024, 1, 31, 15, 30, 45, 0, time.UTC))
+
+	tests := []struct {
+		name        string
+		tag         string
+		env         map[string]string
+		versionFile string
+		gitStdout   string
+		gitStderr   string
+		gitErr      error
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "Default",
+			expected: "azd-deploy-1706715045",
+		},
+		{
+			name:     "Explicit",
+			tag:      "v${BUILD_ID}",
+			env:      map[string]string{"BUILD_ID": "42"},
+			expected: "v42",
+		},
+		{
+			name:     "Timestamp",
+			tag:      TagStrategyTimestamp,
+			expected: "20240131153045",
+		},
+		{
+			name:      "GitSha",
+			tag:       TagStrategyGitSha,
+			gitStdout: "1a2b3c4\n",
+			expected:  "1a2b3c4",
+		},
+		{
+			name:      "GitShaNotRepository",
+			tag:       TagStrategyGitSha,
+			gitStderr: "fatal: not a git repository (or any of the parent directories): .git",
+			gitErr:    errors.New("exit code: 128"),
+			expected:  "azd-deploy-1706715045",
+		},
+		{
+			name:     "GitShaGitNotInstalled",
+			tag:      TagStrategyGitSha,
+			gitErr:   errors.New("exec: \"git\": executable file not found in $PATH"),
+			expected: "azd-deploy-1706715045",
+		},
+		{
+			name:        "SemverFromEnvironment",
+			tag:         TagStrategySemver,
+			env:         map[string]string{ImageVersionEnvVarName: "v1.2.3"},
+			versionFile: "9.9.9",
+			expected:    "1.2.3",
+		},
+		{
+			name:        "SemverFromFile",
+			tag:         TagStrategySemver,
+			versionFile: "2.0.0-beta.1+build.5\n",
+			expected:    "2.0.0-beta.1-build.5",
+		},
+		{
+			name:        "SemverInvalid",
+			tag:         TagStrategySemver,
+			versionFile: "not-a-version",
+			expectError: true,
+		},
+		{
+			name:        "SemverMissing",
+			tag:         TagStrategySemver,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockContext := mocks.NewMockContext(context.Background())
+			mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+				return strings.Contains(command, "git -C") && strings.Contains(command, "rev-parse --short HEAD")
+			}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+				return exec.NewRunResult(0, tt.gitStdout, tt.gitStderr), tt.gitErr
+			})
+
+			serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
+			serviceConfig.Project.Path = t.TempDir()
+			serviceConfig.Docker.Tag = osutil.NewExpandableString(tt.tag)
+			if tt.versionFile != "" {
+				require.NoError(t, os.MkdirAll(serviceConfig.Path(), osutil.PermissionDirectory))
+				require.NoError(t, os.WriteFile(
+					filepath.Join(serviceConfig.Path(), defaultVersionFile),
+					[]byte(tt.versionFile),
+					osutil.PermissionFile,
+				))
+			}
+
+			env := environment.NewWithValues("dev", tt.env)
+			containerHelper := NewContainerHelper(
+				env,
+				&mockenv.MockEnvManager{},
+				mockClock,
+				nil,
+				nil,
+				git.NewGitCli(mockContext.CommandRunner),
+				cloud.AzurePublic(),
+				nil,
+			)
+
+			tag, err := containerHelper.resolveImageTag(*mockContext.Context, serviceConfig)
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, tag)
+		})
+	}
+}
+
+func Test_ContainerHelper_Deploy_PushLatest(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockResults := map[string][]exec.RunArgs{}
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.HasPrefix(command, "docker tag") || strings.HasPrefix(command, "docker push")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		mockResults[args.Args[0]] = append(mockResults[args.Args[0]], args)
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	env := environment.NewWithValues("dev", map[string]string{})
+	envManager := &mockenv.MockEnvManager{}
+	envManager.On("Save", *mockContext.Context, env).Return(nil)
+
+	containerHelper := NewContainerHelper(
+		env,
+		envManager,
+		clock.NewMock(),
+		nil,
+		docker.NewDocker(mockContext.CommandRunner),
+		nil,
+		cloud.AzurePublic(),
+		nil,
+	)
+	serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
+	serviceConfig.Docker.Registry = osutil.NewExpandableString("ghcr.io/contoso")
+	serviceConfig.Docker.PushLatest = true
+
+	packageOutput := &ServicePackageResult{
+		Details: &dockerPackageResult{
+			TargetImage: "my-project/api:1.2.3",
+		},
+	}
+
+	deployTask := containerHelper.Deploy(*mockContext.Context, serviceConfig, packageOutput, nil, true)
+	logProgress(deployTask)
+	_, err := deployTask.Await()
+	require.NoError(t, err)
+
+	require.Len(t, mockResults["push"], 2)
+	require.Equal(t, "ghcr.io/contoso/my-project/api:1.2.3", mockResults["push"][0].Args[1])
+	require.Equal(t, "ghcr.io/contoso/my-project/api:latest", mockResults["push"][1].Args[1])
+	require.Equal(t, "ghcr.io/contoso/my-project/api:1.2.3", env.GetServiceProperty("api", "IMAGE_NAME"))
+	require.Equal(t, "1.2.3", env.GetServiceProperty("api", "IMAGE_TAG"))
+}
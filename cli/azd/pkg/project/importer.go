@@ -12,12 +12,25 @@ import (
 	"path/filepath"
 	"slices"
 	"strings"
+	"sync"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 )
 
 type ImportManager struct {
 	dotNetImporter *DotNetImporter
+	// Returns the name of the environment the services are filtered for, with the enabledFor and disabledFor lists of
+	// the services. Nil when the services aren't filtered.
+	environmentName func() string
+	// Reports the services skipped for the environment
+	console input.Console
+	// The messages already reported to the console, since the services are listed many times by a command
+	reportedMu sync.Mutex
+	reported   map[string]bool
 }
 
 func NewImportManager(dotNetImporter *DotNetImporter) *ImportManager {
@@ -26,6 +39,22 @@ func NewImportManager(dotNetImporter *DotNetImporter) *ImportManager {
 	}
 }
 
+// NewImportManagerForEnvironment creates an ImportManager which excludes the services disabled for the environment
+// named by environmentName from the services of the project. The name is read when listing the services, since the
+// environment may be created while the command runs. The skipped services are reported to the console.
+func NewImportManagerForEnvironment(
+	dotNetImporter *DotNetImporter,
+	console input.Console,
+	environmentName func() string,
+) *ImportManager {
+	return &ImportManager{
+		dotNetImporter:  dotNetImporter,
+		environmentName: environmentName,
+		console:         console,
+		reported:        map[string]bool{},
+	}
+}
+
 func (im *ImportManager) HasService(ctx context.Context, projectConfig *ProjectConfig, name string) (bool, error) {
 	services, err := im.ServiceStable(ctx, projectConfig)
 	if err != nil {
@@ -41,6 +70,95 @@ func (im *ImportManager) HasService(ctx context.Context, projectConfig *ProjectC
 	return false, nil
 }
 
+// ValidateServiceName returns an error when the project doesn't contain a service with the given name. The error lists
+// the valid service names and suggests the closest one, to help with typos.
+func (im *ImportManager) ValidateServiceName(ctx context.Context, projectConfig *ProjectConfig, name string) error {
+	services, err := im.ServiceStable(ctx, projectConfig)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(services))
+	for _, svc := range services {
+		if svc.Name == name {
+			return nil
+		}
+
+		names = append(names, svc.Name)
+	}
+
+	if svc, has := projectConfig.Services[name]; has && im.environmentName != nil {
+		envName := im.environmentName()
+		if enabled, reason := svc.EnabledForEnvironment(envName); !enabled {
+			return fmt.Errorf("service '%s' is not enabled for environment '%s', it's %s", name, envName, reason)
+		}
+	}
+
+	err = fmt.Errorf("service name '%s' doesn't exist", name)
+	if len(names) == 0 {
+		return &azcli.ErrorWithSuggestion{
+			Err:        err,
+			Suggestion: "The project doesn't define any services. Add services to the 'services' section of azure.yaml.",
+		}
+	}
+
+	suggestion := fmt.Sprintf("Valid service names: %s.", strings.Join(names, ", "))
+	if closest := closestServiceName(name, names); closest != "" {
+		suggestion = fmt.Sprintf("Did you mean '%s'? %s", closest, suggestion)
+	}
+
+	return &azcli.ErrorWithSuggestion{
+		Err:        err,
+		Suggestion: suggestion,
+	}
+}
+
+// closestServiceName returns the name closest to the given one, when it's close enough to likely be a typo.
+func closestServiceName(name string, names []string) string {
+	closest := ""
+	closestDistance := 0
+	for _, candidate := range names {
+		distance := editDistance(strings.ToLower(name), strings.ToLower(candidate))
+		if closest == "" || distance < closestDistance {
+			closest = candidate
+			closestDistance = distance
+		}
+	}
+
+	// Allow about a third of the characters to differ, so unrelated names aren't suggested
+	if closestDistance > max(1, len(name)/3) {
+		return ""
+	}
+
+	return closest
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
+
 var (
 	errNoMultipleServicesWithAppHost = fmt.Errorf(
 		"a project may only contain a single Aspire service and no other services at this time.")
@@ -86,6 +204,10 @@ func (im *ImportManager) ServiceStable(ctx context.Context, projectConfig *Proje
 		allServices[name] = svcConfig
 	}
 
+	if im.environmentName != nil {
+		im.filterServicesForEnvironment(ctx, allServices, im.environmentName())
+	}
+
 	// Collect all the services and then sort the resulting list by name. This provides a stable ordering of services.
 	allServicesSlice := make([]*ServiceConfig, 0, len(allServices))
 	for _, v := range allServices {
@@ -99,6 +221,61 @@ func (im *ImportManager) ServiceStable(ctx context.Context, projectConfig *Proje
 	return allServicesSlice, nil
 }
 
+// filterServicesForEnvironment removes the services which aren't enabled for the environment. When no environment is
+// selected, the services aren't filtered and the services with enabledFor or disabledFor lists are reported, since
+// they may run on an environment they aren't meant for.
+func (im *ImportManager) filterServicesForEnvironment(
+	ctx context.Context,
+	services map[string]*ServiceConfig,
+	envName string,
+) {
+	if envName == "" {
+		var names []string
+		for name, svcConfig := range services {
+			if len(svcConfig.EnabledFor) > 0 || len(svcConfig.DisabledFor) > 0 {
+				names = append(names, name)
+			}
+		}
+
+		if len(names) > 0 {
+			slices.Sort(names)
+			im.reportOnce(ctx, output.WithWarningFormat(
+				"WARNING: No environment is selected, so the enabledFor and disabledFor lists of services %s aren't "+
+					"applied. Select an environment with '--environment' or 'azd env select'.",
+				ux.ListAsText(names)))
+		}
+
+		return
+	}
+
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		if enabled, reason := services[name].EnabledForEnvironment(envName); !enabled {
+			im.reportOnce(ctx, output.WithGrayFormat(
+				"Skipping service '%s' for environment '%s', it's %s.", name, envName, reason))
+			delete(services, name)
+		}
+	}
+}
+
+// reportOnce writes the message to the console, unless it was already written.
+func (im *ImportManager) reportOnce(ctx context.Context, message string) {
+	im.reportedMu.Lock()
+	defer im.reportedMu.Unlock()
+
+	if im.reported[message] {
+		return
+	}
+	im.reported[message] = true
+
+	im.console.Message(ctx, message)
+}
+
 // defaultOptions for infra settings. These values are applied across provisioning providers.
 const (
 	DefaultModule = "main"
@@ -109,6 +286,19 @@ const (
 // The configuration can be explicitly defined on azure.yaml using path and module, or in case these values
 // are not explicitly defined, the project importer uses default values to find the infrastructure.
 func (im *ImportManager) ProjectInfrastructure(ctx context.Context, projectConfig *ProjectConfig) (*Infra, error) {
+	infra, err := im.projectInfrastructure(ctx, projectConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if projectConfig.Metadata != nil {
+		infra.Options.TemplateId = projectConfig.Metadata.Template
+	}
+
+	return infra, nil
+}
+
+func (im *ImportManager) projectInfrastructure(ctx context.Context, projectConfig *ProjectConfig) (*Infra, error) {
 	// Use default project values for Infra when not specified in azure.yaml
 	if projectConfig.Infra.Module == "" {
 		projectConfig.Infra.Module = DefaultModule
@@ -18,9 +18,11 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
 	"github.com/azure/azure-dev/cli/azd/pkg/lazy"
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/dotnet"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/azure/azure-dev/cli/azd/test/mocks/mockenv"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockinput"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
@@ -66,6 +68,91 @@ func TestImportManagerHasService(t *testing.T) {
 	require.False(t, r)
 }
 
+func TestImportManagerValidateServiceName(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	manager := NewImportManager(&DotNetImporter{})
+	projectConfig := &ProjectConfig{
+		Services: map[string]*ServiceConfig{
+			"api":           {Name: "api", Language: ServiceLanguageJava},
+			"web":           {Name: "web", Language: ServiceLanguageJavaScript},
+			"csharpapptest": {Name: "csharpapptest", Language: ServiceLanguageJava},
+		},
+	}
+
+	require.NoError(t, manager.ValidateServiceName(*mockContext.Context, projectConfig, "csharpapptest"))
+
+	// mistyped service name
+	err := manager.ValidateServiceName(*mockContext.Context, projectConfig, "csharpaptest")
+	var suggestionErr *azcli.ErrorWithSuggestion
+	require.ErrorAs(t, err, &suggestionErr)
+	require.ErrorContains(t, err, "service name 'csharpaptest' doesn't exist")
+	require.Equal(t, "Did you mean 'csharpapptest'? Valid service names: api, csharpapptest, web.", suggestionErr.Suggestion)
+
+	// unrelated service name
+	err = manager.ValidateServiceName(*mockContext.Context, projectConfig, "worker")
+	require.ErrorAs(t, err, &suggestionErr)
+	require.Equal(t, "Valid service names: api, csharpapptest, web.", suggestionErr.Suggestion)
+}
+
+func TestImportManagerServiceStableForEnvironment(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	projectConfig := &ProjectConfig{
+		Services: map[string]*ServiceConfig{
+			"api":     {Name: "api", Language: ServiceLanguageJava},
+			"sidecar": {Name: "sidecar", Language: ServiceLanguageJava, EnabledFor: []string{"dev"}},
+			"web":     {Name: "web", Language: ServiceLanguageJavaScript, DisabledFor: []string{"prod"}},
+		},
+	}
+
+	serviceNames := func(t *testing.T, envName string) ([]string, []string) {
+		console := mockinput.NewMockConsole()
+		manager := NewImportManagerForEnvironment(&DotNetImporter{}, console, func() string { return envName })
+
+		// The skipped services are only reported once, though the services are listed many times
+		var services []*ServiceConfig
+		for i := 0; i < 2; i++ {
+			var err error
+			services, err = manager.ServiceStable(*mockContext.Context, projectConfig)
+			require.NoError(t, err)
+		}
+
+		names := []string{}
+		for _, svc := range services {
+			names = append(names, svc.Name)
+		}
+
+		return names, console.Output()
+	}
+
+	names, output := serviceNames(t, "dev")
+	require.Equal(t, []string{"api", "sidecar", "web"}, names)
+	require.Empty(t, output)
+
+	names, output = serviceNames(t, "prod")
+	require.Equal(t, []string{"api"}, names)
+	require.Equal(t, []string{
+		"Skipping service 'sidecar' for environment 'prod', it's only enabled for dev.",
+		"Skipping service 'web' for environment 'prod', it's disabled for prod.",
+	}, output)
+
+	names, _ = serviceNames(t, "test")
+	require.Equal(t, []string{"api", "web"}, names)
+
+	// The services aren't filtered when the environment isn't known, which is reported
+	names, output = serviceNames(t, "")
+	require.Equal(t, []string{"api", "sidecar", "web"}, names)
+	require.Len(t, output, 1)
+	require.Contains(t, output[0], "enabledFor and disabledFor lists of services sidecar and web aren't applied")
+
+	manager := NewImportManagerForEnvironment(&DotNetImporter{}, mockContext.Console, func() string { return "prod" })
+	require.EqualError(t,
+		manager.ValidateServiceName(*mockContext.Context, projectConfig, "sidecar"),
+		"service 'sidecar' is not enabled for environment 'prod', it's only enabled for dev")
+	require.EqualError(t,
+		manager.ValidateServiceName(*mockContext.Context, projectConfig, "web"),
+		"service 'web' is not enabled for environment 'prod', it's disabled for prod")
+}
+
 func TestImportManagerHasServiceErrorNoMultipleServicesWithAppHost(t *testing.T) {
 	mockContext := mocks.NewMockContext(context.Background())
 	mockEnv := &mockenv.MockEnvManager{}
@@ -247,15 +334,21 @@ func TestImportManagerProjectInfrastructure(t *testing.T) {
 	defer os.Remove(path)
 
 	r, e := manager.ProjectInfrastructure(*mockContext.Context, &ProjectConfig{
+		Metadata: &ProjectMetadata{
+			Template: "todo-nodejs-mongo@0.0.1-beta",
+		},
 		Infra: provisioning.Options{
 			Path:   expectedDefaultFolder,
 			Module: expectedDefaultModule,
+			Tags:   map[string]string{"team": "web"},
 		},
 	})
 
 	require.NoError(t, e)
 	require.Equal(t, expectedDefaultFolder, r.Options.Path)
 	require.Equal(t, expectedDefaultModule, r.Options.Module)
+	require.Equal(t, "todo-nodejs-mongo@0.0.1-beta", r.Options.TemplateId)
+	require.Equal(t, map[string]string{"team": "web"}, r.Options.Tags)
 }
 
 //go:embed testdata/aspire-escaping.json
@@ -0,0 +1,112 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+)
+
+// ProgressPhase is the phase of a service operation a progress message belongs to.
+type ProgressPhase string
+
+const (
+	ProgressPhaseRestoring          ProgressPhase = "restoring"
+	ProgressPhaseBuilding           ProgressPhase = "building"
+	ProgressPhasePackaging          ProgressPhase = "packaging"
+	ProgressPhaseBuildingImage      ProgressPhase = "building image"
+	ProgressPhasePullingImage       ProgressPhase = "pulling image"
+	ProgressPhasePushingImage       ProgressPhase = "pushing"
+	ProgressPhaseUploading          ProgressPhase = "uploading"
+	ProgressPhaseCreatingDeployment ProgressPhase = "creating deployment"
+	ProgressPhaseFetchingEndpoints  ProgressPhase = "fetching endpoints"
+	ProgressPhaseWarmingUp          ProgressPhase = "warming up"
+	ProgressPhaseVerifying          ProgressPhase = "verifying"
+)
+
+// ProgressEvent is a progress message of an operation on the services of a project, such as 'azd deploy'.
+type ProgressEvent struct {
+	// The name of the service the operation is running on
+	Service string `json:"service"`
+	// The phase of the operation. Empty when the message doesn't belong to a known phase.
+	Phase ProgressPhase `json:"phase,omitempty"`
+	// The percentage of the services of the operation that completed
+	Percent int `json:"percent"`
+	// Whether the operation only validates the services without deploying them, like 'azd deploy --dry-run'
+	DryRun  bool   `json:"dryRun,omitempty"`
+	Message string `json:"message"`
+	// The time the progress was reported by the service
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ProgressReporter receives the progress events of operations.
+type ProgressReporter interface {
+	Report(ctx context.Context, event ProgressEvent)
+}
+
+// ProgressReporterFunc is a ProgressReporter calling a function for each event.
+type ProgressReporterFunc func(ctx context.Context, event ProgressEvent)
+
+// Report calls the function with the event.
+func (f ProgressReporterFunc) Report(ctx context.Context, event ProgressEvent) {
+	f(ctx, event)
+}
+
+// NewChannelProgressReporter creates a ProgressReporter sending each event to the channel. Events are dropped once the
+// context of the operation is done, so an operation isn't blocked by a channel that is no longer read.
+func NewChannelProgressReporter(events chan<- ProgressEvent) ProgressReporter {
+	return ProgressReporterFunc(func(ctx context.Context, event ProgressEvent) {
+		select {
+		case events <- event:
+		case <-ctx.Done():
+		}
+	})
+}
+
+type consoleProgressReporter struct {
+	console input.Console
+}
+
+// NewConsoleProgressReporter creates the default ProgressReporter, rendering each event as a step of the console.
+func NewConsoleProgressReporter(console input.Console) ProgressReporter {
+	return &consoleProgressReporter{
+		console: console,
+	}
+}
+
+// Report shows the message of the event in the spinner of the current step.
+func (r *consoleProgressReporter) Report(ctx context.Context, event ProgressEvent) {
+	title := fmt.Sprintf("Deploying service %s", event.Service)
+	if event.DryRun {
+		title = fmt.Sprintf("Validating service %s", event.Service)
+	}
+
+	r.console.ShowSpinner(ctx, fmt.Sprintf("%s (%s)", title, event.Message), input.Step)
+}
+
+// ReportProgress reports each progress message of a service operation until the progress channel is closed. percent
+// is the percentage of the services of the operation that completed before this one, and dryRun whether the operation
+// only validates the services.
+func ReportProgress(
+	ctx context.Context,
+	reporter ProgressReporter,
+	serviceName string,
+	percent int,
+	dryRun bool,
+	progress <-chan ServiceProgress,
+) {
+	for serviceProgress := range progress {
+		reporter.Report(ctx, ProgressEvent{
+			Service:   serviceName,
+			Phase:     serviceProgress.Phase,
+			Percent:   percent,
+			DryRun:    dryRun,
+			Message:   serviceProgress.Message,
+			Timestamp: serviceProgress.Timestamp,
+		})
+	}
+}
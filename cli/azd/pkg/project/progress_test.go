@@ -0,0 +1,107 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockinput"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ReportProgress_FakeDeploy(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	setupMocksForServiceManager(mockContext)
+	env := environment.NewWithValues("test", map[string]string{
+		environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+	})
+	sm := createServiceManager(mockContext, env, ServiceOperationCache{})
+	serviceConfig := createTestServiceConfig("./src/api", ServiceTargetFake, ServiceLanguageFake)
+
+	phases := []ProgressPhase{
+		ProgressPhaseBuildingImage,
+		ProgressPhasePushingImage,
+		ProgressPhaseCreatingDeployment,
+	}
+	ctx := context.WithValue(*mockContext.Context, serviceTargetDeployPhases, phases)
+
+	events := make(chan ProgressEvent)
+	reporter := NewChannelProgressReporter(events)
+
+	deployTask := sm.Deploy(ctx, serviceConfig, nil)
+	go func() {
+		ReportProgress(ctx, reporter, serviceConfig.Name, 50, false, deployTask.Progress())
+		close(events)
+	}()
+
+	var captured []ProgressEvent
+	for event := range events {
+		captured = append(captured, event)
+	}
+
+	_, err := deployTask.Await()
+	require.NoError(t, err)
+
+	require.Len(t, captured, len(phases))
+	for i, event := range captured {
+		require.Equal(t, "api", event.Service)
+		require.Equal(t, phases[i], event.Phase)
+		require.Equal(t, 50, event.Percent)
+		require.Equal(t, "Fake "+string(phases[i]), event.Message)
+		require.False(t, event.Timestamp.IsZero())
+	}
+}
+
+func Test_ProgressReporters(t *testing.T) {
+	event := ProgressEvent{Service: "api", Phase: ProgressPhaseUploading, Message: "Uploading deployment package"}
+
+	t.Run("Func", func(t *testing.T) {
+		var reported []ProgressEvent
+		reporter := ProgressReporterFunc(func(ctx context.Context, event ProgressEvent) {
+			reported = append(reported, event)
+		})
+
+		reporter.Report(context.Background(), event)
+		require.Equal(t, []ProgressEvent{event}, reported)
+	})
+
+	t.Run("ChannelNotRead", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// Reporting doesn't block once the operation is done
+		reporter := NewChannelProgressReporter(make(chan ProgressEvent))
+		reporter.Report(ctx, event)
+	})
+
+	t.Run("Console", func(t *testing.T) {
+		console := mockinput.NewMockConsole()
+		reporter := NewConsoleProgressReporter(console)
+
+		reporter.Report(context.Background(), event)
+		require.Equal(t, []mockinput.SpinnerOp{{
+			Op:      mockinput.SpinnerOpShow,
+			Message: "Deploying service api (Uploading deployment package)",
+			Format:  input.Step,
+		}}, console.SpinnerOps())
+	})
+
+	t.Run("ConsoleDryRun", func(t *testing.T) {
+		console := mockinput.NewMockConsole()
+		reporter := NewConsoleProgressReporter(console)
+
+		dryRunEvent := event
+		dryRunEvent.DryRun = true
+		reporter.Report(context.Background(), dryRunEvent)
+		require.Equal(t, []mockinput.SpinnerOp{{
+			Op:      mockinput.SpinnerOpShow,
+			Message: "Validating service api (Uploading deployment package)",
+			Format:  input.Step,
+		}}, console.SpinnerOps())
+	})
+}
@@ -57,6 +57,8 @@ func Parse(ctx context.Context, yamlContent string) (*ProjectConfig, error) {
 
 	projectConfig.EventDispatcher = ext.NewEventDispatcher[ProjectLifecycleEventArgs]()
 
+	warnDeprecatedFields(ctx, yamlContent)
+
 	if projectConfig.RequiredVersions != nil && projectConfig.RequiredVersions.Azd != nil {
 		supportedRange, err := semver.ParseRange(*projectConfig.RequiredVersions.Azd)
 		if err != nil {
@@ -103,6 +105,48 @@ func Parse(ctx context.Context, yamlContent string) (*ProjectConfig, error) {
 			return nil, fmt.Errorf("parsing service %s: %w", svc.Name, err)
 		}
 
+		if _, err := svc.DeployTimeout(); err != nil {
+			return nil, fmt.Errorf("parsing service %s: %w", svc.Name, err)
+		}
+
+		for hookName, hook := range svc.Hooks {
+			if hook != nil && hook.RunsForEachService() {
+				return nil, fmt.Errorf("parsing service %s: hook %s: %w", svc.Name, hookName, ext.ErrForEachServiceNotSupported)
+			}
+		}
+
+		if len(svc.EnabledFor) > 0 && len(svc.DisabledFor) > 0 {
+			return nil, fmt.Errorf("parsing service %s: enabledFor and disabledFor can't both be set", svc.Name)
+		}
+
+		if svc.DotNet != (DotNetOptions{}) && !svc.MatchesLanguage(ServiceLanguageDotNet) {
+			return nil, fmt.Errorf("parsing service %s: dotnet is only supported by dotnet services", svc.Name)
+		}
+
+		if err := svc.DotNet.Validate(); err != nil {
+			return nil, fmt.Errorf("parsing service %s: %w", svc.Name, err)
+		}
+
+		if !svc.Java.isEmpty() && svc.Language != ServiceLanguageJava {
+			return nil, fmt.Errorf("parsing service %s: java is only supported by java services", svc.Name)
+		}
+
+		if err := svc.Java.Validate(); err != nil {
+			return nil, fmt.Errorf("parsing service %s: %w", svc.Name, err)
+		}
+
+		if err := svc.ContainerApp.Validate(); err != nil {
+			return nil, fmt.Errorf("parsing service %s: %w", svc.Name, err)
+		}
+
+		if err := svc.ValidateImage(); err != nil {
+			return nil, fmt.Errorf("parsing service %s: %w", svc.Name, err)
+		}
+
+		if err := svc.ValidateBuild(); err != nil {
+			return nil, fmt.Errorf("parsing service %s: %w", svc.Name, err)
+		}
+
 		// TODO: Move parsing/validation requirements for service targets into their respective components.
 		// When working within container based applications users may be using external/pre-built images instead of source
 		// In this case it is valid to have not specified a language but would be required to specify a source image
@@ -114,6 +158,20 @@ func Parse(ctx context.Context, yamlContent string) (*ProjectConfig, error) {
 	return &projectConfig, nil
 }
 
+// ConfigError is returned when the project file is invalid, like a malformed azure.yaml or an unsupported service
+// configuration.
+type ConfigError struct {
+	Err error
+}
+
+func (e *ConfigError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
 // Load hydrates the azure.yaml configuring into an viewable structure
 // This does not evaluate any tooling
 func Load(ctx context.Context, projectFilePath string) (*ProjectConfig, error) {
@@ -127,7 +185,7 @@ func Load(ctx context.Context, projectFilePath string) (*ProjectConfig, error) {
 
 	projectConfig, err := Parse(ctx, yaml)
 	if err != nil {
-		return nil, fmt.Errorf("parsing project file: %w", err)
+		return nil, &ConfigError{Err: fmt.Errorf("parsing project file: %w", err)}
 	}
 
 	if projectConfig.Metadata != nil && projectConfig.Metadata.Template != "" {
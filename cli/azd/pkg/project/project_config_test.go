@@ -9,6 +9,7 @@ import (
 	"github.com/MakeNowJust/heredoc/v2"
 	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/stretchr/testify/require"
 )
@@ -122,7 +123,7 @@ services:
 	service := projectConfig.Services["web"]
 
 	require.Equal(t, "./Dockerfile.dev", service.Docker.Path)
-	require.Equal(t, "../", service.Docker.Context)
+	require.Equal(t, osutil.NewExpandableString("../"), service.Docker.Context)
 	require.Equal(t, []string{"foo", "bar"}, service.Docker.BuildArgs)
 }
 
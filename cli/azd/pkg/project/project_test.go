@@ -5,13 +5,18 @@ package project
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/azure/azure-dev/cli/azd/pkg/azure"
 	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/ext"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/warnings"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/azure/azure-dev/cli/azd/test/mocks/mockarmresources"
 	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
@@ -259,6 +264,16 @@ func Test_Invalid_Project_File(t *testing.T) {
 	}
 }
 
+func Test_Load_InvalidProjectFile(t *testing.T) {
+	projectFile := filepath.Join(t.TempDir(), "azure.yaml")
+	require.NoError(t, os.WriteFile(projectFile, []byte("name: [test-proj"), osutil.PermissionFile))
+
+	_, err := Load(context.Background(), projectFile)
+	var configErr *ConfigError
+	require.ErrorAs(t, err, &configErr)
+	require.ErrorContains(t, err, "parsing project file")
+}
+
 func TestMinimalYaml(t *testing.T) {
 	prj := &ProjectConfig{
 		Name:     "minimal",
@@ -311,3 +326,457 @@ func TestMinimalYaml(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceEnabledForAndDisabledFor(t *testing.T) {
+	const testProj = `
+name: test-proj
+services:
+  api:
+    project: src/api
+    language: js
+    host: appservice
+    enabledFor: [dev]
+    disabledFor: [prod]
+`
+
+	_, err := Parse(context.Background(), testProj)
+	require.EqualError(t, err, "parsing service api: enabledFor and disabledFor can't both be set")
+}
+
+func TestServiceHookForEachService(t *testing.T) {
+	const testProj = `
+name: test-proj
+services:
+  api:
+    project: src/api
+    language: js
+    host: appservice
+    hooks:
+      prebuild:
+        posix:
+          run: ./lint.sh
+          forEachService: true
+`
+
+	_, err := Parse(context.Background(), testProj)
+	require.ErrorIs(t, err, ext.ErrForEachServiceNotSupported)
+	require.ErrorContains(t, err, "parsing service api: hook prebuild")
+}
+
+func TestServiceOutputEnv(t *testing.T) {
+	const testProj = `
+name: test-proj
+services:
+  api:
+    project: src/api
+    language: js
+    host: appservice
+    outputEnv:
+      DATABASE_URL: SQL_CONN
+`
+
+	projectConfig, err := Parse(context.Background(), testProj)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"DATABASE_URL": "SQL_CONN"}, projectConfig.Services["api"].OutputEnv)
+}
+
+func TestParseDeprecatedFields(t *testing.T) {
+	const testProj = `
+name: test-proj
+services:
+  api:
+    project: src/api
+    language: js
+    host: appservice
+    module: app/api
+  web:
+    project: src/web
+    language: js
+    host: appservice
+`
+
+	sink := warnings.NewSink()
+	_, err := Parse(warnings.WithSink(context.Background(), sink), testProj)
+	require.NoError(t, err)
+
+	require.Equal(t, []warnings.Warning{
+		{
+			Code: warnings.CodeDeprecatedField,
+			Message: "'services.api.module' in azure.yaml is deprecated and will be removed in the next major version " +
+				"of azd: it's ignored, the infrastructure of all the services is provisioned from the module of the " +
+				"project. Use 'infra.module' instead.",
+		},
+	}, sink.Warnings())
+
+	// Projects without deprecated fields parse without warnings
+	sink = warnings.NewSink()
+	_, err = Parse(warnings.WithSink(context.Background(), sink), `
+name: test-proj
+infra:
+  module: app/main
+`)
+	require.NoError(t, err)
+	require.Empty(t, sink.Warnings())
+}
+
+func TestParseServiceImage(t *testing.T) {
+	parse := func(service string) (*ProjectConfig, error) {
+		return Parse(context.Background(), `
+name: test-proj
+services:
+  web:
+    host: containerapp
+`+service)
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		projectConfig, err := parse("    image: docker.io/nginx:1.25\n    docker:\n      push: false\n")
+		require.NoError(t, err)
+
+		service := projectConfig.Services["web"]
+		require.Equal(t, "docker.io/nginx:1.25", service.Image)
+		require.False(t, *service.Docker.Push)
+	})
+
+	tests := []struct {
+		name        string
+		service     string
+		expectedErr string
+	}{
+		{
+			name:        "InvalidImage",
+			service:     "    image: nginx:1.25:latest\n",
+			expectedErr: "invalid image 'nginx:1.25:latest', expected a reference like 'docker.io/nginx:1.25'",
+		},
+		{
+			name:        "WithProject",
+			service:     "    image: nginx\n    project: src/web\n",
+			expectedErr: "image can't be set with project, which build the image from source",
+		},
+		{
+			name:        "WithBuildOptions",
+			service:     "    image: nginx\n    docker:\n      path: ./Dockerfile\n      buildArgs:\n        - A=1\n",
+			expectedErr: "image can't be set with docker.path, docker.buildArgs, which build the image from source",
+		},
+		{
+			name:        "PushWithoutImage",
+			service:     "    project: src/web\n    language: js\n    docker:\n      push: false\n",
+			expectedErr: "docker.push can only be disabled when deploying an existing image",
+		},
+		{
+			name:        "SignWithoutPush",
+			service:     "    image: nginx\n    docker:\n      push: false\n      sign:\n        key: cosign.key\n",
+			expectedErr: "docker.sign requires the image to be pushed to the container registry, docker.push is false",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse(tt.service)
+			require.EqualError(t, err, "parsing service web: "+tt.expectedErr)
+		})
+	}
+}
+
+func TestParseServiceBuild(t *testing.T) {
+	parse := func(host string, build string) (*ProjectConfig, error) {
+		return Parse(context.Background(), `
+name: test-proj
+services:
+  api:
+    project: src/api
+    language: js
+    host: `+host+`
+    build:
+`+build)
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		projectConfig, err := parse("appservice", "      run: npm run build:prod\n      cwd: ./web\n")
+		require.NoError(t, err)
+
+		build := projectConfig.Services["api"].Build
+		require.Equal(t, "npm run build:prod", build.Run.MustEnvsubst(nil))
+		require.Equal(t, "./web", build.Cwd)
+		require.Equal(t, BuildModeReplace, build.mode())
+	})
+
+	tests := []struct {
+		name        string
+		host        string
+		build       string
+		expectedErr string
+	}{
+		{
+			name:        "MissingRun",
+			host:        "appservice",
+			build:       "      cwd: ./web\n",
+			expectedErr: "build.run is required",
+		},
+		{
+			name:        "InvalidMode",
+			host:        "appservice",
+			build:       "      run: make\n      mode: instead\n",
+			expectedErr: "invalid build.mode 'instead', expected 'replace', 'before' or 'after'",
+		},
+		{
+			name:  "ReplaceContainer",
+			host:  "containerapp",
+			build: "      run: make\n",
+			expectedErr: "build.mode 'replace' isn't supported by services built as container images, " +
+				"use 'before' or 'after'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse(tt.host, tt.build)
+			require.EqualError(t, err, "parsing service api: "+tt.expectedErr)
+		})
+	}
+}
+
+func TestParseDotNetOptions(t *testing.T) {
+	parse := func(language string, dotnet string) (*ProjectConfig, error) {
+		return Parse(context.Background(), `
+name: test-proj
+services:
+  api:
+    project: src/api
+    language: `+language+`
+    host: appservice
+    dotnet:
+`+dotnet)
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		projectConfig, err := parse("csharp", "      configuration: Debug\n      runtime: linux-x64\n      selfContained: true\n")
+		require.NoError(t, err)
+
+		dotnetOptions := projectConfig.Services["api"].DotNet
+		require.Equal(t, "Debug", dotnetOptions.configuration())
+		require.Equal(t, "linux-x64", dotnetOptions.Runtime)
+		require.True(t, *dotnetOptions.SelfContained)
+		require.False(t, dotnetOptions.Trimmed)
+	})
+
+	tests := []struct {
+		name        string
+		language    string
+		dotnet      string
+		expectedErr string
+	}{
+		{
+			name:        "InvalidRuntime",
+			language:    "dotnet",
+			dotnet:      "      runtime: linux_x64\n",
+			expectedErr: "invalid dotnet.runtime 'linux_x64', expected a runtime identifier like 'linux-x64' or 'win-arm64'",
+		},
+		{
+			name:        "NotDotNet",
+			language:    "js",
+			dotnet:      "      configuration: Debug\n",
+			expectedErr: "dotnet is only supported by dotnet services",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse(tt.language, tt.dotnet)
+			require.EqualError(t, err, "parsing service api: "+tt.expectedErr)
+		})
+	}
+}
+
+func TestParseJavaOptions(t *testing.T) {
+	parse := func(language string, java string) (*ProjectConfig, error) {
+		return Parse(context.Background(), `
+name: test-proj
+services:
+  api:
+    project: src/api
+    language: `+language+`
+    host: appservice
+    java:
+`+java)
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		projectConfig, err := parse("java", "      profiles: [prod]\n      properties:\n        skipITs: \"true\"\n")
+		require.NoError(t, err)
+
+		javaOptions := projectConfig.Services["api"].Java
+		require.Equal(t, []string{"prod"}, javaOptions.Profiles)
+		require.Equal(t, []string{"-Pprod", "-DskipITs=true"}, javaOptions.packageArgs())
+	})
+
+	tests := []struct {
+		name        string
+		language    string
+		java        string
+		expectedErr string
+	}{
+		{
+			name:        "InvalidBuildTool",
+			language:    "java",
+			java:        "      buildTool: ant\n",
+			expectedErr: "invalid java.buildTool 'ant', expected 'maven' or 'gradle'",
+		},
+		{
+			name:        "GradleProfiles",
+			language:    "java",
+			java:        "      buildTool: gradle\n      profiles: [prod]\n",
+			expectedErr: "java.profiles are only supported by maven, use java.properties with gradle",
+		},
+		{
+			name:        "NotJava",
+			language:    "python",
+			java:        "      buildTool: maven\n",
+			expectedErr: "java is only supported by java services",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse(tt.language, tt.java)
+			require.EqualError(t, err, "parsing service api: "+tt.expectedErr)
+		})
+	}
+}
+
+func TestParseContainerAppOptions(t *testing.T) {
+	parse := func(containerApp string) (*ProjectConfig, error) {
+		return Parse(context.Background(), `
+name: test-proj
+services:
+  api:
+    project: src/api
+    language: js
+    host: containerapp
+    containerapp:
+`+containerApp)
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		projectConfig, err := parse(`
+      ingress:
+        external: false
+        targetPort: 8080
+      scale:
+        minReplicas: 0
+        maxReplicas: 10
+        concurrentRequests: 50
+      dapr:
+        enabled: true
+        appPort: 3000
+      containers:
+        - name: logger
+          project: ./logger
+          env:
+            LEVEL: info
+          resources:
+            cpu: 0.25
+            memory: 0.5Gi
+        - name: proxy
+          image: docker.io/envoyproxy/envoy:v1.30
+`)
+		require.NoError(t, err)
+
+		options := projectConfig.Services["api"].ContainerApp
+		require.False(t, *options.Ingress.External)
+		require.Equal(t, int32(8080), *options.Ingress.TargetPort)
+		require.Equal(t, int32(0), *options.Scale.MinReplicas)
+		require.Equal(t, int32(10), *options.Scale.MaxReplicas)
+		require.Equal(t, int32(50), *options.Scale.ConcurrentRequests)
+		require.True(t, *options.Dapr.Enabled)
+		require.Equal(t, int32(3000), *options.Dapr.AppPort)
+		require.Len(t, options.Containers, 2)
+		require.Equal(t, "./logger", options.Containers[0].Project)
+		require.Equal(t, &ContainerAppResources{Cpu: 0.25, Memory: "0.5Gi"}, options.Containers[0].Resources)
+		require.Equal(t, "docker.io/envoyproxy/envoy:v1.30", options.Containers[1].Image.MustEnvsubst(nil))
+	})
+
+	tests := []struct {
+		name         string
+		containerApp string
+		expectedErr  string
+	}{
+		{
+			name:         "TargetPort",
+			containerApp: "      ingress:\n        targetPort: 70000\n",
+			expectedErr:  "containerapp.ingress.targetPort must be between 1 and 65535, got 70000",
+		},
+		{
+			name:         "MinReplicas",
+			containerApp: "      scale:\n        minReplicas: -1\n",
+			expectedErr:  "containerapp.scale.minReplicas must be between 0 and 1000, got -1",
+		},
+		{
+			name:         "MaxReplicas",
+			containerApp: "      scale:\n        maxReplicas: 0\n",
+			expectedErr:  "containerapp.scale.maxReplicas must be between 1 and 1000, got 0",
+		},
+		{
+			name:         "MinGreaterThanMax",
+			containerApp: "      scale:\n        minReplicas: 5\n        maxReplicas: 2\n",
+			expectedErr:  "containerapp.scale.minReplicas (5) can't be greater than maxReplicas (2)",
+		},
+		{
+			name:         "DaprAppPortRequired",
+			containerApp: "      dapr:\n        enabled: true\n        appId: orders\n",
+			expectedErr:  "containerapp.dapr.appPort is required when Dapr is enabled",
+		},
+		{
+			name:         "DaprAppPortRequiredByDefault",
+			containerApp: "      dapr: {}\n",
+			expectedErr:  "containerapp.dapr.appPort is required when Dapr is enabled",
+		},
+		{
+			name:         "DaprAppPortRequiredWithAppId",
+			containerApp: "      dapr:\n        appId: orders\n",
+			expectedErr:  "containerapp.dapr.appPort is required when Dapr is enabled",
+		},
+		{
+			name:         "DaprAppPort",
+			containerApp: "      dapr:\n        enabled: true\n        appPort: 0\n",
+			expectedErr:  "containerapp.dapr.appPort must be between 1 and 65535, got 0",
+		},
+		{
+			name:         "ConcurrentRequests",
+			containerApp: "      scale:\n        concurrentRequests: 0\n",
+			expectedErr:  "containerapp.scale.concurrentRequests must be at least 1, got 0",
+		},
+		{
+			name:         "ContainerName",
+			containerApp: "      containers:\n        - image: nginx\n",
+			expectedErr:  "containerapp.containers[0].name is required",
+		},
+		{
+			name:         "ContainerNameDuplicate",
+			containerApp: "      containers:\n        - name: a\n          image: nginx\n        - name: a\n          image: nginx\n",
+			expectedErr:  "containerapp.containers has more than one container named 'a'",
+		},
+		{
+			name:         "ContainerImageAndProject",
+			containerApp: "      containers:\n        - name: a\n          image: nginx\n          project: ./a\n",
+			expectedErr:  "containerapp.containers 'a' must have either an 'image' or a 'project' to build it from",
+		},
+		{
+			name:         "ContainerImageOrProject",
+			containerApp: "      containers:\n        - name: a\n",
+			expectedErr:  "containerapp.containers 'a' must have either an 'image' or a 'project' to build it from",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse(tt.containerApp)
+			require.EqualError(t, err, "parsing service api: "+tt.expectedErr)
+		})
+	}
+
+	t.Run("DaprDisabled", func(t *testing.T) {
+		_, err := parse("      dapr:\n        enabled: false\n")
+		require.NoError(t, err)
+	})
+}
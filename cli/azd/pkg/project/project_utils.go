@@ -4,18 +4,20 @@
 package project
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"time"
 
+	"github.com/azure/azure-dev/cli/azd/pkg/cleanup"
 	"github.com/azure/azure-dev/cli/azd/pkg/rzip"
 	"github.com/otiai10/copy"
 )
 
 // CreateDeployableZip creates a zip file of a folder, recursively.
 // Returns the path to the created zip file or an error if it fails.
-func createDeployableZip(projectName string, appName string, path string) (string, error) {
+func createDeployableZip(ctx context.Context, projectName string, appName string, path string) (string, error) {
 	// TODO: should probably avoid picking up files that weren't meant to be deployed (ie, local .env files, etc..)
 	filePath := filepath.Join(os.TempDir(), fmt.Sprintf("%s-%s-azddeploy-%d.zip", projectName, appName, time.Now().Unix()))
 	zipFile, err := os.Create(filePath)
@@ -23,6 +25,9 @@ func createDeployableZip(projectName string, appName string, path string) (strin
 		return "", fmt.Errorf("failed when creating zip package to deploy %s: %w", appName, err)
 	}
 
+	// The package is removed once deployed, or when the operation is interrupted before
+	cleanup.RemoveOnCancel(ctx, filePath)
+
 	if err := rzip.CreateFromDirectory(path, zipFile); err != nil {
 		// if we fail here just do our best to close things out and cleanup
 		zipFile.Close()
@@ -0,0 +1,162 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+)
+
+// BuildMode sets when the custom build command of a service runs, relative to the build of its language.
+type BuildMode string
+
+const (
+	// BuildModeReplace runs the custom build command instead of the build of the language.
+	BuildModeReplace BuildMode = "replace"
+	// BuildModeBefore runs the custom build command before the build of the language.
+	BuildModeBefore BuildMode = "before"
+	// BuildModeAfter runs the custom build command after the build of the language.
+	BuildModeAfter BuildMode = "after"
+)
+
+// BuildOptions are the options of the custom build command of a service, for builds the defaults of its language don't
+// cover.
+type BuildOptions struct {
+	// The command building the service, run with the shell, like 'make dist'. Environment variables are expanded
+	Run osutil.ExpandableString `yaml:"run"`
+	// The directory the command runs in, relative to the service path. Defaults to the service path
+	Cwd string `yaml:"cwd,omitempty"`
+	// When the command runs, relative to the build of the language. Defaults to 'replace'
+	Mode BuildMode `yaml:"mode,omitempty"`
+}
+
+// ValidateBuild checks the custom build command of the service. Services built as container images can't replace their
+// build, since the image is built by the build of the language.
+func (sc *ServiceConfig) ValidateBuild() error {
+	if sc.Build == nil {
+		return nil
+	}
+
+	if sc.Build.Run.Empty() {
+		return errors.New("build.run is required")
+	}
+
+	switch sc.Build.mode() {
+	case BuildModeReplace:
+		if sc.Host.RequiresContainer() || sc.Language == ServiceLanguageDocker {
+			return fmt.Errorf(
+				"build.mode '%s' isn't supported by services built as container images, use '%s' or '%s'",
+				BuildModeReplace, BuildModeBefore, BuildModeAfter)
+		}
+	case BuildModeBefore, BuildModeAfter:
+	default:
+		return fmt.Errorf(
+			"invalid build.mode '%s', expected '%s', '%s' or '%s'",
+			sc.Build.Mode, BuildModeReplace, BuildModeBefore, BuildModeAfter)
+	}
+
+	return nil
+}
+
+func (o *BuildOptions) mode() BuildMode {
+	if o.Mode == "" {
+		return BuildModeReplace
+	}
+
+	return o.Mode
+}
+
+// customBuild builds the service with its custom build command, before, after or instead of the build of the language
+// by the framework service.
+func customBuild(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	frameworkService FrameworkService,
+	restoreOutput *ServiceRestoreResult,
+	env *environment.Environment,
+	commandRunner exec.CommandRunner,
+	console input.Console,
+) *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress]) {
+			runBuild := func() error {
+				task.SetProgress(NewServicePhaseProgress(ProgressPhaseBuilding, "Running build command"))
+				return runBuildCommand(ctx, serviceConfig, env, commandRunner, console)
+			}
+
+			mode := serviceConfig.Build.mode()
+			if mode == BuildModeReplace || mode == BuildModeBefore {
+				if err := runBuild(); err != nil {
+					task.SetError(err)
+					return
+				}
+			}
+
+			buildResult := &ServiceBuildResult{
+				Restore:         restoreOutput,
+				BuildOutputPath: resolvePathFrom(serviceConfig.Path(), serviceConfig.OutputPath),
+			}
+
+			if mode != BuildModeReplace {
+				frameworkBuildTask := frameworkService.Build(ctx, serviceConfig, restoreOutput)
+				syncProgress(task, frameworkBuildTask.Progress())
+
+				frameworkBuildResult, err := frameworkBuildTask.Await()
+				if err != nil {
+					task.SetError(err)
+					return
+				}
+
+				buildResult = frameworkBuildResult
+			}
+
+			if mode == BuildModeAfter {
+				if err := runBuild(); err != nil {
+					task.SetError(err)
+					return
+				}
+			}
+
+			task.SetResult(buildResult)
+		},
+	)
+}
+
+// runBuildCommand runs the custom build command of the service with the shell, streaming its output to the console.
+func runBuildCommand(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	env *environment.Environment,
+	commandRunner exec.CommandRunner,
+	console input.Console,
+) error {
+	command, err := serviceConfig.Build.Run.Envsubst(env.Getenv)
+	if err != nil {
+		return fmt.Errorf("expanding build command: %w", err)
+	}
+
+	previewerWriter := console.ShowPreviewer(ctx,
+		&input.ShowPreviewerOptions{
+			Prefix:       "  ",
+			MaxLineCount: 8,
+			Title:        "Build Output",
+		})
+	runArgs := exec.NewRunArgs("", command).
+		WithCwd(resolvePathFrom(serviceConfig.Path(), serviceConfig.Build.Cwd)).
+		WithEnv(env.Environ()).
+		WithShell(true).
+		WithStdOut(previewerWriter).
+		WithStdErr(previewerWriter)
+	_, err = commandRunner.Run(ctx, runArgs)
+	console.StopPreviewer(ctx, false)
+	if err != nil {
+		return fmt.Errorf("running build command '%s': %w", command, err)
+	}
+
+	return nil
+}
@@ -1,12 +1,19 @@
 package project
 
 import (
+	"errors"
+	"fmt"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/apphost"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/ext"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
 )
 
 type ServiceConfig struct {
@@ -24,18 +31,42 @@ type ServiceConfig struct {
 	Language ServiceLanguageKind `yaml:"language"`
 	// The output path for build artifacts
 	OutputPath string `yaml:"dist,omitempty"`
-	// The source image to use for container based applications
+	// The existing image deployed by container based applications instead of an image built from source, like
+	// 'docker.io/nginx:1.25'. Can't be set with the options building the image, like project or docker.path
 	Image string `yaml:"image,omitempty"`
+	// The optional custom command building the service, instead of or in addition to the build of its language
+	Build *BuildOptions `yaml:"build,omitempty"`
 	// The optional docker options for configuring the output image
 	Docker DockerProjectOptions `yaml:"docker,omitempty"`
 	// The optional K8S / AKS options
 	K8s AksOptions `yaml:"k8s,omitempty"`
+	// The optional options of the build and publish of dotnet services
+	DotNet DotNetOptions `yaml:"dotnet,omitempty"`
+	// The optional options of the build and package of java services
+	Java JavaOptions `yaml:"java,omitempty"`
 	// The optional Azure Spring Apps options
 	Spring SpringOptions `yaml:"spring,omitempty"`
+	// The optional Azure Container Apps options
+	ContainerApp ContainerAppOptions `yaml:"containerapp,omitempty"`
+	// The optional deployment slot options for App Service and Function App targets
+	Slot SlotOptions `yaml:"slot,omitempty"`
+	// The optional requests sent to App Service and Function App targets once they are deployed
+	WarmUp WarmUpOptions `yaml:"warmUp,omitempty"`
 	// The infrastructure provisioning configuration
 	Infra provisioning.Options `yaml:"infra,omitempty"`
 	// Hook configuration for service
 	Hooks map[string]*ext.HookConfig `yaml:"hooks,omitempty"`
+	// The options passed to the service target plugin providing the host of the service
+	Config map[string]any `yaml:"config,omitempty"`
+	// The maximum duration of the deployment of the service, e.g. '10m'. No limit when empty
+	Timeout string `yaml:"timeout,omitempty"`
+	// The names of the environments the service is enabled for. The service is enabled for all environments when empty
+	EnabledFor []string `yaml:"enabledFor,omitempty"`
+	// The names of the environments the service is disabled for, like a debug sidecar disabled for 'prod'
+	DisabledFor []string `yaml:"disabledFor,omitempty"`
+	// The provisioning outputs set as environment variables of the service at deploy, keyed by the variable name,
+	// e.g. 'DATABASE_URL: SQL_CONN'. Ignored by the hosts which don't support it, see outputEnvHosts
+	OutputEnv map[string]string `yaml:"outputEnv,omitempty"`
 	// Options specific to the DotNetContainerApp target. These are set by the importer and
 	// can not be controlled via the project file today.
 	DotNetContainerApp *DotNetContainerAppOptions `yaml:"-,omitempty"`
@@ -49,6 +80,126 @@ type DotNetContainerAppOptions struct {
 	ProjectPath string
 }
 
+// EnabledForEnvironment returns whether the service is enabled for the environment, according to its enabledFor and
+// disabledFor lists, and the reason when it isn't. Services are enabled when the environment isn't known.
+func (sc *ServiceConfig) EnabledForEnvironment(envName string) (bool, string) {
+	if envName == "" {
+		return true, ""
+	}
+
+	if len(sc.EnabledFor) > 0 && !slices.Contains(sc.EnabledFor, envName) {
+		return false, fmt.Sprintf("only enabled for %s", strings.Join(sc.EnabledFor, ", "))
+	}
+
+	if slices.Contains(sc.DisabledFor, envName) {
+		return false, fmt.Sprintf("disabled for %s", envName)
+	}
+
+	return true, ""
+}
+
+// MatchesLanguage returns whether the service is written in the language. The dotnet language also matches the C# and
+// F# services.
+func (sc *ServiceConfig) MatchesLanguage(language ServiceLanguageKind) bool {
+	if language == ServiceLanguageDotNet {
+		return sc.Language == ServiceLanguageDotNet ||
+			sc.Language == ServiceLanguageCsharp ||
+			sc.Language == ServiceLanguageFsharp
+	}
+
+	return sc.Language == language
+}
+
+// outputEnvHosts are the hosts which set the environment variables mapped by outputEnv when the service is deployed,
+// as app settings of web and function apps, and environment variables of container apps and Spring apps.
+var outputEnvHosts = []ServiceTargetKind{AppServiceTarget, AzureFunctionTarget, ContainerAppTarget, SpringAppTarget}
+
+// ResolveOutputEnv returns the environment variables of the service mapped from provisioning outputs by outputEnv,
+// read from the state of the environment. References to other values are expanded when AZD_ENV_EXPAND_REFERENCES is
+// enabled.
+func (sc *ServiceConfig) ResolveOutputEnv(env *environment.Environment) (map[string]string, error) {
+	if len(sc.OutputEnv) == 0 {
+		return nil, nil
+	}
+
+	vars := make(map[string]string, len(sc.OutputEnv))
+	for name, output := range sc.OutputEnv {
+		value, has := env.LookupEnv(output)
+		if !has {
+			return nil, fmt.Errorf(
+				"output '%s' mapped to environment variable '%s' isn't set, run `azd provision` to create it", output, name)
+		}
+
+		vars[name] = value
+	}
+
+	return vars, nil
+}
+
+// ValidateImage checks that the existing image deployed by the service is a valid image reference, and that the
+// service doesn't also configure how to build its image.
+func (sc *ServiceConfig) ValidateImage() error {
+	if sc.Image == "" {
+		if sc.Docker.Push != nil && !*sc.Docker.Push {
+			return errors.New("docker.push can only be disabled when deploying an existing image")
+		}
+
+		return nil
+	}
+
+	if _, err := docker.ParseContainerImage(sc.Image); err != nil || strings.ContainsAny(sc.Image, " \t") {
+		return fmt.Errorf("invalid image '%s', expected a reference like 'docker.io/nginx:1.25'", sc.Image)
+	}
+
+	buildOptions := []string{}
+	if sc.RelativePath != "" {
+		buildOptions = append(buildOptions, "project")
+	}
+	if sc.Language != ServiceLanguageNone && sc.Language != ServiceLanguageDocker {
+		buildOptions = append(buildOptions, "language")
+	}
+	if sc.Docker.Path != "" {
+		buildOptions = append(buildOptions, "docker.path")
+	}
+	if !sc.Docker.Dockerfile.Empty() {
+		buildOptions = append(buildOptions, "docker.dockerfile")
+	}
+	if !sc.Docker.Context.Empty() {
+		buildOptions = append(buildOptions, "docker.context")
+	}
+	if sc.Docker.Target != "" {
+		buildOptions = append(buildOptions, "docker.target")
+	}
+	if len(sc.Docker.BuildArgs) > 0 {
+		buildOptions = append(buildOptions, "docker.buildArgs")
+	}
+
+	if len(buildOptions) > 0 {
+		return fmt.Errorf(
+			"image can't be set with %s, which build the image from source", strings.Join(buildOptions, ", "))
+	}
+
+	if sc.Docker.Sign != nil && !sc.Docker.pushImage() {
+		return errors.New("docker.sign requires the image to be pushed to the container registry, docker.push is false")
+	}
+
+	return nil
+}
+
+// DeployTimeout returns the maximum duration of the deployment of the service, or zero when there is no limit.
+func (sc *ServiceConfig) DeployTimeout() (time.Duration, error) {
+	if sc.Timeout == "" {
+		return 0, nil
+	}
+
+	timeout, err := time.ParseDuration(sc.Timeout)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid timeout '%s', expected a positive duration like '90s' or '10m'", sc.Timeout)
+	}
+
+	return timeout, nil
+}
+
 // Path returns the fully qualified path to the project
 func (sc *ServiceConfig) Path() string {
 	if filepath.IsAbs(sc.RelativePath) {
@@ -56,3 +207,16 @@ func (sc *ServiceConfig) Path() string {
 	}
 	return filepath.Join(sc.Project.Path, sc.RelativePath)
 }
+
+// HookServices returns the services which project hooks configured with forEachService run for.
+func HookServices(services []*ServiceConfig) []ext.HookService {
+	hookServices := make([]ext.HookService, 0, len(services))
+	for _, service := range services {
+		hookServices = append(hookServices, ext.HookService{
+			Name: service.Name,
+			Path: service.Path(),
+		})
+	}
+
+	return hookServices
+}
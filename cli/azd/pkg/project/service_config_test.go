@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/ext"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/stretchr/testify/require"
@@ -224,3 +225,37 @@ func createTestServiceConfig(path string, host ServiceTargetKind, language Servi
 		EventDispatcher: ext.NewEventDispatcher[ServiceLifecycleEventArgs](),
 	}
 }
+
+func TestServiceConfigResolveOutputEnv(t *testing.T) {
+	serviceConfig := &ServiceConfig{
+		Name:      "api",
+		OutputEnv: map[string]string{"DATABASE_URL": "SQL_CONN"},
+	}
+
+	t.Run("Mapped", func(t *testing.T) {
+		env := environment.NewWithValues("dev", map[string]string{"SQL_CONN": "Server=sql.example.com"})
+
+		vars, err := serviceConfig.ResolveOutputEnv(env)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"DATABASE_URL": "Server=sql.example.com"}, vars)
+	})
+
+	t.Run("Expanded", func(t *testing.T) {
+		env := environment.NewWithValues("dev", map[string]string{
+			environment.ExpandReferencesEnvVarName: "true",
+			"SQL_HOST":                             "sql.example.com",
+			"SQL_CONN":                             "Server=${SQL_HOST}",
+		})
+
+		vars, err := serviceConfig.ResolveOutputEnv(env)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"DATABASE_URL": "Server=sql.example.com"}, vars)
+	})
+
+	t.Run("NotProvisioned", func(t *testing.T) {
+		env := environment.NewWithValues("dev", nil)
+
+		_, err := serviceConfig.ResolveOutputEnv(env)
+		require.ErrorContains(t, err, "output 'SQL_CONN' mapped to environment variable 'DATABASE_URL' isn't set")
+	})
+}
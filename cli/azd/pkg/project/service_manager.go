@@ -9,15 +9,22 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/alpha"
 	"github.com/azure/azure-dev/cli/azd/pkg/async"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/ext"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/ioc"
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/rzip"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 )
 
@@ -96,6 +103,9 @@ type ServiceManager interface {
 	// The service target is responsible for packaging & deploying the service app code
 	// to the destination Azure resource
 	GetServiceTarget(ctx context.Context, serviceConfig *ServiceConfig) (ServiceTarget, error)
+
+	// Gets the Azure resource the specified service config is deployed to
+	GetTargetResource(ctx context.Context, serviceConfig *ServiceConfig) (*environment.TargetResource, error)
 }
 
 // ServiceOperationCache is an alias to map used for internal caching of service operation results
@@ -103,12 +113,18 @@ type ServiceManager interface {
 // The ServiceOperationCache is used as a singleton cache for all service manager instances
 type ServiceOperationCache map[string]any
 
+// operationCacheMu guards the singleton ServiceOperationCache, since services may be restored concurrently
+var operationCacheMu sync.RWMutex
+
 type serviceManager struct {
 	env                 *environment.Environment
 	resourceManager     ResourceManager
 	serviceLocator      ioc.ServiceLocator
 	operationCache      ServiceOperationCache
 	alphaFeatureManager *alpha.FeatureManager
+	targetRegistry      *ServiceTargetRegistry
+	commandRunner       exec.CommandRunner
+	console             input.Console
 	initialized         map[*ServiceConfig]map[any]bool
 }
 
@@ -119,6 +135,9 @@ func NewServiceManager(
 	serviceLocator ioc.ServiceLocator,
 	operationCache ServiceOperationCache,
 	alphaFeatureManager *alpha.FeatureManager,
+	targetRegistry *ServiceTargetRegistry,
+	commandRunner exec.CommandRunner,
+	console input.Console,
 ) ServiceManager {
 	return &serviceManager{
 		env:                 env,
@@ -126,6 +145,9 @@ func NewServiceManager(
 		serviceLocator:      serviceLocator,
 		operationCache:      operationCache,
 		alphaFeatureManager: alphaFeatureManager,
+		targetRegistry:      targetRegistry,
+		commandRunner:       commandRunner,
+		console:             console,
 		initialized:         map[*ServiceConfig]map[any]bool{},
 	}
 }
@@ -199,6 +221,11 @@ func (sm *serviceManager) Restore(
 			return
 		}
 
+		if err := ensureTools(ctx, "restoring", serviceConfig, frameworkService.RequiredExternalTools(ctx)...); err != nil {
+			task.SetError(err)
+			return
+		}
+
 		restoreResult, err := runCommand(
 			ctx,
 			task,
@@ -246,12 +273,23 @@ func (sm *serviceManager) Build(
 			return
 		}
 
+		// Missing tools, like docker for a container build, are reported before the build starts
+		if err := ensureTools(ctx, "building", serviceConfig, frameworkService.RequiredExternalTools(ctx)...); err != nil {
+			task.SetError(err)
+			return
+		}
+
 		buildResult, err := runCommand(
 			ctx,
 			task,
 			ServiceEventBuild,
 			serviceConfig,
 			func() *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress] {
+				if serviceConfig.Build != nil {
+					return customBuild(
+						ctx, serviceConfig, frameworkService, restoreOutput, sm.env, sm.commandRunner, sm.console)
+				}
+
 				return frameworkService.Build(ctx, serviceConfig, restoreOutput)
 			},
 		)
@@ -305,6 +343,12 @@ func (sm *serviceManager) Package(
 			return
 		}
 
+		packageTools := append(frameworkService.RequiredExternalTools(ctx), serviceTarget.RequiredExternalTools(ctx)...)
+		if err := ensureTools(ctx, "packaging", serviceConfig, packageTools...); err != nil {
+			task.SetError(err)
+			return
+		}
+
 		eventArgs := ServiceLifecycleEventArgs{
 			Project: serviceConfig.Project,
 			Service: serviceConfig,
@@ -333,9 +377,9 @@ func (sm *serviceManager) Package(
 
 		buildResult := &ServiceBuildResult{}
 
-		// When a previous build result was not provided, and we require it
+		// When a previous build result was not provided, and we require it or the service has a custom build command
 		// Then we need to build the project
-		if frameworkRequirements.Package.RequireBuild && !hasBuildOutput {
+		if (frameworkRequirements.Package.RequireBuild || serviceConfig.Build != nil) && !hasBuildOutput {
 			buildTask := sm.Build(ctx, serviceConfig, restoreResult)
 			syncProgress(task, buildTask.Progress())
 
@@ -354,6 +398,8 @@ func (sm *serviceManager) Package(
 		}
 
 		var packageResult *ServicePackageResult
+		// The number of entries expected in a zip package of the framework output, -1 when unknown
+		expectedZipEntries := -1
 
 		err = serviceConfig.Invoke(ctx, ServiceEventPackage, eventArgs, func() error {
 			frameworkPackageTask := frameworkService.Package(ctx, serviceConfig, buildOutput)
@@ -364,6 +410,14 @@ func (sm *serviceManager) Package(
 				return err
 			}
 
+			if options.VerifyZip {
+				if info, err := os.Stat(frameworkPackageResult.PackagePath); err == nil && info.IsDir() {
+					if expectedZipEntries, err = rzip.CountEntries(frameworkPackageResult.PackagePath); err != nil {
+						return fmt.Errorf("counting package entries: %w", err)
+					}
+				}
+			}
+
 			serviceTargetPackageTask := serviceTarget.Package(ctx, serviceConfig, frameworkPackageResult)
 			syncProgress(task, serviceTargetPackageTask.Progress())
 
@@ -422,6 +476,14 @@ func (sm *serviceManager) Package(
 			packageResult.PackagePath = destFilePath
 		}
 
+		if hasPackageFile && options.VerifyZip && strings.EqualFold(filepath.Ext(packageResult.PackagePath), ".zip") {
+			task.SetProgress(NewServicePhaseProgress(ProgressPhasePackaging, "Verifying zip package"))
+			if err := rzip.Verify(packageResult.PackagePath, expectedZipEntries); err != nil {
+				task.SetError(fmt.Errorf("verifying package of service '%s': %w", serviceConfig.Name, err))
+				return
+			}
+		}
+
 		task.SetResult(packageResult)
 	})
 }
@@ -454,58 +516,61 @@ func (sm *serviceManager) Deploy(
 			return
 		}
 
-		var targetResource *environment.TargetResource
+		if err := ensureTools(ctx, "deploying", serviceConfig, serviceTarget.RequiredExternalTools(ctx)...); err != nil {
+			task.SetError(err)
+			return
+		}
 
-		if serviceConfig.Host == DotNetContainerAppTarget {
-			containerEnvName := sm.env.GetServiceProperty(serviceConfig.Name, "CONTAINER_ENVIRONMENT_NAME")
-			if containerEnvName == "" {
-				containerEnvName = sm.env.Getenv("AZURE_CONTAINER_APPS_ENVIRONMENT_ID")
-				if containerEnvName == "" {
-					task.SetError(fmt.Errorf(
-						"could not determine container app environment for service %s, "+
-							"have you set AZURE_CONTAINER_ENVIRONMENT_NAME or "+
-							"SERVICE_%s_CONTAINER_ENVIRONMENT_NAME as an output of your "+
-							"infrastructure?", serviceConfig.Name, strings.ToUpper(serviceConfig.Name)))
+		targetResource, err := sm.GetTargetResource(ctx, serviceConfig)
+		if err != nil {
+			// Custom hosts may deploy outside of Azure, in which case the plugin receives no target resource
+			if _, isPlugin := sm.targetRegistry.Provider(serviceConfig.Host); !isPlugin {
+				task.SetError(fmt.Errorf("getting target resource: %w", err))
+				return
+			}
 
-					return
-				}
+			log.Printf("no target resource found for service '%s': %v", serviceConfig.Name, err)
+			targetResource = nil
+		}
 
-				parts := strings.Split(containerEnvName, "/")
-				containerEnvName = parts[len(parts)-1]
-			}
+		if len(serviceConfig.OutputEnv) > 0 && !slices.Contains(outputEnvHosts, serviceConfig.Host) {
+			sm.console.Message(ctx, output.WithWarningFormat(
+				"WARNING: outputEnv of service '%s' is ignored, since the %s host doesn't support it.",
+				serviceConfig.Name,
+				serviceConfig.Host,
+			))
+		}
 
-			resourceGroupName, err := sm.resourceManager.GetResourceGroupName(
-				ctx, sm.env.GetSubscriptionId(), serviceConfig.Project)
-			if err != nil {
-				task.SetError(fmt.Errorf("getting resource group name: %w", err))
-				return
-			}
+		timeout, err := serviceConfig.DeployTimeout()
+		if err != nil {
+			task.SetError(err)
+			return
+		}
 
-			targetResource = environment.NewTargetResource(
-				sm.env.GetSubscriptionId(),
-				resourceGroupName,
-				containerEnvName,
-				string(infra.AzureResourceTypeContainerAppEnvironment),
-			)
-		} else {
-			targetResource, err = sm.resourceManager.GetTargetResource(ctx, sm.env.GetSubscriptionId(), serviceConfig)
-			if err != nil {
-				task.SetError(fmt.Errorf("getting target resource: %w", err))
-				return
-			}
+		// The timeout of the service cancels the deployment, including its hooks and the tools and Azure operations
+		// started by the service target.
+		deployCtx := ctx
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			deployCtx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
 		}
 
 		deployResult, err := runCommand(
-			ctx,
+			deployCtx,
 			task,
 			ServiceEventDeploy,
 			serviceConfig,
 			func() *async.TaskWithProgress[*ServiceDeployResult, ServiceProgress] {
-				return serviceTarget.Deploy(ctx, serviceConfig, packageResult, targetResource)
+				return serviceTarget.Deploy(deployCtx, serviceConfig, packageResult, targetResource)
 			},
 		)
 
 		if err != nil {
+			if timeout > 0 && ctx.Err() == nil && errors.Is(deployCtx.Err(), context.DeadlineExceeded) {
+				err = &ServiceTimeoutError{ServiceName: serviceConfig.Name, Timeout: timeout, Err: err}
+			}
+
 			task.SetError(fmt.Errorf("failed deploying service '%s': %w", serviceConfig.Name, err))
 			return
 		}
@@ -522,6 +587,45 @@ func (sm *serviceManager) Deploy(
 	})
 }
 
+// GetTargetResource resolves the Azure resource a service is deployed to. Services of .NET Aspire projects are deployed
+// to their container apps environment.
+func (sm *serviceManager) GetTargetResource(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) (*environment.TargetResource, error) {
+	if serviceConfig.Host != DotNetContainerAppTarget {
+		return sm.resourceManager.GetTargetResource(ctx, sm.env.GetSubscriptionId(), serviceConfig)
+	}
+
+	containerEnvName := sm.env.GetServiceProperty(serviceConfig.Name, "CONTAINER_ENVIRONMENT_NAME")
+	if containerEnvName == "" {
+		containerEnvName = sm.env.Getenv("AZURE_CONTAINER_APPS_ENVIRONMENT_ID")
+		if containerEnvName == "" {
+			return nil, fmt.Errorf(
+				"could not determine container app environment for service %s, "+
+					"have you set AZURE_CONTAINER_ENVIRONMENT_NAME or "+
+					"SERVICE_%s_CONTAINER_ENVIRONMENT_NAME as an output of your "+
+					"infrastructure?", serviceConfig.Name, strings.ToUpper(serviceConfig.Name))
+		}
+
+		parts := strings.Split(containerEnvName, "/")
+		containerEnvName = parts[len(parts)-1]
+	}
+
+	resourceGroupName, err := sm.resourceManager.GetResourceGroupName(
+		ctx, sm.env.GetSubscriptionId(), serviceConfig.Project)
+	if err != nil {
+		return nil, fmt.Errorf("getting resource group name: %w", err)
+	}
+
+	return environment.NewTargetResource(
+		sm.env.GetSubscriptionId(),
+		resourceGroupName,
+		containerEnvName,
+		string(infra.AzureResourceTypeContainerAppEnvironment),
+	), nil
+}
+
 // GetServiceTarget constructs a ServiceTarget from the underlying service configuration
 func (sm *serviceManager) GetServiceTarget(ctx context.Context, serviceConfig *ServiceConfig) (ServiceTarget, error) {
 	var target ServiceTarget
@@ -538,7 +642,26 @@ func (sm *serviceManager) GetServiceTarget(ctx context.Context, serviceConfig *S
 		}
 	}
 
+	if provider, has := sm.targetRegistry.Provider(serviceConfig.Host); has {
+		target, err := provider.ServiceTarget(serviceConfig.Host, sm.env)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"creating service target of plugin '%s' for service '%s': %w", provider.Name(), serviceConfig.Name, err)
+		}
+
+		return target, nil
+	}
+
 	if err := sm.serviceLocator.ResolveNamed(host, &target); err != nil {
+		if !isBuiltInHost(serviceConfig.Host) && serviceConfig.Host != NonSpecifiedTarget {
+			return nil, fmt.Errorf(
+				"unsupported host '%s' for service '%s'. Install a plugin providing the host in '%s'",
+				serviceConfig.Host,
+				serviceConfig.Name,
+				filepath.Join("~", ".azd", PluginsDirectoryName),
+			)
+		}
+
 		return nil, fmt.Errorf(
 			"failed to resolve service host '%s' for service '%s', %w",
 			serviceConfig.Host,
@@ -559,7 +682,15 @@ func (sm *serviceManager) GetFrameworkService(ctx context.Context, serviceConfig
 		serviceConfig.Language = ServiceLanguageDocker
 	}
 
-	if err := sm.serviceLocator.ResolveNamed(string(serviceConfig.Language), &frameworkService); err != nil {
+	frameworkName := string(serviceConfig.Language)
+
+	// Java projects built with Gradle follow the lifecycle of a gradle project rather than a maven project
+	if serviceConfig.Language == ServiceLanguageJava &&
+		serviceConfig.Java.buildTool(serviceConfig.Path()) == JavaBuildToolGradle {
+		frameworkName = GradleFrameworkName
+	}
+
+	if err := sm.serviceLocator.ResolveNamed(frameworkName, &frameworkService); err != nil {
 		return nil, fmt.Errorf(
 			"failed to resolve language '%s' for service '%s', %w",
 			serviceConfig.Language,
@@ -612,6 +743,9 @@ func OverriddenEndpoints(ctx context.Context, serviceConfig *ServiceConfig, env
 // Attempts to retrieve the result of a previous operation from the cache
 func (sm *serviceManager) getOperationResult(serviceConfig *ServiceConfig, operationName string) (any, bool) {
 	key := fmt.Sprintf("%s:%s:%s", sm.env.Name(), serviceConfig.Name, operationName)
+
+	operationCacheMu.RLock()
+	defer operationCacheMu.RUnlock()
 	value, ok := sm.operationCache[key]
 
 	return value, ok
@@ -620,6 +754,9 @@ func (sm *serviceManager) getOperationResult(serviceConfig *ServiceConfig, opera
 // Sets the result of an operation in the cache
 func (sm *serviceManager) setOperationResult(serviceConfig *ServiceConfig, operationName string, result any) {
 	key := fmt.Sprintf("%s:%s:%s", sm.env.Name(), serviceConfig.Name, operationName)
+
+	operationCacheMu.Lock()
+	defer operationCacheMu.Unlock()
 	sm.operationCache[key] = result
 }
 
@@ -655,9 +792,15 @@ func runCommand[T comparable, P comparable](
 
 	err := serviceConfig.Invoke(ctx, eventName, eventArgs, func() error {
 		serviceTask := taskFunc()
-		go syncProgress(task, serviceTask.Progress())
+		done := make(chan struct{})
+		go func() {
+			syncProgress(task, serviceTask.Progress())
+			close(done)
+		}()
 
 		taskResult, err := serviceTask.Await()
+		// wait for progress updates to be forwarded before the task completes
+		<-done
 		if err != nil {
 			return err
 		}
@@ -673,6 +816,18 @@ func runCommand[T comparable, P comparable](
 	return result, nil
 }
 
+// ensureTools checks that the external tools required by an operation on the service, like "building", are installed
+// before the operation starts.
+func ensureTools(
+	ctx context.Context,
+	operation string,
+	serviceConfig *ServiceConfig,
+	requiredTools ...tools.ExternalTool,
+) error {
+	return tools.EnsureInstalledFor(
+		ctx, fmt.Sprintf("%s service '%s'", operation, serviceConfig.Name), tools.Unique(requiredTools)...)
+}
+
 func syncProgress[T comparable, P comparable](task *async.TaskContextWithProgress[T, P], progressChannel <-chan P) {
 	for progress := range progressChannel {
 		task.SetProgress(progress)
@@ -706,3 +861,19 @@ func moveFile(sourcePath string, destinationPath string) error {
 
 	return nil
 }
+
+// ServiceTimeoutError is returned when the deployment of a service is cancelled because it exceeded the timeout of
+// the service.
+type ServiceTimeoutError struct {
+	ServiceName string
+	Timeout     time.Duration
+	Err         error
+}
+
+func (e *ServiceTimeoutError) Error() string {
+	return fmt.Sprintf("timed out after %s: %v", e.Timeout, e.Err)
+}
+
+func (e *ServiceTimeoutError) Unwrap() error {
+	return e.Err
+}
@@ -3,8 +3,13 @@ package project
 import (
 	"context"
 	"errors"
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/azure/azure-dev/cli/azd/pkg/alpha"
@@ -16,6 +21,8 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/ext"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/rzip"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/azure/azure-dev/cli/azd/test/mocks/mockarmresources"
@@ -34,6 +41,8 @@ const (
 	frameworkPackageCalled     contextKey = "frameworkPackageCalled"
 	serviceTargetPackageCalled contextKey = "serviceTargetPackageCalled"
 	serviceTargetDeployCalled  contextKey = "serviceTargetDeployCalled"
+	serviceTargetDeployBlocks  contextKey = "serviceTargetDeployBlocks"
+	serviceTargetDeployPhases  contextKey = "serviceTargetDeployPhases"
 )
 
 func createServiceManager(
@@ -52,7 +61,16 @@ func createServiceManager(
 			},
 		}))
 
-	return NewServiceManager(env, resourceManager, mockContext.Container, operationCache, alphaManager)
+	return NewServiceManager(
+		env,
+		resourceManager,
+		mockContext.Container,
+		operationCache,
+		alphaManager,
+		NewServiceTargetRegistry(),
+		mockContext.CommandRunner,
+		mockContext.Console,
+	)
 }
 
 func Test_ServiceManager_GetRequiredTools(t *testing.T) {
@@ -183,6 +201,140 @@ func Test_ServiceManager_Package(t *testing.T) {
 	require.True(t, raisedPostPackageEvent)
 }
 
+func Test_ServiceManager_Package_VerifyZip(t *testing.T) {
+	createZip := func(t *testing.T) string {
+		source := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(source, "app.py"), []byte("print('hello')"), osutil.PermissionFile))
+
+		zipPath := filepath.Join(t.TempDir(), "api.zip")
+		zipFile, err := os.Create(zipPath)
+		require.NoError(t, err)
+		require.NoError(t, rzip.CreateFromDirectory(source, zipFile))
+		require.NoError(t, zipFile.Close())
+
+		return zipPath
+	}
+
+	packageZip := func(t *testing.T, zipPath string, verifyZip bool) error {
+		mockContext := mocks.NewMockContext(context.Background())
+		setupMocksForServiceManager(mockContext)
+		mockContext.Container.MustRegisterNamedSingleton(
+			string(ServiceTargetFake),
+			func(commandRunner exec.CommandRunner) ServiceTarget {
+				return &zipServiceTarget{
+					fakeServiceTarget: &fakeServiceTarget{commandRunner: commandRunner},
+					zipPath:           zipPath,
+				}
+			},
+		)
+
+		sm := createServiceManager(mockContext, environment.New("test"), ServiceOperationCache{})
+		serviceConfig := createTestServiceConfig("./src/api", ServiceTargetFake, ServiceLanguageFake)
+
+		packageTask := sm.Package(*mockContext.Context, serviceConfig, nil, &PackageOptions{VerifyZip: verifyZip})
+		logProgress(packageTask)
+
+		_, err := packageTask.Await()
+		return err
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		require.NoError(t, packageZip(t, createZip(t), true))
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		zipPath := createZip(t)
+		info, err := os.Stat(zipPath)
+		require.NoError(t, err)
+		require.NoError(t, os.Truncate(zipPath, info.Size()-10))
+
+		err = packageZip(t, zipPath, true)
+		require.ErrorContains(t, err, "verifying package of service 'api': opening zip archive "+zipPath)
+
+		// Without verification, the corrupt archive is only found when it's deployed
+		require.NoError(t, packageZip(t, zipPath, false))
+	})
+}
+
+func Test_ServiceManager_Package_CustomBuild(t *testing.T) {
+	setup := func(t *testing.T, mode BuildMode, buildErr error) (
+		*mocks.MockContext, *ServiceConfig, ServiceManager, *[]string, *exec.RunArgs) {
+		mockContext := mocks.NewMockContext(context.Background())
+		setupMocksForServiceManager(mockContext)
+		env := environment.NewWithValues("test", map[string]string{"APP_ENV": "prod"})
+		sm := createServiceManager(mockContext, env, ServiceOperationCache{})
+		serviceConfig := createTestServiceConfig("./src/api", ServiceTargetFake, ServiceLanguageFake)
+		serviceConfig.OutputPath = "dist"
+		serviceConfig.Build = &BuildOptions{
+			Run:  osutil.NewExpandableString("make dist ENV=${APP_ENV}"),
+			Cwd:  "build",
+			Mode: mode,
+		}
+
+		commands := []string{}
+		var buildArgs exec.RunArgs
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "fake-framework build")
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			commands = append(commands, "fake-framework build")
+			return exec.NewRunResult(0, "", ""), nil
+		})
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "make dist")
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			commands = append(commands, "make dist")
+			buildArgs = args
+			return exec.NewRunResult(0, "", ""), buildErr
+		})
+
+		return mockContext, serviceConfig, sm, &commands, &buildArgs
+	}
+
+	t.Run("Replace", func(t *testing.T) {
+		mockContext, serviceConfig, sm, commands, buildArgs := setup(t, "", nil)
+
+		fakeFrameworkPackageCalled := convert.RefOf(false)
+		ctx := context.WithValue(*mockContext.Context, frameworkPackageCalled, fakeFrameworkPackageCalled)
+		packageTask := sm.Package(ctx, serviceConfig, nil, nil)
+		logProgress(packageTask)
+
+		result, err := packageTask.Await()
+		require.NoError(t, err)
+		require.True(t, *fakeFrameworkPackageCalled)
+		require.Equal(t, []string{"make dist"}, *commands)
+		require.Equal(t, []string{"make dist ENV=prod"}, buildArgs.Args)
+		require.True(t, buildArgs.UseShell)
+		require.Equal(t, filepath.Join(serviceConfig.Path(), "build"), buildArgs.Cwd)
+		require.Equal(t, filepath.Join(serviceConfig.Path(), "dist"), result.Build.BuildOutputPath)
+	})
+
+	t.Run("After", func(t *testing.T) {
+		mockContext, serviceConfig, sm, commands, _ := setup(t, BuildModeAfter, nil)
+
+		packageTask := sm.Package(*mockContext.Context, serviceConfig, nil, nil)
+		logProgress(packageTask)
+
+		_, err := packageTask.Await()
+		require.NoError(t, err)
+		require.Equal(t, []string{"fake-framework build", "make dist"}, *commands)
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		buildErr := errors.New("exit code: 2")
+		mockContext, serviceConfig, sm, _, _ := setup(t, BuildModeBefore, buildErr)
+
+		fakeFrameworkPackageCalled := convert.RefOf(false)
+		ctx := context.WithValue(*mockContext.Context, frameworkPackageCalled, fakeFrameworkPackageCalled)
+		packageTask := sm.Package(ctx, serviceConfig, nil, nil)
+		logProgress(packageTask)
+
+		_, err := packageTask.Await()
+		require.ErrorIs(t, err, buildErr)
+		require.ErrorContains(t, err, "running build command 'make dist ENV=prod'")
+		require.False(t, *fakeFrameworkPackageCalled)
+	})
+}
+
 func Test_ServiceManager_Deploy(t *testing.T) {
 	mockContext := mocks.NewMockContext(context.Background())
 	setupMocksForServiceManager(mockContext)
@@ -219,6 +371,110 @@ func Test_ServiceManager_Deploy(t *testing.T) {
 	require.True(t, raisedPostDeployEvent)
 }
 
+func Test_ServiceManager_Deploy_Timeout(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	setupMocksForServiceManager(mockContext)
+	env := environment.NewWithValues("test", map[string]string{
+		environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+	})
+
+	t.Run("ServiceExceedsTimeout", func(t *testing.T) {
+		sm := createServiceManager(mockContext, env, ServiceOperationCache{})
+		serviceConfig := createTestServiceConfig("./src/api", ServiceTargetFake, ServiceLanguageFake)
+		serviceConfig.Timeout = "20ms"
+
+		ctx := context.WithValue(*mockContext.Context, serviceTargetDeployBlocks, true)
+		deployTask := sm.Deploy(ctx, serviceConfig, nil)
+		logProgress(deployTask)
+
+		_, err := deployTask.Await()
+		var timeoutErr *ServiceTimeoutError
+		require.ErrorAs(t, err, &timeoutErr)
+		require.Equal(t, "api", timeoutErr.ServiceName)
+		require.Equal(t, 20*time.Millisecond, timeoutErr.Timeout)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("ParentCancelledIsNotServiceTimeout", func(t *testing.T) {
+		sm := createServiceManager(mockContext, env, ServiceOperationCache{})
+		serviceConfig := createTestServiceConfig("./src/api", ServiceTargetFake, ServiceLanguageFake)
+		serviceConfig.Timeout = "1h"
+
+		ctx, cancel := context.WithTimeout(*mockContext.Context, 20*time.Millisecond)
+		defer cancel()
+		ctx = context.WithValue(ctx, serviceTargetDeployBlocks, true)
+		deployTask := sm.Deploy(ctx, serviceConfig, nil)
+		logProgress(deployTask)
+
+		_, err := deployTask.Await()
+		var timeoutErr *ServiceTimeoutError
+		require.False(t, errors.As(err, &timeoutErr))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("WithinTimeout", func(t *testing.T) {
+		sm := createServiceManager(mockContext, env, ServiceOperationCache{})
+		serviceConfig := createTestServiceConfig("./src/api", ServiceTargetFake, ServiceLanguageFake)
+		serviceConfig.Timeout = "1m"
+
+		deployTask := sm.Deploy(*mockContext.Context, serviceConfig, nil)
+		logProgress(deployTask)
+
+		result, err := deployTask.Await()
+		require.NoError(t, err)
+		require.NotNil(t, result)
+	})
+}
+
+func Test_ServiceConfig_DeployTimeout(t *testing.T) {
+	serviceConfig := &ServiceConfig{}
+	timeout, err := serviceConfig.DeployTimeout()
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(0), timeout)
+
+	serviceConfig.Timeout = "90s"
+	timeout, err = serviceConfig.DeployTimeout()
+	require.NoError(t, err)
+	require.Equal(t, 90*time.Second, timeout)
+
+	for _, invalid := range []string{"10", "-1m", "soon"} {
+		serviceConfig.Timeout = invalid
+		_, err = serviceConfig.DeployTimeout()
+		require.Error(t, err, invalid)
+	}
+}
+
+func Test_ServiceManager_Build_MissingTool(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	setupMocksForServiceManager(mockContext)
+	mockContext.Container.MustRegisterNamedSingleton(
+		string(ServiceLanguageDocker),
+		func(commandRunner exec.CommandRunner) FrameworkService {
+			return &fakeContainerFramework{fakeFramework: &fakeFramework{commandRunner: commandRunner}}
+		},
+	)
+
+	env := environment.New("test")
+	sm := createServiceManager(mockContext, env, ServiceOperationCache{})
+	serviceConfig := createTestServiceConfig("./src/api", ServiceTargetFake, ServiceLanguageDocker)
+
+	buildCalled := convert.RefOf(false)
+	ctx := context.WithValue(*mockContext.Context, frameworkBuildCalled, buildCalled)
+
+	buildResult, err := sm.Build(ctx, serviceConfig, nil).Await()
+	require.Nil(t, buildResult)
+
+	// The missing docker is reported before the container build starts
+	var missingToolsErr *tools.MissingToolsError
+	require.ErrorAs(t, err, &missingToolsErr)
+	require.ErrorIs(t, err, osexec.ErrNotFound)
+	require.Equal(t, "building service 'api'", missingToolsErr.Operation)
+	require.Len(t, missingToolsErr.Tools, 1)
+	require.Equal(t, "Docker", missingToolsErr.Tools[0].Name)
+	require.Equal(t, "https://aka.ms/azure-dev/docker-install", missingToolsErr.Tools[0].InstallUrl)
+	require.False(t, *buildCalled)
+}
+
 func Test_ServiceManager_GetFrameworkService(t *testing.T) {
 	t.Run("Standard", func(t *testing.T) {
 		mockContext := mocks.NewMockContext(context.Background())
@@ -249,6 +505,28 @@ func Test_ServiceManager_GetFrameworkService(t *testing.T) {
 		require.IsType(t, new(fakeFramework), framework)
 	})
 
+	t.Run("Java project built with gradle", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.Container.MustRegisterNamedTransient(string(ServiceLanguageJava), newFakeFramework)
+		mockContext.Container.MustRegisterNamedTransient(GradleFrameworkName, func() FrameworkService {
+			return &noOpProject{}
+		})
+
+		setupMocksForServiceManager(mockContext)
+		env := environment.New("test")
+		sm := createServiceManager(mockContext, env, ServiceOperationCache{})
+		serviceConfig := createTestServiceConfig("./src/api", ServiceTargetFake, ServiceLanguageJava)
+
+		framework, err := sm.GetFrameworkService(*mockContext.Context, serviceConfig)
+		require.NoError(t, err)
+		require.IsType(t, new(fakeFramework), framework)
+
+		serviceConfig.Java.BuildTool = JavaBuildToolGradle
+		framework, err = sm.GetFrameworkService(*mockContext.Context, serviceConfig)
+		require.NoError(t, err)
+		require.IsType(t, new(noOpProject), framework)
+	})
+
 	t.Run("No project path or docker tag", func(t *testing.T) {
 		mockContext := mocks.NewMockContext(context.Background())
 		mockContext.Container.MustRegisterNamedTransient("docker", newFakeFramework)
@@ -609,6 +887,25 @@ func (st *fakeServiceTarget) Package(
 	})
 }
 
+// zipServiceTarget is a fake service target packaging the service as the zip archive of zipPath
+type zipServiceTarget struct {
+	*fakeServiceTarget
+	zipPath string
+}
+
+func (st *zipServiceTarget) Package(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	packageOutput *ServicePackageResult,
+) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
+	return async.RunTaskWithProgress(func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
+		task.SetResult(&ServicePackageResult{
+			Build:       packageOutput.Build,
+			PackagePath: st.zipPath,
+		})
+	})
+}
+
 func (st *fakeServiceTarget) Deploy(
 	ctx context.Context,
 	serviceConfig *ServiceConfig,
@@ -621,6 +918,19 @@ func (st *fakeServiceTarget) Deploy(
 	}
 
 	return async.RunTaskWithProgress(func(task *async.TaskContextWithProgress[*ServiceDeployResult, ServiceProgress]) {
+		// Simulates a deployment that never completes unless cancelled
+		if blocks, ok := ctx.Value(serviceTargetDeployBlocks).(bool); ok && blocks {
+			<-ctx.Done()
+			task.SetError(ctx.Err())
+			return
+		}
+
+		if phases, ok := ctx.Value(serviceTargetDeployPhases).([]ProgressPhase); ok {
+			for _, phase := range phases {
+				task.SetProgress(NewServicePhaseProgress(phase, fmt.Sprintf("Fake %s", phase)))
+			}
+		}
+
 		runArgs := exec.NewRunArgs("fake-service-target", "deploy")
 		result, err := st.commandRunner.Run(ctx, runArgs)
 		if err != nil {
@@ -643,6 +953,33 @@ func (st *fakeServiceTarget) Endpoints(
 	return []string{"https://test.azurewebsites.net"}, nil
 }
 
+// fakeContainerFramework is a framework building container images with a docker which isn't installed.
+type fakeContainerFramework struct {
+	*fakeFramework
+}
+
+func (f *fakeContainerFramework) RequiredExternalTools(ctx context.Context) []tools.ExternalTool {
+	return []tools.ExternalTool{&missingTool{name: "Docker", installUrl: "https://aka.ms/azure-dev/docker-install"}}
+}
+
+// missingTool is an external tool which isn't installed.
+type missingTool struct {
+	name       string
+	installUrl string
+}
+
+func (t *missingTool) CheckInstalled(ctx context.Context) error {
+	return osexec.ErrNotFound
+}
+
+func (t *missingTool) InstallUrl() string {
+	return t.installUrl
+}
+
+func (t *missingTool) Name() string {
+	return t.name
+}
+
 type fakeTool struct {
 }
 
@@ -22,6 +22,7 @@ type ServiceLifecycleEventArgs struct {
 // during a service operation such as restore, build, package & deploy
 type ServiceProgress struct {
 	Message   string
+	Phase     ProgressPhase
 	Timestamp time.Time
 }
 
@@ -34,6 +35,16 @@ func NewServiceProgress(message string) ServiceProgress {
 	}
 }
 
+// NewServicePhaseProgress is a helper method to create a new
+// progress message of a known phase with a current timestamp
+func NewServicePhaseProgress(phase ProgressPhase, message string) ServiceProgress {
+	return ServiceProgress{
+		Message:   message,
+		Phase:     phase,
+		Timestamp: time.Now(),
+	}
+}
+
 // ServiceRestoreResult is the result of a successful Restore operation
 type ServiceRestoreResult struct {
 	Details interface{} `json:"details"`
@@ -62,6 +73,8 @@ func (sbr *ServiceBuildResult) MarshalJSON() ([]byte, error) {
 
 type PackageOptions struct {
 	OutputPath string
+	// When set, zip packages are verified to be valid archives with the expected entries before they're deployed
+	VerifyZip bool
 }
 
 // ServicePackageResult is the result of a successful Package operation
@@ -69,6 +82,8 @@ type ServicePackageResult struct {
 	Build       *ServiceBuildResult `json:"build"`
 	PackagePath string              `json:"packagePath"`
 	Details     interface{}         `json:"details"`
+	// The URI of the package uploaded to the artifact store by 'azd package --upload'.
+	ArtifactUri string `json:"artifactUri,omitempty"`
 }
 
 // Supports rendering messages for UX items
@@ -78,7 +93,12 @@ func (spr *ServicePackageResult) ToString(currentIndentation string) string {
 		return uxItem.ToString(currentIndentation)
 	}
 
-	return fmt.Sprintf("%s- Package Output: %s", currentIndentation, output.WithLinkFormat(spr.PackagePath))
+	message := fmt.Sprintf("%s- Package Output: %s", currentIndentation, output.WithLinkFormat(spr.PackagePath))
+	if spr.ArtifactUri != "" {
+		message += fmt.Sprintf("\n%s- Uploaded To: %s", currentIndentation, output.WithLinkFormat(spr.ArtifactUri))
+	}
+
+	return message
 }
 
 func (spr *ServicePackageResult) MarshalJSON() ([]byte, error) {
@@ -93,6 +113,8 @@ type ServiceDeployResult struct {
 	Kind             ServiceTargetKind `json:"kind"`
 	Endpoints        []string          `json:"endpoints"`
 	Details          interface{}       `json:"details"`
+	// The time taken to package and deploy the service, set by 'azd deploy'.
+	Duration time.Duration `json:"duration,omitempty"`
 }
 
 // Supports rendering messages for UX items
@@ -7,12 +7,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/async"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
 )
 
 type ServiceTargetKind string
@@ -55,6 +58,19 @@ func parseServiceHost(kind ServiceTargetKind) (ServiceTargetKind, error) {
 		return kind, nil
 	}
 
+	// Other hosts may be provided by the installed service target plugins
+	if kind != DotNetContainerAppTarget && installedPluginHosts()[kind] {
+		return kind, nil
+	}
+
+	if kind != DotNetContainerAppTarget && pluginHostRegex.MatchString(string(kind)) {
+		return ServiceTargetKind(""), fmt.Errorf(
+			"unsupported host '%s'. Install a plugin providing the host in '%s'",
+			kind,
+			filepath.Join("~", ".azd", PluginsDirectoryName),
+		)
+	}
+
 	return ServiceTargetKind(""), fmt.Errorf("unsupported host '%s'", kind)
 }
 
@@ -129,6 +145,40 @@ func resourceTypeMismatchError(
 	)
 }
 
+// ValidatePackagePath checks that a package produced ahead of time, like one passed to 'azd deploy --from-package', can
+// be deployed to the service target kind: a container image reference for hosts running containers, a zip file for App
+// Service and Azure Functions, and an existing file or directory for the other built-in hosts.
+func (st ServiceTargetKind) ValidatePackagePath(packagePath string) error {
+	info, statErr := os.Stat(packagePath)
+
+	switch st {
+	case ContainerAppTarget, AksTarget, DotNetContainerAppTarget:
+		if statErr == nil {
+			return fmt.Errorf(
+				"host '%s' deploys container images, '%s' must be a container image reference rather than a file",
+				st, packagePath)
+		}
+
+		if _, err := docker.ParseContainerImage(packagePath); err != nil {
+			return fmt.Errorf("'%s' is not a valid container image reference: %w", packagePath, err)
+		}
+	case AppServiceTarget, AzureFunctionTarget:
+		if statErr != nil {
+			return fmt.Errorf("reading package '%s': %w", packagePath, statErr)
+		}
+
+		if info.IsDir() || !strings.EqualFold(filepath.Ext(packagePath), ".zip") {
+			return fmt.Errorf("host '%s' deploys zip packages, '%s' must be a zip file", st, packagePath)
+		}
+	case StaticWebAppTarget, SpringAppTarget:
+		if statErr != nil {
+			return fmt.Errorf("reading package '%s': %w", packagePath, statErr)
+		}
+	}
+
+	return nil
+}
+
 // SupportsDelayedProvisioning returns true if the service target kind
 // supports delayed provisioning resources at deployment time, otherwise false.
 //
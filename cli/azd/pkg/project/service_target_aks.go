@@ -218,6 +218,12 @@ func (t *aksTarget) Deploy(
 				}
 			}
 
+			task.SetProgress(NewServicePhaseProgress(ProgressPhaseCreatingDeployment, "Configuring image pull secret"))
+			if err := t.ensureImagePullSecret(ctx, serviceConfig); err != nil {
+				task.SetError(err)
+				return
+			}
+
 			// Sync environment
 			t.kubectl.SetEnv(t.env.Dotenv())
 
@@ -264,7 +270,7 @@ func (t *aksTarget) Deploy(
 				return
 			}
 
-			task.SetProgress(NewServiceProgress("Fetching endpoints for AKS service"))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhaseFetchingEndpoints, "Fetching endpoints for AKS service"))
 			endpoints, err := t.Endpoints(ctx, serviceConfig, targetResource)
 			if err != nil {
 				task.SetError(err)
@@ -317,7 +323,7 @@ func (t *aksTarget) deployManifests(
 		return false, nil, err
 	}
 
-	task.SetProgress(NewServiceProgress("Applying k8s manifests"))
+	task.SetProgress(NewServicePhaseProgress(ProgressPhaseCreatingDeployment, "Applying k8s manifests"))
 	err := t.kubectl.Apply(
 		ctx,
 		deploymentPath,
@@ -334,7 +340,7 @@ func (t *aksTarget) deployManifests(
 
 	// It is not a requirement for a AZD deploy to contain a deployment object
 	// If we don't find any deployment within the namespace we will continue
-	task.SetProgress(NewServiceProgress("Verifying deployment"))
+	task.SetProgress(NewServicePhaseProgress(ProgressPhaseVerifying, "Verifying deployment"))
 	deployment, err := t.waitForDeployment(ctx, deploymentName)
 	if err != nil && !errors.Is(err, kubectl.ErrResourceNotFound) {
 		// We continue to return a true value here since at this point we have successfully applied the manifests
@@ -362,7 +368,7 @@ func (t *aksTarget) deployKustomize(
 		)
 	}
 
-	task.SetProgress(NewServiceProgress("Applying k8s manifests with Kustomize"))
+	task.SetProgress(NewServicePhaseProgress(ProgressPhaseCreatingDeployment, "Applying k8s manifests with Kustomize"))
 	overlayPath, err := serviceConfig.K8s.Kustomize.Directory.Envsubst(t.env.Getenv)
 	if err != nil {
 		return false, fmt.Errorf("failed to envsubst kustomize directory: %w", err)
@@ -438,7 +444,8 @@ func (t *aksTarget) deployHelmCharts(
 	}
 
 	for _, repo := range serviceConfig.K8s.Helm.Repositories {
-		task.SetProgress(NewServiceProgress(fmt.Sprintf("Configuring helm repo: %s", repo.Name)))
+		task.SetProgress(NewServicePhaseProgress(
+			ProgressPhaseCreatingDeployment, fmt.Sprintf("Configuring helm repo: %s", repo.Name)))
 		if err := t.helmCli.AddRepo(ctx, repo); err != nil {
 			return false, err
 		}
@@ -457,12 +464,14 @@ func (t *aksTarget) deployHelmCharts(
 			return false, err
 		}
 
-		task.SetProgress(NewServiceProgress(fmt.Sprintf("Installing helm release: %s", release.Name)))
+		task.SetProgress(NewServicePhaseProgress(
+			ProgressPhaseCreatingDeployment, fmt.Sprintf("Installing helm release: %s", release.Name)))
 		if err := t.helmCli.Upgrade(ctx, release); err != nil {
 			return false, err
 		}
 
-		task.SetProgress(NewServiceProgress(fmt.Sprintf("Checking helm release status: %s", release.Name)))
+		task.SetProgress(NewServicePhaseProgress(
+			ProgressPhaseVerifying, fmt.Sprintf("Checking helm release status: %s", release.Name)))
 		err := retry.Do(
 			ctx,
 			retry.WithMaxDuration(10*time.Minute, retry.NewConstant(5*time.Second)),
@@ -667,6 +676,41 @@ func (t *aksTarget) ensureNamespace(ctx context.Context, namespace string) error
 	return nil
 }
 
+// ensureImagePullSecret creates or updates a docker registry secret in the service namespace when the image is pushed
+// to a registry other than ACR with credentials. The secret name is written to the SERVICE_<NAME>_IMAGE_PULL_SECRET
+// environment variable so manifests can reference it from 'imagePullSecrets'.
+func (t *aksTarget) ensureImagePullSecret(ctx context.Context, serviceConfig *ServiceConfig) error {
+	credentials, err := t.containerHelper.PullCredentials(ctx, serviceConfig)
+	if err != nil || credentials == nil {
+		return err
+	}
+
+	secretName := fmt.Sprintf("%s-registry", strings.ToLower(serviceConfig.Name))
+	secret, err := kubectl.NewDockerConfigSecret(
+		secretName,
+		credentials.LoginServer,
+		credentials.Username,
+		credentials.Password,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = t.kubectl.ApplyWithStdIn(ctx, secret, &kubectl.KubeCliFlags{
+		Namespace: t.getK8sNamespace(serviceConfig),
+	})
+	if err != nil {
+		return fmt.Errorf("failed applying image pull secret: %w", err)
+	}
+
+	t.env.SetServiceProperty(serviceConfig.Name, "IMAGE_PULL_SECRET", secretName)
+	if err := t.envManager.Save(ctx, t.env); err != nil {
+		return fmt.Errorf("saving image pull secret to environment: %w", err)
+	}
+
+	return nil
+}
+
 // Finds a deployment using the specified deploymentNameFilter string
 // Waits until the deployment rollout is complete and all replicas are accessible
 // Additionally confirms rollout is complete by checking the rollout status
@@ -837,7 +837,9 @@ func createAksServiceTarget(
 		clock.NewMock(),
 		containerRegistryService,
 		dockerCli,
+		nil,
 		cloud.AzurePublic(),
+		nil,
 	)
 
 	if userConfig == nil {
@@ -12,25 +12,35 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/async"
 	"github.com/azure/azure-dev/cli/azd/pkg/azure"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 )
 
 type appServiceTarget struct {
-	env *environment.Environment
-	cli azcli.AzCli
+	env        *environment.Environment
+	cli        azcli.AzCli
+	httpClient httputil.HttpClient
+	slot       *slotDeployment
 }
 
 // NewAppServiceTarget creates a new instance of the AppServiceTarget
 func NewAppServiceTarget(
 	env *environment.Environment,
 	azCli azcli.AzCli,
+	httpClient httputil.HttpClient,
 ) ServiceTarget {
 
 	return &appServiceTarget{
-		env: env,
-		cli: azCli,
+		env:        env,
+		cli:        azCli,
+		httpClient: httpClient,
+		slot: &slotDeployment{
+			env:        env,
+			cli:        azCli,
+			httpClient: httpClient,
+		},
 	}
 }
 
@@ -52,8 +62,9 @@ func (st *appServiceTarget) Package(
 ) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
 	return async.RunTaskWithProgress(
 		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
-			task.SetProgress(NewServiceProgress("Compressing deployment artifacts"))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhasePackaging, "Compressing deployment artifacts"))
 			zipFilePath, err := createDeployableZip(
+				ctx,
 				serviceConfig.Project.Name,
 				serviceConfig.Name,
 				packageOutput.PackagePath,
@@ -94,26 +105,57 @@ func (st *appServiceTarget) Deploy(
 			defer os.Remove(packageOutput.PackagePath)
 			defer zipFile.Close()
 
-			task.SetProgress(NewServiceProgress("Uploading deployment package"))
-			res, err := st.cli.DeployAppServiceZip(
-				ctx,
-				targetResource.SubscriptionId(),
-				targetResource.ResourceGroupName(),
-				targetResource.ResourceName(),
-				zipFile,
-			)
+			slotName, err := st.slot.slotName(serviceConfig)
 			if err != nil {
-				task.SetError(fmt.Errorf("deploying service %s: %w", serviceConfig.Name, err))
+				task.SetError(err)
 				return
 			}
 
-			task.SetProgress(NewServiceProgress("Fetching endpoints for app service"))
-			endpoints, err := st.Endpoints(ctx, serviceConfig, targetResource)
+			// Deployment slots are updated once they exist
+			if slotName == "" {
+				if err := st.slot.updateAppSettings(ctx, task, serviceConfig, targetResource, ""); err != nil {
+					task.SetError(fmt.Errorf("deploying service %s: %w", serviceConfig.Name, err))
+					return
+				}
+			}
+
+			var res *string
+			var endpoints []string
+			if slotName != "" {
+				res, endpoints, err = st.slot.Deploy(ctx, task, serviceConfig, targetResource, slotName, zipFile)
+			} else {
+				task.SetProgress(NewServicePhaseProgress(ProgressPhaseUploading, "Uploading deployment package"))
+				res, err = st.cli.DeployAppServiceZip(
+					ctx,
+					targetResource.SubscriptionId(),
+					targetResource.ResourceGroupName(),
+					targetResource.ResourceName(),
+					zipFile,
+				)
+			}
 			if err != nil {
-				task.SetError(err)
+				task.SetError(fmt.Errorf("deploying service %s: %w", serviceConfig.Name, err))
 				return
 			}
 
+			// Endpoints are only returned for a slot that wasn't swapped into production
+			if endpoints == nil {
+				task.SetProgress(NewServicePhaseProgress(ProgressPhaseFetchingEndpoints, "Fetching endpoints for app service"))
+				endpoints, err = st.Endpoints(ctx, serviceConfig, targetResource)
+				if err != nil {
+					task.SetError(err)
+					return
+				}
+			}
+
+			if len(serviceConfig.WarmUp.Paths) > 0 && len(endpoints) > 0 {
+				task.SetProgress(NewServicePhaseProgress(ProgressPhaseWarmingUp, "Warming up app service"))
+				if err := warmUp(ctx, st.httpClient, endpoints[0], serviceConfig.WarmUp); err != nil {
+					task.SetError(err)
+					return
+				}
+			}
+
 			sdr := NewServiceDeployResult(
 				azure.WebsiteRID(
 					targetResource.SubscriptionId(),
@@ -5,23 +5,272 @@ package project
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"slices"
 	"strconv"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/async"
 	"github.com/azure/azure-dev/cli/azd/pkg/azure"
 	"github.com/azure/azure-dev/cli/azd/pkg/containerapps"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/ext"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/ioc"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 )
 
+// ContainerAppOptions are the options of the Azure Container Apps target.
+type ContainerAppOptions struct {
+	// The suffix of the revisions created at deploy, like '${GITHUB_SHA}', for reproducible revision names. Defaults to
+	// a suffix based on the time of the deployment.
+	RevisionSuffix osutil.ExpandableString `yaml:"revisionSuffix,omitempty"`
+	// The ingress of the container app, applied on deploy. The ingress provisioned by the infrastructure is kept when
+	// not set
+	Ingress *ContainerAppIngressOptions `yaml:"ingress,omitempty"`
+	// The scale of the container app, applied on deploy. The scale of the previous revision is kept when not set
+	Scale *ContainerAppScaleOptions `yaml:"scale,omitempty"`
+	// The Dapr settings of the container app, applied on deploy
+	Dapr *ContainerAppDaprOptions `yaml:"dapr,omitempty"`
+	// The containers running alongside the container of the service, like sidecars, deployed in the same revision
+	Containers []ContainerAppContainer `yaml:"containers,omitempty"`
+}
+
+// ContainerAppContainer is a container running alongside the container of the service, like a sidecar. Its image is
+// either an external image, or built by azd from the project of the container like the image of the service.
+type ContainerAppContainer struct {
+	// The name of the container, unique in the container app
+	Name string `yaml:"name"`
+	// The external image of the container, like 'docker.io/fluent/fluent-bit:3.0'. Can't be set with project
+	Image osutil.ExpandableString `yaml:"image,omitempty"`
+	// The path of the project the image of the container is built from, relative to the path of the service
+	Project string `yaml:"project,omitempty"`
+	// The docker options used to build and push the image of the container. The registry of the service is used when
+	// not set
+	Docker DockerProjectOptions `yaml:"docker,omitempty"`
+	// The environment variables of the container
+	Env map[string]osutil.ExpandableString `yaml:"env,omitempty"`
+	// The resources allocated to the container
+	Resources *ContainerAppResources `yaml:"resources,omitempty"`
+}
+
+// ContainerAppResources are the resources allocated to a container of a container app.
+type ContainerAppResources struct {
+	// The number of CPU cores, like 0.25
+	Cpu float64 `yaml:"cpu,omitempty"`
+	// The memory, like '0.5Gi'
+	Memory string `yaml:"memory,omitempty"`
+}
+
+// serviceConfig returns the configuration the image of the container is built and pushed with, as the container image
+// of a service named after the service and the container.
+func (c *ContainerAppContainer) serviceConfig(serviceConfig *ServiceConfig) *ServiceConfig {
+	docker := c.Docker
+	if docker.Registry.Empty() {
+		docker.Registry = serviceConfig.Docker.Registry
+	}
+
+	return &ServiceConfig{
+		EventDispatcher: ext.NewEventDispatcher[ServiceLifecycleEventArgs](),
+		Project:         serviceConfig.Project,
+		Name:            fmt.Sprintf("%s-%s", serviceConfig.Name, c.Name),
+		RelativePath:    filepath.Join(serviceConfig.RelativePath, c.Project),
+		Host:            ContainerAppTarget,
+		Language:        ServiceLanguageDocker,
+		Docker:          docker,
+	}
+}
+
+// ContainerAppIngressOptions are the ingress settings of a container app.
+type ContainerAppIngressOptions struct {
+	// Whether the container app is accessible from the internet (true), or only from its environment (false)
+	External *bool `yaml:"external,omitempty"`
+	// The port of the container receiving the requests
+	TargetPort *int32 `yaml:"targetPort,omitempty"`
+}
+
+// ContainerAppScaleOptions are the scale settings of a container app.
+type ContainerAppScaleOptions struct {
+	// The minimum number of replicas, from 0 to 1000
+	MinReplicas *int32 `yaml:"minReplicas,omitempty"`
+	// The maximum number of replicas, from 1 to 1000
+	MaxReplicas *int32 `yaml:"maxReplicas,omitempty"`
+	// The number of concurrent HTTP requests per replica above which the container app scales out
+	ConcurrentRequests *int32 `yaml:"concurrentRequests,omitempty"`
+}
+
+// ContainerAppDaprOptions are the Dapr settings of a container app.
+type ContainerAppDaprOptions struct {
+	// Whether the Dapr sidecar runs alongside the container app. Keeps the state of the container app when not set, and
+	// enables Dapr when the container app doesn't have Dapr settings yet
+	Enabled *bool `yaml:"enabled,omitempty"`
+	// The Dapr application identifier of the container app. Keeps the identifier of the container app when not set, and
+	// defaults to the name of the service when it doesn't have one
+	AppId string `yaml:"appId,omitempty"`
+	// The port the container app listens on, which the Dapr sidecar sends requests to. Required unless Dapr is disabled
+	AppPort *int32 `yaml:"appPort,omitempty"`
+}
+
+// enabled returns whether the options enable Dapr, which is the case unless Dapr is explicitly disabled, since deploying
+// enables Dapr when enabled isn't set.
+func (o *ContainerAppDaprOptions) enabled() bool {
+	return o.Enabled == nil || *o.Enabled
+}
+
+// maxContainerAppReplicas is the maximum number of replicas of a container app.
+const maxContainerAppReplicas = 1000
+
+// Validate checks that the ingress and scale settings are in the ranges accepted by Azure Container Apps.
+func (o *ContainerAppOptions) Validate() error {
+	if o.Ingress != nil && o.Ingress.TargetPort != nil {
+		if port := *o.Ingress.TargetPort; port < 1 || port > 65535 {
+			return fmt.Errorf("containerapp.ingress.targetPort must be between 1 and 65535, got %d", port)
+		}
+	}
+
+	if o.Dapr != nil {
+		if o.Dapr.enabled() && o.Dapr.AppPort == nil {
+			return errors.New("containerapp.dapr.appPort is required when Dapr is enabled")
+		}
+
+		if o.Dapr.AppPort != nil && (*o.Dapr.AppPort < 1 || *o.Dapr.AppPort > 65535) {
+			return fmt.Errorf("containerapp.dapr.appPort must be between 1 and 65535, got %d", *o.Dapr.AppPort)
+		}
+	}
+
+	if err := validateContainerAppContainers(o.Containers); err != nil {
+		return err
+	}
+
+	if o.Scale == nil {
+		return nil
+	}
+
+	minReplicas, maxReplicas := o.Scale.MinReplicas, o.Scale.MaxReplicas
+	if minReplicas != nil && (*minReplicas < 0 || *minReplicas > maxContainerAppReplicas) {
+		return fmt.Errorf(
+			"containerapp.scale.minReplicas must be between 0 and %d, got %d", maxContainerAppReplicas, *minReplicas)
+	}
+
+	if maxReplicas != nil && (*maxReplicas < 1 || *maxReplicas > maxContainerAppReplicas) {
+		return fmt.Errorf(
+			"containerapp.scale.maxReplicas must be between 1 and %d, got %d", maxContainerAppReplicas, *maxReplicas)
+	}
+
+	if minReplicas != nil && maxReplicas != nil && *minReplicas > *maxReplicas {
+		return fmt.Errorf(
+			"containerapp.scale.minReplicas (%d) can't be greater than maxReplicas (%d)", *minReplicas, *maxReplicas)
+	}
+
+	if o.Scale.ConcurrentRequests != nil && *o.Scale.ConcurrentRequests < 1 {
+		return fmt.Errorf(
+			"containerapp.scale.concurrentRequests must be at least 1, got %d", *o.Scale.ConcurrentRequests)
+	}
+
+	return nil
+}
+
+// validateContainerAppContainers checks that the containers have unique names, and either an image or a project.
+func validateContainerAppContainers(containers []ContainerAppContainer) error {
+	names := map[string]bool{}
+	for i, container := range containers {
+		if container.Name == "" {
+			return fmt.Errorf("containerapp.containers[%d].name is required", i)
+		}
+
+		if names[container.Name] {
+			return fmt.Errorf("containerapp.containers has more than one container named '%s'", container.Name)
+		}
+		names[container.Name] = true
+
+		if container.Image.Empty() == (container.Project == "") {
+			return fmt.Errorf(
+				"containerapp.containers '%s' must have either an 'image' or a 'project' to build it from", container.Name)
+		}
+
+		if container.Resources != nil && container.Resources.Cpu < 0 {
+			return fmt.Errorf(
+				"containerapp.containers '%s' resources.cpu must be positive, got %g", container.Name, container.Resources.Cpu)
+		}
+	}
+
+	return nil
+}
+
+// containerAppRevisionOptions returns the options of the revision of the service added on deploy, with the expanded
+// revision suffix.
+func containerAppRevisionOptions(serviceConfig *ServiceConfig, revisionSuffix string) containerapps.RevisionOptions {
+	o := serviceConfig.ContainerApp
+	options := containerapps.RevisionOptions{
+		Suffix: revisionSuffix,
+	}
+
+	if o.Ingress != nil {
+		options.Ingress = &containerapps.IngressOptions{
+			External:   o.Ingress.External,
+			TargetPort: o.Ingress.TargetPort,
+		}
+	}
+
+	if o.Scale != nil {
+		options.Scale = &containerapps.ScaleOptions{
+			MinReplicas:        o.Scale.MinReplicas,
+			MaxReplicas:        o.Scale.MaxReplicas,
+			ConcurrentRequests: o.Scale.ConcurrentRequests,
+		}
+	}
+
+	if o.Dapr != nil {
+		options.Dapr = &containerapps.DaprOptions{
+			Enabled:      o.Dapr.Enabled,
+			AppId:        o.Dapr.AppId,
+			DefaultAppId: serviceConfig.Name,
+			AppPort:      o.Dapr.AppPort,
+		}
+	}
+
+	return options
+}
+
+// splitSecureOutputEnv separates the environment variables mapped from secure provisioning outputs from the other
+// environment variables of outputEnv, so their values are stored as secrets of the container app.
+func splitSecureOutputEnv(
+	serviceConfig *ServiceConfig,
+	env *environment.Environment,
+	outputEnv map[string]string,
+) (map[string]string, map[string]string, error) {
+	secureOutputs, err := provisioning.SecureOutputs(env)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(secureOutputs) == 0 {
+		return outputEnv, nil, nil
+	}
+
+	plainEnv := map[string]string{}
+	secretEnv := map[string]string{}
+	for name, value := range outputEnv {
+		if slices.Contains(secureOutputs, serviceConfig.OutputEnv[name]) {
+			secretEnv[name] = value
+		} else {
+			plainEnv[name] = value
+		}
+	}
+
+	return plainEnv, secretEnv, nil
+}
+
 type containerAppTarget struct {
 	env                 *environment.Environment
 	envManager          environment.Manager
 	containerHelper     *ContainerHelper
 	containerAppService containerapps.ContainerAppService
 	resourceManager     ResourceManager
+	serviceLocator      ioc.ServiceLocator
 }
 
 // NewContainerAppTarget creates the container app service target.
@@ -34,6 +283,7 @@ func NewContainerAppTarget(
 	containerHelper *ContainerHelper,
 	containerAppService containerapps.ContainerAppService,
 	resourceManager ResourceManager,
+	serviceLocator ioc.ServiceLocator,
 ) ServiceTarget {
 	return &containerAppTarget{
 		env:                 env,
@@ -41,6 +291,7 @@ func NewContainerAppTarget(
 		containerHelper:     containerHelper,
 		containerAppService: containerAppService,
 		resourceManager:     resourceManager,
+		serviceLocator:      serviceLocator,
 	}
 }
 
@@ -58,7 +309,8 @@ func (at *containerAppTarget) Initialize(ctx context.Context, serviceConfig *Ser
 	return nil
 }
 
-// Prepares and tags the container image from the build output based on the specified service configuration
+// Prepares and tags the container image from the build output based on the specified service configuration. The
+// images of the additional containers built from a project are built and tagged like the image of the service.
 func (at *containerAppTarget) Package(
 	ctx context.Context,
 	serviceConfig *ServiceConfig,
@@ -66,11 +318,155 @@ func (at *containerAppTarget) Package(
 ) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
 	return async.RunTaskWithProgress(
 		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
-			task.SetResult(packageOutput)
+			containers := map[string]*dockerPackageResult{}
+			for _, container := range serviceConfig.ContainerApp.Containers {
+				if container.Project == "" {
+					continue
+				}
+
+				containerPackage, err := at.packageContainer(ctx, task, serviceConfig, container)
+				if err != nil {
+					task.SetError(err)
+					return
+				}
+
+				containers[container.Name] = containerPackage
+			}
+
+			if len(containers) == 0 {
+				task.SetResult(packageOutput)
+				return
+			}
+
+			packageDetails, ok := packageOutput.Details.(*dockerPackageResult)
+			if !ok || packageDetails == nil {
+				task.SetError(fmt.Errorf("service '%s' wasn't packaged as a container image", serviceConfig.Name))
+				return
+			}
+
+			details := *packageDetails
+			details.Containers = containers
+			task.SetResult(&ServicePackageResult{
+				Build:       packageOutput.Build,
+				PackagePath: packageOutput.PackagePath,
+				Details:     &details,
+			})
 		},
 	)
 }
 
+// packageContainer builds and tags the image of an additional container of the container app from its project.
+func (at *containerAppTarget) packageContainer(
+	ctx context.Context,
+	task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress],
+	serviceConfig *ServiceConfig,
+	container ContainerAppContainer,
+) (*dockerPackageResult, error) {
+	var framework FrameworkService
+	if err := at.serviceLocator.ResolveNamed(string(ServiceLanguageDocker), &framework); err != nil {
+		return nil, fmt.Errorf("resolving docker framework: %w", err)
+	}
+
+	containerConfig := container.serviceConfig(serviceConfig)
+
+	buildTask := framework.Build(ctx, containerConfig, nil)
+	syncProgress(task, buildTask.Progress())
+	buildResult, err := buildTask.Await()
+	if err != nil {
+		return nil, fmt.Errorf("building container '%s': %w", container.Name, err)
+	}
+
+	packageTask := framework.Package(ctx, containerConfig, buildResult)
+	syncProgress(task, packageTask.Progress())
+	packageResult, err := packageTask.Await()
+	if err != nil {
+		return nil, fmt.Errorf("packaging container '%s': %w", container.Name, err)
+	}
+
+	packageDetails, ok := packageResult.Details.(*dockerPackageResult)
+	if !ok {
+		return nil, fmt.Errorf("container '%s' wasn't packaged as a container image", container.Name)
+	}
+
+	return packageDetails, nil
+}
+
+// deployContainers pushes the images of the additional containers of the container app built by azd, and returns the
+// options of the containers of the revision.
+func (at *containerAppTarget) deployContainers(
+	ctx context.Context,
+	task *async.TaskContextWithProgress[*ServiceDeployResult, ServiceProgress],
+	serviceConfig *ServiceConfig,
+	packageOutput *ServicePackageResult,
+	targetResource *environment.TargetResource,
+) ([]containerapps.ContainerOptions, error) {
+	containers := serviceConfig.ContainerApp.Containers
+	if len(containers) == 0 {
+		return nil, nil
+	}
+
+	var packages map[string]*dockerPackageResult
+	if packageDetails, ok := packageOutput.Details.(*dockerPackageResult); ok && packageDetails != nil {
+		packages = packageDetails.Containers
+	}
+
+	options := make([]containerapps.ContainerOptions, 0, len(containers))
+	for _, container := range containers {
+		image, err := container.Image.Envsubst(at.env.Getenv)
+		if err != nil {
+			return nil, fmt.Errorf("expanding image of container '%s': %w", container.Name, err)
+		}
+
+		if container.Project != "" {
+			containerPackage, has := packages[container.Name]
+			if !has {
+				return nil, fmt.Errorf(
+					"container '%s' of service '%s' wasn't packaged, its image is built when packaging the service",
+					container.Name, serviceConfig.Name)
+			}
+
+			deployTask := at.containerHelper.Deploy(
+				ctx,
+				container.serviceConfig(serviceConfig),
+				&ServicePackageResult{Details: containerPackage},
+				targetResource,
+				false,
+			)
+			syncProgress(task, deployTask.Progress())
+			deployResult, err := deployTask.Await()
+			if err != nil {
+				return nil, fmt.Errorf("pushing image of container '%s': %w", container.Name, err)
+			}
+
+			image = deployResult.Details.(*dockerDeployResult).RemoteImageTag
+		}
+
+		containerOptions := containerapps.ContainerOptions{
+			Name:  container.Name,
+			Image: image,
+			Env:   map[string]string{},
+		}
+
+		for name, value := range container.Env {
+			expanded, err := value.Envsubst(at.env.Getenv)
+			if err != nil {
+				return nil, fmt.Errorf("expanding env '%s' of container '%s': %w", name, container.Name, err)
+			}
+
+			containerOptions.Env[name] = expanded
+		}
+
+		if container.Resources != nil {
+			containerOptions.Cpu = container.Resources.Cpu
+			containerOptions.Memory = container.Resources.Memory
+		}
+
+		options = append(options, containerOptions)
+	}
+
+	return options, nil
+}
+
 // Deploys service container images to ACR and provisions the container app service.
 func (at *containerAppTarget) Deploy(
 	ctx context.Context,
@@ -85,31 +481,94 @@ func (at *containerAppTarget) Deploy(
 				return
 			}
 
+			// The revision suffix is validated before the image is pushed
+			revisionSuffix, err := serviceConfig.ContainerApp.RevisionSuffix.Envsubst(at.env.Getenv)
+			if err != nil {
+				task.SetError(fmt.Errorf("expanding revision suffix: %w", err))
+				return
+			}
+
+			if revisionSuffix != "" {
+				if err := containerapps.ValidateRevisionSuffix(targetResource.ResourceName(), revisionSuffix); err != nil {
+					task.SetError(err)
+					return
+				}
+			}
+
+			// The provisioning outputs mapped by outputEnv are resolved before the image is pushed
+			outputEnv, err := serviceConfig.ResolveOutputEnv(at.env)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			outputEnv, secretEnv, err := splitSecureOutputEnv(serviceConfig, at.env, outputEnv)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
 			// Login, tag & push container image to ACR
 			containerDeployTask := at.containerHelper.Deploy(ctx, serviceConfig, packageOutput, targetResource, true)
 			syncProgress(task, containerDeployTask.Progress())
 
-			_, err := containerDeployTask.Await()
+			_, err = containerDeployTask.Await()
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			containers, err := at.deployContainers(ctx, task, serviceConfig, packageOutput, targetResource)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			// Images in registries other than ACR are pulled with the configured credentials
+			var registry *containerapps.RegistryCredentials
+			pullCredentials, err := at.containerHelper.PullCredentials(ctx, serviceConfig)
 			if err != nil {
 				task.SetError(err)
 				return
 			}
 
+			if pullCredentials != nil {
+				registry = &containerapps.RegistryCredentials{
+					Server:   pullCredentials.LoginServer,
+					Username: pullCredentials.Username,
+					Password: pullCredentials.Password,
+				}
+			}
+
 			imageName := at.env.GetServiceProperty(serviceConfig.Name, "IMAGE_NAME")
-			task.SetProgress(NewServiceProgress("Updating container app revision"))
-			err = at.containerAppService.AddRevision(
+			revisionOptions := containerAppRevisionOptions(serviceConfig, revisionSuffix)
+			revisionOptions.Containers = containers
+			revisionOptions.Env = outputEnv
+			revisionOptions.SecretEnv = secretEnv
+
+			task.SetProgress(NewServicePhaseProgress(ProgressPhaseCreatingDeployment, "Updating container app revision"))
+			revisionName, err := at.containerAppService.AddRevision(
 				ctx,
 				targetResource.SubscriptionId(),
 				targetResource.ResourceGroupName(),
 				targetResource.ResourceName(),
 				imageName,
+				revisionOptions,
+				registry,
 			)
 			if err != nil {
 				task.SetError(fmt.Errorf("updating container app service: %w", err))
 				return
 			}
 
-			task.SetProgress(NewServiceProgress("Fetching endpoints for container app service"))
+			// Save the name of the created revision, like the image name, so it can be referenced after the deploy
+			at.env.SetServiceProperty(serviceConfig.Name, "REVISION_NAME", revisionName)
+			if err := at.envManager.Save(ctx, at.env); err != nil {
+				task.SetError(fmt.Errorf("saving revision name to environment: %w", err))
+				return
+			}
+
+			task.SetProgress(NewServicePhaseProgress(ProgressPhaseFetchingEndpoints, "Fetching endpoints for container app service"))
 			endpoints, err := at.Endpoints(ctx, serviceConfig, targetResource)
 			if err != nil {
 				task.SetError(err)
@@ -5,7 +5,12 @@ package project
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -15,7 +20,10 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/containerapps"
 	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
@@ -23,6 +31,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
 	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazsdk"
 	"github.com/azure/azure-dev/cli/azd/test/mocks/mockenv"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockinput"
 	"github.com/azure/azure-dev/cli/azd/test/ostest"
 	"github.com/benbjohnson/clock"
 	"github.com/stretchr/testify/require"
@@ -152,7 +161,9 @@ func createContainerAppServiceTarget(
 		clock.NewMock(),
 		containerRegistryService,
 		dockerCli,
+		nil,
 		cloud.AzurePublic(),
+		nil,
 	)
 	azCli := mockazcli.NewAzCliFromMockContext(mockContext)
 	depOpService := mockazcli.NewDeploymentOperationsServiceFromMockContext(mockContext)
@@ -164,16 +175,19 @@ func createContainerAppServiceTarget(
 		containerHelper,
 		containerAppService,
 		resourceManager,
+		mockContext.Container,
 	)
 }
 
-func setupMocksForContainerAppTarget(mockContext *mocks.MockContext) {
+// setupMocksForContainerAppTarget mocks the registry and container app requests, and returns the request updating the
+// container app.
+func setupMocksForContainerAppTarget(mockContext *mocks.MockContext) *http.Request {
 	setupMocksForDocker(mockContext)
 	setupMocksForAcr(mockContext)
-	setupMocksForContainerApps(mockContext)
+	return setupMocksForContainerApps(mockContext)
 }
 
-func setupMocksForContainerApps(mockContext *mocks.MockContext) {
+func setupMocksForContainerApps(mockContext *mocks.MockContext) *http.Request {
 	subscriptionId := "SUBSCRIPTION_ID"
 	location := "eastus2"
 	resourceGroup := "RESOURCE_GROUP"
@@ -236,6 +250,271 @@ func setupMocksForContainerApps(mockContext *mocks.MockContext) {
 		revision,
 	)
 	mockazsdk.MockContainerAppSecretsList(mockContext, subscriptionId, resourceGroup, appName, secrets)
-	mockazsdk.MockContainerAppUpdate(mockContext, subscriptionId, resourceGroup, appName, containerApp)
+	updateRequest := mockazsdk.MockContainerAppUpdate(mockContext, subscriptionId, resourceGroup, appName, containerApp)
 	mockazsdk.MockContainerRegistryTokenExchange(mockContext, subscriptionId, subscriptionId, "REFRESH_TOKEN")
+
+	return updateRequest
+}
+
+func Test_ContainerApp_Deploy_RevisionSuffix(t *testing.T) {
+	deploy := func(t *testing.T, revisionSuffix string, env *environment.Environment) (*ServiceDeployResult, error) {
+		tempDir := t.TempDir()
+		ostest.Chdir(t, tempDir)
+
+		mockContext := mocks.NewMockContext(context.Background())
+		setupMocksForContainerAppTarget(mockContext)
+
+		serviceConfig := createTestServiceConfig(tempDir, ContainerAppTarget, ServiceLanguageTypeScript)
+		serviceConfig.ContainerApp.RevisionSuffix = osutil.NewExpandableString(revisionSuffix)
+		serviceTarget := createContainerAppServiceTarget(mockContext, serviceConfig, env)
+
+		packageResult := &ServicePackageResult{
+			PackagePath: "test-app/api-test:azd-deploy-0",
+			Details: &dockerPackageResult{
+				ImageHash:   "IMAGE_HASH",
+				TargetImage: "test-app/api-test:azd-deploy-0",
+			},
+		}
+		scope := environment.NewTargetResource(
+			"SUBSCRIPTION_ID",
+			"RESOURCE_GROUP",
+			"CONTAINER_APP",
+			string(infra.AzureResourceTypeContainerApp),
+		)
+
+		deployTask := serviceTarget.Deploy(*mockContext.Context, serviceConfig, packageResult, scope)
+		logProgress(deployTask)
+		return deployTask.Await()
+	}
+
+	t.Run("Expanded", func(t *testing.T) {
+		env := createEnv()
+		env.DotenvSet("GIT_SHA", "1a2b3c")
+
+		deployResult, err := deploy(t, "sha-${GIT_SHA}", env)
+		require.NoError(t, err)
+		require.NotNil(t, deployResult)
+		// The name of the created revision is recorded in the environment
+		require.Equal(t, "CONTAINER_APP--sha-1a2b3c", env.Dotenv()["SERVICE_API_REVISION_NAME"])
+	})
+
+	t.Run("Default", func(t *testing.T) {
+		env := createEnv()
+
+		_, err := deploy(t, "", env)
+		require.NoError(t, err)
+		require.Equal(t, "CONTAINER_APP--azd-0", env.Dotenv()["SERVICE_API_REVISION_NAME"])
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		env := createEnv()
+		env.DotenvSet("GIT_SHA", "1A2B3C")
+
+		_, err := deploy(t, "${GIT_SHA}", env)
+		require.ErrorContains(t, err, "invalid revision suffix '1A2B3C'")
+		// The image isn't pushed for an invalid suffix
+		require.Empty(t, env.Dotenv()["SERVICE_API_IMAGE_NAME"])
+	})
+}
+
+func Test_ContainerApp_Deploy_Containers(t *testing.T) {
+	tempDir := t.TempDir()
+	ostest.Chdir(t, tempDir)
+
+	loggerPath := filepath.Join(tempDir, "logger")
+	require.NoError(t, os.MkdirAll(loggerPath, osutil.PermissionDirectory))
+	require.NoError(t, os.WriteFile(filepath.Join(loggerPath, "Dockerfile"), []byte("FROM alpine"), osutil.PermissionFile))
+
+	mockContext := mocks.NewMockContext(context.Background())
+	updateRequest := setupMocksForContainerAppTarget(mockContext)
+
+	builtImages := []string{}
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "docker build")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		builtImages = append(builtImages, args.Args[slices.Index(args.Args, "-t")+1])
+
+		// The image id is written to the file following '--iidfile'
+		err := os.WriteFile(args.Args[len(args.Args)-1], []byte("LOGGER_IMAGE_ID"), osutil.PermissionFile)
+		return exec.NewRunResult(0, "", ""), err
+	})
+
+	env := createEnv()
+	env.DotenvSet("LOG_LEVEL", "info")
+	env.DotenvSet("SQL_CONN", "Server=db")
+	env.DotenvSet("SQL_PASSWORD", "P@ssw0rd")
+	require.NoError(t, env.Config.Set(provisioning.SecureOutputsConfigPath, []string{"SQL_PASSWORD"}))
+
+	serviceConfig := createTestServiceConfig(tempDir, ContainerAppTarget, ServiceLanguageDocker)
+	serviceConfig.OutputEnv = map[string]string{"DATABASE_URL": "SQL_CONN", "DATABASE_PASSWORD": "SQL_PASSWORD"}
+	serviceConfig.ContainerApp.Containers = []ContainerAppContainer{
+		{
+			Name:    "logger",
+			Project: "logger",
+			Env: map[string]osutil.ExpandableString{
+				"LEVEL": osutil.NewExpandableString("${LOG_LEVEL}"),
+			},
+			Resources: &ContainerAppResources{Cpu: 0.25, Memory: "0.5Gi"},
+		},
+		{
+			Name:  "proxy",
+			Image: osutil.NewExpandableString("docker.io/envoyproxy/envoy:v1.30"),
+		},
+	}
+
+	dockerCli := docker.NewDocker(mockContext.CommandRunner)
+	mockContext.Container.MustRegisterNamedSingleton(string(ServiceLanguageDocker), func() FrameworkService {
+		containerHelper := NewContainerHelper(
+			env, &mockenv.MockEnvManager{}, clock.NewMock(), nil, dockerCli, nil, cloud.AzurePublic(), nil)
+		return NewDockerProject(
+			env, dockerCli, containerHelper, mockinput.NewMockConsole(), mockContext.AlphaFeaturesManager,
+			mockContext.CommandRunner)
+	})
+
+	serviceTarget := createContainerAppServiceTarget(mockContext, serviceConfig, env)
+
+	packageTask := serviceTarget.Package(*mockContext.Context, serviceConfig, &ServicePackageResult{
+		PackagePath: "test-app/api-test:azd-deploy-0",
+		Details: &dockerPackageResult{
+			ImageHash:   "IMAGE_HASH",
+			TargetImage: "test-app/api-test:azd-deploy-0",
+		},
+	})
+	logProgress(packageTask)
+	packageResult, err := packageTask.Await()
+	require.NoError(t, err)
+
+	// Only the image of the container with a project is built
+	require.Equal(t, []string{"test-app-api-logger"}, builtImages)
+	containerPackage := packageResult.Details.(*dockerPackageResult).Containers["logger"]
+	require.Equal(t, "LOGGER_IMAGE_ID", containerPackage.ImageHash)
+	require.Equal(t, "test-app/api-logger-test:azd-deploy-0", containerPackage.TargetImage)
+
+	scope := environment.NewTargetResource(
+		"SUBSCRIPTION_ID",
+		"RESOURCE_GROUP",
+		"CONTAINER_APP",
+		string(infra.AzureResourceTypeContainerApp),
+	)
+
+	deployTask := serviceTarget.Deploy(*mockContext.Context, serviceConfig, packageResult, scope)
+	logProgress(deployTask)
+	_, err = deployTask.Await()
+	require.NoError(t, err)
+
+	var updatedContainerApp *armappcontainers.ContainerApp
+	require.NoError(t, json.NewDecoder(updateRequest.Body).Decode(&updatedContainerApp))
+
+	containers := updatedContainerApp.Properties.Template.Containers
+	require.Len(t, containers, 3)
+	require.Equal(t, "REGISTRY.azurecr.io/test-app/api-test:azd-deploy-0", *containers[0].Image)
+	// The outputs mapped by outputEnv are set on the main container, and the secure outputs are stored as secrets
+	require.Equal(t, []*armappcontainers.EnvironmentVar{
+		{Name: convert.RefOf("DATABASE_URL"), Value: convert.RefOf("Server=db")},
+		{Name: convert.RefOf("DATABASE_PASSWORD"), SecretRef: convert.RefOf("azd-env-database-password")},
+	}, containers[0].Env)
+	require.Equal(t, []*armappcontainers.Secret{
+		{Name: convert.RefOf("azd-env-database-password"), Value: convert.RefOf("P@ssw0rd")},
+	}, updatedContainerApp.Properties.Configuration.Secrets)
+
+	// The built image is pushed to the registry of the service
+	require.Equal(t, "logger", *containers[1].Name)
+	require.Equal(t, "REGISTRY.azurecr.io/test-app/api-logger-test:azd-deploy-0", *containers[1].Image)
+	require.Equal(t, []*armappcontainers.EnvironmentVar{
+		{Name: convert.RefOf("LEVEL"), Value: convert.RefOf("info")},
+	}, containers[1].Env)
+	require.Equal(t, 0.25, *containers[1].Resources.CPU)
+	require.Equal(t, "0.5Gi", *containers[1].Resources.Memory)
+
+	// The external image is deployed as-is
+	require.Equal(t, "proxy", *containers[2].Name)
+	require.Equal(t, "docker.io/envoyproxy/envoy:v1.30", *containers[2].Image)
+	require.Nil(t, containers[2].Resources)
+}
+
+func Test_ContainerApp_Deploy_ExistingImage(t *testing.T) {
+	tempDir := t.TempDir()
+	ostest.Chdir(t, tempDir)
+
+	mockContext := mocks.NewMockContext(context.Background())
+	updateRequest := setupMocksForContainerAppTarget(mockContext)
+
+	dockerCommands := []string{}
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return args.Cmd == "docker"
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		dockerCommands = append(dockerCommands, args.Args[0])
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	env := createEnv()
+	serviceConfig := createTestServiceConfig("", ContainerAppTarget, ServiceLanguageDocker)
+	serviceConfig.Image = "docker.io/nginx:1.25"
+	serviceConfig.Docker.Push = convert.RefOf(false)
+	require.NoError(t, serviceConfig.ValidateImage())
+
+	dockerCli := docker.NewDocker(mockContext.CommandRunner)
+	containerHelper := NewContainerHelper(
+		env, &mockenv.MockEnvManager{}, clock.NewMock(), nil, dockerCli, nil, cloud.AzurePublic(), nil)
+	framework := NewDockerProject(
+		env, dockerCli, containerHelper, mockinput.NewMockConsole(), mockContext.AlphaFeaturesManager,
+		mockContext.CommandRunner)
+
+	// The image isn't built
+	buildResult, err := framework.Build(*mockContext.Context, serviceConfig, nil).Await()
+	require.NoError(t, err)
+	require.Empty(t, buildResult.BuildOutputPath)
+
+	packageResult, err := framework.Package(*mockContext.Context, serviceConfig, buildResult).Await()
+	require.NoError(t, err)
+	require.Equal(t, "docker.io/nginx:1.25", packageResult.PackagePath)
+
+	serviceTarget := createContainerAppServiceTarget(mockContext, serviceConfig, env)
+	scope := environment.NewTargetResource(
+		"SUBSCRIPTION_ID",
+		"RESOURCE_GROUP",
+		"CONTAINER_APP",
+		string(infra.AzureResourceTypeContainerApp),
+	)
+
+	deployTask := serviceTarget.Deploy(*mockContext.Context, serviceConfig, packageResult, scope)
+	logProgress(deployTask)
+	_, err = deployTask.Await()
+	require.NoError(t, err)
+
+	// The image is deployed directly from its registry, without being pulled, tagged or pushed to the registry of the
+	// environment
+	require.Empty(t, dockerCommands)
+	require.Equal(t, "docker.io/nginx:1.25", env.GetServiceProperty("api", "IMAGE_NAME"))
+
+	var updatedContainerApp *armappcontainers.ContainerApp
+	require.NoError(t, json.NewDecoder(updateRequest.Body).Decode(&updatedContainerApp))
+	require.Equal(t, "docker.io/nginx:1.25", *updatedContainerApp.Properties.Template.Containers[0].Image)
+}
+
+func Test_ContainerAppRevisionOptions_Dapr(t *testing.T) {
+	serviceConfig := &ServiceConfig{
+		Name: "api",
+		ContainerApp: ContainerAppOptions{
+			Dapr: &ContainerAppDaprOptions{
+				Enabled: convert.RefOf(true),
+				AppPort: convert.RefOf[int32](3000),
+			},
+		},
+	}
+
+	// The app id of the container app is kept, and defaults to the name of the service when it doesn't have one
+	options := containerAppRevisionOptions(serviceConfig, "")
+	require.Equal(t, &containerapps.DaprOptions{
+		Enabled:      convert.RefOf(true),
+		DefaultAppId: "api",
+		AppPort:      convert.RefOf[int32](3000),
+	}, options.Dapr)
+
+	serviceConfig.ContainerApp.Dapr.AppId = "orders"
+	options = containerAppRevisionOptions(serviceConfig, "")
+	require.Equal(t, "orders", options.Dapr.AppId)
+
+	// Dapr is unchanged when not configured
+	require.Nil(t, containerAppRevisionOptions(&ServiceConfig{Name: "api"}, "").Dapr)
 }
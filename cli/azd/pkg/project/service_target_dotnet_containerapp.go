@@ -110,7 +110,7 @@ func (at *dotnetContainerAppTarget) Deploy(
 				return
 			}
 
-			task.SetProgress(NewServiceProgress("Logging in to registry"))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhasePushingImage, "Logging in to registry"))
 
 			// Login, tag & push container image to ACR
 			dockerCreds, err := at.containerHelper.Credentials(ctx, serviceConfig, targetResource)
@@ -119,7 +119,7 @@ func (at *dotnetContainerAppTarget) Deploy(
 				return
 			}
 
-			task.SetProgress(NewServiceProgress("Pushing container image"))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhasePushingImage, "Pushing container image"))
 
 			var remoteImageName string
 			var portNumber int
@@ -154,7 +154,7 @@ func (at *dotnetContainerAppTarget) Deploy(
 				remoteImageName = fmt.Sprintf("%s/%s", dockerCreds.LoginServer, imageName)
 			}
 
-			task.SetProgress(NewServiceProgress("Updating container app"))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhaseCreatingDeployment, "Updating container app"))
 
 			var manifest string
 
@@ -265,7 +265,7 @@ func (at *dotnetContainerAppTarget) Deploy(
 				return
 			}
 
-			task.SetProgress(NewServiceProgress("Fetching endpoints for container app service"))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhaseFetchingEndpoints, "Fetching endpoints for container app service"))
 
 			containerAppTarget := environment.NewTargetResource(
 				targetResource.SubscriptionId(),
@@ -5,6 +5,7 @@ package project
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -12,26 +13,42 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/async"
 	"github.com/azure/azure-dev/cli/azd/pkg/azure"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 )
 
+// The tier and SKU name of the Flex Consumption plan.
+const (
+	flexConsumptionTier = "FlexConsumption"
+	flexConsumptionSku  = "FC1"
+)
+
 // functionAppTarget specifies an Azure Function to deploy to.
 // Implements `project.ServiceTarget`
 type functionAppTarget struct {
-	env *environment.Environment
-	cli azcli.AzCli
+	env        *environment.Environment
+	cli        azcli.AzCli
+	httpClient httputil.HttpClient
+	slot       *slotDeployment
 }
 
 // NewFunctionAppTarget creates a new instance of the Function App target
 func NewFunctionAppTarget(
 	env *environment.Environment,
 	azCli azcli.AzCli,
+	httpClient httputil.HttpClient,
 ) ServiceTarget {
 	return &functionAppTarget{
-		env: env,
-		cli: azCli,
+		env:        env,
+		cli:        azCli,
+		httpClient: httpClient,
+		slot: &slotDeployment{
+			env:        env,
+			cli:        azCli,
+			httpClient: httpClient,
+		},
 	}
 }
 
@@ -53,8 +70,9 @@ func (f *functionAppTarget) Package(
 ) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
 	return async.RunTaskWithProgress(
 		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
-			task.SetProgress(NewServiceProgress("Compressing deployment artifacts"))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhasePackaging, "Compressing deployment artifacts"))
 			zipFilePath, err := createDeployableZip(
+				ctx,
 				serviceConfig.Project.Name,
 				serviceConfig.Name,
 				packageOutput.PackagePath,
@@ -95,26 +113,79 @@ func (f *functionAppTarget) Deploy(
 			defer os.Remove(packageOutput.PackagePath)
 			defer zipFile.Close()
 
-			task.SetProgress(NewServiceProgress("Uploading deployment package"))
-			res, err := f.cli.DeployFunctionAppUsingZipFile(
-				ctx,
-				targetResource.SubscriptionId(),
-				targetResource.ResourceGroupName(),
-				targetResource.ResourceName(),
-				zipFile,
-			)
+			flex, err := f.isFlexConsumption(ctx, serviceConfig, targetResource)
 			if err != nil {
 				task.SetError(err)
 				return
 			}
 
-			task.SetProgress(NewServiceProgress("Fetching endpoints for function app"))
-			endpoints, err := f.Endpoints(ctx, serviceConfig, targetResource)
+			slotName, err := f.slot.slotName(serviceConfig)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			if flex && slotName != "" {
+				task.SetError(errors.New("deployment slots are not supported on the Flex Consumption plan"))
+				return
+			}
+
+			// Deployment slots are updated once they exist
+			if slotName == "" {
+				if err := f.slot.updateAppSettings(ctx, task, serviceConfig, targetResource, ""); err != nil {
+					task.SetError(fmt.Errorf("deploying service %s: %w", serviceConfig.Name, err))
+					return
+				}
+			}
+
+			var res *string
+			var endpoints []string
+			if slotName != "" {
+				res, endpoints, err = f.slot.Deploy(ctx, task, serviceConfig, targetResource, slotName, zipFile)
+			} else if flex {
+				task.SetProgress(NewServicePhaseProgress(ProgressPhaseUploading, "Uploading deployment package"))
+				// Python dependencies are installed by the platform since the package is built on the local machine
+				res, err = f.cli.DeployFlexFunctionAppUsingZipFile(
+					ctx,
+					targetResource.SubscriptionId(),
+					targetResource.ResourceGroupName(),
+					targetResource.ResourceName(),
+					zipFile,
+					serviceConfig.Language == ServiceLanguagePython,
+				)
+			} else {
+				task.SetProgress(NewServicePhaseProgress(ProgressPhaseUploading, "Uploading deployment package"))
+				res, err = f.cli.DeployFunctionAppUsingZipFile(
+					ctx,
+					targetResource.SubscriptionId(),
+					targetResource.ResourceGroupName(),
+					targetResource.ResourceName(),
+					zipFile,
+				)
+			}
 			if err != nil {
 				task.SetError(err)
 				return
 			}
 
+			// Endpoints are only returned for a slot that wasn't swapped into production
+			if endpoints == nil {
+				task.SetProgress(NewServicePhaseProgress(ProgressPhaseFetchingEndpoints, "Fetching endpoints for function app"))
+				endpoints, err = f.Endpoints(ctx, serviceConfig, targetResource)
+				if err != nil {
+					task.SetError(err)
+					return
+				}
+			}
+
+			if len(serviceConfig.WarmUp.Paths) > 0 && len(endpoints) > 0 {
+				task.SetProgress(NewServicePhaseProgress(ProgressPhaseWarmingUp, "Warming up function app"))
+				if err := warmUp(ctx, f.httpClient, endpoints[0], serviceConfig.WarmUp); err != nil {
+					task.SetError(err)
+					return
+				}
+			}
+
 			sdr := NewServiceDeployResult(
 				azure.WebsiteRID(
 					targetResource.SubscriptionId(),
@@ -157,6 +228,32 @@ func (f *functionAppTarget) Endpoints(
 	}
 }
 
+// isFlexConsumption returns whether the function app is hosted on the Flex Consumption plan, which uses a different
+// deployment API than the Consumption, Premium and Dedicated plans. The plan is read from the SERVICE_<NAME>_PLAN_SKU
+// provisioning output when available, and from the App Service plan of the function app otherwise.
+func (f *functionAppTarget) isFlexConsumption(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+) (bool, error) {
+	planSku := f.env.GetServiceProperty(serviceConfig.Name, "PLAN_SKU")
+	if planSku == "" {
+		tier, err := f.cli.GetFunctionAppPlanTier(
+			ctx,
+			targetResource.SubscriptionId(),
+			targetResource.ResourceGroupName(),
+			targetResource.ResourceName(),
+		)
+		if err != nil {
+			return false, fmt.Errorf("getting function app plan: %w", err)
+		}
+
+		planSku = tier
+	}
+
+	return strings.EqualFold(planSku, flexConsumptionTier) || strings.EqualFold(planSku, flexConsumptionSku), nil
+}
+
 func (f *functionAppTarget) validateTargetResource(
 	ctx context.Context,
 	serviceConfig *ServiceConfig,
@@ -5,12 +5,20 @@ package project
 
 import (
 	"context"
+	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
 	"github.com/stretchr/testify/require"
 )
 
@@ -49,3 +57,113 @@ func TestNewFunctionAppTargetTypeValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestFunctionAppTargetDeployFlexConsumption(t *testing.T) {
+	tests := []struct {
+		name          string
+		language      ServiceLanguageKind
+		outputs       map[string]string
+		planTier      string
+		expectPath    string
+		expectQueries map[string]string
+	}{
+		{
+			name:          "FlexFromOutputs",
+			language:      ServiceLanguagePython,
+			outputs:       map[string]string{"SERVICE_API_PLAN_SKU": "FC1"},
+			expectPath:    "/api/publish",
+			expectQueries: map[string]string{"RemoteBuild": "true", "Deployer": "azd"},
+		},
+		{
+			name:          "FlexFromPlan",
+			language:      ServiceLanguageJavaScript,
+			planTier:      "FlexConsumption",
+			expectPath:    "/api/publish",
+			expectQueries: map[string]string{"RemoteBuild": "false"},
+		},
+		{
+			name:          "Consumption",
+			language:      ServiceLanguageJavaScript,
+			planTier:      "Dynamic",
+			expectPath:    "/api/zipdeploy",
+			expectQueries: map[string]string{"isAsync": "true"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockContext := mocks.NewMockContext(context.Background())
+			planRequested := false
+			var deployRequest *http.Request
+
+			mockContext.HttpClient.When(func(request *http.Request) bool {
+				return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/Microsoft.Web/sites/FUNC_APP")
+			}).RespondFn(func(request *http.Request) (*http.Response, error) {
+				return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armappservice.Site{
+					Properties: &armappservice.SiteProperties{
+						DefaultHostName: convert.RefOf("FUNC_APP.azurewebsites.net"),
+						ServerFarmID: convert.RefOf(
+							"/subscriptions/SUB_ID/resourceGroups/RG_ID/providers/Microsoft.Web/serverfarms/PLAN"),
+						HostNameSSLStates: []*armappservice.HostNameSSLState{
+							{
+								HostType: convert.RefOf(armappservice.HostTypeRepository),
+								Name:     convert.RefOf("FUNC_APP_SCM_HOST"),
+							},
+						},
+					},
+				})
+			})
+
+			mockContext.HttpClient.When(func(request *http.Request) bool {
+				return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/serverfarms/PLAN")
+			}).RespondFn(func(request *http.Request) (*http.Response, error) {
+				planRequested = true
+				return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armappservice.Plan{
+					SKU: &armappservice.SKUDescription{Tier: convert.RefOf(tt.planTier)},
+				})
+			})
+
+			mockContext.HttpClient.When(func(request *http.Request) bool {
+				return request.Method == http.MethodPost && request.URL.Host == "FUNC_APP_SCM_HOST"
+			}).RespondFn(func(request *http.Request) (*http.Response, error) {
+				deployRequest = request
+				response, err := mocks.CreateEmptyHttpResponse(request, http.StatusAccepted)
+				if strings.HasSuffix(request.URL.Path, "/api/zipdeploy") {
+					response.Header.Set("Location", "https://FUNC_APP_SCM_HOST/api/deployments/latest")
+				}
+				return response, err
+			})
+
+			mockContext.HttpClient.When(func(request *http.Request) bool {
+				return request.Method == http.MethodGet && request.URL.Path == "/api/deployments/latest"
+			}).RespondFn(func(request *http.Request) (*http.Response, error) {
+				return mocks.CreateHttpResponseWithBody(request, http.StatusOK, azsdk.DeployStatusResponse{
+					DeployStatus: azsdk.DeployStatus{StatusText: "OK", Complete: true},
+				})
+			})
+
+			zipPath := filepath.Join(t.TempDir(), "package.zip")
+			require.NoError(t, os.WriteFile(zipPath, []byte("zip"), osutil.PermissionFile))
+
+			env := environment.NewWithValues("dev", tt.outputs)
+			serviceConfig := createTestServiceConfig("./src/api", AzureFunctionTarget, tt.language)
+			serviceTarget := NewFunctionAppTarget(env, mockazcli.NewAzCliFromMockContext(mockContext), mockContext.HttpClient)
+			targetResource := environment.NewTargetResource(
+				"SUB_ID", "RG_ID", "FUNC_APP", string(infra.AzureResourceTypeWebSite))
+
+			deployTask := serviceTarget.Deploy(
+				*mockContext.Context, serviceConfig, &ServicePackageResult{PackagePath: zipPath}, targetResource)
+			logProgress(deployTask)
+			result, err := deployTask.Await()
+			require.NoError(t, err)
+			require.Equal(t, []string{"https://FUNC_APP.azurewebsites.net/"}, result.Endpoints)
+
+			require.NotNil(t, deployRequest)
+			require.Equal(t, tt.expectPath, deployRequest.URL.Path)
+			for key, value := range tt.expectQueries {
+				require.Equal(t, value, deployRequest.URL.Query().Get(key))
+			}
+			require.Equal(t, tt.planTier != "", planRequested)
+		})
+	}
+}
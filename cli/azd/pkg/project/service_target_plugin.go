@@ -0,0 +1,484 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+)
+
+// PluginManifestFileName is the name of the manifest describing a service target plugin. Plugins are discovered from
+// the directories of the plugins directory that contain a manifest.
+const PluginManifestFileName = "azd-plugin.json"
+
+// PluginsDirectoryName is the name of the directory of the user configuration directory containing plugins.
+const PluginsDirectoryName = "plugins"
+
+// Custom host names are lower case and may contain digits and dashes, e.g. 'my-paas'.
+var pluginHostRegex = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// ServiceTargetProvider provides the service targets of host types azd doesn't support natively.
+type ServiceTargetProvider interface {
+	// Name is the name of the provider, used in error messages.
+	Name() string
+	// Hosts are the host types, as used by the 'host' property of services, that the provider handles.
+	Hosts() []ServiceTargetKind
+	// ServiceTarget creates the service target deploying services of the host type to the environment.
+	ServiceTarget(host ServiceTargetKind, env *environment.Environment) (ServiceTarget, error)
+}
+
+// ServiceTargetRegistry contains the providers of custom host types.
+type ServiceTargetRegistry struct {
+	providers map[ServiceTargetKind]ServiceTargetProvider
+}
+
+// NewServiceTargetRegistry creates an empty registry.
+func NewServiceTargetRegistry() *ServiceTargetRegistry {
+	return &ServiceTargetRegistry{
+		providers: map[ServiceTargetKind]ServiceTargetProvider{},
+	}
+}
+
+// Register registers the provider for each of its hosts. Built-in hosts and hosts registered by another provider
+// can't be registered.
+func (r *ServiceTargetRegistry) Register(provider ServiceTargetProvider) error {
+	hosts := provider.Hosts()
+	if len(hosts) == 0 {
+		return fmt.Errorf("plugin '%s' does not declare any hosts", provider.Name())
+	}
+
+	for _, host := range hosts {
+		if isBuiltInHost(host) {
+			return fmt.Errorf("plugin '%s' can't register the built-in host '%s'", provider.Name(), host)
+		}
+
+		if !pluginHostRegex.MatchString(string(host)) {
+			return fmt.Errorf("plugin '%s' declares the invalid host '%s'", provider.Name(), host)
+		}
+
+		if existing, has := r.providers[host]; has {
+			return fmt.Errorf(
+				"plugin '%s' can't register host '%s', already registered by plugin '%s'",
+				provider.Name(),
+				host,
+				existing.Name(),
+			)
+		}
+	}
+
+	for _, host := range hosts {
+		r.providers[host] = provider
+	}
+
+	return nil
+}
+
+// Provider returns the provider registered for the host.
+func (r *ServiceTargetRegistry) Provider(host ServiceTargetKind) (ServiceTargetProvider, bool) {
+	provider, has := r.providers[host]
+	return provider, has
+}
+
+// Hosts returns the registered hosts, sorted by name.
+func (r *ServiceTargetRegistry) Hosts() []ServiceTargetKind {
+	hosts := make([]ServiceTargetKind, 0, len(r.providers))
+	for host := range r.providers {
+		hosts = append(hosts, host)
+	}
+	slices.Sort(hosts)
+
+	return hosts
+}
+
+// PluginManifest describes a service target plugin implemented by an external executable.
+type PluginManifest struct {
+	// The name of the plugin
+	Name string `json:"name"`
+	// The host types handled by the plugin
+	Hosts []ServiceTargetKind `json:"hosts"`
+	// The path of the plugin executable, relative to the directory of the manifest
+	Executable string `json:"executable"`
+	// The variables of the azd environment the plugin reads, in addition to pluginEnvironmentVariables
+	Environment []string `json:"environment,omitempty"`
+}
+
+// pluginEnvironmentVariables are the variables of the azd environment sent to every plugin. Plugins declare the other
+// variables they read in their manifest, so the values of the environment, like secrets, aren't sent to plugins that
+// don't need them.
+var pluginEnvironmentVariables = []string{
+	environment.EnvNameEnvVarName,
+	environment.SubscriptionIdEnvVarName,
+	environment.TenantIdEnvVarName,
+	environment.LocationEnvVarName,
+	environment.ResourceGroupEnvVarName,
+}
+
+// installedPlugin is the manifest of a plugin installed in a directory.
+type installedPlugin struct {
+	manifest PluginManifest
+	dir      string
+}
+
+// loadPluginManifests loads the manifests of the plugins installed in the subdirectories of dir. A missing directory
+// contains no plugins. Invalid manifests are returned as errors along with the valid ones.
+func loadPluginManifests(dir string) ([]installedPlugin, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading plugins directory: %w", err)
+	}
+
+	plugins := []installedPlugin{}
+	var errs []error
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		manifestPath := filepath.Join(dir, entry.Name(), PluginManifestFileName)
+		manifestBytes, err := os.ReadFile(manifestPath)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			errs = append(errs, fmt.Errorf("reading plugin manifest %s: %w", manifestPath, err))
+			continue
+		}
+
+		var manifest PluginManifest
+		if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+			errs = append(errs, fmt.Errorf("parsing plugin manifest %s: %w", manifestPath, err))
+			continue
+		}
+
+		if manifest.Name == "" || manifest.Executable == "" {
+			errs = append(errs, fmt.Errorf("plugin manifest %s must specify a name and an executable", manifestPath))
+			continue
+		}
+
+		plugins = append(plugins, installedPlugin{manifest: manifest, dir: filepath.Join(dir, entry.Name())})
+	}
+
+	return plugins, errors.Join(errs...)
+}
+
+// installedPluginHosts returns the hosts declared by the manifests of the plugins installed in the plugins directory
+// of the user configuration directory. Manifests that can't be loaded are logged and skipped, like LoadPlugins does.
+func installedPluginHosts() map[ServiceTargetKind]bool {
+	hosts := map[ServiceTargetKind]bool{}
+
+	configDir, err := config.GetUserConfigDir()
+	if err != nil {
+		log.Printf("could not load service target plugins: %v", err)
+		return hosts
+	}
+
+	plugins, err := loadPluginManifests(filepath.Join(configDir, PluginsDirectoryName))
+	if err != nil {
+		log.Printf("loading service target plugins: %v", err)
+	}
+
+	for _, plugin := range plugins {
+		for _, host := range plugin.manifest.Hosts {
+			hosts[host] = true
+		}
+	}
+
+	return hosts
+}
+
+// DiscoverPlugins loads the manifests of the plugins installed in the subdirectories of dir. A missing directory
+// contains no plugins. Invalid manifests are returned as errors along with the valid plugins.
+func DiscoverPlugins(dir string, commandRunner exec.CommandRunner) ([]ServiceTargetProvider, error) {
+	plugins, err := loadPluginManifests(dir)
+
+	providers := []ServiceTargetProvider{}
+	for _, plugin := range plugins {
+		executable := plugin.manifest.Executable
+		if !filepath.IsAbs(executable) {
+			executable = filepath.Join(plugin.dir, executable)
+		}
+
+		providers = append(providers, &pluginProvider{
+			manifest:      plugin.manifest,
+			executable:    executable,
+			commandRunner: commandRunner,
+		})
+	}
+
+	return providers, err
+}
+
+// LoadPlugins registers the plugins discovered in dir. Plugins that can't be loaded are logged and skipped, so a
+// broken plugin doesn't prevent using the other hosts.
+func (r *ServiceTargetRegistry) LoadPlugins(dir string, commandRunner exec.CommandRunner) {
+	providers, err := DiscoverPlugins(dir, commandRunner)
+	if err != nil {
+		log.Printf("loading service target plugins: %v", err)
+	}
+
+	for _, provider := range providers {
+		if err := r.Register(provider); err != nil {
+			log.Printf("registering service target plugin: %v", err)
+		}
+	}
+}
+
+// isBuiltInHost returns whether the host is supported natively by azd.
+func isBuiltInHost(host ServiceTargetKind) bool {
+	switch host {
+	case AppServiceTarget,
+		ContainerAppTarget,
+		AzureFunctionTarget,
+		StaticWebAppTarget,
+		SpringAppTarget,
+		AksTarget,
+		DotNetContainerAppTarget:
+		return true
+	}
+
+	return false
+}
+
+// pluginProvider provides the service targets of a plugin executable.
+type pluginProvider struct {
+	manifest      PluginManifest
+	executable    string
+	commandRunner exec.CommandRunner
+}
+
+func (p *pluginProvider) Name() string {
+	return p.manifest.Name
+}
+
+func (p *pluginProvider) Hosts() []ServiceTargetKind {
+	return p.manifest.Hosts
+}
+
+func (p *pluginProvider) ServiceTarget(host ServiceTargetKind, env *environment.Environment) (ServiceTarget, error) {
+	return &pluginServiceTarget{
+		host:          host,
+		name:          p.manifest.Name,
+		executable:    p.executable,
+		environment:   p.manifest.Environment,
+		env:           env,
+		commandRunner: p.commandRunner,
+	}, nil
+}
+
+// PluginRequest is written as JSON to the standard input of the plugin executable, which is run with the name of the
+// operation ('package', 'deploy' or 'endpoints') as its only argument.
+type PluginRequest struct {
+	Service PluginService `json:"service"`
+	// The variables of pluginEnvironmentVariables and the variables declared by the manifest of the plugin, when set
+	// in the azd environment
+	Environment    map[string]string     `json:"environment"`
+	PackagePath    string                `json:"packagePath,omitempty"`
+	TargetResource *PluginTargetResource `json:"targetResource,omitempty"`
+	// The plugin specific options of the service, from its 'config' property
+	Config map[string]any `json:"config,omitempty"`
+}
+
+// PluginService describes the service being packaged or deployed by a plugin.
+type PluginService struct {
+	Name       string `json:"name"`
+	Host       string `json:"host"`
+	Language   string `json:"language,omitempty"`
+	Path       string `json:"path"`
+	OutputPath string `json:"outputPath,omitempty"`
+	Image      string `json:"image,omitempty"`
+}
+
+// PluginTargetResource is the Azure resource found for the service, when there is one.
+type PluginTargetResource struct {
+	SubscriptionId    string `json:"subscriptionId"`
+	ResourceGroupName string `json:"resourceGroupName"`
+	ResourceName      string `json:"resourceName"`
+	ResourceType      string `json:"resourceType"`
+}
+
+// PluginResponse is read as JSON from the standard output of the plugin executable. A non-zero exit code fails the
+// operation, with the standard error of the plugin as the error message.
+type PluginResponse struct {
+	// The path of the package created by the 'package' operation
+	PackagePath string `json:"packagePath,omitempty"`
+	// A description of the deployment returned by the 'deploy' operation
+	Details string `json:"details,omitempty"`
+	// The endpoints of the service returned by the 'deploy' and 'endpoints' operations
+	Endpoints []string `json:"endpoints,omitempty"`
+}
+
+// pluginServiceTarget is the service target of a custom host, implemented by running the plugin executable.
+type pluginServiceTarget struct {
+	host          ServiceTargetKind
+	name          string
+	executable    string
+	environment   []string
+	env           *environment.Environment
+	commandRunner exec.CommandRunner
+}
+
+func (t *pluginServiceTarget) Initialize(ctx context.Context, serviceConfig *ServiceConfig) error {
+	return nil
+}
+
+func (t *pluginServiceTarget) RequiredExternalTools(ctx context.Context) []tools.ExternalTool {
+	return []tools.ExternalTool{}
+}
+
+func (t *pluginServiceTarget) Package(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	packageOutput *ServicePackageResult,
+) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
+			task.SetProgress(NewServicePhaseProgress(
+				ProgressPhasePackaging, fmt.Sprintf("Packaging with plugin '%s'", t.name)))
+			res, err := t.run(ctx, "package", serviceConfig, packageOutput.PackagePath, nil)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			packagePath := packageOutput.PackagePath
+			if res.PackagePath != "" {
+				packagePath = res.PackagePath
+			}
+
+			task.SetResult(&ServicePackageResult{
+				Build:       packageOutput.Build,
+				PackagePath: packagePath,
+			})
+		},
+	)
+}
+
+func (t *pluginServiceTarget) Deploy(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	packageOutput *ServicePackageResult,
+	targetResource *environment.TargetResource,
+) *async.TaskWithProgress[*ServiceDeployResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceDeployResult, ServiceProgress]) {
+			task.SetProgress(NewServicePhaseProgress(
+				ProgressPhaseCreatingDeployment, fmt.Sprintf("Deploying with plugin '%s'", t.name)))
+			packagePath := ""
+			if packageOutput != nil {
+				packagePath = packageOutput.PackagePath
+			}
+
+			res, err := t.run(ctx, "deploy", serviceConfig, packagePath, targetResource)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			sdr := NewServiceDeployResult("", t.host, res.Details, res.Endpoints)
+			sdr.Package = packageOutput
+
+			task.SetResult(sdr)
+		},
+	)
+}
+
+func (t *pluginServiceTarget) Endpoints(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+) ([]string, error) {
+	res, err := t.run(ctx, "endpoints", serviceConfig, "", targetResource)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Endpoints, nil
+}
+
+// requestEnvironment returns the variables of the azd environment sent to the plugin.
+func (t *pluginServiceTarget) requestEnvironment() map[string]string {
+	keys := append(slices.Clone(pluginEnvironmentVariables), t.environment...)
+
+	dotenv := t.env.Dotenv()
+	values := map[string]string{}
+	for _, key := range keys {
+		if value, has := dotenv[key]; has {
+			values[key] = value
+		}
+	}
+
+	return values
+}
+
+// run runs the operation of the plugin executable, writing the request to its standard input and reading the response
+// from its standard output.
+func (t *pluginServiceTarget) run(
+	ctx context.Context,
+	operation string,
+	serviceConfig *ServiceConfig,
+	packagePath string,
+	targetResource *environment.TargetResource,
+) (*PluginResponse, error) {
+	request := PluginRequest{
+		Service: PluginService{
+			Name:       serviceConfig.Name,
+			Host:       string(serviceConfig.Host),
+			Language:   string(serviceConfig.Language),
+			Path:       serviceConfig.Path(),
+			OutputPath: serviceConfig.OutputPath,
+			Image:      serviceConfig.Image,
+		},
+		Environment: t.requestEnvironment(),
+		PackagePath: packagePath,
+		Config:      serviceConfig.Config,
+	}
+
+	if targetResource != nil {
+		request.TargetResource = &PluginTargetResource{
+			SubscriptionId:    targetResource.SubscriptionId(),
+			ResourceGroupName: targetResource.ResourceGroupName(),
+			ResourceName:      targetResource.ResourceName(),
+			ResourceType:      targetResource.ResourceType(),
+		}
+	}
+
+	requestBytes, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	runArgs := exec.NewRunArgs(t.executable, operation).
+		WithCwd(serviceConfig.Path()).
+		WithStdIn(bytes.NewReader(requestBytes))
+
+	result, err := t.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return nil, fmt.Errorf("plugin '%s' failed to %s service '%s': %w", t.name, operation, serviceConfig.Name, err)
+	}
+
+	response := &PluginResponse{}
+	if stdout := strings.TrimSpace(result.Stdout); stdout != "" {
+		if err := json.Unmarshal([]byte(stdout), response); err != nil {
+			return nil, fmt.Errorf("parsing response of plugin '%s': %w", t.name, err)
+		}
+	}
+
+	return response, nil
+}
@@ -0,0 +1,284 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/alpha"
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeServiceTargetProvider struct {
+	name  string
+	hosts []ServiceTargetKind
+}
+
+func (p *fakeServiceTargetProvider) Name() string {
+	return p.name
+}
+
+func (p *fakeServiceTargetProvider) Hosts() []ServiceTargetKind {
+	return p.hosts
+}
+
+func (p *fakeServiceTargetProvider) ServiceTarget(
+	host ServiceTargetKind,
+	env *environment.Environment,
+) (ServiceTarget, error) {
+	return &fakePluginTarget{host: host}, nil
+}
+
+type fakePluginTarget struct {
+	host ServiceTargetKind
+}
+
+func (t *fakePluginTarget) Initialize(ctx context.Context, serviceConfig *ServiceConfig) error {
+	return nil
+}
+
+func (t *fakePluginTarget) RequiredExternalTools(ctx context.Context) []tools.ExternalTool {
+	return nil
+}
+
+func (t *fakePluginTarget) Package(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	packageOutput *ServicePackageResult,
+) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
+	return nil
+}
+
+func (t *fakePluginTarget) Deploy(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	packageOutput *ServicePackageResult,
+	targetResource *environment.TargetResource,
+) *async.TaskWithProgress[*ServiceDeployResult, ServiceProgress] {
+	return nil
+}
+
+func (t *fakePluginTarget) Endpoints(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+) ([]string, error) {
+	return nil, nil
+}
+
+func Test_ServiceTargetRegistry_Register(t *testing.T) {
+	registry := NewServiceTargetRegistry()
+	require.NoError(t, registry.Register(&fakeServiceTargetProvider{name: "paas", hosts: []ServiceTargetKind{"my-paas"}}))
+
+	provider, has := registry.Provider("my-paas")
+	require.True(t, has)
+	require.Equal(t, "paas", provider.Name())
+
+	_, has = registry.Provider("other-paas")
+	require.False(t, has)
+
+	t.Run("DuplicateHost", func(t *testing.T) {
+		err := registry.Register(&fakeServiceTargetProvider{name: "other", hosts: []ServiceTargetKind{"my-paas"}})
+		require.ErrorContains(t, err, "already registered by plugin 'paas'")
+	})
+
+	t.Run("BuiltInHost", func(t *testing.T) {
+		err := registry.Register(&fakeServiceTargetProvider{name: "other", hosts: []ServiceTargetKind{AppServiceTarget}})
+		require.ErrorContains(t, err, "built-in host")
+	})
+
+	t.Run("InvalidHost", func(t *testing.T) {
+		err := registry.Register(&fakeServiceTargetProvider{name: "other", hosts: []ServiceTargetKind{"My PaaS"}})
+		require.ErrorContains(t, err, "invalid host")
+	})
+
+	t.Run("NoHosts", func(t *testing.T) {
+		err := registry.Register(&fakeServiceTargetProvider{name: "other"})
+		require.Error(t, err)
+	})
+
+	require.Equal(t, []ServiceTargetKind{"my-paas"}, registry.Hosts())
+}
+
+func Test_ServiceManager_GetServiceTarget_Plugin(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	setupMocksForServiceManager(mockContext)
+	env := environment.New("test")
+
+	registry := NewServiceTargetRegistry()
+	require.NoError(t, registry.Register(&fakeServiceTargetProvider{name: "paas", hosts: []ServiceTargetKind{"my-paas"}}))
+
+	azCli := mockazcli.NewAzCliFromMockContext(mockContext)
+	depOpService := mockazcli.NewDeploymentOperationsServiceFromMockContext(mockContext)
+	sm := NewServiceManager(
+		env,
+		NewResourceManager(env, azCli, depOpService),
+		mockContext.Container,
+		ServiceOperationCache{},
+		alpha.NewFeaturesManagerWithConfig(config.NewEmptyConfig()),
+		registry,
+		mockContext.CommandRunner,
+		mockContext.Console,
+	)
+
+	t.Run("RegisteredHost", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig("./src/api", "my-paas", ServiceLanguageFake)
+		serviceTarget, err := sm.GetServiceTarget(*mockContext.Context, serviceConfig)
+		require.NoError(t, err)
+		require.Equal(t, &fakePluginTarget{host: "my-paas"}, serviceTarget)
+	})
+
+	t.Run("UnknownHost", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig("./src/api", "other-paas", ServiceLanguageFake)
+		_, err := sm.GetServiceTarget(*mockContext.Context, serviceConfig)
+		require.ErrorContains(t, err, "unsupported host 'other-paas'")
+	})
+
+	t.Run("BuiltInHost", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig("./src/api", ServiceTargetFake, ServiceLanguageFake)
+		serviceTarget, err := sm.GetServiceTarget(*mockContext.Context, serviceConfig)
+		require.NoError(t, err)
+		require.IsType(t, &fakeServiceTarget{}, serviceTarget)
+	})
+}
+
+func Test_ParseServiceHost_Plugin(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("AZD_CONFIG_DIR", configDir)
+
+	pluginDir := filepath.Join(configDir, PluginsDirectoryName, "my-paas")
+	require.NoError(t, os.MkdirAll(pluginDir, osutil.PermissionDirectory))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(pluginDir, PluginManifestFileName),
+		[]byte(`{"name": "my-paas", "hosts": ["my-paas"], "executable": "azd-my-paas"}`),
+		osutil.PermissionFile))
+
+	host, err := parseServiceHost("my-paas")
+	require.NoError(t, err)
+	require.Equal(t, ServiceTargetKind("my-paas"), host)
+
+	// Hosts which aren't provided by an installed plugin are rejected when parsing the project
+	for _, invalid := range []ServiceTargetKind{"", "My PaaS", "other-paas", DotNetContainerAppTarget} {
+		_, err := parseServiceHost(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func Test_DiscoverPlugins(t *testing.T) {
+	dir := t.TempDir()
+	writeManifest := func(name string, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, name), osutil.PermissionDirectory))
+		require.NoError(t, os.WriteFile(
+			filepath.Join(dir, name, PluginManifestFileName), []byte(content), osutil.PermissionFile))
+	}
+
+	writeManifest("noop", `{"name": "noop", "hosts": ["noop"], "executable": "azd-noop"}`)
+	writeManifest("broken", `{"name": `)
+	writeManifest("incomplete", `{"name": "incomplete", "hosts": ["incomplete"]}`)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "no-manifest"), osutil.PermissionDirectory))
+
+	mockContext := mocks.NewMockContext(context.Background())
+	providers, err := DiscoverPlugins(dir, mockContext.CommandRunner)
+	require.ErrorContains(t, err, "parsing plugin manifest")
+	require.ErrorContains(t, err, "must specify a name and an executable")
+	require.Len(t, providers, 1)
+	require.Equal(t, "noop", providers[0].Name())
+	require.Equal(t, []ServiceTargetKind{"noop"}, providers[0].Hosts())
+
+	t.Run("LoadPlugins", func(t *testing.T) {
+		registry := NewServiceTargetRegistry()
+		registry.LoadPlugins(dir, mockContext.CommandRunner)
+		require.Equal(t, []ServiceTargetKind{"noop"}, registry.Hosts())
+	})
+
+	t.Run("MissingDirectory", func(t *testing.T) {
+		providers, err := DiscoverPlugins(filepath.Join(dir, "missing"), mockContext.CommandRunner)
+		require.NoError(t, err)
+		require.Empty(t, providers)
+	})
+}
+
+func Test_PluginServiceTarget_Deploy(t *testing.T) {
+	dir := t.TempDir()
+	mockContext := mocks.NewMockContext(context.Background())
+
+	var request PluginRequest
+	var runArgs exec.RunArgs
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "azd-noop deploy")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		runArgs = args
+		requestBytes, err := io.ReadAll(args.StdIn)
+		if err != nil {
+			return exec.RunResult{}, err
+		}
+
+		if err := json.Unmarshal(requestBytes, &request); err != nil {
+			return exec.RunResult{}, err
+		}
+
+		return exec.NewRunResult(0, `{"details": "deployed", "endpoints": ["https://api.contoso.com/"]}`, ""), nil
+	})
+
+	provider := &pluginProvider{
+		manifest: PluginManifest{
+			Name:        "noop",
+			Hosts:       []ServiceTargetKind{"noop"},
+			Executable:  "azd-noop",
+			Environment: []string{"API_URL"},
+		},
+		executable:    filepath.Join(dir, "noop", "azd-noop"),
+		commandRunner: mockContext.CommandRunner,
+	}
+
+	env := environment.NewWithValues("dev", map[string]string{
+		environment.EnvNameEnvVarName:        "dev",
+		environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+		"API_URL":                            "https://api.contoso.com",
+		"DB_PASSWORD":                        "secret",
+	})
+	serviceTarget, err := provider.ServiceTarget("noop", env)
+	require.NoError(t, err)
+
+	serviceConfig := createTestServiceConfig("./src/api", "noop", ServiceLanguageJavaScript)
+	serviceConfig.Config = map[string]any{"region": "west"}
+
+	deployTask := serviceTarget.Deploy(
+		*mockContext.Context, serviceConfig, &ServicePackageResult{PackagePath: "api.zip"}, nil)
+	logProgress(deployTask)
+	result, err := deployTask.Await()
+	require.NoError(t, err)
+
+	require.Equal(t, ServiceTargetKind("noop"), result.Kind)
+	require.Equal(t, "deployed", result.Details)
+	require.Equal(t, []string{"https://api.contoso.com/"}, result.Endpoints)
+
+	require.Equal(t, []string{"deploy"}, runArgs.Args)
+	require.Equal(t, serviceConfig.Path(), runArgs.Cwd)
+	require.Equal(t, "api", request.Service.Name)
+	require.Equal(t, "noop", request.Service.Host)
+	require.Equal(t, "api.zip", request.PackagePath)
+	// Only the variables azd sends to every plugin and the ones declared by the manifest are sent
+	require.Equal(t, map[string]string{
+		environment.EnvNameEnvVarName:        "dev",
+		environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+		"API_URL":                            "https://api.contoso.com",
+	}, request.Environment)
+	require.Equal(t, map[string]any{"region": "west"}, request.Config)
+	require.Nil(t, request.TargetResource)
+}
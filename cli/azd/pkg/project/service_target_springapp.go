@@ -7,15 +7,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/async"
 	"github.com/azure/azure-dev/cli/azd/pkg/azure"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"gopkg.in/yaml.v3"
 )
 
 const (
@@ -26,6 +30,73 @@ const (
 type SpringOptions struct {
 	// The deployment name of ASA app
 	DeploymentName string `yaml:"deploymentName"`
+	// The JVM options of the deployment, like '-Xms1024m -Xmx2048m'
+	JvmOptions osutil.ExpandableString `yaml:"jvmOptions,omitempty"`
+	// The environment variables of the deployment
+	Env map[string]SpringEnvValue `yaml:"env,omitempty"`
+	// The Azure service instances to bind to the ASA app
+	Bindings []SpringBinding `yaml:"bindings,omitempty"`
+}
+
+// SpringEnvValue is the value of an environment variable of the ASA app. It's either a string, or a mapping marking
+// the value as a secret, which azd redacts from its logs:
+//
+//	DB_PASSWORD:
+//	  value: ${DB_PASSWORD}
+//	  secret: true
+type SpringEnvValue struct {
+	osutil.ExpandableString
+	// Whether the value is a secret, redacted from the logs
+	Secret bool
+}
+
+// NewSpringEnvValue creates the value of an environment variable from a template, like '${AZURE_ENV_NAME}'.
+func NewSpringEnvValue(template string) SpringEnvValue {
+	return SpringEnvValue{ExpandableString: osutil.NewExpandableString(template)}
+}
+
+// NewSecretSpringEnvValue creates the value of a secret environment variable from a template.
+func NewSecretSpringEnvValue(template string) SpringEnvValue {
+	return SpringEnvValue{ExpandableString: osutil.NewExpandableString(template), Secret: true}
+}
+
+type springEnvValueMapping struct {
+	Value  osutil.ExpandableString `yaml:"value"`
+	Secret bool                    `yaml:"secret,omitempty"`
+}
+
+func (v SpringEnvValue) MarshalYAML() (interface{}, error) {
+	if !v.Secret {
+		return v.ExpandableString, nil
+	}
+
+	return springEnvValueMapping{Value: v.ExpandableString, Secret: true}, nil
+}
+
+func (v *SpringEnvValue) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		v.Secret = false
+		return value.Decode(&v.ExpandableString)
+	}
+
+	var mapping springEnvValueMapping
+	if err := value.Decode(&mapping); err != nil {
+		return err
+	}
+
+	v.ExpandableString = mapping.Value
+	v.Secret = mapping.Secret
+	return nil
+}
+
+// The binding of an Azure service instance, like a Cosmos DB account or a Redis cache, to the ASA app
+type SpringBinding struct {
+	// The name of the binding
+	Name string `yaml:"name"`
+	// The resource id of the service instance
+	ResourceId osutil.ExpandableString `yaml:"resourceId"`
+	// The key of the service instance, for services ASA can't retrieve the key of
+	Key osutil.ExpandableString `yaml:"key,omitempty"`
 }
 
 type springAppTarget struct {
@@ -90,20 +161,49 @@ func (st *springAppTarget) Deploy(
 				deploymentName = defaultDeploymentName
 			}
 
-			_, err := st.springService.GetSpringAppDeployment(
+			deploymentOptions, err := st.deploymentOptions(serviceConfig)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			bindings, err := st.bindings(serviceConfig)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			created, err := st.springService.CreateSpringAppIfNotExists(
 				ctx,
 				targetResource.SubscriptionId(),
 				targetResource.ResourceGroupName(),
 				targetResource.ResourceName(),
 				serviceConfig.Name,
-				deploymentName,
 			)
-
 			if err != nil {
-				task.SetError(fmt.Errorf("get deployment '%s' of Spring App '%s' failed: %w",
-					serviceConfig.Name, deploymentName, err))
+				task.SetError(fmt.Errorf("ensuring Spring App '%s' exists: %w", serviceConfig.Name, err))
 				return
 			}
+			if created {
+				log.Printf("created Spring App '%s'", serviceConfig.Name)
+			}
+
+			for _, binding := range bindings {
+				task.SetProgress(
+					NewServicePhaseProgress(ProgressPhaseCreatingDeployment, fmt.Sprintf("Binding service '%s'", binding.Name)))
+
+				if err := st.springService.BindSpringAppService(
+					ctx,
+					targetResource.SubscriptionId(),
+					targetResource.ResourceGroupName(),
+					targetResource.ResourceName(),
+					serviceConfig.Name,
+					binding,
+				); err != nil {
+					task.SetError(err)
+					return
+				}
+			}
 
 			// TODO: Consider support container image and buildpacks deployment in the future
 			// For now, Azure Spring Apps only support jar deployment
@@ -120,7 +220,7 @@ func (st *springAppTarget) Deploy(
 				return
 			}
 
-			task.SetProgress(NewServiceProgress("Uploading spring artifact"))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhaseUploading, "Uploading spring artifact"))
 
 			relativePath, err := st.springService.UploadSpringArtifact(
 				ctx,
@@ -136,7 +236,7 @@ func (st *springAppTarget) Deploy(
 				return
 			}
 
-			task.SetProgress(NewServiceProgress("Deploying spring artifact"))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhaseCreatingDeployment, "Deploying spring artifact"))
 
 			res, err := st.springService.DeploySpringAppArtifact(
 				ctx,
@@ -146,6 +246,7 @@ func (st *springAppTarget) Deploy(
 				serviceConfig.Name,
 				*relativePath,
 				deploymentName,
+				deploymentOptions,
 			)
 			if err != nil {
 				task.SetError(fmt.Errorf("deploying service %s: %w", serviceConfig.Name, err))
@@ -160,7 +261,7 @@ func (st *springAppTarget) Deploy(
 				return
 			}
 
-			task.SetProgress(NewServiceProgress("Fetching endpoints for spring app service"))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhaseFetchingEndpoints, "Fetching endpoints for spring app service"))
 			endpoints, err := st.Endpoints(ctx, serviceConfig, targetResource)
 			if err != nil {
 				task.SetError(err)
@@ -205,6 +306,81 @@ func (st *springAppTarget) Endpoints(
 	return springAppProperties.Url, nil
 }
 
+// deploymentOptions returns the settings of the deployment configured for the service, with environment references
+// expanded.
+func (st *springAppTarget) deploymentOptions(serviceConfig *ServiceConfig) (*azcli.SpringDeploymentOptions, error) {
+	jvmOptions, err := serviceConfig.Spring.JvmOptions.Envsubst(st.env.Getenv)
+	if err != nil {
+		return nil, fmt.Errorf("expanding JVM options: %w", err)
+	}
+
+	options := &azcli.SpringDeploymentOptions{
+		JvmOptions: jvmOptions,
+	}
+
+	if len(serviceConfig.Spring.Env) > 0 {
+		options.EnvironmentVariables = make(map[string]string, len(serviceConfig.Spring.Env))
+		for key, value := range serviceConfig.Spring.Env {
+			expanded, err := value.Envsubst(st.env.Getenv)
+			if err != nil {
+				return nil, fmt.Errorf("expanding environment variable '%s': %w", key, err)
+			}
+
+			if value.Secret {
+				exec.AddSensitiveData(expanded)
+			}
+
+			options.EnvironmentVariables[key] = expanded
+		}
+	}
+
+	outputEnv, err := serviceConfig.ResolveOutputEnv(st.env)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range outputEnv {
+		if options.EnvironmentVariables == nil {
+			options.EnvironmentVariables = make(map[string]string, len(outputEnv))
+		}
+
+		options.EnvironmentVariables[key] = value
+	}
+
+	return options, nil
+}
+
+// bindings returns the bindings configured for the service, with environment references expanded.
+func (st *springAppTarget) bindings(serviceConfig *ServiceConfig) ([]azcli.SpringAppBinding, error) {
+	bindings := make([]azcli.SpringAppBinding, 0, len(serviceConfig.Spring.Bindings))
+	for _, binding := range serviceConfig.Spring.Bindings {
+		if binding.Name == "" {
+			return nil, fmt.Errorf("a name is required for the bindings of service '%s'", serviceConfig.Name)
+		}
+
+		resourceId, err := binding.ResourceId.Envsubst(st.env.Getenv)
+		if err != nil {
+			return nil, fmt.Errorf("expanding resource id of binding '%s': %w", binding.Name, err)
+		}
+		if resourceId == "" {
+			return nil, fmt.Errorf("a resource id is required for binding '%s'", binding.Name)
+		}
+
+		key, err := binding.Key.Envsubst(st.env.Getenv)
+		if err != nil {
+			return nil, fmt.Errorf("expanding key of binding '%s': %w", binding.Name, err)
+		}
+
+		bindings = append(bindings, azcli.SpringAppBinding{
+			Name:       binding.Name,
+			ResourceId: resourceId,
+			Key:        key,
+		})
+	}
+
+	return bindings, nil
+}
+
 func (st *springAppTarget) validateTargetResource(
 	ctx context.Context,
 	serviceConfig *ServiceConfig,
@@ -9,9 +9,13 @@ import (
 	"testing"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 func TestNewSpringAppTargetTypeValidation(t *testing.T) {
@@ -57,3 +61,88 @@ func TestNewSpringAppTargetTypeValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestSpringAppTargetDeploymentSettings(t *testing.T) {
+	env := environment.NewWithValues("dev", map[string]string{
+		"AZURE_COSMOS_RESOURCE_ID":    "COSMOS_RESOURCE_ID",
+		"JAVA_HEAP":                   "2048m",
+		"SQL_CONN":                    "Server=sql.example.com",
+		environment.EnvNameEnvVarName: "dev",
+	})
+	serviceTarget := &springAppTarget{env: env}
+	serviceConfig := &ServiceConfig{
+		Name: "api",
+		Spring: SpringOptions{
+			JvmOptions: osutil.NewExpandableString("-Xmx${JAVA_HEAP}"),
+			Env: map[string]SpringEnvValue{
+				"SPRING_PROFILES_ACTIVE": NewSpringEnvValue("${AZURE_ENV_NAME}"),
+			},
+			Bindings: []SpringBinding{
+				{Name: "cosmos", ResourceId: osutil.NewExpandableString("${AZURE_COSMOS_RESOURCE_ID}")},
+			},
+		},
+		OutputEnv: map[string]string{"DATABASE_URL": "SQL_CONN"},
+	}
+
+	options, err := serviceTarget.deploymentOptions(serviceConfig)
+	require.NoError(t, err)
+	require.Equal(t, &azcli.SpringDeploymentOptions{
+		JvmOptions: "-Xmx2048m",
+		EnvironmentVariables: map[string]string{
+			"SPRING_PROFILES_ACTIVE": "dev",
+			"DATABASE_URL":           "Server=sql.example.com",
+		},
+	}, options)
+
+	bindings, err := serviceTarget.bindings(serviceConfig)
+	require.NoError(t, err)
+	require.Equal(t, []azcli.SpringAppBinding{{Name: "cosmos", ResourceId: "COSMOS_RESOURCE_ID"}}, bindings)
+
+	// Bindings to service instances that weren't provisioned fail before deploying
+	env.DotenvDelete("AZURE_COSMOS_RESOURCE_ID")
+	_, err = serviceTarget.bindings(serviceConfig)
+	require.ErrorContains(t, err, "a resource id is required for binding 'cosmos'")
+}
+
+func TestSpringAppTargetSecretEnv(t *testing.T) {
+	const testProj = `
+name: test-proj
+services:
+  api:
+    project: src/api
+    language: java
+    host: springapp
+    spring:
+      env:
+        SPRING_PROFILES_ACTIVE: ${AZURE_ENV_NAME}
+        DB_PASSWORD:
+          value: ${DB_PASSWORD}
+          secret: true
+`
+
+	projectConfig, err := Parse(context.Background(), testProj)
+	require.NoError(t, err)
+
+	serviceConfig := projectConfig.Services["api"]
+	require.Equal(t, NewSpringEnvValue("${AZURE_ENV_NAME}"), serviceConfig.Spring.Env["SPRING_PROFILES_ACTIVE"])
+	require.Equal(t, NewSecretSpringEnvValue("${DB_PASSWORD}"), serviceConfig.Spring.Env["DB_PASSWORD"])
+
+	env := environment.NewWithValues("dev", map[string]string{
+		"DB_PASSWORD":                 "springSecretP@ss",
+		environment.EnvNameEnvVarName: "dev",
+	})
+	serviceTarget := &springAppTarget{env: env}
+
+	// The secret is set on the deployment, but redacted from the logs
+	options, err := serviceTarget.deploymentOptions(serviceConfig)
+	require.NoError(t, err)
+	require.Equal(t, "springSecretP@ss", options.EnvironmentVariables["DB_PASSWORD"])
+	require.Equal(t, "password: <redacted>", exec.RedactSensitiveData("password: springSecretP@ss"))
+	require.Equal(t, "profile: dev", exec.RedactSensitiveData("profile: dev"))
+
+	// The annotation is kept when the project is saved
+	contents, err := yaml.Marshal(serviceConfig.Spring.Env)
+	require.NoError(t, err)
+	require.Contains(t, string(contents), "DB_PASSWORD:\n    value: ${DB_PASSWORD}\n    secret: true\n")
+	require.Contains(t, string(contents), "SPRING_PROFILES_ACTIVE: ${AZURE_ENV_NAME}\n")
+}
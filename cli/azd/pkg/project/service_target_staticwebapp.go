@@ -88,7 +88,7 @@ func (at *staticWebAppTarget) Deploy(
 			}
 
 			// Get the static webapp deployment token
-			task.SetProgress(NewServiceProgress("Retrieving deployment token"))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhaseUploading, "Retrieving deployment token"))
 			deploymentToken, err := at.cli.GetStaticWebAppApiKey(
 				ctx,
 				targetResource.SubscriptionId(),
@@ -101,7 +101,7 @@ func (at *staticWebAppTarget) Deploy(
 			}
 
 			// SWA performs a zip & deploy of the specified output folder and deploys it to the configured environment
-			task.SetProgress(NewServiceProgress("Uploading deployment artifacts"))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhaseUploading, "Uploading deployment artifacts"))
 			res, err := at.swa.Deploy(ctx,
 				serviceConfig.Project.Path,
 				at.env.GetTenantId(),
@@ -120,13 +120,13 @@ func (at *staticWebAppTarget) Deploy(
 				return
 			}
 
-			task.SetProgress(NewServiceProgress("Verifying deployment"))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhaseVerifying, "Verifying deployment"))
 			if err := at.verifyDeployment(ctx, targetResource); err != nil {
 				task.SetError(err)
 				return
 			}
 
-			task.SetProgress(NewServiceProgress("Fetching endpoints for static web app"))
+			task.SetProgress(NewServicePhaseProgress(ProgressPhaseFetchingEndpoints, "Fetching endpoints for static web app"))
 			endpoints, err := at.Endpoints(ctx, serviceConfig, targetResource)
 			if err != nil {
 				task.SetError(err)
@@ -0,0 +1,50 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ServiceTargetKind_ValidatePackagePath(t *testing.T) {
+	dir := t.TempDir()
+	zipPath := filepath.Join(dir, "api.zip")
+	require.NoError(t, os.WriteFile(zipPath, []byte("zip"), osutil.PermissionFile))
+	jarPath := filepath.Join(dir, "api.jar")
+	require.NoError(t, os.WriteFile(jarPath, []byte("jar"), osutil.PermissionFile))
+
+	tests := []struct {
+		name        string
+		host        ServiceTargetKind
+		packagePath string
+		expectError bool
+	}{
+		{name: "AppServiceZip", host: AppServiceTarget, packagePath: zipPath},
+		{name: "FunctionZip", host: AzureFunctionTarget, packagePath: zipPath},
+		{name: "AppServiceNotZip", host: AppServiceTarget, packagePath: jarPath, expectError: true},
+		{name: "AppServiceDirectory", host: AppServiceTarget, packagePath: dir, expectError: true},
+		{name: "AppServiceMissing", host: AppServiceTarget, packagePath: filepath.Join(dir, "missing.zip"), expectError: true},
+		{name: "ContainerAppImage", host: ContainerAppTarget, packagePath: "contoso.azurecr.io/my-project/api:azd-deploy-0"},
+		{name: "AksLocalImage", host: AksTarget, packagePath: "my-project/api:azd-deploy-0"},
+		{name: "ContainerAppZip", host: ContainerAppTarget, packagePath: zipPath, expectError: true},
+		{name: "StaticWebAppDirectory", host: StaticWebAppTarget, packagePath: dir},
+		{name: "StaticWebAppMissing", host: StaticWebAppTarget, packagePath: filepath.Join(dir, "dist"), expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.host.ValidatePackagePath(tt.packagePath)
+			if tt.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"os"
 	"slices"
 	"strings"
 
@@ -16,6 +15,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/cloud"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 )
 
@@ -52,6 +52,20 @@ func NewDefaultPrompter(
 }
 
 func (p *DefaultPrompter) PromptSubscription(ctx context.Context, msg string) (subscriptionId string, err error) {
+	resolved, err := p.accountManager.ResolveDefaultSubscription(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// When the account only has access to a single subscription there is nothing to choose from.
+	if resolved.Source == account.DefaultSourceSingleSubscription {
+		p.console.Message(ctx, fmt.Sprintf(
+			"Using subscription %s, the only subscription available to your account.",
+			output.WithHighLightFormat(resolved.Value)))
+		p.setDefaultSubscription(ctx, resolved.Value)
+		return resolved.Value, nil
+	}
+
 	subscriptionOptions, defaultSubscription, err := p.getSubscriptionOptions(ctx)
 	if err != nil {
 		return "", err
@@ -82,13 +96,16 @@ func (p *DefaultPrompter) PromptSubscription(ctx context.Context, msg string) (s
 			len("(00000000-0000-0000-0000-000000000000)")+1 : len(subscriptionSelection)-1]
 	}
 
+	p.setDefaultSubscription(ctx, subscriptionId)
+	return subscriptionId, nil
+}
+
+func (p *DefaultPrompter) setDefaultSubscription(ctx context.Context, subscriptionId string) {
 	if !p.accountManager.HasDefaultSubscription() {
 		if _, err := p.accountManager.SetDefaultSubscription(ctx, subscriptionId); err != nil {
 			log.Printf("failed setting default subscription. %s\n", err.Error())
 		}
 	}
-
-	return subscriptionId, nil
 }
 
 func (p *DefaultPrompter) PromptLocation(
@@ -166,12 +183,13 @@ func (p *DefaultPrompter) getSubscriptionOptions(ctx context.Context) ([]string,
 		return nil, nil, fmt.Errorf("listing accounts: %w", err)
 	}
 
-	// The default value is based on AZURE_SUBSCRIPTION_ID, falling back to whatever default subscription in
-	// set in azd's config.
-	defaultSubscriptionId := os.Getenv(environment.SubscriptionIdEnvVarName)
-	if defaultSubscriptionId == "" {
-		defaultSubscriptionId = p.accountManager.GetDefaultSubscriptionID(ctx)
+	// The default value is based on AZURE_SUBSCRIPTION_ID, falling back to the default subscription set in azd's
+	// config and then the az CLI.
+	resolved, err := p.accountManager.ResolveDefaultSubscription(ctx)
+	if err != nil {
+		return nil, nil, err
 	}
+	defaultSubscriptionId := resolved.Value
 
 	var subscriptionOptions = make([]string, len(subscriptionInfos))
 	var defaultSubscription any
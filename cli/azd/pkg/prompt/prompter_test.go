@@ -5,11 +5,16 @@ package prompt
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/account"
 	"github.com/azure/azure-dev/cli/azd/pkg/cloud"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/azure/azure-dev/cli/azd/test/mocks/mockaccount"
 	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
@@ -92,3 +97,80 @@ func Test_getSubscriptionOptions(t *testing.T) {
 		require.EqualValues(t, " 1. DISPLAY DEFAULT (SUBSCRIPTION_DEFAULT)", defSub)
 	})
 }
+
+// scriptedPrompter answers the prompts with the scripted answers, like the prompter of an embedder would with the answers
+// of its UI, and records the prompts.
+type scriptedPrompter struct {
+	selections []string
+	prompts    []input.ConsoleOptions
+}
+
+func (p *scriptedPrompter) Select(ctx context.Context, options input.ConsoleOptions) (int, error) {
+	p.prompts = append(p.prompts, options)
+	if len(p.selections) == 0 {
+		return -1, errors.New("no scripted selection")
+	}
+
+	selection := p.selections[0]
+	p.selections = p.selections[1:]
+
+	for i, option := range options.Options {
+		if strings.Contains(option, selection) {
+			return i, nil
+		}
+	}
+
+	return -1, fmt.Errorf("no option matching '%s'", selection)
+}
+
+func (p *scriptedPrompter) Confirm(ctx context.Context, options input.ConsoleOptions) (bool, error) {
+	return false, errors.New("unexpected confirm prompt")
+}
+
+func (p *scriptedPrompter) MultiSelect(ctx context.Context, options input.ConsoleOptions) ([]string, error) {
+	return nil, errors.New("unexpected multi-select prompt")
+}
+
+func (p *scriptedPrompter) Input(ctx context.Context, options input.ConsoleOptions) (string, error) {
+	return "", errors.New("unexpected input prompt")
+}
+
+func (p *scriptedPrompter) Password(ctx context.Context, options input.ConsoleOptions) (string, error) {
+	return "", errors.New("unexpected password prompt")
+}
+
+func Test_PromptSubscription_WithPrompter(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockAccount := &mockaccount.MockAccountManager{
+		Subscriptions: []account.Subscription{
+			{Id: "00000000-0000-0000-0000-000000000001", Name: "Development", TenantId: "TENANT"},
+			{Id: "00000000-0000-0000-0000-000000000002", Name: "Production", TenantId: "TENANT"},
+		},
+	}
+
+	scripted := &scriptedPrompter{selections: []string{"Production"}}
+	console := input.NewConsoleWithPrompter(
+		false,
+		false,
+		input.Writers{Output: io.Discard},
+		input.ConsoleHandles{Stdout: io.Discard, Stderr: io.Discard},
+		nil,
+		scripted)
+
+	prompter := NewDefaultPrompter(
+		environment.New("test"),
+		console,
+		mockAccount,
+		mockazcli.NewAzCliFromMockContext(mockContext),
+		cloud.AzurePublic().PortalUrlBase,
+	)
+
+	subscriptionId, err := prompter.PromptSubscription(*mockContext.Context, "Select an Azure Subscription to use:")
+	require.NoError(t, err)
+	require.Equal(t, "00000000-0000-0000-0000-000000000002", subscriptionId)
+	require.Equal(t, "00000000-0000-0000-0000-000000000002", mockAccount.DefaultSubscription)
+
+	require.Len(t, scripted.prompts, 1)
+	require.Equal(t, "Select an Azure Subscription to use:", scripted.prompts[0].Message)
+	require.Len(t, scripted.prompts[0].Options, 2)
+}
@@ -5,6 +5,7 @@ package rzip
 
 import (
 	"archive/zip"
+	"fmt"
 	"io"
 	"io/fs"
 	"os"
@@ -56,3 +57,66 @@ func CreateFromDirectory(source string, buf *os.File) error {
 
 	return w.Close()
 }
+
+// CountEntries returns the number of entries CreateFromDirectory writes to the archive of the source directory.
+func CountEntries(source string) (int, error) {
+	count := 0
+	err := filepath.WalkDir(source, func(path string, info fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// Verify checks the zip archive at path can be opened, isn't empty, and that the contents of all its entries match
+// their checksums. When expectedEntries isn't negative, the archive must also have exactly that number of entries.
+func Verify(path string, expectedEntries int) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("reading zip archive %s: %w", path, err)
+	}
+
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("opening zip archive %s (%d bytes): %w", path, info.Size(), err)
+	}
+	defer r.Close()
+
+	if len(r.File) == 0 {
+		return fmt.Errorf("zip archive %s (%d bytes) has no entries", path, info.Size())
+	}
+
+	if expectedEntries >= 0 && len(r.File) != expectedEntries {
+		return fmt.Errorf("zip archive %s has %d entries, expected %d", path, len(r.File), expectedEntries)
+	}
+
+	for _, file := range r.File {
+		if err := verifyEntry(file); err != nil {
+			return fmt.Errorf("reading entry %s of zip archive %s: %w", file.Name, path, err)
+		}
+	}
+
+	return nil
+}
+
+// verifyEntry reads the contents of the entry, which fails when they don't match the checksum of the entry.
+func verifyEntry(file *zip.File) error {
+	rc, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	_, err = io.Copy(io.Discard, rc)
+	return err
+}
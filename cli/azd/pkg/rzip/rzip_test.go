@@ -0,0 +1,93 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package rzip
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestZip(t *testing.T) (string, int) {
+	source := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(source, "static"), osutil.PermissionDirectory))
+	files := map[string]string{
+		"app.py":            "print('hello')",
+		"requirements.txt":  "flask",
+		"static/index.html": "<html></html>",
+	}
+	for name, contents := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(source, name), []byte(contents), osutil.PermissionFile))
+	}
+
+	zipPath := filepath.Join(t.TempDir(), "app.zip")
+	zipFile, err := os.Create(zipPath)
+	require.NoError(t, err)
+	require.NoError(t, CreateFromDirectory(source, zipFile))
+	require.NoError(t, zipFile.Close())
+
+	count, err := CountEntries(source)
+	require.NoError(t, err)
+	require.Equal(t, len(files), count)
+
+	return zipPath, count
+}
+
+func Test_Verify(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		zipPath, count := createTestZip(t)
+		require.NoError(t, Verify(zipPath, count))
+		require.NoError(t, Verify(zipPath, -1))
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		zipPath, count := createTestZip(t)
+		info, err := os.Stat(zipPath)
+		require.NoError(t, err)
+		require.NoError(t, os.Truncate(zipPath, info.Size()/2))
+
+		err = Verify(zipPath, count)
+		require.ErrorContains(t, err, "opening zip archive "+zipPath)
+	})
+
+	t.Run("Corrupt", func(t *testing.T) {
+		zipPath, count := createTestZip(t)
+		r, err := zip.OpenReader(zipPath)
+		require.NoError(t, err)
+		require.Equal(t, "app.py", r.File[0].Name)
+		dataOffset, err := r.File[0].DataOffset()
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+
+		// Flip the compressed data of the first entry, leaving the structure of the archive intact
+		contents, err := os.ReadFile(zipPath)
+		require.NoError(t, err)
+		contents[dataOffset] ^= 0xff
+		require.NoError(t, os.WriteFile(zipPath, contents, osutil.PermissionFile))
+
+		err = Verify(zipPath, count)
+		require.ErrorContains(t, err, "reading entry app.py of zip archive "+zipPath)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		zipPath := filepath.Join(t.TempDir(), "empty.zip")
+		zipFile, err := os.Create(zipPath)
+		require.NoError(t, err)
+		require.NoError(t, CreateFromDirectory(t.TempDir(), zipFile))
+		require.NoError(t, zipFile.Close())
+
+		err = Verify(zipPath, -1)
+		require.ErrorContains(t, err, "has no entries")
+	})
+
+	t.Run("UnexpectedEntryCount", func(t *testing.T) {
+		zipPath, count := createTestZip(t)
+		err := Verify(zipPath, count+1)
+		require.EqualError(t, err, "zip archive "+zipPath+" has 3 entries, expected 4")
+	})
+}
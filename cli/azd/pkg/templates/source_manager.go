@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/azure/azure-dev/cli/azd/internal/runcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/config"
 	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
 	"github.com/azure/azure-dev/cli/azd/pkg/ioc"
@@ -37,6 +38,7 @@ var (
 	ErrSourceNotFound    = errors.New("template source not found")
 	ErrSourceExists      = errors.New("template source already exists")
 	ErrSourceTypeInvalid = errors.New("invalid template source type")
+	ErrSourceOffline     = errors.New("template source is not available in offline mode")
 )
 
 // SourceOptions defines options for the SourceManager.
@@ -219,6 +221,11 @@ func (sm *sourceManager) CreateSource(ctx context.Context, config *SourceConfig)
 	var source Source
 	var err error
 
+	// The template gallery and the other remote sources are skipped in offline mode
+	if runcontext.IsOffline() && (config.Type == SourceKindUrl || config.Type == SourceKindAwesomeAzd) {
+		return nil, fmt.Errorf("unable to create template source '%s': %w", config.Key, ErrSourceOffline)
+	}
+
 	switch config.Type {
 	case SourceKindFile:
 		source, err = NewFileTemplateSource(config.Name, config.Location)
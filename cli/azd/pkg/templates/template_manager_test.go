@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/azure/azure-dev/cli/azd/internal/runcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/config"
 	"github.com/azure/azure-dev/cli/azd/resources"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
@@ -96,6 +97,42 @@ func Test_Templates_ListTemplates_SourceError(t *testing.T) {
 	require.Nil(t, err)
 }
 
+func Test_Templates_ListTemplates_Offline(t *testing.T) {
+	t.Setenv(runcontext.OfflineEnvVarName, "true")
+
+	mockContext := mocks.NewMockContext(context.Background())
+	galleryFetched := false
+	mockContext.HttpClient.When(func(req *http.Request) bool {
+		return req.Method == http.MethodGet
+	}).RespondFn(func(req *http.Request) (*http.Response, error) {
+		galleryFetched = true
+		return mocks.CreateHttpResponseWithBody(req, http.StatusOK, testAwesomeAzdTemplates)
+	})
+
+	configManager := &mockUserConfigManager{}
+	config := config.NewConfig(nil)
+	_ = config.Set(baseConfigKey, map[string]interface{}{
+		"default":     map[string]interface{}{},
+		"awesome-azd": map[string]interface{}{},
+	})
+	configManager.On("Load").Return(config, nil)
+
+	templateManager, err := NewTemplateManager(
+		NewSourceManager(NewSourceOptions(), mockContext.Container, configManager, mockContext.HttpClient),
+		mockContext.Console,
+	)
+	require.NoError(t, err)
+
+	// Only the templates embedded in azd are listed
+	templates, err := templateManager.ListTemplates(*mockContext.Context, nil)
+	require.NoError(t, err)
+	require.Greater(t, len(templates), 0)
+	require.False(t, galleryFetched)
+	for _, template := range templates {
+		require.Equal(t, SourceDefault.Name, template.Source)
+	}
+}
+
 func Test_Templates_GetTemplate_WithValidPath(t *testing.T) {
 	mockContext := mocks.NewMockContext(context.Background())
 	configManager := &mockUserConfigManager{}
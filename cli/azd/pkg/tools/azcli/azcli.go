@@ -62,12 +62,28 @@ type AzCli interface {
 		funcName string,
 		deployZipFile io.Reader,
 	) (*string, error)
+	// Deploys the zip file to a function app hosted on the Flex Consumption plan
+	DeployFlexFunctionAppUsingZipFile(
+		ctx context.Context,
+		subscriptionID string,
+		resourceGroup string,
+		funcName string,
+		deployZipFile io.Reader,
+		remoteBuild bool,
+	) (*string, error)
 	GetFunctionAppProperties(
 		ctx context.Context,
 		subscriptionID string,
 		resourceGroup string,
 		funcName string,
 	) (*AzCliFunctionAppProperties, error)
+	// Gets the SKU tier of the App Service plan hosting the function app, e.g. 'FlexConsumption' or 'Dynamic'
+	GetFunctionAppPlanTier(
+		ctx context.Context,
+		subscriptionID string,
+		resourceGroup string,
+		funcName string,
+	) (string, error)
 
 	DeleteResourceGroup(ctx context.Context, subscriptionId string, resourceGroupName string) error
 	CreateOrUpdateResourceGroup(
@@ -77,6 +93,13 @@ type AzCli interface {
 		location string,
 		tags map[string]*string,
 	) error
+	// MergeResourceGroupTags adds or updates the given tags on a resource group, keeping any other existing tags.
+	MergeResourceGroupTags(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		tags map[string]*string,
+	) error
 	ListResourceGroup(
 		ctx context.Context,
 		subscriptionId string,
@@ -88,6 +111,14 @@ type AzCli interface {
 		resourceGroupName string,
 		listOptions *ListResourceGroupResourcesOptions,
 	) ([]AzCliResource, error)
+	// ListResources lists the resources of a subscription matching a tag, across all of its resource groups.
+	ListResources(
+		ctx context.Context,
+		subscriptionId string,
+		listOptions *ListResourcesOptions,
+	) ([]AzCliResource, error)
+	// DeleteResource deletes a resource of the subscription by id, using the latest stable API version of its type.
+	DeleteResource(ctx context.Context, subscriptionId string, resourceId string) error
 	// CreateOrUpdateServicePrincipal creates a service principal using a given name and returns a JSON object which
 	// may be used by tools which understand the `AZURE_CREDENTIALS` format (i.e. the `sdk-auth` format). The service
 	// principal is assigned a given role. If an existing principal exists with the given name,
@@ -98,6 +129,46 @@ type AzCli interface {
 		resourceGroupName string,
 		applicationName string,
 	) (*AzCliAppServiceProperties, error)
+	// Creates the deployment slot of the web or function app when missing. Returns whether the slot was created.
+	EnsureAppServiceSlot(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		applicationName string,
+		slotName string,
+	) (bool, error)
+	DeployAppServiceSlotZip(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		applicationName string,
+		slotName string,
+		deployZipFile io.Reader,
+	) (*string, error)
+	GetAppServiceSlotProperties(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		applicationName string,
+		slotName string,
+	) (*AzCliAppServiceProperties, error)
+	// Merges the settings into the app settings of the web or function app, or of its deployment slot when set.
+	UpdateAppServiceAppSettings(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		applicationName string,
+		slotName string,
+		settings map[string]string,
+	) error
+	// Swaps the deployment slot of the web or function app with the production slot.
+	SwapAppServiceSlot(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		applicationName string,
+		slotName string,
+	) error
 	GetStaticWebAppProperties(
 		ctx context.Context,
 		subscriptionID string,
@@ -154,6 +225,12 @@ type ListResourceGroupResourcesOptions struct {
 	Filter *string
 }
 
+// Optional parameters for subscription resources listing.
+type ListResourcesOptions struct {
+	// The tag the resources must have, required
+	TagFilter *Filter
+}
+
 type Filter struct {
 	Key   string
 	Value string
@@ -2,8 +2,11 @@ package azcli
 
 import (
 	"context"
+	"fmt"
 	"io"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice"
 	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 )
 
@@ -51,3 +54,71 @@ func (cli *azCli) DeployFunctionAppUsingZipFile(
 
 	return convert.RefOf(response.StatusText), nil
 }
+
+func (cli *azCli) DeployFlexFunctionAppUsingZipFile(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroup string,
+	appName string,
+	deployZipFile io.Reader,
+	remoteBuild bool,
+) (*string, error) {
+	hostName, err := cli.appServiceRepositoryHost(ctx, subscriptionId, resourceGroup, appName)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := cli.createZipDeployClient(ctx, subscriptionId, hostName)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := client.FlexDeploy(ctx, deployZipFile, remoteBuild)
+	if err != nil {
+		return nil, err
+	}
+
+	return convert.RefOf(response.StatusText), nil
+}
+
+func (cli *azCli) GetFunctionAppPlanTier(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroup string,
+	appName string,
+) (string, error) {
+	webApp, err := cli.appService(ctx, subscriptionId, resourceGroup, appName)
+	if err != nil {
+		return "", err
+	}
+
+	if webApp.Properties == nil || webApp.Properties.ServerFarmID == nil {
+		return "", fmt.Errorf("function app '%s' is not associated with an app service plan", appName)
+	}
+
+	planId, err := arm.ParseResourceID(*webApp.Properties.ServerFarmID)
+	if err != nil {
+		return "", fmt.Errorf("parsing app service plan id: %w", err)
+	}
+
+	credential, err := cli.credentialProvider.CredentialForSubscription(ctx, planId.SubscriptionID)
+	if err != nil {
+		return "", err
+	}
+
+	client, err := armappservice.NewPlansClient(planId.SubscriptionID, credential, cli.armClientOptions)
+	if err != nil {
+		return "", fmt.Errorf("creating Plans client: %w", err)
+	}
+
+	plan, err := client.Get(ctx, planId.ResourceGroupName, planId.Name, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed retrieving app service plan: %w", err)
+	}
+
+	if plan.SKU == nil || plan.SKU.Tier == nil {
+		return "", nil
+	}
+
+	return *plan.SKU.Tier, nil
+}
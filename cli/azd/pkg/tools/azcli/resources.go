@@ -2,9 +2,14 @@ package azcli
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
 )
 
 func (cli *azCli) GetResource(
@@ -69,6 +74,134 @@ func (cli *azCli) ListResourceGroupResources(
 	return resources, nil
 }
 
+func (cli *azCli) ListResources(
+	ctx context.Context,
+	subscriptionId string,
+	listOptions *ListResourcesOptions,
+) ([]AzCliResource, error) {
+	client, err := cli.createResourcesClient(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Listing every resource of a subscription is never what a caller wants, a tag filter scopes the results
+	if listOptions == nil || listOptions.TagFilter == nil ||
+		listOptions.TagFilter.Key == "" || listOptions.TagFilter.Value == "" {
+		return nil, errors.New("listing the resources of a subscription requires a tag filter")
+	}
+
+	// https://learn.microsoft.com/en-us/rest/api/resources/resources/list#uri-parameters
+	tagFilter := fmt.Sprintf(
+		"tagName eq '%s' and tagValue eq '%s'",
+		listOptions.TagFilter.Key,
+		listOptions.TagFilter.Value,
+	)
+	options := armresources.ClientListOptions{Filter: &tagFilter}
+
+	resources := []AzCliResource{}
+	pager := client.NewListPager(&options)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, resource := range page.ResourceListResult.Value {
+			resources = append(resources, AzCliResource{
+				Id:       *resource.ID,
+				Name:     *resource.Name,
+				Type:     *resource.Type,
+				Location: *resource.Location,
+			})
+		}
+	}
+
+	return resources, nil
+}
+
+func (cli *azCli) DeleteResource(ctx context.Context, subscriptionId string, resourceId string) error {
+	parsedId, err := arm.ParseResourceID(resourceId)
+	if err != nil {
+		return fmt.Errorf("parsing resource id '%s': %w", resourceId, err)
+	}
+
+	if !strings.EqualFold(parsedId.SubscriptionID, subscriptionId) {
+		return fmt.Errorf("resource '%s' is not in subscription '%s'", resourceId, subscriptionId)
+	}
+
+	apiVersion, err := cli.resourceTypeApiVersion(ctx, subscriptionId, parsedId.ResourceType)
+	if err != nil {
+		return err
+	}
+
+	client, err := cli.createResourcesClient(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	poller, err := client.BeginDeleteByID(ctx, resourceId, apiVersion, nil)
+	if err != nil {
+		return fmt.Errorf("beginning resource deletion: %w", err)
+	}
+
+	_, err = poller.PollUntilDone(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("deleting resource: %w", err)
+	}
+
+	return nil
+}
+
+// resourceTypeApiVersion returns the latest stable API version of a resource type from the metadata of its resource
+// provider, or the latest preview version when the resource type has no stable version.
+func (cli *azCli) resourceTypeApiVersion(
+	ctx context.Context,
+	subscriptionId string,
+	resourceType arm.ResourceType,
+) (string, error) {
+	credential, err := cli.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return "", err
+	}
+
+	client, err := armresources.NewProvidersClient(subscriptionId, credential, cli.armClientOptions)
+	if err != nil {
+		return "", fmt.Errorf("creating providers client: %w", err)
+	}
+
+	provider, err := client.Get(ctx, resourceType.Namespace, nil)
+	if err != nil {
+		return "", fmt.Errorf("getting metadata of provider '%s': %w", resourceType.Namespace, err)
+	}
+
+	typeName := strings.Join(resourceType.Types, "/")
+	for _, providerType := range provider.ResourceTypes {
+		if providerType.ResourceType == nil || !strings.EqualFold(*providerType.ResourceType, typeName) {
+			continue
+		}
+
+		// API versions are listed from the most recent
+		var previewVersion string
+		for _, version := range providerType.APIVersions {
+			if version == nil {
+				continue
+			}
+			if !strings.HasSuffix(*version, "-preview") {
+				return *version, nil
+			}
+			if previewVersion == "" {
+				previewVersion = *version
+			}
+		}
+
+		if previewVersion != "" {
+			return previewVersion, nil
+		}
+	}
+
+	return "", fmt.Errorf("no API version found for resource type '%s'", resourceType.String())
+}
+
 func (cli *azCli) ListResourceGroup(
 	ctx context.Context,
 	subscriptionId string,
@@ -156,6 +289,34 @@ func (cli *azCli) DeleteResourceGroup(ctx context.Context, subscriptionId string
 	return nil
 }
 
+func (cli *azCli) MergeResourceGroupTags(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	tags map[string]*string,
+) error {
+	credential, err := cli.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	client, err := armresources.NewTagsClient(subscriptionId, credential, cli.armClientOptions)
+	if err != nil {
+		return fmt.Errorf("creating Tags client: %w", err)
+	}
+
+	scope := azure.ResourceGroupRID(subscriptionId, resourceGroupName)
+	_, err = client.UpdateAtScope(ctx, scope, armresources.TagsPatchResource{
+		Operation:  to.Ptr(armresources.TagsPatchOperationMerge),
+		Properties: &armresources.Tags{Tags: tags},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("updating resource group tags: %w", err)
+	}
+
+	return nil
+}
+
 func (cli *azCli) createResourcesClient(ctx context.Context, subscriptionId string) (*armresources.Client, error) {
 	credential, err := cli.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
 	if err != nil {
@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appplatform/armappplatform/v2"
@@ -33,7 +35,25 @@ type SpringService interface {
 		appName string,
 		relativePath string,
 		deploymentName string,
+		options *SpringDeploymentOptions,
 	) (*string, error)
+	// Create the ASA app when it doesn't exist, returning whether it was created
+	CreateSpringAppIfNotExists(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroup string,
+		instanceName string,
+		appName string,
+	) (bool, error)
+	// Bind an Azure service instance, like a Cosmos DB account, to an ASA app
+	BindSpringAppService(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroup string,
+		instanceName string,
+		appName string,
+		binding SpringAppBinding,
+	) error
 	// Upload jar artifact to ASA app Storage File
 	UploadSpringArtifact(
 		ctx context.Context,
@@ -74,6 +94,20 @@ type SpringAppProperties struct {
 	Url []string
 }
 
+// SpringDeploymentOptions are the settings of the ASA app deployment running the artifact
+type SpringDeploymentOptions struct {
+	JvmOptions           string
+	EnvironmentVariables map[string]string
+}
+
+// SpringAppBinding is the binding of an Azure service instance to an ASA app
+type SpringAppBinding struct {
+	Name       string
+	ResourceId string
+	// The key of the service instance, for services ASA can't retrieve the key of
+	Key string
+}
+
 func (ss *springService) GetSpringAppProperties(
 	ctx context.Context,
 	subscriptionId, resourceGroup, instanceName, appName string,
@@ -154,6 +188,7 @@ func (ss *springService) DeploySpringAppArtifact(
 	appName string,
 	relativePath string,
 	deploymentName string,
+	options *SpringDeploymentOptions,
 ) (*string, error) {
 	deploymentClient, err := ss.createSpringAppDeploymentClient(ctx, subscriptionId)
 	if err != nil {
@@ -166,7 +201,7 @@ func (ss *springService) DeploySpringAppArtifact(
 	}
 
 	_, err = ss.createOrUpdateDeployment(deploymentClient, ctx, resourceGroup, instanceName, appName,
-		deploymentName, relativePath)
+		deploymentName, relativePath, options)
 	if err != nil {
 		return nil, err
 	}
@@ -178,6 +213,83 @@ func (ss *springService) DeploySpringAppArtifact(
 	return resName, nil
 }
 
+func (ss *springService) CreateSpringAppIfNotExists(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroup string,
+	instanceName string,
+	appName string,
+) (bool, error) {
+	client, err := ss.createSpringAppClient(ctx, subscriptionId)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = client.Get(ctx, resourceGroup, instanceName, appName, nil)
+	if err == nil {
+		return false, nil
+	}
+
+	var respErr *azcore.ResponseError
+	if !errors.As(err, &respErr) || respErr.StatusCode != http.StatusNotFound {
+		return false, fmt.Errorf("failed retrieving spring app: %w", err)
+	}
+
+	poller, err := client.BeginCreateOrUpdate(ctx, resourceGroup, instanceName, appName,
+		armappplatform.AppResource{
+			Properties: &armappplatform.AppResourceProperties{},
+		}, nil)
+	if err != nil {
+		return false, fmt.Errorf("creating spring app: %w", err)
+	}
+
+	if _, err := poller.PollUntilDone(ctx, nil); err != nil {
+		return false, fmt.Errorf("creating spring app: %w", err)
+	}
+
+	return true, nil
+}
+
+func (ss *springService) BindSpringAppService(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroup string,
+	instanceName string,
+	appName string,
+	binding SpringAppBinding,
+) error {
+	credential, err := ss.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	client, err := armappplatform.NewBindingsClient(subscriptionId, credential, ss.armClientOptions)
+	if err != nil {
+		return fmt.Errorf("creating SpringAppBinding client: %w", err)
+	}
+
+	properties := &armappplatform.BindingResourceProperties{
+		ResourceID: to.Ptr(binding.ResourceId),
+	}
+	if binding.Key != "" {
+		properties.Key = to.Ptr(binding.Key)
+	}
+
+	poller, err := client.BeginCreateOrUpdate(ctx, resourceGroup, instanceName, appName, binding.Name,
+		armappplatform.BindingResource{
+			Properties: properties,
+		}, nil)
+	if err != nil {
+		return fmt.Errorf("binding service '%s': %w", binding.Name, err)
+	}
+
+	if _, err := poller.PollUntilDone(ctx, nil); err != nil {
+		return fmt.Errorf("binding service '%s': %w", binding.Name, err)
+	}
+
+	return nil
+}
+
 func (ss *springService) GetSpringAppDeployment(
 	ctx context.Context,
 	subscriptionId string,
@@ -242,15 +354,36 @@ func (ss *springService) createOrUpdateDeployment(
 	appName string,
 	deploymentName string,
 	relativePath string,
+	options *SpringDeploymentOptions,
 ) (*string, error) {
+	source := &armappplatform.JarUploadedUserSourceInfo{
+		Type:         to.Ptr("Jar"),
+		RelativePath: to.Ptr(relativePath),
+	}
+	properties := &armappplatform.DeploymentResourceProperties{
+		Source: source,
+	}
+
+	if options != nil {
+		if options.JvmOptions != "" {
+			source.JvmOptions = to.Ptr(options.JvmOptions)
+		}
+
+		if len(options.EnvironmentVariables) > 0 {
+			envVars := make(map[string]*string, len(options.EnvironmentVariables))
+			for key, value := range options.EnvironmentVariables {
+				envVars[key] = to.Ptr(value)
+			}
+
+			properties.DeploymentSettings = &armappplatform.DeploymentSettings{
+				EnvironmentVariables: envVars,
+			}
+		}
+	}
+
 	poller, err := deploymentClient.BeginCreateOrUpdate(ctx, resourceGroup, instanceName, appName, deploymentName,
 		armappplatform.DeploymentResource{
-			Properties: &armappplatform.DeploymentResourceProperties{
-				Source: &armappplatform.JarUploadedUserSourceInfo{
-					Type:         to.Ptr("Jar"),
-					RelativePath: to.Ptr(relativePath),
-				},
-			},
+			Properties: properties,
 		}, nil)
 	if err != nil {
 		return nil, err
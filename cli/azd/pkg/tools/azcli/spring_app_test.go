@@ -0,0 +1,165 @@
+package azcli
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appplatform/armappplatform/v2"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+const springAppPath = "/resourceGroups/RESOURCE_GROUP/providers/Microsoft.AppPlatform/Spring/INSTANCE/apps/APP"
+
+func newSpringServiceFromMockContext(mockContext *mocks.MockContext) SpringService {
+	return NewSpringService(mockContext.SubscriptionCredentialProvider, mockContext.ArmClientOptions)
+}
+
+// readRequestBody decodes the JSON body of a request into v.
+func readRequestBody(t *testing.T, request *http.Request, v any) {
+	body, err := io.ReadAll(request.Body)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(body, v))
+}
+
+func Test_CreateSpringAppIfNotExists(t *testing.T) {
+	t.Run("Missing", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		springService := newSpringServiceFromMockContext(mockContext)
+
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, springAppPath)
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return mocks.CreateEmptyHttpResponse(request, http.StatusNotFound)
+		})
+
+		var createRequested bool
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodPut && strings.HasSuffix(request.URL.Path, springAppPath)
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			createRequested = true
+			return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armappplatform.AppResource{
+				Name: to.Ptr("APP"),
+			})
+		})
+
+		created, err := springService.CreateSpringAppIfNotExists(
+			*mockContext.Context, "SUBSCRIPTION_ID", "RESOURCE_GROUP", "INSTANCE", "APP")
+		require.NoError(t, err)
+		require.True(t, created)
+		require.True(t, createRequested)
+	})
+
+	t.Run("Exists", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		springService := newSpringServiceFromMockContext(mockContext)
+
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, springAppPath)
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armappplatform.AppResource{
+				Name: to.Ptr("APP"),
+			})
+		})
+
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodPut
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			require.Fail(t, "the existing app must not be updated")
+			return nil, nil
+		})
+
+		created, err := springService.CreateSpringAppIfNotExists(
+			*mockContext.Context, "SUBSCRIPTION_ID", "RESOURCE_GROUP", "INSTANCE", "APP")
+		require.NoError(t, err)
+		require.False(t, created)
+	})
+}
+
+func Test_DeploySpringAppArtifact(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	springService := newSpringServiceFromMockContext(mockContext)
+
+	var deployment map[string]any
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut &&
+			strings.HasSuffix(request.URL.Path, springAppPath+"/deployments/default")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		readRequestBody(t, request, &deployment)
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armappplatform.DeploymentResource{
+			Name: to.Ptr("default"),
+		})
+	})
+
+	var activeDeployments armappplatform.ActiveDeploymentCollection
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost &&
+			strings.HasSuffix(request.URL.Path, springAppPath+"/setActiveDeployments")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		readRequestBody(t, request, &activeDeployments)
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armappplatform.AppResource{
+			Name: to.Ptr("APP"),
+		})
+	})
+
+	res, err := springService.DeploySpringAppArtifact(
+		*mockContext.Context,
+		"SUBSCRIPTION_ID",
+		"RESOURCE_GROUP",
+		"INSTANCE",
+		"APP",
+		"resources/APP-artifact.jar",
+		"default",
+		&SpringDeploymentOptions{
+			JvmOptions:           "-Xms1024m -Xmx2048m",
+			EnvironmentVariables: map[string]string{"SPRING_PROFILES_ACTIVE": "dev"},
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, "APP", *res)
+
+	// The deployment runs the uploaded artifact with the configured settings
+	require.Equal(t, map[string]any{
+		"properties": map[string]any{
+			"source": map[string]any{
+				"type":         "Jar",
+				"relativePath": "resources/APP-artifact.jar",
+				"jvmOptions":   "-Xms1024m -Xmx2048m",
+			},
+			"deploymentSettings": map[string]any{
+				"environmentVariables": map[string]any{"SPRING_PROFILES_ACTIVE": "dev"},
+			},
+		},
+	}, deployment)
+	require.Equal(t, []*string{to.Ptr("default")}, activeDeployments.ActiveDeploymentNames)
+}
+
+func Test_BindSpringAppService(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	springService := newSpringServiceFromMockContext(mockContext)
+
+	var binding armappplatform.BindingResource
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut && strings.HasSuffix(request.URL.Path, springAppPath+"/bindings/cosmos")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		readRequestBody(t, request, &binding)
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, binding)
+	})
+
+	err := springService.BindSpringAppService(
+		*mockContext.Context,
+		"SUBSCRIPTION_ID",
+		"RESOURCE_GROUP",
+		"INSTANCE",
+		"APP",
+		SpringAppBinding{Name: "cosmos", ResourceId: "COSMOS_RESOURCE_ID"},
+	)
+	require.NoError(t, err)
+	require.Equal(t, "COSMOS_RESOURCE_ID", *binding.Properties.ResourceID)
+	require.Nil(t, binding.Properties.Key)
+}
@@ -100,6 +100,56 @@ func (cli *azCli) DeployAppServiceZip(
 	return convert.RefOf(response.StatusText), nil
 }
 
+// UpdateAppServiceAppSettings merges the settings into the app settings of the web or function app, or of its
+// deployment slot when slotName is set. The other app settings are kept.
+func (cli *azCli) UpdateAppServiceAppSettings(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroup string,
+	appName string,
+	slotName string,
+	settings map[string]string,
+) error {
+	client, err := cli.createWebAppsClient(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	var appSettings armappservice.StringDictionary
+	if slotName == "" {
+		res, err := client.ListApplicationSettings(ctx, resourceGroup, appName, nil)
+		if err != nil {
+			return fmt.Errorf("failed retrieving app settings of webapp %s: %w", appName, err)
+		}
+		appSettings = res.StringDictionary
+	} else {
+		res, err := client.ListApplicationSettingsSlot(ctx, resourceGroup, appName, slotName, nil)
+		if err != nil {
+			return fmt.Errorf("failed retrieving app settings of slot '%s' of webapp %s: %w", slotName, appName, err)
+		}
+		appSettings = res.StringDictionary
+	}
+
+	if appSettings.Properties == nil {
+		appSettings.Properties = map[string]*string{}
+	}
+
+	for name, value := range settings {
+		appSettings.Properties[name] = convert.RefOf(value)
+	}
+
+	if slotName == "" {
+		_, err = client.UpdateApplicationSettings(ctx, resourceGroup, appName, appSettings, nil)
+	} else {
+		_, err = client.UpdateApplicationSettingsSlot(ctx, resourceGroup, appName, slotName, appSettings, nil)
+	}
+	if err != nil {
+		return fmt.Errorf("updating app settings of webapp %s: %w", appName, err)
+	}
+
+	return nil
+}
+
 func (cli *azCli) createWebAppsClient(ctx context.Context, subscriptionId string) (*armappservice.WebAppsClient, error) {
 	credential, err := cli.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
 	if err != nil {
@@ -0,0 +1,173 @@
+package azcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+)
+
+// The name of the production slot of a web app, used as the target of slot swaps.
+const productionSlotName = "production"
+
+// EnsureAppServiceSlot creates the deployment slot of the web or function app when it doesn't exist yet. Returns
+// whether the slot was created.
+func (cli *azCli) EnsureAppServiceSlot(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroup string,
+	appName string,
+	slotName string,
+) (bool, error) {
+	client, err := cli.createWebAppsClient(ctx, subscriptionId)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = client.GetSlot(ctx, resourceGroup, appName, slotName, nil)
+	if err == nil {
+		return false, nil
+	}
+
+	var responseError *azcore.ResponseError
+	if !errors.As(err, &responseError) || responseError.StatusCode != http.StatusNotFound {
+		return false, fmt.Errorf("failed retrieving slot '%s' of webapp %s: %w", slotName, appName, err)
+	}
+
+	app, err := cli.appService(ctx, subscriptionId, resourceGroup, appName)
+	if err != nil {
+		return false, err
+	}
+
+	// A slot is created in the App Service plan of the app, cloning the configuration and app settings of the
+	// production slot, so the slot runs on the same runtime.
+	poller, err := client.BeginCreateOrUpdateSlot(ctx, resourceGroup, appName, slotName, armappservice.Site{
+		Location: app.Location,
+		Kind:     app.Kind,
+		Properties: &armappservice.SiteProperties{
+			ServerFarmID: app.Properties.ServerFarmID,
+			CloningInfo: &armappservice.CloningInfo{
+				SourceWebAppID: app.ID,
+			},
+		},
+	}, nil)
+	if err != nil {
+		return false, fmt.Errorf("creating slot '%s' of webapp %s: %w", slotName, appName, err)
+	}
+
+	if _, err := poller.PollUntilDone(ctx, nil); err != nil {
+		return false, fmt.Errorf("creating slot '%s' of webapp %s: %w", slotName, appName, err)
+	}
+
+	return true, nil
+}
+
+// DeployAppServiceSlotZip deploys the zip file to a deployment slot of the web or function app.
+func (cli *azCli) DeployAppServiceSlotZip(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroup string,
+	appName string,
+	slotName string,
+	deployZipFile io.Reader,
+) (*string, error) {
+	slot, err := cli.appServiceSlot(ctx, subscriptionId, resourceGroup, appName, slotName)
+	if err != nil {
+		return nil, err
+	}
+
+	hostName := ""
+	for _, item := range slot.Properties.HostNameSSLStates {
+		if *item.HostType == armappservice.HostTypeRepository {
+			hostName = *item.Name
+			break
+		}
+	}
+
+	if hostName == "" {
+		return nil, fmt.Errorf("failed to find host name for slot '%s' of webapp %s", slotName, appName)
+	}
+
+	client, err := cli.createZipDeployClient(ctx, subscriptionId, hostName)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := client.Deploy(ctx, deployZipFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return convert.RefOf(response.StatusText), nil
+}
+
+// GetAppServiceSlotProperties gets the properties of a deployment slot of the web or function app.
+func (cli *azCli) GetAppServiceSlotProperties(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroup string,
+	appName string,
+	slotName string,
+) (*AzCliAppServiceProperties, error) {
+	slot, err := cli.appServiceSlot(ctx, subscriptionId, resourceGroup, appName, slotName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AzCliAppServiceProperties{
+		HostNames: []string{*slot.Properties.DefaultHostName},
+	}, nil
+}
+
+// SwapAppServiceSlot swaps the deployment slot of the web or function app with the production slot.
+func (cli *azCli) SwapAppServiceSlot(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroup string,
+	appName string,
+	slotName string,
+) error {
+	client, err := cli.createWebAppsClient(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	poller, err := client.BeginSwapSlot(ctx, resourceGroup, appName, slotName, armappservice.CsmSlotEntity{
+		TargetSlot:   convert.RefOf(productionSlotName),
+		PreserveVnet: convert.RefOf(true),
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("swapping slot '%s' of webapp %s: %w", slotName, appName, err)
+	}
+
+	if _, err := poller.PollUntilDone(ctx, nil); err != nil {
+		return fmt.Errorf("swapping slot '%s' of webapp %s: %w", slotName, appName, err)
+	}
+
+	return nil
+}
+
+func (cli *azCli) appServiceSlot(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroup string,
+	appName string,
+	slotName string,
+) (*armappservice.WebAppsClientGetSlotResponse, error) {
+	client, err := cli.createWebAppsClient(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	slot, err := client.GetSlot(ctx, resourceGroup, appName, slotName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving slot '%s' of webapp %s: %w", slotName, appName, err)
+	}
+
+	return &slot, nil
+}
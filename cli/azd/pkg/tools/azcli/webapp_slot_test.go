@@ -0,0 +1,71 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azcli
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_EnsureAppServiceSlot(t *testing.T) {
+	appId := "/subscriptions/subID/resourceGroups/resourceGroupID/providers/Microsoft.Web/sites/appName"
+
+	mockContext := mocks.NewMockContext(context.Background())
+	azCli := newAzCliFromMockContext(mockContext)
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet &&
+			strings.HasSuffix(request.URL.Path, "/providers/Microsoft.Web/sites/appName/slots/staging")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateEmptyHttpResponse(request, http.StatusNotFound)
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet &&
+			strings.HasSuffix(request.URL.Path, "/providers/Microsoft.Web/sites/appName")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armappservice.Site{
+			ID:       convert.RefOf(appId),
+			Location: convert.RefOf("eastus2"),
+			Kind:     convert.RefOf("functionapp,linux"),
+			Properties: &armappservice.SiteProperties{
+				ServerFarmID: convert.RefOf("/subscriptions/subID/resourceGroups/resourceGroupID/providers/" +
+					"Microsoft.Web/serverfarms/plan"),
+			},
+		})
+	})
+
+	var created armappservice.Site
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut &&
+			strings.HasSuffix(request.URL.Path, "/providers/Microsoft.Web/sites/appName/slots/staging")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		body, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, &created))
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, created)
+	})
+
+	wasCreated, err := azCli.EnsureAppServiceSlot(
+		*mockContext.Context, "subID", "resourceGroupID", "appName", "staging")
+	require.NoError(t, err)
+	require.True(t, wasCreated)
+
+	// The slot clones the production slot, and has the same kind
+	require.Equal(t, "functionapp,linux", *created.Kind)
+	require.Equal(t, "eastus2", *created.Location)
+	require.NotNil(t, created.Properties.CloningInfo)
+	require.Equal(t, appId, *created.Properties.CloningInfo.SourceWebAppID)
+	require.Contains(t, *created.Properties.ServerFarmID, "/serverfarms/plan")
+}
@@ -32,7 +32,10 @@ func (bs *bashScript) Execute(ctx context.Context, path string, options tools.Ex
 	path = strings.ReplaceAll(path, "\\", "/")
 
 	if runtime.GOOS == "windows" {
-		runArgs = exec.NewRunArgs("bash", path)
+		runArgs = exec.NewRunArgs("bash", append([]string{path}, options.Args...)...)
+	} else if len(options.Args) > 0 {
+		// The arguments are quoted by the shell running the script
+		runArgs = exec.NewRunArgs(path, options.Args...)
 	} else {
 		runArgs = exec.NewRunArgs("", path)
 	}
@@ -40,6 +43,7 @@ func (bs *bashScript) Execute(ctx context.Context, path string, options tools.Ex
 	runArgs = runArgs.
 		WithCwd(bs.cwd).
 		WithEnv(bs.envVars).
+		WithRestrictedEnv(true).
 		WithShell(true)
 
 	if options.Interactive != nil {
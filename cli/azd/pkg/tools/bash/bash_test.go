@@ -36,6 +36,7 @@ func Test_Bash_Execute(t *testing.T) {
 			require.Equal(t, workingDir, args.Cwd)
 			require.Equal(t, scriptPath, args.Args[0])
 			require.Equal(t, env, args.Env)
+			require.True(t, args.RestrictEnv)
 
 			return exec.NewRunResult(0, "", ""), nil
 		})
@@ -51,6 +52,33 @@ func Test_Bash_Execute(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("Args", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return true
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			if runtime.GOOS == "windows" {
+				require.Equal(t, "bash", args.Cmd)
+				require.Equal(t, []string{scriptPath, "--env", "dev env"}, args.Args)
+			} else {
+				// The script is run as the command, so the shell quotes its arguments
+				require.Equal(t, scriptPath, args.Cmd)
+				require.Equal(t, []string{"--env", "dev env"}, args.Args)
+			}
+
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+		bashScript := NewBashScript(mockContext.CommandRunner, workingDir, env)
+		_, err := bashScript.Execute(
+			*mockContext.Context,
+			scriptPath,
+			tools.ExecOptions{Args: []string{"--env", "dev env"}},
+		)
+		require.NoError(t, err)
+	})
+
 	t.Run("Error", func(t *testing.T) {
 		mockContext := mocks.NewMockContext(context.Background())
 
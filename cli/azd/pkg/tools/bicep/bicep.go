@@ -13,6 +13,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sync"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/azure/azure-dev/cli/azd/internal/tracing"
@@ -32,6 +33,8 @@ var BicepVersion semver.Version = semver.MustParse("0.25.3")
 type BicepCli interface {
 	Build(ctx context.Context, file string) (BuildResult, error)
 	BuildBicepParam(ctx context.Context, file string, env []string) (BuildResult, error)
+	// Version returns the version of the bicep CLI, which templates are compiled with.
+	Version(ctx context.Context) (semver.Version, error)
 }
 
 // NewBicepCli creates a new BicepCli. Azd manages its own copy of the bicep CLI, stored in `$AZD_CONFIG_DIR/bin`. If
@@ -106,8 +109,12 @@ func newBicepCliWithTransporter(
 		); err != nil {
 			return nil, fmt.Errorf("upgrading bicep: %w", err)
 		}
+
+		ver = BicepVersion
 	}
 
+	cli.ver = &ver
+
 	log.Printf("using local bicep: %s", bicepPath)
 
 	return cli, nil
@@ -130,6 +137,10 @@ func runStep(ctx context.Context, console input.Console, title string, action fu
 type bicepCli struct {
 	path   string
 	runner exec.CommandRunner
+
+	// The version of the CLI, once checked
+	ver   *semver.Version
+	verMu sync.Mutex
 }
 
 // azdBicepPath returns the path where we store our local copy of bicep ($AZD_CONFIG_DIR/bin).
@@ -260,6 +271,23 @@ func (cli *bicepCli) version(ctx context.Context) (semver.Version, error) {
 
 }
 
+func (cli *bicepCli) Version(ctx context.Context) (semver.Version, error) {
+	cli.verMu.Lock()
+	defer cli.verMu.Unlock()
+
+	if cli.ver != nil {
+		return *cli.ver, nil
+	}
+
+	ver, err := cli.version(ctx)
+	if err != nil {
+		return semver.Version{}, err
+	}
+
+	cli.ver = &ver
+	return ver, nil
+}
+
 type BuildResult struct {
 	// The compiled ARM template
 	Compiled string
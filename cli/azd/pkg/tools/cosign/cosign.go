@@ -0,0 +1,120 @@
+package cosign
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+)
+
+// Signs and attests container images with the sigstore cosign CLI
+type CosignCli interface {
+	tools.ExternalTool
+	// Signs the image, referenced by digest, and pushes the signature to its registry
+	Sign(ctx context.Context, cwd string, image string, options SignOptions) error
+	// Attests the predicate, like an SBOM, to the image referenced by digest and pushes the attestation to its registry
+	Attest(ctx context.Context, cwd string, image string, predicatePath string, predicateType string,
+		options SignOptions) error
+}
+
+// SignOptions are the signing identity used to sign images and attestations
+type SignOptions struct {
+	// The key reference, like a key file or a KMS URI. Images are signed keyless when empty.
+	Key string
+	// The OIDC identity token used for keyless signing. Cosign acquires one when empty.
+	IdentityToken string
+}
+
+type cosignCli struct {
+	commandRunner exec.CommandRunner
+}
+
+// Creates a new cosign CLI instance
+func NewCosignCli(commandRunner exec.CommandRunner) CosignCli {
+	return &cosignCli{
+		commandRunner: commandRunner,
+	}
+}
+
+// Checks whether or not the cosign CLI is installed and available within the PATH
+func (cli *cosignCli) CheckInstalled(ctx context.Context) error {
+	if err := tools.ToolInPath("cosign"); err != nil {
+		return err
+	}
+
+	// We don't have a minimum required version of cosign today, but for diagnostics purposes, let's log the version.
+	if res, err := tools.ExecuteCommand(ctx, cli.commandRunner, "cosign", "version"); err != nil {
+		log.Printf("error fetching cosign version: %s", err)
+	} else {
+		log.Printf("cosign version: %s", strings.TrimSpace(res))
+	}
+
+	return nil
+}
+
+func (cli *cosignCli) InstallUrl() string {
+	return "https://docs.sigstore.dev/system_config/installation"
+}
+
+func (cli *cosignCli) Name() string {
+	return "cosign"
+}
+
+func (cli *cosignCli) Sign(ctx context.Context, cwd string, image string, options SignOptions) error {
+	args := []string{"sign", "--yes"}
+	args = append(args, keyArgs(options)...)
+	args = append(args, image)
+
+	if _, err := cli.executeCommand(ctx, cwd, options, args...); err != nil {
+		return fmt.Errorf("signing image '%s': %w", image, err)
+	}
+
+	return nil
+}
+
+func (cli *cosignCli) Attest(
+	ctx context.Context,
+	cwd string,
+	image string,
+	predicatePath string,
+	predicateType string,
+	options SignOptions,
+) error {
+	args := []string{"attest", "--yes", "--type", predicateType, "--predicate", predicatePath}
+	args = append(args, keyArgs(options)...)
+	args = append(args, image)
+
+	if _, err := cli.executeCommand(ctx, cwd, options, args...); err != nil {
+		return fmt.Errorf("attesting '%s' to image '%s': %w", predicatePath, image, err)
+	}
+
+	return nil
+}
+
+func keyArgs(options SignOptions) []string {
+	if options.Key == "" {
+		return nil
+	}
+
+	return []string{"--key", options.Key}
+}
+
+func (cli *cosignCli) executeCommand(
+	ctx context.Context,
+	cwd string,
+	options SignOptions,
+	args ...string,
+) (exec.RunResult, error) {
+	runArgs := exec.NewRunArgs("cosign", args...).
+		WithCwd(cwd)
+
+	// The identity token is passed through the environment rather than the arguments, which are logged
+	if options.IdentityToken != "" {
+		runArgs = runArgs.WithEnv([]string{"SIGSTORE_ID_TOKEN=" + options.IdentityToken})
+	}
+
+	return cli.commandRunner.Run(ctx, runArgs)
+}
@@ -0,0 +1,74 @@
+package cosign
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+const testImage = "contoso.azurecr.io/todo/api@sha256:0123456789abcdef"
+
+func Test_Sign(t *testing.T) {
+	tests := map[string]struct {
+		options      SignOptions
+		expectedArgs []string
+		expectedEnv  []string
+	}{
+		"Key": {
+			options:      SignOptions{Key: "azurekms://contoso.vault.azure.net/signing"},
+			expectedArgs: []string{"sign", "--yes", "--key", "azurekms://contoso.vault.azure.net/signing", testImage},
+		},
+		"Keyless": {
+			options:      SignOptions{IdentityToken: "TOKEN"},
+			expectedArgs: []string{"sign", "--yes", testImage},
+			expectedEnv:  []string{"SIGSTORE_ID_TOKEN=TOKEN"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockContext := mocks.NewMockContext(context.Background())
+
+			var runArgs exec.RunArgs
+			mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+				return strings.Contains(command, "cosign sign")
+			}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+				runArgs = args
+				return exec.NewRunResult(0, "", ""), nil
+			})
+
+			cli := NewCosignCli(mockContext.CommandRunner)
+			err := cli.Sign(*mockContext.Context, "src/api", testImage, tt.options)
+			require.NoError(t, err)
+
+			require.Equal(t, "cosign", runArgs.Cmd)
+			require.Equal(t, "src/api", runArgs.Cwd)
+			require.Equal(t, tt.expectedArgs, runArgs.Args)
+			require.Equal(t, tt.expectedEnv, runArgs.Env)
+		})
+	}
+}
+
+func Test_Attest(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+
+	var runArgs exec.RunArgs
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "cosign attest")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		runArgs = args
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	cli := NewCosignCli(mockContext.CommandRunner)
+	err := cli.Attest(*mockContext.Context, "src/api", testImage, "sbom.spdx.json", "spdxjson", SignOptions{Key: "cosign.key"})
+	require.NoError(t, err)
+
+	require.Equal(t, []string{
+		"attest", "--yes", "--type", "spdxjson", "--predicate", "sbom.spdx.json", "--key", "cosign.key", testImage,
+	}, runArgs.Args)
+}
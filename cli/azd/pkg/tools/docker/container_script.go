@@ -0,0 +1,102 @@
+package docker
+
+import (
+	"context"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+)
+
+const (
+	// containerWorkspaceDir is where the working directory of the script is mounted in the container
+	containerWorkspaceDir = "/workspace"
+	// containerScriptsDir is where scripts located outside of the working directory are mounted in the container
+	containerScriptsDir = "/azd/scripts"
+)
+
+// Creates a new script runner, running scripts in a container of the image with the shell, like 'sh' or 'pwsh'.
+// The working directory is mounted in the container, and the environment variables are passed to it.
+func NewContainerScript(
+	commandRunner exec.CommandRunner,
+	image string,
+	shell string,
+	cwd string,
+	envVars []string,
+) tools.Script {
+	return &containerScript{
+		commandRunner: commandRunner,
+		image:         image,
+		shell:         shell,
+		cwd:           cwd,
+		envVars:       envVars,
+	}
+}
+
+type containerScript struct {
+	commandRunner exec.CommandRunner
+	image         string
+	shell         string
+	cwd           string
+	envVars       []string
+}
+
+// Executes the script in a new container, which is removed once the script completes. The exit code of the script is
+// the exit code of the container.
+// When interactive is true will attach to stdin, stdout & stderr
+func (cs *containerScript) Execute(
+	ctx context.Context,
+	scriptPath string,
+	options tools.ExecOptions,
+) (exec.RunResult, error) {
+	interactive := options.Interactive != nil && *options.Interactive
+
+	args := []string{"run", "--rm"}
+	if interactive {
+		args = append(args, "-it")
+	}
+
+	args = append(args,
+		"-v", cs.cwd+":"+containerWorkspaceDir,
+		"-w", containerWorkspaceDir,
+	)
+
+	// Scripts within the working directory are available from its mount, the others are mounted on their own
+	relativePath := scriptPath
+	if filepath.IsAbs(scriptPath) {
+		if rel, err := filepath.Rel(cs.cwd, scriptPath); err == nil && !strings.HasPrefix(rel, "..") {
+			relativePath = rel
+		}
+	}
+
+	var containerScriptPath string
+	if !filepath.IsAbs(relativePath) {
+		containerScriptPath = path.Join(containerWorkspaceDir, filepath.ToSlash(relativePath))
+	} else {
+		containerScriptPath = path.Join(containerScriptsDir, filepath.Base(scriptPath))
+		args = append(args, "-v", scriptPath+":"+containerScriptPath+":ro")
+	}
+
+	// Only the names of the variables are passed to docker, which reads their values from its own environment, so the
+	// values aren't visible in the command line
+	for _, envVar := range cs.envVars {
+		name, _, _ := strings.Cut(envVar, "=")
+		args = append(args, "-e", name)
+	}
+
+	args = append(args, cs.image, cs.shell, containerScriptPath)
+	args = append(args, options.Args...)
+
+	runArgs := exec.NewRunArgs("docker", args...).
+		WithCwd(cs.cwd).
+		WithEnv(cs.envVars).
+		WithInteractive(interactive)
+
+	if options.StdOut != nil {
+		runArgs = runArgs.WithStdOut(options.StdOut)
+	}
+
+	return cs.commandRunner.Run(ctx, runArgs)
+}
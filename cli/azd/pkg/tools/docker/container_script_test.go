@@ -0,0 +1,91 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ContainerScript_Execute(t *testing.T) {
+	cwd := t.TempDir()
+	envVars := []string{"AZURE_ENV_NAME=dev", "AZURE_LOCATION=eastus2"}
+
+	t.Run("ScriptInWorkingDirectory", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		ran := false
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "docker run")
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			ran = true
+
+			require.Equal(t, "docker", args.Cmd)
+			require.Equal(t, []string{
+				"run", "--rm",
+				"-v", cwd + ":/workspace",
+				"-w", "/workspace",
+				"-e", "AZURE_ENV_NAME",
+				"-e", "AZURE_LOCATION",
+				"mcr.microsoft.com/azure-cli:latest", "sh", "/workspace/scripts/predeploy.sh",
+			}, args.Args)
+			require.Equal(t, cwd, args.Cwd)
+			require.Equal(t, envVars, args.Env)
+			require.False(t, args.Interactive)
+
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+		script := NewContainerScript(mockContext.CommandRunner, "mcr.microsoft.com/azure-cli:latest", "sh", cwd, envVars)
+		_, err := script.Execute(*mockContext.Context, filepath.Join("scripts", "predeploy.sh"), tools.ExecOptions{})
+		require.NoError(t, err)
+		require.True(t, ran)
+	})
+
+	t.Run("ScriptOutsideWorkingDirectory", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		scriptPath := filepath.Join(t.TempDir(), "azd-predeploy-1234.ps1")
+		interactive := true
+		ran := false
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "docker run")
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			ran = true
+
+			require.Equal(t, []string{
+				"run", "--rm", "-it",
+				"-v", cwd + ":/workspace",
+				"-w", "/workspace",
+				"-v", scriptPath + ":/azd/scripts/azd-predeploy-1234.ps1:ro",
+				"mcr.microsoft.com/powershell", "pwsh", "/azd/scripts/azd-predeploy-1234.ps1",
+			}, args.Args)
+			require.True(t, args.Interactive)
+
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+		script := NewContainerScript(mockContext.CommandRunner, "mcr.microsoft.com/powershell", "pwsh", cwd, nil)
+		_, err := script.Execute(*mockContext.Context, scriptPath, tools.ExecOptions{Interactive: &interactive})
+		require.NoError(t, err)
+		require.True(t, ran)
+	})
+
+	t.Run("ExitCode", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "docker run")
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			return exec.NewRunResult(3, "", "failed"), errors.New("exit code: 3")
+		})
+
+		script := NewContainerScript(mockContext.CommandRunner, "alpine", "sh", cwd, envVars)
+		res, err := script.Execute(*mockContext.Context, "predeploy.sh", tools.ExecOptions{})
+		require.Error(t, err)
+		require.Equal(t, 3, res.ExitCode)
+	})
+}
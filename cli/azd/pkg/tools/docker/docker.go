@@ -10,10 +10,12 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/blang/semver/v4"
+	"github.com/sethvargo/go-retry"
 )
 
 const DefaultPlatform string = "linux/amd64"
@@ -33,7 +35,7 @@ type Docker interface {
 		buildProgress io.Writer,
 	) (string, error)
 	Tag(ctx context.Context, cwd string, imageName string, tag string) error
-	Push(ctx context.Context, cwd string, tag string) error
+	Push(ctx context.Context, cwd string, tag string, pushProgress func(PushProgress)) error
 	Pull(ctx context.Context, imageName string) error
 	Inspect(ctx context.Context, imageName string, format string) (string, error)
 }
@@ -146,8 +148,35 @@ func (d *docker) Tag(ctx context.Context, cwd string, imageName string, tag stri
 	return nil
 }
 
-func (d *docker) Push(ctx context.Context, cwd string, tag string) error {
-	_, err := d.executeCommand(ctx, cwd, "push", tag)
+// The number of times a push rate limited by the registry is retried, and the delay before the first retry, doubled
+// for each retry. Variables so tests can shorten the delay.
+var (
+	pushThrottledRetries uint64 = 3
+	pushThrottledDelay          = 5 * time.Second
+)
+
+// Push pushes the image of tag with docker push, which leaves how many layers are pushed concurrently to the docker
+// daemon. When pushProgress is set, it's called with the aggregated progress of the layers of the image as they're
+// pushed. Pushes rate limited by the registry are retried, backing off exponentially.
+func (d *docker) Push(ctx context.Context, cwd string, tag string, pushProgress func(PushProgress)) error {
+	err := retry.Do(
+		ctx,
+		retry.WithMaxRetries(pushThrottledRetries, retry.NewExponential(pushThrottledDelay)),
+		func(ctx context.Context) error {
+			runArgs := exec.NewRunArgs("docker", "push", tag).WithCwd(cwd)
+			if pushProgress != nil {
+				runArgs = runArgs.WithStdOut(newPushProgressWriter(pushProgress))
+			}
+
+			res, err := d.commandRunner.Run(ctx, runArgs)
+			if err != nil && isRateLimited(res) {
+				log.Printf("pushing %s was rate limited by the registry, retrying", tag)
+				return retry.RetryableError(err)
+			}
+
+			return err
+		},
+	)
 	if err != nil {
 		return fmt.Errorf("pushing image: %w", err)
 	}
@@ -155,6 +184,11 @@ func (d *docker) Push(ctx context.Context, cwd string, tag string) error {
 	return nil
 }
 
+// isRateLimited returns whether the registry rejected the push because of too many requests.
+func isRateLimited(res exec.RunResult) bool {
+	return strings.Contains(res.Stderr, "toomanyrequests") || strings.Contains(res.Stderr, "429 Too Many Requests")
+}
+
 func (d *docker) Pull(ctx context.Context, imageName string) error {
 	_, err := d.executeCommand(ctx, "", "pull", imageName)
 	if err != nil {
@@ -7,6 +7,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
@@ -391,7 +392,7 @@ func Test_DockerPush(t *testing.T) {
 			}, nil
 		})
 
-		err := docker.Push(context.Background(), cwd, tag)
+		err := docker.Push(context.Background(), cwd, tag, nil)
 
 		require.Equal(t, true, ran)
 		require.Nil(t, err)
@@ -424,7 +425,7 @@ func Test_DockerPush(t *testing.T) {
 			}, errors.New(customErrorMessage)
 		})
 
-		err := docker.Push(context.Background(), cwd, tag)
+		err := docker.Push(context.Background(), cwd, tag, nil)
 
 		require.Equal(t, true, ran)
 		require.NotNil(t, err)
@@ -434,6 +435,99 @@ func Test_DockerPush(t *testing.T) {
 			err.Error(),
 		)
 	})
+
+	t.Run("Progress", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		docker := NewDocker(mockContext.CommandRunner)
+
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "docker push")
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			// Layers push concurrently, so their statuses interleave
+			output := []string{
+				"The push refers to repository [contoso.azurecr.io/api]\n",
+				"5f70bf18a086: Preparing\n",
+				"a1b2c3d4e5f6: Preparing\n",
+				"0123456789ab: Prepar",
+				"ing\n",
+				"a1b2c3d4e5f6: Waiting\n",
+				"5f70bf18a086: Layer already exists\n",
+				"0123456789ab: Mounted from library/node\n",
+				"0123456789ab: Pushed\n",
+				"a1b2c3d4e5f6: Pushed\n",
+				"customTag: digest: sha256:4b1a size: 1234\n",
+			}
+			for _, chunk := range output {
+				_, err := args.StdOut.Write([]byte(chunk))
+				require.NoError(t, err)
+			}
+
+			return exec.NewRunResult(0, strings.Join(output, ""), ""), nil
+		})
+
+		progress := []PushProgress{}
+		err := docker.Push(context.Background(), cwd, tag, func(p PushProgress) {
+			progress = append(progress, p)
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, []PushProgress{
+			{Layers: 1, Pushed: 0},
+			{Layers: 2, Pushed: 0},
+			{Layers: 3, Pushed: 0},
+			{Layers: 3, Pushed: 1},
+			{Layers: 3, Pushed: 2},
+			{Layers: 3, Pushed: 3},
+		}, progress)
+	})
+
+	t.Run("RateLimited", func(t *testing.T) {
+		pushThrottledDelay = time.Millisecond
+		t.Cleanup(func() { pushThrottledDelay = 5 * time.Second })
+
+		mockContext := mocks.NewMockContext(context.Background())
+		docker := NewDocker(mockContext.CommandRunner)
+
+		pushes := 0
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "docker push")
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			pushes++
+			if pushes < 3 {
+				stdErr := "toomanyrequests: too many requests, please retry later"
+				return exec.NewRunResult(1, "", stdErr), errors.New(stdErr)
+			}
+
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+		err := docker.Push(context.Background(), cwd, tag, nil)
+
+		require.NoError(t, err)
+		require.Equal(t, 3, pushes)
+	})
+
+	t.Run("RateLimitedExhausted", func(t *testing.T) {
+		pushThrottledDelay = time.Millisecond
+		t.Cleanup(func() { pushThrottledDelay = 5 * time.Second })
+
+		mockContext := mocks.NewMockContext(context.Background())
+		docker := NewDocker(mockContext.CommandRunner)
+
+		pushes := 0
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "docker push")
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			pushes++
+			stdErr := "received unexpected HTTP status: 429 Too Many Requests"
+			return exec.NewRunResult(1, "", stdErr), errors.New(stdErr)
+		})
+
+		err := docker.Push(context.Background(), cwd, tag, nil)
+
+		require.EqualError(t, err, "pushing image: received unexpected HTTP status: 429 Too Many Requests")
+		require.Equal(t, 1+int(pushThrottledRetries), pushes)
+	})
 }
 
 func Test_DockerLogin(t *testing.T) {
@@ -586,3 +680,52 @@ func TestSplitDockerImage(t *testing.T) {
 		})
 	}
 }
+
+func Test_MirrorBaseImages(t *testing.T) {
+	tests := []struct {
+		name       string
+		dockerfile string
+		mirror     string
+		want       string
+	}{
+		{
+			name:       "Official",
+			dockerfile: "FROM node:18\n",
+			mirror:     "contoso.azurecr.io",
+			want:       "FROM contoso.azurecr.io/library/node:18\n",
+		},
+		{
+			name:       "Namespace",
+			dockerfile: "FROM bitnami/nginx:1.25 AS web",
+			mirror:     "contoso.azurecr.io/hub/",
+			want:       "FROM contoso.azurecr.io/hub/bitnami/nginx:1.25 AS web",
+		},
+		{
+			name:       "DockerHubHost",
+			dockerfile: "from --platform=$BUILDPLATFORM docker.io/library/golang@sha256:4b1a AS build",
+			mirror:     "contoso.azurecr.io",
+			want:       "from --platform=$BUILDPLATFORM contoso.azurecr.io/library/golang@sha256:4b1a AS build",
+		},
+		{
+			name:       "OtherRegistries",
+			dockerfile: "FROM mcr.microsoft.com/dotnet/aspnet:8.0\nFROM localhost:5000/api\nFROM localhost/api",
+			mirror:     "contoso.azurecr.io",
+			want:       "FROM mcr.microsoft.com/dotnet/aspnet:8.0\nFROM localhost:5000/api\nFROM localhost/api",
+		},
+		{
+			name: "StagesScratchAndArgs",
+			dockerfile: "ARG BASE=node:18\nFROM ${BASE} AS base\nFROM golang:1.21 AS Build\n" +
+				"RUN go build -o /app\nFROM build AS test\nFROM scratch\nCOPY --from=build /app /app\n",
+			mirror: "contoso.azurecr.io",
+			want: "ARG BASE=node:18\nFROM ${BASE} AS base\nFROM contoso.azurecr.io/library/golang:1.21 AS Build\n" +
+				"RUN go build -o /app\nFROM build AS test\nFROM scratch\nCOPY --from=build /app /app\n",
+		},
+		{name: "NoMirror", dockerfile: "FROM node:18\n", want: "FROM node:18\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, MirrorBaseImages(tt.dockerfile, tt.mirror))
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package docker
+
+import (
+	"regexp"
+	"strings"
+)
+
+// cFromInstructionRegexp matches the FROM instructions of a Dockerfile, capturing the instruction and its flags, the
+// image reference, and the remainder of the line, like the stage name.
+var cFromInstructionRegexp = regexp.MustCompile(`(?i)^(\s*FROM\s+(?:--\S+\s+)*)(\S+)(.*)$`)
+
+// cStageNameRegexp captures the name of a build stage from the remainder of a FROM instruction, like " AS build".
+var cStageNameRegexp = regexp.MustCompile(`(?i)^\s+AS\s+(\S+)`)
+
+// MirrorBaseImages rewrites the base images of the dockerfile pulled from Docker Hub to be pulled through mirror, a
+// registry like an Azure Container Registry with cache rules for Docker Hub. For example, with the mirror
+// 'contoso.azurecr.io', 'node:18' becomes 'contoso.azurecr.io/library/node:18'.
+//
+// Images of other registries, earlier build stages, scratch, and references with build arguments are left untouched.
+// The dockerfile is returned unchanged when mirror is empty.
+func MirrorBaseImages(dockerfile string, mirror string) string {
+	mirror = strings.TrimSuffix(mirror, "/")
+	if mirror == "" {
+		return dockerfile
+	}
+
+	stages := map[string]bool{}
+	lines := strings.Split(dockerfile, "\n")
+	for i, line := range lines {
+		match := cFromInstructionRegexp.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		instruction, image, remainder := match[1], match[2], match[3]
+		if repository, ok := dockerHubRepository(image); ok && !stages[strings.ToLower(image)] {
+			lines[i] = instruction + mirror + "/" + repository + remainder
+		}
+
+		if stage := cStageNameRegexp.FindStringSubmatch(remainder); stage != nil {
+			stages[strings.ToLower(stage[1])] = true
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// dockerHubRepository returns the repository of the image on Docker Hub, including the tag or digest of the image,
+// like 'library/node:18' for 'node:18'. ok is false for images of other registries, scratch, and references with
+// build arguments.
+func dockerHubRepository(image string) (repository string, ok bool) {
+	if strings.Contains(image, "$") || strings.EqualFold(image, "scratch") {
+		return "", false
+	}
+
+	repository = image
+	if host, path, found := strings.Cut(image, "/"); found && isRegistryHost(host) {
+		switch host {
+		case "docker.io", "index.docker.io", "registry-1.docker.io":
+			repository = path
+		default:
+			return "", false
+		}
+	}
+
+	// Official images are in the library namespace
+	if !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+
+	return repository, true
+}
+
+// isRegistryHost returns whether the first component of an image reference is a registry host rather than a Docker
+// Hub namespace, following the rules of docker: hosts have a domain or a port, or are localhost.
+func isRegistryHost(component string) bool {
+	return strings.ContainsAny(component, ".:") || component == "localhost"
+}
@@ -0,0 +1,79 @@
+package docker
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// PushProgress is the progress of the push of the layers of an image, aggregated from the status of each layer
+// reported by docker push. azd doesn't push the layers itself: the docker daemon pushes them concurrently, up to its
+// max-concurrent-uploads setting, so layers complete in no particular order.
+type PushProgress struct {
+	// Layers is the number of layers of the image reported so far.
+	Layers int
+	// Pushed is the number of layers pushed, mounted from another repository, or already in the registry.
+	Pushed int
+}
+
+// cLayerStatusRegexp matches the status lines docker push writes for each layer when its output isn't a terminal,
+// like "5f70bf18a086: Pushed" or "a1b2c3d4e5f6: Mounted from library/node".
+var cLayerStatusRegexp = regexp.MustCompile(`^([0-9a-f]{12}): (.+)$`)
+
+// pushProgressWriter parses the output of docker push, reporting the aggregated progress of the layers as it changes.
+type pushProgressWriter struct {
+	onProgress func(PushProgress)
+
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	layers map[string]bool
+	pushed int
+}
+
+func newPushProgressWriter(onProgress func(PushProgress)) *pushProgressWriter {
+	return &pushProgressWriter{
+		onProgress: onProgress,
+		layers:     map[string]bool{},
+	}
+}
+
+func (w *pushProgressWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			// Keep the partial line until the rest of it is written
+			w.buf.WriteString(line)
+			break
+		}
+
+		w.parseLine(strings.TrimSpace(line))
+	}
+
+	return len(p), nil
+}
+
+func (w *pushProgressWriter) parseLine(line string) {
+	match := cLayerStatusRegexp.FindStringSubmatch(line)
+	if match == nil {
+		return
+	}
+
+	layer, status := match[1], match[2]
+	done, seen := w.layers[layer]
+	complete := status == "Pushed" || status == "Layer already exists" || strings.HasPrefix(status, "Mounted from")
+	if seen && (done || !complete) {
+		return
+	}
+
+	w.layers[layer] = complete
+	if complete {
+		w.pushed++
+	}
+
+	w.onProgress(PushProgress{Layers: len(w.layers), Pushed: w.pushed})
+}
@@ -24,7 +24,7 @@ type DotNetCli interface {
 	tools.ExternalTool
 	Restore(ctx context.Context, project string) error
 	Build(ctx context.Context, project string, configuration string, output string) error
-	Publish(ctx context.Context, project string, configuration string, output string) error
+	Publish(ctx context.Context, project string, configuration string, output string, options PublishOptions) error
 	PublishContainer(
 		ctx context.Context, project, configuration, imageName, server, username, password string,
 	) (int, error)
@@ -37,6 +37,16 @@ type DotNetCli interface {
 	GetMsBuildProperty(ctx context.Context, project string, propertyName string) (string, error)
 }
 
+// PublishOptions are the options of `dotnet publish`, besides its configuration and output.
+type PublishOptions struct {
+	// The runtime identifier (RID) to publish for, like 'linux-x64'. Publishes a portable app when empty
+	Runtime string
+	// Whether the .NET runtime is published with the app. Left to the default of the project when nil
+	SelfContained *bool
+	// Whether the unused code is trimmed from the published app, which must be self-contained
+	Trimmed bool
+}
+
 type dotNetCli struct {
 	commandRunner exec.CommandRunner
 }
@@ -119,7 +129,9 @@ func (cli *dotNetCli) Build(ctx context.Context, project string, configuration s
 	return nil
 }
 
-func (cli *dotNetCli) Publish(ctx context.Context, project string, configuration string, output string) error {
+func (cli *dotNetCli) Publish(
+	ctx context.Context, project string, configuration string, output string, options PublishOptions,
+) error {
 	runArgs := newDotNetRunArgs("publish", project)
 	if configuration != "" {
 		runArgs = runArgs.AppendParams("-c", configuration)
@@ -129,6 +141,18 @@ func (cli *dotNetCli) Publish(ctx context.Context, project string, configuration
 		runArgs = runArgs.AppendParams("--output", output)
 	}
 
+	if options.Runtime != "" {
+		runArgs = runArgs.AppendParams("-r", options.Runtime)
+	}
+
+	if options.SelfContained != nil {
+		runArgs = runArgs.AppendParams("--self-contained", strconv.FormatBool(*options.SelfContained))
+	}
+
+	if options.Trimmed {
+		runArgs = runArgs.AppendParams("-p:PublishTrimmed=true")
+	}
+
 	_, err := cli.commandRunner.Run(ctx, runArgs)
 	if err != nil {
 		return fmt.Errorf("dotnet publish on project '%s' failed: %w", project, err)
@@ -9,31 +9,76 @@ import (
 	osexec "os/exec"
 )
 
-// missingToolErrors wraps a set of errors discovered when
-// probing for tools and implements the Error interface to pretty
-// print the underlying errors. We use this instead of the existing
-// `multierr` package we use elsewhere, because we want to control
-// the error string (the default one produced by multierr is not
-// as nice as what we do here).
-type missingToolErrors struct {
-	errs []error
+// MissingTool is an external tool which isn't installed, or whose installed version isn't supported.
+type MissingTool struct {
+	// The name of the tool, like 'Docker'
+	Name string
+	// The URL of the instructions to install the tool
+	InstallUrl string
+	// The minimum version of the tool supported by azd, set when the installed version isn't supported
+	MinimumVersion string
+	// The error checking the tool
+	Err error
 }
 
-func (m *missingToolErrors) Error() string {
+func (t MissingTool) String() string {
+	var errSem *ErrSemver
+	switch {
+	case errors.Is(t.Err, osexec.ErrNotFound):
+		return fmt.Sprintf("%s is not installed, see %s to install", t.Name, t.InstallUrl)
+	case errors.As(t.Err, &errSem):
+		return t.Err.Error()
+	default:
+		return fmt.Sprintf("error checking for external tool %s: %s", t.Name, t.Err.Error())
+	}
+}
+
+// MissingToolsError is returned when external tools required by an operation are missing, before the operation starts.
+// We use this instead of the existing `multierr` package we use elsewhere, because we want to control the error string
+// (the default one produced by multierr is not as nice as what we do here).
+type MissingToolsError struct {
+	// The operation requiring the tools, like "building service 'api'". Empty when not known
+	Operation string
+	// The missing tools
+	Tools []MissingTool
+}
+
+func (m *MissingToolsError) Error() string {
 	buf := bytes.Buffer{}
 
-	fmt.Fprintf(&buf, "required external tools are missing:")
-	for _, err := range m.errs {
-		fmt.Fprintf(&buf, "\n - %s", err.Error())
+	if m.Operation != "" {
+		fmt.Fprintf(&buf, "%s requires external tools that are missing:", m.Operation)
+	} else {
+		fmt.Fprintf(&buf, "required external tools are missing:")
+	}
+
+	for _, tool := range m.Tools {
+		fmt.Fprintf(&buf, "\n - %s", tool.String())
 	}
 
 	return buf.String()
 }
 
+// Unwrap returns the errors checking the missing tools, e.g. exec.ErrNotFound for a tool which isn't installed.
+func (m *MissingToolsError) Unwrap() []error {
+	errs := make([]error, 0, len(m.Tools))
+	for _, tool := range m.Tools {
+		errs = append(errs, tool.Err)
+	}
+
+	return errs
+}
+
 // EnsureInstalled checks that all tools are installed, returning an
 // error if one or more tools are not.
 func EnsureInstalled(ctx context.Context, tools ...ExternalTool) error {
-	var allErrors []error
+	return EnsureInstalledFor(ctx, "", tools...)
+}
+
+// EnsureInstalledFor checks that all the tools required by an operation, like "building service 'api'", are installed
+// before it starts. A *MissingToolsError naming the operation is returned when one or more tools are not.
+func EnsureInstalledFor(ctx context.Context, operation string, tools ...ExternalTool) error {
+	var missingTools []MissingTool
 	errorsEncountered := map[string]struct{}{}
 
 	confirmedTools := make(map[string]struct{})
@@ -48,22 +93,22 @@ func EnsureInstalled(ctx context.Context, tools ...ExternalTool) error {
 			continue
 		}
 
-		err := tool.CheckInstalled(ctx)
-		var errSem *ErrSemver
-		if errors.As(err, &errSem) {
-			errorMsg := err.Error()
-			if _, hasV := errorsEncountered[errorMsg]; !hasV {
-				allErrors = append(allErrors, err)
-				errorsEncountered[errorMsg] = struct{}{}
+		if err := tool.CheckInstalled(ctx); err != nil {
+			missingTool := MissingTool{
+				Name:       tool.Name(),
+				InstallUrl: tool.InstallUrl(),
+				Err:        err,
+			}
+
+			var errSem *ErrSemver
+			if errors.As(err, &errSem) {
+				missingTool.MinimumVersion = errSem.VersionInfo.MinimumVersion.String()
 			}
-		} else if errors.Is(err, osexec.ErrNotFound) {
-			allErrors = append(
-				allErrors, fmt.Errorf("%s is not installed, see %s to install", tool.Name(), tool.InstallUrl()))
 
-		} else if err != nil {
-			errorMsg := err.Error()
+			// Tools sharing the same check, e.g. a CLI reused by several tools, are reported once
+			errorMsg := missingTool.String()
 			if _, hasV := errorsEncountered[errorMsg]; !hasV {
-				allErrors = append(allErrors, fmt.Errorf("error checking for external tool %s: %w", tool.Name(), err))
+				missingTools = append(missingTools, missingTool)
 				errorsEncountered[errorMsg] = struct{}{}
 			}
 		}
@@ -72,8 +117,8 @@ func EnsureInstalled(ctx context.Context, tools ...ExternalTool) error {
 		confirmedTools[tool.Name()] = struct{}{}
 	}
 
-	if len(allErrors) > 0 {
-		return &missingToolErrors{errs: allErrors}
+	if len(missingTools) > 0 {
+		return &MissingToolsError{Operation: operation, Tools: missingTools}
 	}
 
 	return nil
@@ -2,8 +2,10 @@ package tools
 
 import (
 	"context"
+	osexec "os/exec"
 	"testing"
 
+	"github.com/blang/semver/v4"
 	"github.com/stretchr/testify/require"
 )
 
@@ -17,6 +19,53 @@ func Test_EnsureInstalledOnlyOnceWhenCached(t *testing.T) {
 	require.Equal(t, tool.installChecks, 1)
 }
 
+func Test_EnsureInstalledFor_MissingTools(t *testing.T) {
+	notInstalled := &missingTestTool{name: "Docker", err: osexec.ErrNotFound}
+	outdated := &missingTestTool{
+		name: "Node.js",
+		err: &ErrSemver{
+			ToolName:    "Node.js",
+			VersionInfo: VersionInfo{MinimumVersion: semver.MustParse("18.0.0"), UpdateCommand: "Update to"},
+		},
+	}
+
+	err := EnsureInstalledFor(context.Background(), "building service 'api'", notInstalled, &TestTool{}, outdated)
+
+	var missingToolsErr *MissingToolsError
+	require.ErrorAs(t, err, &missingToolsErr)
+	require.ErrorIs(t, err, osexec.ErrNotFound)
+	require.Equal(t, "building service 'api'", missingToolsErr.Operation)
+	require.Len(t, missingToolsErr.Tools, 2)
+	require.Equal(t, "Docker", missingToolsErr.Tools[0].Name)
+	require.Equal(t, "https://example.com/install", missingToolsErr.Tools[0].InstallUrl)
+	require.Empty(t, missingToolsErr.Tools[0].MinimumVersion)
+	require.Equal(t, "18.0.0", missingToolsErr.Tools[1].MinimumVersion)
+	require.Equal(t,
+		"building service 'api' requires external tools that are missing:\n"+
+			" - Docker is not installed, see https://example.com/install to install\n"+
+			" - need at least version 18.0.0 or later of Node.js installed. Update to Node.js version",
+		err.Error())
+
+	require.NoError(t, EnsureInstalledFor(context.Background(), "building service 'api'", &TestTool{}))
+}
+
+type missingTestTool struct {
+	name string
+	err  error
+}
+
+func (t *missingTestTool) CheckInstalled(ctx context.Context) error {
+	return t.err
+}
+
+func (t *missingTestTool) InstallUrl() string {
+	return "https://example.com/install"
+}
+
+func (t *missingTestTool) Name() string {
+	return t.name
+}
+
 type TestTool struct {
 	installChecks int
 }
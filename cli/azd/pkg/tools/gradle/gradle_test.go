@@ -0,0 +1,126 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package gradle
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockexec"
+	"github.com/azure/azure-dev/cli/azd/test/ostest"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_getGradlePath(t *testing.T) {
+	rootPath := t.TempDir()
+	sourcePath := filepath.Join(rootPath, "src")
+	projectPath := filepath.Join(sourcePath, "api")
+	pathDir := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(projectPath, 0755))
+	ostest.Unsetenv(t, "PATH")
+
+	tests := []struct {
+		name       string
+		gradlewDir string
+		gradleDir  string
+		want       string
+		wantErr    bool
+	}{
+		{name: "GradlewProjectPath", gradlewDir: projectPath, want: filepath.Join(projectPath, withExt("gradlew"))},
+		{name: "GradlewRootPath", gradlewDir: rootPath, want: filepath.Join(rootPath, withExt("gradlew"))},
+		{
+			name:       "GradlewFirst",
+			gradlewDir: sourcePath,
+			gradleDir:  pathDir,
+			want:       filepath.Join(sourcePath, withExt("gradlew")),
+		},
+		{name: "Gradle", gradleDir: pathDir, want: filepath.Join(pathDir, withExt("gradle"))},
+		{name: "NotFound", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.gradlewDir != "" {
+				placeExecutable(t, filepath.Join(tt.gradlewDir, withExt("gradlew")))
+			}
+			if tt.gradleDir != "" {
+				placeExecutable(t, filepath.Join(tt.gradleDir, withExt("gradle")))
+				t.Setenv("PATH", tt.gradleDir)
+			}
+
+			actual, err := getGradlePath(projectPath, rootPath)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, tt.want, actual)
+		})
+	}
+}
+
+func Test_extractVersion(t *testing.T) {
+	projectPath := t.TempDir()
+	placeExecutable(t, filepath.Join(projectPath, withExt("gradlew")))
+
+	execMock := mockexec.NewMockCommandRunner().
+		When(func(a exec.RunArgs, command string) bool { return a.Args[0] == "--version" }).
+		RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			return exec.NewRunResult(0, heredoc.Doc(`
+
+			------------------------------------------------------------
+			Gradle 8.5
+			------------------------------------------------------------
+
+			Build time:   2023-11-29 14:08:57 UTC
+			`), ""), nil
+		})
+
+	gradle := NewGradleCli(execMock).(*gradleCli)
+	gradle.SetPath(projectPath, projectPath)
+	ver, err := gradle.extractVersion(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "8.5", ver)
+}
+
+func Test_Package(t *testing.T) {
+	projectPath := t.TempDir()
+	placeExecutable(t, filepath.Join(projectPath, withExt("gradlew")))
+
+	var runArgs exec.RunArgs
+	execMock := mockexec.NewMockCommandRunner().
+		When(func(a exec.RunArgs, command string) bool { return a.Args[0] == "assemble" }).
+		RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			runArgs = args
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+	gradle := NewGradleCli(execMock)
+	gradle.SetPath(projectPath, projectPath)
+	err := gradle.Package(context.Background(), projectPath, "bootJar", "-Dspring.profiles.active=prod")
+	require.NoError(t, err)
+	require.Equal(t, projectPath, runArgs.Cwd)
+	require.Equal(t, []string{"assemble", "bootJar", "-Dspring.profiles.active=prod"}, runArgs.Args)
+}
+
+func placeExecutable(t *testing.T, path string) {
+	ostest.Create(t, path)
+	require.NoError(t, os.Chmod(path, 0755))
+}
+
+func withExt(name string) string {
+	if runtime.GOOS == "windows" {
+		// For Windows, we want to test EXT resolution behavior
+		return name + ".bat"
+	}
+
+	return name
+}
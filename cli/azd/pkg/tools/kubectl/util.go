@@ -2,6 +2,7 @@ package kubectl
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -141,3 +142,41 @@ func WaitForResource[T comparable](
 
 	return resource, nil
 }
+
+// NewDockerConfigSecret returns the YAML manifest of a 'kubernetes.io/dockerconfigjson' secret that can be referenced
+// from 'imagePullSecrets' to pull images from the specified registry. The manifest is built locally so the password
+// is never passed on the kubectl command line.
+func NewDockerConfigSecret(name string, server string, username string, password string) (string, error) {
+	auth := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", username, password)))
+	dockerConfig, err := json.Marshal(map[string]any{
+		"auths": map[string]any{
+			server: map[string]string{
+				"username": username,
+				"password": password,
+				"auth":     auth,
+			},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed marshalling docker config, %w", err)
+	}
+
+	secret := map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"type":       "kubernetes.io/dockerconfigjson",
+		"metadata": map[string]any{
+			"name": name,
+		},
+		"data": map[string]string{
+			".dockerconfigjson": base64.StdEncoding.EncodeToString(dockerConfig),
+		},
+	}
+
+	manifest, err := yaml.Marshal(secret)
+	if err != nil {
+		return "", fmt.Errorf("failed marshalling secret to yaml, %w", err)
+	}
+
+	return string(manifest), nil
+}
@@ -21,7 +21,7 @@ type MavenCli interface {
 	SetPath(projectPath string, rootProjectPath string)
 	ResolveDependencies(ctx context.Context, projectPath string) error
 	Compile(ctx context.Context, projectPath string) error
-	Package(ctx context.Context, projectPath string) error
+	Package(ctx context.Context, projectPath string, args ...string) error
 }
 
 type mavenCli struct {
@@ -188,14 +188,15 @@ func (cli *mavenCli) Compile(ctx context.Context, projectPath string) error {
 	return nil
 }
 
-func (cli *mavenCli) Package(ctx context.Context, projectPath string) error {
+// Package packages the project, running the additional goals and arguments of args.
+func (cli *mavenCli) Package(ctx context.Context, projectPath string, args ...string) error {
 	mvnCmd, err := cli.mvnCmd()
 	if err != nil {
 		return err
 	}
 
 	// Maven's package phase includes tests by default. Skip it explicitly.
-	runArgs := exec.NewRunArgs(mvnCmd, "package", "-DskipTests").WithCwd(projectPath)
+	runArgs := exec.NewRunArgs(mvnCmd, append([]string{"package", "-DskipTests"}, args...)...).WithCwd(projectPath)
 	_, err = cli.commandRunner.Run(ctx, runArgs)
 	if err != nil {
 		return fmt.Errorf("mvn package on project '%s' failed: %w", projectPath, err)
@@ -6,6 +6,7 @@ package npm
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
@@ -16,6 +17,10 @@ type NpmCli interface {
 	tools.ExternalTool
 	Install(ctx context.Context, project string) error
 
+	// InstallWorkspace installs the dependencies of all the packages of the workspace at its root, once for all the
+	// members of the workspace.
+	InstallWorkspace(ctx context.Context, workspace *Workspace) error
+
 	// RunScript runs the given npm script (if it exists) in the project.
 	//
 	// Returns an error only if the script execution fails. If the script doesn't exist, no error is returned.
@@ -25,6 +30,8 @@ type NpmCli interface {
 
 type npmCli struct {
 	commandRunner exec.CommandRunner
+	// The installs of the workspaces, keyed by their root
+	workspaceInstalls sync.Map
 }
 
 func NewNpmCli(commandRunner exec.CommandRunner) NpmCli {
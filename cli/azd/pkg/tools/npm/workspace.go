@@ -0,0 +1,188 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"gopkg.in/yaml.v3"
+)
+
+// PackageManager is the package manager installing the dependencies of a workspace.
+type PackageManager string
+
+const (
+	PackageManagerNpm  PackageManager = "npm"
+	PackageManagerYarn PackageManager = "yarn"
+	PackageManagerPnpm PackageManager = "pnpm"
+)
+
+// Workspace is a monorepo whose packages share a single node_modules, installed at the root of the workspace by npm,
+// yarn or pnpm workspaces.
+type Workspace struct {
+	// The directory of the root package.json of the workspace
+	Root string
+	// The package manager installing the workspace
+	PackageManager PackageManager
+}
+
+// FindWorkspace returns the workspace the package at projectPath is a member of, looking for the root of the workspace
+// in the parent directories of projectPath up to stopAt. The root is the directory with a package.json listing
+// `workspaces`, or with a pnpm-workspace.yaml file. Returns nil when the package isn't a member of a workspace.
+func FindWorkspace(projectPath string, stopAt string) (*Workspace, error) {
+	projectPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	stopAt, err = filepath.Abs(stopAt)
+	if err != nil {
+		return nil, err
+	}
+
+	for dir := filepath.Dir(projectPath); ; dir = filepath.Dir(dir) {
+		rel, err := filepath.Rel(stopAt, dir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, nil
+		}
+
+		patterns, packageManager, err := readWorkspacePatterns(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(patterns) > 0 {
+			member, err := filepath.Rel(dir, projectPath)
+			if err != nil {
+				return nil, err
+			}
+
+			if matchesWorkspace(filepath.ToSlash(member), patterns) {
+				return &Workspace{Root: dir, PackageManager: packageManager}, nil
+			}
+
+			// A package isn't a member of the workspaces enclosing a workspace it isn't a member of
+			return nil, nil
+		}
+
+		if dir == stopAt || dir == filepath.Dir(dir) {
+			return nil, nil
+		}
+	}
+}
+
+// readWorkspacePatterns reads the patterns of the packages of the workspace rooted at dir, and the package manager
+// installing it. Returns no patterns when dir isn't the root of a workspace.
+func readWorkspacePatterns(dir string) ([]string, PackageManager, error) {
+	pnpmWorkspace, err := os.ReadFile(filepath.Join(dir, "pnpm-workspace.yaml"))
+	if err == nil {
+		var workspace struct {
+			Packages []string `yaml:"packages"`
+		}
+		if err := yaml.Unmarshal(pnpmWorkspace, &workspace); err != nil {
+			return nil, "", fmt.Errorf("parsing %s: %w", filepath.Join(dir, "pnpm-workspace.yaml"), err)
+		}
+
+		return workspace.Packages, PackageManagerPnpm, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, "", err
+	}
+
+	packageJson, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, "", nil
+	} else if err != nil {
+		return nil, "", err
+	}
+
+	var pkg struct {
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+	if err := json.Unmarshal(packageJson, &pkg); err != nil {
+		return nil, "", fmt.Errorf("parsing %s: %w", filepath.Join(dir, "package.json"), err)
+	}
+
+	if len(pkg.Workspaces) == 0 {
+		return nil, "", nil
+	}
+
+	// Workspaces are either a list of patterns, or an object with the patterns in `packages` for yarn
+	var patterns []string
+	if err := json.Unmarshal(pkg.Workspaces, &patterns); err != nil {
+		var yarnWorkspaces struct {
+			Packages []string `json:"packages"`
+		}
+		if err := json.Unmarshal(pkg.Workspaces, &yarnWorkspaces); err != nil {
+			return nil, "", fmt.Errorf("parsing the workspaces of %s: %w", filepath.Join(dir, "package.json"), err)
+		}
+
+		patterns = yarnWorkspaces.Packages
+	}
+
+	packageManager := PackageManagerNpm
+	if _, err := os.Stat(filepath.Join(dir, "yarn.lock")); err == nil {
+		packageManager = PackageManagerYarn
+	}
+
+	return patterns, packageManager, nil
+}
+
+// matchesWorkspace returns whether the package at the slash separated path member, relative to the root of the
+// workspace, matches one of the patterns of the packages of the workspace, like 'packages/*' or 'apps/**'.
+func matchesWorkspace(member string, patterns []string) bool {
+	matched := false
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(strings.TrimPrefix(filepath.ToSlash(pattern), "./"), "/")
+		excluded := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(pattern, "!")
+
+		var match bool
+		if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+			match = strings.HasPrefix(member, prefix+"/")
+		} else {
+			// The patterns and the member are slash separated on every platform
+			match, _ = path.Match(pattern, member)
+		}
+
+		if match {
+			matched = !excluded
+		}
+	}
+
+	return matched
+}
+
+// workspaceInstall is the install of a workspace, shared by the members of the workspace.
+type workspaceInstall struct {
+	once sync.Once
+	err  error
+}
+
+// InstallWorkspace installs the dependencies of all the packages of the workspace at its root, with its package
+// manager. The workspace is only installed once, when several of its members are restored.
+func (cli *npmCli) InstallWorkspace(ctx context.Context, workspace *Workspace) error {
+	value, _ := cli.workspaceInstalls.LoadOrStore(workspace.Root, &workspaceInstall{})
+	install := value.(*workspaceInstall)
+
+	install.once.Do(func() {
+		runArgs := exec.
+			NewRunArgs(string(workspace.PackageManager), "install").
+			WithCwd(workspace.Root)
+
+		if _, err := cli.commandRunner.Run(ctx, runArgs); err != nil {
+			install.err = fmt.Errorf("failed to install workspace %s: %w", workspace.Root, err)
+		}
+	})
+
+	return install.err
+}
@@ -0,0 +1,146 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package npm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_FindWorkspace(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		member   string
+		wantRoot string
+		wantPm   PackageManager
+	}{
+		{
+			name:     "Npm",
+			files:    map[string]string{"package.json": `{"workspaces": ["apps/*"]}`},
+			member:   "apps/api",
+			wantRoot: ".",
+			wantPm:   PackageManagerNpm,
+		},
+		{
+			name: "Yarn",
+			files: map[string]string{
+				"package.json": `{"workspaces": {"packages": ["./services/**"]}}`,
+				"yarn.lock":    "",
+			},
+			member:   "services/backend/api",
+			wantRoot: ".",
+			wantPm:   PackageManagerYarn,
+		},
+		{
+			name: "Pnpm",
+			files: map[string]string{
+				"package.json":        `{"name": "root"}`,
+				"pnpm-workspace.yaml": "packages:\n  - 'apps/*'\n",
+			},
+			member:   "apps/web",
+			wantRoot: ".",
+			wantPm:   PackageManagerPnpm,
+		},
+		{
+			name: "NestedRoot",
+			files: map[string]string{
+				"package.json":     `{"name": "root"}`,
+				"src/package.json": `{"workspaces": ["*"]}`,
+			},
+			member:   "src/api",
+			wantRoot: "src",
+			wantPm:   PackageManagerNpm,
+		},
+		{
+			name:   "NotMember",
+			files:  map[string]string{"package.json": `{"workspaces": ["apps/*"]}`},
+			member: "tools/cli",
+		},
+		{
+			// A single star only matches a single directory
+			name:   "NestedNotMember",
+			files:  map[string]string{"package.json": `{"workspaces": ["apps/*"]}`},
+			member: "apps/api/src",
+		},
+		{
+			name:   "Excluded",
+			files:  map[string]string{"package.json": `{"workspaces": ["apps/*", "!apps/legacy"]}`},
+			member: "apps/legacy",
+		},
+		{
+			name:   "NoWorkspace",
+			files:  map[string]string{"package.json": `{"name": "root"}`},
+			member: "apps/api",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for path, contents := range tt.files {
+				path = filepath.Join(root, path)
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+				require.NoError(t, os.WriteFile(path, []byte(contents), 0600))
+			}
+
+			workspace, err := FindWorkspace(filepath.Join(root, tt.member), root)
+			require.NoError(t, err)
+
+			if tt.wantRoot == "" {
+				require.Nil(t, workspace)
+				return
+			}
+
+			require.Equal(t, &Workspace{Root: filepath.Join(root, tt.wantRoot), PackageManager: tt.wantPm}, workspace)
+		})
+	}
+
+	t.Run("StopAt", func(t *testing.T) {
+		root := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(root, "package.json"), []byte(`{"workspaces": ["*/*"]}`), 0600))
+
+		// The workspace enclosing the project isn't used by its services
+		workspace, err := FindWorkspace(filepath.Join(root, "project", "api"), filepath.Join(root, "project"))
+		require.NoError(t, err)
+		require.Nil(t, workspace)
+	})
+}
+
+func Test_InstallWorkspace_Once(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	var installs []exec.RunArgs
+	var mu sync.Mutex
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return command == "pnpm install"
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		installs = append(installs, args)
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	cli := NewNpmCli(mockContext.CommandRunner)
+	workspace := &Workspace{Root: "/repo", PackageManager: PackageManagerPnpm}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, cli.InstallWorkspace(*mockContext.Context, workspace))
+		}()
+	}
+	wg.Wait()
+
+	require.Len(t, installs, 1)
+	require.Equal(t, "/repo", installs[0].Cwd)
+}
@@ -25,9 +25,10 @@ type powershellScript struct {
 // Executes the specified powershell script
 // When interactive is true will attach to stdin, stdout & stderr
 func (bs *powershellScript) Execute(ctx context.Context, path string, options tools.ExecOptions) (exec.RunResult, error) {
-	runArgs := exec.NewRunArgs("pwsh", path).
+	runArgs := exec.NewRunArgs("pwsh", append([]string{path}, options.Args...)...).
 		WithCwd(bs.cwd).
 		WithEnv(bs.envVars).
+		WithRestrictedEnv(true).
 		WithShell(true)
 
 	if options.Interactive != nil {
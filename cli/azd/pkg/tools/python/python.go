@@ -5,25 +5,107 @@ package python
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	osexec "os/exec"
 	"path"
 	"path/filepath"
 	"runtime"
+	"strings"
 
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/blang/semver/v4"
 )
 
+// PythonPathEnvVarName is the name of the environment variable pinning the python interpreter used by azd, like
+// `/usr/bin/python3.11`, instead of discovering it in PATH. It takes precedence over the PythonPathConfigPath setting.
+const PythonPathEnvVarName = "AZD_PYTHON_PATH"
+
+// PythonPathConfigPath is the user config setting pinning the python interpreter used by azd, set with
+// `azd config set tools.python.path <path>`.
+const PythonPathConfigPath = "tools.python.path"
+
 type PythonCli struct {
 	commandRunner exec.CommandRunner
+	// The path of the pinned interpreter, empty when the interpreter is discovered in PATH.
+	pinnedPath string
+	// Where the interpreter is pinned, for diagnostics.
+	pinnedBy string
 }
 
-func NewPythonCli(commandRunner exec.CommandRunner) *PythonCli {
-	return &PythonCli{
+func NewPythonCli(commandRunner exec.CommandRunner, userConfigManager config.UserConfigManager) *PythonCli {
+	cli := &PythonCli{
 		commandRunner: commandRunner,
 	}
+
+	if pinnedPath := os.Getenv(PythonPathEnvVarName); pinnedPath != "" {
+		cli.pinnedPath = pinnedPath
+		cli.pinnedBy = PythonPathEnvVarName
+	} else if userConfig, err := userConfigManager.Load(); err != nil {
+		log.Printf("reading the pinned python interpreter from the user config: %v", err)
+	} else if pinnedPath, has := userConfig.GetString(PythonPathConfigPath); has && pinnedPath != "" {
+		cli.pinnedPath = pinnedPath
+		cli.pinnedBy = PythonPathConfigPath
+	}
+
+	return cli
+}
+
+// ValidatePinned returns an error when the pinned python interpreter doesn't exist. It returns nil when the interpreter
+// isn't pinned.
+func (cli *PythonCli) ValidatePinned() error {
+	_, err := cli.resolvePinned()
+	return err
+}
+
+// resolvePinned returns the path of the pinned python interpreter. An interpreter pinned by name, like `python3.11`, is
+// looked up in PATH.
+func (cli *PythonCli) resolvePinned() (string, error) {
+	if cli.pinnedPath == "" {
+		return "", nil
+	}
+
+	if filepath.Base(cli.pinnedPath) == cli.pinnedPath {
+		resolved, err := osexec.LookPath(cli.pinnedPath)
+		if err != nil {
+			return "", fmt.Errorf(
+				"the python interpreter '%s' pinned with %s was not found in PATH", cli.pinnedPath, cli.pinnedBy)
+		}
+
+		return resolved, nil
+	}
+
+	info, err := os.Stat(cli.pinnedPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf(
+			"the python interpreter '%s' pinned with %s does not exist", cli.pinnedPath, cli.pinnedBy)
+	} else if err != nil {
+		return "", fmt.Errorf(
+			"checking the python interpreter '%s' pinned with %s: %w", cli.pinnedPath, cli.pinnedBy, err)
+	}
+
+	if info.IsDir() {
+		return "", fmt.Errorf(
+			"the python interpreter '%s' pinned with %s is a directory, expected the path of the executable",
+			cli.pinnedPath,
+			cli.pinnedBy)
+	}
+
+	return cli.pinnedPath, nil
+}
+
+// Path returns the path of the python interpreter used by azd, which is the pinned interpreter when set, or else the
+// one found in PATH.
+func (cli *PythonCli) Path() (string, error) {
+	if cli.pinnedPath != "" {
+		return cli.resolvePinned()
+	}
+
+	return checkPath()
 }
 
 func (cli *PythonCli) versionInfo() tools.VersionInfo {
@@ -37,10 +119,16 @@ func (cli *PythonCli) versionInfo() tools.VersionInfo {
 }
 
 func (cli *PythonCli) CheckInstalled(ctx context.Context) error {
-	pyString, err := checkPath()
+	pyString, err := cli.Path()
 	if err != nil {
 		return err
 	}
+
+	if cli.pinnedPath != "" {
+		log.Printf("using python interpreter: %s (pinned with %s)", pyString, cli.pinnedBy)
+	} else {
+		log.Printf("using python interpreter: %s (found in PATH)", pyString)
+	}
 	pythonRes, err := tools.ExecuteCommand(ctx, cli.commandRunner, pyString, "--version")
 	if err != nil {
 		return fmt.Errorf("checking %s version: %w", cli.Name(), err)
@@ -67,14 +155,11 @@ func (cli *PythonCli) Name() string {
 	return "Python CLI"
 }
 
+// InstallRequirements installs the requirements of a project into its virtual environment. The packages are installed
+// with the interpreter of the virtual environment, the pinned interpreter is only used to create it.
 func (cli *PythonCli) InstallRequirements(ctx context.Context, workingDir, environment, requirementFile string) error {
 	var err error
 
-	pyString, err := checkPath()
-	if err != nil {
-		return err
-	}
-
 	if runtime.GOOS == "windows" {
 		// Unfortunately neither cmd.exe, nor PowerShell provide a straightforward way to use a script
 		// to modify environment for command(s) in a command list.
@@ -85,20 +170,23 @@ func (cli *PythonCli) InstallRequirements(ctx context.Context, workingDir, envir
 			return pathErr
 		}
 
-		vEnvSetting := fmt.Sprintf("VIRTUAL_ENV=%s", path.Join(absWorkingDir, environment))
+		vEnvPath := filepath.Join(absWorkingDir, environment)
+		vEnvSetting := fmt.Sprintf("VIRTUAL_ENV=%s", vEnvPath)
 
 		runArgs := exec.
-			NewRunArgs(pyString, "-m", "pip", "install", "-r", requirementFile).
+			NewRunArgs(filepath.Join(vEnvPath, "Scripts", "python.exe"), "-m", "pip", "install", "-r", requirementFile).
 			WithCwd(workingDir).
-			WithEnv([]string{vEnvSetting})
+			WithEnv([]string{vEnvSetting}).
+			WithRestrictedEnv(true)
 
 		_, err = cli.commandRunner.Run(ctx, runArgs)
 	} else {
-		envActivation := ". " + path.Join(environment, "bin", "activate")
-		installCmd := fmt.Sprintf("%s -m pip install -r %s", pyString, requirementFile)
+		// Once the virtual environment is activated, python is its interpreter
+		envActivation := ". " + shellQuote(path.Join(environment, "bin", "activate"))
+		installCmd := fmt.Sprintf("python -m pip install -r %s", shellQuote(requirementFile))
 		commands := []string{envActivation, installCmd}
 
-		runArgs := exec.NewRunArgs(pyString).WithCwd(workingDir)
+		runArgs := exec.NewRunArgs("python").WithCwd(workingDir).WithRestrictedEnv(true)
 		_, err = cli.commandRunner.RunList(ctx, commands, runArgs)
 	}
 
@@ -109,14 +197,15 @@ func (cli *PythonCli) InstallRequirements(ctx context.Context, workingDir, envir
 }
 
 func (cli *PythonCli) CreateVirtualEnv(ctx context.Context, workingDir, name string) error {
-	pyString, err := checkPath()
+	pyString, err := cli.Path()
 	if err != nil {
 		return err
 	}
 
 	runArgs := exec.
 		NewRunArgs(pyString, "-m", "venv", name).
-		WithCwd(workingDir)
+		WithCwd(workingDir).
+		WithRestrictedEnv(true)
 
 	_, err = cli.commandRunner.Run(ctx, runArgs)
 
@@ -150,3 +239,8 @@ func checkPath() (pyString string, err error) {
 		return "", err
 	}
 }
+
+// shellQuote quotes a value for a POSIX shell.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,137 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package python
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockconfig"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockexec"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PinnedInterpreter(t *testing.T) {
+	pinned := filepath.Join(t.TempDir(), "python3.11")
+	require.NoError(t, os.WriteFile(pinned, nil, osutil.PermissionExecutableFile))
+
+	newCli := func(t *testing.T, userConfig config.Config) (*PythonCli, *[]string) {
+		var ran []string
+		runner := mockexec.NewMockCommandRunner()
+		runner.When(func(args exec.RunArgs, command string) bool {
+			return true
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			ran = append(ran, args.Cmd)
+			return exec.NewRunResult(0, "Python 3.11.4", ""), nil
+		})
+
+		configManager := mockconfig.NewMockConfigManager().WithConfig(userConfig)
+		return NewPythonCli(runner, config.NewUserConfigManager(configManager)), &ran
+	}
+
+	t.Run("EnvVar", func(t *testing.T) {
+		t.Setenv(PythonPathEnvVarName, pinned)
+
+		userConfig := config.NewEmptyConfig()
+		require.NoError(t, userConfig.Set(PythonPathConfigPath, filepath.Join(t.TempDir(), "python3")))
+
+		// The environment variable takes precedence over the config setting
+		cli, ran := newCli(t, userConfig)
+		require.NoError(t, cli.ValidatePinned())
+		require.NoError(t, cli.CheckInstalled(context.Background()))
+		require.Equal(t, []string{pinned}, *ran)
+	})
+
+	t.Run("Config", func(t *testing.T) {
+		t.Setenv(PythonPathEnvVarName, "")
+
+		userConfig := config.NewEmptyConfig()
+		require.NoError(t, userConfig.Set(PythonPathConfigPath, pinned))
+
+		cli, _ := newCli(t, userConfig)
+		path, err := cli.Path()
+		require.NoError(t, err)
+		require.Equal(t, pinned, path)
+	})
+
+	t.Run("NotPinned", func(t *testing.T) {
+		t.Setenv(PythonPathEnvVarName, "")
+
+		cli, _ := newCli(t, config.NewEmptyConfig())
+		require.NoError(t, cli.ValidatePinned())
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		missing := filepath.Join(t.TempDir(), "python3")
+		t.Setenv(PythonPathEnvVarName, missing)
+
+		cli, ran := newCli(t, config.NewEmptyConfig())
+		require.EqualError(t, cli.ValidatePinned(),
+			"the python interpreter '"+missing+"' pinned with AZD_PYTHON_PATH does not exist")
+
+		// The interpreter isn't looked up in PATH instead
+		require.Error(t, cli.CheckInstalled(context.Background()))
+		require.Empty(t, *ran)
+	})
+
+	t.Run("Name", func(t *testing.T) {
+		// An interpreter pinned by name is looked up in PATH
+		t.Setenv("PATH", filepath.Dir(pinned))
+		t.Setenv(PythonPathEnvVarName, "python3.11")
+
+		cli, _ := newCli(t, config.NewEmptyConfig())
+		path, err := cli.Path()
+		require.NoError(t, err)
+		require.Equal(t, pinned, path)
+
+		t.Setenv(PythonPathEnvVarName, "python3.99")
+		cli, _ = newCli(t, config.NewEmptyConfig())
+		require.EqualError(t, cli.ValidatePinned(),
+			"the python interpreter 'python3.99' pinned with AZD_PYTHON_PATH was not found in PATH")
+	})
+
+	t.Run("Directory", func(t *testing.T) {
+		t.Setenv(PythonPathEnvVarName, t.TempDir())
+
+		cli, _ := newCli(t, config.NewEmptyConfig())
+		require.ErrorContains(t, cli.ValidatePinned(), "is a directory")
+	})
+}
+
+func Test_InstallRequirements(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("virtual environments are activated by a shell script on POSIX only")
+	}
+
+	pinned := filepath.Join(t.TempDir(), "python3.11")
+	require.NoError(t, os.WriteFile(pinned, nil, osutil.PermissionExecutableFile))
+	t.Setenv(PythonPathEnvVarName, pinned)
+
+	var ran []string
+	runner := mockexec.NewMockCommandRunner()
+	runner.When(func(args exec.RunArgs, command string) bool {
+		return true
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		ran = append(ran, args.Args...)
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	configManager := mockconfig.NewMockConfigManager().WithConfig(config.NewEmptyConfig())
+	cli := NewPythonCli(runner, config.NewUserConfigManager(configManager))
+
+	err := cli.InstallRequirements(context.Background(), t.TempDir(), ".venv", "my requirements.txt")
+	require.NoError(t, err)
+
+	// The requirements are installed with the interpreter of the activated virtual environment, not the pinned one
+	require.Equal(t, []string{
+		". '.venv/bin/activate'",
+		"python -m pip install -r 'my requirements.txt'",
+	}, ran)
+}
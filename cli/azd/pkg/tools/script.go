@@ -11,6 +11,8 @@ import (
 type ExecOptions struct {
 	Interactive *bool
 	StdOut      io.Writer
+	// Arguments passed to the script
+	Args []string
 }
 
 // Utility to easily execute a bash script across platforms
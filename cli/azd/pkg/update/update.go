@@ -0,0 +1,309 @@
+// Package update checks whether a newer version of azd is available.
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/internal/runcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/azure/azure-dev/cli/azd/pkg/installer"
+	"github.com/azure/azure-dev/cli/azd/pkg/logging"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/blang/semver/v4"
+)
+
+// SkipUpdateCheckEnvVarName is the name of the environment variable which, when set to a truthy value, disables the
+// requests to the release feed, like the offline mode: only the cached latest version is used.
+const SkipUpdateCheckEnvVarName = "AZD_SKIP_UPDATE_CHECK"
+
+// cacheFileName is the name of the file created in the azd configuration directory which is used to cache version
+// information for the up to date check.
+const cacheFileName = "update-check.json"
+
+// cacheDuration is how long the latest version is cached.
+const cacheDuration = 24 * time.Hour
+
+// ErrOffline is returned when the latest version isn't cached, and the release feed can't be queried since the update
+// check is disabled.
+var ErrOffline = errors.New(
+	"the update check is disabled by the offline mode or " + SkipUpdateCheckEnvVarName + ", and no version is cached")
+
+// Checker fetches the latest version of azd from the release feed, caching it in the azd configuration directory.
+type Checker struct {
+	// FeedUrl is the url of the release feed, which returns the latest version as plain text.
+	FeedUrl string
+	// CacheFilePath is the path of the file caching the latest version.
+	CacheFilePath string
+	// SendUserAgent is whether the requests to the feed include the user agent of azd, which is disabled when the
+	// user opted out of telemetry.
+	SendUserAgent bool
+	// HttpClient sends the requests to the feed.
+	HttpClient httputil.HttpClient
+}
+
+// NewChecker creates a Checker for the release feed of azd, caching the latest version in the user config directory.
+func NewChecker(httpClient httputil.HttpClient, sendUserAgent bool) (*Checker, error) {
+	configDir, err := config.GetUserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("could not determine config directory: %w", err)
+	}
+
+	return &Checker{
+		FeedUrl:       "https://aka.ms/azure-dev/versions/cli/latest",
+		CacheFilePath: filepath.Join(configDir, cacheFileName),
+		SendUserAgent: sendUserAgent,
+		HttpClient:    httpClient,
+	}, nil
+}
+
+// CheckResult is the result of an update check.
+type CheckResult struct {
+	// Current is the version of the running azd, the same version recorded in telemetry.
+	Current semver.Version
+	// Latest is the latest released version of azd.
+	Latest semver.Version
+}
+
+// UpdateAvailable returns whether the latest version is newer than the current one. Dev builds are never out of date.
+func (r *CheckResult) UpdateAvailable() bool {
+	return !internal.IsDevVersion() && r.Latest.GT(r.Current)
+}
+
+// Check compares the current version of azd to the latest one. A latest version cached for less than maxAge is used
+// instead of querying the feed, any unexpired cached version is used when maxAge is zero.
+func (c *Checker) Check(ctx context.Context, maxAge time.Duration) (*CheckResult, error) {
+	latest, err := c.LatestVersion(ctx, maxAge)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CheckResult{
+		Current: internal.VersionInfo().Version,
+		Latest:  latest,
+	}, nil
+}
+
+// LatestVersion returns the latest version of azd. A latest version cached for less than maxAge is used instead of
+// querying the feed, any unexpired cached version is used when maxAge is zero.
+//
+// When the update check is disabled with AZD_SKIP_UPDATE_CHECK or the offline mode, only the cached version is used,
+// even when expired, and ErrOffline is returned when there is none.
+func (c *Checker) LatestVersion(ctx context.Context, maxAge time.Duration) (semver.Version, error) {
+	offline := runcontext.IsOffline()
+	if value, has := os.LookupEnv(SkipUpdateCheckEnvVarName); has {
+		if setting, err := strconv.ParseBool(value); err == nil {
+			offline = offline || setting
+		} else {
+			log.Printf("could not parse value for %s a boolean (it was: %s), proceeding with update check",
+				SkipUpdateCheckEnvVarName, value)
+		}
+	}
+
+	cache, err := c.readCache()
+	if err != nil {
+		log.Printf("%v, ignoring cache", err)
+	}
+
+	if cache != nil {
+		version, err := cache.version(maxAge, offline)
+		if err == nil {
+			log.Printf("using cached latest version: %s (expires on: %s)", cache.Version, cache.ExpiresOn)
+			return version, nil
+		}
+
+		log.Printf("ignoring cached latest version: %v", err)
+	}
+
+	if offline {
+		return semver.Version{}, ErrOffline
+	}
+
+	return c.fetch(ctx)
+}
+
+// fetch fetches the latest version from the feed, and caches it.
+func (c *Checker) fetch(ctx context.Context) (semver.Version, error) {
+	log.Print("fetching latest version information for update check")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.FeedUrl, nil)
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("failed to create request object: %w", err)
+	}
+
+	if c.SendUserAgent {
+		req.Header.Set("User-Agent", internal.UserAgent())
+	}
+
+	res, err := c.HttpClient.Do(req)
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("failed to fetch latest version: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return semver.Version{}, fmt.Errorf(
+			"failed to refresh latest version, http status: %v, body: %v", res.StatusCode, string(body))
+	}
+
+	// Parse the body of the response as a semver, and if it's valid, cache it.
+	fetchedVersionText := strings.TrimSpace(string(body))
+	fetchedVersion, err := semver.Parse(fetchedVersionText)
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("failed to parse latest version '%s' as a semver: %w", fetchedVersionText, err)
+	}
+
+	now := time.Now().UTC()
+	cache := cacheFile{
+		Version:   fetchedVersionText,
+		ExpiresOn: now.Add(cacheDuration).Format(time.RFC3339),
+		CheckedOn: now.Format(time.RFC3339),
+	}
+
+	// Failing to cache the version doesn't fail the check
+	if err := c.writeCache(cache); err != nil {
+		logging.Warn("writing the update cache file", "error", err)
+	} else {
+		log.Printf("updated cache file to version %s (expires on: %s)", cache.Version, cache.ExpiresOn)
+	}
+
+	return fetchedVersion, nil
+}
+
+type cacheFile struct {
+	// The semver of the latest version the CLI
+	Version string `json:"version"`
+	// A time at which this cached value expires, stored as an RFC3339 timestamp
+	ExpiresOn string `json:"expiresOn"`
+	// The time at which the version was fetched, stored as an RFC3339 timestamp
+	CheckedOn string `json:"checkedOn,omitempty"`
+}
+
+// version returns the cached version, or an error when it can't be used because it is invalid, it expired or it is
+// older than maxAge. Expired versions are used when offline.
+func (f *cacheFile) version(maxAge time.Duration, offline bool) (semver.Version, error) {
+	version, err := semver.Parse(f.Version)
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("failed to parse cached version '%s' as a semver: %w", f.Version, err)
+	}
+
+	if offline {
+		return version, nil
+	}
+
+	expiresOn, err := time.Parse(time.RFC3339, f.ExpiresOn)
+	if err != nil {
+		return semver.Version{}, fmt.Errorf(
+			"failed to parse cached version expiration time '%s' as a RFC3339 timestamp: %w", f.ExpiresOn, err)
+	}
+
+	now := time.Now().UTC()
+	if !now.Before(expiresOn) {
+		return semver.Version{}, errors.New("it is out of date")
+	}
+
+	if maxAge > 0 {
+		// Cache files written before the check time was recorded are considered too old
+		checkedOn, err := time.Parse(time.RFC3339, f.CheckedOn)
+		if err != nil || now.Sub(checkedOn) > maxAge {
+			return semver.Version{}, fmt.Errorf("it is older than %s", maxAge)
+		}
+	}
+
+	return version, nil
+}
+
+func (c *Checker) readCache() (*cacheFile, error) {
+	contents, err := os.ReadFile(c.CacheFilePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading update cache file: %w", err)
+	}
+
+	var cache cacheFile
+	if err := json.Unmarshal(contents, &cache); err != nil {
+		return nil, fmt.Errorf("could not unmarshal cache file: %w", err)
+	}
+
+	return &cache, nil
+}
+
+func (c *Checker) writeCache(cache cacheFile) error {
+	if err := os.MkdirAll(filepath.Dir(c.CacheFilePath), osutil.PermissionDirectory); err != nil {
+		return fmt.Errorf("failed to create cache folder '%s': %w", filepath.Dir(c.CacheFilePath), err)
+	}
+
+	// The marshal call can not fail, so we ignore the error.
+	contents, _ := json.Marshal(cache)
+
+	return os.WriteFile(c.CacheFilePath, contents, osutil.PermissionFile)
+}
+
+// UpgradeInstructions returns how to update azd, based on the installer which installed it, like
+// "run:\nwinget upgrade Microsoft.Azd".
+func UpgradeInstructions() string {
+	var upgradeText string
+
+	installedBy := installer.InstalledBy()
+	if runtime.GOOS == "windows" {
+		switch installedBy {
+		case installer.InstallTypePs:
+			//nolint:lll
+			upgradeText = "run:\npowershell -ex AllSigned -c \"Invoke-RestMethod 'https://aka.ms/install-azd.ps1' | Invoke-Expression\"\n\nIf the install script was run with custom parameters, ensure that the same parameters are used for the upgrade. For advanced install instructions, see: https://aka.ms/azd/upgrade/windows"
+		case installer.InstallTypeWinget:
+			upgradeText = "run:\nwinget upgrade Microsoft.Azd"
+		case installer.InstallTypeChoco:
+			upgradeText = "run:\nchoco upgrade azd"
+		default:
+			// Also covers "msi" case where the user installed directly
+			// via MSI
+			upgradeText = "visit https://aka.ms/azd/upgrade/windows"
+		}
+	} else if runtime.GOOS == "linux" {
+		switch installedBy {
+		case installer.InstallTypeSh:
+			//nolint:lll
+			upgradeText = "run:\ncurl -fsSL https://aka.ms/install-azd.sh | bash\n\nIf the install script was run with custom parameters, ensure that the same parameters are used for the upgrade. For advanced install instructions, see: https://aka.ms/azd/upgrade/linux"
+		default:
+			// Also covers "deb" and "rpm" cases which are currently
+			// documented. When package manager distribution support is
+			// added, this will need to be updated.
+			upgradeText = "visit https://aka.ms/azd/upgrade/linux"
+		}
+	} else if runtime.GOOS == "darwin" {
+		switch installedBy {
+		case installer.InstallTypeBrew:
+			upgradeText = "run:\nbrew update && brew upgrade azd"
+		case installer.InstallTypeSh:
+			//nolint:lll
+			upgradeText = "run:\ncurl -fsSL https://aka.ms/install-azd.sh | bash\n\nIf the install script was run with custom parameters, ensure that the same parameters are used for the upgrade. For advanced install instructions, see: https://aka.ms/azd/upgrade/mac"
+		default:
+			upgradeText = "visit https://aka.ms/azd/upgrade/mac"
+		}
+	} else {
+		// Platform is not recognized, use the generic install link
+		upgradeText = "visit https://aka.ms/azd/upgrade"
+	}
+
+	return upgradeText
+}
@@ -0,0 +1,166 @@
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/internal/runcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeFeed serves latest as the latest version of azd, counting the requests.
+type fakeFeed struct {
+	latest     string
+	requests   int
+	userAgents []string
+}
+
+func (f *fakeFeed) checker(t *testing.T, sendUserAgent bool) *Checker {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.requests++
+		f.userAgents = append(f.userAgents, r.Header.Get("User-Agent"))
+		fmt.Fprintln(w, f.latest)
+	}))
+	t.Cleanup(server.Close)
+
+	return &Checker{
+		FeedUrl:       server.URL,
+		CacheFilePath: filepath.Join(t.TempDir(), cacheFileName),
+		SendUserAgent: sendUserAgent,
+		HttpClient:    server.Client(),
+	}
+}
+
+func setVersion(t *testing.T, version string) {
+	original := internal.Version
+	internal.Version = version + " (commit 0000000000000000000000000000000000000000)"
+	t.Cleanup(func() { internal.Version = original })
+}
+
+func Test_Check(t *testing.T) {
+	t.Setenv(SkipUpdateCheckEnvVarName, "")
+
+	t.Run("UpToDate", func(t *testing.T) {
+		setVersion(t, "1.5.0")
+		feed := &fakeFeed{latest: "1.5.0"}
+
+		result, err := feed.checker(t, true).Check(context.Background(), time.Hour)
+		require.NoError(t, err)
+		require.Equal(t, "1.5.0", result.Current.String())
+		require.Equal(t, "1.5.0", result.Latest.String())
+		require.False(t, result.UpdateAvailable())
+		require.Equal(t, []string{internal.UserAgent()}, feed.userAgents)
+	})
+
+	t.Run("OutOfDate", func(t *testing.T) {
+		setVersion(t, "1.5.0")
+		feed := &fakeFeed{latest: "1.6.1"}
+
+		result, err := feed.checker(t, true).Check(context.Background(), time.Hour)
+		require.NoError(t, err)
+		require.Equal(t, "1.6.1", result.Latest.String())
+		require.True(t, result.UpdateAvailable())
+	})
+
+	t.Run("DevBuild", func(t *testing.T) {
+		setVersion(t, "0.0.0-dev.0")
+		feed := &fakeFeed{latest: "1.6.1"}
+
+		result, err := feed.checker(t, true).Check(context.Background(), time.Hour)
+		require.NoError(t, err)
+		require.False(t, result.UpdateAvailable())
+	})
+
+	t.Run("TelemetryOptOut", func(t *testing.T) {
+		feed := &fakeFeed{latest: "1.6.1"}
+
+		_, err := feed.checker(t, false).LatestVersion(context.Background(), 0)
+		require.NoError(t, err)
+		require.Len(t, feed.userAgents, 1)
+		require.NotEqual(t, internal.UserAgent(), feed.userAgents[0])
+	})
+}
+
+func Test_LatestVersion_Cache(t *testing.T) {
+	t.Setenv(SkipUpdateCheckEnvVarName, "")
+
+	writeCache := func(t *testing.T, checker *Checker, cache cacheFile) {
+		contents, err := json.Marshal(cache)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(checker.CacheFilePath, contents, osutil.PermissionFile))
+	}
+
+	t.Run("Cached", func(t *testing.T) {
+		feed := &fakeFeed{latest: "1.6.1"}
+		checker := feed.checker(t, true)
+
+		for i := 0; i < 2; i++ {
+			version, err := checker.LatestVersion(context.Background(), time.Hour)
+			require.NoError(t, err)
+			require.Equal(t, "1.6.1", version.String())
+		}
+
+		require.Equal(t, 1, feed.requests)
+	})
+
+	t.Run("OlderThanMaxAge", func(t *testing.T) {
+		feed := &fakeFeed{latest: "1.6.1"}
+		checker := feed.checker(t, true)
+		now := time.Now().UTC()
+		writeCache(t, checker, cacheFile{
+			Version:   "1.6.0",
+			ExpiresOn: now.Add(20 * time.Hour).Format(time.RFC3339),
+			CheckedOn: now.Add(-4 * time.Hour).Format(time.RFC3339),
+		})
+
+		// The background check uses the unexpired version
+		version, err := checker.LatestVersion(context.Background(), 0)
+		require.NoError(t, err)
+		require.Equal(t, "1.6.0", version.String())
+		require.Equal(t, 0, feed.requests)
+
+		version, err = checker.LatestVersion(context.Background(), time.Hour)
+		require.NoError(t, err)
+		require.Equal(t, "1.6.1", version.String())
+		require.Equal(t, 1, feed.requests)
+	})
+
+	t.Run("OfflineMode", func(t *testing.T) {
+		t.Setenv(runcontext.OfflineEnvVarName, "true")
+
+		feed := &fakeFeed{latest: "1.6.1"}
+		_, err := feed.checker(t, true).LatestVersion(context.Background(), 0)
+		require.ErrorIs(t, err, ErrOffline)
+		require.Equal(t, 0, feed.requests)
+	})
+
+	t.Run("SkipUpdateCheck", func(t *testing.T) {
+		t.Setenv(SkipUpdateCheckEnvVarName, "true")
+
+		feed := &fakeFeed{latest: "1.6.1"}
+		checker := feed.checker(t, true)
+
+		_, err := checker.LatestVersion(context.Background(), time.Hour)
+		require.ErrorIs(t, err, ErrOffline)
+
+		// An expired version is still used when offline
+		writeCache(t, checker, cacheFile{
+			Version:   "1.6.0",
+			ExpiresOn: time.Now().UTC().Add(-time.Hour).Format(time.RFC3339),
+		})
+
+		version, err := checker.LatestVersion(context.Background(), time.Hour)
+		require.NoError(t, err)
+		require.Equal(t, "1.6.0", version.String())
+		require.Equal(t, 0, feed.requests)
+	})
+}
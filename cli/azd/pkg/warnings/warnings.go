@@ -0,0 +1,93 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package warnings collects the warnings of an operation, such as deprecated flags or build contexts missing
+// .dockerignore exclusions, so they are returned alongside the result of the operation and rendered once it completes.
+package warnings
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// Codes identifying the kind of a warning.
+const (
+	// CodeDeprecatedFlag is the code of warnings about a deprecated flag being used.
+	CodeDeprecatedFlag = "deprecated-flag"
+	// CodeDeprecatedField is the code of warnings about a deprecated azure.yaml field being set.
+	CodeDeprecatedField = "deprecated-field"
+	// CodeDockerignore is the code of warnings about a large docker build context whose .dockerignore doesn't exclude
+	// heavy directories.
+	CodeDockerignore = "dockerignore"
+)
+
+// Warning is a typed warning raised by an operation.
+type Warning struct {
+	// Code identifies the kind of warning, like 'deprecated-flag'.
+	Code string `json:"code"`
+	// Message is the description of the warning shown to users.
+	Message string `json:"message"`
+}
+
+type sinkKey struct{}
+
+// Sink collects the warnings of an operation. It is safe for concurrent use.
+type Sink struct {
+	mu       sync.Mutex
+	warnings []Warning
+}
+
+// NewSink creates an empty Sink.
+func NewSink() *Sink {
+	return &Sink{}
+}
+
+// Add appends a warning to the sink, unless the same warning was already added, like when a project is loaded again.
+func (s *Sink) Add(warning Warning) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if slices.Contains(s.warnings, warning) {
+		return
+	}
+
+	s.warnings = append(s.warnings, warning)
+}
+
+// Warnings returns the warnings added to the sink, in the order they were added.
+func (s *Sink) Warnings() []Warning {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.warnings) == 0 {
+		return nil
+	}
+
+	warnings := make([]Warning, len(s.warnings))
+	copy(warnings, s.warnings)
+	return warnings
+}
+
+// WithSink returns a copy of ctx carrying the sink.
+func WithSink(ctx context.Context, sink *Sink) context.Context {
+	return context.WithValue(ctx, sinkKey{}, sink)
+}
+
+// FromContext returns the sink carried by ctx, if any.
+func FromContext(ctx context.Context) (*Sink, bool) {
+	sink, ok := ctx.Value(sinkKey{}).(*Sink)
+	return sink, ok
+}
+
+// Add appends a warning to the sink carried by ctx, which renders it once the operation completes. It returns false
+// when ctx has no sink, in which case the caller remains responsible for showing the warning.
+func Add(ctx context.Context, warning Warning) bool {
+	sink, ok := FromContext(ctx)
+	if !ok {
+		return false
+	}
+
+	sink.Add(warning)
+	return true
+}
@@ -0,0 +1,41 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package warnings
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Sink_CollectsWarnings(t *testing.T) {
+	sink := NewSink()
+	ctx := WithSink(context.Background(), sink)
+
+	require.True(t, Add(ctx, Warning{Code: CodeDeprecatedFlag, Message: "--service is deprecated"}))
+	require.True(t, Add(ctx, Warning{Code: CodeDockerignore, Message: "node_modules isn't excluded"}))
+
+	require.Equal(t, []Warning{
+		{Code: CodeDeprecatedFlag, Message: "--service is deprecated"},
+		{Code: CodeDockerignore, Message: "node_modules isn't excluded"},
+	}, sink.Warnings())
+}
+
+func Test_Add_WithoutSink(t *testing.T) {
+	// The caller shows the warning itself when the context doesn't collect warnings
+	require.False(t, Add(context.Background(), Warning{Code: CodeDeprecatedFlag, Message: "--service is deprecated"}))
+}
+
+func Test_Sink_Empty(t *testing.T) {
+	require.Nil(t, NewSink().Warnings())
+}
+
+func Test_Sink_SkipsDuplicates(t *testing.T) {
+	sink := NewSink()
+	sink.Add(Warning{Code: CodeDeprecatedFlag, Message: "--service is deprecated"})
+	sink.Add(Warning{Code: CodeDeprecatedFlag, Message: "--service is deprecated"})
+
+	require.Len(t, sink.Warnings(), 1)
+}
@@ -63,6 +63,22 @@ func (a *MockAccountManager) GetDefaultSubscriptionID(ctx context.Context) strin
 	return a.DefaultSubscription
 }
 
+func (a *MockAccountManager) ResolveDefaultSubscription(ctx context.Context) (account.ResolvedDefault, error) {
+	if a.DefaultSubscription != "" {
+		return account.ResolvedDefault{Value: a.DefaultSubscription, Source: account.DefaultSourceConfig}, nil
+	}
+
+	return account.ResolvedDefault{}, nil
+}
+
+func (a *MockAccountManager) ResolveDefaultLocation(ctx context.Context) account.ResolvedDefault {
+	if a.DefaultLocation != "" {
+		return account.ResolvedDefault{Value: a.DefaultLocation, Source: account.DefaultSourceConfig}
+	}
+
+	return account.ResolvedDefault{}
+}
+
 func (a *MockAccountManager) GetLocations(ctx context.Context, subscriptionId string) ([]account.Location, error) {
 	return a.Locations, nil
 }